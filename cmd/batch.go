@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+// batchTarget holds the connection flags of the batch command.
+var batchTarget targetFlags
+
+// batchStopOnError stops a batch at the first failing command.
+var batchStopOnError bool
+
+// batchCmd runs every command of a file against one server.
+var batchCmd = &cobra.Command{
+	Use:   "batch [flags] <file>",
+	Short: "Run the commands in a file against an RCON server",
+	Long: `Run each line of a command file against an RCON server, in order, and
+print every command's result. Blank lines and lines starting with # or //
+are ignored, as in rcon_execute_script. Use "-" to read commands from stdin.
+
+The file is checked against the config file's policies before anything is
+sent; if any command is denied, none runs. Otherwise every command is
+attempted even if earlier ones fail; with --stop-on-error the batch ends at
+the first failure. Commands the server rejects count as failures.
+
+Exit codes:
+  0  every command ran successfully
+  1  usage or configuration error
+  2  authentication failed
+  3  the server could not be reached
+  4  at least one command failed or was rejected by the server
+  5  the config file's policies do not allow a command in the file
+
+Examples:
+  rcon-mcp-server batch --profile prod restart-runbook.txt
+  echo "save-all" | rcon-mcp-server batch --address localhost:25575 --password secret -`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		script, err := readInputFile(cmd.InOrStdin(), args[0])
+		if err != nil {
+			return err
+		}
+		lines := mcp.ParseScript(script)
+		if len(lines) == 0 {
+			return fmt.Errorf("%s contains no commands", args[0])
+		}
+
+		t, err := batchTarget.resolve()
+		if err != nil {
+			return err
+		}
+		// Check the whole file first so a runbook is never left half done
+		// because of a command the policies forbid.
+		var denied []error
+		for _, line := range lines {
+			if err := t.allow(line.Command); err != nil {
+				denied = append(denied, fmt.Errorf("line %d: %w", line.Number, err))
+			}
+		}
+		if len(denied) > 0 {
+			return withExitCode(exitPolicyDenied, errors.Join(denied...))
+		}
+
+		client, err := t.dial()
+		if err != nil {
+			return err
+		}
+		defer client.Disconnect()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		out := cmd.OutOrStdout()
+		failed, executed := 0, 0
+		for _, line := range lines {
+			fmt.Fprintf(out, "== line %d: %s\n", line.Number, line.Command)
+			output, err := client.ExecuteContext(ctx, line.Command)
+			executed++
+
+			switch {
+			case err != nil:
+				failed++
+				fmt.Fprintf(out, "error: %v\n", err)
+			case game.Rejected(t.Game, output):
+				failed++
+				fmt.Fprintf(out, "rejected: %s\n", strings.TrimRight(output, "\n"))
+			case output != "":
+				fmt.Fprintln(out, strings.TrimRight(output, "\n"))
+			}
+
+			if failed > 0 && (batchStopOnError || ctx.Err() != nil) {
+				break
+			}
+		}
+
+		summary := fmt.Sprintf("%d of %d commands run, %d failed", executed, len(lines), failed)
+		fmt.Fprintln(out, summary)
+		if failed > 0 {
+			return withExitCode(exitCommandError, fmt.Errorf("batch failed: %s", summary))
+		}
+		return nil
+	},
+}
+
+// init registers the batch command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(batchCmd)
+	batchTarget.register(batchCmd)
+	batchCmd.Flags().BoolVar(&batchStopOnError, "stop-on-error", false, "stop at the first command that fails")
+}
+
+// readInputFile reads the named file, or stdin when name is "-".
+func readInputFile(stdin io.Reader, name string) (string, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return string(data), nil
+}
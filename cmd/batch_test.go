@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatchCommand(t *testing.T) {
+	address := startTestRCONServer(t, "secret", func(command string) string {
+		if command == "bogus" {
+			return "Unknown or incomplete command, see below for error"
+		}
+		return "ok: " + command
+	})
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	good := writeFile("good.txt", "# restart runbook\nsay Restarting\n\n// flush the world\nsave-all\n")
+	mixed := writeFile("mixed.txt", "say one\nbogus\nsay two\n")
+	empty := writeFile("empty.txt", "# nothing here\n")
+	denied := writeFile("denied.txt", "say one\nstop\n")
+	config := writeFile("config.json", `{"policies": {"deny": ["stop"]}}`)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput []string
+		wantAbsent []string
+		wantCode   int
+	}{
+		{
+			name:       "all commands succeed",
+			args:       []string{"batch", "--address", address, "--password", "secret", good},
+			wantOutput: []string{"== line 2: say Restarting", "ok: say Restarting", "== line 5: save-all", "2 of 2 commands run, 0 failed"},
+			wantCode:   exitOK,
+		},
+		{
+			name:       "failures continue by default",
+			args:       []string{"batch", "--address", address, "--password", "secret", mixed},
+			wantOutput: []string{"rejected: Unknown or incomplete command", "ok: say two", "3 of 3 commands run, 1 failed"},
+			wantCode:   exitCommandError,
+		},
+		{
+			name:       "stop on error",
+			args:       []string{"batch", "--address", address, "--password", "secret", "--stop-on-error", mixed},
+			wantOutput: []string{"ok: say one", "2 of 3 commands run, 1 failed"},
+			wantAbsent: []string{"say two"},
+			wantCode:   exitCommandError,
+		},
+		{
+			name:       "no commands",
+			args:       []string{"batch", "--address", address, "--password", "secret", empty},
+			wantOutput: []string{"contains no commands"},
+			wantCode:   exitFailure,
+		},
+		{
+			name:       "denied by policy runs nothing",
+			args:       []string{"batch", "--config", config, "--address", address, "--password", "secret", denied},
+			wantOutput: []string{`line 2: command "stop" is not allowed`},
+			wantAbsent: []string{"ok: say one"},
+			wantCode:   exitPolicyDenied,
+		},
+		{
+			name:       "wrong password",
+			args:       []string{"batch", "--address", address, "--password", "wrong", good},
+			wantOutput: []string{"invalid password"},
+			wantCode:   exitAuthFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, code := runCLI(t, tt.args...)
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d; output:\n%s", tt.wantCode, code, output)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(output, absent) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", absent, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// configOutput is the file config export writes to, stdout when empty.
+var configOutput string
+
+// configReplace lets config import overwrite existing profiles and sessions.
+var configReplace bool
+
+// configRootCmd groups the subcommands that move configuration between machines.
+var configRootCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Export and import the configuration",
+	Long: `Export the config file as a single portable JSON document and import it
+on another machine. Profiles, preloaded sessions, tool settings, limits,
+policies, logging and transport settings are included.
+
+Secrets are referenced, not embedded: passwords are removed and listed
+under "secrets", and import reports the ones that still have to be set.
+
+Examples:
+  rcon-mcp-server config export --output rcon-config.json
+  rcon-mcp-server config import rcon-config.json --config ~/.config/rcon-mcp-server/config.json`,
+	Args: cobra.NoArgs,
+}
+
+// configExportCmd writes the config file without secrets.
+var configExportCmd = &cobra.Command{
+	Use:          "export",
+	Short:        "Export the configuration without secrets",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, _, err := loadConfigForUpdate()
+		if err != nil {
+			return err
+		}
+
+		if configOutput == "" {
+			return printJSON(cmd.OutOrStdout(), cfg.Export())
+		}
+		f, err := os.OpenFile(configOutput, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to create export: %w", err)
+		}
+		if err := printJSON(f, cfg.Export()); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		return f.Close()
+	},
+}
+
+// configImportCmd merges an export document into the config file.
+var configImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import an exported configuration",
+	Long: `Merge an export document ("-" for stdin) into the config file. Sections
+in the document replace the local ones. Profiles and sessions are added;
+existing ones are only overwritten with --replace, and keep their local
+passwords.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := readInputFile(cmd.InOrStdin(), args[0])
+		if err != nil {
+			return err
+		}
+		export, err := config.ParseExport([]byte(data))
+		if err != nil {
+			return err
+		}
+
+		cfg, path, err := loadConfigForUpdate()
+		if err != nil {
+			return err
+		}
+		missing, err := cfg.Import(export, configReplace)
+		if err != nil {
+			return err
+		}
+		if err := cfg.Save(path); err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "imported %d profiles and %d sessions into %s\n", len(export.Profiles), len(export.Sessions), path)
+		if len(missing) > 0 {
+			fmt.Fprintf(out, "secrets to set: %s\n", strings.Join(missing, ", "))
+		}
+		return nil
+	},
+}
+
+// init registers the config command and its subcommands with the root
+// command during package initialization.
+func init() {
+	rootCmd.AddCommand(configRootCmd)
+	configRootCmd.AddCommand(configExportCmd, configImportCmd)
+	configExportCmd.Flags().StringVarP(&configOutput, "output", "o", "", "write the export to this file instead of stdout")
+	configImportCmd.Flags().BoolVar(&configReplace, "replace", false, "overwrite existing profiles and sessions")
+}
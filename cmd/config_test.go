@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+)
+
+func TestConfigExportImport(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.json")
+	target := filepath.Join(dir, "target.json")
+	exported := filepath.Join(dir, "export.json")
+
+	if _, code := runCLI(t, "servers", "add", "survival", "--config", source, "--address", "mc:25575", "--password", "secret"); code != exitOK {
+		t.Fatal("Failed to set up the source config")
+	}
+
+	if output, code := runCLI(t, "config", "export", "--config", source, "--output", exported); code != exitOK {
+		t.Fatalf("Export failed with code %d:\n%s", code, output)
+	}
+	data, err := os.ReadFile(exported)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if strings.Contains(string(data), `"password"`) || !strings.Contains(string(data), "profile/survival/password") {
+		t.Errorf("Expected the password to be referenced, not embedded:\n%s", data)
+	}
+
+	output, code := runCLI(t, "config", "import", exported, "--config", target)
+	if code != exitOK {
+		t.Fatalf("Import failed with code %d:\n%s", code, output)
+	}
+	if !strings.Contains(output, "imported 1 profiles") || !strings.Contains(output, "secrets to set: profile/survival/password") {
+		t.Errorf("Unexpected import output:\n%s", output)
+	}
+	cfg, err := config.Load(target)
+	if err != nil {
+		t.Fatalf("Failed to load imported config: %v", err)
+	}
+	if p, err := cfg.Profile("survival"); err != nil || p.Address != "mc:25575" {
+		t.Errorf("Expected imported profile, got %+v, %v", p, err)
+	}
+
+	output, code = runCLI(t, "config", "import", exported, "--config", target)
+	if code != exitFailure || !strings.Contains(output, "already exists") {
+		t.Errorf("Expected a conflict on second import, got code %d:\n%s", code, output)
+	}
+	if _, code := runCLI(t, "config", "import", exported, "--config", target, "--replace"); code != exitOK {
+		t.Errorf("Expected import with --replace to succeed, got code %d", code)
+	}
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+// conformanceTarget holds the connection flags of the conformance command.
+var conformanceTarget targetFlags
+
+// conformanceFlags holds the other flags of the conformance command.
+var conformanceFlags conformanceOptions
+
+// conformanceOptions are the parameters of a conformance run.
+type conformanceOptions struct {
+	command         string        // Command answered in one packet
+	largeCommand    string        // Command whose reply may span several packets
+	timeout         time.Duration // How long to wait for each reply
+	skipBadPassword bool          // Skip logging in with a wrong password
+	json            bool          // Print the report as JSON
+}
+
+// conformanceReport is the result of a conformance run, as printed with
+// --json.
+type conformanceReport struct {
+	Target  string                  `json:"target"`
+	Address string                  `json:"address"`
+	Checks  []rcon.ConformanceCheck `json:"checks"`
+}
+
+// conformanceCmd checks how a server's RCON deviates from the protocol.
+var conformanceCmd = &cobra.Command{
+	Use:   "conformance",
+	Short: "Check how a server's RCON deviates from the Source RCON protocol",
+	Long: `Run a battery of protocol checks against a Source RCON server and print a
+compatibility report: how it answers logins and wrong passwords, whether
+replies echo request IDs, whether it reassembles packets split across writes
+and accepts packets of the protocol's largest size, how it splits long
+replies, whether it mirrors the empty packets marking the end of a reply,
+and whether it answers two commands sent together.
+
+Each check is ok, a quirk the client copes with (with the setting it needs,
+if any), or a failure that breaks the client. Attach the report to quirk
+reports, so the behavior of the server is known without access to it.
+
+The checks send --command, "status" by default, and --large-command, "help"
+by default, whose reply should span several packets; pick commands that only
+read state. --skip-bad-password leaves out the wrong password login, for
+servers that ban addresses after failed logins.
+
+Exit codes:
+  0  no check failed
+  1  a check failed, or usage or configuration error
+  2  authentication failed
+  3  the server could not be reached
+
+Examples:
+  rcon-mcp-server conformance --address localhost:25575 --password secret
+  rcon-mcp-server conformance --profile tf2 --large-command "cvarlist sv_" --json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := conformanceFlags
+		if opts.timeout <= 0 {
+			return errors.New("--timeout must be positive")
+		}
+
+		t, err := conformanceTarget.resolve()
+		if err != nil {
+			return err
+		}
+		if t.Protocol != "" && t.Protocol != rcon.ProtocolRCON {
+			return fmt.Errorf("%s uses the %s protocol; conformance checks Source RCON servers only", t.Name, t.Protocol)
+		}
+		for _, command := range []string{opts.command, opts.largeCommand} {
+			if err := t.allow(command); err != nil {
+				return err
+			}
+		}
+		redact.AddSecret(t.Password)
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		checks, err := rcon.CheckConformance(ctx, t.Address, t.Password, rcon.ConformanceOptions{
+			Command:         opts.command,
+			LargeCommand:    opts.largeCommand,
+			Timeout:         opts.timeout,
+			SkipBadPassword: opts.skipBadPassword,
+		})
+		switch {
+		case errors.Is(err, rcon.ErrAuthFailed):
+			return withExitCode(exitAuthFailure, fmt.Errorf("%s: %w", t.Name, err))
+		case err != nil && checks == nil:
+			return withExitCode(exitConnectError, fmt.Errorf("%s: %w", t.Name, err))
+		case err != nil:
+			return err
+		}
+
+		report := conformanceReport{Target: t.Name, Address: t.Address, Checks: checks}
+		if opts.json {
+			err = printJSON(cmd.OutOrStdout(), report)
+		} else {
+			err = report.print(cmd.OutOrStdout())
+		}
+		if err != nil {
+			return err
+		}
+		if failed := report.count(rcon.ConformanceFail); failed > 0 {
+			return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+		}
+		return nil
+	},
+}
+
+// init registers the conformance command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(conformanceCmd)
+	conformanceTarget.register(conformanceCmd)
+	flags := conformanceCmd.Flags()
+	flags.StringVar(&conformanceFlags.command, "command", "status", "command answered in one packet, sent by most checks")
+	flags.StringVar(&conformanceFlags.largeCommand, "large-command", "help", "command whose reply spans several packets, e.g. cvarlist on Source servers")
+	flags.DurationVar(&conformanceFlags.timeout, "timeout", 5*time.Second, "how long to wait for each reply")
+	flags.BoolVar(&conformanceFlags.skipBadPassword, "skip-bad-password", false, "skip the login with a wrong password")
+	flags.BoolVar(&conformanceFlags.json, "json", false, "print the report as JSON")
+}
+
+// count returns the number of checks with status.
+func (r conformanceReport) count(status string) int {
+	n := 0
+	for _, check := range r.Checks {
+		if check.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// print writes the report as one line per check, followed by the advice
+// for quirks and failures and a summary.
+func (r conformanceReport) print(w io.Writer) error {
+	if r.Target == r.Address {
+		fmt.Fprintf(w, "Conformance of %s\n\n", r.Address)
+	} else {
+		fmt.Fprintf(w, "Conformance of %s (%s)\n\n", r.Target, r.Address)
+	}
+	for _, check := range r.Checks {
+		fmt.Fprintf(w, "[%s] %s: %s\n", check.Status, check.Name, check.Message)
+		if check.Advice != "" && check.Status != rcon.ConformanceOK {
+			fmt.Fprintf(w, "       advice: %s\n", check.Advice)
+		}
+	}
+	quirks, failed := r.count(rcon.ConformanceQuirk), r.count(rcon.ConformanceFail)
+	_, err := fmt.Fprintf(w, "\n%d checks: %d ok, %d quirks, %d failed\n", len(r.Checks), len(r.Checks)-quirks-failed, quirks, failed)
+	if err == nil && quirks+failed > 0 {
+		_, err = fmt.Fprintln(w, "Attach this report when filing a quirk report for this server.")
+	}
+	return err
+}
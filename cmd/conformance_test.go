@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestConformanceCommand(t *testing.T) {
+	address := startTestRCONServer(t, "secret", func(command string) string { return "ok" })
+
+	output, code := runCLI(t, "conformance", "--address", address, "--password", "secret", "--timeout", "1s")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, output)
+	}
+	for _, want := range []string{"[ok] auth:", "[ok] id-echo:", "[quirk] end-marker:", "advice:", "Attach this report"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+
+	output, code = runCLI(t, "conformance", "--address", address, "--password", "secret", "--timeout", "1s", "--skip-bad-password", "--json")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, output)
+	}
+	var report conformanceReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("Expected a JSON report, got %v: %s", err, output)
+	}
+	if report.Address != address || len(report.Checks) != 7 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+func TestConformanceCommand_Errors(t *testing.T) {
+	address := startTestRCONServer(t, "secret", func(command string) string { return "ok" })
+	if output, code := runCLI(t, "conformance", "--address", address, "--password", "wrong"); code != exitAuthFailure {
+		t.Errorf("Expected exit code %d for a wrong password, got %d: %s", exitAuthFailure, code, output)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := ln.Addr().String()
+	ln.Close()
+	if output, code := runCLI(t, "conformance", "--address", closed, "--password", "secret"); code != exitConnectError {
+		t.Errorf("Expected exit code %d for an unreachable server, got %d: %s", exitConnectError, code, output)
+	}
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// daemonStartTimeout is how long serve --daemon waits for the background
+// server to write its pidfile.
+const daemonStartTimeout = 5 * time.Second
+
+// daemonStopTimeout is how long daemon stop waits for the server to exit.
+const daemonStopTimeout = 10 * time.Second
+
+// daemonPidfile holds the --pidfile flag of the daemon subcommands.
+var daemonPidfile string
+
+// daemonCmd groups the commands that manage a server started with
+// serve --daemon.
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Manage a server running in the background",
+	Long: `Inspect and stop a server started with "serve --daemon --pidfile".
+
+Examples:
+  rcon-mcp-server serve --transport http --daemon --pidfile /run/rcon-mcp-server.pid --log-file /var/log/rcon-mcp-server.log
+  rcon-mcp-server daemon status --pidfile /run/rcon-mcp-server.pid
+  rcon-mcp-server daemon stop --pidfile /run/rcon-mcp-server.pid`,
+	Args: cobra.NoArgs,
+}
+
+// daemonStatusCmd reports whether the server in the pidfile is running.
+var daemonStatusCmd = &cobra.Command{
+	Use:          "status",
+	Short:        "Report whether the background server is running",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pid, err := runningPid(daemonPidfile)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "running (pid %d)\n", pid)
+		return nil
+	},
+}
+
+// daemonStopCmd stops the server in the pidfile and waits for it to exit.
+var daemonStopCmd = &cobra.Command{
+	Use:          "stop",
+	Short:        "Stop the background server",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pid, err := runningPid(daemonPidfile)
+		if err != nil {
+			return err
+		}
+		if err := terminate(pid); err != nil {
+			return fmt.Errorf("failed to stop pid %d: %w", pid, err)
+		}
+
+		deadline := time.Now().Add(daemonStopTimeout)
+		for processAlive(pid) {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("pid %d did not exit within %s", pid, daemonStopTimeout)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		_ = os.Remove(daemonPidfile)
+		fmt.Fprintf(cmd.OutOrStdout(), "stopped (pid %d)\n", pid)
+		return nil
+	},
+}
+
+// init registers the daemon command and its subcommands with the root
+// command during package initialization.
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonStatusCmd, daemonStopCmd)
+	daemonCmd.PersistentFlags().StringVar(&daemonPidfile, "pidfile", "", "pidfile written by serve --pidfile")
+	_ = daemonCmd.MarkPersistentFlagRequired("pidfile")
+}
+
+// startDaemon runs the current command line again, without --daemon, as a
+// background process detached from the terminal. When pidfile is set it
+// waits until the background server has written it.
+func startDaemon(pidfile string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	var args []string
+	for _, arg := range os.Args[1:] {
+		if arg != "--daemon" && !strings.HasPrefix(arg, "--daemon=") {
+			args = append(args, arg)
+		}
+	}
+
+	child := exec.Command(exe, args...)
+	detach(child)
+	if err := child.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start daemon: %w", err)
+	}
+	pid := child.Process.Pid
+
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+
+	deadline := time.After(daemonStartTimeout)
+	for {
+		if pidfile == "" {
+			return pid, nil
+		}
+		if written, err := readPidfile(pidfile); err == nil && written == pid {
+			return pid, nil
+		}
+		select {
+		case err := <-exited:
+			return 0, fmt.Errorf("daemon exited during startup: %v; check the log file", err)
+		case <-deadline:
+			return 0, fmt.Errorf("daemon did not write %s within %s", pidfile, daemonStartTimeout)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// acquirePidfile writes the current process ID to path. It fails if the
+// file names a process that is still running; a stale file is replaced.
+// The returned function removes the file.
+func acquirePidfile(path string) (func(), error) {
+	if pid, err := readPidfile(path); err == nil && pid != os.Getpid() && processAlive(pid) {
+		return nil, fmt.Errorf("already running with pid %d (pidfile %s)", pid, path)
+	}
+
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write pidfile: %w", err)
+	}
+	return func() { _ = os.Remove(path) }, nil
+}
+
+// readPidfile returns the process ID stored in path.
+func readPidfile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("pidfile %s does not hold a process ID", path)
+	}
+	return pid, nil
+}
+
+// runningPid returns the process ID in the pidfile if that process is
+// running.
+func runningPid(path string) (int, error) {
+	pid, err := readPidfile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, errors.New("not running (no pidfile)")
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !processAlive(pid) {
+		return 0, fmt.Errorf("not running (stale pidfile for pid %d)", pid)
+	}
+	return pid, nil
+}
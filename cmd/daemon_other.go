@@ -0,0 +1,25 @@
+//go:build !unix
+
+package cmd
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// errDaemonUnsupported is returned on platforms without daemon support.
+var errDaemonUnsupported = errors.New("daemon mode is only supported on Unix systems")
+
+// detach is a no-op on platforms without sessions.
+func detach(*exec.Cmd) {}
+
+// terminate is not supported on this platform.
+func terminate(int) error {
+	return errDaemonUnsupported
+}
+
+// processAlive cannot check processes on this platform and assumes the
+// process is gone.
+func processAlive(int) bool {
+	return false
+}
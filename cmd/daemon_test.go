@@ -0,0 +1,100 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestAcquirePidfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.pid")
+
+	release, err := acquirePidfile(path)
+	if err != nil {
+		t.Fatalf("acquirePidfile failed: %v", err)
+	}
+	if pid, err := readPidfile(path); err != nil || pid != os.Getpid() {
+		t.Errorf("Expected pidfile to hold %d, got %d, %v", os.Getpid(), pid, err)
+	}
+	release()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected release to remove the pidfile")
+	}
+
+	sleeper := startSleeper(t)
+	writePid(t, path, sleeper.Process.Pid)
+	if _, err := acquirePidfile(path); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("Expected error for a running process, got %v", err)
+	}
+
+	writePid(t, path, exitedPid(t))
+	if _, err := acquirePidfile(path); err != nil {
+		t.Errorf("Expected stale pidfile to be replaced, got %v", err)
+	}
+}
+
+func TestDaemonCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.pid")
+
+	output, code := runCLI(t, "daemon", "status", "--pidfile", path)
+	if code != exitFailure || !strings.Contains(output, "no pidfile") {
+		t.Errorf("Expected missing pidfile to be reported, got %d: %s", code, output)
+	}
+
+	writePid(t, path, exitedPid(t))
+	output, code = runCLI(t, "daemon", "status", "--pidfile", path)
+	if code != exitFailure || !strings.Contains(output, "stale pidfile") {
+		t.Errorf("Expected stale pidfile to be reported, got %d: %s", code, output)
+	}
+
+	sleeper := startSleeper(t)
+	writePid(t, path, sleeper.Process.Pid)
+	output, code = runCLI(t, "daemon", "status", "--pidfile", path)
+	if code != exitOK || !strings.Contains(output, "running (pid "+strconv.Itoa(sleeper.Process.Pid)+")") {
+		t.Errorf("Expected running status, got %d: %s", code, output)
+	}
+
+	go sleeper.Wait()
+	output, code = runCLI(t, "daemon", "stop", "--pidfile", path)
+	if code != exitOK || !strings.Contains(output, "stopped") {
+		t.Errorf("Expected stop to succeed, got %d: %s", code, output)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected stop to remove the pidfile")
+	}
+}
+
+// startSleeper starts a long-running child process that is killed when the
+// test ends.
+func startSleeper(t *testing.T) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("Cannot start sleep: %v", err)
+	}
+	t.Cleanup(func() { _ = cmd.Process.Kill() })
+	return cmd
+}
+
+// exitedPid returns the process ID of a child that has already exited.
+func exitedPid(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("Cannot run true: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+// writePid writes a pidfile holding pid.
+func writePid(t *testing.T, path string, pid int) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strconv.Itoa(pid)), 0o644); err != nil {
+		t.Fatalf("Failed to write pidfile: %v", err)
+	}
+}
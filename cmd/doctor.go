@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// doctorDialTimeout bounds how long the doctor waits for each profile address.
+const doctorDialTimeout = 5 * time.Second
+
+// doctorCmd diagnoses common setup problems.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the configuration and runtime environment",
+	Long: `Check the environment the server runs in and print findings with suggested
+fixes:
+
+  config     the config file is found and valid
+  profiles   every profile address accepts TCP connections
+  transport  the HTTP or SSE listen address is free
+  keyring    whether passwords can be kept in an OS keyring
+  stdio      nothing but the MCP protocol will be written to stdout
+
+The command exits with code 1 if any check fails. Warnings do not affect
+the exit code.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		findings := runDoctor()
+		printFindings(cmd.OutOrStdout(), findings)
+
+		failed := 0
+		for _, f := range findings {
+			if f.status == statusFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+// init registers the doctor command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// Outcomes of a doctor check.
+const (
+	statusOK   = "ok"
+	statusWarn = "warn"
+	statusFail = "fail"
+)
+
+// finding is the outcome of one doctor check.
+type finding struct {
+	status  string // statusOK, statusWarn or statusFail
+	check   string // Name of the check
+	message string // What was found
+	fix     string // How to fix a warning or failure
+}
+
+// runDoctor runs every check. Checks that need a valid configuration are
+// skipped when it cannot be loaded.
+func runDoctor() []finding {
+	cfg, configFinding := checkConfig()
+	findings := []finding{configFinding}
+	if cfg != nil {
+		findings = append(findings, checkProfileAddresses(cfg)...)
+		findings = append(findings, checkTransport(cfg))
+	}
+	findings = append(findings, checkKeyring(), checkStdio(cfg))
+	return findings
+}
+
+// printFindings writes one line per finding, followed by its fix.
+func printFindings(w io.Writer, findings []finding) {
+	for _, f := range findings {
+		fmt.Fprintf(w, "[%s] %s: %s\n", f.status, f.check, f.message)
+		if f.fix != "" && f.status != statusOK {
+			fmt.Fprintf(w, "       fix: %s\n", f.fix)
+		}
+	}
+}
+
+// checkConfig locates and loads the config file.
+func checkConfig() (*config.Config, finding) {
+	path, required := config.Locate(configPath, os.LookupEnv)
+	cfg, err := loadConfig()
+	switch {
+	case err != nil:
+		return nil, finding{statusFail, "config", err.Error(), "correct the file, or point --config or RCON_MCP_CONFIG at a valid one"}
+	case path == "":
+		return cfg, finding{statusWarn, "config", "cannot determine the user config directory", "pass --config or set RCON_MCP_CONFIG"}
+	}
+
+	info, statErr := os.Stat(path)
+	switch {
+	case !required && errors.Is(statErr, os.ErrNotExist):
+		return cfg, finding{statusWarn, "config", "no config file at " + path + "; no profiles are available",
+			"create one with \"rcon-mcp-server servers add\""}
+	case statErr == nil && info.Mode().Perm()&0o077 != 0 && hasPasswords(cfg):
+		return cfg, finding{statusWarn, "config", fmt.Sprintf("%s holds passwords but is readable by other users (mode %o)", path, info.Mode().Perm()),
+			"chmod 600 " + path}
+	}
+	return cfg, finding{statusOK, "config", fmt.Sprintf("%s is valid (%d profiles)", path, len(cfg.Profiles)), ""}
+}
+
+// hasPasswords reports whether any profile stores a password.
+func hasPasswords(cfg *config.Config) bool {
+	for _, p := range cfg.Profiles {
+		if p.Password != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkProfileAddresses opens a TCP connection to every profile address in
+// parallel. It does not authenticate; use healthcheck for that.
+func checkProfileAddresses(cfg *config.Config) []finding {
+	profiles := cfg.SortedProfiles()
+	findings := make([]finding, len(profiles))
+	var wg sync.WaitGroup
+	for i, p := range profiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			check := "profile " + p.Name
+			conn, err := net.DialTimeout("tcp", p.Address, doctorDialTimeout)
+			if err != nil {
+				findings[i] = finding{statusFail, check, fmt.Sprintf("%s is unreachable: %v", p.Address, err),
+					"check the address, that the game server is running with RCON enabled, and firewalls in between"}
+				return
+			}
+			conn.Close()
+			message := p.Address + " is reachable"
+			if !p.HasPassword() {
+				findings[i] = finding{statusWarn, check, message + " but the profile has no password",
+					"add a password so the profile can authenticate"}
+				return
+			}
+			if _, err := p.ResolvePassword(os.LookupEnv); err != nil {
+				fix := "export " + p.PasswordEnv + " in the server's environment"
+				switch {
+				case p.PasswordKeyring != "":
+					fix = "store it with \"rcon-mcp-server servers set-password " + p.Name + "\""
+				case p.PasswordEncrypted != "":
+					fix = "set " + secrets.KeyEnv + " to the key it was encrypted with, or re-encrypt it with \"rcon-mcp-server encrypt-password " + p.Name + "\""
+				case p.PasswordRef != "":
+					fix = "check that the secret store is reachable with the server's credentials and holds " + p.PasswordRef
+				}
+				findings[i] = finding{statusWarn, check, message + " but its password cannot be read: " + err.Error(), fix}
+				return
+			}
+			findings[i] = finding{statusOK, check, message, ""}
+		}()
+	}
+	wg.Wait()
+	return findings
+}
+
+// checkTransport verifies that a network transport can bind its listen
+// address.
+func checkTransport(cfg *config.Config) finding {
+	kind := cfg.Transport.Kind()
+	if kind == config.TransportStdio {
+		return finding{statusOK, "transport", "stdio, no port needed", ""}
+	}
+
+	address := cfg.Transport.Address()
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return finding{statusFail, "transport", fmt.Sprintf("cannot listen on %s for %s: %v", address, kind, err),
+			"stop the process using the port or choose another address with --listen"}
+	}
+	ln.Close()
+	return finding{statusOK, "transport", fmt.Sprintf("%s can listen on %s", kind, address), ""}
+}
+
+// checkKeyring reports whether passwords can be kept in an OS keyring.
+func checkKeyring() finding {
+	return finding{statusWarn, "keyring", "OS keyring storage is not supported; passwords are read from the config file",
+		"keep the config file private (mode 0600)"}
+}
+
+// checkStdio verifies that, for the stdio transport, the log is not written
+// to stdout where it would corrupt the MCP stream.
+func checkStdio(cfg *config.Config) finding {
+	if cfg == nil || cfg.Transport.Kind() != config.TransportStdio {
+		return finding{statusOK, "stdio", "not used by the configured transport", ""}
+	}
+
+	switch filepath.Clean(cfg.Logging.File) {
+	case "/dev/stdout", "/dev/fd/1", "/proc/self/fd/1":
+		return finding{statusFail, "stdio", "the log is written to stdout, which corrupts the MCP stream",
+			"log to stderr (the default) or to a file"}
+	}
+	return finding{statusOK, "stdio", "logs are kept off stdout", ""}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctorCommand(t *testing.T) {
+	address := startTestRCONServer(t, "secret", func(string) string { return "" })
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer busy.Close()
+
+	writeConfig := func(content string, perm os.FileMode) string {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(content), perm); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name       string
+		config     string
+		perm       os.FileMode
+		wantOutput []string
+		wantCode   int
+	}{
+		{
+			name:       "healthy stdio setup",
+			config:     `{"profiles": [{"name": "local", "address": "` + address + `", "password": "secret"}]}`,
+			perm:       0o600,
+			wantOutput: []string{"[ok] config:", "(1 profiles)", "[ok] profile local:", "[ok] transport: stdio", "[warn] keyring:", "[ok] stdio:"},
+			wantCode:   exitOK,
+		},
+		{
+			name:       "loose permissions and unreachable profile",
+			config:     `{"profiles": [{"name": "down", "address": "127.0.0.1:1", "password": "secret"}]}`,
+			perm:       0o644,
+			wantOutput: []string{"[warn] config:", "chmod 600", "[fail] profile down:", "unreachable"},
+			wantCode:   exitFailure,
+		},
+		{
+			name:       "port conflict",
+			config:     `{"transport": {"type": "http", "listen": "` + busy.Addr().String() + `"}}`,
+			perm:       0o600,
+			wantOutput: []string{"[fail] transport:", "cannot listen", "fix: stop the process"},
+			wantCode:   exitFailure,
+		},
+		{
+			name:       "log written to stdout",
+			config:     `{"logging": {"file": "/dev/stdout"}}`,
+			perm:       0o600,
+			wantOutput: []string{"[fail] stdio:", "corrupts the MCP stream"},
+			wantCode:   exitFailure,
+		},
+		{
+			name:       "invalid config",
+			config:     `{"profiles": [{"name": "x"}]}`,
+			perm:       0o600,
+			wantOutput: []string{"[fail] config:", "address is required"},
+			wantCode:   exitFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, code := runCLI(t, "doctor", "--config", writeConfig(tt.config, tt.perm))
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d; output:\n%s", tt.wantCode, code, output)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/keyring"
+	"github.com/mjmorales/rcon-mcp-server/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	encryptGenerateKey bool // Generate an encryption key instead of encrypting
+	encryptKeyring     bool // Store the generated key in the OS keyring
+)
+
+// encryptPasswordCmd encrypts RCON passwords for the config file.
+var encryptPasswordCmd = &cobra.Command{
+	Use:   "encrypt-password [profile]",
+	Short: "Encrypt a password so it can be kept in the config file",
+	Long: `Encrypt an RCON password with AES-256-GCM, for users who cannot keep it in
+an OS keyring but do not want it in the config file in plain text.
+
+The key is read from ` + secrets.KeyEnv + ` or, when it is unset, from the OS
+keyring. Generate one first with --generate-key, which prints it, or with
+--generate-key --keyring, which stores it in the keyring.
+
+The password is prompted for without echo, or read from the first line of
+standard input when it is not a terminal. Given a profile, its
+password_encrypted is set and any other password source removed from the
+config file; otherwise the encrypted value is printed for use as a
+profile's "password_encrypted".
+
+Examples:
+  rcon-mcp-server encrypt-password --generate-key --keyring
+  rcon-mcp-server encrypt-password survival --config config.json
+  export ` + secrets.KeyEnv + `=$(rcon-mcp-server encrypt-password --generate-key)`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if encryptGenerateKey {
+			if len(args) > 0 {
+				return errors.New("--generate-key does not take a profile")
+			}
+			return generateSecretKey(cmd)
+		}
+		if encryptKeyring {
+			return errors.New("--keyring requires --generate-key")
+		}
+
+		key, err := secrets.LoadKey(os.LookupEnv)
+		if err != nil {
+			return err
+		}
+		prompt := "Password: "
+		if len(args) > 0 {
+			prompt = "Password for " + args[0] + ": "
+		}
+		password, err := readPassword(cmd, prompt)
+		if err != nil {
+			return err
+		}
+		if password == "" {
+			return errors.New("the password must not be empty")
+		}
+		encrypted, err := secrets.Encrypt(key, password)
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), encrypted)
+			return nil
+		}
+		cfg, path, err := loadConfigForUpdate()
+		if err != nil {
+			return err
+		}
+		profile, err := cfg.Profile(args[0])
+		if err != nil {
+			return err
+		}
+		profile.Password, profile.PasswordEnv, profile.PasswordKeyring, profile.PasswordRef = "", "", "", ""
+		profile.PasswordEncrypted = encrypted
+		if err := cfg.Save(path); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "stored the encrypted password of profile %s in %s\n", profile.Name, path)
+		return nil
+	},
+}
+
+// generateSecretKey creates an encryption key and prints it, or stores it
+// in the OS keyring with --keyring.
+func generateSecretKey(cmd *cobra.Command) error {
+	key, err := secrets.GenerateKey()
+	if err != nil {
+		return err
+	}
+	if !encryptKeyring {
+		fmt.Fprintln(cmd.OutOrStdout(), key)
+		return nil
+	}
+	if _, err := keyring.Get(secrets.KeyringAccount); err == nil {
+		return errors.New("the OS keyring already holds an encryption key; passwords encrypted with it could no longer be read")
+	}
+	if err := keyring.Set(secrets.KeyringAccount, key); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "stored a new encryption key in the OS keyring as %s\n", secrets.KeyringAccount)
+	return nil
+}
+
+// init registers the encrypt-password command with the root command during
+// package initialization.
+func init() {
+	rootCmd.AddCommand(encryptPasswordCmd)
+	encryptPasswordCmd.Flags().BoolVar(&encryptGenerateKey, "generate-key", false, "generate a new encryption key instead of encrypting a password")
+	encryptPasswordCmd.Flags().BoolVar(&encryptKeyring, "keyring", false, "with --generate-key, store the key in the OS keyring instead of printing it")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/keyring"
+	"github.com/mjmorales/rcon-mcp-server/internal/secrets"
+)
+
+func TestEncryptPassword(t *testing.T) {
+	keyring.MockInit()
+	defer keyring.Delete(secrets.KeyringAccount)
+	defer rootCmd.SetIn(nil)
+
+	rootCmd.SetIn(strings.NewReader("secret\n"))
+	if output, code := runCLI(t, "encrypt-password"); code != exitFailure || !strings.Contains(output, "no encryption key") {
+		t.Errorf("Expected a missing key error, got code %d:\n%s", code, output)
+	}
+
+	output, code := runCLI(t, "encrypt-password", "--generate-key")
+	if code != exitOK {
+		t.Fatalf("--generate-key failed with code %d:\n%s", code, output)
+	}
+	if _, err := secrets.ParseKey(output); err != nil {
+		t.Errorf("Expected a printed key, got %q: %v", output, err)
+	}
+	if output, code := runCLI(t, "encrypt-password", "--generate-key", "--keyring"); code != exitOK {
+		t.Fatalf("--generate-key --keyring failed with code %d:\n%s", code, output)
+	}
+	if output, code := runCLI(t, "encrypt-password", "--generate-key", "--keyring"); code != exitFailure || !strings.Contains(output, "already holds") {
+		t.Errorf("Expected an existing key to be kept, got code %d:\n%s", code, output)
+	}
+
+	rootCmd.SetIn(strings.NewReader("secret\n"))
+	output, code = runCLI(t, "encrypt-password")
+	if code != exitOK || !strings.HasPrefix(output, "v1:") || strings.Contains(output, "secret") {
+		t.Errorf("Expected an encrypted password, got code %d:\n%s", code, output)
+	}
+
+	address := startTestRCONServer(t, "secret", func(string) string { return "" })
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if _, code := runCLI(t, "servers", "add", "local", "--config", configFile, "--address", address, "--password", "old"); code != exitOK {
+		t.Fatalf("Failed to add profile")
+	}
+	rootCmd.SetIn(strings.NewReader("secret\n"))
+	if output, code := runCLI(t, "encrypt-password", "local", "--config", configFile); code != exitOK {
+		t.Fatalf("encrypt-password failed with code %d:\n%s", code, output)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load saved config: %v", err)
+	}
+	if p := cfg.Profiles[0]; p.Password != "" || p.PasswordEncrypted == "" {
+		t.Errorf("Expected the password replaced by an encrypted one, got %+v", p)
+	}
+	if output, code := runCLI(t, "servers", "test", "local", "--config", configFile); code != exitOK {
+		t.Errorf("Expected the profile to authenticate with the decrypted password, got code %d:\n%s", code, output)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/spf13/cobra"
+)
+
+// execTarget holds the connection flags of the exec command.
+var execTarget targetFlags
+
+// execCmd runs a single RCON command and exits, so the binary can be used
+// as a standalone RCON client.
+var execCmd = &cobra.Command{
+	Use:   "exec [flags] <command>",
+	Short: "Run one RCON command and print the response",
+	Long: `Connect to an RCON server, authenticate, run one command and print the
+server's response to stdout.
+
+The server is given either with --address and --password or by naming a
+profile from the config file with --profile. Flags override profile values.
+
+Exit codes:
+  0  the command ran successfully
+  1  usage or configuration error
+  2  authentication failed
+  3  the server could not be reached
+  4  the command failed or was rejected by the server
+  5  the config file's policies do not allow the command
+
+Examples:
+  rcon-mcp-server exec --address localhost:25575 --password secret list
+  rcon-mcp-server exec --config servers.json --profile survival "say Restarting soon"`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := execTarget.resolve()
+		if err != nil {
+			return err
+		}
+		command := strings.Join(args, " ")
+		if err := t.allow(command); err != nil {
+			return err
+		}
+
+		client, err := t.dial()
+		if err != nil {
+			return err
+		}
+		defer client.Disconnect()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		output, err := client.ExecuteContext(ctx, command)
+		if err != nil {
+			return withExitCode(exitCommandError, fmt.Errorf("failed to execute command: %w", err))
+		}
+
+		fmt.Fprint(cmd.OutOrStdout(), output)
+		if output != "" && !strings.HasSuffix(output, "\n") {
+			fmt.Fprintln(cmd.OutOrStdout())
+		}
+
+		if game.Rejected(t.Game, output) {
+			return withExitCode(exitCommandError, errors.New("command rejected by server"))
+		}
+		return nil
+	},
+}
+
+// init registers the exec command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(execCmd)
+	execTarget.register(execCmd)
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcontest"
+	"github.com/spf13/pflag"
+)
+
+// startTestRCONServer starts an RCON server on a random local port that
+// accepts password and answers each command with respond(command).
+func startTestRCONServer(t *testing.T, password string, respond func(command string) string) string {
+	t.Helper()
+	return rcontest.NewServer(t, password, rcontest.Respond(respond)).Addr()
+}
+
+// runCLI executes the root command with args after resetting flag state
+// shared between tests, returning the combined output and the exit code.
+func runCLI(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	execTarget = targetFlags{}
+	shellTarget = targetFlags{}
+	shellNoColor = false
+	serversAddProfile = config.Profile{}
+	versionJSON = false
+	healthcheckTags = nil
+	daemonPidfile = ""
+	batchTarget = targetFlags{}
+	batchStopOnError = false
+	watchTarget = targetFlags{}
+	watchInterval = 2 * time.Second
+	watchCount = 0
+	watchNoColor = false
+	loadtestTarget = targetFlags{}
+	loadtestFlags = loadtestOptions{concurrency: 10, duration: 30 * time.Second}
+	conformanceTarget = targetFlags{}
+	conformanceFlags = conformanceOptions{command: "status", largeCommand: "help", timeout: 5 * time.Second}
+	toolsCallArgs, toolsCallPreload = "{}", ""
+	queryJSON = false
+	querySourcePlayers, querySourceRules = false, false
+	serveReadOnly = false
+	serveQuiet = false
+	serveGamePacks = nil
+	servePreload = ""
+	servePath = config.DefaultPath
+	mcpConfigFormat = mcpFormatClaude
+	mcpConfigName = "rcon"
+	configOutput = ""
+	configReplace = false
+	logsFlags = logsOptions{}
+	encryptGenerateKey = false
+	encryptKeyring = false
+	logFlags = config.Logging{Level: "info", Format: config.LogFormatText}
+	rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) { f.Changed = false })
+	configPath = ""
+
+	var buf bytes.Buffer
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	err := rootCmd.Execute()
+	return buf.String(), exitCode(err)
+}
+
+func TestExecCommand(t *testing.T) {
+	address := startTestRCONServer(t, "secret", func(command string) string {
+		switch command {
+		case "list":
+			return "There are 0 of a max of 20 players online:"
+		case "bogus":
+			return "Unknown or incomplete command, see below for error"
+		}
+		return "ok: " + command
+	})
+
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	profiles := `{"profiles": [{"name": "local", "address": "` + address + `", "password": "secret", "game": "minecraft"},
+		{"name": "viewer", "address": "` + address + `", "password": "secret", "role": "viewer"}],
+		"policies": {"deny": ["stop"]}}`
+	if err := os.WriteFile(configFile, []byte(profiles), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput string
+		wantCode   int
+	}{
+		{
+			name:       "address and password",
+			args:       []string{"exec", "--address", address, "--password", "secret", "list"},
+			wantOutput: "There are 0 of a max of 20 players online:\n",
+			wantCode:   exitOK,
+		},
+		{
+			name:       "profile with multi-word command",
+			args:       []string{"exec", "--config", configFile, "--profile", "local", "say", "hello", "world"},
+			wantOutput: "ok: say hello world",
+			wantCode:   exitOK,
+		},
+		{
+			name:       "wrong password",
+			args:       []string{"exec", "--address", address, "--password", "wrong", "list"},
+			wantOutput: "invalid password",
+			wantCode:   exitAuthFailure,
+		},
+		{
+			name:       "unreachable server",
+			args:       []string{"exec", "--address", "127.0.0.1:1", "--password", "secret", "list"},
+			wantOutput: "failed to connect",
+			wantCode:   exitConnectError,
+		},
+		{
+			name:       "rejected command",
+			args:       []string{"exec", "--config", configFile, "--profile", "local", "bogus"},
+			wantOutput: "command rejected by server",
+			wantCode:   exitCommandError,
+		},
+		{
+			name:       "embedded newline",
+			args:       []string{"exec", "--address", address, "--password", "secret", "list\nstop"},
+			wantOutput: "invalid command: control character U+000A",
+			wantCode:   exitFailure,
+		},
+		{
+			name:       "denied by policy",
+			args:       []string{"exec", "--config", configFile, "--address", address, "--password", "secret", "/stop"},
+			wantOutput: `command "stop" is not allowed`,
+			wantCode:   exitPolicyDenied,
+		},
+		{
+			name:       "chained command denied by policy",
+			args:       []string{"exec", "--config", configFile, "--address", address, "--password", "secret", "list;stop"},
+			wantOutput: `command "stop" is not allowed`,
+			wantCode:   exitPolicyDenied,
+		},
+		{
+			name:       "role allows query",
+			args:       []string{"exec", "--config", configFile, "--profile", "viewer", "list"},
+			wantOutput: "There are 0 of a max of 20 players online:\n",
+			wantCode:   exitOK,
+		},
+		{
+			name:       "denied by role",
+			args:       []string{"exec", "--config", configFile, "--profile", "viewer", "say", "hi"},
+			wantOutput: `command "say" is not allowed for the viewer role of profile viewer`,
+			wantCode:   exitPolicyDenied,
+		},
+		{
+			name:       "missing address",
+			args:       []string{"exec", "list"},
+			wantOutput: "an address or profile is required",
+			wantCode:   exitFailure,
+		},
+		{
+			name:       "missing command",
+			args:       []string{"exec", "--address", address},
+			wantOutput: "requires at least 1 arg",
+			wantCode:   exitFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, code := runCLI(t, tt.args...)
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d (output: %q)", tt.wantCode, code, output)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("Expected output to contain %q, got %q", tt.wantOutput, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import "errors"
+
+// Process exit codes returned by commands that talk to RCON servers. They
+// are part of the documented CLI interface, so scripts can branch on the
+// kind of failure; never renumber them.
+const (
+	exitOK           = 0 // Success
+	exitFailure      = 1 // Usage, configuration or other errors
+	exitAuthFailure  = 2 // The server rejected the password
+	exitConnectError = 3 // The server could not be reached
+	exitCommandError = 4 // The command failed or was rejected by the server
+	exitPolicyDenied = 5 // The config file's policies do not allow the command
+)
+
+// exitError is an error that carries the process exit code to use for it.
+type exitError struct {
+	code int   // Process exit code
+	err  error // Underlying error, printed to stderr
+}
+
+// Error implements the error interface.
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode wraps err so that the process exits with code.
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the process exit code for an error returned by a command.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return exitFailure
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "nil", err: nil, want: exitOK},
+		{name: "plain error", err: errors.New("boom"), want: exitFailure},
+		{name: "coded error", err: withExitCode(exitAuthFailure, errors.New("bad password")), want: exitAuthFailure},
+		{name: "wrapped coded error", err: fmt.Errorf("exec: %w", withExitCode(exitConnectError, errors.New("refused"))), want: exitConnectError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	if withExitCode(exitCommandError, nil) != nil {
+		t.Error("Expected withExitCode to keep nil errors nil")
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// healthcheckTags restricts the healthcheck to profiles carrying one of these tags.
+var healthcheckTags []string
+
+// healthcheckCmd checks every configured server in parallel.
+var healthcheckCmd = &cobra.Command{
+	Use:   "healthcheck",
+	Short: "Check that every configured server is reachable and accepts its password",
+	Long: `Connect and authenticate to every profile in the config file in parallel
+and print a table of the results. The command exits with a non-zero code if
+any server is down, so it can be run from cron or a monitoring system.
+
+The exit code is that of the first failing profile in name order, using
+the same scheme as exec and batch:
+  0  every server is up
+  1  usage or configuration error
+  2  authentication failed
+  3  the server could not be reached
+
+Examples:
+  rcon-mcp-server healthcheck --config servers.json
+  rcon-mcp-server healthcheck --tag prod`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		var profiles []*config.Profile
+		for _, p := range cfg.SortedProfiles() {
+			if len(healthcheckTags) == 0 || slices.ContainsFunc(p.Tags, func(tag string) bool { return slices.Contains(healthcheckTags, tag) }) {
+				profiles = append(profiles, p)
+			}
+		}
+		if len(profiles) == 0 {
+			return errors.New("no profiles to check")
+		}
+
+		results := make([]checkResult, len(profiles))
+		var wg sync.WaitGroup
+		for i, p := range profiles {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = checkProfile(p.Name)
+			}()
+		}
+		wg.Wait()
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tADDRESS\tSTATUS\tLATENCY\tERROR")
+		var failure error
+		for i, r := range results {
+			status, latency, message := "up", r.latency.Round(time.Millisecond).String(), ""
+			if r.err != nil {
+				status, latency, message = "down", "-", r.err.Error()
+				if failure == nil {
+					failure = r.err
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", profiles[i].Name, profiles[i].Address, status, latency, message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if failure != nil {
+			down := 0
+			for _, r := range results {
+				if r.err != nil {
+					down++
+				}
+			}
+			return withExitCode(exitCode(failure), fmt.Errorf("%d of %d servers down", down, len(results)))
+		}
+		return nil
+	},
+}
+
+// init registers the healthcheck command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(healthcheckCmd)
+	healthcheckCmd.Flags().StringSliceVar(&healthcheckTags, "tag", nil, "only check profiles with this tag; may be repeated")
+}
+
+// checkResult is the outcome of connecting to one server.
+type checkResult struct {
+	latency time.Duration // Time taken to connect and authenticate
+	err     error         // Why the check failed, carrying its exit code
+}
+
+// checkProfile connects and authenticates to the named profile's server,
+// then disconnects.
+func checkProfile(name string) checkResult {
+	t, err := (&targetFlags{profile: name}).resolve()
+	if err != nil {
+		return checkResult{err: err}
+	}
+
+	start := time.Now()
+	client, err := t.dial()
+	if err != nil {
+		return checkResult{err: err}
+	}
+	latency := time.Since(start)
+	_ = client.Disconnect()
+	return checkResult{latency: latency}
+}
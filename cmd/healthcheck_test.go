@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHealthcheckCommand(t *testing.T) {
+	address := startTestRCONServer(t, "secret", func(string) string { return "" })
+
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	profiles := `{"profiles": [
+		{"name": "good", "address": "` + address + `", "password": "secret", "tags": ["prod"]},
+		{"name": "badpass", "address": "` + address + `", "password": "wrong", "tags": ["dev"]},
+		{"name": "offline", "address": "127.0.0.1:1", "password": "secret", "tags": ["dev"]}
+	]}`
+	if err := os.WriteFile(configFile, []byte(profiles), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput []string
+		wantAbsent []string
+		wantCode   int
+	}{
+		{
+			name:       "all profiles",
+			args:       []string{"healthcheck", "--config", configFile},
+			wantOutput: []string{"good", "up", "badpass", "down", "invalid password", "offline", "2 of 3 servers down"},
+			wantCode:   exitAuthFailure,
+		},
+		{
+			name:       "healthy tag",
+			args:       []string{"healthcheck", "--config", configFile, "--tag", "prod"},
+			wantOutput: []string{"good", "up"},
+			wantAbsent: []string{"badpass", "offline"},
+			wantCode:   exitOK,
+		},
+		{
+			name:       "no matching profiles",
+			args:       []string{"healthcheck", "--config", configFile, "--tag", "missing"},
+			wantOutput: []string{"no profiles to check"},
+			wantCode:   exitFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, code := runCLI(t, tt.args...)
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d; output:\n%s", tt.wantCode, code, output)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(output, absent) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", absent, output)
+				}
+			}
+		})
+	}
+}
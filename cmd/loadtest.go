@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/signal"
+	"slices"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/spf13/cobra"
+)
+
+// loadtestTarget holds the connection flags of the loadtest command.
+var loadtestTarget targetFlags
+
+// loadtestFlags holds the other flags of the loadtest command.
+var loadtestFlags loadtestOptions
+
+// loadtestOptions are the parameters of a load test.
+type loadtestOptions struct {
+	command     string        // Command every worker sends
+	concurrency int           // Workers sending commands at once
+	duration    time.Duration // How long the workers keep sending
+	connections int           // Connections the commands are spread over, 0 for the profile's pool
+	json        bool          // Print the report as JSON
+}
+
+// loadtestCmd drives a server with a command from many workers at once.
+var loadtestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Measure the throughput and latency of an RCON server under load",
+	Long: `Send one command to an RCON server from many workers at once for a fixed
+time, and report the throughput and latency percentiles reached. Commands
+are spread over a pool of connections as an MCP server session would: the
+profile's "pool" unless --connections sets another size, and a single
+connection without either. Run it before pointing agents at a production
+fleet to see what the server, and the path to it, can sustain.
+
+Every command is sent for real, so prefer a query such as list or status;
+the config file's policies and the profile's role apply as for exec.
+Ctrl-C stops the test early and reports what was measured so far. The
+command exits with 4 if any command failed.
+
+Examples:
+  rcon-mcp-server loadtest --profile prod --concurrency 20 --duration 60s --command list
+  rcon-mcp-server loadtest --address localhost:27015 --password secret --connections 4 --command status --json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := loadtestFlags
+		if opts.concurrency < 1 {
+			return errors.New("--concurrency must be at least 1")
+		}
+		if opts.duration <= 0 {
+			return errors.New("--duration must be positive")
+		}
+		if opts.connections < 0 || opts.connections > config.MaxPool {
+			return fmt.Errorf("--connections must be between 1 and %d", config.MaxPool)
+		}
+
+		t, err := loadtestTarget.resolve()
+		if err != nil {
+			return err
+		}
+		if err := t.allow(opts.command); err != nil {
+			return err
+		}
+		if opts.connections == 0 {
+			opts.connections = max(t.Pool, 1)
+		}
+
+		sm := rcon.NewSessionManager()
+		session, err := sm.CreateSession("loadtest", t.Name, t.Address)
+		if err != nil {
+			return err
+		}
+		defer sm.DisconnectAll()
+		if err := t.open(session.Client); err != nil {
+			return err
+		}
+		if err := session.OpenPool(opts.connections, t.Password); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: opened %d of %d connections: %v\n", session.Connections(), opts.connections, err)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		report := runLoadtest(ctx, session, opts)
+		report.Target = t.Name
+		if opts.json {
+			err = printJSON(cmd.OutOrStdout(), report)
+		} else {
+			err = report.print(cmd.OutOrStdout())
+		}
+		if err != nil {
+			return err
+		}
+		if report.Failed > 0 {
+			return withExitCode(exitCommandError, fmt.Errorf("%d of %d commands failed", report.Failed, report.Commands))
+		}
+		return nil
+	},
+}
+
+// init registers the loadtest command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(loadtestCmd)
+	loadtestTarget.register(loadtestCmd)
+	flags := loadtestCmd.Flags()
+	flags.StringVar(&loadtestFlags.command, "command", "", "RCON command every worker sends, e.g. list")
+	flags.IntVar(&loadtestFlags.concurrency, "concurrency", 10, "workers sending commands at once")
+	flags.DurationVar(&loadtestFlags.duration, "duration", 30*time.Second, "how long to keep sending commands")
+	flags.IntVar(&loadtestFlags.connections, "connections", 0, "connections to spread the commands over (default: the profile's pool, or 1)")
+	flags.BoolVar(&loadtestFlags.json, "json", false, "print the report as JSON")
+	_ = loadtestCmd.MarkFlagRequired("command")
+}
+
+// loadtestReport is the outcome of a load test. Latencies are in
+// milliseconds, with fractions, since a server on the same host answers
+// in well under one.
+type loadtestReport struct {
+	Target      string         `json:"target"`           // Profile name, or the address when no profile is used
+	Command     string         `json:"command"`          // Command sent
+	Concurrency int            `json:"concurrency"`      // Workers sending commands at once
+	Connections int            `json:"connections"`      // Connections the commands were spread over
+	Seconds     float64        `json:"seconds"`          // How long the test ran
+	Commands    int            `json:"commands"`         // Commands that completed, successfully or not
+	Failed      int            `json:"failed"`           // Commands that returned an error
+	Throughput  float64        `json:"throughput"`       // Completed commands per second
+	LatencyMin  float64        `json:"latency_min_ms"`   // Fastest command
+	LatencyP50  float64        `json:"latency_p50_ms"`   // Median latency
+	LatencyP90  float64        `json:"latency_p90_ms"`   // 90th percentile latency
+	LatencyP99  float64        `json:"latency_p99_ms"`   // 99th percentile latency
+	LatencyMax  float64        `json:"latency_max_ms"`   // Slowest command
+	Errors      map[string]int `json:"errors,omitempty"` // Number of failures by error message
+}
+
+// runLoadtest sends opts.command on session from opts.concurrency workers
+// until opts.duration has passed or ctx is cancelled, and reports how the
+// commands went. Commands cut short by the end of the test are not counted,
+// and a worker stops early once the session's connection is lost.
+func runLoadtest(ctx context.Context, session *rcon.Session, opts loadtestOptions) *loadtestReport {
+	ctx, cancel := context.WithTimeout(ctx, opts.duration)
+	defer cancel()
+
+	var mu sync.Mutex
+	var latencies []time.Duration
+	errs := make(map[string]int)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range opts.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var own []time.Duration
+			for ctx.Err() == nil {
+				sent := time.Now()
+				_, _, err := session.Execute(ctx, opts.command)
+				if err != nil && ctx.Err() != nil {
+					break
+				}
+				own = append(own, time.Since(sent))
+				if err != nil {
+					mu.Lock()
+					errs[err.Error()]++
+					mu.Unlock()
+				}
+				if errors.Is(err, rcon.ErrNotConnected) {
+					// Every later command would fail the same way at once
+					break
+				}
+			}
+			mu.Lock()
+			latencies = append(latencies, own...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	report := &loadtestReport{
+		Command:     opts.command,
+		Concurrency: opts.concurrency,
+		Connections: session.Connections(),
+		Seconds:     elapsed.Seconds(),
+		Commands:    len(latencies),
+		Throughput:  float64(len(latencies)) / elapsed.Seconds(),
+	}
+	for _, n := range errs {
+		report.Failed += n
+	}
+	if len(errs) > 0 {
+		report.Errors = errs
+	}
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		report.LatencyMin = millis(latencies[0])
+		report.LatencyP50 = millis(rcon.Percentile(latencies, 50))
+		report.LatencyP90 = millis(rcon.Percentile(latencies, 90))
+		report.LatencyP99 = millis(rcon.Percentile(latencies, 99))
+		report.LatencyMax = millis(latencies[len(latencies)-1])
+	}
+	return report
+}
+
+// millis converts d to fractional milliseconds.
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// print writes the report as text, with the most frequent errors first.
+func (r *loadtestReport) print(w io.Writer) error {
+	fields := [][2]string{
+		{"Target", r.Target},
+		{"Command", r.Command},
+		{"Workers", fmt.Sprintf("%d over %d connections", r.Concurrency, r.Connections)},
+		{"Duration", (time.Duration(r.Seconds * float64(time.Second))).Round(time.Millisecond).String()},
+		{"Commands", fmt.Sprintf("%d (%d failed)", r.Commands, r.Failed)},
+		{"Throughput", fmt.Sprintf("%.1f commands/s", r.Throughput)},
+	}
+	if r.Commands > 0 {
+		fields = append(fields, [2]string{"Latency", fmt.Sprintf("min %.2fms, p50 %.2fms, p90 %.2fms, p99 %.2fms, max %.2fms",
+			r.LatencyMin, r.LatencyP50, r.LatencyP90, r.LatencyP99, r.LatencyMax)})
+	}
+	if err := printFields(w, fields); err != nil {
+		return err
+	}
+
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(r.Errors))
+	for msg := range r.Errors {
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if r.Errors[messages[i]] != r.Errors[messages[j]] {
+			return r.Errors[messages[i]] > r.Errors[messages[j]]
+		}
+		return messages[i] < messages[j]
+	})
+	fmt.Fprintln(w, "Errors:")
+	for _, msg := range messages {
+		fmt.Fprintf(w, "  %6d  %s\n", r.Errors[msg], msg)
+	}
+	return nil
+}
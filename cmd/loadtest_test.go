@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadtestCommand(t *testing.T) {
+	address := startTestRCONServer(t, "secret", func(command string) string {
+		return "There are 0 of a max of 20 players online:"
+	})
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	profiles := `{"profiles": [{"name": "local", "address": "` + address + `", "password": "secret", "pool": 2}],
+		"policies": {"deny": ["stop"]}}`
+	if err := os.WriteFile(configFile, []byte(profiles), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	output, code := runCLI(t, "loadtest", "--config", configFile, "--profile", "local",
+		"--concurrency", "4", "--duration", "200ms", "--command", "list", "--json")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, output)
+	}
+	var report loadtestReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("Expected a JSON report, got %q: %v", output, err)
+	}
+	if report.Target != "local" || report.Concurrency != 4 || report.Connections != 2 {
+		t.Errorf("Expected 4 workers over the profile's 2 connections to local, got %+v", report)
+	}
+	if report.Commands == 0 || report.Failed != 0 || report.Throughput <= 0 {
+		t.Errorf("Expected commands to succeed, got %+v", report)
+	}
+	if report.LatencyP50 > report.LatencyP99 || report.LatencyMin > report.LatencyMax {
+		t.Errorf("Expected ordered latency percentiles, got %+v", report)
+	}
+
+	output, code = runCLI(t, "loadtest", "--address", address, "--password", "secret",
+		"--concurrency", "2", "--duration", "50ms", "--command", "list")
+	if code != exitOK || !strings.Contains(output, "2 over 1 connections") || !strings.Contains(output, "commands/s") {
+		t.Errorf("Expected a text report over one connection, got %d: %s", code, output)
+	}
+
+	if output, code := runCLI(t, "loadtest", "--config", configFile, "--profile", "local", "--command", "stop"); code != exitPolicyDenied {
+		t.Errorf("Expected a denied command to exit with %d, got %d: %s", exitPolicyDenied, code, output)
+	}
+}
+
+func TestLoadtestReport_Print(t *testing.T) {
+	report := &loadtestReport{
+		Target: "prod", Command: "list", Concurrency: 2, Connections: 1, Seconds: 1,
+		Commands: 5, Failed: 3, Throughput: 5,
+		Errors: map[string]int{"i/o timeout": 1, "connection reset": 2},
+	}
+	var b strings.Builder
+	if err := report.print(&b); err != nil {
+		t.Fatalf("print failed: %v", err)
+	}
+	out := b.String()
+	if !strings.Contains(out, "5 (3 failed)") {
+		t.Errorf("Expected the failure count, got:\n%s", out)
+	}
+	if strings.Index(out, "connection reset") > strings.Index(out, "i/o timeout") {
+		t.Errorf("Expected the most frequent error first, got:\n%s", out)
+	}
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// logsOptions are the filters and output settings of the logs command.
+type logsOptions struct {
+	file    string
+	session string
+	client  string
+	request string
+	event   string
+	command string
+	since   time.Duration
+	errors  bool
+	limit   int
+	json    bool
+
+	unchained bool
+	trimmed   bool
+}
+
+// logsFlags holds the flags of the logs command.
+var logsFlags logsOptions
+
+// logsCmd queries the audit log written by the server.
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Query the audit log of commands run on servers",
+	Long: `Print the entries of the audit log, the record of every connect, execute
+and disconnect the server performed, oldest first. Rotated audit files are
+included. The log is the "audit" "file" of the config file unless --file
+is given.
+
+Examples:
+  rcon-mcp-server logs --config config.json --session survival --since 1h
+  rcon-mcp-server logs --file /var/log/rcon-audit.jsonl --command ban --limit 20
+  rcon-mcp-server logs --errors --json | jq .
+  rcon-mcp-server logs verify --file /var/log/rcon-audit.jsonl`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := auditPath()
+		if err != nil {
+			return err
+		}
+
+		entries, err := audit.Read(path, logsFilter(time.Now()))
+		if err != nil {
+			return err
+		}
+		if logsFlags.limit > 0 && len(entries) > logsFlags.limit {
+			entries = entries[len(entries)-logsFlags.limit:]
+		}
+
+		if logsFlags.json {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			for _, e := range entries {
+				if err := enc.Encode(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return printAuditEntries(cmd, entries)
+	},
+}
+
+// logsVerifyCmd checks the hash chain of the audit log.
+var logsVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that the audit log has not been edited",
+	Long: `Check the hash chain of the audit log, including its rotated files. Each
+entry records a hash of itself and of the entry before it, so editing,
+inserting or removing entries breaks the chain. The command names the
+first entry out of chain and exits with 1 if the log has been tampered
+with.
+
+The log must start the chain. Entries without a hash, written by versions
+from before chaining, are only accepted at its start with --unchained, and
+a first entry following one that rotation removed only with --trimmed:
+both also accept a log whose oldest entries were stripped or removed.
+
+Removing the latest entries leaves a shorter chain that still verifies.
+Keep the printed head hash somewhere safe: if a later head no longer
+follows from it, entries were removed.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := auditPath()
+		if err != nil {
+			return err
+		}
+		v, err := audit.Verify(path, audit.VerifyOptions{Unchained: logsFlags.unchained, Trimmed: logsFlags.trimmed})
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "audit log intact: %d entries in %d files\n", v.Entries, v.Files)
+		if v.Unchained > 0 {
+			fmt.Fprintf(out, "%d older entries predate hash chaining and cannot be verified\n", v.Unchained)
+		}
+		if v.Head != "" {
+			fmt.Fprintf(out, "head: %s\n", v.Head)
+		}
+		return nil
+	},
+}
+
+// auditPath returns the audit log to read: the --file flag, or else the
+// audit file of the config file.
+func auditPath() (string, error) {
+	path := logsFlags.file
+	if path == "" {
+		cfg, err := loadConfig()
+		if err != nil {
+			return "", err
+		}
+		path = cfg.Audit.File
+	}
+	if path == "" {
+		return "", errors.New("no audit log configured; set audit.file in the config file or use --file")
+	}
+	return path, nil
+}
+
+// logsFilter returns the predicate selecting the entries the flags ask for.
+func logsFilter(now time.Time) func(audit.Entry) bool {
+	f := logsFlags
+	return func(e audit.Entry) bool {
+		switch {
+		case f.session != "" && e.SessionID != f.session,
+			f.client != "" && !strings.Contains(e.Client, f.client) && e.ClientID != f.client,
+			f.request != "" && e.RequestID != f.request,
+			f.event != "" && e.Event != f.event,
+			f.command != "" && !strings.EqualFold(config.CommandName(e.Command), config.CommandName(f.command)),
+			f.since > 0 && e.Time.Before(now.Add(-f.since)),
+			f.errors && e.Error == "":
+			return false
+		}
+		return true
+	}
+}
+
+// printAuditEntries writes entries as a table.
+func printAuditEntries(cmd *cobra.Command, entries []audit.Entry) error {
+	if len(entries) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no matching audit entries")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tEVENT\tSESSION\tCLIENT\tCOMMAND\tLATENCY\tRESULT")
+	for _, e := range entries {
+		client := e.Client
+		if client == "" {
+			client = "-"
+		}
+		latency := "-"
+		if e.Event != audit.EventDisconnect {
+			latency = fmt.Sprintf("%dms", e.LatencyMillis)
+		}
+		result := "ok"
+		if e.Error != "" {
+			result = "error: " + e.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format(time.DateTime), e.Event,
+			e.SessionID, client, e.Command, latency, result)
+	}
+	return w.Flush()
+}
+
+// init registers the logs command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.AddCommand(logsVerifyCmd)
+	logsCmd.PersistentFlags().StringVar(&logsFlags.file, "file", "", "audit log to read instead of the configured one")
+	flags := logsCmd.Flags()
+	flags.StringVar(&logsFlags.session, "session", "", "only entries of this session ID")
+	flags.StringVar(&logsFlags.client, "client", "", "only entries of MCP clients whose name contains this, or with this client ID")
+	flags.StringVar(&logsFlags.request, "request", "", "only entries of the tool call with this request ID")
+	flags.StringVar(&logsFlags.event, "event", "", "only entries of this event: connect, execute or disconnect")
+	flags.StringVar(&logsFlags.command, "command", "", "only executions of this command, matched by its first word ignoring case")
+	flags.DurationVar(&logsFlags.since, "since", 0, "only entries newer than this, e.g. 30m or 24h")
+	flags.BoolVar(&logsFlags.errors, "errors", false, "only entries that failed")
+	flags.IntVarP(&logsFlags.limit, "limit", "n", 0, "print only the latest n entries")
+	flags.BoolVar(&logsFlags.json, "json", false, "print the entries as JSON lines")
+	logsVerifyCmd.Flags().BoolVar(&logsFlags.unchained, "unchained", false, "accept entries without a hash at the start of the log, written before chaining")
+	logsVerifyCmd.Flags().BoolVar(&logsFlags.trimmed, "trimmed", false, "accept a log whose oldest file rotation removed")
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+)
+
+func TestLogsCommand(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(config.EnvPath, "")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	l, err := audit.Open(path, 1<<20, 1)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	now := time.Now()
+	for _, e := range []audit.Entry{
+		{Time: now.Add(-2 * time.Hour), Event: audit.EventConnect, SessionID: "old", Client: "claude 1.0", ClientID: "c1"},
+		{Time: now.Add(-time.Minute), Event: audit.EventExecute, SessionID: "s1", Command: "ban griefer", LatencyMillis: 7, Client: "claude 1.0", RequestID: "r1"},
+		{Time: now.Add(-time.Minute), Event: audit.EventExecute, SessionID: "s1", Command: "stop", Error: "denied", Client: "cursor"},
+		{Time: now, Event: audit.EventDisconnect, SessionID: "s1"},
+	} {
+		if err := l.Write(e); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	l.Close()
+
+	configFile := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configFile, []byte(fmt.Sprintf(`{"audit": {"file": %q}}`, path)), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantOutput []string
+		wantAbsent []string
+	}{
+		{
+			name:       "all entries from the configured log",
+			args:       []string{"logs", "--config", configFile},
+			wantCode:   exitOK,
+			wantOutput: []string{"TIME", "connect", "ban griefer", "7ms", "error: denied", "disconnect"},
+		},
+		{
+			name:       "filters",
+			args:       []string{"logs", "--file", path, "--session", "s1", "--command", "BAN", "--since", "1h"},
+			wantCode:   exitOK,
+			wantOutput: []string{"ban griefer", "claude 1.0"},
+			wantAbsent: []string{"stop", "connect"},
+		},
+		{
+			name:       "client and errors",
+			args:       []string{"logs", "--file", path, "--client", "cursor", "--errors"},
+			wantCode:   exitOK,
+			wantOutput: []string{"stop"},
+			wantAbsent: []string{"ban"},
+		},
+		{
+			name:       "request",
+			args:       []string{"logs", "--file", path, "--request", "r1"},
+			wantCode:   exitOK,
+			wantOutput: []string{"ban griefer"},
+			wantAbsent: []string{"stop", "connect"},
+		},
+		{
+			name:       "limit as json",
+			args:       []string{"logs", "--file", path, "-n", "1", "--json"},
+			wantCode:   exitOK,
+			wantOutput: []string{`"event":"disconnect"`},
+			wantAbsent: []string{"ban", "TIME"},
+		},
+		{
+			name:       "no match",
+			args:       []string{"logs", "--file", path, "--event", "connect", "--since", "1h"},
+			wantCode:   exitOK,
+			wantOutput: []string{"no matching audit entries"},
+		},
+		{
+			name:       "no audit log configured",
+			args:       []string{"logs"},
+			wantCode:   exitFailure,
+			wantOutput: []string{"no audit log configured"},
+		},
+		{
+			name:       "missing file",
+			args:       []string{"logs", "--file", filepath.Join(dir, "missing.jsonl")},
+			wantCode:   exitFailure,
+			wantOutput: []string{"no audit log at"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, code := runCLI(t, tt.args...)
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d; output:\n%s", code, tt.wantCode, output)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(output, absent) {
+					t.Errorf("Output must not contain %q, got:\n%s", absent, output)
+				}
+			}
+		})
+	}
+}
+
+func TestLogsVerifyCommand(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(config.EnvPath, "")
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := audit.Open(path, 1<<20, 1)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for _, command := range []string{"list", "ban griefer", "stop"} {
+		if err := l.Write(audit.Entry{Time: time.Now(), Event: audit.EventExecute, SessionID: "s1", Command: command}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	l.Close()
+
+	output, code := runCLI(t, "logs", "verify", "--file", path)
+	if code != exitOK || !strings.Contains(output, "audit log intact: 3 entries in 1 files") || !strings.Contains(output, "head: ") {
+		t.Fatalf("Expected an intact log, got code %d:\n%s", code, output)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	edited := strings.Replace(string(data), "ban griefer", "ban nobody1", 1)
+	if err := os.WriteFile(path, []byte(edited), 0o600); err != nil {
+		t.Fatalf("Failed to edit log: %v", err)
+	}
+	output, code = runCLI(t, "logs", "verify", "--file", path)
+	if code != exitFailure || !strings.Contains(output, "audit.jsonl:2") || !strings.Contains(output, "tampered") {
+		t.Errorf("Expected the edited entry to be reported, got code %d:\n%s", code, output)
+	}
+
+	// Entries without a hash at the start pass only with --unchained.
+	older := `{"time":"2026-01-01T00:00:00Z","event":"connect","session_id":"old"}` + "\n"
+	if err := os.WriteFile(path, append([]byte(older), data...), 0o600); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+	if output, code := runCLI(t, "logs", "verify", "--file", path); code != exitFailure || !strings.Contains(output, "no hash") {
+		t.Errorf("Expected an unchained entry to fail verification, got code %d:\n%s", code, output)
+	}
+	output, code = runCLI(t, "logs", "verify", "--file", path, "--unchained")
+	if code != exitOK || !strings.Contains(output, "1 older entries predate hash chaining") {
+		t.Errorf("Expected --unchained to accept the older entry, got code %d:\n%s", code, output)
+	}
+}
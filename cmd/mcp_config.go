@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// mcpConfigFormat selects the client the mcp-config snippet is written for.
+var mcpConfigFormat string
+
+// mcpConfigName is the server name used as the key in client configurations.
+var mcpConfigName string
+
+// Client configuration formats printed by mcp-config.
+const (
+	mcpFormatClaude  = "claude"  // Claude Desktop's claude_desktop_config.json
+	mcpFormatVSCode  = "vscode"  // VS Code's settings.json
+	mcpFormatGeneric = "generic" // Plain command and arguments
+)
+
+// pathFlags lists the serve flags whose values are file paths, which are
+// made absolute since MCP clients start the server from another directory.
+var pathFlags = []string{"--config", "--preload-sessions", "--log-file", "--pidfile"}
+
+// mcpConfigCmd prints client configuration that starts this server.
+var mcpConfigCmd = &cobra.Command{
+	Use:   "mcp-config [flags] [-- serve flags]",
+	Short: "Print MCP client configuration for this server",
+	Long: `Print a ready-to-paste MCP client configuration that starts this binary
+with "serve", using its absolute path. Flags after -- are passed on to serve,
+and --config is included when given; file paths are made absolute.
+
+Formats:
+  claude   Claude Desktop (claude_desktop_config.json)
+  vscode   VS Code (settings.json)
+  generic  command and arguments, for any other client
+
+Examples:
+  rcon-mcp-server mcp-config --config ~/servers.json
+  rcon-mcp-server mcp-config --format vscode -- --readonly --log-file rcon.log`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		command, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate this binary: %w", err)
+		}
+		if resolved, err := filepath.EvalSymlinks(command); err == nil {
+			command = resolved
+		}
+
+		serveArgs := []string{"serve"}
+		if configPath != "" {
+			serveArgs = append(serveArgs, "--config", configPath)
+		}
+		serveArgs, err = absolutePaths(append(serveArgs, args...))
+		if err != nil {
+			return err
+		}
+
+		server := map[string]any{"command": command, "args": serveArgs}
+		var doc any
+		switch strings.ToLower(mcpConfigFormat) {
+		case mcpFormatClaude:
+			doc = map[string]any{"mcpServers": map[string]any{mcpConfigName: server}}
+		case mcpFormatVSCode:
+			server["type"] = "stdio"
+			doc = map[string]any{"mcp": map[string]any{"servers": map[string]any{mcpConfigName: server}}}
+		case mcpFormatGeneric:
+			server["name"] = mcpConfigName
+			doc = server
+		default:
+			return fmt.Errorf("unknown format %q, want claude, vscode or generic", mcpConfigFormat)
+		}
+		return printJSON(cmd.OutOrStdout(), doc)
+	},
+}
+
+// init registers the mcp-config command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(mcpConfigCmd)
+	mcpConfigCmd.Flags().StringVar(&mcpConfigFormat, "format", mcpFormatClaude, "client format: claude, vscode or generic")
+	mcpConfigCmd.Flags().StringVar(&mcpConfigName, "name", "rcon", "server name in the client configuration")
+}
+
+// absolutePaths returns args with the values of path flags, given either
+// as "--flag value" or "--flag=value", made absolute.
+func absolutePaths(args []string) ([]string, error) {
+	out := make([]string, len(args))
+	copy(out, args)
+	for i := 0; i < len(out); i++ {
+		for _, flag := range pathFlags {
+			var err error
+			switch {
+			case out[i] == flag && i+1 < len(out):
+				i++
+				out[i], err = filepath.Abs(out[i])
+			case strings.HasPrefix(out[i], flag+"="):
+				var path string
+				path, err = filepath.Abs(strings.TrimPrefix(out[i], flag+"="))
+				out[i] = flag + "=" + path
+			default:
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s: %w", flag, err)
+			}
+			break
+		}
+	}
+	return out, nil
+}
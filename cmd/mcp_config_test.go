@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMCPConfigCommand(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	tests := []struct {
+		name     string
+		args     []string
+		path     []string // Keys leading to the server entry
+		wantArgs []string
+	}{
+		{
+			name:     "claude",
+			args:     []string{"mcp-config", "--config", "servers.json"},
+			path:     []string{"mcpServers", "rcon"},
+			wantArgs: []string{"serve", "--config", filepath.Join(dir, "servers.json")},
+		},
+		{
+			name:     "vscode with serve flags",
+			args:     []string{"mcp-config", "--format", "vscode", "--name", "game", "--", "--readonly", "--log-file=rcon.log"},
+			path:     []string{"mcp", "servers", "game"},
+			wantArgs: []string{"serve", "--readonly", "--log-file=" + filepath.Join(dir, "rcon.log")},
+		},
+		{
+			name:     "generic",
+			args:     []string{"mcp-config", "--format", "generic", "--", "--preload-sessions", "/etc/sessions.json"},
+			wantArgs: []string{"serve", "--preload-sessions", "/etc/sessions.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, code := runCLI(t, tt.args...)
+			if code != exitOK {
+				t.Fatalf("Expected exit code 0, got %d; output:\n%s", code, output)
+			}
+
+			var doc map[string]any
+			if err := json.Unmarshal([]byte(output), &doc); err != nil {
+				t.Fatalf("Expected JSON output, got %v:\n%s", err, output)
+			}
+			for _, key := range tt.path {
+				next, ok := doc[key].(map[string]any)
+				if !ok {
+					t.Fatalf("Expected key %q in:\n%s", key, output)
+				}
+				doc = next
+			}
+
+			if command, _ := doc["command"].(string); !filepath.IsAbs(command) {
+				t.Errorf("Expected an absolute command path, got %q", command)
+			}
+			var args []string
+			for _, a := range doc["args"].([]any) {
+				args = append(args, a.(string))
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+
+	output, code := runCLI(t, "mcp-config", "--format", "cursor")
+	if code != exitFailure || !strings.Contains(output, "unknown format") {
+		t.Errorf("Expected unknown format error, got code %d:\n%s", code, output)
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/mockrcon"
+	"github.com/spf13/cobra"
+)
+
+// mockServerFlags holds the flags of the mock-server command.
+var mockServerFlags struct {
+	host     string
+	port     int
+	password string
+	fixture  string
+}
+
+// mockServerCmd runs a fake RCON server with canned responses.
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Run a fake RCON server with canned responses",
+	Long: `Run a fake RCON server that speaks the Source RCON protocol and answers
+commands with canned responses, for local development and demos without a
+real game server. It runs until interrupted.
+
+Fixtures imitate a kind of game server, so game detection and the output
+parsers work against them: ` + strings.Join(mockrcon.FixtureNames(), ", ") + `.
+
+Examples:
+  rcon-mcp-server mock-server --port 25575 --password test --fixture minecraft
+  rcon-mcp-server exec --address localhost:25575 --password test list`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fixture, err := mockrcon.Lookup(mockServerFlags.fixture)
+		if err != nil {
+			return err
+		}
+
+		address := net.JoinHostPort(mockServerFlags.host, strconv.Itoa(mockServerFlags.port))
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
+		}
+
+		server := mockrcon.NewServer(mockServerFlags.password, fixture)
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			_ = server.Close()
+		}()
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Mock %s RCON server listening on %s (password %q)\n",
+			fixture.Name, ln.Addr(), mockServerFlags.password)
+		if err := server.Serve(ln); !errors.Is(err, net.ErrClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+// init registers the mock-server command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(mockServerCmd)
+	flags := mockServerCmd.Flags()
+	flags.StringVar(&mockServerFlags.host, "host", "127.0.0.1", "interface to listen on")
+	flags.IntVar(&mockServerFlags.port, "port", 25575, "port to listen on")
+	flags.StringVar(&mockServerFlags.password, "password", "test", "password clients must authenticate with")
+	flags.StringVar(&mockServerFlags.fixture, "fixture", "minecraft", "canned responses to serve: "+strings.Join(mockrcon.FixtureNames(), ", "))
+}
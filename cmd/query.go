@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/query"
+	"github.com/spf13/cobra"
+)
+
+// queryJSON selects JSON output for the query subcommands.
+var queryJSON bool
+
+// querySourcePlayers and querySourceRules add the A2S_PLAYER and A2S_RULES
+// queries to "query source".
+var querySourcePlayers, querySourceRules bool
+
+// queryCmd groups the subcommands that query servers without RCON.
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Query a game server's public status without RCON",
+	Long: `Query a game server through its public status protocol. No password is
+needed and the RCON port is not used, so this is a quick check that a server
+is up before opening an RCON session.
+
+The address is the game port; when it names no port the protocol's default
+is used (27015 for Source, 25565 for Minecraft). The mcquery subcommand uses
+Minecraft's Query protocol instead, which answers on the query.port of
+server.properties once enable-query is set, and reports plugins and the
+full player list. A server that does not answer exits with 3.
+
+Examples:
+  rcon-mcp-server query source cs.example.com:27015 --players
+  rcon-mcp-server query minecraft mc.example.com --json
+  rcon-mcp-server query mcquery mc.example.com:25565`,
+	Args: cobra.NoArgs,
+}
+
+// querySourceCmd sends an A2S_INFO query, and A2S_PLAYER and A2S_RULES
+// queries when asked to.
+var querySourceCmd = &cobra.Command{
+	Use:          "source <address>",
+	Aliases:      []string{"a2s"},
+	Short:        "Query a Source engine server with A2S_INFO",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		info, err := query.QuerySource(ctx, args[0])
+		if err != nil {
+			return withExitCode(exitConnectError, fmt.Errorf("%s: %w", args[0], err))
+		}
+		result := struct {
+			*query.SourceInfo
+			PlayerList []query.SourcePlayer `json:"player_list,omitempty"`
+			Rules      map[string]string    `json:"rules,omitempty"`
+		}{SourceInfo: info}
+		if querySourcePlayers {
+			if result.PlayerList, err = query.QuerySourcePlayers(ctx, args[0]); err != nil {
+				return withExitCode(exitConnectError, fmt.Errorf("%s: players: %w", args[0], err))
+			}
+		}
+		if querySourceRules {
+			if result.Rules, err = query.QuerySourceRules(ctx, args[0]); err != nil {
+				return withExitCode(exitConnectError, fmt.Errorf("%s: rules: %w", args[0], err))
+			}
+		}
+		if queryJSON {
+			return printJSON(cmd.OutOrStdout(), result)
+		}
+
+		w := cmd.OutOrStdout()
+		if err := printFields(w, [][2]string{
+			{"Name", info.Name},
+			{"Game", fmt.Sprintf("%s (%s, app %d)", info.Game, info.Folder, info.AppID)},
+			{"Map", info.Map},
+			{"Players", fmt.Sprintf("%d/%d (%d bots)", info.Players, info.MaxPlayers, info.Bots)},
+			{"Version", info.Version},
+			{"Server", fmt.Sprintf("%s, %s", info.ServerType, info.Environment)},
+			{"Password", yesNo(info.Password)},
+			{"VAC", yesNo(info.VAC)},
+			{"Keywords", info.Keywords},
+			{"Latency", info.Latency.Round(time.Millisecond).String()},
+		}); err != nil {
+			return err
+		}
+		if querySourcePlayers {
+			fmt.Fprintln(w, "\nPlayers:")
+			for _, p := range result.PlayerList {
+				fmt.Fprintf(w, "  %-32s %6d  %s\n", p.Name, p.Score, time.Duration(p.ConnectedSeconds)*time.Second)
+			}
+		}
+		if querySourceRules {
+			fmt.Fprintln(w, "\nRules:")
+			names := slices.Sorted(maps.Keys(result.Rules))
+			rules := make([][2]string, len(names))
+			for i, name := range names {
+				rules[i] = [2]string{"  " + name, result.Rules[name]}
+			}
+			return printFields(w, rules)
+		}
+		return nil
+	},
+}
+
+// queryMinecraftCmd performs a Minecraft Server List Ping.
+var queryMinecraftCmd = &cobra.Command{
+	Use:          "minecraft <address>",
+	Aliases:      []string{"mc"},
+	Short:        "Ping a Minecraft server with Server List Ping",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		status, err := query.PingMinecraft(ctx, args[0])
+		if err != nil {
+			return withExitCode(exitConnectError, fmt.Errorf("%s: %w", args[0], err))
+		}
+		if queryJSON {
+			return printJSON(cmd.OutOrStdout(), status)
+		}
+		return printFields(cmd.OutOrStdout(), [][2]string{
+			{"MOTD", status.MOTD},
+			{"Version", fmt.Sprintf("%s (protocol %d)", status.Version, status.Protocol)},
+			{"Players", fmt.Sprintf("%d/%d", status.Players, status.Max)},
+			{"Online", strings.Join(status.Sample, ", ")},
+			{"Latency", status.Latency.Round(time.Millisecond).String()},
+		})
+	},
+}
+
+// queryMinecraftFullCmd sends a Minecraft Query full stat request.
+var queryMinecraftFullCmd = &cobra.Command{
+	Use:          "mcquery <address>",
+	Aliases:      []string{"gamespy"},
+	Short:        "Query a Minecraft server with the Query (GameSpy4) protocol",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		stats, err := query.QueryMinecraft(ctx, args[0])
+		if err != nil {
+			return withExitCode(exitConnectError, fmt.Errorf("%s: %w", args[0], err))
+		}
+		if queryJSON {
+			return printJSON(cmd.OutOrStdout(), stats)
+		}
+		return printFields(cmd.OutOrStdout(), [][2]string{
+			{"MOTD", stats.MOTD},
+			{"Version", stats.Version},
+			{"Software", stats.Software},
+			{"Plugins", strings.Join(stats.Plugins, ", ")},
+			{"Map", stats.Map},
+			{"Players", fmt.Sprintf("%d/%d", stats.Players, stats.Max)},
+			{"Online", strings.Join(stats.Online, ", ")},
+			{"Latency", stats.Latency.Round(time.Millisecond).String()},
+		})
+	},
+}
+
+// init registers the query command and its subcommands with the root
+// command during package initialization.
+func init() {
+	rootCmd.AddCommand(queryCmd)
+	queryCmd.AddCommand(querySourceCmd, queryMinecraftCmd, queryMinecraftFullCmd)
+	queryCmd.PersistentFlags().BoolVar(&queryJSON, "json", false, "print the result as JSON")
+	querySourceCmd.Flags().BoolVar(&querySourcePlayers, "players", false, "also list the players with A2S_PLAYER")
+	querySourceCmd.Flags().BoolVar(&querySourceRules, "rules", false, "also list the public console variables with A2S_RULES")
+}
+
+// printJSON writes v as indented JSON.
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printFields writes name/value pairs as an aligned list, skipping empty values.
+func printFields(w io.Writer, fields [][2]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range fields {
+		if f[1] != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", f[0], f[1])
+		}
+	}
+	return tw.Flush()
+}
+
+// yesNo formats a boolean for display.
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestQueryCommandUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := ln.Addr().String()
+	ln.Close()
+
+	output, code := runCLI(t, "query", "minecraft", address)
+	if code != exitConnectError {
+		t.Errorf("Expected exit code %d, got %d; output:\n%s", exitConnectError, code, output)
+	}
+	if !strings.Contains(output, address) {
+		t.Errorf("Expected the error to name %s, got:\n%s", address, output)
+	}
+}
+
+func TestPrintFields(t *testing.T) {
+	var b strings.Builder
+	if err := printFields(&b, [][2]string{{"Name", "Test"}, {"Keywords", ""}, {"Players", "1/10"}}); err != nil {
+		t.Fatalf("printFields failed: %v", err)
+	}
+	want := "Name:     Test\nPlayers:  1/10\n"
+	if b.String() != want {
+		t.Errorf("printFields() = %q, want %q", b.String(), want)
+	}
+}
@@ -3,8 +3,11 @@
 package cmd
 
 import (
+	"io"
 	"os"
 
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -27,25 +30,73 @@ Features:
 
 To start the server, use:
   rcon-mcp-server serve`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupLogging(cmd, config.Logging{})
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-// It returns an error code to the OS on failure.
+// It returns an error code to the OS on failure; commands that talk to RCON
+// servers choose codes that tell failure types apart.
 func Execute() {
 	err := rootCmd.Execute()
+	closeLog()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
+// logFlags holds the --log-level, --log-format and --log-file flags, which
+// override the logging settings of the config file.
+var logFlags config.Logging
+
+// logFile is the log file opened by setupLogging, if any.
+var logFile io.Closer
+
+// configPath is the path of the JSON configuration file given with --config.
+// When empty, the file is looked up on the config search path.
+var configPath string
+
 func init() {
-	// Here you will define your flags and configuration settings.
-	// Cobra supports persistent flags, which, if defined here,
-	// will be global for your application.
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "",
+		"path to a JSON config file (default $RCON_MCP_CONFIG, then $XDG_CONFIG_HOME/rcon-mcp-server/config.json)")
+	rootCmd.PersistentFlags().StringVar(&logFlags.Level, "log-level", "info", "log verbosity: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logFlags.Format, "log-format", config.LogFormatText, "log format: text or json")
+	rootCmd.PersistentFlags().StringVar(&logFlags.File, "log-file", "", "append the log to this file instead of stderr")
+}
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.rcon-mcp-server.yaml)")
+// setupLogging installs the process-wide logger from base, the logging
+// settings of the config file, overridden by the logging flags that were
+// given. It may be called again once the config file has been loaded.
+func setupLogging(cmd *cobra.Command, base config.Logging) error {
+	flags := cmd.Root().PersistentFlags()
+	if flags.Changed("log-level") {
+		base.Level = logFlags.Level
+	}
+	if flags.Changed("log-format") {
+		base.Format = logFlags.Format
+	}
+	if flags.Changed("log-file") {
+		base.File = logFlags.File
+	}
+	if err := (&config.Config{Logging: base}).Validate(); err != nil {
+		return err
+	}
+
+	closer, err := logging.Setup(base)
+	if err != nil {
+		return err
+	}
+	closeLog()
+	logFile = closer
+	return nil
+}
 
-	// Cobra also supports local flags, which will only run
-	// when this action is called directly.
+// closeLog closes the log file opened by setupLogging, if any.
+func closeLog() {
+	if logFile != nil {
+		_ = logFile.Close()
+		logFile = nil
+	}
 }
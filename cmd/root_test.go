@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -82,4 +85,27 @@ func TestExecuteFunction(t *testing.T) {
 	
 	// Note: We can't easily test os.Exit(1) behavior
 	// In a real scenario, we might use a different approach
-}
\ No newline at end of file
+}
+func TestLoggingFlags(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	logPath := filepath.Join(t.TempDir(), "cli.log")
+	if output, code := runCLI(t, "version", "--log-level", "debug", "--log-format", "json", "--log-file", logPath); code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, output)
+	}
+	slog.Debug("after setup")
+	closeLog()
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Expected log file to be created: %v", err)
+	}
+	if !strings.Contains(string(data), `"msg":"after setup"`) {
+		t.Errorf("Expected JSON debug record in log file, got:\n%s", data)
+	}
+
+	output, code := runCLI(t, "version", "--log-level", "chatty")
+	if code != exitFailure || !strings.Contains(output, "unknown level") {
+		t.Errorf("Expected invalid log level to fail, got %d: %s", code, output)
+	}
+}
@@ -1,10 +1,42 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
 	"github.com/mjmorales/rcon-mcp-server/internal/mcp"
 	"github.com/spf13/cobra"
 )
 
+// serveTransport, serveListen and servePath hold the --transport, --listen
+// and --path flags, which override the transport settings of the config file.
+var (
+	serveTransport string
+	serveListen    string
+	servePath      string
+)
+
+// serveReadOnly holds the --readonly flag.
+var serveReadOnly bool
+
+// serveGamePacks holds the --game-pack flags, which add to the game packs
+// of the config file.
+var serveGamePacks []string
+
+// serveQuiet holds the --quiet flag.
+var serveQuiet bool
+
+// servePreload names a sessions file to open at startup.
+var servePreload string
+
+// serveDaemon and servePidfile hold the --daemon and --pidfile flags.
+var (
+	serveDaemon  bool
+	servePidfile string
+)
+
 // serveCmd represents the serve command which starts the MCP server.
 // This command initializes the RCON MCP server and begins listening for connections.
 var serveCmd = &cobra.Command{
@@ -19,14 +51,202 @@ Available tools:
 - rcon_connect: Connect to an RCON server
 - rcon_disconnect: Disconnect from an RCON server
 - rcon_execute: Execute commands on an RCON server
-- rcon_list_sessions: List all active RCON sessions`,
+- rcon_list_sessions: List all active RCON sessions
+- rcon_detect_game: Identify the game running on an RCON server
+- rcon_execute_multi: Execute commands on several RCON sessions concurrently
+- rcon_test_connection: Validate RCON credentials without creating a session
+- rcon_broadcast: Execute a command on every session in a group
+- rcon_group_create, rcon_group_add, rcon_group_remove, rcon_group_list: Manage session groups
+- rcon_list_profiles: List the configured server profiles
+- rcon_execute_script: Run a multi-line script of commands on a session
+- rcon_history: Search the commands previously executed on a session
+- rcon_wait_for: Poll a command until its output matches a pattern
+- rcon_metrics: Report command counts, error rates, latencies and uptime
+- rcon_help: Look up server commands from the cached native help output
+- rcon_quota_status: Report the commands the configured quotas still allow
+- rcon_tail_logs: Read a Source server's live log, with game_logs.listen set
+- rcon_console_stream: Read the console and chat a server pushed unprompted
+
+--game-pack adds the helper tools of a game, e.g. --game-pack minecraft
+for mc_list_players, mc_whitelist_add, mc_whitelist_remove, mc_ban, mc_say,
+mc_save_all, mc_ping and mc_query_full, --game-pack source for
+source_server_info, source_players, source_status, source_changelevel,
+source_exec_config and source_kick, --game-pack factorio for
+factorio_players, factorio_time and factorio_lua, --game-pack ark for
+ark_list_players, ark_save_world, ark_broadcast and ark_exit, --game-pack
+rust for rust_serverinfo, rust_console_stream, rust_kick and rust_ban over
+WebRCON, --game-pack palworld for palworld_show_players,
+palworld_broadcast, palworld_save and palworld_shutdown, --game-pack
+zomboid for zomboid_players, zomboid_servermsg, zomboid_save and
+zomboid_quit, --game-pack valheim for valheim_save, valheim_kick and
+valheim_ban, --game-pack battleye for battleye_players, battleye_say,
+battleye_reload_bans and battleye_restart on DayZ and Arma, --game-pack
+gmod for gmod_lua_run and gmod_lua_run_cl on Garry's Mod, or --game-pack
+console for console_players and console_broadcast on line-console games
+such as Unturned and Eco, run from the config file's console templates.
+
+By default the server talks to a single client over stdio. With
+--transport http (streamable HTTP) or --transport sse it listens on the
+--listen address instead, so it can run as a shared network service that
+many clients connect to. Clients connect to the --path endpoint (default
+/mcp), e.g. http://0.0.0.0:8080/mcp. Each client has its own MCP session,
+but all clients share the same RCON sessions. When a client disconnects,
+the RCON sessions it opened are closed. The transport's "allow" and "deny"
+lists of IP ranges, e.g. {"allow": ["10.8.0.0/24"]}, restrict which
+addresses may connect; other clients get 403 Forbidden. Liveness and
+readiness probes are answered at /healthz and /readyz from any address;
+with the transport's "ready_sessions" set, /readyz fails while a
+preloaded session is down.
+
+  rcon-mcp-server serve --transport http --listen 0.0.0.0:8080
+
+Only MCP messages are written to stdout. The startup and ready messages
+go to the log, on stderr unless --log-file is set; --quiet omits them.
+
+With --readonly the assistant can only observe servers: rcon_connect
+accepts configured profiles only, rcon_test_connection is unavailable, and
+every tool runs only query commands such as list, status or version.
+
+  rcon-mcp-server serve --readonly --config servers.json
+
+With --preload-sessions the server opens a declared set of sessions at
+startup, from a JSON array in the same shape as rcon_connect's arguments.
+Sessions can also be declared under "sessions" in the config file. A
+session that fails to connect is logged and skipped.
+
+  [{"session_id": "prod", "profile": "survival"},
+   {"session_id": "test", "address": "localhost:25575", "password": "secret"}]
+
+With --daemon a network server detaches and keeps running in the
+background; --pidfile records its process ID for "daemon status" and
+"daemon stop". Use --log-file, since a daemon has no terminal to log to.
+
+  rcon-mcp-server serve --transport http --daemon --pidfile /tmp/rcon-mcp.pid --log-file /tmp/rcon-mcp.log
+
+Settings are read from the JSON file given with --config, or else from
+$RCON_MCP_CONFIG or $XDG_CONFIG_HOME/rcon-mcp-server/config.json:
+
+  {"profiles": [{"name": "survival", "address": "mc.example.com:25575",
+                 "password": "secret", "game": "minecraft", "tags": ["prod"]}],
+   "tools": {"prefix": "mc_", "disabled": ["rcon_broadcast"]},
+   "limits": {"max_sessions": 10, "history_size": 500, "max_clients": 20, "max_client_sessions": 5},
+   "policies": {"deny": ["stop", "op"]},
+   "logging": {"level": "info", "format": "json", "file": "/var/log/rcon-mcp-server.log"},
+   "audit": {"file": "/var/log/rcon-mcp-audit.jsonl", "max_size_mb": 10, "max_files": 5},
+   "transport": {"type": "http", "listen": "127.0.0.1:8080", "path": "/mcp"}}
+
+A profile can name an environment variable holding its password with
+"password_env", or an OS keyring entry with "password_keyring" (see
+"servers set-password"), instead of storing it under "password", or keep
+it encrypted under "password_encrypted" (see "encrypt-password"), or refer
+to it in HashiCorp Vault with "password_ref": "vault:kv/game/prod#rcon",
+using VAULT_ADDR and VAULT_TOKEN.
+
+A profile's "role" restricts the commands run on it: "viewer" allows only
+queries, "operator" everything but administrative commands such as stop or
+op, and "admin" everything. The "roles" section overrides these or adds
+new ones, each a set of policies: {"roles": {"mod": {"allow": ["kick"]}}}.
+
+A profile's "quota", such as "200/1h", caps the commands all its sessions
+together may run in a sliding window, and "session_quota" under limits
+does the same for each session. Commands beyond a quota are refused.
+Sessions clients opened are closed once they have run no command for
+"idle_timeout" under limits, such as "30m".
+
+Passwords are masked as [REDACTED] in tool results, errors and logs, along
+with the matches of the regular expressions under "logging": {"redact": [...]}.
+The rules under "responses": {"redact": [...]} rewrite server output before
+clients see it, e.g. {"preset": "ipv4"}, {"preset": "steamid"} or
+{"pattern": "...", "replace": "..."}.
+
+The alerts section warns in the log about commands slower than
+"slow_command", such as "2s", and about sessions whose recent commands
+fail at "error_rate" or more, e.g. 0.5 of the last "error_window" (20);
+with "notify": true clients are sent the warnings too.
+
+The audit section appends every connect, execute and disconnect to a JSONL
+file, rotated at max_size_mb; query it with "rcon-mcp-server logs". Entries
+are hash chained, and "rcon-mcp-server logs verify" reports any that were
+edited, inserted or removed.
+
+The tools section renames tools by replacing their "rcon_" prefix and
+hides the tools listed under disabled. Policies match commands by their
+first word; deny wins over allow, and an allow list blocks everything
+not on it. Setting "read_only": true is the same as --readonly. Setting
+"strict_passwords": true removes the password argument from the tools, so
+servers can only be reached through profiles.
+
+Environment variables override the file: RCON_MCP_TOOLS_PREFIX,
+RCON_MCP_TOOLS_DISABLED, RCON_MCP_GAME_PACKS, RCON_MCP_MAX_SESSIONS, RCON_MCP_HISTORY_SIZE,
+RCON_MCP_MAX_CLIENTS, RCON_MCP_MAX_CLIENT_SESSIONS, RCON_MCP_SESSION_QUOTA,
+RCON_MCP_IDLE_TIMEOUT, RCON_MCP_READ_BUFFER, RCON_MCP_MAX_PACKET_SIZE,
+RCON_MCP_POLICY_ALLOW, RCON_MCP_POLICY_DENY, RCON_MCP_READONLY,
+RCON_MCP_STRICT_PASSWORDS, RCON_MCP_LOG_LEVEL, RCON_MCP_LOG_FORMAT,
+RCON_MCP_LOG_FILE, RCON_MCP_QUIET, RCON_MCP_SLOW_COMMAND, RCON_MCP_AUDIT_FILE,
+RCON_MCP_GAME_LOGS_LISTEN, RCON_MCP_GAME_LOGS_ADVERTISE,
+RCON_MCP_TRANSPORT, RCON_MCP_LISTEN, RCON_MCP_PATH, RCON_MCP_ALLOW_IPS,
+RCON_MCP_DENY_IPS and RCON_MCP_READY_SESSIONS. Lists are comma-separated. The
+--transport, --listen, --path, --readonly, --quiet and --log-* flags
+override both.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := loadConfig()
+		cobra.CheckErr(err)
+		if cmd.Flags().Changed("transport") {
+			cfg.Transport.Type = serveTransport
+		}
+		if cmd.Flags().Changed("listen") {
+			cfg.Transport.Listen = serveListen
+		}
+		if cmd.Flags().Changed("path") {
+			cfg.Transport.Path = servePath
+		}
+		if cmd.Flags().Changed("readonly") {
+			cfg.Policies.ReadOnly = serveReadOnly
+		}
+		if cmd.Flags().Changed("quiet") {
+			cfg.Logging.Quiet = serveQuiet
+		}
+		cfg.Tools.GamePacks = append(cfg.Tools.GamePacks, serveGamePacks...)
+		if servePreload != "" {
+			sessions, err := config.LoadSessions(servePreload)
+			cobra.CheckErr(err)
+			cfg.Sessions = append(cfg.Sessions, sessions...)
+		}
+		cobra.CheckErr(cfg.Validate())
+		cobra.CheckErr(setupLogging(cmd, cfg.Logging))
+
+		if serveDaemon {
+			if cfg.Transport.Kind() == config.TransportStdio {
+				cobra.CheckErr(errors.New("--daemon requires the http or sse transport"))
+			}
+			pid, err := startDaemon(servePidfile)
+			cobra.CheckErr(err)
+			if !cfg.Logging.Quiet {
+				fmt.Fprintf(cmd.OutOrStdout(), "rcon-mcp-server started in the background (pid %d)\n", pid)
+			}
+			return
+		}
+		if servePidfile != "" {
+			release, err := acquirePidfile(servePidfile)
+			cobra.CheckErr(err)
+			defer release()
+		}
+
 		// Start the MCP server. This will block until the server is terminated.
-		mcp.Serve()
+		mcp.Serve(cfg)
 	},
 }
 
 // init registers the serve command with the root command during package initialization.
 func init() {
 	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveTransport, "transport", config.TransportStdio, "MCP transport: stdio, http or sse")
+	serveCmd.Flags().StringVar(&serveListen, "listen", config.DefaultListen, "address the http and sse transports listen on")
+	serveCmd.Flags().StringVar(&servePath, "path", config.DefaultPath, "URL path of the http and sse endpoint")
+	serveCmd.Flags().BoolVar(&serveReadOnly, "readonly", false, "only allow query commands on configured profiles")
+	serveCmd.Flags().StringSliceVar(&serveGamePacks, "game-pack", nil, "enable the helper tools of a game: "+strings.Join(config.GamePacks, ", "))
+	serveCmd.Flags().BoolVar(&serveQuiet, "quiet", false, "do not log the startup and ready messages")
+	serveCmd.Flags().StringVar(&servePreload, "preload-sessions", "", "JSON file of sessions to open at startup")
+	serveCmd.Flags().BoolVar(&serveDaemon, "daemon", false, "run the server in the background (http and sse transports only)")
+	serveCmd.Flags().StringVar(&servePidfile, "pidfile", "", "write the server's process ID to this file")
 }
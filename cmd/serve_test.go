@@ -19,6 +19,12 @@ func TestServeCommand(t *testing.T) {
 			wantOutput: []string{"Start the RCON Model Context Protocol", "Available tools:", "rcon_connect", "rcon_execute"},
 			wantErr:    false,
 		},
+		{
+			name:       "serve command config flag",
+			args:       []string{"serve", "--help"},
+			wantOutput: []string{"--config", "rcon_list_profiles", "--quiet"},
+			wantErr:    false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/keyring"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// serversAddProfile holds the flags of the servers add command.
+var serversAddProfile config.Profile
+
+// serversCmd groups the subcommands that manage server profiles.
+var serversCmd = &cobra.Command{
+	Use:   "servers",
+	Short: "Manage the server profiles in the config file",
+	Long: `Add, list, remove and test the server profiles stored in the config file,
+and move their passwords into the OS keyring.
+The file is the one given with --config or found on the config search path,
+and is created by "servers add" if it does not exist yet.
+
+Examples:
+  rcon-mcp-server servers add survival --config servers.json --address mc.example.com:25575 --password secret --game minecraft --tag prod
+  rcon-mcp-server servers add creative --config servers.json --address mc.example.com:25576 --password-env CREATIVE_RCON_PASSWORD
+  rcon-mcp-server servers set-password survival --config servers.json
+  rcon-mcp-server servers list --config servers.json
+  rcon-mcp-server servers test survival --config servers.json
+  rcon-mcp-server servers remove survival --config servers.json`,
+	Args: cobra.NoArgs,
+}
+
+// serversAddCmd adds a profile to the config file.
+var serversAddCmd = &cobra.Command{
+	Use:          "add <name>",
+	Short:        "Add a server profile",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfigForUpdate()
+		if err != nil {
+			return err
+		}
+
+		profile := serversAddProfile
+		profile.Name = args[0]
+		if err := cfg.AddProfile(&profile); err != nil {
+			return err
+		}
+		if err := cfg.Save(path); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "added profile %s (%s)\n", profile.Name, profile.Address)
+		return nil
+	},
+}
+
+// serversListCmd prints the configured profiles without their passwords.
+var serversListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the server profiles",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if len(cfg.Profiles) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "no profiles configured")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tADDRESS\tGAME\tTAGS\tROLE")
+		for _, p := range cfg.SortedProfiles() {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Name, p.Address, p.Game, strings.Join(p.Tags, ","), p.Role)
+		}
+		return w.Flush()
+	},
+}
+
+// serversRemoveCmd removes a profile from the config file.
+var serversRemoveCmd = &cobra.Command{
+	Use:          "remove <name>",
+	Aliases:      []string{"rm"},
+	Short:        "Remove a server profile",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfigForUpdate()
+		if err != nil {
+			return err
+		}
+		if err := cfg.RemoveProfile(args[0]); err != nil {
+			return err
+		}
+		if err := cfg.Save(path); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "removed profile %s\n", args[0])
+		return nil
+	},
+}
+
+// serversTestCmd connects and authenticates to a profile's server. It exits
+// with the same codes as exec, so it can be used in scripts.
+var serversTestCmd = &cobra.Command{
+	Use:          "test <name>",
+	Short:        "Verify that a profile's server is reachable and accepts its password",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result := checkProfile(args[0])
+		if result.err != nil {
+			return result.err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%s: ok, authenticated in %s\n",
+			args[0], result.latency.Round(time.Millisecond))
+		return nil
+	},
+}
+
+// serversSetPasswordCmd stores a profile's password in the OS keyring and
+// points the profile at it, so the password is no longer kept in the file.
+var serversSetPasswordCmd = &cobra.Command{
+	Use:   "set-password <name>",
+	Short: "Store a profile's password in the OS keyring",
+	Long: `Store a profile's password in the OS credential store (macOS Keychain,
+Windows Credential Manager or the Secret Service on Linux) and set the
+profile's password_keyring to the entry, removing any password from the
+config file. The password is prompted for without echo, or read from the
+first line of standard input when it is not a terminal.
+
+The entry is named after the profile unless password_keyring already
+names one.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, path, err := loadConfigForUpdate()
+		if err != nil {
+			return err
+		}
+		profile, err := cfg.Profile(args[0])
+		if err != nil {
+			return err
+		}
+
+		password, err := readPassword(cmd, "Password for "+profile.Name+": ")
+		if err != nil {
+			return err
+		}
+		if password == "" {
+			return errors.New("the password must not be empty")
+		}
+
+		account := profile.PasswordKeyring
+		if account == "" {
+			account = profile.Name
+		}
+		if err := keyring.Set(account, password); err != nil {
+			return err
+		}
+		profile.Password, profile.PasswordEnv, profile.PasswordEncrypted, profile.PasswordRef = "", "", "", ""
+		profile.PasswordKeyring = account
+		if err := cfg.Save(path); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "stored the password of profile %s in the OS keyring as %s\n", profile.Name, account)
+		return nil
+	},
+}
+
+// readPassword prompts for a password on the terminal without echoing it,
+// or reads the first line of the command's input when it is not a terminal.
+func readPassword(cmd *cobra.Command, prompt string) (string, error) {
+	in := cmd.InOrStdin()
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(cmd.ErrOrStderr(), prompt)
+		password, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(cmd.ErrOrStderr())
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+		return string(password), nil
+	}
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// init registers the servers command and its subcommands with the root
+// command during package initialization.
+func init() {
+	rootCmd.AddCommand(serversCmd)
+	serversCmd.AddCommand(serversAddCmd, serversListCmd, serversRemoveCmd, serversTestCmd, serversSetPasswordCmd)
+
+	flags := serversAddCmd.Flags()
+	flags.StringVar(&serversAddProfile.Address, "address", "", "RCON server address (host:port)")
+	flags.StringVar(&serversAddProfile.Password, "password", "", "RCON server password")
+	flags.StringVar(&serversAddProfile.PasswordEnv, "password-env", "", "environment variable holding the RCON server password, instead of --password")
+	flags.StringVar(&serversAddProfile.PasswordKeyring, "password-keyring", "", `OS keyring entry holding the RCON server password (see "servers set-password")`)
+	flags.StringVar(&serversAddProfile.PasswordRef, "password-ref", "", `reference to the RCON server password in a secret store, e.g. "vault:kv/game/prod#rcon"`)
+	flags.StringVar(&serversAddProfile.Game, "game", "", `game type, e.g. "minecraft"`)
+	flags.StringVar(&serversAddProfile.Protocol, "protocol", "", `RCON protocol: "rcon", "webrcon", "telnet", "tshock", "battleye", "satisfactory", "generic-telnet", "generic-websocket" or "generic-http" (default "webrcon" for rust, "telnet" for 7dtd, "tshock" for terraria, "battleye" for dayz and arma3, "satisfactory" for satisfactory, else "rcon")`)
+	flags.StringVar(&serversAddProfile.QueryAddress, "query-address", "", "address of the server's status query port, when not the default for its game")
+	flags.StringSliceVar(&serversAddProfile.Tags, "tag", nil, "label for the profile; may be repeated")
+	flags.StringVar(&serversAddProfile.Role, "role", "", "role limiting the commands run on the server: viewer, operator, admin or one from the config file")
+	flags.StringVar(&serversAddProfile.Quota, "quota", "", `most commands the profile's sessions may run in a window, e.g. "200/1h"`)
+	flags.IntVar(&serversAddProfile.Pool, "pool", 0, "connections each session of the profile opens to run commands in parallel")
+	flags.StringVar(&serversAddProfile.CacheTTL, "cache-ttl", "", `how long the output of read-only commands is reused, e.g. "5s"`)
+	flags.BoolVar(&serversAddProfile.AutoReconnect, "auto-reconnect", false, "reconnect and retry a command once when the connection drops")
+	_ = serversAddCmd.MarkFlagRequired("address")
+}
+
+// loadConfigForUpdate loads the config file that a command is about to
+// modify and returns it with its path. A missing file yields an empty
+// configuration so that it can be created. Environment variable overrides
+// are not applied, so they are never written to the file.
+func loadConfigForUpdate() (*config.Config, string, error) {
+	path, _ := config.Locate(configPath, os.LookupEnv)
+	if path == "" {
+		return nil, "", errors.New("cannot determine the config file location; use --config")
+	}
+	cfg, err := config.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config.Config{}, path, nil
+	}
+	return cfg, path, err
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/keyring"
+)
+
+func TestServersCommand(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(config.EnvPath, "")
+	address := startTestRCONServer(t, "secret", func(string) string { return "" })
+	configFile := filepath.Join(t.TempDir(), "config.json")
+
+	steps := []struct {
+		name       string
+		args       []string
+		wantOutput string
+		wantCode   int
+	}{
+		{
+			name:       "list without config file",
+			args:       []string{"servers", "list"},
+			wantOutput: "no profiles configured",
+			wantCode:   exitOK,
+		},
+		{
+			name:       "add creates the config file",
+			args:       []string{"servers", "add", "local", "--config", configFile, "--address", address, "--password", "secret", "--game", "minecraft", "--tag", "prod"},
+			wantOutput: "added profile local",
+			wantCode:   exitOK,
+		},
+		{
+			name:       "add with wrong password",
+			args:       []string{"servers", "add", "broken", "--config", configFile, "--address", address, "--password", "wrong"},
+			wantOutput: "added profile broken",
+			wantCode:   exitOK,
+		},
+		{
+			name:       "add duplicate",
+			args:       []string{"servers", "add", "local", "--config", configFile, "--address", address},
+			wantOutput: "already exists",
+			wantCode:   exitFailure,
+		},
+		{
+			name:       "list",
+			args:       []string{"servers", "list", "--config", configFile},
+			wantOutput: "local   " + address + "  minecraft  prod",
+			wantCode:   exitOK,
+		},
+		{
+			name:       "test",
+			args:       []string{"servers", "test", "local", "--config", configFile},
+			wantOutput: "local: ok",
+			wantCode:   exitOK,
+		},
+		{
+			name:       "test wrong password",
+			args:       []string{"servers", "test", "broken", "--config", configFile},
+			wantOutput: "invalid password",
+			wantCode:   exitAuthFailure,
+		},
+		{
+			name:       "remove",
+			args:       []string{"servers", "remove", "broken", "--config", configFile},
+			wantOutput: "removed profile broken",
+			wantCode:   exitOK,
+		},
+		{
+			name:       "remove missing",
+			args:       []string{"servers", "remove", "broken", "--config", configFile},
+			wantOutput: "not found",
+			wantCode:   exitFailure,
+		},
+		{
+			name:       "add to default config",
+			args:       []string{"servers", "add", "default", "--address", address},
+			wantOutput: "added profile default",
+			wantCode:   exitOK,
+		},
+		{
+			name:       "list default config",
+			args:       []string{"servers", "list"},
+			wantOutput: "default",
+			wantCode:   exitOK,
+		},
+	}
+
+	for _, step := range steps {
+		output, code := runCLI(t, step.args...)
+		if code != step.wantCode {
+			t.Errorf("%s: exit code = %d, want %d; output:\n%s", step.name, code, step.wantCode, output)
+		}
+		if !strings.Contains(output, step.wantOutput) {
+			t.Errorf("%s: expected output to contain %q, got:\n%s", step.name, step.wantOutput, output)
+		}
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load saved config: %v", err)
+	}
+	if len(cfg.Profiles) != 1 || cfg.Profiles[0].Password != "secret" || cfg.Profiles[0].Tags[0] != "prod" {
+		t.Errorf("Unexpected saved profiles: %+v", cfg.Profiles)
+	}
+}
+
+func TestServersSetPassword(t *testing.T) {
+	keyring.MockInit()
+	address := startTestRCONServer(t, "secret", func(string) string { return "" })
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if _, code := runCLI(t, "servers", "add", "local", "--config", configFile, "--address", address, "--password", "old"); code != exitOK {
+		t.Fatalf("Failed to add profile")
+	}
+
+	rootCmd.SetIn(strings.NewReader("secret\n"))
+	defer rootCmd.SetIn(nil)
+	output, code := runCLI(t, "servers", "set-password", "local", "--config", configFile)
+	if code != exitOK || !strings.Contains(output, "stored the password of profile local") {
+		t.Fatalf("set-password failed with code %d:\n%s", code, output)
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		t.Fatalf("Failed to load saved config: %v", err)
+	}
+	if p := cfg.Profiles[0]; p.Password != "" || p.PasswordKeyring != "local" {
+		t.Errorf("Expected the password moved to the keyring, got %+v", p)
+	}
+	if password, err := keyring.Get("local"); err != nil || password != "secret" {
+		t.Errorf("Expected keyring entry %q, got %q, %v", "secret", password, err)
+	}
+	if output, code := runCLI(t, "servers", "test", "local", "--config", configFile); code != exitOK {
+		t.Errorf("Expected the profile to authenticate from the keyring, got code %d:\n%s", code, output)
+	}
+
+	rootCmd.SetIn(strings.NewReader("\n"))
+	if output, code := runCLI(t, "servers", "set-password", "local", "--config", configFile); code != exitFailure || !strings.Contains(output, "must not be empty") {
+		t.Errorf("Expected an empty password to be refused, got code %d:\n%s", code, output)
+	}
+}
@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// shellTarget holds the connection flags of the shell command.
+var shellTarget targetFlags
+
+// shellNoColor disables colorized shell output.
+var shellNoColor bool
+
+// shellCmd starts an interactive console on one or more RCON servers.
+var shellCmd = &cobra.Command{
+	Use:   "shell [flags]",
+	Short: "Start an interactive RCON console",
+	Long: `Start an interactive console connected to an RCON server. Each line typed
+is sent to the current server and its response is printed.
+
+The console supports line editing and command history when run in a
+terminal. Lines starting with a colon control the console itself:
+
+  :use <name>     switch to another session, connecting to the profile
+                  of that name if it is not open yet
+  :sessions       list open sessions
+  :close [name]   close a session (the current one by default)
+  :help           show this list
+  :quit           leave the console (Ctrl-D also works)
+
+Output is colorized when writing to a terminal; use --no-color to disable it.
+
+Examples:
+  rcon-mcp-server shell --config servers.json --profile prod
+  rcon-mcp-server shell --address localhost:25575 --password secret`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := shellTarget.resolve()
+		if err != nil {
+			return err
+		}
+		client, err := t.dial()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGTERM)
+		defer stop()
+
+		sh := newShell(cmd.OutOrStdout())
+		defer sh.closeAll()
+		sh.add(t, client)
+
+		in, out := cmd.InOrStdin(), cmd.OutOrStdout()
+		if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+			return sh.runTerminal(ctx, f)
+		}
+		sh.color = !shellNoColor && isTerminal(out)
+		return sh.run(ctx, in)
+	},
+}
+
+// init registers the shell command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(shellCmd)
+	shellTarget.register(shellCmd)
+	shellCmd.Flags().BoolVar(&shellNoColor, "no-color", false, "disable colorized output")
+}
+
+// ANSI escape sequences used to colorize shell output.
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+)
+
+// shellSession is one server connection open in the shell.
+type shellSession struct {
+	target *target
+	client *rcon.Client
+}
+
+// shell is an interactive console over one or more RCON sessions.
+type shell struct {
+	out      io.Writer                // Destination for responses and messages
+	color    bool                     // Whether to colorize output
+	sessions map[string]*shellSession // Open sessions by name
+	current  string                   // Name of the session commands are sent to
+}
+
+// newShell creates a shell writing to out with no open sessions.
+func newShell(out io.Writer) *shell {
+	return &shell{out: out, sessions: make(map[string]*shellSession)}
+}
+
+// add registers an open session and makes it the current one.
+func (s *shell) add(t *target, client *rcon.Client) {
+	s.sessions[t.Name] = &shellSession{target: t, client: client}
+	s.current = t.Name
+}
+
+// closeAll disconnects every open session.
+func (s *shell) closeAll() {
+	for name, sess := range s.sessions {
+		_ = sess.client.Disconnect()
+		delete(s.sessions, name)
+	}
+}
+
+// prompt returns the prompt for the current session.
+func (s *shell) prompt() string {
+	if s.current == "" {
+		return s.paint(colorCyan, "(none)") + "> "
+	}
+	return s.paint(colorCyan, s.current) + "> "
+}
+
+// paint wraps text in a color escape sequence when colors are enabled.
+func (s *shell) paint(color, text string) string {
+	if !s.color || text == "" {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// println writes one line of text in color.
+func (s *shell) println(color, text string) {
+	fmt.Fprintln(s.out, s.paint(color, strings.TrimRight(text, "\n")))
+}
+
+// run reads lines from in until it is exhausted or the user quits. It is
+// used when input is not a terminal, such as when commands are piped in.
+func (s *shell) run(ctx context.Context, in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(s.out, s.prompt())
+		if !scanner.Scan() {
+			fmt.Fprintln(s.out)
+			return scanner.Err()
+		}
+		if s.handleLine(ctx, scanner.Text()) {
+			return nil
+		}
+	}
+}
+
+// runTerminal runs the shell on a terminal in raw mode, providing line
+// editing and command history.
+func (s *shell) runTerminal(ctx context.Context, f *os.File) error {
+	state, err := term.MakeRaw(int(f.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to configure terminal: %w", err)
+	}
+	defer term.Restore(int(f.Fd()), state)
+
+	terminal := term.NewTerminal(f, "")
+	s.out = terminal
+	s.color = !shellNoColor
+	for {
+		terminal.SetPrompt(s.prompt())
+		line, err := terminal.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil && !errors.Is(err, term.ErrPasteIndicator) {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		if s.handleLine(ctx, line) {
+			return nil
+		}
+	}
+}
+
+// handleLine runs one line of input and reports whether the shell should exit.
+func (s *shell) handleLine(ctx context.Context, line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return false
+	}
+	if strings.HasPrefix(line, ":") {
+		return s.meta(line)
+	}
+
+	sess, ok := s.sessions[s.current]
+	if !ok {
+		s.println(colorRed, "no open session; use :use <name> to connect")
+		return false
+	}
+
+	if err := sess.target.allow(line); err != nil {
+		s.println(colorRed, "error: "+err.Error())
+		return false
+	}
+	output, err := sess.client.ExecuteContext(ctx, line)
+	switch {
+	case err != nil:
+		s.println(colorRed, "error: "+err.Error())
+	case game.Rejected(sess.target.Game, output):
+		s.println(colorYellow, output)
+	case output != "":
+		s.println("", output)
+	}
+	return false
+}
+
+// meta runs a console command starting with a colon and reports whether the
+// shell should exit.
+func (s *shell) meta(line string) bool {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ":quit", ":exit", ":q":
+		return true
+	case ":help":
+		s.println("", shellHelp)
+	case ":sessions":
+		s.listSessions()
+	case ":use":
+		if len(fields) != 2 {
+			s.println(colorRed, "usage: :use <name>")
+			return false
+		}
+		s.use(fields[1])
+	case ":close":
+		name := s.current
+		if len(fields) > 1 {
+			name = fields[1]
+		}
+		s.closeSession(name)
+	default:
+		s.println(colorRed, fmt.Sprintf("unknown console command %s; type :help for a list", fields[0]))
+	}
+	return false
+}
+
+// shellHelp lists the console commands.
+const shellHelp = `:use <name>     switch to a session, connecting to the profile if needed
+:sessions       list open sessions
+:close [name]   close a session (the current one by default)
+:help           show this list
+:quit           leave the console`
+
+// listSessions prints the open sessions, marking the current one.
+func (s *shell) listSessions() {
+	names := make([]string, 0, len(s.sessions))
+	for name := range s.sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		marker, color := "  ", ""
+		if name == s.current {
+			marker, color = "* ", colorGreen
+		}
+		s.println(color, fmt.Sprintf("%s%s (%s)", marker, name, s.sessions[name].target.Address))
+	}
+}
+
+// use switches to the named session, connecting to the profile of that name
+// if no such session is open.
+func (s *shell) use(name string) {
+	if _, ok := s.sessions[name]; ok {
+		s.current = name
+		return
+	}
+
+	t, err := (&targetFlags{profile: name}).resolve()
+	if err != nil {
+		s.println(colorRed, "error: "+err.Error())
+		return
+	}
+	client, err := t.dial()
+	if err != nil {
+		s.println(colorRed, "error: "+err.Error())
+		return
+	}
+	s.add(t, client)
+	s.println(colorGreen, fmt.Sprintf("connected to %s (%s)", t.Name, t.Address))
+}
+
+// closeSession disconnects the named session. When it was the current
+// session, another open session becomes current.
+func (s *shell) closeSession(name string) {
+	sess, ok := s.sessions[name]
+	if !ok {
+		s.println(colorRed, "session not found: "+name)
+		return
+	}
+	_ = sess.client.Disconnect()
+	delete(s.sessions, name)
+	s.println("", "closed "+name)
+
+	if name != s.current {
+		return
+	}
+	s.current = ""
+	for other := range s.sessions {
+		if s.current == "" || other < s.current {
+			s.current = other
+		}
+	}
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShellCommand(t *testing.T) {
+	survival := startTestRCONServer(t, "secret", func(command string) string {
+		if command == "bogus" {
+			return "Unknown or incomplete command, see below for error"
+		}
+		return "survival: " + command
+	})
+	creative := startTestRCONServer(t, "secret", func(command string) string {
+		return "creative: " + command
+	})
+
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	profiles := `{"profiles": [
+		{"name": "survival", "address": "` + survival + `", "password": "secret", "game": "minecraft"},
+		{"name": "creative", "address": "` + creative + `", "password": "secret"}
+	]}`
+	if err := os.WriteFile(configFile, []byte(profiles), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		input       string
+		wantOutput  []string
+		avoidOutput []string
+		wantCode    int
+	}{
+		{
+			name:       "runs commands",
+			input:      "list\nsay hi\n",
+			wantOutput: []string{"survival> survival: list", "survival: say hi"},
+			wantCode:   exitOK,
+		},
+		{
+			name:       "switches sessions",
+			input:      ":use creative\nlist\n:use survival\nlist\n:sessions\n",
+			wantOutput: []string{"connected to creative", "creative: list", "survival: list", "* survival", "  creative"},
+			wantCode:   exitOK,
+		},
+		{
+			name:       "unknown profile",
+			input:      ":use nether\nlist\n",
+			wantOutput: []string{"profile nether not found", "survival: list"},
+			wantCode:   exitOK,
+		},
+		{
+			name:        "quit stops reading",
+			input:       ":quit\nlist\n",
+			avoidOutput: []string{"survival: list"},
+			wantCode:    exitOK,
+		},
+		{
+			name:       "close current session",
+			input:      ":use creative\n:close\nlist\n",
+			wantOutput: []string{"closed creative", "survival> survival: list"},
+			wantCode:   exitOK,
+		},
+		{
+			name:       "close last session",
+			input:      ":close\nlist\n",
+			wantOutput: []string{"(none)> no open session"},
+			wantCode:   exitOK,
+		},
+		{
+			name:       "rejected command is printed",
+			input:      "bogus\n",
+			wantOutput: []string{"Unknown or incomplete command"},
+			wantCode:   exitOK,
+		},
+		{
+			name:       "unknown console command",
+			input:      ":frobnicate\n",
+			wantOutput: []string{"unknown console command :frobnicate"},
+			wantCode:   exitOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootCmd.SetIn(strings.NewReader(tt.input))
+			defer rootCmd.SetIn(nil)
+
+			output, code := runCLI(t, "shell", "--config", configFile, "--profile", "survival")
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d (output: %q)", tt.wantCode, code, output)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got %q", want, output)
+				}
+			}
+			for _, avoid := range tt.avoidOutput {
+				if strings.Contains(output, avoid) {
+					t.Errorf("Expected output not to contain %q, got %q", avoid, output)
+				}
+			}
+			if strings.Contains(output, "\x1b[") {
+				t.Errorf("Expected no color escapes when output is not a terminal, got %q", output)
+			}
+		})
+	}
+}
+
+func TestShellCommand_ConnectFailure(t *testing.T) {
+	output, code := runCLI(t, "shell", "--address", "127.0.0.1:1", "--password", "secret")
+	if code != exitConnectError {
+		t.Errorf("Expected exit code %d, got %d (output: %q)", exitConnectError, code, output)
+	}
+}
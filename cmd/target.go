@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/mcp"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+// target identifies the RCON server a CLI command talks to.
+type target struct {
+	Name     string              // Profile name, or the address when no profile is used
+	Address  string              // Server address in "host:port" format
+	Password string              // RCON password
+	Game     game.Type           // Game type from the profile, Unknown if not set
+	Pool     int                 // Connections per session from the profile, 0 if not set
+	Protocol rcon.Protocol       // Wire protocol from the profile, Source RCON if not set
+	Backend  func() rcon.Backend // Backend configured from the profile, for the generic protocols
+	HTTP     rcon.HTTPDialect    // HTTP API from the profile, for the generic-http protocol
+
+	policies     config.Policies  // Command policies from the config file
+	role         string           // Role of the profile, if it has one
+	rolePolicies config.Policies  // Command policies of the profile's role
+	read         rcon.ReadOptions // Read path tuning from the config file's limits
+}
+
+// targetFlags holds the connection flags shared by commands that talk to a
+// single RCON server.
+type targetFlags struct {
+	profile  string
+	address  string
+	password string
+}
+
+// register adds the --profile, --address and --password flags to cmd.
+func (f *targetFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.profile, "profile", "", "name of a server profile from the config file")
+	cmd.Flags().StringVar(&f.address, "address", "", "RCON server address (host:port)")
+	cmd.Flags().StringVar(&f.password, "password", "", "RCON server password")
+}
+
+// resolve combines the flags with the named profile, if any. Explicit flags
+// override the profile's values. The command policies of the config file
+// apply whether or not a profile is used, and those of the profile's role
+// when it has one.
+func (f *targetFlags) resolve() (*target, error) {
+	t := &target{Address: f.address, Password: f.password, Game: game.Unknown}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	t.policies = cfg.Policies
+	t.read = rcon.ReadOptions{BufferSize: cfg.Limits.ReadBuffer, MaxPacketSize: cfg.Limits.MaxPacketSize}
+
+	if f.profile != "" {
+		profile, err := cfg.Profile(f.profile)
+		if err != nil {
+			return nil, err
+		}
+		t.Name = profile.Name
+		if t.Address == "" {
+			t.Address = profile.Address
+		}
+		if t.Password == "" {
+			if t.Password, err = profile.ResolvePassword(os.LookupEnv); err != nil {
+				return nil, err
+			}
+		}
+		if profile.Game != "" {
+			t.Game = game.Type(profile.Game)
+		}
+		t.Pool = profile.Pool
+		t.Protocol = rcon.Protocol(profile.WireProtocol())
+		t.Backend = mcp.ProfileBackend(t.Protocol, profile)
+		t.role = profile.Role
+		if t.rolePolicies, err = cfg.RolePolicies(profile.Role); err != nil {
+			return nil, err
+		}
+	}
+
+	if t.Address == "" {
+		return nil, errors.New("an address or profile is required")
+	}
+	if t.Name == "" {
+		t.Name = t.Address
+	}
+	return t, nil
+}
+
+// allow checks that command holds no control characters and is allowed by
+// the config file's policies and the profile's role. A denied command
+// carries the policy exit code.
+func (t *target) allow(command string) error {
+	if err := rcon.CheckCommand(command); err != nil {
+		return err
+	}
+	if refused, ok := t.policies.Refused(t.Game, command); ok {
+		return withExitCode(exitPolicyDenied, fmt.Errorf("command %q is not allowed by the config policies", config.CommandName(refused)))
+	}
+	if refused, ok := t.rolePolicies.Refused(t.Game, command); ok {
+		return withExitCode(exitPolicyDenied, fmt.Errorf("command %q is not allowed for the %s role of profile %s", config.CommandName(refused), t.role, t.Name))
+	}
+	return nil
+}
+
+// dial connects and authenticates to the target. Failures carry the exit
+// code for a connection or authentication error.
+func (t *target) dial() (*rcon.Client, error) {
+	client := rcon.NewClient()
+	if err := t.open(client); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// open connects and authenticates client to the target, with the exit
+// codes of dial.
+func (t *target) open(client *rcon.Client) error {
+	redact.AddSecret(t.Password)
+	client.SetReadOptions(t.read)
+	client.SetProtocol(t.Protocol)
+	client.SetBackend(t.Backend)
+	if err := client.Connect(t.Address); err != nil {
+		return withExitCode(exitConnectError, fmt.Errorf("%s: %w", t.Name, err))
+	}
+	if err := client.Authenticate(t.Password); err != nil {
+		_ = client.Disconnect()
+		return withExitCode(exitAuthFailure, fmt.Errorf("%s: %w", t.Name, err))
+	}
+	return nil
+}
+
+// loadConfig loads the file given with --config, or the one found on the
+// config search path, with environment variable overrides applied. It
+// returns an empty configuration when there is no config file.
+func loadConfig() (*config.Config, error) {
+	return config.LoadFrom(configPath, os.LookupEnv)
+}
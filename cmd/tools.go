@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/signal"
+	"syscall"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+// toolsCallArgs and toolsCallPreload hold the --json and --preload-sessions
+// flags of the tools call command.
+var (
+	toolsCallArgs    string
+	toolsCallPreload string
+)
+
+// toolsCmd groups the subcommands that work with the MCP tools directly.
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Work with the MCP tools without an MCP client",
+	Args:  cobra.NoArgs,
+}
+
+// toolsCallCmd invokes one tool handler in process and prints its result.
+var toolsCallCmd = &cobra.Command{
+	Use:   "call <tool>",
+	Short: "Call one MCP tool in process and print its result",
+	Long: `Build the MCP server as "serve" does, call one tool over an in-process
+transport and print the CallToolResult as JSON, so tool handlers can be
+debugged without wiring up an MCP client. The arguments are a JSON object
+given with --json ("-" reads it from stdin), checked against the tool's
+input schema as for any client.
+
+Each call runs in a fresh server: the sessions declared under "sessions" in
+the config file, and those of --preload-sessions, are opened before the call
+and closed after it. The config file's tools, policies, limits and audit
+settings apply as they do for "serve".
+
+The command exits with 4 if the tool returns an error result, and with 1 if
+the call itself fails, for example for an unknown tool or invalid arguments.
+
+Examples:
+  rcon-mcp-server tools call rcon_list_profiles
+  rcon-mcp-server tools call rcon_execute --config servers.json --json '{"session_id":"x","command":"list"}'
+  echo '{"address":"localhost:25575","password":"secret"}' | rcon-mcp-server tools call rcon_test_connection --json -`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data := []byte(toolsCallArgs)
+		if toolsCallArgs == "-" {
+			var err error
+			if data, err = io.ReadAll(cmd.InOrStdin()); err != nil {
+				return fmt.Errorf("failed to read the arguments: %w", err)
+			}
+		}
+		var arguments map[string]any
+		if err := json.Unmarshal(data, &arguments); err != nil {
+			return fmt.Errorf("--json must be a JSON object: %w", err)
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if toolsCallPreload != "" {
+			sessions, err := config.LoadSessions(toolsCallPreload)
+			if err != nil {
+				return err
+			}
+			cfg.Sessions = append(cfg.Sessions, sessions...)
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		if err := setupLogging(cmd, cfg.Logging); err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		result, err := mcp.Invoke(ctx, cfg, args[0], arguments)
+		if err != nil {
+			return fmt.Errorf("failed to call %s: %w", args[0], err)
+		}
+		if err := printJSON(cmd.OutOrStdout(), result); err != nil {
+			return err
+		}
+		if result.IsError {
+			return withExitCode(exitCommandError, errors.New("the tool returned an error result"))
+		}
+		return nil
+	},
+}
+
+// init registers the tools command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(toolsCmd)
+	toolsCmd.AddCommand(toolsCallCmd)
+	toolsCallCmd.Flags().StringVar(&toolsCallArgs, "json", "{}", `arguments of the tool as a JSON object, or "-" to read them from stdin`)
+	toolsCallCmd.Flags().StringVar(&toolsCallPreload, "preload-sessions", "", "JSON file of sessions to open before the call")
+}
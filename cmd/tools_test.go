@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToolsCallCommand(t *testing.T) {
+	address := startTestRCONServer(t, "secret", func(command string) string { return "ran " + command })
+	sessionsFile := filepath.Join(t.TempDir(), "sessions.json")
+	sessions := `[{"session_id": "x", "address": "` + address + `", "password": "secret"}]`
+	if err := os.WriteFile(sessionsFile, []byte(sessions), 0o600); err != nil {
+		t.Fatalf("Failed to write sessions: %v", err)
+	}
+
+	output, code := runCLI(t, "tools", "call", "rcon_execute", "--preload-sessions", sessionsFile,
+		"--json", `{"session_id":"x","command":"list"}`)
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, output)
+	}
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Expected a JSON result, got %v: %s", err, output)
+	}
+	if result.IsError || len(result.Content) == 0 || !strings.Contains(result.Content[0].Text, "ran list") {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestToolsCallCommand_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{"error result", []string{"rcon_execute", "--json", `{"session_id":"missing","command":"list"}`}, exitCommandError},
+		{"unknown tool", []string{"rcon_no_such_tool"}, exitFailure},
+		{"invalid json", []string{"rcon_execute", "--json", `["list"]`}, exitFailure},
+		{"invalid arguments", []string{"rcon_execute", "--json", `{"session_id":1}`}, exitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, code := runCLI(t, append([]string{"tools", "call"}, tt.args...)...)
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d: %s", tt.wantCode, code, output)
+			}
+		})
+	}
+}
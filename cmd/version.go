@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// versionJSON selects JSON output for the version command.
+var versionJSON bool
+
+// versionCmd prints the build information of the binary.
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Long: `Print the version, commit and build date of this binary along with the Go
+and MCP SDK versions it was built with. The same version is advertised to
+MCP clients when the server starts.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info := version.Get()
+		if versionJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(info)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "rcon-mcp-server %s\n", info)
+		return nil
+	},
+}
+
+// init registers the version command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "print the build information as JSON")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/version"
+)
+
+func TestVersionCommand(t *testing.T) {
+	output, code := runCLI(t, "version")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, output)
+	}
+	if !strings.HasPrefix(output, "rcon-mcp-server "+version.Get().Version) || !strings.Contains(output, "mcp-sdk") {
+		t.Errorf("Unexpected version output: %q", output)
+	}
+
+	output, code = runCLI(t, "version", "--json")
+	if code != exitOK {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, output)
+	}
+	var info version.Info
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", output, err)
+	}
+	if info.GoVersion == "" || info.SDKVersion == "" {
+		t.Errorf("Expected Go and SDK versions, got %+v", info)
+	}
+}
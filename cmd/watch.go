@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/spf13/cobra"
+)
+
+// watchTarget holds the connection flags of the watch command.
+var watchTarget targetFlags
+
+// watchInterval is the delay between two runs of the watched command.
+var watchInterval time.Duration
+
+// watchCount limits the number of runs; zero runs until interrupted.
+var watchCount int
+
+// watchNoColor disables colorized diff output.
+var watchNoColor bool
+
+// watchCmd re-runs one command on an interval and shows what changed.
+var watchCmd = &cobra.Command{
+	Use:   "watch [flags] <command>",
+	Short: "Run an RCON command repeatedly and highlight changes",
+	Long: `Run one RCON command on a fixed interval over a single connection and
+print each response. From the second run on, lines that appeared since the
+previous run are marked with "+" and lines that disappeared with "-", which
+makes it easy to follow player counts or TPS during an incident.
+
+Errors from individual runs are printed and watching continues. Press
+Ctrl-C to stop, or use --count to stop after a number of runs. Output is
+colorized when writing to a terminal; use --no-color to disable it.
+
+Examples:
+  rcon-mcp-server watch --profile prod --interval 10s list
+  rcon-mcp-server watch --address localhost:27015 --password secret --count 6 status`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if watchInterval <= 0 {
+			return errors.New("--interval must be positive")
+		}
+		if watchCount < 0 {
+			return errors.New("--count must not be negative")
+		}
+
+		t, err := watchTarget.resolve()
+		if err != nil {
+			return err
+		}
+		command := strings.Join(args, " ")
+		if err := t.allow(command); err != nil {
+			return err
+		}
+		client, err := t.dial()
+		if err != nil {
+			return err
+		}
+		defer client.Disconnect()
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		w := &watcher{
+			out:     cmd.OutOrStdout(),
+			color:   !watchNoColor && isTerminal(cmd.OutOrStdout()),
+			client:  client,
+			command: command,
+		}
+
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for run := 1; ; run++ {
+			if !w.run(ctx) {
+				return nil
+			}
+			if watchCount > 0 && run >= watchCount {
+				return nil
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	},
+}
+
+// init registers the watch command with the root command during package initialization.
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchTarget.register(watchCmd)
+	flags := watchCmd.Flags()
+	flags.DurationVar(&watchInterval, "interval", 2*time.Second, "delay between runs")
+	flags.IntVar(&watchCount, "count", 0, "stop after this many runs (0 runs until interrupted)")
+	flags.BoolVar(&watchNoColor, "no-color", false, "disable colorized output")
+}
+
+// watcher runs the watched command and prints each response as a diff
+// against the previous one.
+type watcher struct {
+	out      io.Writer
+	color    bool
+	client   *rcon.Client
+	command  string
+	previous []string // Lines of the last successful response
+	started  bool     // Whether a response has been printed yet
+}
+
+// run executes the command once and prints the result. It returns false
+// when ctx was cancelled and watching should stop.
+func (w *watcher) run(ctx context.Context) bool {
+	output, err := w.client.ExecuteContext(ctx, w.command)
+	if ctx.Err() != nil {
+		return false
+	}
+
+	fmt.Fprintf(w.out, "%s %s\n", w.paint(colorCyan, "== "+time.Now().Format(time.TimeOnly)), w.command)
+	if err != nil {
+		fmt.Fprintln(w.out, w.paint(colorRed, "error: "+err.Error()))
+		return true
+	}
+
+	lines := splitLines(output)
+	if !w.started {
+		for _, line := range lines {
+			fmt.Fprintln(w.out, line)
+		}
+	} else {
+		for _, d := range diffLines(w.previous, lines) {
+			switch d.op {
+			case '+':
+				fmt.Fprintln(w.out, w.paint(colorGreen, "+ "+d.text))
+			case '-':
+				fmt.Fprintln(w.out, w.paint(colorRed, "- "+d.text))
+			default:
+				fmt.Fprintln(w.out, "  "+d.text)
+			}
+		}
+	}
+	w.previous = lines
+	w.started = true
+	return true
+}
+
+// paint wraps text in a color escape sequence when colors are enabled.
+func (w *watcher) paint(color, text string) string {
+	if !w.color || text == "" {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// splitLines splits a response into lines without a trailing empty line.
+func splitLines(output string) []string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
+
+// diffLine is one line of a line diff: op is '+' for an added line, '-'
+// for a removed line and ' ' for a line present in both versions.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffLines returns a line diff from a to b based on their longest common
+// subsequence. Responses are small, so the quadratic table is fine.
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := make([]diffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, diffLine{'-', a[i]})
+			i++
+		default:
+			diff = append(diff, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, diffLine{'+', b[j]})
+	}
+	return diff
+}
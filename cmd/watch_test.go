@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want []diffLine
+	}{
+		{
+			name: "unchanged",
+			a:    []string{"one", "two"},
+			b:    []string{"one", "two"},
+			want: []diffLine{{' ', "one"}, {' ', "two"}},
+		},
+		{
+			name: "line changed",
+			a:    []string{"players: 1", "tps: 20"},
+			b:    []string{"players: 2", "tps: 20"},
+			want: []diffLine{{'-', "players: 1"}, {'+', "players: 2"}, {' ', "tps: 20"}},
+		},
+		{
+			name: "lines added and removed",
+			a:    []string{"alice", "bob"},
+			b:    []string{"bob", "carol"},
+			want: []diffLine{{'-', "alice"}, {' ', "bob"}, {'+', "carol"}},
+		},
+		{
+			name: "from empty",
+			a:    nil,
+			b:    []string{"alice"},
+			want: []diffLine{{'+', "alice"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffLines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchCommand(t *testing.T) {
+	var runs atomic.Int32
+	address := startTestRCONServer(t, "secret", func(command string) string {
+		n := runs.Add(1)
+		return fmt.Sprintf("There are %d players online\ntps: 20\n", n)
+	})
+
+	output, code := runCLI(t, "watch", "--address", address, "--password", "secret",
+		"--interval", "10ms", "--count", "3", "list")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d; output:\n%s", exitOK, code, output)
+	}
+
+	for _, want := range []string{
+		"There are 1 players online",
+		"- There are 1 players online",
+		"+ There are 2 players online",
+		"+ There are 3 players online",
+		"  tps: 20",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if got := strings.Count(output, "== "); got != 3 {
+		t.Errorf("Expected 3 runs, got %d; output:\n%s", got, output)
+	}
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("Expected no color escapes when not writing to a terminal")
+	}
+}
+
+func TestWatchCommandFlags(t *testing.T) {
+	output, code := runCLI(t, "watch", "--address", "127.0.0.1:1", "--password", "x", "--interval", "0s", "list")
+	if code != exitFailure || !strings.Contains(output, "--interval must be positive") {
+		t.Errorf("Expected interval error, got code %d, output:\n%s", code, output)
+	}
+}
@@ -0,0 +1,363 @@
+// Package audit keeps an append-only record of what was done to RCON
+// servers. Every connect, execute and disconnect is written as one JSON
+// object per line to an audit file, which is rotated once it grows past a
+// size limit. Secrets are redacted before an entry is written.
+//
+// Entries are chained: each carries the hash of the entry before it and a
+// SHA-256 hash of itself, so that editing, inserting or removing an entry
+// breaks the chain from that point on, which Verify detects.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+)
+
+// Events recorded in the audit log.
+const (
+	EventConnect    = "connect"
+	EventExecute    = "execute"
+	EventDisconnect = "disconnect"
+	EventTest       = "test" // A connection test, which opens no session
+)
+
+// MaxResponse is the number of bytes of a command's response kept in an
+// entry; longer responses are cut and flagged as truncated.
+const MaxResponse = 1024
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Time          time.Time `json:"time"`
+	Event         string    `json:"event"`
+	SessionID     string    `json:"session_id"`
+	Address       string    `json:"address,omitempty"`
+	Command       string    `json:"command,omitempty"`
+	Response      string    `json:"response,omitempty"`
+	Truncated     bool      `json:"truncated,omitempty"`
+	LatencyMillis int64     `json:"latency_ms,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	Client        string    `json:"client,omitempty"`     // Name and version the MCP client reported
+	ClientID      string    `json:"client_id,omitempty"`  // Transport session ID of the MCP client
+	RequestID     string    `json:"request_id,omitempty"` // Correlation ID of the tool call, as in the server's log
+	Prev          string    `json:"prev,omitempty"`       // Hash of the previous entry, empty for the first
+	Hash          string    `json:"hash,omitempty"`       // Hash of this entry, see Entry.Sum
+}
+
+// Sum returns the hash of e: the hex-encoded SHA-256 of its JSON encoding
+// with Hash left empty. Prev is included, which chains e to the entry
+// before it.
+func (e Entry) Sum() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log appends entries to an audit file. It is safe for concurrent use.
+type Log struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	last string // Hash of the latest entry, which the next one chains to
+}
+
+// Open opens the audit file at path for appending, creating it if needed.
+// Once the file would grow past maxSize bytes it is renamed to path.1,
+// older files shifting to path.2 and so on, and only maxFiles of them are
+// kept. New entries are chained to the latest entry already in the log.
+func Open(path string, maxSize int64, maxFiles int) (*Log, error) {
+	l := &Log{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	last, err := lastHash(path)
+	if err != nil {
+		return nil, err
+	}
+	l.last = last
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// lastHash returns the hash of the latest entry of the audit log at path,
+// or an empty string if it has none.
+func lastHash(path string) (string, error) {
+	files := Files(path)
+	for i := len(files) - 1; i >= 0; i-- {
+		var last string
+		err := scan(files[i], func(_ int, e Entry) error {
+			last = e.Hash
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		if last != "" {
+			return last, nil
+		}
+	}
+	return "", nil
+}
+
+// open opens the current audit file and records its size.
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.file, l.size = f, info.Size()
+	return nil
+}
+
+// Write appends e to the log. The time is set if it is zero, the response
+// is truncated to MaxResponse bytes, the command, response and error are
+// redacted, and the entry is chained to the one written before it.
+func (l *Log) Write(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	if len(e.Response) > MaxResponse {
+		// Cut at a character boundary so the entry stays valid UTF-8.
+		n := MaxResponse
+		for n > 0 && !utf8.RuneStart(e.Response[n]) {
+			n--
+		}
+		e.Response, e.Truncated = e.Response[:n], true
+	}
+	e.Command = redact.String(e.Command)
+	e.Response = redact.String(e.Response)
+	e.Error = redact.String(e.Error)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return errors.New("audit log is closed")
+	}
+
+	e.Prev = l.last
+	hash, err := e.Sum()
+	if err != nil {
+		return err
+	}
+	e.Hash = hash
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	l.last = hash
+	return nil
+}
+
+// rotate shifts the rotated files up by one, dropping the oldest, moves
+// the current file to path.1 and starts a new one.
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	l.file = nil
+	_ = os.Remove(rotatedName(l.path, l.maxFiles))
+	for i := l.maxFiles - 1; i >= 1; i-- {
+		_ = os.Rename(rotatedName(l.path, i), rotatedName(l.path, i+1))
+	}
+	if l.maxFiles > 0 {
+		if err := os.Rename(l.path, rotatedName(l.path, 1)); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	} else if err := os.Remove(l.path); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return l.open()
+}
+
+// Close closes the audit file. Entries written afterwards fail.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// rotatedName returns the name of the n-th rotated file of path.
+func rotatedName(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// Files returns the audit files of path that exist, oldest first: the
+// rotated files from the highest number down, then path itself.
+func Files(path string) []string {
+	var rotated []string
+	for n := 1; ; n++ {
+		name := rotatedName(path, n)
+		if _, err := os.Stat(name); err != nil {
+			break
+		}
+		rotated = append(rotated, name)
+	}
+	files := make([]string, 0, len(rotated)+1)
+	for i := len(rotated) - 1; i >= 0; i-- {
+		files = append(files, rotated[i])
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files
+}
+
+// Read returns the entries of the audit log at path, including its rotated
+// files, that match reports true for, oldest first. A nil match keeps every
+// entry. Returns an error if there is no audit file or a line is invalid.
+func Read(path string, match func(Entry) bool) ([]Entry, error) {
+	files := Files(path)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no audit log at %s: %w", path, fs.ErrNotExist)
+	}
+
+	var entries []Entry
+	for _, name := range files {
+		err := scan(name, func(_ int, e Entry) error {
+			if match == nil || match(e) {
+				entries = append(entries, e)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// scan calls fn with each entry of the audit file name and its line
+// number, stopping at the first error.
+func scan(name string, fn func(line int, e Entry) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("%s:%d: invalid audit entry: %w", name, line, err)
+		}
+		if err := fn(line, e); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return nil
+}
+
+// Verification is the outcome of verifying an audit log.
+type Verification struct {
+	Files     int    // Audit files checked
+	Entries   int    // Chained entries whose hashes match
+	Unchained int    // Entries at the start of the log written before chaining
+	Head      string // Hash of the latest entry
+}
+
+// ErrTampered is returned by Verify when the chain of entries is broken.
+var ErrTampered = errors.New("audit log has been tampered with")
+
+// VerifyOptions relaxes what Verify accepts at the start of the log, where
+// a shortened or older log cannot be told apart from one edited there.
+type VerifyOptions struct {
+	// Unchained accepts entries without a hash before the first chained
+	// entry, as written before chaining existed.
+	Unchained bool
+	// Trimmed accepts a first chained entry that names a previous one,
+	// which it does once rotation has removed the oldest file.
+	Trimmed bool
+}
+
+// Verify checks the hash chain of the audit log at path, including its
+// rotated files, oldest first. Every entry's hash must match its contents
+// and name the previous entry, and the first entry must start the chain.
+// opts can accept a log whose start rotation removed, or that begins with
+// entries from before chaining; entries without a hash are never accepted
+// after the first chained entry. Returns an error wrapping ErrTampered,
+// naming the first entry out of chain, if the log has been edited.
+//
+// Removing the latest entries leaves a valid, shorter chain; recording the
+// head hash elsewhere lets a later check detect that too.
+func Verify(path string, opts VerifyOptions) (*Verification, error) {
+	files := Files(path)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no audit log at %s: %w", path, fs.ErrNotExist)
+	}
+
+	v := &Verification{Files: len(files)}
+	for _, name := range files {
+		err := scan(name, func(line int, e Entry) error {
+			switch {
+			case e.Hash == "" && v.Entries == 0 && opts.Unchained:
+				v.Unchained++
+				return nil
+			case e.Hash == "":
+				return fmt.Errorf("%s:%d: %w: entry has no hash", name, line, ErrTampered)
+			case v.Entries == 0 && e.Prev != "" && !opts.Trimmed:
+				return fmt.Errorf("%s:%d: %w: first entry follows one that is not in the log", name, line, ErrTampered)
+			case v.Entries > 0 && e.Prev != v.Head:
+				return fmt.Errorf("%s:%d: %w: entry does not follow the previous one", name, line, ErrTampered)
+			}
+			sum, err := e.Sum()
+			if err != nil {
+				return err
+			}
+			if sum != e.Hash {
+				return fmt.Errorf("%s:%d: %w: entry does not match its hash", name, line, ErrTampered)
+			}
+			v.Entries++
+			v.Head = e.Hash
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
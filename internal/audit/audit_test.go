@@ -0,0 +1,229 @@
+package audit
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+)
+
+func TestLog_WriteRead(t *testing.T) {
+	redact.AddSecret("audit-secret")
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path, 1<<20, 3)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []Entry{
+		{Time: start, Event: EventConnect, SessionID: "s1", Address: "host:1", Client: "claude 1.0"},
+		{Event: EventExecute, SessionID: "s1", Command: "say audit-secret", Response: strings.Repeat("é", MaxResponse), LatencyMillis: 12},
+		{Event: EventDisconnect, SessionID: "s1", Error: "connection reset"},
+	}
+	for _, e := range entries {
+		if err := l.Write(e); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := l.Write(Entry{Event: EventConnect}); err == nil {
+		t.Error("Expected writing to a closed log to fail")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the audit file to be private, got %v, %v", info.Mode().Perm(), err)
+	}
+
+	got, err := Read(path, nil)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(got))
+	}
+	if !got[0].Time.Equal(start) || got[0].Client != "claude 1.0" {
+		t.Errorf("Unexpected first entry: %+v", got[0])
+	}
+	if got[1].Time.IsZero() {
+		t.Error("Expected the time to be filled in")
+	}
+	if got[1].Command != "say [REDACTED]" {
+		t.Errorf("Expected the command to be redacted, got %q", got[1].Command)
+	}
+	if !got[1].Truncated || len(got[1].Response) > MaxResponse || !strings.HasPrefix(got[1].Response, "éé") ||
+		strings.ContainsRune(got[1].Response, '�') {
+		t.Errorf("Expected the response truncated at a character boundary, got %d bytes", len(got[1].Response))
+	}
+
+	errorsOnly, err := Read(path, func(e Entry) bool { return e.Error != "" })
+	if err != nil || len(errorsOnly) != 1 || errorsOnly[0].Event != EventDisconnect {
+		t.Errorf("Expected only the failed entry, got %+v, %v", errorsOnly, err)
+	}
+}
+
+func TestLog_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path, 400, 2)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer l.Close()
+
+	for i := range 10 {
+		if err := l.Write(Entry{Event: EventExecute, SessionID: "s1", Command: strings.Repeat("x", 50) + string(rune('a'+i))}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	files := Files(path)
+	want := []string{path + ".2", path + ".1", path}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Fatalf("Files() = %v, want %v", files, want)
+	}
+	if _, err := os.Stat(path + ".3"); !errors.Is(err, fs.ErrNotExist) {
+		t.Error("Expected rotated files beyond max_files to be removed")
+	}
+	for _, name := range files {
+		if info, _ := os.Stat(name); info.Size() > 400 {
+			t.Errorf("Expected %s to stay under the size limit, got %d bytes", name, info.Size())
+		}
+	}
+
+	got, err := Read(path, nil)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if last := got[len(got)-1].Command; !strings.HasSuffix(last, "j") {
+		t.Errorf("Expected the newest entry last, got %q", last)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Command < got[i-1].Command {
+			t.Errorf("Expected entries oldest first, got %q before %q", got[i-1].Command, got[i].Command)
+		}
+	}
+}
+
+func TestRead_Missing(t *testing.T) {
+	if _, err := Read(filepath.Join(t.TempDir(), "missing.jsonl"), nil); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a not-exist error, got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// An entry from before chaining existed starts the log.
+	if err := os.WriteFile(path, []byte(`{"time":"2026-01-01T00:00:00Z","event":"connect","session_id":"old"}`+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Open(path, 400, 5)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for i := range 4 {
+		if err := l.Write(Entry{Event: EventExecute, SessionID: "s1", Command: strings.Repeat("x", 50) + string(rune('a'+i))}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	l.Close()
+
+	// Reopening continues the chain across rotated files.
+	l, err = Open(path, 400, 5)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := l.Write(Entry{Event: EventDisconnect, SessionID: "s1"}); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	l.Close()
+
+	if _, err := Verify(path, VerifyOptions{}); !errors.Is(err, ErrTampered) || !strings.Contains(err.Error(), "no hash") {
+		t.Errorf("Expected entries without a hash to need Unchained, got %v", err)
+	}
+	v, err := Verify(path, VerifyOptions{Unchained: true})
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if v.Entries != 5 || v.Unchained != 1 || v.Files < 2 || v.Head == "" {
+		t.Errorf("Unexpected verification %+v", v)
+	}
+
+	files := Files(path)
+	if len(files) < 3 {
+		t.Fatalf("Expected the entries spread over rotated files, got %v", files)
+	}
+	tamper := func(name string, edit func(string) string) {
+		t.Helper()
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(edit(string(data))), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	last := files[len(files)-1]
+	original, _ := os.ReadFile(last)
+
+	tamper(last, func(s string) string { return strings.Replace(s, `"session_id":"s1"`, `"session_id":"s2"`, 1) })
+	if _, err := Verify(path, VerifyOptions{Unchained: true}); !errors.Is(err, ErrTampered) || !strings.Contains(err.Error(), "does not match its hash") {
+		t.Errorf("Expected an edited entry to be detected, got %v", err)
+	}
+
+	tamper(last, func(string) string { return string(original) })
+	tamper(files[1], func(string) string { return "" })
+	if _, err := Verify(path, VerifyOptions{Unchained: true}); !errors.Is(err, ErrTampered) || !strings.Contains(err.Error(), "does not follow") {
+		t.Errorf("Expected removed entries to be detected, got %v", err)
+	}
+
+	// Without its oldest file, the log verifies only as trimmed.
+	tamper(last, func(string) string { return string(original) })
+	if err := os.Remove(files[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(path, VerifyOptions{Unchained: true}); !errors.Is(err, ErrTampered) || !strings.Contains(err.Error(), "not in the log") {
+		t.Errorf("Expected a log without its start to be detected, got %v", err)
+	}
+	if _, err := Verify(path, VerifyOptions{Trimmed: true}); err != nil {
+		t.Errorf("Expected a trimmed log to verify as trimmed, got %v", err)
+	}
+}
+
+func TestVerify_StrippedStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path, 1<<20, 1)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for _, command := range []string{"ban griefer", "list", "stop"} {
+		if err := l.Write(Entry{Event: EventExecute, SessionID: "s1", Command: command}); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	l.Close()
+
+	// Stripping the chain from the first entry lets it be edited unseen,
+	// unless the log must start the chain.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	lines[0] = `{"time":"2026-01-01T00:00:00Z","event":"execute","session_id":"s1","command":"ban nobody"}` + "\n"
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range []VerifyOptions{{}, {Unchained: true}} {
+		if _, err := Verify(path, opts); !errors.Is(err, ErrTampered) {
+			t.Errorf("Expected the stripped entry to be detected with %+v, got %v", opts, err)
+		}
+	}
+}
@@ -0,0 +1,986 @@
+// Package config loads the RCON MCP server configuration, including the
+// server profiles that clients can connect to by name, the limits and command
+// policies the server enforces, where it logs, and how clients reach it.
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/keyring"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+	"github.com/mjmorales/rcon-mcp-server/internal/secrets"
+)
+
+// Config is the top-level server configuration.
+type Config struct {
+	Profiles  []*Profile          `json:"profiles,omitempty"` // Named RCON server profiles
+	Sessions  []*Session          `json:"sessions,omitempty"` // Sessions opened when the server starts
+	Tools     Tools               `json:"tools"`              // Which tools are exposed and how they are named
+	Limits    Limits              `json:"limits"`             // Resource limits for the MCP server
+	Policies  Policies            `json:"policies"`           // Which commands may be sent to servers
+	Roles     map[string]Policies `json:"roles,omitempty"`    // Command policies of profile roles, by role name
+	Consoles  map[string]Console  `json:"consoles,omitempty"` // Command templates of line-console games, by game name
+	Logging   Logging             `json:"logging"`            // How and where the server logs
+	Alerts    Alerts              `json:"alerts"`             // When slow or failing commands are warned about
+	Responses Responses           `json:"responses"`          // How server responses are rewritten for MCP clients
+	Audit     Audit               `json:"audit"`              // Where commands run on servers are recorded
+	GameLogs  GameLogs            `json:"game_logs"`          // Where the log streams of game servers are received
+	Transport Transport           `json:"transport"`          // How MCP clients reach the server
+}
+
+// Limits bounds the resources the MCP server uses. Zero values select the
+// defaults.
+type Limits struct {
+	MaxSessions       int    `json:"max_sessions,omitempty"`        // Most sessions open at once, 0 for no limit
+	HistorySize       int    `json:"history_size,omitempty"`        // Commands kept in each session's history
+	MaxClients        int    `json:"max_clients,omitempty"`         // Most MCP clients connected at once, 0 for no limit
+	MaxClientSessions int    `json:"max_client_sessions,omitempty"` // Most sessions one MCP client may open, 0 for no limit
+	SessionQuota      string `json:"session_quota,omitempty"`       // Most commands each session may run in a window, e.g. "200/1h"
+	IdleTimeout       string `json:"idle_timeout,omitempty"`        // Sessions clients opened are closed after running no command this long, e.g. "30m"; empty for never
+	ReadBuffer        int    `json:"read_buffer,omitempty"`         // Bytes buffered when reading from a server connection, 0 for 4096
+	MaxPacketSize     int    `json:"max_packet_size,omitempty"`     // Largest packet accepted from a server, 0 for the protocol's 4096
+}
+
+// Bounds of the read path limits, matching those the rcon package applies.
+const (
+	minReadBuffer  = 16
+	maxReadBuffer  = 1 << 20
+	minPacketLimit = 4096
+	maxPacketLimit = 16 << 20
+)
+
+// IdleAfter returns how long a session a client opened may run no command
+// before it is closed, 0 if it is never closed for being idle.
+func (l Limits) IdleAfter() time.Duration {
+	d, _ := time.ParseDuration(l.IdleTimeout)
+	return d
+}
+
+// Quota caps how many commands may run within a sliding window of time.
+// It is written as the number of commands and the window, e.g. "200/1h".
+type Quota struct {
+	Commands int           // Commands allowed in any window
+	Window   time.Duration // Length of the window
+}
+
+// ParseQuota parses a quota such as "200/1h" or "50/10m". An empty string
+// yields the zero Quota, which sets no limit.
+func ParseQuota(s string) (Quota, error) {
+	if s == "" {
+		return Quota{}, nil
+	}
+	commands, window, ok := strings.Cut(s, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(commands))
+	if !ok || err != nil || n <= 0 {
+		return Quota{}, fmt.Errorf("invalid quota %q, want a number of commands per duration such as 200/1h", s)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || d <= 0 {
+		return Quota{}, fmt.Errorf("invalid quota %q, want a number of commands per duration such as 200/1h", s)
+	}
+	return Quota{Commands: n, Window: d}, nil
+}
+
+// ParseCacheTTL parses how long a profile reuses the output of read-only
+// commands, such as "5s". An empty string means never.
+func ParseCacheTTL(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid cache_ttl %q, want a duration such as 5s", s)
+	}
+	return d, nil
+}
+
+// IsZero reports whether the quota sets no limit.
+func (q Quota) IsZero() bool {
+	return q.Commands == 0
+}
+
+// String formats the quota as ParseQuota reads it, e.g. "200/1h".
+func (q Quota) String() string {
+	window := q.Window.String()
+	if strings.HasSuffix(window, "m0s") {
+		window = strings.TrimSuffix(window, "0s")
+	}
+	if strings.HasSuffix(window, "h0m") {
+		window = strings.TrimSuffix(window, "0m")
+	}
+	return fmt.Sprintf("%d/%s", q.Commands, window)
+}
+
+// Policies restricts the commands the MCP server sends to RCON servers.
+// Commands are matched by name, the first word of the command without a
+// leading slash, ignoring case.
+type Policies struct {
+	Allow           []string `json:"allow,omitempty"`            // If set, only these commands may run
+	Deny            []string `json:"deny,omitempty"`             // Commands that may never run; takes precedence over allow
+	ReadOnly        bool     `json:"read_only,omitempty"`        // Only query commands may run, and only on configured profiles
+	StrictPasswords bool     `json:"strict_passwords,omitempty"` // Tools take no passwords; servers are reached through profiles
+	LuaAllow        []string `json:"lua_allow,omitempty"`        // If set, Lua code may only run if it fully matches one of these regular expressions
+}
+
+// ReadOnlyCommands lists the commands that only report server state on
+// the supported games. In read-only mode no other command may run.
+var ReadOnlyCommands = []string{
+	"admins", "banlist", "cvarlist", "evolution", "help", "info", "list", "listplayers",
+	"maps", "mspt", "ping", "players", "seed", "showplayers", "stats", "status", "tps",
+	"uptime", "users", "version",
+	// Functions of the Satisfactory API that only read the server's state
+	"EnumerateSessions", "GetAdvancedGameSettings", "GetServerOptions", "HealthCheck", "QueryServerState",
+}
+
+// LuaCommands lists the commands that run the Lua code following them:
+// Factorio's, and Garry's Mod's for the server and client realms. Policies
+// can name all of them as "lua".
+var LuaCommands = []string{"c", "command", "measured-command", "silent-command", "lua_run", "lua_run_cl"}
+
+// IsLua reports whether command is one of LuaCommands.
+func IsLua(command string) bool {
+	name := CommandName(command)
+	return slices.ContainsFunc(LuaCommands, func(n string) bool { return strings.EqualFold(n, name) })
+}
+
+// LuaCode returns the Lua code a command of LuaCommands runs: everything
+// after its name.
+func LuaCode(command string) string {
+	command = strings.TrimSpace(command)
+	i := strings.IndexFunc(command, unicode.IsSpace)
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSpace(command[i:])
+}
+
+// IsReadOnly reports whether command is one of ReadOnlyCommands, and so
+// are all the commands a console of game t chains after it; see Commands.
+func IsReadOnly(t game.Type, command string) bool {
+	for _, c := range Commands(t, command) {
+		name := CommandName(c)
+		if !slices.ContainsFunc(ReadOnlyCommands, func(n string) bool { return strings.EqualFold(n, name) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// Allows reports whether the policies permit command to run on a server of
+// game t, and so every command chained in it; see Commands. The name "lua"
+// in the allow and deny lists stands for every one of LuaCommands.
+func (p Policies) Allows(t game.Type, command string) bool {
+	_, refused := p.Refused(t, command)
+	return !refused
+}
+
+// Refused returns the first of the commands a console of game t chains in
+// command that the policies refuse, and whether there is one.
+func (p Policies) Refused(t game.Type, command string) (string, bool) {
+	for _, c := range Commands(t, command) {
+		if !p.allowsOne(t, c) {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// allowsOne reports whether the policies permit command, a single command
+// of game t.
+func (p Policies) allowsOne(t game.Type, command string) bool {
+	name := CommandName(command)
+	lua := IsLua(command)
+	matches := func(names []string) bool {
+		return slices.ContainsFunc(names, func(n string) bool {
+			return strings.EqualFold(n, name) || lua && strings.EqualFold(n, "lua")
+		})
+	}
+	if matches(p.Deny) {
+		return false
+	}
+	if p.ReadOnly && !IsReadOnly(t, command) {
+		return false
+	}
+	if lua && !p.AllowsLua(LuaCode(command)) {
+		return false
+	}
+	return len(p.Allow) == 0 || matches(p.Allow)
+}
+
+// AllowsLua reports whether code fully matches one of the LuaAllow
+// patterns, or no patterns are set. Invalid patterns match nothing.
+func (p Policies) AllowsLua(code string) bool {
+	if len(p.LuaAllow) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(p.LuaAllow, func(pattern string) bool {
+		re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		return err == nil && re.MatchString(code)
+	})
+}
+
+// validate checks the LuaAllow patterns.
+func (p Policies) validate() error {
+	var errs []error
+	for _, pattern := range p.LuaAllow {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid lua_allow pattern %q: %w", pattern, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CommandName returns the name policies match a command by: its first word
+// without a leading slash. A request of the Satisfactory API, a JSON object,
+// is named by its function, or for RunCommand by the command it runs.
+func CommandName(command string) string {
+	if name, ok := apiFunctionName(command); ok {
+		return name
+	}
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(fields[0], "/")
+}
+
+// factorioLuaCommands lists the LuaCommands that run the rest of the line
+// as Lua, in which ';' separates statements rather than commands.
+var factorioLuaCommands = []string{"c", "command", "measured-command", "silent-command"}
+
+// Commands returns the commands a console of game t runs for command.
+// Source engine consoles, among others, run every part of a line separated
+// by ';' outside double quotes as a command of its own, so policies must
+// judge each part: "status; quit" would otherwise pass as the read-only
+// "status". On Factorio, whose Lua commands keep the rest of the line, a
+// line starting with one is a single command; a request of the Satisfactory
+// API is one command unless RunCommand runs a console line. Empty parts are
+// left out; a command without any is returned as it is.
+func Commands(t game.Type, command string) []string {
+	if _, ok := apiFunctionName(command); ok {
+		if inner, ok := runCommandLine(command); ok {
+			return Commands(t, inner)
+		}
+		return []string{command}
+	}
+
+	var commands []string
+	add := func(part string) {
+		if strings.TrimSpace(part) != "" {
+			commands = append(commands, part)
+		}
+	}
+	start, quoted := 0, false
+	for i := 0; i < len(command); i++ {
+		switch command[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if quoted {
+				continue
+			}
+			part := command[start:i]
+			name := CommandName(part)
+			if start == 0 && t == game.Factorio && slices.ContainsFunc(factorioLuaCommands, func(n string) bool { return strings.EqualFold(n, name) }) {
+				return []string{command}
+			}
+			add(part)
+			start = i + 1
+		}
+	}
+	add(command[start:])
+	if len(commands) == 0 {
+		return []string{command}
+	}
+	return commands
+}
+
+// apiFunctionName returns the name of command if it is a request of the
+// Satisfactory API.
+func apiFunctionName(command string) (string, bool) {
+	function, line, ok := apiRequest(command)
+	if !ok || !strings.EqualFold(function, "RunCommand") {
+		return function, ok
+	}
+	return CommandName(line), true
+}
+
+// runCommandLine returns the console line command runs if it is a
+// RunCommand request of the Satisfactory API.
+func runCommandLine(command string) (string, bool) {
+	function, line, ok := apiRequest(command)
+	return line, ok && strings.EqualFold(function, "RunCommand")
+}
+
+// apiRequest returns the function of command if it is a request of the
+// Satisfactory API, which the rcon package posts as it is, and the console
+// line it runs if the function is RunCommand.
+func apiRequest(command string) (function, line string, ok bool) {
+	command = strings.TrimSpace(command)
+	if !strings.HasPrefix(command, "{") {
+		return "", "", false
+	}
+	var req struct {
+		Function string          `json:"function"`
+		Data     json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(command), &req); err != nil {
+		return "", "", false
+	}
+	if !strings.EqualFold(req.Function, "RunCommand") {
+		return req.Function, "", true
+	}
+	var data struct {
+		Command string `json:"Command"`
+	}
+	if err := json.Unmarshal(req.Data, &data); err != nil {
+		return "", "", false
+	}
+	return req.Function, data.Command, true
+}
+
+// MCP transports the server can be reached over.
+const (
+	TransportStdio = "stdio" // A single client that spawned the server, over stdin/stdout
+	TransportHTTP  = "http"  // Streamable HTTP, for many network clients
+	TransportSSE   = "sse"   // HTTP with server-sent events, for older network clients
+)
+
+// DefaultListen is the address network transports listen on by default.
+const DefaultListen = "127.0.0.1:8080"
+
+// DefaultPath is the URL path network transports serve MCP clients on by default.
+const DefaultPath = "/mcp"
+
+// URL paths of the liveness and readiness probes network transports serve.
+const (
+	HealthPath = "/healthz"
+	ReadyPath  = "/readyz"
+)
+
+// Transport selects how MCP clients reach the server.
+type Transport struct {
+	Type          string   `json:"type,omitempty"`           // "stdio" (default), "http" or "sse"
+	Listen        string   `json:"listen,omitempty"`         // Address network transports listen on, default DefaultListen
+	Path          string   `json:"path,omitempty"`           // URL path of the MCP endpoint, default DefaultPath
+	Allow         []string `json:"allow,omitempty"`          // If set, only clients from these IP ranges are served
+	Deny          []string `json:"deny,omitempty"`           // Clients from these IP ranges are refused; takes precedence over allow
+	ReadySessions bool     `json:"ready_sessions,omitempty"` // The server is only ready while every preloaded session is connected
+}
+
+// Kind returns the transport type, defaulting to stdio.
+func (t Transport) Kind() string {
+	if t.Type == "" {
+		return TransportStdio
+	}
+	return t.Type
+}
+
+// Address returns the address network transports listen on.
+func (t Transport) Address() string {
+	if t.Listen == "" {
+		return DefaultListen
+	}
+	return t.Listen
+}
+
+// Endpoint returns the URL path network transports serve MCP clients on.
+func (t Transport) Endpoint() string {
+	if t.Path == "" {
+		return DefaultPath
+	}
+	return t.Path
+}
+
+// ParsePrefixes parses IP ranges in CIDR notation, such as "10.8.0.0/24".
+// A bare address stands for itself alone.
+func ParsePrefixes(ranges []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(ranges))
+	for _, r := range ranges {
+		if addr, err := netip.ParseAddr(r); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP range %q, want an address or CIDR such as 10.8.0.0/24", r)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Log formats.
+const (
+	LogFormatText = "text" // key=value pairs, the default
+	LogFormatJSON = "json" // One JSON object per line
+)
+
+// Logging controls how verbose the log is, its format and where it goes.
+type Logging struct {
+	Level  string   `json:"level,omitempty"`  // "debug", "info" (default), "warn" or "error"
+	Format string   `json:"format,omitempty"` // "text" (default) or "json"
+	File   string   `json:"file,omitempty"`   // Log file to append to instead of stderr
+	Quiet  bool     `json:"quiet,omitempty"`  // Omit the startup and ready messages
+	Redact []string `json:"redact,omitempty"` // Regular expressions masked in logs and tool results
+}
+
+// LevelName returns the log level, defaulting to info.
+func (l Logging) LevelName() string {
+	if l.Level == "" {
+		return "info"
+	}
+	return l.Level
+}
+
+// FormatName returns the log format, defaulting to text.
+func (l Logging) FormatName() string {
+	if l.Format == "" {
+		return LogFormatText
+	}
+	return l.Format
+}
+
+// DefaultErrorWindow is the number of recent commands of a session whose
+// error rate is checked against Alerts.ErrorRate.
+const DefaultErrorWindow = 20
+
+// Alerts sets when the server warns about slow commands and about sessions
+// whose commands start failing. Warnings are logged, along with the
+// session's recent commands, and optionally sent to MCP clients.
+type Alerts struct {
+	SlowCommand string  `json:"slow_command,omitempty"` // Latency beyond which a command is slow, e.g. "2s"; empty for no warning
+	ErrorRate   float64 `json:"error_rate,omitempty"`   // Share of recent commands failing that is a spike, e.g. 0.5; 0 for no warning
+	ErrorWindow int     `json:"error_window,omitempty"` // Recent commands the error rate is taken over, 20 by default
+	Notify      bool    `json:"notify,omitempty"`       // Also send the warnings to MCP clients as log notifications
+}
+
+// SlowThreshold returns the latency beyond which a command is slow, 0 if
+// slow commands are not warned about.
+func (a Alerts) SlowThreshold() time.Duration {
+	d, _ := time.ParseDuration(a.SlowCommand)
+	return d
+}
+
+// Window returns the number of recent commands the error rate is taken
+// over, defaulting to DefaultErrorWindow.
+func (a Alerts) Window() int {
+	if a.ErrorWindow <= 0 {
+		return DefaultErrorWindow
+	}
+	return a.ErrorWindow
+}
+
+// Responses controls how the output of server commands is rewritten
+// before MCP clients see it.
+type Responses struct {
+	Redact []RedactRule `json:"redact,omitempty"` // Rules applied in order to every response
+}
+
+// RedactRule replaces personal data such as IP addresses in server
+// responses: the matches of a built-in preset or of a regular expression.
+type RedactRule struct {
+	Preset  string `json:"preset,omitempty"`  // Built-in rule: "ipv4" or "steamid"
+	Pattern string `json:"pattern,omitempty"` // Regular expression, instead of a preset
+	Replace string `json:"replace,omitempty"` // Replacement, "[REDACTED]" or the preset's by default; $1 refers to a group
+}
+
+// Rules compiles the redaction rules.
+func (r Responses) Rules() (redact.Rules, error) {
+	rules := make(redact.Rules, 0, len(r.Redact))
+	for i, rr := range r.Redact {
+		rule, err := redact.NewRule(rr.Preset, rr.Pattern, rr.Replace)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// DefaultGameLogBacklog is the number of log lines kept for each session
+// when GameLogs.Backlog is not set.
+const DefaultGameLogBacklog = 500
+
+// GameLogs controls the receiver of the log streams Source engine servers
+// send over UDP once registered with logaddress_add. It is disabled unless
+// Listen is set.
+type GameLogs struct {
+	Listen    string `json:"listen,omitempty"`    // UDP address the receiver listens on, e.g. ":27500"
+	Advertise string `json:"advertise,omitempty"` // Address servers are told to send logs to, e.g. behind NAT; by default the local address of the RCON connection with Listen's port
+	Backlog   int    `json:"backlog,omitempty"`   // Log lines kept for each session, default DefaultGameLogBacklog
+}
+
+// Lines returns how many log lines are kept for each session.
+func (g GameLogs) Lines() int {
+	if g.Backlog == 0 {
+		return DefaultGameLogBacklog
+	}
+	return g.Backlog
+}
+
+// Audit defaults.
+const (
+	DefaultAuditMaxSizeMB = 10 // Size an audit file grows to before it is rotated
+	DefaultAuditMaxFiles  = 5  // Rotated audit files kept besides the current one
+)
+
+// Audit controls the audit log, an append-only JSONL record of every
+// connect, execute and disconnect. It is disabled unless File is set.
+type Audit struct {
+	File      string `json:"file,omitempty"`        // Audit file to append to
+	MaxSizeMB int    `json:"max_size_mb,omitempty"` // Size in MB before rotation, default DefaultAuditMaxSizeMB
+	MaxFiles  int    `json:"max_files,omitempty"`   // Rotated files kept, default DefaultAuditMaxFiles
+}
+
+// MaxSize returns the size in bytes an audit file may reach before it is
+// rotated.
+func (a Audit) MaxSize() int64 {
+	if a.MaxSizeMB == 0 {
+		return DefaultAuditMaxSizeMB << 20
+	}
+	return int64(a.MaxSizeMB) << 20
+}
+
+// Rotated returns how many rotated audit files are kept.
+func (a Audit) Rotated() int {
+	if a.MaxFiles == 0 {
+		return DefaultAuditMaxFiles
+	}
+	return a.MaxFiles
+}
+
+// Tools controls which MCP tools the server registers and under what names,
+// so the server can sit alongside other MCP servers without collisions.
+type Tools struct {
+	Prefix    string   `json:"prefix,omitempty"`     // Replaces the "rcon_" prefix of every tool name
+	Disabled  []string `json:"disabled,omitempty"`   // Tools that are not registered, by their default name
+	GamePacks []string `json:"game_packs,omitempty"` // Game packs whose helper tools are registered, e.g. "minecraft"
+}
+
+// GamePacks lists the game packs that can be enabled, each adding helper
+// tools for one game.
+var GamePacks = []string{"minecraft", "source", "factorio", "ark", "rust", "palworld", "zomboid", "valheim", "battleye", "gmod", "console"}
+
+// toolPrefixPattern restricts prefixes to characters allowed in tool names.
+var toolPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]*$`)
+
+// Enabled reports whether the tool with the given default name is enabled.
+func (t Tools) Enabled(name string) bool {
+	return !slices.Contains(t.Disabled, name)
+}
+
+// Name returns the name a tool is registered under. With no prefix
+// configured the default name is kept, as are the names of game pack
+// tools, which do not start with "rcon_".
+func (t Tools) Name(name string) string {
+	if t.Prefix == "" || !strings.HasPrefix(name, "rcon_") {
+		return name
+	}
+	return t.Prefix + strings.TrimPrefix(name, "rcon_")
+}
+
+// MaxPool is the most connections a profile's sessions may each open.
+const MaxPool = 16
+
+// Profile describes a named RCON server that clients may connect to
+// without knowing its address or password.
+type Profile struct {
+	Name              string            `json:"name"`                         // Unique profile name
+	Address           string            `json:"address"`                      // Server address in "host:port" format
+	Password          string            `json:"password,omitempty"`           // RCON password
+	PasswordEnv       string            `json:"password_env,omitempty"`       // Environment variable holding the RCON password
+	PasswordKeyring   string            `json:"password_keyring,omitempty"`   // OS keyring account holding the RCON password
+	PasswordEncrypted string            `json:"password_encrypted,omitempty"` // RCON password encrypted by "encrypt-password"
+	PasswordRef       string            `json:"password_ref,omitempty"`       // Reference to the RCON password in a secret store, e.g. "vault:kv/game/prod#rcon"
+	Role              string            `json:"role,omitempty"`               // Role limiting the commands sessions may run, e.g. "viewer"
+	Quota             string            `json:"quota,omitempty"`              // Most commands all its sessions together may run in a window, e.g. "200/1h"
+	Pool              int               `json:"pool,omitempty"`               // Connections each session opens to run commands in parallel, 1 if unset
+	CacheTTL          string            `json:"cache_ttl,omitempty"`          // How long the output of read-only commands is reused, e.g. "5s"; unset for never
+	AutoReconnect     bool              `json:"auto_reconnect,omitempty"`     // Reconnect and retry a command once when its connection drops
+	Game              string            `json:"game,omitempty"`               // Game type, e.g. "minecraft"
+	Protocol          string            `json:"protocol,omitempty"`           // Wire protocol, one of Protocols; "generic-telnet", "generic-websocket" or "generic-http" with a telnet, websocket or http section, "webrcon" for rust, "telnet" for 7dtd, "tshock" for terraria, "battleye" for dayz and arma3, "satisfactory" for satisfactory, "rcon" for other games if unset
+	QueryAddress      string            `json:"query_address,omitempty"`      // Address of the server's status query port when it differs from the default for its game
+	Telnet            *TelnetConsole    `json:"telnet,omitempty"`             // Telnet console spoken to over "generic-telnet", which it selects if protocol is unset
+	WebSocket         *WebSocketConsole `json:"websocket,omitempty"`          // WebSocket console spoken to over "generic-websocket", which it selects if protocol is unset
+	HTTP              *HTTPConsole      `json:"http,omitempty"`               // HTTP API spoken to over "generic-http", which it selects if protocol is unset
+	Tags              []string          `json:"tags,omitempty"`               // Free-form labels such as "prod" or "lobby"
+}
+
+// Protocols lists the wire protocols profiles can be reached over: Source
+// RCON, the WebRCON of Rust servers, the telnet console of 7 Days to Die,
+// the REST API of TShock for Terraria, the BattlEye RCon of DayZ and Arma,
+// the HTTPS API of Satisfactory and the telnet and WebSocket consoles and
+// HTTP APIs a profile's telnet, websocket and http sections describe.
+var Protocols = []string{"rcon", "webrcon", "telnet", "tshock", "battleye", "satisfactory", "generic-telnet", "generic-websocket", "generic-http"}
+
+// GameProtocols maps the games whose servers are reached over a protocol
+// other than Source RCON to that protocol: Rust's own WebRCON, the telnet
+// console of 7 Days to Die, TShock's REST API for Terraria, BattlEye RCon
+// for DayZ and Arma and the HTTPS API of Satisfactory.
+var GameProtocols = map[string]string{
+	"rust":         "webrcon",
+	"7dtd":         "telnet",
+	"terraria":     "tshock",
+	"dayz":         "battleye",
+	"arma3":        "battleye",
+	"satisfactory": "satisfactory",
+}
+
+// WireProtocol returns the protocol the profile's server is reached over:
+// Protocol if set, otherwise "generic-telnet", "generic-websocket" or
+// "generic-http" if it describes a telnet or WebSocket console or an HTTP
+// API, the one GameProtocols gives for its game and Source RCON for the
+// rest.
+func (p *Profile) WireProtocol() string {
+	if p.Protocol != "" {
+		return p.Protocol
+	}
+	if p.Telnet != nil {
+		return "generic-telnet"
+	}
+	if p.WebSocket != nil {
+		return "generic-websocket"
+	}
+	if p.HTTP != nil {
+		return "generic-http"
+	}
+	if protocol, ok := GameProtocols[p.Game]; ok {
+		return protocol
+	}
+	return "rcon"
+}
+
+// HasPassword reports whether the profile stores a password or names where
+// to find one.
+func (p *Profile) HasPassword() bool {
+	return p.Password != "" || p.PasswordEnv != "" || p.PasswordKeyring != "" || p.PasswordEncrypted != "" || p.PasswordRef != ""
+}
+
+// ResolvePassword returns the profile's password. When PasswordEnv is set
+// the password is read from that environment variable through lookup,
+// normally os.LookupEnv, and when PasswordKeyring is set it is read from
+// the OS keyring. Either is read each time the profile is used, so the
+// password is never stored in the config file. PasswordEncrypted is
+// decrypted with the key from the secrets package, also found through
+// lookup. PasswordRef is looked up in the secret store its scheme names.
+// Returns an error if the variable is not set, the keyring has no entry,
+// the password cannot be decrypted or the secret store fails.
+func (p *Profile) ResolvePassword(lookup func(string) (string, bool)) (string, error) {
+	switch {
+	case p.PasswordEnv != "":
+		password, ok := lookup(p.PasswordEnv)
+		if !ok {
+			return "", fmt.Errorf("profile %s: environment variable %s is not set", p.Name, p.PasswordEnv)
+		}
+		return password, nil
+	case p.PasswordKeyring != "":
+		password, err := keyring.Get(p.PasswordKeyring)
+		if err != nil {
+			return "", fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+		return password, nil
+	case p.PasswordEncrypted != "":
+		key, err := secrets.LoadKey(lookup)
+		if err != nil {
+			return "", fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+		password, err := secrets.Decrypt(key, p.PasswordEncrypted)
+		if err != nil {
+			return "", fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+		return password, nil
+	case p.PasswordRef != "":
+		password, err := secrets.Resolve(context.Background(), p.PasswordRef)
+		if err != nil {
+			return "", fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+		return password, nil
+	}
+	return p.Password, nil
+}
+
+// Load reads and validates a JSON configuration file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// Save validates the configuration and writes it to path as indented JSON.
+// The file is only readable by its owner since profiles hold passwords.
+func (c *Config) Save(path string) error {
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// Validate checks that every profile has a unique name and an address,
+// that the tool prefix only uses characters allowed in tool names, that
+// limits are not negative, and that the log settings and transport are known.
+func (c *Config) Validate() error {
+	var errs []error
+	if !toolPrefixPattern.MatchString(c.Tools.Prefix) {
+		errs = append(errs, fmt.Errorf("tools: prefix %q may only contain letters, digits, '_', '-' and '.'", c.Tools.Prefix))
+	}
+	for _, pack := range c.Tools.GamePacks {
+		if !slices.Contains(GamePacks, pack) {
+			errs = append(errs, fmt.Errorf("tools: unknown game pack %q, want one of %s", pack, strings.Join(GamePacks, ", ")))
+		}
+	}
+	if err := c.Policies.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("policies: %w", err))
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Roles)) {
+		if err := c.Roles[name].validate(); err != nil {
+			errs = append(errs, fmt.Errorf("roles: %s: %w", name, err))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Consoles)) {
+		if err := c.Consoles[name].validate(); err != nil {
+			errs = append(errs, fmt.Errorf("consoles: %s: %w", name, err))
+		}
+	}
+	if c.Limits.MaxSessions < 0 {
+		errs = append(errs, errors.New("limits: max_sessions must not be negative"))
+	}
+	if c.Limits.HistorySize < 0 {
+		errs = append(errs, errors.New("limits: history_size must not be negative"))
+	}
+	if c.Limits.MaxClients < 0 {
+		errs = append(errs, errors.New("limits: max_clients must not be negative"))
+	}
+	if c.Limits.MaxClientSessions < 0 {
+		errs = append(errs, errors.New("limits: max_client_sessions must not be negative"))
+	}
+	if _, err := ParseQuota(c.Limits.SessionQuota); err != nil {
+		errs = append(errs, fmt.Errorf("limits: session_quota: %w", err))
+	}
+	if t := c.Limits.IdleTimeout; t != "" {
+		if d, err := time.ParseDuration(t); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("limits: invalid idle_timeout %q, want a duration such as 30m", t))
+		}
+	}
+	if b := c.Limits.ReadBuffer; b != 0 && (b < minReadBuffer || b > maxReadBuffer) {
+		errs = append(errs, fmt.Errorf("limits: read_buffer must be between %d and %d bytes", minReadBuffer, maxReadBuffer))
+	}
+	if m := c.Limits.MaxPacketSize; m != 0 && (m < minPacketLimit || m > maxPacketLimit) {
+		errs = append(errs, fmt.Errorf("limits: max_packet_size must be between %d and %d bytes", minPacketLimit, maxPacketLimit))
+	}
+	switch strings.ToLower(c.Logging.LevelName()) {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("logging: unknown level %q, want debug, info, warn or error", c.Logging.Level))
+	}
+	switch c.Logging.FormatName() {
+	case LogFormatText, LogFormatJSON:
+	default:
+		errs = append(errs, fmt.Errorf("logging: unknown format %q, want text or json", c.Logging.Format))
+	}
+	if _, err := redact.Compile(c.Logging.Redact); err != nil {
+		errs = append(errs, fmt.Errorf("logging: %w", err))
+	}
+	if a := c.Alerts.SlowCommand; a != "" {
+		if d, err := time.ParseDuration(a); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("alerts: invalid slow_command %q, want a duration such as 2s", a))
+		}
+	}
+	if c.Alerts.ErrorRate < 0 || c.Alerts.ErrorRate > 1 {
+		errs = append(errs, errors.New("alerts: error_rate must be between 0 and 1"))
+	}
+	if c.Alerts.ErrorWindow < 0 {
+		errs = append(errs, errors.New("alerts: error_window must not be negative"))
+	}
+	if _, err := c.Responses.Rules(); err != nil {
+		errs = append(errs, fmt.Errorf("responses: %w", err))
+	}
+	if c.Audit.MaxSizeMB < 0 {
+		errs = append(errs, errors.New("audit: max_size_mb must not be negative"))
+	}
+	if c.Audit.MaxFiles < 0 {
+		errs = append(errs, errors.New("audit: max_files must not be negative"))
+	}
+	if l := c.GameLogs.Listen; l != "" {
+		if _, _, err := net.SplitHostPort(l); err != nil {
+			errs = append(errs, fmt.Errorf("game_logs: invalid listen address %q, want host:port such as :27500", l))
+		}
+	}
+	if a := c.GameLogs.Advertise; a != "" {
+		if host, _, err := net.SplitHostPort(a); err != nil || host == "" {
+			errs = append(errs, fmt.Errorf("game_logs: invalid advertise address %q, want host:port", a))
+		}
+	}
+	if c.GameLogs.Backlog < 0 {
+		errs = append(errs, errors.New("game_logs: backlog must not be negative"))
+	}
+	switch c.Transport.Kind() {
+	case TransportStdio, TransportHTTP, TransportSSE:
+	default:
+		errs = append(errs, fmt.Errorf("transport: unknown type %q, want stdio, http or sse", c.Transport.Type))
+	}
+	switch endpoint := c.Transport.Endpoint(); {
+	case !strings.HasPrefix(endpoint, "/"):
+		errs = append(errs, fmt.Errorf("transport: path %q must start with /", c.Transport.Path))
+	case endpoint == HealthPath, endpoint == ReadyPath:
+		errs = append(errs, fmt.Errorf("transport: path %q is reserved for health probes", c.Transport.Path))
+	}
+	for _, ranges := range [][]string{c.Transport.Allow, c.Transport.Deny} {
+		if _, err := ParsePrefixes(ranges); err != nil {
+			errs = append(errs, fmt.Errorf("transport: %w", err))
+		}
+	}
+	seen := make(map[string]bool)
+	for i, p := range c.Profiles {
+		switch {
+		case p.Name == "":
+			errs = append(errs, fmt.Errorf("profile %d: name is required", i))
+		case seen[p.Name]:
+			errs = append(errs, fmt.Errorf("profile %s: duplicate name", p.Name))
+		}
+		seen[p.Name] = true
+
+		if p.Address == "" {
+			errs = append(errs, fmt.Errorf("profile %s: address is required", p.Name))
+		}
+		sources := 0
+		for _, source := range []string{p.Password, p.PasswordEnv, p.PasswordKeyring, p.PasswordEncrypted, p.PasswordRef} {
+			if source != "" {
+				sources++
+			}
+		}
+		if sources > 1 {
+			errs = append(errs, fmt.Errorf("profile %s: set only one of password, password_env, password_keyring, password_encrypted and password_ref", p.Name))
+		}
+		if p.PasswordRef != "" {
+			if _, _, err := secrets.ParseRef(p.PasswordRef); err != nil {
+				errs = append(errs, fmt.Errorf("profile %s: %w", p.Name, err))
+			}
+		}
+		if _, err := c.RolePolicies(p.Role); err != nil {
+			errs = append(errs, fmt.Errorf("profile %s: %w", p.Name, err))
+		}
+		if _, err := ParseQuota(p.Quota); err != nil {
+			errs = append(errs, fmt.Errorf("profile %s: %w", p.Name, err))
+		}
+		if p.Pool < 0 || p.Pool > MaxPool {
+			errs = append(errs, fmt.Errorf("profile %s: pool must be between 1 and %d connections", p.Name, MaxPool))
+		}
+		if _, err := ParseCacheTTL(p.CacheTTL); err != nil {
+			errs = append(errs, fmt.Errorf("profile %s: %w", p.Name, err))
+		}
+		if p.Protocol != "" && !slices.Contains(Protocols, p.Protocol) {
+			errs = append(errs, fmt.Errorf("profile %s: unknown protocol %q, want one of %s", p.Name, p.Protocol, strings.Join(Protocols, ", ")))
+		}
+		if p.Telnet != nil {
+			if p.WireProtocol() != "generic-telnet" {
+				errs = append(errs, fmt.Errorf("profile %s: a telnet section needs the generic-telnet protocol, not %q", p.Name, p.WireProtocol()))
+			}
+			if err := p.Telnet.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("profile %s: telnet: %w", p.Name, err))
+			}
+		}
+		if p.WebSocket != nil {
+			if p.WireProtocol() != "generic-websocket" {
+				errs = append(errs, fmt.Errorf("profile %s: a websocket section needs the generic-websocket protocol, not %q", p.Name, p.WireProtocol()))
+			}
+			if err := p.WebSocket.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("profile %s: websocket: %w", p.Name, err))
+			}
+		}
+		if p.HTTP != nil {
+			if p.WireProtocol() != "generic-http" {
+				errs = append(errs, fmt.Errorf("profile %s: an http section needs the generic-http protocol, not %q", p.Name, p.WireProtocol()))
+			}
+			if err := p.HTTP.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("profile %s: http: %w", p.Name, err))
+			}
+		} else if p.WireProtocol() == "generic-http" {
+			errs = append(errs, fmt.Errorf("profile %s: the generic-http protocol needs an http section", p.Name))
+		}
+	}
+	if err := c.validateSessions(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Profile returns the profile with the given name.
+// Returns an error if no such profile is configured.
+func (c *Config) Profile(name string) (*Profile, error) {
+	for _, p := range c.Profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("profile %s not found", name)
+}
+
+// AddProfile adds a profile to the configuration.
+// Returns an error if a profile with the same name already exists.
+func (c *Config) AddProfile(p *Profile) error {
+	if _, err := c.Profile(p.Name); err == nil {
+		return fmt.Errorf("profile %s already exists", p.Name)
+	}
+	c.Profiles = append(c.Profiles, p)
+	return nil
+}
+
+// RemoveProfile removes the profile with the given name.
+// Returns an error if no such profile is configured.
+func (c *Config) RemoveProfile(name string) error {
+	for i, p := range c.Profiles {
+		if p.Name == name {
+			c.Profiles = slices.Delete(c.Profiles, i, i+1)
+			return nil
+		}
+	}
+	return fmt.Errorf("profile %s not found", name)
+}
+
+// SortedProfiles returns the profiles ordered by name.
+// The returned slice is a copy and can be safely modified.
+func (c *Config) SortedProfiles() []*Profile {
+	profiles := append([]*Profile(nil), c.Profiles...)
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles
+}
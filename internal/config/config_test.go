@@ -0,0 +1,638 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/keyring"
+	"github.com/mjmorales/rcon-mcp-server/internal/secrets"
+)
+
+// writeConfig writes content to a temporary config file and returns its path.
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantErr     bool
+		errContains string
+		wantNames   []string
+	}{
+		{
+			name: "valid profiles",
+			content: `{"profiles": [
+				{"name": "survival", "address": "mc.example.com:25575", "password": "pw", "game": "minecraft", "tags": ["prod"]},
+				{"name": "creative", "address": "mc.example.com:25576"}
+			]}`,
+			wantNames: []string{"survival", "creative"},
+		},
+		{
+			name:      "empty config",
+			content:   `{}`,
+			wantNames: nil,
+		},
+		{
+			name:        "invalid tool prefix",
+			content:     `{"tools": {"prefix": "mc tools "}}`,
+			wantErr:     true,
+			errContains: "prefix",
+		},
+		{
+			name:        "unknown transport",
+			content:     `{"transport": {"type": "websocket"}}`,
+			wantErr:     true,
+			errContains: "unknown type",
+		},
+		{
+			name:        "unknown log format",
+			content:     `{"logging": {"format": "xml"}}`,
+			wantErr:     true,
+			errContains: "unknown format",
+		},
+		{
+			name:        "invalid redaction pattern",
+			content:     `{"logging": {"redact": ["("]}}`,
+			wantErr:     true,
+			errContains: "invalid redaction pattern",
+		},
+		{
+			name:        "unknown role",
+			content:     `{"profiles": [{"name": "a", "address": "x:1", "role": "janitor"}]}`,
+			wantErr:     true,
+			errContains: "unknown role",
+		},
+		{
+			name:        "invalid password reference",
+			content:     `{"profiles": [{"name": "a", "address": "x:1", "password_ref": "kv/game/prod"}]}`,
+			wantErr:     true,
+			errContains: "invalid secret reference",
+		},
+		{
+			name:        "invalid transport range",
+			content:     `{"transport": {"type": "http", "allow": ["10.8.0.0/24", "vpn"]}}`,
+			wantErr:     true,
+			errContains: `invalid IP range "vpn"`,
+		},
+		{
+			name:        "invalid game log advertise address",
+			content:     `{"game_logs": {"listen": ":27500", "advertise": ":27500"}}`,
+			wantErr:     true,
+			errContains: `game_logs: invalid advertise address ":27500"`,
+		},
+		{
+			name:        "unknown protocol",
+			content:     `{"profiles": [{"name": "rust", "address": "localhost:28016", "protocol": "ssh"}]}`,
+			wantErr:     true,
+			errContains: `unknown protocol "ssh"`,
+		},
+		{
+			name:        "invalid telnet prompt",
+			content:     `{"profiles": [{"name": "indie", "address": "localhost:2323", "telnet": {"prompt": "(> "}}]}`,
+			wantErr:     true,
+			errContains: `profile indie: telnet: invalid prompt "(> "`,
+		},
+		{
+			name:        "telnet section on another protocol",
+			content:     `{"profiles": [{"name": "indie", "address": "localhost:2323", "protocol": "telnet", "telnet": {}}]}`,
+			wantErr:     true,
+			errContains: "a telnet section needs the generic-telnet protocol",
+		},
+		{
+			name:        "websocket request without command",
+			content:     `{"profiles": [{"name": "panel", "address": "localhost:8080", "websocket": {"url": "wss://panel/ws", "request": "{\"cmd\": \"status\"}"}}]}`,
+			wantErr:     true,
+			errContains: `profile panel: websocket: request "{\"cmd\": \"status\"}" has no {command} placeholder`,
+		},
+		{
+			name:        "websocket url not a websocket",
+			content:     `{"profiles": [{"name": "panel", "address": "localhost:8080", "websocket": {"url": "https://panel/ws"}}]}`,
+			wantErr:     true,
+			errContains: `must be a ws:// or wss:// URL`,
+		},
+		{
+			name:        "http without command",
+			content:     `{"profiles": [{"name": "panel", "address": "panel:443", "http": {"url": "https://panel/api/command", "body": "{\"command\": \"status\"}"}}]}`,
+			wantErr:     true,
+			errContains: `profile panel: http: neither the url nor the body has a {command} placeholder`,
+		},
+		{
+			name:        "generic-http without http section",
+			content:     `{"profiles": [{"name": "panel", "address": "panel:443", "protocol": "generic-http"}]}`,
+			wantErr:     true,
+			errContains: "the generic-http protocol needs an http section",
+		},
+		{
+			name:        "invalid lua_allow pattern",
+			content:     `{"roles": {"builder": {"lua_allow": ["game.print("]}}}`,
+			wantErr:     true,
+			errContains: `roles: builder: invalid lua_allow pattern`,
+		},
+		{
+			name:        "invalid console player pattern",
+			content:     `{"consoles": {"mygame": {"players": "who", "player_pattern": "(?P<name>"}}}`,
+			wantErr:     true,
+			errContains: `consoles: mygame: invalid player_pattern`,
+		},
+		{
+			name:        "console broadcast without placeholder",
+			content:     `{"consoles": {"mygame": {"broadcast": "say"}}}`,
+			wantErr:     true,
+			errContains: `consoles: mygame: broadcast "say" has no {message} placeholder`,
+		},
+		{
+			name:        "pool too large",
+			content:     `{"profiles": [{"name": "prod", "address": "localhost:25575", "pool": 100}]}`,
+			wantErr:     true,
+			errContains: "pool must be between 1 and 16",
+		},
+		{
+			name:        "invalid idle timeout",
+			content:     `{"limits": {"idle_timeout": "0s"}}`,
+			wantErr:     true,
+			errContains: `limits: invalid idle_timeout "0s"`,
+		},
+		{
+			name:        "max packet size below the protocol's",
+			content:     `{"limits": {"max_packet_size": 1024}}`,
+			wantErr:     true,
+			errContains: "limits: max_packet_size must be between 4096 and 16777216 bytes",
+		},
+		{
+			name:        "unknown game pack",
+			content:     `{"tools": {"game_packs": ["minecraft", "tetris"]}}`,
+			wantErr:     true,
+			errContains: `tools: unknown game pack "tetris"`,
+		},
+		{
+			name:        "invalid cache TTL",
+			content:     `{"profiles": [{"name": "prod", "address": "localhost:25575", "cache_ttl": "-5s"}]}`,
+			wantErr:     true,
+			errContains: `profile prod: invalid cache_ttl "-5s"`,
+		},
+		{
+			name:        "transport path reserved for probes",
+			content:     `{"transport": {"type": "http", "path": "/healthz"}}`,
+			wantErr:     true,
+			errContains: "reserved for health probes",
+		},
+		{
+			name:        "invalid quota",
+			content:     `{"limits": {"session_quota": "200"}, "profiles": [{"name": "prod", "address": "localhost:25575", "quota": "50/1h"}]}`,
+			wantErr:     true,
+			errContains: `limits: session_quota: invalid quota "200"`,
+		},
+		{
+			name:        "invalid alerts",
+			content:     `{"alerts": {"slow_command": "2", "error_rate": 1.5}}`,
+			wantErr:     true,
+			errContains: `alerts: invalid slow_command "2"`,
+		},
+		{
+			name:        "unknown response redaction preset",
+			content:     `{"responses": {"redact": [{"preset": "steamid"}, {"preset": "ip"}]}}`,
+			wantErr:     true,
+			errContains: `responses: rule 1: unknown redaction preset "ip"`,
+		},
+		{
+			name:        "malformed json",
+			content:     `{"profiles": [`,
+			wantErr:     true,
+			errContains: "failed to parse",
+		},
+		{
+			name:        "missing address",
+			content:     `{"profiles": [{"name": "a"}]}`,
+			wantErr:     true,
+			errContains: "address is required",
+		},
+		{
+			name:        "duplicate names",
+			content:     `{"profiles": [{"name": "a", "address": "x:1"}, {"name": "a", "address": "x:2"}]}`,
+			wantErr:     true,
+			errContains: "duplicate name",
+		},
+		{
+			name:        "password and password_env",
+			content:     `{"profiles": [{"name": "a", "address": "x:1", "password": "pw", "password_env": "A_PW"}]}`,
+			wantErr:     true,
+			errContains: "only one of",
+		},
+		{
+			name:        "missing name",
+			content:     `{"profiles": [{"address": "x:1"}]}`,
+			wantErr:     true,
+			errContains: "name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.content))
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			var names []string
+			for _, p := range cfg.Profiles {
+				names = append(names, p.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("Expected profiles %v, got %v", tt.wantNames, names)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestProfile_ResolvePassword(t *testing.T) {
+	keyring.MockInit()
+	if err := keyring.Set("survival", "from-keyring"); err != nil {
+		t.Fatalf("Failed to set keyring entry: %v", err)
+	}
+	encodedKey, _ := secrets.GenerateKey()
+	key, _ := secrets.ParseKey(encodedKey)
+	encrypted, err := secrets.Encrypt(key, "from-file")
+	if err != nil {
+		t.Fatalf("Failed to encrypt password: %v", err)
+	}
+	env := map[string]string{"SURVIVAL_PW": "from-env", secrets.KeyEnv: encodedKey}
+	secrets.Register("test", secrets.ResolverFunc(func(_ context.Context, ref string) (string, error) {
+		if ref == "game/prod" {
+			return "from-store", nil
+		}
+		return "", errors.New("no such secret")
+	}))
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name    string
+		profile Profile
+		want    string
+		wantErr string
+	}{
+		{name: "stored password", profile: Profile{Name: "a", Password: "stored"}, want: "stored"},
+		{name: "no password", profile: Profile{Name: "a"}, want: ""},
+		{name: "environment variable", profile: Profile{Name: "a", PasswordEnv: "SURVIVAL_PW"}, want: "from-env"},
+		{name: "unset environment variable", profile: Profile{Name: "a", PasswordEnv: "MISSING_PW"}, wantErr: "MISSING_PW is not set"},
+		{name: "keyring", profile: Profile{Name: "a", PasswordKeyring: "survival"}, want: "from-keyring"},
+		{name: "missing keyring entry", profile: Profile{Name: "a", PasswordKeyring: "creative"}, wantErr: "no keyring entry for creative"},
+		{name: "encrypted", profile: Profile{Name: "a", PasswordEncrypted: encrypted}, want: "from-file"},
+		{name: "secret store", profile: Profile{Name: "a", PasswordRef: "test:game/prod"}, want: "from-store"},
+		{name: "missing secret", profile: Profile{Name: "a", PasswordRef: "test:game/staging"}, wantErr: "no such secret"},
+		{name: "corrupted encrypted", profile: Profile{Name: "a", PasswordEncrypted: encrypted[:len(encrypted)-4] + "AAAA"}, wantErr: "failed to decrypt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.profile.ResolvePassword(lookup)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ResolvePassword() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Profile(t *testing.T) {
+	cfg := &Config{Profiles: []*Profile{
+		{Name: "zeta", Address: "z:1"},
+		{Name: "alpha", Address: "a:1"},
+	}}
+
+	p, err := cfg.Profile("alpha")
+	if err != nil || p.Address != "a:1" {
+		t.Errorf("Expected alpha profile, got %+v, %v", p, err)
+	}
+	if _, err := cfg.Profile("missing"); err == nil {
+		t.Error("Expected error for missing profile")
+	}
+
+	sorted := cfg.SortedProfiles()
+	if sorted[0].Name != "alpha" || sorted[1].Name != "zeta" {
+		t.Errorf("Expected profiles sorted by name, got %v, %v", sorted[0].Name, sorted[1].Name)
+	}
+	if cfg.Profiles[0].Name != "zeta" {
+		t.Error("SortedProfiles must not reorder the config")
+	}
+}
+
+func TestTools(t *testing.T) {
+	tests := []struct {
+		name        string
+		tools       Tools
+		tool        string
+		wantEnabled bool
+		wantName    string
+	}{
+		{name: "defaults", tool: "rcon_execute", wantEnabled: true, wantName: "rcon_execute"},
+		{name: "prefix replaces rcon_", tools: Tools{Prefix: "mc_"}, tool: "rcon_execute", wantEnabled: true, wantName: "mc_execute"},
+		{name: "disabled", tools: Tools{Disabled: []string{"rcon_connect"}}, tool: "rcon_connect", wantEnabled: false, wantName: "rcon_connect"},
+		{name: "game pack tools keep their name", tools: Tools{Prefix: "game_"}, tool: "mc_say", wantEnabled: true, wantName: "mc_say"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tools.Enabled(tt.tool); got != tt.wantEnabled {
+				t.Errorf("Enabled(%q) = %v, want %v", tt.tool, got, tt.wantEnabled)
+			}
+			if got := tt.tools.Name(tt.tool); got != tt.wantName {
+				t.Errorf("Name(%q) = %q, want %q", tt.tool, got, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestConfig_SaveRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg := &Config{}
+	if err := cfg.AddProfile(&Profile{Name: "a", Address: "a:1", Password: "pw"}); err != nil {
+		t.Fatalf("AddProfile failed: %v", err)
+	}
+	if err := cfg.AddProfile(&Profile{Name: "b", Address: "b:1"}); err != nil {
+		t.Fatalf("AddProfile failed: %v", err)
+	}
+	if err := cfg.AddProfile(&Profile{Name: "a", Address: "c:1"}); err == nil {
+		t.Error("Expected error adding a duplicate profile")
+	}
+	if err := cfg.RemoveProfile("b"); err != nil {
+		t.Fatalf("RemoveProfile failed: %v", err)
+	}
+	if err := cfg.RemoveProfile("b"); err == nil {
+		t.Error("Expected error removing a missing profile")
+	}
+
+	if err := cfg.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("Expected file mode 0600, got %o", perm)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.Profiles) != 1 || loaded.Profiles[0].Name != "a" || loaded.Profiles[0].Password != "pw" {
+		t.Errorf("Unexpected profiles after round trip: %+v", loaded.Profiles)
+	}
+
+	invalid := &Config{Profiles: []*Profile{{Name: "x"}}}
+	if err := invalid.Save(path); err == nil {
+		t.Error("Expected Save to reject an invalid config")
+	}
+}
+
+func TestPolicies_Allows(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies Policies
+		game     game.Type
+		command  string
+		want     bool
+	}{
+		{name: "no policies", command: "stop", want: true},
+		{name: "denied", policies: Policies{Deny: []string{"stop"}}, command: "stop", want: false},
+		{name: "denied with slash and case", policies: Policies{Deny: []string{"stop"}}, command: "/STOP now", want: false},
+		{name: "not on allow list", policies: Policies{Allow: []string{"list"}}, command: "op steve", want: false},
+		{name: "on allow list", policies: Policies{Allow: []string{"list"}}, command: "list uuids", want: true},
+		{name: "deny wins over allow", policies: Policies{Allow: []string{"kick"}, Deny: []string{"kick"}}, command: "kick steve", want: false},
+		{name: "read-only query", policies: Policies{ReadOnly: true}, command: "/list", want: true},
+		{name: "read-only blocks changes", policies: Policies{ReadOnly: true}, command: "say hi", want: false},
+		{name: "read-only wins over allow", policies: Policies{ReadOnly: true, Allow: []string{"stop"}}, command: "stop", want: false},
+		{name: "read-only with deny", policies: Policies{ReadOnly: true, Deny: []string{"list"}}, command: "list", want: false},
+		{name: "read-only blocks chained changes", policies: Policies{ReadOnly: true}, command: "status ;quit", want: false},
+		{name: "read-only chain of queries", policies: Policies{ReadOnly: true}, command: "status; players", want: true},
+		{name: "read-only blocks changes chained in RunCommand", policies: Policies{ReadOnly: true}, command: `{"function": "RunCommand", "data": {"Command": "list;SaveGame a"}}`, want: false},
+		{name: "lua denied as a group", policies: Policies{Deny: []string{"lua"}}, command: "/silent-command game.print(1)", want: false},
+		{name: "lua group leaves other commands", policies: Policies{Deny: []string{"lua"}}, command: "/players online", want: true},
+		{name: "lua allowed as a group", policies: Policies{Allow: []string{"lua"}}, command: "/c rcon.print(game.tick)", want: true},
+		{name: "lua matching a pattern", policies: Policies{LuaAllow: []string{`rcon\.print\(game\.tick\)`}}, command: "/silent-command  rcon.print(game.tick) ", want: true},
+		{name: "lua matching no pattern", policies: Policies{LuaAllow: []string{`rcon\.print\(game\.tick\)`}}, command: "/c rcon.print(game.tick) game.speed = 10", want: false},
+		{name: "gmod lua denied as a group", policies: Policies{Deny: []string{"lua"}}, command: "lua_run print(1)", want: false},
+		{name: "gmod client lua denied as a group", policies: Policies{Deny: []string{"lua"}}, command: "lua_run_cl print(1)", want: false},
+		{name: "gmod lua matching a pattern", policies: Policies{LuaAllow: []string{`print\(#player\.GetAll\(\)\)`}}, command: "lua_run print(#player.GetAll())", want: true},
+		{name: "gmod lua matching no pattern", policies: Policies{LuaAllow: []string{`print\(#player\.GetAll\(\)\)`}}, command: "lua_run RunConsoleCommand(\"quit\")", want: false},
+		{name: "deny list covers chained commands", policies: Policies{Deny: []string{"lua"}}, command: "echo x;lua_run print(1)", want: false},
+		{name: "allow list covers chained commands", policies: Policies{Allow: []string{"say"}}, command: "say hi; stop", want: false},
+		{name: "lua patterns cover chained lua", policies: Policies{LuaAllow: []string{`print\(1\)`}}, command: `lua_run print(1); lua_run RunConsoleCommand("quit")`, want: false},
+		{name: "factorio lua keeps the line", policies: Policies{Deny: []string{"quit"}}, game: game.Factorio, command: "/c game.print(1); quit()", want: true},
+		{name: "lua name chained on another game", policies: Policies{Deny: []string{"quit"}}, game: game.Source, command: "c; quit", want: false},
+		{name: "lua name chained on an unknown game", policies: Policies{ReadOnly: true}, game: game.Unknown, command: "c; quit", want: false},
+		{name: "quoted semicolon in one command", policies: Policies{Deny: []string{"stop"}}, command: `say "a; stop"`, want: true},
+		{name: "api function denied", policies: Policies{Deny: []string{"shutdown"}}, command: `{"function": "Shutdown"}`, want: false},
+		{name: "api function read-only", policies: Policies{ReadOnly: true}, command: `{"function": "QueryServerState"}`, want: true},
+		{name: "api RunCommand named by its command", policies: Policies{Deny: []string{"quit"}}, command: `{"function": "RunCommand", "data": {"Command": "quit"}}`, want: false},
+		{name: "api RunCommand of an allowed command", policies: Policies{Allow: []string{"SaveGame"}}, command: `{"function": "runcommand", "data": {"Command": "SaveGame a"}}`, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policies.Allows(tt.game, tt.command); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		game    game.Type
+		command string
+		want    []string
+	}{
+		{command: "status", want: []string{"status"}},
+		{command: "status ;quit", want: []string{"status ", "quit"}},
+		{command: "say hi; ; kick steve;", want: []string{"say hi", " kick steve"}},
+		{command: `say "a;b"; quit`, want: []string{`say "a;b"`, " quit"}},
+		{game: game.Factorio, command: "/c game.print(1); game.print(2)", want: []string{"/c game.print(1); game.print(2)"}},
+		{game: game.Unknown, command: "/c game.print(1); quit", want: []string{"/c game.print(1)", " quit"}},
+		{command: "lua_run print(1); quit", want: []string{"lua_run print(1)", " quit"}},
+		{command: `{"function": "RunCommand", "data": {"Command": "list;stop"}}`, want: []string{"list", "stop"}},
+		{command: `{"function": "QueryServerState", "data": {"note": "a;b"}}`, want: []string{`{"function": "QueryServerState", "data": {"note": "a;b"}}`}},
+		{command: "", want: []string{""}},
+	}
+	for _, tt := range tests {
+		if got := Commands(tt.game, tt.command); !slices.Equal(got, tt.want) {
+			t.Errorf("Commands(%q, %q) = %q, want %q", tt.game, tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestConfig_RolePolicies(t *testing.T) {
+	cfg := &Config{
+		Policies: Policies{Deny: []string{"kill"}},
+		Roles: map[string]Policies{
+			RoleOperator: {Deny: []string{"stop", "ban"}},
+			"moderator":  {Allow: []string{"kick", "say", "list"}},
+		},
+	}
+
+	tests := []struct {
+		role    string
+		command string
+		want    bool
+	}{
+		{role: "", command: "stop", want: true},
+		{role: "", command: "kill", want: false},
+		{role: RoleViewer, command: "list", want: true},
+		{role: RoleViewer, command: "say hi", want: false},
+		{role: RoleOperator, command: "ban griefer", want: false},
+		{role: RoleOperator, command: "op someone", want: true}, // Redefined without the built-in deny list
+		{role: RoleAdmin, command: "stop", want: true},
+		{role: RoleAdmin, command: "kill", want: false},
+		{role: "moderator", command: "/kick griefer", want: true},
+		{role: "moderator", command: "stop", want: false},
+		{role: "missing", command: "list", want: false},
+	}
+	for _, tt := range tests {
+		if got := cfg.AllowsCommand(tt.role, game.Unknown, tt.command); got != tt.want {
+			t.Errorf("AllowsCommand(%q, %q) = %v, want %v", tt.role, tt.command, got, tt.want)
+		}
+	}
+
+	if _, err := cfg.RolePolicies("missing"); err == nil || !strings.Contains(err.Error(), "admin, moderator, operator, viewer") {
+		t.Errorf("Expected an unknown role error listing the roles, got %v", err)
+	}
+	if p, _ := (&Config{}).RolePolicies(RoleOperator); !p.Allows(game.Unknown, "kick") || p.Allows(game.Unknown, "stop") || p.Allows(game.Unknown, "lua_run print(1)") {
+		t.Error("Expected the built-in operator role to deny admin commands only")
+	}
+}
+
+func TestConfig_Console(t *testing.T) {
+	cfg := &Config{Consoles: map[string]Console{
+		"eco":    {Players: "/online", Broadcast: "/say {message}"},
+		"mygame": {Players: "who"},
+	}}
+	if c, err := cfg.Console("eco"); err != nil || c.Players != "/online" {
+		t.Errorf("Expected the configured templates to replace the built-in ones, got %+v, %v", c, err)
+	}
+	if c, err := cfg.Console("unturned"); err != nil || c.Broadcast != "say {message}" {
+		t.Errorf("Expected the built-in templates, got %+v, %v", c, err)
+	}
+	if _, err := cfg.Console("missing"); err == nil || !strings.Contains(err.Error(), "eco, mygame, unturned") {
+		t.Errorf("Expected an unknown game error listing the games, got %v", err)
+	}
+	for name, c := range DefaultConsoles {
+		if err := c.validate(); err != nil {
+			t.Errorf("Built-in console %s is invalid: %v", name, err)
+		}
+	}
+}
+
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := ParsePrefixes([]string{"10.8.0.7/24", "192.168.1.5", "::ffff:192.168.1.6", "2001:db8::/32"})
+	if err != nil {
+		t.Fatalf("ParsePrefixes failed: %v", err)
+	}
+	want := []string{"10.8.0.0/24", "192.168.1.5/32", "192.168.1.6/32", "2001:db8::/32"}
+	for i, p := range prefixes {
+		if p.String() != want[i] {
+			t.Errorf("Expected %s, got %s", want[i], p)
+		}
+	}
+	if _, err := ParsePrefixes([]string{"10.0.0.0/40"}); err == nil {
+		t.Error("Expected an error for an invalid prefix length")
+	}
+}
+
+func TestParseQuota(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Quota
+		str     string
+		wantErr bool
+	}{
+		{in: "", want: Quota{}},
+		{in: "200/1h", want: Quota{Commands: 200, Window: time.Hour}, str: "200/1h"},
+		{in: "50 / 90s", want: Quota{Commands: 50, Window: 90 * time.Second}, str: "50/1m30s"},
+		{in: "10/30m", want: Quota{Commands: 10, Window: 30 * time.Minute}, str: "10/30m"},
+		{in: "10/30s", want: Quota{Commands: 10, Window: 30 * time.Second}, str: "10/30s"},
+		{in: "200", wantErr: true},
+		{in: "0/1h", wantErr: true},
+		{in: "5/-1h", wantErr: true},
+		{in: "5/hour", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseQuota(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseQuota(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseQuota(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if tt.str != "" && got.String() != tt.str {
+			t.Errorf("Quota.String() = %q, want %q", got.String(), tt.str)
+		}
+	}
+}
+
+func TestParseCacheTTL(t *testing.T) {
+	for in, want := range map[string]time.Duration{"": 0, "5s": 5 * time.Second, "0s": 0} {
+		if got, err := ParseCacheTTL(in); err != nil || got != want {
+			t.Errorf("ParseCacheTTL(%q) = %v, %v, want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"5", "-1s", "soon"} {
+		if _, err := ParseCacheTTL(in); err == nil {
+			t.Errorf("ParseCacheTTL(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+func TestProfile_WireProtocol(t *testing.T) {
+	tests := []struct {
+		profile Profile
+		want    string
+	}{
+		{Profile{}, "rcon"},
+		{Profile{Game: "rust"}, "webrcon"},
+		{Profile{Game: "rust", Protocol: "rcon"}, "rcon"},
+		{Profile{Telnet: &TelnetConsole{Prompt: "^> $"}}, "generic-telnet"},
+		{Profile{WebSocket: &WebSocketConsole{URL: "ws://panel/ws"}}, "generic-websocket"},
+		{Profile{HTTP: &HTTPConsole{URL: "https://panel/api/{command}"}}, "generic-http"},
+	}
+	for _, tt := range tests {
+		if got := tt.profile.WireProtocol(); got != tt.want {
+			t.Errorf("WireProtocol() of %+v = %q, want %q", tt.profile, got, tt.want)
+		}
+	}
+}
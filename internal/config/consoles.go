@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MessagePlaceholder stands for the message in a console's Broadcast
+// template.
+const MessagePlaceholder = "{message}"
+
+// Console holds the command templates of a game whose RCON is a plain line
+// console, which the console game pack runs in place of game-specific code.
+type Console struct {
+	Players       string `json:"players,omitempty"`        // Command listing the players online, e.g. "players"
+	PlayerPattern string `json:"player_pattern,omitempty"` // Regular expression matching each line of its reply that names a player; the "name" group, or else the whole match, is the name
+	Broadcast     string `json:"broadcast,omitempty"`      // Command showing a message to every player, with {message} in its place, e.g. "say {message}"
+}
+
+// DefaultConsoles are the consoles of the line-console games known out of
+// the box, by the game name profiles give. The consoles section of the
+// config file can redefine them or add games of its own.
+var DefaultConsoles = map[string]Console{
+	"unturned": {
+		Players:       "players",
+		PlayerPattern: `^\s*7656\d{13}\s*[-:]?\s*(?P<name>\S.*?)\s*$`,
+		Broadcast:     "say {message}",
+	},
+	"eco": {
+		Players:       "/players",
+		PlayerPattern: `^\s*(?P<name>[^\s:,][^:,]*?)\s*$`,
+		Broadcast:     "/announce {message}",
+	},
+}
+
+// Console returns the console templates of game: the ones defined in the
+// config file, or else the built-in ones. Returns an error if neither
+// defines the game.
+func (c *Config) Console(game string) (Console, error) {
+	if t, ok := c.Consoles[game]; ok {
+		return t, nil
+	}
+	if t, ok := DefaultConsoles[game]; ok {
+		return t, nil
+	}
+	return Console{}, fmt.Errorf("no console templates for game %q, want one of %s", game, strings.Join(c.ConsoleGames(), ", "))
+}
+
+// ConsoleGames returns the games with built-in or configured console
+// templates, sorted.
+func (c *Config) ConsoleGames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, consoles := range []map[string]Console{DefaultConsoles, c.Consoles} {
+		for name := range consoles {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PlayerRegexp compiles PlayerPattern.
+func (t Console) PlayerRegexp() (*regexp.Regexp, error) {
+	return regexp.Compile(t.PlayerPattern)
+}
+
+// validate checks that the player pattern compiles and that the broadcast
+// template has a place for the message.
+func (t Console) validate() error {
+	var errs []error
+	if _, err := t.PlayerRegexp(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid player_pattern %q: %w", t.PlayerPattern, err))
+	}
+	if t.Players == "" && t.PlayerPattern != "" {
+		errs = append(errs, errors.New("player_pattern is set without a players command"))
+	}
+	if t.Broadcast != "" && !strings.Contains(t.Broadcast, MessagePlaceholder) {
+		errs = append(errs, fmt.Errorf("broadcast %q has no %s placeholder", t.Broadcast, MessagePlaceholder))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ExportVersion is the version of the export document format.
+const ExportVersion = 1
+
+// Export is a portable copy of a configuration for migrating between
+// machines. Secrets are not embedded: passwords are removed and Secrets
+// lists the profiles and sessions whose password has to be supplied on
+// the target machine. Sections left out of a document are not imported.
+type Export struct {
+	Version   int                 `json:"version"`
+	Profiles  []*Profile          `json:"profiles,omitempty"`
+	Sessions  []*Session          `json:"sessions,omitempty"`
+	Tools     *Tools              `json:"tools,omitempty"`
+	Limits    *Limits             `json:"limits,omitempty"`
+	Policies  *Policies           `json:"policies,omitempty"`
+	Roles     map[string]Policies `json:"roles,omitempty"`
+	Consoles  map[string]Console  `json:"consoles,omitempty"`
+	Logging   *Logging            `json:"logging,omitempty"`
+	Audit     *Audit              `json:"audit,omitempty"`
+	Transport *Transport          `json:"transport,omitempty"`
+	Secrets   []string            `json:"secrets,omitempty"` // References such as "profile/survival/password"
+}
+
+// Export returns a copy of the configuration without its secrets.
+func (c *Config) Export() *Export {
+	e := &Export{
+		Version:   ExportVersion,
+		Tools:     &c.Tools,
+		Limits:    &c.Limits,
+		Policies:  &c.Policies,
+		Roles:     c.Roles,
+		Consoles:  c.Consoles,
+		Logging:   &c.Logging,
+		Audit:     &c.Audit,
+		Transport: &c.Transport,
+	}
+	for _, p := range c.Profiles {
+		exported := *p
+		if exported.Password != "" {
+			exported.Password = ""
+			e.Secrets = append(e.Secrets, secretRef("profile", p.Name))
+		}
+		e.Profiles = append(e.Profiles, &exported)
+	}
+	for _, s := range c.Sessions {
+		exported := *s
+		if exported.Password != "" {
+			exported.Password = ""
+			e.Secrets = append(e.Secrets, secretRef("session", s.ID))
+		}
+		e.Sessions = append(e.Sessions, &exported)
+	}
+	return e
+}
+
+// ParseExport decodes an export document.
+func ParseExport(data []byte) (*Export, error) {
+	var e Export
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("failed to parse export: %w", err)
+	}
+	if e.Version != ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d, want %d", e.Version, ExportVersion)
+	}
+	return &e, nil
+}
+
+// Import merges an export document into the configuration. Sections present
+// in the document replace the configuration's. Profiles and sessions are
+// added; one that already exists is an error unless replace is set. A
+// replaced entry keeps its local password, since exports carry none. The
+// returned references name the secrets that are still missing.
+func (c *Config) Import(e *Export, replace bool) ([]string, error) {
+	var errs []error
+	for _, p := range e.Profiles {
+		i := slices.IndexFunc(c.Profiles, func(existing *Profile) bool { return existing.Name == p.Name })
+		switch {
+		case i < 0:
+			c.Profiles = append(c.Profiles, p)
+		case !replace:
+			errs = append(errs, fmt.Errorf("profile %s already exists", p.Name))
+		default:
+			if !p.HasPassword() {
+				p.Password = c.Profiles[i].Password
+			}
+			c.Profiles[i] = p
+		}
+	}
+	for _, s := range e.Sessions {
+		i := slices.IndexFunc(c.Sessions, func(existing *Session) bool { return existing.ID == s.ID })
+		switch {
+		case i < 0:
+			c.Sessions = append(c.Sessions, s)
+		case !replace:
+			errs = append(errs, fmt.Errorf("session %s already exists", s.ID))
+		default:
+			if s.Password == "" {
+				s.Password = c.Sessions[i].Password
+			}
+			c.Sessions[i] = s
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("%w (use replace to overwrite)", err)
+	}
+
+	if e.Tools != nil {
+		c.Tools = *e.Tools
+	}
+	if e.Limits != nil {
+		c.Limits = *e.Limits
+	}
+	if e.Policies != nil {
+		c.Policies = *e.Policies
+	}
+	if e.Roles != nil {
+		c.Roles = e.Roles
+	}
+	if e.Consoles != nil {
+		c.Consoles = e.Consoles
+	}
+	if e.Logging != nil {
+		c.Logging = *e.Logging
+	}
+	if e.Audit != nil {
+		c.Audit = *e.Audit
+	}
+	if e.Transport != nil {
+		c.Transport = *e.Transport
+	}
+
+	var missing []string
+	for _, ref := range e.Secrets {
+		if !c.hasSecret(ref) {
+			missing = append(missing, ref)
+		}
+	}
+	return missing, nil
+}
+
+// secretRef names the password of a profile or session in an export.
+func secretRef(kind, name string) string {
+	return kind + "/" + name + "/password"
+}
+
+// hasSecret reports whether the secret named by ref is set.
+func (c *Config) hasSecret(ref string) bool {
+	for _, p := range c.Profiles {
+		if ref == secretRef("profile", p.Name) {
+			return p.Password != ""
+		}
+	}
+	for _, s := range c.Sessions {
+		if ref == secretRef("session", s.ID) {
+			return s.Password != ""
+		}
+	}
+	return false
+}
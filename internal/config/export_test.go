@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportImport(t *testing.T) {
+	source := &Config{
+		Profiles: []*Profile{
+			{Name: "survival", Address: "mc:25575", Password: "secret", Tags: []string{"prod"}},
+			{Name: "open", Address: "open:25575"},
+		},
+		Sessions: []*Session{{ID: "prod", Profile: "survival"}},
+		Policies: Policies{Deny: []string{"stop"}},
+		Tools:    Tools{Prefix: "mc_"},
+	}
+
+	export := source.Export()
+	data, err := json.Marshal(export)
+	if err != nil {
+		t.Fatalf("Failed to encode export: %v", err)
+	}
+	if strings.Contains(string(data), `"password"`) {
+		t.Errorf("Expected passwords to be left out of the export, got %s", data)
+	}
+	if source.Profiles[0].Password != "secret" {
+		t.Error("Expected Export to leave the configuration unchanged")
+	}
+	if !reflect.DeepEqual(export.Secrets, []string{"profile/survival/password"}) {
+		t.Errorf("Unexpected secret references: %v", export.Secrets)
+	}
+
+	parsed, err := ParseExport(data)
+	if err != nil {
+		t.Fatalf("ParseExport failed: %v", err)
+	}
+	target := &Config{Profiles: []*Profile{{Name: "local", Address: "local:25575"}}}
+	missing, err := target.Import(parsed, false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !reflect.DeepEqual(missing, []string{"profile/survival/password"}) {
+		t.Errorf("Expected the survival password to be missing, got %v", missing)
+	}
+	if len(target.Profiles) != 3 || len(target.Sessions) != 1 {
+		t.Errorf("Expected profiles and sessions to be merged, got %+v", target)
+	}
+	if target.Tools.Prefix != "mc_" || target.Policies.Deny[0] != "stop" {
+		t.Errorf("Expected sections to be imported, got %+v", target)
+	}
+	if err := target.Validate(); err != nil {
+		t.Errorf("Expected imported config to be valid, got %v", err)
+	}
+
+	// Importing again conflicts unless replacing, which keeps local passwords.
+	if _, err := target.Import(parsed, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected a conflict error, got %v", err)
+	}
+	target.Profiles[1].Password = "local-secret"
+	missing, err = target.Import(parsed, true)
+	if err != nil {
+		t.Fatalf("Import with replace failed: %v", err)
+	}
+	if len(missing) != 0 || target.Profiles[1].Password != "local-secret" {
+		t.Errorf("Expected the local password to be kept, got %v, %q", missing, target.Profiles[1].Password)
+	}
+}
+
+func TestParseExport_Version(t *testing.T) {
+	if _, err := ParseExport([]byte(`{"version": 2}`)); err == nil {
+		t.Error("Expected an error for an unsupported version")
+	}
+	if _, err := ParseExport([]byte(`not json`)); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// HTTPConsole describes the HTTP API of a game or panel without a protocol
+// of its own, such as a hosting panel's console endpoint, which profiles
+// reach over the "generic-http" protocol with one request per command.
+// Templates may hold {password} and {command}; their values are escaped
+// for a query in URLs and as in JSON strings in JSON bodies.
+type HTTPConsole struct {
+	Method       string            `json:"method,omitempty"`        // Method of command requests, POST if there is a body and GET otherwise
+	URL          string            `json:"url"`                     // URL of command requests, e.g. "https://panel.example.com/api/client/servers/1a2b/command"; the profile's address is dialed
+	Insecure     bool              `json:"insecure,omitempty"`      // Skip verifying the certificate of an https:// API, for self-signed ones
+	Headers      map[string]string `json:"headers,omitempty"`       // Headers of every request, e.g. {"Authorization": "Bearer {password}"}
+	Body         string            `json:"body,omitempty"`          // Body of command requests, e.g. {"command": "{command}"}; none if unset
+	ResponsePath string            `json:"response_path,omitempty"` // Path of the output in a JSON reply, keys and array indexes separated by dots; the whole body if unset
+	CheckURL     string            `json:"check_url,omitempty"`     // URL requested with GET to check the password on connect and in health checks; nothing is checked if unset
+}
+
+// validate checks that the URLs are HTTP URLs on the same scheme and that
+// the request has a place for the command.
+func (h *HTTPConsole) validate() error {
+	var errs []error
+	for name, raw := range map[string]string{"url": h.URL, "check_url": h.CheckURL} {
+		if raw == "" && name == "check_url" {
+			continue
+		}
+		u, err := url.Parse(strings.NewReplacer("{password}", "", "{command}", "").Replace(raw))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs, fmt.Errorf("%s %q must be an http:// or https:// URL", name, raw))
+		}
+	}
+	if h.CheckURL != "" && strings.HasPrefix(h.URL, "https:") != strings.HasPrefix(h.CheckURL, "https:") {
+		errs = append(errs, errors.New("url and check_url must both be http:// or both be https://"))
+	}
+	if !strings.Contains(h.URL+h.Body, "{command}") {
+		errs = append(errs, errors.New("neither the url nor the body has a {command} placeholder"))
+	}
+	return errors.Join(errs...)
+}
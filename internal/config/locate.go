@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EnvPath is the environment variable naming the config file when no path
+// is given on the command line.
+const EnvPath = "RCON_MCP_CONFIG"
+
+// LookupEnv looks up an environment variable, like os.LookupEnv.
+type LookupEnv func(key string) (string, bool)
+
+// Locate returns the config file to use and whether it must exist. An
+// explicit path wins, then the file named by RCON_MCP_CONFIG, then
+// config.json under the rcon-mcp-server directory of the user's config
+// directory ($XDG_CONFIG_HOME or ~/.config on Linux). The default file is
+// optional; the path is empty if the config directory cannot be determined.
+func Locate(path string, lookup LookupEnv) (string, bool) {
+	if path != "" {
+		return path, true
+	}
+	if env, ok := lookup(EnvPath); ok && env != "" {
+		return env, true
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(dir, "rcon-mcp-server", "config.json"), false
+}
+
+// LoadFrom loads the config file chosen by Locate and applies environment
+// variable overrides. A missing optional file yields an empty configuration.
+func LoadFrom(path string, lookup LookupEnv) (*Config, error) {
+	path, required := Locate(path, lookup)
+
+	cfg := &Config{}
+	if path != "" {
+		loaded, err := Load(path)
+		switch {
+		case err == nil:
+			cfg = loaded
+		case required || !errors.Is(err, fs.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	if err := cfg.ApplyEnv(lookup); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// envOverrides maps environment variables to the settings they override.
+var envOverrides = []struct {
+	key string
+	set func(c *Config, value string) error
+}{
+	{"RCON_MCP_TOOLS_PREFIX", func(c *Config, v string) error { c.Tools.Prefix = v; return nil }},
+	{"RCON_MCP_TOOLS_DISABLED", func(c *Config, v string) error { c.Tools.Disabled = splitList(v); return nil }},
+	{"RCON_MCP_GAME_PACKS", func(c *Config, v string) error { c.Tools.GamePacks = splitList(v); return nil }},
+	{"RCON_MCP_MAX_SESSIONS", func(c *Config, v string) error { return setInt(&c.Limits.MaxSessions, v) }},
+	{"RCON_MCP_HISTORY_SIZE", func(c *Config, v string) error { return setInt(&c.Limits.HistorySize, v) }},
+	{"RCON_MCP_MAX_CLIENTS", func(c *Config, v string) error { return setInt(&c.Limits.MaxClients, v) }},
+	{"RCON_MCP_MAX_CLIENT_SESSIONS", func(c *Config, v string) error { return setInt(&c.Limits.MaxClientSessions, v) }},
+	{"RCON_MCP_SESSION_QUOTA", func(c *Config, v string) error { c.Limits.SessionQuota = v; return nil }},
+	{"RCON_MCP_IDLE_TIMEOUT", func(c *Config, v string) error { c.Limits.IdleTimeout = v; return nil }},
+	{"RCON_MCP_READ_BUFFER", func(c *Config, v string) error { return setInt(&c.Limits.ReadBuffer, v) }},
+	{"RCON_MCP_MAX_PACKET_SIZE", func(c *Config, v string) error { return setInt(&c.Limits.MaxPacketSize, v) }},
+	{"RCON_MCP_POLICY_ALLOW", func(c *Config, v string) error { c.Policies.Allow = splitList(v); return nil }},
+	{"RCON_MCP_POLICY_DENY", func(c *Config, v string) error { c.Policies.Deny = splitList(v); return nil }},
+	{"RCON_MCP_READONLY", func(c *Config, v string) error { return setBool(&c.Policies.ReadOnly, v) }},
+	{"RCON_MCP_STRICT_PASSWORDS", func(c *Config, v string) error { return setBool(&c.Policies.StrictPasswords, v) }},
+	{"RCON_MCP_LOG_LEVEL", func(c *Config, v string) error { c.Logging.Level = v; return nil }},
+	{"RCON_MCP_LOG_FORMAT", func(c *Config, v string) error { c.Logging.Format = v; return nil }},
+	{"RCON_MCP_LOG_FILE", func(c *Config, v string) error { c.Logging.File = v; return nil }},
+	{"RCON_MCP_QUIET", func(c *Config, v string) error { return setBool(&c.Logging.Quiet, v) }},
+	{"RCON_MCP_SLOW_COMMAND", func(c *Config, v string) error { c.Alerts.SlowCommand = v; return nil }},
+	{"RCON_MCP_AUDIT_FILE", func(c *Config, v string) error { c.Audit.File = v; return nil }},
+	{"RCON_MCP_GAME_LOGS_LISTEN", func(c *Config, v string) error { c.GameLogs.Listen = v; return nil }},
+	{"RCON_MCP_GAME_LOGS_ADVERTISE", func(c *Config, v string) error { c.GameLogs.Advertise = v; return nil }},
+	{"RCON_MCP_TRANSPORT", func(c *Config, v string) error { c.Transport.Type = v; return nil }},
+	{"RCON_MCP_LISTEN", func(c *Config, v string) error { c.Transport.Listen = v; return nil }},
+	{"RCON_MCP_PATH", func(c *Config, v string) error { c.Transport.Path = v; return nil }},
+	{"RCON_MCP_ALLOW_IPS", func(c *Config, v string) error { c.Transport.Allow = splitList(v); return nil }},
+	{"RCON_MCP_DENY_IPS", func(c *Config, v string) error { c.Transport.Deny = splitList(v); return nil }},
+	{"RCON_MCP_READY_SESSIONS", func(c *Config, v string) error { return setBool(&c.Transport.ReadySessions, v) }},
+}
+
+// ApplyEnv overrides settings with the RCON_MCP_* environment variables that
+// are set, then validates the result. Lists are comma-separated.
+func (c *Config) ApplyEnv(lookup LookupEnv) error {
+	var errs []error
+	for _, o := range envOverrides {
+		value, ok := lookup(o.key)
+		if !ok {
+			continue
+		}
+		if err := o.set(c, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", o.key, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return c.Validate()
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// setInt parses value as an integer into dst.
+func setInt(dst *int, value string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("invalid number %q", value)
+	}
+	*dst = n
+	return nil
+}
+
+// setBool parses value as a boolean into dst.
+func setBool(dst *bool, value string) error {
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("invalid boolean %q", value)
+	}
+	*dst = b
+	return nil
+}
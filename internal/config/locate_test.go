@@ -0,0 +1,104 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// envMap returns a LookupEnv backed by env.
+func envMap(env map[string]string) LookupEnv {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestLocate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+
+	tests := []struct {
+		name         string
+		path         string
+		env          map[string]string
+		wantPath     string
+		wantRequired bool
+	}{
+		{name: "flag wins", path: "flag.json", env: map[string]string{EnvPath: "env.json"}, wantPath: "flag.json", wantRequired: true},
+		{name: "environment", env: map[string]string{EnvPath: "env.json"}, wantPath: "env.json", wantRequired: true},
+		{name: "xdg default", wantPath: filepath.Join("/xdg", "rcon-mcp-server", "config.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, required := Locate(tt.path, envMap(tt.env))
+			if path != tt.wantPath || required != tt.wantRequired {
+				t.Errorf("Locate() = %q, %v, want %q, %v", path, required, tt.wantPath, tt.wantRequired)
+			}
+		})
+	}
+}
+
+func TestLoadFrom(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := LoadFrom("", envMap(nil))
+	if err != nil || len(cfg.Profiles) != 0 {
+		t.Fatalf("Expected empty config without a file, got %+v, %v", cfg, err)
+	}
+
+	if _, err := LoadFrom(filepath.Join(t.TempDir(), "missing.json"), envMap(nil)); err == nil {
+		t.Error("Expected error for a missing explicit config file")
+	}
+
+	path := writeConfig(t, `{"profiles": [{"name": "a", "address": "a:1"}], "limits": {"max_sessions": 5}, "tools": {"prefix": "mc_"}}`)
+	cfg, err = LoadFrom("", envMap(map[string]string{
+		EnvPath:                     path,
+		"RCON_MCP_MAX_SESSIONS":     "2",
+		"RCON_MCP_POLICY_DENY":      "stop, op,",
+		"RCON_MCP_LOG_FILE":         "/tmp/rcon.log",
+		"RCON_MCP_TOOLS_DISABLED":   "rcon_broadcast",
+		"RCON_MCP_READONLY":         "true",
+		"RCON_MCP_QUIET":            "1",
+		"RCON_MCP_STRICT_PASSWORDS": "true",
+		"RCON_MCP_READY_SESSIONS":   "true",
+	}))
+	if err != nil {
+		t.Fatalf("LoadFrom failed: %v", err)
+	}
+	if cfg.Limits.MaxSessions != 2 {
+		t.Errorf("Expected max_sessions overridden to 2, got %d", cfg.Limits.MaxSessions)
+	}
+	if strings.Join(cfg.Policies.Deny, ",") != "stop,op" {
+		t.Errorf("Expected deny list [stop op], got %v", cfg.Policies.Deny)
+	}
+	if !cfg.Policies.ReadOnly {
+		t.Error("Expected read-only mode enabled")
+	}
+	if !cfg.Policies.StrictPasswords {
+		t.Error("Expected strict password mode enabled")
+	}
+	if !cfg.Logging.Quiet {
+		t.Error("Expected quiet mode enabled")
+	}
+	if !cfg.Transport.ReadySessions {
+		t.Error("Expected readiness to require the preloaded sessions")
+	}
+	if cfg.Logging.File != "/tmp/rcon.log" || cfg.Tools.Prefix != "mc_" || cfg.Tools.Disabled[0] != "rcon_broadcast" {
+		t.Errorf("Unexpected config after overrides: %+v", cfg)
+	}
+
+	for _, env := range []map[string]string{
+		{"RCON_MCP_HISTORY_SIZE": "lots"},
+		{"RCON_MCP_MAX_SESSIONS": "-1"},
+		{"RCON_MCP_READONLY": "maybe"},
+		{"RCON_MCP_STRICT_PASSWORDS": "maybe"},
+		{"RCON_MCP_READY_SESSIONS": "maybe"},
+		{"RCON_MCP_MAX_CLIENTS": "-1"},
+		{"RCON_MCP_MAX_CLIENT_SESSIONS": "many"},
+	} {
+		if _, err := LoadFrom(path, envMap(env)); err == nil {
+			t.Errorf("Expected error for overrides %v", env)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+// Built-in roles a profile can be given.
+const (
+	RoleViewer   = "viewer"   // Only query commands
+	RoleOperator = "operator" // Day-to-day moderation, but not AdminCommands
+	RoleAdmin    = "admin"    // Every command the server policies allow
+)
+
+// AdminCommands lists the commands that stop or reconfigure a server,
+// grant privileges or run arbitrary code on the supported games. The
+// operator role may not run them.
+var AdminCommands = []string{
+	"c", "command", "deop", "doexit", "exec", "lua_run", "lua_run_cl", "measured-command", "op", "quit",
+	"rcon_password", "reload", "restart", "save-off", "shutdown", "silent-command", "stop", "sv_password",
+	// Functions of the Satisfactory API
+	"ApplyAdvancedGameSettings", "ApplyServerOptions", "ClaimServer", "CreateNewGame", "DeleteSaveFile",
+	"DeleteSaveSession", "LoadGame", "SetAdminPassword", "SetClientPassword", "UploadSaveGame",
+}
+
+// DefaultRoles are the command policies of the built-in roles. The roles
+// section of the config file can redefine them or add roles of its own.
+var DefaultRoles = map[string]Policies{
+	RoleViewer:   {ReadOnly: true},
+	RoleOperator: {Deny: AdminCommands},
+	RoleAdmin:    {},
+}
+
+// RolePolicies returns the command policies of role: the ones defined in
+// the config file, or else the built-in ones. A profile without a role is
+// only restricted by the server policies, so the empty role has no policies
+// of its own. Returns an error if the role is not defined.
+func (c *Config) RolePolicies(role string) (Policies, error) {
+	if role == "" {
+		return Policies{}, nil
+	}
+	if p, ok := c.Roles[role]; ok {
+		return p, nil
+	}
+	if p, ok := DefaultRoles[role]; ok {
+		return p, nil
+	}
+	return Policies{}, fmt.Errorf("unknown role %q, want one of %s", role, strings.Join(c.RoleNames(), ", "))
+}
+
+// RoleNames returns the names of the built-in and configured roles, sorted.
+func (c *Config) RoleNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, roles := range []map[string]Policies{DefaultRoles, c.Roles} {
+		for name := range roles {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AllowsCommand reports whether command may run on a session of game t
+// opened with role: both the server policies and the role's policies must
+// permit it.
+func (c *Config) AllowsCommand(role string, t game.Type, command string) bool {
+	p, err := c.RolePolicies(role)
+	return err == nil && c.Policies.Allows(t, command) && p.Allows(t, command)
+}
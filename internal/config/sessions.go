@@ -0,0 +1,59 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Session declares an RCON session the MCP server opens at startup, so it
+// comes up with a known set of sessions instead of waiting for rcon_connect.
+// The server is named by a profile, or by an address and password; explicit
+// values take precedence over the profile's, as with rcon_connect.
+type Session struct {
+	ID       string `json:"session_id"`         // Session ID the tools refer to
+	Name     string `json:"name,omitempty"`     // Friendly name, defaults to the profile name
+	Profile  string `json:"profile,omitempty"`  // Profile supplying the address and password
+	Address  string `json:"address,omitempty"`  // RCON server address (host:port)
+	Password string `json:"password,omitempty"` // RCON server password
+}
+
+// LoadSessions reads a sessions file: a JSON array of session declarations.
+func LoadSessions(path string) ([]*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions file: %w", err)
+	}
+	var sessions []*Session
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse sessions file %s: %w", path, err)
+	}
+	return sessions, nil
+}
+
+// validateSessions checks that every declared session has a unique ID and
+// names a server, through a configured profile or an address.
+func (c *Config) validateSessions() error {
+	var errs []error
+	seen := make(map[string]bool)
+	for i, s := range c.Sessions {
+		switch {
+		case s.ID == "":
+			errs = append(errs, fmt.Errorf("session %d: session_id is required", i))
+			continue
+		case seen[s.ID]:
+			errs = append(errs, fmt.Errorf("session %s: duplicate session_id", s.ID))
+		}
+		seen[s.ID] = true
+
+		if s.Profile != "" {
+			if _, err := c.Profile(s.Profile); err != nil {
+				errs = append(errs, fmt.Errorf("session %s: %w", s.ID, err))
+			}
+		} else if s.Address == "" {
+			errs = append(errs, fmt.Errorf("session %s: a profile or address is required", s.ID))
+		}
+	}
+	return errors.Join(errs...)
+}
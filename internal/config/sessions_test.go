@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadSessions(t *testing.T) {
+	path := writeConfig(t, `[{"session_id": "prod", "profile": "survival"}, {"session_id": "dev", "address": "localhost:25575", "password": "x"}]`)
+	sessions, err := LoadSessions(path)
+	if err != nil {
+		t.Fatalf("LoadSessions failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "prod" || sessions[0].Profile != "survival" || sessions[1].Address != "localhost:25575" {
+		t.Errorf("Unexpected sessions: %+v", sessions)
+	}
+
+	if _, err := LoadSessions(writeConfig(t, `{"session_id": "prod"}`)); err == nil {
+		t.Error("Expected an error for a sessions file that is not an array")
+	}
+}
+
+func TestValidateSessions(t *testing.T) {
+	profiles := []*Profile{{Name: "survival", Address: "mc:25575"}}
+	tests := []struct {
+		name     string
+		sessions []*Session
+		wantErr  string
+	}{
+		{name: "valid", sessions: []*Session{{ID: "a", Profile: "survival"}, {ID: "b", Address: "x:1"}}},
+		{name: "missing id", sessions: []*Session{{Profile: "survival"}}, wantErr: "session_id is required"},
+		{name: "duplicate id", sessions: []*Session{{ID: "a", Address: "x:1"}, {ID: "a", Address: "y:1"}}, wantErr: "duplicate session_id"},
+		{name: "unknown profile", sessions: []*Session{{ID: "a", Profile: "missing"}}, wantErr: "profile missing not found"},
+		{name: "no server", sessions: []*Session{{ID: "a"}}, wantErr: "a profile or address is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Profiles: profiles, Sessions: tt.sessions}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// TelnetConsole describes the telnet console of a game without a protocol
+// of its own, which profiles reach over the "generic-telnet" protocol. Its
+// patterns are regular expressions matched against each line the console
+// sends and against the text it leaves unterminated, as prompts are.
+type TelnetConsole struct {
+	Login       []TelnetStep `json:"login,omitempty"`        // Prompts to answer when logging in, in order; the password after a "Password:" prompt if unset
+	LoginFailed string       `json:"login_failed,omitempty"` // Pattern of the line refusing the login, e.g. "^Access denied"
+	Prompt      string       `json:"prompt,omitempty"`       // Pattern of the prompt shown when ready for a command, e.g. "^> $"; without one, replies end once the console is quiet
+	Terminator  string       `json:"terminator,omitempty"`   // Sent after each command and answer, "\r\n" if unset
+}
+
+// TelnetStep is one prompt of a telnet console's login and its answer.
+type TelnetStep struct {
+	Expect string `json:"expect"`         // Pattern of the prompt, e.g. "^Login: $"
+	Send   string `json:"send,omitempty"` // Answer, with {password} in place of the password; nothing is sent if unset
+}
+
+// validate checks that the patterns compile.
+func (t *TelnetConsole) validate() error {
+	var errs []error
+	for i, step := range t.Login {
+		if step.Expect == "" {
+			errs = append(errs, fmt.Errorf("login step %d needs a pattern to expect", i+1))
+		} else if _, err := regexp.Compile(step.Expect); err != nil {
+			errs = append(errs, fmt.Errorf("invalid login step %d pattern %q: %w", i+1, step.Expect, err))
+		}
+	}
+	for name, pattern := range map[string]string{"login_failed": t.LoginFailed, "prompt": t.Prompt} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", name, pattern, err))
+		}
+	}
+	return errors.Join(errs...)
+}
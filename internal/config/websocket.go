@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// WebSocketConsole describes the WebSocket console of a game or panel
+// without a protocol of its own, which profiles reach over the
+// "generic-websocket" protocol. Templates may hold {password}, and the
+// request {command} and {id}; in JSON templates their values are escaped
+// as in JSON strings. Paths name a value in a JSON reply by the keys and
+// array indexes leading to it, separated by dots, e.g. "data.output".
+type WebSocketConsole struct {
+	URL          string            `json:"url,omitempty"`           // URL of the console, e.g. "wss://panel.example.com/api/console?token={password}"; the profile's address is dialed
+	Insecure     bool              `json:"insecure,omitempty"`      // Skip verifying the certificate of a wss:// console, for self-signed ones
+	Headers      map[string]string `json:"headers,omitempty"`       // Headers of the upgrade request, e.g. {"Authorization": "Bearer {password}"}
+	AuthMessage  string            `json:"auth_message,omitempty"`  // Message sent once connected to log in, e.g. {"event": "auth", "args": ["{password}"]}
+	AuthOK       string            `json:"auth_ok,omitempty"`       // Pattern of the message accepting the login, waited for after auth_message
+	AuthFailed   string            `json:"auth_failed,omitempty"`   // Pattern of the message refusing the login
+	Request      string            `json:"request,omitempty"`       // Template of the message running a command, e.g. {"event": "send command", "args": ["{command}"]}; the command as it is if unset
+	IDPath       string            `json:"id_path,omitempty"`       // Path of the {id} of the request in its reply; if unset, the next message with response_path is the reply
+	ResponsePath string            `json:"response_path,omitempty"` // Path of the output in replies; the whole message if unset
+}
+
+// validate checks that the URL is a WebSocket URL, that the request has a
+// place for the command and that the patterns compile.
+func (w *WebSocketConsole) validate() error {
+	var errs []error
+	if w.URL != "" {
+		u, err := url.Parse(strings.ReplaceAll(w.URL, "{password}", ""))
+		if err != nil || (u.Scheme != "ws" && u.Scheme != "wss") {
+			errs = append(errs, fmt.Errorf("url %q must be a ws:// or wss:// URL", w.URL))
+		}
+	}
+	if w.Request != "" && !strings.Contains(w.Request, "{command}") {
+		errs = append(errs, fmt.Errorf("request %q has no {command} placeholder", w.Request))
+	}
+	for name, pattern := range map[string]string{"auth_ok": w.AuthOK, "auth_failed": w.AuthFailed} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", name, pattern, err))
+		}
+	}
+	return errors.Join(errs...)
+}
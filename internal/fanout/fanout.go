@@ -0,0 +1,98 @@
+// Package fanout runs one task per target on a bounded pool of workers, as
+// the broadcast and multi-execute tools do across RCON sessions. Each task
+// runs under its own deadline within the overall one, and the outcome of
+// every target is reported, including those that never started.
+package fanout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNotStarted is matched by the errors of targets whose task never ran,
+// because the context was done before a worker was free for them.
+var ErrNotStarted = errors.New("not started")
+
+// Options bound the work of Run.
+type Options struct {
+	Workers int           // Most tasks running at once; values below 1 mean 1
+	Timeout time.Duration // Deadline of each task, 0 for none beyond the context's
+}
+
+// Result is the outcome of the task for one target.
+type Result[R any] struct {
+	Value R     // What the task returned, the zero value if it never ran
+	Err   error // Error the task returned, or one matching ErrNotStarted
+}
+
+// Run calls task for every target on at most opts.Workers goroutines and
+// returns the results in the order of targets. Each task gets a context
+// that is done when ctx is, or once opts.Timeout has passed. Once ctx is
+// done the targets no worker has picked up yet are not run; their results
+// carry an error matching both ErrNotStarted and the context's error.
+func Run[T, R any](ctx context.Context, targets []T, opts Options, task func(context.Context, T) (R, error)) []Result[R] {
+	results := make([]Result[R], len(targets))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(opts.Workers, 1), len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = runTask(ctx, targets[i], opts.Timeout, task)
+			}
+		}()
+	}
+
+	for i := range targets {
+		if ctx.Err() == nil {
+			select {
+			case next <- i:
+				continue
+			case <-ctx.Done():
+			}
+		}
+		results[i].Err = fmt.Errorf("%w: %w", ErrNotStarted, ctx.Err())
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// runTask calls task for target under its own deadline, if any.
+func runTask[T, R any](ctx context.Context, target T, timeout time.Duration, task func(context.Context, T) (R, error)) Result[R] {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	value, err := task(ctx, target)
+	return Result[R]{Value: value, Err: err}
+}
+
+// Summary aggregates the results of a Run.
+type Summary struct {
+	Succeeded int   // Targets whose task returned no error
+	Failed    int   // Targets whose task failed or never ran
+	Err       error // The errors of the failed targets joined, nil if none failed
+}
+
+// Summarize counts the targets that succeeded and failed and joins the
+// errors of the failed ones.
+func Summarize[R any](results []Result[R]) Summary {
+	var s Summary
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			s.Failed++
+			errs = append(errs, r.Err)
+		} else {
+			s.Succeeded++
+		}
+	}
+	s.Err = errors.Join(errs...)
+	return s
+}
@@ -0,0 +1,99 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	var running, peak atomic.Int32
+	targets := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	results := Run(context.Background(), targets, Options{Workers: 3}, func(ctx context.Context, n int) (int, error) {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if now <= old || peak.CompareAndSwap(old, now) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if n%4 == 0 {
+			return 0, errors.New("unlucky")
+		}
+		return n * n, nil
+	})
+
+	if len(results) != len(targets) {
+		t.Fatalf("Expected %d results, got %d", len(targets), len(results))
+	}
+	for i, n := range targets {
+		r := results[i]
+		if n%4 == 0 {
+			if r.Err == nil {
+				t.Errorf("Expected result %d to fail, got %+v", i, r)
+			}
+		} else if r.Err != nil || r.Value != n*n {
+			t.Errorf("Result %d = %+v, want %d", i, r, n*n)
+		}
+	}
+	if p := peak.Load(); p > 3 {
+		t.Errorf("Expected at most 3 tasks at once, saw %d", p)
+	}
+
+	s := Summarize(results)
+	if s.Succeeded != 6 || s.Failed != 2 || s.Err == nil {
+		t.Errorf("Summarize = %+v, want 6 succeeded and 2 failed", s)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	results := Run(context.Background(), []time.Duration{time.Second, 0}, Options{Workers: 1, Timeout: 50 * time.Millisecond},
+		func(ctx context.Context, d time.Duration) (string, error) {
+			select {
+			case <-time.After(d):
+				return "done", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		})
+
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Errorf("Expected the slow task to time out, got %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Value != "done" {
+		t.Errorf("Expected the next task to run in full, got %+v", results[1])
+	}
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var started atomic.Int32
+	results := Run(ctx, []int{1, 2, 3}, Options{Workers: 1}, func(ctx context.Context, n int) (int, error) {
+		started.Add(1)
+		cancel()
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+
+	if started.Load() != 1 {
+		t.Errorf("Expected only the first task to start, %d did", started.Load())
+	}
+	for _, r := range results[1:] {
+		if !errors.Is(r.Err, ErrNotStarted) || !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("Expected a not started error, got %v", r.Err)
+		}
+	}
+	if s := Summarize(results); s.Failed != 3 {
+		t.Errorf("Expected every target to fail, got %+v", s)
+	}
+}
+
+func TestSummarize_Empty(t *testing.T) {
+	if s := Summarize[int](nil); s.Succeeded != 0 || s.Failed != 0 || s.Err != nil {
+		t.Errorf("Summarize(nil) = %+v, want zero", s)
+	}
+}
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// arkNoResponse is the reply of ARK to commands that print nothing, which
+// includes both Broadcast and commands it does not know.
+const arkNoResponse = "Server received, But no response"
+
+// ArkPlayer is a player listed by the ARK "ListPlayers" command.
+type ArkPlayer struct {
+	Index int    `json:"index"` // Position in the listing, from 0
+	Name  string `json:"name"`
+	ID    string `json:"id"` // SteamID64 on Survival Evolved, EOS ID on Survival Ascended
+}
+
+// ArkPlayerList is the structured form of the ARK "ListPlayers" command.
+type ArkPlayerList struct {
+	Online  int         `json:"online"`
+	Players []ArkPlayer `json:"players"`
+}
+
+// arkPlayerPattern matches a line of ListPlayers output, "0. Bob, 76561197960290418",
+// whose name may itself contain commas, so the ID is taken from the end.
+var arkPlayerPattern = regexp.MustCompile(`^(\d+)\.\s*(.*),\s*([0-9A-Fa-f]+)$`)
+
+// parseArkListPlayers parses the output of the ARK "ListPlayers" command.
+// ARK pads its replies with blank lines and trailing spaces, and answers
+// "No Players Connected" when the server is empty.
+func parseArkListPlayers(output string) (any, error) {
+	list := &ArkPlayerList{Players: []ArkPlayer{}}
+	text := strings.TrimSpace(output)
+	if strings.HasPrefix(text, "No Players Connected") {
+		return list, nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		m := arkPlayerPattern.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("unrecognized ark ListPlayers line %q", line)
+		}
+		index, _ := strconv.Atoi(m[1])
+		list.Players = append(list.Players, ArkPlayer{Index: index, Name: strings.TrimSpace(m[2]), ID: m[3]})
+	}
+	if len(list.Players) == 0 {
+		return nil, errors.New("unrecognized ark ListPlayers output")
+	}
+	list.Online = len(list.Players)
+	return list, nil
+}
+
+// ValidArkText reports whether text can be passed to an ARK command as its
+// last argument without running another command: ARK's console runs each
+// part of a line separated by "|" as a command of its own.
+func ValidArkText(text string) bool {
+	return !strings.ContainsAny(text, "|\r\n")
+}
+
+// arkChanges holds, for each ARK command that changes the server's state,
+// the pattern of replies in which it took effect. Broadcast only replies
+// that it printed nothing, as ARK does for commands it does not know.
+var arkChanges = map[string]*regexp.Regexp{
+	"saveworld": regexp.MustCompile(`(?i)^World Saved`),
+	"broadcast": regexp.MustCompile(`^` + arkNoResponse),
+	"doexit":    regexp.MustCompile(`(?i)^Exiting`),
+}
+
+// ParseArkChange reads the reply of ARK to command, one of "SaveWorld",
+// "Broadcast" and "DoExit" with its arguments. Other replies are returned
+// as errors carrying the reply.
+func ParseArkChange(command, output string) (*Change, error) {
+	verb, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(command)), " ")
+	done, ok := arkChanges[verb]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoParser, command)
+	}
+	message := strings.TrimSpace(output)
+	switch {
+	case done.MatchString(message):
+		return &Change{Changed: true, Message: message}, nil
+	case message == "":
+		return nil, errors.New("the server sent an empty reply")
+	default:
+		return nil, errors.New(message)
+	}
+}
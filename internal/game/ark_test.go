@@ -0,0 +1,81 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseArkListPlayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []ArkPlayer
+		wantErr bool
+	}{
+		{
+			name:   "survival evolved",
+			output: "\n0. Bob, 76561197960290418 \n1. Smith, John, 76561198000000001 \n ",
+			want: []ArkPlayer{
+				{Index: 0, Name: "Bob", ID: "76561197960290418"},
+				{Index: 1, Name: "Smith, John", ID: "76561198000000001"},
+			},
+		},
+		{
+			name:   "survival ascended",
+			output: "0. Alice, 0002d4c0e1b34c5b9b0f7e1b2a3c4d5e\n",
+			want:   []ArkPlayer{{Index: 0, Name: "Alice", ID: "0002d4c0e1b34c5b9b0f7e1b2a3c4d5e"}},
+		},
+		{name: "empty", output: "No Players Connected \n ", want: []ArkPlayer{}},
+		{name: "unknown command", output: "Server received, But no response!! \n ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(ARK, "ListPlayers", tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			list := result.(*ArkPlayerList)
+			if list.Online != len(tt.want) || !reflect.DeepEqual(list.Players, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, list)
+			}
+		})
+	}
+}
+
+func TestParseArkChange(t *testing.T) {
+	tests := []struct {
+		command, output string
+		wantErr         bool
+	}{
+		{"SaveWorld", "World Saved \n ", false},
+		{"Broadcast Restart in 5 minutes", "Server received, But no response!! \n ", false},
+		{"DoExit", "Exiting... \n ", false},
+		{"SaveWorld", "Server received, But no response!! \n ", true},
+		{"DoExit", "", true},
+	}
+	for _, tt := range tests {
+		change, err := ParseArkChange(tt.command, tt.output)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseArkChange(%q, %q) error = %v, wantErr %v", tt.command, tt.output, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !change.Changed {
+			t.Errorf("ParseArkChange(%q, %q) reported no change", tt.command, tt.output)
+		}
+	}
+}
+
+func TestValidArkText(t *testing.T) {
+	for text, want := range map[string]bool{
+		"Restart in 5 minutes, log off!": true,
+		"hi | DoExit":                    false,
+		"two\nlines":                     false,
+	} {
+		if got := ValidArkText(text); got != want {
+			t.Errorf("ValidArkText(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// battleyeNoReply is the message of a Change for the empty reply BattlEye
+// sends to commands that ran.
+const battleyeNoReply = "the server sent an empty reply, which BattlEye sends for commands that ran"
+
+// BattlEyePlayer is a player listed by the BattlEye "players" command.
+type BattlEyePlayer struct {
+	Number   int    `json:"number"`   // BattlEye's number of the player, which say and kick take
+	Address  string `json:"address"`  // IP address and port the player connects from
+	Ping     int    `json:"ping"`     // In milliseconds
+	GUID     string `json:"guid"`     // BE GUID of the player's account, empty until BattlEye computed it
+	Verified bool   `json:"verified"` // BattlEye checked the GUID with its master server
+	Name     string `json:"name"`
+	Lobby    bool   `json:"lobby,omitempty"` // The player is still in the lobby
+}
+
+// BattlEyePlayerList is the structured form of the BattlEye "players"
+// command.
+type BattlEyePlayerList struct {
+	Online  int              `json:"online"`
+	Players []BattlEyePlayer `json:"players"`
+}
+
+// battleyePlayerPattern matches a line of the table "players" prints,
+// "0   1.2.3.4:2304   47   0123456789abcdef0123456789abcdef(OK) Bob (Lobby)",
+// in which the GUID is "-" until BattlEye computed it.
+var battleyePlayerPattern = regexp.MustCompile(`^(\d+)\s+(\S+)\s+(-?\d+)\s+(?:([0-9a-fA-F]{32})\((OK|\?)\)|-)\s+(.*?)(\s+\(Lobby\))?$`)
+
+// parseBattlEyePlayers parses the output of the BattlEye "players"
+// command: a table under "Players on server:" with a line per player,
+// ended by "(N players in total)".
+func parseBattlEyePlayers(output string) (any, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if !strings.HasPrefix(strings.TrimSpace(lines[0]), "Players on server:") {
+		return nil, errors.New("unrecognized battleye players output")
+	}
+	list := &BattlEyePlayerList{Players: []BattlEyePlayer{}}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "[#]") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "(") {
+			continue
+		}
+		m := battleyePlayerPattern.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("unrecognized battleye players line %q", line)
+		}
+		number, _ := strconv.Atoi(m[1])
+		ping, _ := strconv.Atoi(m[3])
+		list.Players = append(list.Players, BattlEyePlayer{
+			Number:   number,
+			Address:  m[2],
+			Ping:     ping,
+			GUID:     strings.ToLower(m[4]),
+			Verified: m[5] == "OK",
+			Name:     m[6],
+			Lobby:    m[7] != "",
+		})
+	}
+	list.Online = len(list.Players)
+	return list, nil
+}
+
+// BattlEyeText returns text trimmed for the message argument of say, or
+// an error if it cannot be sent as one: the message is the rest of the
+// command, which ends at a line break.
+func BattlEyeText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if strings.ContainsAny(text, "\r\n") {
+		return "", errors.New("battleye messages may not contain line breaks")
+	}
+	return text, nil
+}
+
+// ParseBattlEyeChange reads the reply of a BattlEye server to command, one
+// of "say", "loadBans" and "#shutdown" with its arguments. BattlEye answers
+// them with nothing when they ran; any other reply, such as "Unknown
+// command", is returned as an error carrying the reply.
+func ParseBattlEyeChange(command, output string) (*Change, error) {
+	verb, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(command)), " ")
+	switch verb {
+	case "say", "loadbans", "#shutdown":
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrNoParser, command)
+	}
+	if change, ok := EmptyReply(DayZ, output); ok {
+		return change, nil
+	}
+	return nil, errors.New(strings.TrimSpace(output))
+}
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseBattlEyePlayers(t *testing.T) {
+	output := "Players on server:\n" +
+		"[#] [IP Address]:[Port] [Ping] [GUID] [Name]\n" +
+		"--------------------------------------------------\n" +
+		"0   203.0.113.5:2304      47   0123456789ABCDEF0123456789abcdef(OK) Bob\n" +
+		"1   198.51.100.7:2316     63   0123456789abcdef0123456789abcdef(?) Alice Smith (Lobby)\n" +
+		"2   192.0.2.1:2304        0    - Newcomer\n" +
+		"(3 players in total)\n"
+	result, err := Parse(DayZ, "players", output)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := &BattlEyePlayerList{Online: 3, Players: []BattlEyePlayer{
+		{Number: 0, Address: "203.0.113.5:2304", Ping: 47, GUID: "0123456789abcdef0123456789abcdef", Verified: true, Name: "Bob"},
+		{Number: 1, Address: "198.51.100.7:2316", Ping: 63, GUID: "0123456789abcdef0123456789abcdef", Name: "Alice Smith", Lobby: true},
+		{Number: 2, Address: "192.0.2.1:2304", Name: "Newcomer"},
+	}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Expected %+v, got %+v", want, result)
+	}
+
+	if _, err := Parse(Arma3, "players", "Unknown command"); err == nil {
+		t.Error("Expected a refusal not to parse")
+	}
+	result, err = Parse(Arma3, "players", "Players on server:\n[#] [IP Address]:[Port] [Ping] [GUID] [Name]\n---\n(0 players in total)")
+	if err != nil || result.(*BattlEyePlayerList).Online != 0 {
+		t.Errorf("Expected an empty listing, got %+v, %v", result, err)
+	}
+}
+
+func TestParseBattlEyeChange(t *testing.T) {
+	if change, err := ParseBattlEyeChange("say -1 hello", ""); err != nil || !change.Changed {
+		t.Errorf("Expected an empty reply to be a change, got %+v, %v", change, err)
+	}
+	if _, err := ParseBattlEyeChange("loadBans", "Unknown command"); err == nil || err.Error() != "Unknown command" {
+		t.Errorf("Expected the reply as an error, got %v", err)
+	}
+	if _, err := ParseBattlEyeChange("players", ""); !errors.Is(err, ErrNoParser) {
+		t.Errorf("Expected ErrNoParser, got %v", err)
+	}
+	if _, err := BattlEyeText("two\nlines"); err == nil {
+		t.Error("Expected a message with a line break to be refused")
+	}
+}
@@ -0,0 +1,82 @@
+// Package game identifies the kind of game server behind an RCON connection
+// and provides game-specific knowledge such as output parsers.
+package game
+
+import "regexp"
+
+// Type identifies the game (or engine family) running on an RCON server.
+type Type string
+
+// Known game types. Unknown is used when detection is inconclusive.
+const (
+	Unknown      Type = "unknown"
+	Minecraft    Type = "minecraft"
+	Source       Type = "source"
+	Factorio     Type = "factorio"
+	ARK          Type = "ark"
+	Rust         Type = "rust"
+	Palworld     Type = "palworld"
+	SevenDays    Type = "7dtd" // 7 Days to Die, whose console is reached over telnet
+	Zomboid      Type = "zomboid"
+	Valheim      Type = "valheim"  // Through an RCON mod, as the game has none of its own
+	Terraria     Type = "terraria" // Through the REST API of TShock
+	Squad        Type = "squad"
+	PostScriptum Type = "postscriptum" // Built on Squad's engine, with the same RCON
+	DayZ         Type = "dayz"         // Through BattlEye RCon
+	Arma3        Type = "arma3"        // Through BattlEye RCon
+	Satisfactory Type = "satisfactory" // Through its HTTPS API
+)
+
+// Probes lists the commands sent, in order, to identify a server.
+// They are chosen to be harmless on every supported game: each one is either
+// read-only or rejected as an unknown command. Probing stops at the first
+// conclusive match, so the slash-prefixed Factorio probe comes first to avoid
+// echoing the later ones into Factorio's in-game chat.
+var Probes = []string{"/version", "version", "status", "list"}
+
+// signature associates a probe response pattern with a game type.
+type signature struct {
+	probe   string
+	pattern *regexp.Regexp
+	game    Type
+}
+
+// signatures holds the detection heuristics, checked in order.
+var signatures = []signature{
+	{"/version", regexp.MustCompile(`^\s*\d+\.\d+\.\d+\s*$`), Factorio},
+	{"version", regexp.MustCompile(`This server is running`), Minecraft},
+	{"version", regexp.MustCompile(`(?m)^(Protocol|Exe) version`), Source},
+	{"status", regexp.MustCompile(`(?m)^hostname\s*:`), Source},
+	{"list", regexp.MustCompile(`There are \d+\s*(?:of a max of|/)\s*\d+ players online`), Minecraft},
+	{"", regexp.MustCompile(`Server received, But no response`), ARK},
+}
+
+// Match inspects the response to a single probe command and returns the
+// game type it identifies, or Unknown if the response is inconclusive.
+// Signatures with an empty probe apply to the response of any probe.
+func Match(probe, response string) Type {
+	for _, sig := range signatures {
+		if sig.probe != "" && sig.probe != probe {
+			continue
+		}
+		if sig.pattern.MatchString(response) {
+			return sig.game
+		}
+	}
+	return Unknown
+}
+
+// Detect returns the game type identified by a set of probe responses,
+// keyed by probe command. Probes are considered in the order of Probes.
+func Detect(responses map[string]string) Type {
+	for _, probe := range Probes {
+		response, ok := responses[probe]
+		if !ok {
+			continue
+		}
+		if game := Match(probe, response); game != Unknown {
+			return game
+		}
+	}
+	return Unknown
+}
@@ -0,0 +1,115 @@
+package game
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		probe    string
+		response string
+		want     Type
+	}{
+		{
+			name:     "factorio version",
+			probe:    "/version",
+			response: "1.1.100",
+			want:     Factorio,
+		},
+		{
+			name:     "minecraft list",
+			probe:    "list",
+			response: "There are 2 of a max of 20 players online: Alice, Bob",
+			want:     Minecraft,
+		},
+		{
+			name:     "minecraft list short form",
+			probe:    "list",
+			response: "There are 0/20 players online:",
+			want:     Minecraft,
+		},
+		{
+			name:     "minecraft version",
+			probe:    "version",
+			response: "This server is running Paper version git-Paper-196 (MC: 1.20.1)",
+			want:     Minecraft,
+		},
+		{
+			name:     "source status",
+			probe:    "status",
+			response: "hostname: My CS Server\nversion : 1.38.7.9/13879 1575 secure\nmap     : de_dust2\n",
+			want:     Source,
+		},
+		{
+			name:     "source version",
+			probe:    "version",
+			response: "Protocol version 24\nExe version 1.38.7.9 (csgo)",
+			want:     Source,
+		},
+		{
+			name:     "ark unknown command",
+			probe:    "status",
+			response: "Server received, But no response!! \n ",
+			want:     ARK,
+		},
+		{
+			name:     "pattern for another probe",
+			probe:    "status",
+			response: "There are 2 of a max of 20 players online",
+			want:     Unknown,
+		},
+		{
+			name:     "unknown command",
+			probe:    "version",
+			response: "Unknown command",
+			want:     Unknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Match(tt.probe, tt.response); got != tt.want {
+				t.Errorf("Match(%q, %q) = %q, want %q", tt.probe, tt.response, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string
+		want      Type
+	}{
+		{
+			name:      "no responses",
+			responses: map[string]string{},
+			want:      Unknown,
+		},
+		{
+			name: "minecraft",
+			responses: map[string]string{
+				"/version": "Unknown or incomplete command",
+				"version":  "Unknown or incomplete command",
+				"status":   "Unknown or incomplete command",
+				"list":     "There are 0 of a max of 20 players online:",
+			},
+			want: Minecraft,
+		},
+		{
+			name: "first conclusive probe wins",
+			responses: map[string]string{
+				"version": "Protocol version 24",
+				"list":    "There are 0 of a max of 20 players online:",
+			},
+			want: Source,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.responses); got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
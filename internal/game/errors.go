@@ -0,0 +1,89 @@
+package game
+
+import (
+	"regexp"
+	"strings"
+)
+
+// rejections holds the patterns of responses in which a server refuses a
+// command, such as an unknown command or a syntax error. They are anchored
+// to the start of the output so that chat or log text quoting an error is
+// not mistaken for one.
+var rejections = map[Type][]*regexp.Regexp{
+	Minecraft: {
+		regexp.MustCompile(`^Unknown or incomplete command`),
+		regexp.MustCompile(`^Unknown command`),
+		regexp.MustCompile(`^Incorrect argument for command`),
+		regexp.MustCompile(`^Expected (?:whitespace|integer|boolean|float|double|long)`),
+	},
+	Source: {
+		regexp.MustCompile(`^Unknown command "`),
+	},
+	Factorio: {
+		regexp.MustCompile(`^Unknown command`),
+		regexp.MustCompile(`^Cannot execute command\.`),
+	},
+	Zomboid: {
+		regexp.MustCompile(`^Unknown command`),
+	},
+	Terraria: {
+		regexp.MustCompile(`^Invalid command entered`),
+	},
+	SevenDays: {
+		regexp.MustCompile(`^\*\*\* ERROR: unknown command`),
+	},
+	DayZ: {
+		regexp.MustCompile(`^Unknown command`),
+	},
+	Arma3: {
+		regexp.MustCompile(`^Unknown command`),
+	},
+	Satisfactory: {
+		regexp.MustCompile(`^Command not recognized`),
+	},
+}
+
+// Rejected reports whether output is a server's refusal to run a command.
+// For Unknown game types the patterns of every known game are tried.
+func Rejected(t Type, output string) bool {
+	if t != Unknown {
+		return matchAny(rejections[t], output)
+	}
+	for _, patterns := range rejections {
+		if matchAny(patterns, output) {
+			return true
+		}
+	}
+	return false
+}
+
+// emptyReplies holds, for each game whose servers answer commands that
+// succeeded with an empty body, the message of the Change standing in for
+// that body. An empty reply from other games is only an empty reply.
+var emptyReplies = map[Type]string{
+	Valheim: "the server sent an empty reply, which Valheim RCON mods send for commands that succeeded",
+	DayZ:    battleyeNoReply,
+	Arma3:   battleyeNoReply,
+}
+
+// EmptyReply returns the Change an empty response from a server of type t
+// stands for, and whether it stands for one: Valheim RCON mods and
+// BattlEye answer commands that succeeded with nothing, which would
+// otherwise look like the command failing.
+func EmptyReply(t Type, output string) (*Change, bool) {
+	message, ok := emptyReplies[t]
+	if !ok || strings.TrimSpace(output) != "" {
+		return nil, false
+	}
+	return &Change{Changed: true, Message: message}, true
+}
+
+// matchAny reports whether any of the patterns matches s.
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package game
+
+import "testing"
+
+func TestRejected(t *testing.T) {
+	tests := []struct {
+		name   string
+		game   Type
+		output string
+		want   bool
+	}{
+		{name: "minecraft unknown command", game: Minecraft, output: "Unknown or incomplete command, see below for error\nfoo<--[HERE]", want: true},
+		{name: "minecraft bad argument", game: Minecraft, output: "Incorrect argument for command\ngive Steve<--[HERE]", want: true},
+		{name: "minecraft success", game: Minecraft, output: "Saved the game", want: false},
+		{name: "source unknown command", game: Source, output: `Unknown command "foo"`, want: true},
+		{name: "factorio not allowed", game: Factorio, output: "Cannot execute command. Error: not admin", want: true},
+		{name: "7dtd unknown command", game: SevenDays, output: "*** ERROR: unknown command 'foo'", want: true},
+		{name: "satisfactory unknown command", game: Satisfactory, output: "Command not recognized: foo", want: true},
+		{name: "unknown game tries all", game: Unknown, output: `Unknown command "foo"`, want: true},
+		{name: "quoted error is not a rejection", game: Minecraft, output: "<Steve> Unknown command lol", want: false},
+		{name: "other game patterns not used", game: Source, output: "Incorrect argument for command", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Rejected(tt.game, tt.output); got != tt.want {
+				t.Errorf("Rejected(%s, %q) = %v, want %v", tt.game, tt.output, got, tt.want)
+			}
+		})
+	}
+}
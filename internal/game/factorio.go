@@ -0,0 +1,98 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// factorioPacketLimit is the largest response packet accepted from Factorio,
+// which sends each reply in a single packet however long it is, ignoring
+// the protocol's 4096-byte limit.
+const factorioPacketLimit = 1 << 20
+
+// PacketLimit returns the largest response packet a server of game t is
+// expected to send, or 0 for games that keep to the protocol's limit.
+func PacketLimit(t Type) int {
+	if t == Factorio {
+		return factorioPacketLimit
+	}
+	return 0
+}
+
+// factorioOnlinePattern matches the header of the "/players online" output.
+var factorioOnlinePattern = regexp.MustCompile(`Online players \((\d+)\):`)
+
+// parseFactorioPlayersOnline parses the output of the Factorio
+// "/players online" command, which lists one "  name (online)" per line.
+func parseFactorioPlayersOnline(output string) (any, error) {
+	m := factorioOnlinePattern.FindStringSubmatchIndex(output)
+	if m == nil {
+		return nil, fmt.Errorf("unrecognized factorio players output")
+	}
+
+	online, _ := strconv.Atoi(output[m[2]:m[3]])
+
+	players := []string{}
+	for _, line := range strings.Split(output[m[1]:], "\n") {
+		name := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "(online)"))
+		if name != "" {
+			players = append(players, name)
+		}
+	}
+
+	return &PlayerList{Online: online, Players: players}, nil
+}
+
+// FactorioTime is the structured form of the Factorio "/time" command,
+// the age of the map.
+type FactorioTime struct {
+	Seconds int    `json:"seconds"` // Age of the map in seconds of game time
+	Text    string `json:"text"`    // The reply, e.g. "1 hour, 12 minutes and 5 seconds"
+}
+
+// factorioTimePattern matches the parts of a "/time" reply.
+var factorioTimePattern = regexp.MustCompile(`(\d+) (day|hour|minute|second)s?\b`)
+
+// factorioTimeUnits holds the length in seconds of the units of a "/time"
+// reply.
+var factorioTimeUnits = map[string]int{"day": 86400, "hour": 3600, "minute": 60, "second": 1}
+
+// parseFactorioTime parses the output of the Factorio "/time" command.
+func parseFactorioTime(output string) (any, error) {
+	text := strings.TrimSpace(output)
+	matches := factorioTimePattern.FindAllStringSubmatch(text, -1)
+	if matches == nil {
+		return nil, fmt.Errorf("unrecognized factorio time output")
+	}
+	t := &FactorioTime{Text: text}
+	for _, m := range matches {
+		n, _ := strconv.Atoi(m[1])
+		t.Seconds += n * factorioTimeUnits[m[2]]
+	}
+	return t, nil
+}
+
+// FactorioLua is the output of Lua code run on a Factorio server, which is
+// whatever the code passed to rcon.print.
+type FactorioLua struct {
+	Output string `json:"output"`
+}
+
+// factorioAchievementsPattern matches the reply of Factorio to the first
+// Lua command on a map that still allows achievements, which it does not
+// run: "Using Lua console commands will disable achievements, please repeat
+// the command to proceed."
+var factorioAchievementsPattern = regexp.MustCompile(`(?i)^Using Lua console commands will disable achievements`)
+
+// ParseFactorioLua reads the reply of Factorio to a command running Lua
+// code. The warning that Lua commands disable achievements, sent instead
+// of running the first one, is returned as an error carrying the reply.
+func ParseFactorioLua(output string) (*FactorioLua, error) {
+	if factorioAchievementsPattern.MatchString(output) {
+		return nil, errors.New(strings.TrimSpace(output))
+	}
+	return &FactorioLua{Output: strings.TrimSuffix(output, "\n")}, nil
+}
@@ -0,0 +1,79 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFactorioPlayersOnline(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *PlayerList
+		wantErr bool
+	}{
+		{
+			name:   "two players",
+			output: "Online players (2):\n  Alice (online)\n  Bob (online)\n",
+			want:   &PlayerList{Online: 2, Players: []string{"Alice", "Bob"}},
+		},
+		{
+			name:   "no players",
+			output: "Online players (0):\n",
+			want:   &PlayerList{Online: 0, Players: []string{}},
+		},
+		{
+			name:    "unrecognized output",
+			output:  "Unknown command",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFactorioPlayersOnline(tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseFactorioTime(t *testing.T) {
+	for output, want := range map[string]int{
+		"1 hour, 12 minutes and 5 seconds\n": 4325,
+		"2 days, 1 hour and 1 second":        176401,
+		"45 seconds":                         45,
+	} {
+		got, err := Parse(Factorio, "/time", output)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", output, err)
+			continue
+		}
+		if got.(*FactorioTime).Seconds != want {
+			t.Errorf("Parse(%q) = %+v, want %d seconds", output, got, want)
+		}
+	}
+	if _, err := Parse(Factorio, "/time", "Unknown command \"time\"."); err == nil {
+		t.Error("Expected an unknown command reply to be refused")
+	}
+}
+
+func TestParseFactorioLua(t *testing.T) {
+	got, err := ParseFactorioLua("42\n")
+	if err != nil || got.Output != "42" {
+		t.Errorf("ParseFactorioLua() = %+v, %v", got, err)
+	}
+	if _, err := ParseFactorioLua("Using Lua console commands will disable achievements, please repeat the command to proceed."); err == nil {
+		t.Error("Expected the achievements warning to be returned as an error")
+	}
+}
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GModMaxCode is the longest Lua code lua_run and lua_run_cl accept:
+// Source consoles cut command lines at 512 bytes, name included.
+const GModMaxCode = 500
+
+// GModLua is the output of Lua code run on a Garry's Mod server, which is
+// whatever the code printed, cut to the size asked for.
+type GModLua struct {
+	Output    string `json:"output"`
+	Truncated bool   `json:"truncated,omitempty"` // Output was longer and has been cut
+}
+
+// CheckGModLua checks that code can be passed to lua_run or lua_run_cl
+// whole. Source consoles end a command at a line break or a semicolon,
+// even in quotes, and run what follows as another console command, so
+// code holding either is refused rather than split.
+func CheckGModLua(code string) error {
+	switch {
+	case code == "":
+		return errors.New("code is empty")
+	case len(code) > GModMaxCode:
+		return fmt.Errorf("code is %d bytes long; Garry's Mod runs at most %d", len(code), GModMaxCode)
+	case strings.ContainsAny(code, "\r\n"):
+		return errors.New("code must fit on one line")
+	case strings.Contains(code, ";"):
+		return errors.New("code must not contain semicolons, which end the console command; separate Lua statements with spaces")
+	}
+	return nil
+}
+
+// ParseGModLua reads the reply of Garry's Mod to lua_run or lua_run_cl,
+// keeping at most limit bytes of it, or all of it if limit is 0. The echo
+// of the code, "> code...", is dropped, and a Lua error, which the server
+// prints as "[ERROR] ..." followed by its stack, is returned as an error.
+func ParseGModLua(output string, limit int) (*GModLua, error) {
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "> ") && strings.HasSuffix(lines[0], "...") {
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if strings.HasPrefix(line, "[ERROR] ") {
+			return nil, errors.New(strings.TrimSpace(strings.Join(lines[i:], "\n")))
+		}
+	}
+
+	lua := &GModLua{Output: strings.Join(lines, "\n")}
+	if limit > 0 && len(lua.Output) > limit {
+		lua.Output = strings.ToValidUTF8(lua.Output[:limit], "")
+		lua.Truncated = true
+	}
+	return lua, nil
+}
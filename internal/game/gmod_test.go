@@ -0,0 +1,100 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckGModLua(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		wantErr bool
+	}{
+		{name: "one statement", code: "print(#player.GetAll())"},
+		{name: "statements separated by spaces", code: `local n = 0 for _, p in ipairs(player.GetAll()) do n = n + p:Frags() end print(n)`},
+		{name: "empty", code: "", wantErr: true},
+		{name: "line break", code: "print(1)\nprint(2)", wantErr: true},
+		{name: "semicolon", code: "print(1); quit", wantErr: true},
+		{name: "semicolon in a string", code: `print("a;b")`, wantErr: true},
+		{name: "longest", code: strings.Repeat("x", GModMaxCode)},
+		{name: "too long", code: strings.Repeat("x", GModMaxCode+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckGModLua(tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckGModLua() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseGModLua(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		limit   int
+		want    *GModLua
+		wantErr bool
+	}{
+		{
+			name:   "echo dropped",
+			output: "> print(#player.GetAll())...\n3\n",
+			want:   &GModLua{Output: "3"},
+		},
+		{
+			name:   "nothing printed",
+			output: "> game.CleanUpMap()...\n",
+			want:   &GModLua{Output: ""},
+		},
+		{
+			name:   "no echo",
+			output: "a\nb\n",
+			want:   &GModLua{Output: "a\nb"},
+		},
+		{
+			name:   "cut to the limit",
+			output: "> print(string.rep(\"a\", 10))...\naaaaaaaaaa\n",
+			limit:  4,
+			want:   &GModLua{Output: "aaaa", Truncated: true},
+		},
+		{
+			name:   "within the limit",
+			output: "ok\n",
+			limit:  4,
+			want:   &GModLua{Output: "ok"},
+		},
+		{
+			name:   "cut inside a character",
+			output: "aé",
+			limit:  2,
+			want:   &GModLua{Output: "a", Truncated: true},
+		},
+		{
+			name:    "lua error",
+			output:  "> print(nil + 1)...\n[ERROR] lua_run:1: attempt to perform arithmetic on a nil value\n  1. unknown - lua_run:1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGModLua(tt.output, tt.limit)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseGModLua() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
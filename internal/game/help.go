@@ -0,0 +1,141 @@
+package game
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// HelpEntry is one command listed by a server's native help output.
+type HelpEntry struct {
+	Command string `json:"command"` // Command name without any leading slash
+	Usage   string `json:"usage"`   // Full help line, including arguments and description
+}
+
+// HelpIndex is the parsed help output of a server.
+type HelpIndex struct {
+	Command string      `json:"command"` // Help command that produced the index
+	Entries []HelpEntry `json:"entries"` // Commands sorted by name
+}
+
+// helpCommands lists the native help commands to try for each game, in
+// order. Every game falls back to the generic commands afterwards.
+var helpCommands = map[Type][]string{
+	Source:   {"cmdlist"},
+	Factorio: {"/help"},
+}
+
+// genericHelpCommands are tried on every server after its game-specific ones.
+var genericHelpCommands = []string{"help", "?"}
+
+// HelpCommands returns the commands that may list a server's available
+// commands, most specific first.
+func HelpCommands(t Type) []string {
+	return append(append([]string(nil), helpCommands[t]...), genericHelpCommands...)
+}
+
+// minecraftHelpSplit finds the start of each command in Minecraft help
+// output, which arrives over RCON as one line with the commands run together.
+var minecraftHelpSplit = regexp.MustCompile(`/[a-z][a-z0-9_:-]*`)
+
+// ParseHelp splits help output into one entry per command, sorted by
+// command name. Lines that do not start with a command name are skipped, and
+// only the first line for each command is kept.
+func ParseHelp(t Type, output string) []HelpEntry {
+	lines := strings.Split(output, "\n")
+	if t == Minecraft || (t == Unknown && len(lines) == 1) {
+		lines = splitRunTogetherHelp(output)
+	}
+
+	// When commands are written with a leading slash, other lines are
+	// headers or prose rather than commands
+	slashed := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "/") {
+			slashed = true
+			break
+		}
+	}
+
+	seen := make(map[string]bool)
+	var entries []HelpEntry
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		fields := strings.Fields(line)
+		if len(fields) == 0 || (slashed && !strings.HasPrefix(line, "/")) {
+			continue
+		}
+		name := strings.TrimRight(strings.TrimPrefix(fields[0], "/"), ":")
+		if name == "" || seen[name] || !isCommandName(name) {
+			continue
+		}
+		seen[name] = true
+		entries = append(entries, HelpEntry{Command: name, Usage: line})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Command < entries[j].Command })
+	return entries
+}
+
+// splitRunTogetherHelp splits output in which commands follow each other
+// without separators, such as "/ban <targets>/ban-ip <target>". A slash only
+// starts a new command at the beginning of the output or of a line, or
+// right after a non-space character closing the previous entry.
+func splitRunTogetherHelp(output string) []string {
+	var lines []string
+	start := 0
+	for _, loc := range minecraftHelpSplit.FindAllStringIndex(output, -1) {
+		if loc[0] == 0 {
+			continue
+		}
+		prev := output[loc[0]-1]
+		if prev == ' ' || prev == '|' || prev == '<' || prev == '[' || prev == '(' {
+			continue
+		}
+		lines = append(lines, output[start:loc[0]])
+		start = loc[0]
+	}
+	return append(lines, output[start:])
+}
+
+// isCommandName reports whether s looks like a console command name: a
+// letter followed by letters, digits and a few separators.
+func isCommandName(s string) bool {
+	for i, r := range s {
+		isLetter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		switch {
+		case i == 0 && !isLetter:
+			return false
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_' || r == '-' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// SearchHelp returns the entries matching query, best matches first: an
+// exact command name, then command names starting with the query, then
+// entries whose usage mentions it. Matching is case-insensitive. An empty
+// query returns every entry.
+func SearchHelp(entries []HelpEntry, query string) []HelpEntry {
+	query = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(query), "/"))
+	if query == "" {
+		return entries
+	}
+
+	var exact, prefix, mention []HelpEntry
+	for _, e := range entries {
+		name := strings.ToLower(e.Command)
+		switch {
+		case name == query:
+			exact = append(exact, e)
+		case strings.HasPrefix(name, query):
+			prefix = append(prefix, e)
+		case strings.Contains(strings.ToLower(e.Usage), query):
+			mention = append(mention, e)
+		}
+	}
+	return append(append(exact, prefix...), mention...)
+}
@@ -0,0 +1,94 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHelp(t *testing.T) {
+	tests := []struct {
+		name      string
+		game      Type
+		output    string
+		wantNames []string
+	}{
+		{
+			name:      "minecraft run-together output",
+			game:      Minecraft,
+			output:    "/advancement (grant|revoke) <targets>/ban <targets> [<reason>]/ban-ip <target>/list/tp <destination>",
+			wantNames: []string{"advancement", "ban", "ban-ip", "list", "tp"},
+		},
+		{
+			name:      "source cmdlist",
+			game:      Source,
+			output:    "changelevel : cheat : Change server to the specified map\nkick : : Kick a player by name.\n--------------\n 2 total convars/concommands",
+			wantNames: []string{"changelevel", "kick"},
+		},
+		{
+			name:      "factorio help lines",
+			game:      Factorio,
+			output:    "Available commands:\n/admins - Prints a list of game admins.\n/ban <player> <reason> - Bans the specified player.\n/ban <player> duplicate",
+			wantNames: []string{"admins", "ban"},
+		},
+		{
+			name:      "unknown game with one line is split like minecraft",
+			game:      Unknown,
+			output:    "/kick <player>/list",
+			wantNames: []string{"kick", "list"},
+		},
+		{
+			name:      "empty output",
+			game:      Minecraft,
+			output:    "",
+			wantNames: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, e := range ParseHelp(tt.game, tt.output) {
+				names = append(names, e.Command)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("Expected %v, got %v", tt.wantNames, names)
+			}
+		})
+	}
+}
+
+func TestSearchHelp(t *testing.T) {
+	entries := ParseHelp(Minecraft, "/ban <targets> [<reason>]/ban-ip <target>/banlist/pardon <targets>/whitelist (add|remove) <targets>")
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "ban", want: []string{"ban", "ban-ip", "banlist"}},
+		{query: "/BANLIST", want: []string{"banlist"}},
+		{query: "targets", want: []string{"ban", "pardon", "whitelist"}},
+		{query: "", want: []string{"ban", "ban-ip", "banlist", "pardon", "whitelist"}},
+		{query: "teleport", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var names []string
+			for _, e := range SearchHelp(entries, tt.query) {
+				names = append(names, e.Command)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("SearchHelp(%q) = %v, want %v", tt.query, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelpCommands(t *testing.T) {
+	if got, want := HelpCommands(Factorio), []string{"/help", "help", "?"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HelpCommands(Factorio) = %v, want %v", got, want)
+	}
+	if got, want := HelpCommands(Minecraft), []string{"help", "?"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HelpCommands(Minecraft) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package game
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ParseConsolePlayers reads the player list of a line-console game, such
+// as Unturned or Eco, from the reply to its players command: every line
+// pattern matches names a player, by its "name" group or else the whole
+// match. Lines it does not match, such as headers and counts, are skipped.
+func ParseConsolePlayers(output string, pattern *regexp.Regexp) *PlayerList {
+	name := pattern.SubexpIndex("name")
+	list := &PlayerList{Players: []string{}}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		m := pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		player := m[0]
+		if name >= 0 {
+			player = m[name]
+		}
+		if player = strings.TrimSpace(player); player != "" {
+			list.Players = append(list.Players, player)
+		}
+	}
+	list.Online = len(list.Players)
+	return list
+}
+
+// ParseConsoleBroadcast reads the reply of a line-console game to its
+// broadcast command. Such consoles echo a broadcast, if they answer at all,
+// so any reply the server did not reject counts as the message shown.
+func ParseConsoleBroadcast(output string) *Change {
+	message := strings.TrimSpace(output)
+	if message == "" {
+		message = "the server sent an empty reply"
+	}
+	return &Change{Changed: true, Message: message}
+}
+
+// ValidConsoleMessage reports whether message can be put in a broadcast
+// template whole: it is not empty and has no line breaks or other control
+// characters, with which a line console would end the command.
+func ValidConsoleMessage(message string) bool {
+	return message != "" && !strings.ContainsFunc(message, unicode.IsControl)
+}
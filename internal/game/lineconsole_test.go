@@ -0,0 +1,66 @@
+package game
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestParseConsolePlayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		output  string
+		want    *PlayerList
+	}{
+		{
+			name:    "name group",
+			pattern: `^\s*7656\d{13}\s*[-:]?\s*(?P<name>\S.*?)\s*$`,
+			output:  "Players: 2/24\r\n76561198000000001 Alice\r\n76561198000000002 - Bob the Builder\r\n",
+			want:    &PlayerList{Online: 2, Players: []string{"Alice", "Bob the Builder"}},
+		},
+		{
+			name:    "whole match",
+			pattern: `[A-Za-z]+$`,
+			output:  "1. alice\n2. bob\n",
+			want:    &PlayerList{Online: 2, Players: []string{"alice", "bob"}},
+		},
+		{
+			name:    "header only",
+			pattern: `^\s*(?P<name>[^\s:,][^:,]*?)\s*$`,
+			output:  "Online players: 0\n",
+			want:    &PlayerList{Online: 0, Players: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseConsolePlayers(tt.output, regexp.MustCompile(tt.pattern))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseConsolePlayers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConsoleBroadcast(t *testing.T) {
+	if got := ParseConsoleBroadcast("Broadcast: hello\n"); !got.Changed || got.Message != "Broadcast: hello" {
+		t.Errorf("Unexpected change for an echo: %+v", got)
+	}
+	if got := ParseConsoleBroadcast(""); !got.Changed || got.Message == "" {
+		t.Errorf("Expected an empty reply to count as shown with a message, got %+v", got)
+	}
+}
+
+func TestValidConsoleMessage(t *testing.T) {
+	for message, want := range map[string]bool{
+		"Restart in 5 minutes": true,
+		"":                     false,
+		"hi\nshutdown":         false,
+		"hi\tthere":            false,
+	} {
+		if got := ValidConsoleMessage(message); got != want {
+			t.Errorf("ValidConsoleMessage(%q) = %v, want %v", message, got, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// minecraftListPattern matches both the modern ("There are 1 of a max of 20
+// players online: Steve") and legacy ("There are 1/20 players online:") forms
+// of the list command output.
+var minecraftListPattern = regexp.MustCompile(`(?s)There are (\d+)\s*(?:of a max of|/)\s*(\d+) players online:?(.*)`)
+
+// parseMinecraftList parses the output of the Minecraft "list" command.
+func parseMinecraftList(output string) (any, error) {
+	m := minecraftListPattern.FindStringSubmatch(output)
+	if m == nil {
+		return nil, fmt.Errorf("unrecognized minecraft list output")
+	}
+
+	online, _ := strconv.Atoi(m[1])
+	maxPlayers, _ := strconv.Atoi(m[2])
+
+	players := []string{}
+	for _, name := range strings.Split(m[3], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			players = append(players, name)
+		}
+	}
+
+	return &PlayerList{Online: online, Max: maxPlayers, Players: players}, nil
+}
+
+// minecraftPlayerPattern matches the names Minecraft Java Edition accounts
+// may have.
+var minecraftPlayerPattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,16}$`)
+
+// ValidMinecraftPlayer reports whether name is a valid Minecraft player
+// name, so that it can be put in a command without changing its meaning.
+func ValidMinecraftPlayer(name string) bool {
+	return minecraftPlayerPattern.MatchString(name)
+}
+
+// Change is the outcome of a command that changes a server's state, read
+// from the server's reply.
+type Change struct {
+	Changed bool   `json:"changed"` // The command took effect; false when there was nothing to change
+	Message string `json:"message"` // The server's reply
+}
+
+// minecraftChanges holds, for each Minecraft command that changes the
+// server's state, the pattern of replies in which it took effect and, if
+// the command can find nothing to change, the pattern of those replies.
+var minecraftChanges = map[string]struct{ done, unchanged *regexp.Regexp }{
+	"whitelist add": {
+		done:      regexp.MustCompile(`^Added \S+ to the whitelist`),
+		unchanged: regexp.MustCompile(`(?i)already whitelisted`),
+	},
+	"whitelist remove": {
+		done:      regexp.MustCompile(`^Removed \S+ from the whitelist`),
+		unchanged: regexp.MustCompile(`(?i)not whitelisted`),
+	},
+	"ban": {
+		done:      regexp.MustCompile(`^Banned \S+`),
+		unchanged: regexp.MustCompile(`(?i)already banned`),
+	},
+	"save-all": {
+		done: regexp.MustCompile(`(?m)^Sav(?:ed|ing) the game`),
+	},
+}
+
+// ParseMinecraftChange reads the reply of Minecraft to command, one of
+// "whitelist add", "whitelist remove", "ban" and "save-all" with its
+// arguments. Replies in which the server did neither what was asked nor
+// find it already done, such as an unknown player, are returned as errors
+// carrying the reply.
+func ParseMinecraftChange(command, output string) (*Change, error) {
+	fields := strings.Fields(strings.ToLower(command))
+	var patterns struct{ done, unchanged *regexp.Regexp }
+	var ok bool
+	for n := min(len(fields), 2); n > 0 && !ok; n-- {
+		patterns, ok = minecraftChanges[strings.Join(fields[:n], " ")]
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoParser, command)
+	}
+
+	message := strings.TrimSpace(output)
+	switch {
+	case patterns.done.MatchString(message):
+		return &Change{Changed: true, Message: message}, nil
+	case patterns.unchanged != nil && patterns.unchanged.MatchString(message):
+		return &Change{Message: message}, nil
+	case message == "":
+		return nil, errors.New("the server sent an empty reply")
+	default:
+		return nil, errors.New(message)
+	}
+}
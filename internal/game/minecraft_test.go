@@ -0,0 +1,152 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMinecraftList(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *PlayerList
+		wantErr bool
+	}{
+		{
+			name:   "modern format",
+			output: "There are 2 of a max of 20 players online: Alice, Bob",
+			want:   &PlayerList{Online: 2, Max: 20, Players: []string{"Alice", "Bob"}},
+		},
+		{
+			name:   "legacy format",
+			output: "There are 1/10 players online:\nSteve",
+			want:   &PlayerList{Online: 1, Max: 10, Players: []string{"Steve"}},
+		},
+		{
+			name:   "no players",
+			output: "There are 0 of a max of 20 players online: ",
+			want:   &PlayerList{Online: 0, Max: 20, Players: []string{}},
+		},
+		{
+			name:    "unrecognized output",
+			output:  "Unknown or incomplete command",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMinecraftList(tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseMinecraftChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		output  string
+		want    *Change
+		wantErr string
+	}{
+		{
+			name:    "whitelisted",
+			command: "whitelist add Steve",
+			output:  "Added Steve to the whitelist",
+			want:    &Change{Changed: true, Message: "Added Steve to the whitelist"},
+		},
+		{
+			name:    "already whitelisted",
+			command: "whitelist add Steve",
+			output:  "Player is already whitelisted",
+			want:    &Change{Message: "Player is already whitelisted"},
+		},
+		{
+			name:    "removed from the whitelist",
+			command: "Whitelist  remove Steve",
+			output:  "Removed Steve from the whitelist\n",
+			want:    &Change{Changed: true, Message: "Removed Steve from the whitelist"},
+		},
+		{
+			name:    "banned with a reason",
+			command: "ban Steve griefing",
+			output:  "Banned Steve: griefing",
+			want:    &Change{Changed: true, Message: "Banned Steve: griefing"},
+		},
+		{
+			name:    "already banned",
+			command: "ban Steve",
+			output:  "Nothing changed. The player is already banned",
+			want:    &Change{Message: "Nothing changed. The player is already banned"},
+		},
+		{
+			name:    "saved",
+			command: "save-all flush",
+			output:  "Saving the game (this may take a moment!)\nSaved the game",
+			want:    &Change{Changed: true, Message: "Saving the game (this may take a moment!)\nSaved the game"},
+		},
+		{
+			name:    "unknown player",
+			command: "ban Nobody",
+			output:  "That player does not exist",
+			wantErr: "That player does not exist",
+		},
+		{
+			name:    "empty reply",
+			command: "whitelist add Steve",
+			wantErr: "empty reply",
+		},
+		{
+			name:    "unsupported command",
+			command: "kick Steve",
+			output:  "Kicked Steve",
+			wantErr: "no parser",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMinecraftChange(tt.command, tt.output)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidMinecraftPlayer(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Steve":             true,
+		"x_Notch_x":         true,
+		"":                  false,
+		"Steve Jobs":        false,
+		"Steve;op Steve":    false,
+		"ThisNameIsTooLong": false,
+	} {
+		if got := ValidMinecraftPlayer(name); got != want {
+			t.Errorf("ValidMinecraftPlayer(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
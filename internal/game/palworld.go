@@ -0,0 +1,106 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PalworldSpace replaces the spaces of the text passed to Palworld's
+// Broadcast and Shutdown commands. Palworld splits a command at spaces and
+// only shows the first word of a message; a no-break space renders as a
+// space without being split on.
+const PalworldSpace = "\u00a0"
+
+// PalworldPlayer is a player listed by the Palworld "ShowPlayers" command.
+type PalworldPlayer struct {
+	Name      string `json:"name"`
+	PlayerUID string `json:"player_uid"` // Palworld's own ID of the player's character
+	SteamID   string `json:"steam_id"`   // SteamID64 of the player's account
+}
+
+// PalworldPlayerList is the structured form of the Palworld "ShowPlayers"
+// command.
+type PalworldPlayerList struct {
+	Online    int              `json:"online"`
+	Players   []PalworldPlayer `json:"players"`
+	Truncated bool             `json:"truncated,omitempty"` // The reply was cut short, so players may be missing
+}
+
+// parsePalworldShowPlayers parses the CSV output of the Palworld
+// "ShowPlayers" command, a "name,playeruid,steamid" header followed by a
+// line per player. Palworld mangles names that are not ASCII and cuts long
+// replies short, sometimes mid-line, and may pad them with NUL bytes, so
+// invalid text is replaced and a partial last line dropped rather than
+// failing the whole listing. Names may contain commas, so the IDs are taken
+// from the end of each line.
+func parsePalworldShowPlayers(output string) (any, error) {
+	text := strings.ToValidUTF8(strings.TrimRight(output, "\x00"), "\ufffd")
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if !strings.EqualFold(strings.TrimSpace(lines[0]), "name,playeruid,steamid") {
+		return nil, errors.New("unrecognized palworld ShowPlayers output")
+	}
+	list := &PalworldPlayerList{Players: []PalworldPlayer{}}
+	for i, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) < 3 || fields[len(fields)-1] == "" {
+			if i == len(lines)-2 {
+				list.Truncated = true
+				break
+			}
+			return nil, fmt.Errorf("unrecognized palworld ShowPlayers line %q", line)
+		}
+		n := len(fields)
+		list.Players = append(list.Players, PalworldPlayer{
+			Name:      strings.Join(fields[:n-2], ","),
+			PlayerUID: fields[n-2],
+			SteamID:   fields[n-1],
+		})
+	}
+	list.Online = len(list.Players)
+	return list, nil
+}
+
+// PalworldText returns text with its spaces encoded as PalworldSpace, for
+// the message argument of Broadcast and Shutdown, or an error if it cannot
+// be sent as one: Palworld cuts a command at a line break.
+func PalworldText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if strings.ContainsAny(text, "\r\n") {
+		return "", errors.New("palworld messages may not contain line breaks")
+	}
+	return strings.Join(strings.Fields(text), PalworldSpace), nil
+}
+
+// palworldChanges holds, for each Palworld command that changes the
+// server's state, the pattern of replies in which it took effect.
+var palworldChanges = map[string]*regexp.Regexp{
+	"save":      regexp.MustCompile(`(?i)^Complete Save`),
+	"broadcast": regexp.MustCompile(`(?i)^Broadcasted:`),
+	"shutdown":  regexp.MustCompile(`(?i)^The server will shut ?down`),
+}
+
+// ParsePalworldChange reads the reply of Palworld to command, one of
+// "Save", "Broadcast" and "Shutdown" with its arguments. Other replies are
+// returned as errors carrying the reply.
+func ParsePalworldChange(command, output string) (*Change, error) {
+	verb, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(command)), " ")
+	done, ok := palworldChanges[verb]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoParser, command)
+	}
+	message := strings.TrimSpace(strings.TrimRight(output, "\x00"))
+	switch {
+	case done.MatchString(message):
+		return &Change{Changed: true, Message: strings.ReplaceAll(message, PalworldSpace, " ")}, nil
+	case message == "":
+		return nil, errors.New("the server sent an empty reply")
+	default:
+		return nil, errors.New(message)
+	}
+}
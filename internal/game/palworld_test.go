@@ -0,0 +1,87 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePalworldShowPlayers(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        string
+		want          []PalworldPlayer
+		wantTruncated bool
+		wantErr       bool
+	}{
+		{
+			name:   "players",
+			output: "name,playeruid,steamid\nBob,1234567890,76561197960290418\nSmith, John,987654321,76561198000000001\n",
+			want: []PalworldPlayer{
+				{Name: "Bob", PlayerUID: "1234567890", SteamID: "76561197960290418"},
+				{Name: "Smith, John", PlayerUID: "987654321", SteamID: "76561198000000001"},
+			},
+		},
+		{name: "empty", output: "name,playeruid,steamid\n", want: []PalworldPlayer{}},
+		{
+			name:          "cut short with padding",
+			output:        "name,playeruid,steamid\nBob,1234567890,76561197960290418\nAli\x00\x00",
+			want:          []PalworldPlayer{{Name: "Bob", PlayerUID: "1234567890", SteamID: "76561197960290418"}},
+			wantTruncated: true,
+		},
+		{
+			name:   "mangled name",
+			output: "name,playeruid,steamid\n\xe3\x81,1234567890,76561197960290418\n",
+			want:   []PalworldPlayer{{Name: "\ufffd", PlayerUID: "1234567890", SteamID: "76561197960290418"}},
+		},
+		{name: "not a listing", output: "Unknown command", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(Palworld, "ShowPlayers", tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			list := result.(*PalworldPlayerList)
+			if list.Online != len(tt.want) || list.Truncated != tt.wantTruncated || !reflect.DeepEqual(list.Players, tt.want) {
+				t.Errorf("Expected %+v (truncated %v), got %+v", tt.want, tt.wantTruncated, list)
+			}
+		})
+	}
+}
+
+func TestPalworldText(t *testing.T) {
+	got, err := PalworldText("  Restart in  5 minutes ")
+	if err != nil || got != "Restart\u00a0in\u00a05\u00a0minutes" {
+		t.Errorf("PalworldText() = %q, %v", got, err)
+	}
+	if _, err := PalworldText("two\nlines"); err == nil {
+		t.Error("Expected a message with a line break to be refused")
+	}
+}
+
+func TestParsePalworldChange(t *testing.T) {
+	tests := []struct {
+		command, output string
+		wantMessage     string
+		wantErr         bool
+	}{
+		{"Save", "Complete Save\x00", "Complete Save", false},
+		{"Broadcast Hello\u00a0there", "Broadcasted: Hello\u00a0there", "Broadcasted: Hello there", false},
+		{"Shutdown 60 Bye", "The server will shut down in 60 seconds. Please prepare to exit the game.", "The server will shut down in 60 seconds. Please prepare to exit the game.", false},
+		{"Save", "", "", true},
+		{"Save", "Failed to save", "", true},
+	}
+	for _, tt := range tests {
+		change, err := ParsePalworldChange(tt.command, tt.output)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePalworldChange(%q, %q) error = %v, wantErr %v", tt.command, tt.output, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (!change.Changed || change.Message != tt.wantMessage) {
+			t.Errorf("ParsePalworldChange(%q, %q) = %+v, want message %q", tt.command, tt.output, change, tt.wantMessage)
+		}
+	}
+}
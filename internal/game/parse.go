@@ -0,0 +1,105 @@
+package game
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNoParser is returned by Parse when no parser is registered for a command.
+var ErrNoParser = errors.New("no parser available for command")
+
+// ParseFunc converts the raw console output of a command into structured data.
+// It returns an error if the output is not in the expected format.
+type ParseFunc func(output string) (any, error)
+
+// commandParser associates a parser with the commands it understands.
+type commandParser struct {
+	commands []string // Normalized command lines handled by the parser
+	parse    ParseFunc
+}
+
+// parsers holds the registered output parsers for each game.
+var parsers = map[Type][]commandParser{
+	Minecraft: {
+		{commands: []string{"list"}, parse: parseMinecraftList},
+	},
+	Source: {
+		{commands: []string{"status"}, parse: parseSourceStatus},
+	},
+	Factorio: {
+		{commands: []string{"/players online", "/p o", "/players o", "/p online"}, parse: parseFactorioPlayersOnline},
+		{commands: []string{"/time"}, parse: parseFactorioTime},
+	},
+	ARK: {
+		{commands: []string{"listplayers"}, parse: parseArkListPlayers},
+	},
+	Rust: {
+		{commands: []string{"serverinfo", "global.serverinfo"}, parse: parseRustServerInfo},
+	},
+	Palworld: {
+		{commands: []string{"showplayers"}, parse: parsePalworldShowPlayers},
+	},
+	Zomboid: {
+		{commands: []string{"players"}, parse: parseZomboidPlayers},
+	},
+	DayZ: {
+		{commands: []string{"players"}, parse: parseBattlEyePlayers},
+	},
+	Arma3: {
+		{commands: []string{"players"}, parse: parseBattlEyePlayers},
+	},
+}
+
+// parserOrder fixes the order in which games are tried when the game type of
+// a server is unknown, keeping Parse deterministic.
+var parserOrder = []Type{Minecraft, Source, Factorio, ARK, Rust, Palworld, Zomboid, DayZ}
+
+// Parse converts the output of command into structured data using the parser
+// registered for the given game. When the game is Unknown, parsers of every
+// game that handle the command are tried in turn and the first success wins.
+// Returns ErrNoParser if no parser handles the command.
+func Parse(t Type, command, output string) (any, error) {
+	games := []Type{t}
+	if t == Unknown || t == "" {
+		games = parserOrder
+	}
+
+	normalized := normalizeCommand(command)
+	var lastErr error = ErrNoParser
+	for _, g := range games {
+		for _, p := range parsers[g] {
+			if !matchesCommand(p.commands, normalized) {
+				continue
+			}
+			result, err := p.parse(output)
+			if err == nil {
+				return result, nil
+			}
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+// normalizeCommand lowercases a command and collapses its whitespace so that
+// trivially different spellings select the same parser.
+func normalizeCommand(command string) string {
+	return strings.ToLower(strings.Join(strings.Fields(command), " "))
+}
+
+// matchesCommand reports whether command is one of the given commands.
+func matchesCommand(commands []string, command string) bool {
+	for _, c := range commands {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
+
+// PlayerList is the structured form of a game's online player listing.
+type PlayerList struct {
+	Online  int      `json:"online"`        // Number of players online
+	Max     int      `json:"max,omitempty"` // Player limit, if reported
+	Players []string `json:"players"`       // Names of the online players
+}
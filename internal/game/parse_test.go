@@ -0,0 +1,89 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		game    Type
+		command string
+		output  string
+		wantErr error
+		check   func(t *testing.T, result any)
+	}{
+		{
+			name:    "known game and command",
+			game:    Minecraft,
+			command: "list",
+			output:  "There are 1 of a max of 20 players online: Steve",
+			check: func(t *testing.T, result any) {
+				if list, ok := result.(*PlayerList); !ok || list.Online != 1 {
+					t.Errorf("Expected player list with 1 player, got %#v", result)
+				}
+			},
+		},
+		{
+			name:    "command normalization",
+			game:    Factorio,
+			command: "  /Players   Online ",
+			output:  "Online players (0):",
+			check: func(t *testing.T, result any) {
+				if _, ok := result.(*PlayerList); !ok {
+					t.Errorf("Expected player list, got %#v", result)
+				}
+			},
+		},
+		{
+			name:    "unknown game tries every parser",
+			game:    Unknown,
+			command: "status",
+			output:  "hostname: Test\nmap     : de_dust2\n",
+			check: func(t *testing.T, result any) {
+				if status, ok := result.(*SourceStatus); !ok || status.Map != "de_dust2" {
+					t.Errorf("Expected source status, got %#v", result)
+				}
+			},
+		},
+		{
+			name:    "no parser for command",
+			game:    Minecraft,
+			command: "say hello",
+			output:  "",
+			wantErr: ErrNoParser,
+		},
+		{
+			name:    "parser for another game",
+			game:    Minecraft,
+			command: "status",
+			output:  "hostname: Test",
+			wantErr: ErrNoParser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.game, tt.command, tt.output)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			tt.check(t, result)
+		})
+	}
+}
+
+func TestParse_MalformedOutput(t *testing.T) {
+	if _, err := Parse(Minecraft, "list", "Unknown or incomplete command"); err == nil {
+		t.Error("Expected error for malformed output")
+	} else if errors.Is(err, ErrNoParser) {
+		t.Error("Expected parse error, got ErrNoParser")
+	}
+}
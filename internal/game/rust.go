@@ -0,0 +1,142 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RustServerInfo is the structured form of the Rust "serverinfo" command,
+// which replies with a JSON object.
+type RustServerInfo struct {
+	Hostname        string  `json:"hostname"`
+	Map             string  `json:"map"`
+	Players         int     `json:"players"`
+	MaxPlayers      int     `json:"max_players"`
+	Queued          int     `json:"queued"`
+	Joining         int     `json:"joining"`
+	EntityCount     int     `json:"entity_count"`
+	GameTime        string  `json:"game_time,omitempty"`    // In-game date and time
+	Uptime          int     `json:"uptime"`                 // Seconds since the server started
+	Framerate       float64 `json:"framerate"`              // Server frames per second
+	Memory          int     `json:"memory"`                 // Megabytes in use
+	NetworkIn       int     `json:"network_in"`             // Bytes per second received
+	NetworkOut      int     `json:"network_out"`            // Bytes per second sent
+	Restarting      bool    `json:"restarting"`             // A restart is counting down
+	SaveCreatedTime string  `json:"save_created,omitempty"` // When the save the map runs on was created
+	Version         int     `json:"version,omitempty"`      // Network protocol version
+}
+
+// rustServerInfoReply is RustServerInfo with the field names of the reply,
+// which untagged fields match.
+type rustServerInfoReply struct {
+	Hostname        string
+	Map             string
+	Players         int
+	MaxPlayers      int
+	Queued          int
+	Joining         int
+	EntityCount     int
+	GameTime        string
+	Uptime          int
+	Framerate       float64
+	Memory          int
+	NetworkIn       int
+	NetworkOut      int
+	Restarting      bool
+	SaveCreatedTime string
+	Version         int
+}
+
+// parseRustServerInfo parses the JSON reply of the Rust "serverinfo"
+// command.
+func parseRustServerInfo(output string) (any, error) {
+	var reply rustServerInfoReply
+	if err := json.Unmarshal([]byte(output), &reply); err != nil {
+		return nil, fmt.Errorf("unrecognized rust serverinfo output: %w", err)
+	}
+	if reply.Hostname == "" && reply.MaxPlayers == 0 {
+		return nil, errors.New("unrecognized rust serverinfo output")
+	}
+	info := RustServerInfo(reply)
+	return &info, nil
+}
+
+// RustChat is a chat message a Rust server sends over WebRCON, as the JSON
+// Message of a console message of type Chat.
+type RustChat struct {
+	Channel  int    `json:"channel"`  // 0 for global chat, 1 for team chat
+	Message  string `json:"message"`  // What was said
+	UserID   string `json:"user_id"`  // SteamID64 of the sender, "0" for the server
+	Username string `json:"username"` // Name of the sender
+	Time     int64  `json:"time"`     // When it was said, in Unix seconds
+}
+
+// rustChatReply is RustChat with the field names of the message.
+type rustChatReply struct {
+	Channel  int
+	Message  string
+	UserID   string `json:"UserId"`
+	Username string
+	Time     int64
+}
+
+// ParseRustChat parses the Message of a Rust chat console message.
+func ParseRustChat(message string) (*RustChat, error) {
+	var reply rustChatReply
+	if err := json.Unmarshal([]byte(message), &reply); err != nil {
+		return nil, fmt.Errorf("unrecognized rust chat message: %w", err)
+	}
+	chat := RustChat(reply)
+	return &chat, nil
+}
+
+// ValidRustPlayer reports whether player, a SteamID64 or a name, can be
+// quoted in a Rust command without changing its meaning.
+func ValidRustPlayer(player string) bool {
+	return player != "" && len(player) <= 64 && !strings.ContainsAny(player, "\";\r\n")
+}
+
+// rustSteamIDPattern matches the 64-bit SteamIDs Rust identifies players by.
+var rustSteamIDPattern = regexp.MustCompile(`^7656119\d{10}$`)
+
+// ValidRustSteamID reports whether id is a 64-bit SteamID, such as
+// 76561197960290418.
+func ValidRustSteamID(id string) bool {
+	return rustSteamIDPattern.MatchString(id)
+}
+
+// rustChanges holds, for each Rust command that changes the server's state,
+// the pattern of replies in which it failed and, if the command can find
+// nothing to change, the pattern of those replies. Rust mostly replies with
+// nothing, or a confirmation, when such a command succeeds.
+var rustChanges = map[string]struct{ failed, unchanged *regexp.Regexp }{
+	"kick": {
+		failed: regexp.MustCompile(`(?i)player not found|couldn't find|no player`),
+	},
+	"banid": {
+		failed:    regexp.MustCompile(`(?i)usage|invalid|not found`),
+		unchanged: regexp.MustCompile(`(?i)already banned`),
+	},
+}
+
+// ParseRustChange reads the reply of a Rust server to command, one of
+// "kick" and "banid" with its arguments. Replies reporting a failure, such
+// as an unknown player, are returned as errors carrying the reply.
+func ParseRustChange(command, output string) (*Change, error) {
+	verb, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(command)), " ")
+	patterns, ok := rustChanges[verb]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoParser, command)
+	}
+	message := strings.TrimSpace(output)
+	switch {
+	case patterns.failed.MatchString(message):
+		return nil, errors.New(message)
+	case patterns.unchanged != nil && patterns.unchanged.MatchString(message):
+		return &Change{Message: message}, nil
+	}
+	return &Change{Changed: true, Message: message}, nil
+}
@@ -0,0 +1,88 @@
+package game
+
+import "testing"
+
+const rustServerInfo = `{
+  "Hostname": "Rust Arena",
+  "MaxPlayers": 100,
+  "Players": 3,
+  "Queued": 1,
+  "Joining": 0,
+  "EntityCount": 154321,
+  "GameTime": "10/16/2026 12:00:00",
+  "Uptime": 3600,
+  "Map": "Procedural Map",
+  "Framerate": 59.8,
+  "Memory": 8123,
+  "Collections": 12,
+  "NetworkIn": 1024,
+  "NetworkOut": 2048,
+  "Restarting": false,
+  "SaveCreatedTime": "10/15/2026 06:00:00",
+  "Version": 2510
+}`
+
+func TestParseRustServerInfo(t *testing.T) {
+	result, err := Parse(Rust, "serverinfo", rustServerInfo)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	info := result.(*RustServerInfo)
+	want := RustServerInfo{Hostname: "Rust Arena", Map: "Procedural Map", Players: 3, MaxPlayers: 100, Queued: 1,
+		EntityCount: 154321, GameTime: "10/16/2026 12:00:00", Uptime: 3600, Framerate: 59.8, Memory: 8123,
+		NetworkIn: 1024, NetworkOut: 2048, SaveCreatedTime: "10/15/2026 06:00:00", Version: 2510}
+	if *info != want {
+		t.Errorf("Expected %+v, got %+v", want, *info)
+	}
+
+	if _, err := Parse(Rust, "serverinfo", "Unknown command: serverinfo"); err == nil {
+		t.Error("Expected text output to be refused")
+	}
+}
+
+func TestParseRustChat(t *testing.T) {
+	chat, err := ParseRustChat(`{"Channel":1,"Message":"base at G12","UserId":"76561197960290418","Username":"Bob","Color":"#5af","Time":1792152000}`)
+	if err != nil {
+		t.Fatalf("ParseRustChat failed: %v", err)
+	}
+	want := RustChat{Channel: 1, Message: "base at G12", UserID: "76561197960290418", Username: "Bob", Time: 1792152000}
+	if *chat != want {
+		t.Errorf("Expected %+v, got %+v", want, *chat)
+	}
+}
+
+func TestParseRustChange(t *testing.T) {
+	tests := []struct {
+		command, output string
+		wantChanged     bool
+		wantErr         bool
+	}{
+		{`kick "Bob" "afk"`, "", true, false},
+		{`kick "Nobody" "afk"`, "Player not found", false, true},
+		{`banid 76561197960290418 "Bob" "cheating"`, "Kickbanned User: 76561197960290418 - Bob", true, false},
+		{`banid 76561197960290418 "Bob" "cheating"`, "User 76561197960290418 is already banned", false, false},
+	}
+	for _, tt := range tests {
+		change, err := ParseRustChange(tt.command, tt.output)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRustChange(%q, %q) error = %v, wantErr %v", tt.command, tt.output, err, tt.wantErr)
+			continue
+		}
+		if err == nil && change.Changed != tt.wantChanged {
+			t.Errorf("ParseRustChange(%q, %q) changed = %v, want %v", tt.command, tt.output, change.Changed, tt.wantChanged)
+		}
+	}
+}
+
+func TestValidRustPlayer(t *testing.T) {
+	for player, want := range map[string]bool{
+		"76561197960290418": true,
+		"Bob the Builder":   true,
+		"":                  false,
+		`Bob"; quit; "`:     false,
+	} {
+		if got := ValidRustPlayer(player); got != want {
+			t.Errorf("ValidRustPlayer(%q) = %v, want %v", player, got, want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SourceStatus is the structured form of the Source engine "status" command.
+type SourceStatus struct {
+	Hostname   string         `json:"hostname"`
+	Version    string         `json:"version,omitempty"`
+	Map        string         `json:"map"`
+	Humans     int            `json:"humans"`
+	Bots       int            `json:"bots"`
+	MaxPlayers int            `json:"max_players"`
+	Players    []SourcePlayer `json:"players"`
+}
+
+// SourcePlayer describes one row of the player table in "status" output.
+type SourcePlayer struct {
+	UserID    int    `json:"userid"`
+	Name      string `json:"name"`
+	UniqueID  string `json:"uniqueid"` // SteamID, or "BOT" for bots; CS2 lists no SteamIDs
+	Connected string `json:"connected,omitempty"`
+	Ping      int    `json:"ping,omitempty"`
+	Loss      int    `json:"loss,omitempty"`
+	State     string `json:"state,omitempty"`
+	Address   string `json:"address,omitempty"`
+}
+
+var (
+	// sourceHeaderPattern matches "key : value" lines in the status header.
+	sourceHeaderPattern = regexp.MustCompile(`^(\w[\w/ ]*?)\s*:\s*(.*)$`)
+	// sourcePlayersPattern matches the player count line, e.g.
+	// "2 humans, 0 bots (16/0 max)" or "1 humans, 0 bots (24 max)".
+	sourcePlayersPattern = regexp.MustCompile(`(\d+) humans?, (\d+) bots? \((\d+)(?:/\d+)? max\)`)
+	// sourcePlayerRowPattern matches the identifying prefix of a row of the
+	// player table. CS:GO inserts an extra slot column after the userid.
+	sourcePlayerRowPattern = regexp.MustCompile(`^#\s*(\d+)\s+(?:\d+\s+)?"(.*)"\s+(\S+)(.*)$`)
+	// cs2PlayerRowPattern matches a row of the CS2 player table, "id time
+	// ping loss state rate adr 'name'", where bots have BOT for a time and
+	// no address.
+	cs2PlayerRowPattern = regexp.MustCompile(`^\s*(\d+)\s+(\S+)\s+(\d+)\s+(\d+)\s+(\w+)\s+\d+\s+(?:(\S+)\s+)?'(.*)'\s*$`)
+	// cs2SpawnGroupPattern matches the line naming the map CS2 loaded, e.g.
+	// "loaded spawngroup(  1)  : SV:  [1: de_dust2 | main lump | mapload]".
+	cs2SpawnGroupPattern = regexp.MustCompile(`^loaded spawngroup\(\s*\d+\)\s*:\s*SV:\s*\[\d+:\s*(\S+)\s*\|\s*main lump`)
+)
+
+// parseSourceStatus parses the output of the Source engine "status" command,
+// in the layouts of Source 1 games such as CS:GO and TF2 and of CS2.
+func parseSourceStatus(output string) (any, error) {
+	status := &SourceStatus{Players: []SourcePlayer{}}
+	sawHostname := false
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, "#") {
+			if player, ok := parseSourcePlayerRow(line); ok {
+				status.Players = append(status.Players, player)
+			}
+			continue
+		}
+		if player, ok := parseCS2PlayerRow(line); ok {
+			status.Players = append(status.Players, player)
+			continue
+		}
+		if m := cs2SpawnGroupPattern.FindStringSubmatch(line); m != nil && status.Map == "" {
+			status.Map = m[1]
+			continue
+		}
+
+		m := sourceHeaderPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		value := strings.TrimSpace(m[2])
+		switch strings.TrimSpace(m[1]) {
+		case "hostname":
+			status.Hostname = value
+			sawHostname = true
+		case "version":
+			status.Version = value
+		case "map":
+			// TF2 appends the camera position: "ctf_2fort at: 0 x, 0 y, 0 z".
+			status.Map, _, _ = strings.Cut(value, " ")
+		case "players":
+			if pm := sourcePlayersPattern.FindStringSubmatch(value); pm != nil {
+				status.Humans, _ = strconv.Atoi(pm[1])
+				status.Bots, _ = strconv.Atoi(pm[2])
+				status.MaxPlayers, _ = strconv.Atoi(pm[3])
+			}
+		}
+	}
+
+	if !sawHostname {
+		return nil, fmt.Errorf("unrecognized source status output")
+	}
+	return status, nil
+}
+
+// parseSourcePlayerRow parses a single "# userid name uniqueid ..." row.
+// Returns false for the table header and "#end" marker.
+func parseSourcePlayerRow(line string) (SourcePlayer, bool) {
+	m := sourcePlayerRowPattern.FindStringSubmatch(line)
+	if m == nil {
+		return SourcePlayer{}, false
+	}
+
+	userID, _ := strconv.Atoi(m[1])
+	player := SourcePlayer{UserID: userID, Name: m[2], UniqueID: m[3]}
+
+	// Human rows continue with "connected ping loss state [rate] [adr]".
+	// Bot rows only carry a state and are left with the identifying fields.
+	fields := strings.Fields(m[4])
+	if len(fields) < 4 {
+		return player, true
+	}
+	ping, pingErr := strconv.Atoi(fields[1])
+	loss, lossErr := strconv.Atoi(fields[2])
+	if pingErr != nil || lossErr != nil {
+		return player, true
+	}
+	player.Connected = fields[0]
+	player.Ping = ping
+	player.Loss = loss
+	player.State = fields[3]
+	if last := fields[len(fields)-1]; len(fields) > 4 && strings.Contains(last, ":") {
+		player.Address = last
+	}
+	return player, true
+}
+
+// parseCS2PlayerRow parses a single row of the CS2 player table. Returns
+// false for lines that are not one, such as the header.
+func parseCS2PlayerRow(line string) (SourcePlayer, bool) {
+	m := cs2PlayerRowPattern.FindStringSubmatch(line)
+	if m == nil {
+		return SourcePlayer{}, false
+	}
+	userID, _ := strconv.Atoi(m[1])
+	player := SourcePlayer{UserID: userID, Name: m[7]}
+	if m[2] == "BOT" {
+		player.UniqueID = "BOT"
+		return player, true
+	}
+	player.Connected = m[2]
+	player.Ping, _ = strconv.Atoi(m[3])
+	player.Loss, _ = strconv.Atoi(m[4])
+	player.State = m[5]
+	player.Address = m[6]
+	return player, true
+}
+
+var (
+	// sourcePathPattern matches map names, including workshop paths such
+	// as "workshop/123456789/de_cache", and config file names relative to
+	// the cfg directory, such as "gamemode_competitive.cfg".
+	sourcePathPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_./-]{0,127}$`)
+	// steamIDPattern matches the SteamID forms kickid and banid accept:
+	// STEAM_X:Y:Z, [U:1:Z] and 64-bit SteamIDs.
+	steamIDPattern = regexp.MustCompile(`^(?:STEAM_[0-5]:[01]:\d{1,10}|\[U:1:\d{1,10}\]|7656119\d{10})$`)
+)
+
+// ValidSourceMap reports whether name is a map name that can be put in a
+// command without changing its meaning or leaving the maps directory.
+func ValidSourceMap(name string) bool {
+	return sourcePathPattern.MatchString(name) && !strings.Contains(name, "..")
+}
+
+// ValidSourceConfig reports whether name is a config file name that can be
+// put in an exec command without leaving the cfg directory.
+func ValidSourceConfig(name string) bool {
+	return sourcePathPattern.MatchString(name) && !strings.Contains(name, "..")
+}
+
+// ValidSteamID reports whether id is a SteamID in one of the forms Source
+// servers accept: STEAM_1:0:12345, [U:1:24690] or 76561197960290418.
+func ValidSteamID(id string) bool {
+	return steamIDPattern.MatchString(id)
+}
+
+// sourceFailures holds, for each Source command that changes the server's
+// state, the pattern of replies in which it failed. Source servers mostly
+// reply with nothing, or with log lines, when such a command succeeds.
+var sourceFailures = map[string]*regexp.Regexp{
+	"changelevel": regexp.MustCompile(`(?i)changelevel failed|can't find map|map .* not found|not a valid map|invalid map`),
+	"exec":        regexp.MustCompile(`(?i)not present; not executing|couldn't exec|could not exec`),
+	"kickid":      regexp.MustCompile(`(?i)not found|can't find|couldn't find|no such|usage:`),
+}
+
+// ParseSourceChange reads the reply of a Source engine server to command,
+// one of "changelevel", "exec" and "kickid" with its arguments. Replies
+// reporting a failure, such as an unknown map, are returned as errors
+// carrying the reply; any other reply, an empty one included, means the
+// command took effect.
+func ParseSourceChange(command, output string) (*Change, error) {
+	verb, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(command)), " ")
+	failed, ok := sourceFailures[verb]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoParser, command)
+	}
+	message := strings.TrimSpace(output)
+	if failed.MatchString(message) {
+		return nil, errors.New(message)
+	}
+	return &Change{Changed: true, Message: message}, nil
+}
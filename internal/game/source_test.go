@@ -0,0 +1,226 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const csgoStatus = `hostname: Counter-Strike: Global Offensive
+version : 1.38.7.9/13879 1575 secure  [G:1:1234567]
+udp/ip  : 0.0.0.0:27015  (public ip: 203.0.113.1)
+os      :  Linux
+type    :  community dedicated
+map     : de_dust2
+players : 2 humans, 1 bots (16/0 max) (not hibernating)
+
+# userid name uniqueid connected ping loss state rate adr
+#  2 1 "Alice" STEAM_1:0:12345 01:23 50 0 active 196608 198.51.100.7:27005
+#  3 2 "Bob Smith" STEAM_1:1:67890 12:05 35 1 active 786432 198.51.100.8:27005
+#  4 "Bot Joe" BOT active 64
+#end
+`
+
+const tf2Status = `hostname: TF2 Server
+version : 8604529/24 8604529 secure
+udp/ip  : 10.0.0.5:27015
+map     : ctf_2fort at: 0 x, 0 y, 0 z
+players : 1 humans, 0 bots (24 max)
+# userid name                uniqueid            connected ping loss state  adr
+#      2 "Carol"             [U:1:12345]         05:12       55    0 active 198.51.100.9:27005
+`
+
+const cs2Status = `Server:  Running [0.0.0.0:27015]
+Client:  Disconnected
+@ Current  :  game
+source   :  console
+hostname  : CS2 Arena
+spawn     : 1
+version   : 1.40.1.2/14012 9912 secure  public
+steamid   : [G:1:1234567] (85568392920040487)
+udp/ip    : 0.0.0.0:27015 (local: 172.18.0.2:27015) (public IP from Steam: 203.0.113.1)
+os/type   : Linux dedicated
+players   : 1 humans, 1 bots (10 max) (not hibernating) (unreserved)
+loaded spawngroup(  1)  : SV:  [1: de_mirage | main lump | mapload]
+---------players--------
+  id     time ping loss      state   rate adr name
+65535 [NO SLOT]
+    2    03:42   23    0     active 786432 198.51.100.7:27005 'Dave O'Hara'
+    3      BOT    0    0     active      0 'Bot Ivan'
+#end
+`
+
+func TestParseSourceStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *SourceStatus
+		wantErr bool
+	}{
+		{
+			name:   "csgo",
+			output: csgoStatus,
+			want: &SourceStatus{
+				Hostname:   "Counter-Strike: Global Offensive",
+				Version:    "1.38.7.9/13879 1575 secure  [G:1:1234567]",
+				Map:        "de_dust2",
+				Humans:     2,
+				Bots:       1,
+				MaxPlayers: 16,
+				Players: []SourcePlayer{
+					{UserID: 2, Name: "Alice", UniqueID: "STEAM_1:0:12345", Connected: "01:23", Ping: 50, State: "active", Address: "198.51.100.7:27005"},
+					{UserID: 3, Name: "Bob Smith", UniqueID: "STEAM_1:1:67890", Connected: "12:05", Ping: 35, Loss: 1, State: "active", Address: "198.51.100.8:27005"},
+					{UserID: 4, Name: "Bot Joe", UniqueID: "BOT"},
+				},
+			},
+		},
+		{
+			name:   "tf2",
+			output: tf2Status,
+			want: &SourceStatus{
+				Hostname:   "TF2 Server",
+				Version:    "8604529/24 8604529 secure",
+				Map:        "ctf_2fort",
+				Humans:     1,
+				MaxPlayers: 24,
+				Players: []SourcePlayer{
+					{UserID: 2, Name: "Carol", UniqueID: "[U:1:12345]", Connected: "05:12", Ping: 55, State: "active", Address: "198.51.100.9:27005"},
+				},
+			},
+		},
+		{
+			name:   "cs2",
+			output: cs2Status,
+			want: &SourceStatus{
+				Hostname:   "CS2 Arena",
+				Version:    "1.40.1.2/14012 9912 secure  public",
+				Map:        "de_mirage",
+				Humans:     1,
+				Bots:       1,
+				MaxPlayers: 10,
+				Players: []SourcePlayer{
+					{UserID: 2, Name: "Dave O'Hara", Connected: "03:42", Ping: 23, State: "active", Address: "198.51.100.7:27005"},
+					{UserID: 3, Name: "Bot Ivan", UniqueID: "BOT"},
+				},
+			},
+		},
+		{
+			name:    "unrecognized output",
+			output:  "Unknown command \"status\"",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSourceStatus(tt.output)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseSourceChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		output  string
+		want    *Change
+		wantErr string
+	}{
+		{
+			name:    "map changed without a reply",
+			command: "changelevel de_nuke",
+			want:    &Change{Changed: true},
+		},
+		{
+			name:    "unknown map",
+			command: "changelevel de_nowhere",
+			output:  "changelevel failed: de_nowhere not found\n",
+			wantErr: "changelevel failed",
+		},
+		{
+			name:    "config executed",
+			command: "exec gamemode_competitive",
+			output:  "Executing gamemode_competitive.cfg",
+			want:    &Change{Changed: true, Message: "Executing gamemode_competitive.cfg"},
+		},
+		{
+			name:    "missing config",
+			command: "EXEC missing.cfg",
+			output:  "'missing.cfg' not present; not executing.",
+			wantErr: "not present",
+		},
+		{
+			name:    "player not on the server",
+			command: "kickid STEAM_1:0:12345",
+			output:  "kickid: STEAM_1:0:12345 not found",
+			wantErr: "not found",
+		},
+		{
+			name:    "unsupported command",
+			command: "mp_restartgame 1",
+			wantErr: "no parser",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSourceChange(tt.command, tt.output)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidSourceNames(t *testing.T) {
+	for name, want := range map[string]bool{
+		"de_dust2":                  true,
+		"workshop/123456/de_cache":  true,
+		"gamemode_competitive.cfg":  true,
+		"":                          false,
+		"de_dust2; rcon_password x": false,
+		"../../server.cfg":          false,
+		"/etc/passwd":               false,
+	} {
+		if got := ValidSourceMap(name); got != want {
+			t.Errorf("ValidSourceMap(%q) = %v, want %v", name, got, want)
+		}
+		if got := ValidSourceConfig(name); got != want {
+			t.Errorf("ValidSourceConfig(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	for id, want := range map[string]bool{
+		"STEAM_1:0:12345":   true,
+		"[U:1:24690]":       true,
+		"76561197960290418": true,
+		"12345":             false,
+		"STEAM_1:0:1; quit": false,
+		"[U:1:]":            false,
+	} {
+		if got := ValidSteamID(id); got != want {
+			t.Errorf("ValidSteamID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
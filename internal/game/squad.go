@@ -0,0 +1,21 @@
+package game
+
+import "strings"
+
+// PushesChat reports whether servers of game t send chat and admin events
+// over RCON unprompted, as packets interleaved with the replies to
+// commands, which a client must read as they come to keep the two apart.
+func PushesChat(t Type) bool {
+	return t == Squad || t == PostScriptum
+}
+
+// SquadMessageType returns the console type of a message a Squad or Post
+// Scriptum server pushed: "Chat" for the chat of any channel, which starts
+// with its channel such as "[ChatAll]", and "Log" for events such as a
+// player entering the admin camera.
+func SquadMessageType(message string) string {
+	if strings.HasPrefix(message, "[Chat") {
+		return "Chat"
+	}
+	return "Log"
+}
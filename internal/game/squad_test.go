@@ -0,0 +1,18 @@
+package game
+
+import "testing"
+
+func TestSquadMessageType(t *testing.T) {
+	for _, tt := range []struct{ message, want string }{
+		{"[ChatAll] [Online IDs:EOS: 0002a1b2 steam: 76561197960290418] Bob : hello", "Chat"},
+		{"[ChatSquad] [SteamID:76561197960290418] Bob : rally up", "Chat"},
+		{"[SteamID:76561197960290418] Admin has possessed admin camera.", "Log"},
+	} {
+		if got := SquadMessageType(tt.message); got != tt.want {
+			t.Errorf("SquadMessageType(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+	if !PushesChat(Squad) || !PushesChat(PostScriptum) || PushesChat(Source) {
+		t.Error("Expected only Squad and Post Scriptum to push chat")
+	}
+}
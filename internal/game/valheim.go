@@ -0,0 +1,40 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// valheimFailure matches the replies in which Valheim RCON mods report
+// that a command failed, such as an unknown player or a usage hint.
+var valheimFailure = regexp.MustCompile(`(?i)unknown|not found|no player|usage|error|fail`)
+
+// ValidValheimPlayer reports whether player, a name or platform ID such as
+// a SteamID64, can be passed to a Valheim RCON command: the mods split a
+// command at spaces and line breaks.
+func ValidValheimPlayer(player string) bool {
+	return player != "" && !strings.ContainsAny(player, " \t\r\n\"")
+}
+
+// ParseValheimChange reads the reply of a Valheim RCON mod to command, one
+// of "save", "kick" and "ban" with its arguments. An empty reply means the
+// command succeeded, as does any reply but one reporting a failure, which
+// is returned as an error carrying the reply.
+func ParseValheimChange(command, output string) (*Change, error) {
+	verb, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(command)), " ")
+	switch verb {
+	case "save", "kick", "ban":
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrNoParser, command)
+	}
+	if change, ok := EmptyReply(Valheim, output); ok {
+		return change, nil
+	}
+	message := strings.TrimSpace(output)
+	if valheimFailure.MatchString(message) {
+		return nil, errors.New(message)
+	}
+	return &Change{Changed: true, Message: message}, nil
+}
@@ -0,0 +1,39 @@
+package game
+
+import "testing"
+
+func TestParseValheimChange(t *testing.T) {
+	tests := []struct {
+		command, output string
+		wantMessage     string
+		wantErr         bool
+	}{
+		{"save", "", emptyReplies[Valheim], false},
+		{"kick 76561197960290418", " \n", emptyReplies[Valheim], false},
+		{"ban Bob", "Banned Bob", "Banned Bob", false},
+		{"kick Alice", "Player Alice not found", "", true},
+		{"say hi", "", "", true},
+	}
+	for _, tt := range tests {
+		change, err := ParseValheimChange(tt.command, tt.output)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseValheimChange(%q, %q) error = %v, wantErr %v", tt.command, tt.output, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (!change.Changed || change.Message != tt.wantMessage) {
+			t.Errorf("ParseValheimChange(%q, %q) = %+v, want message %q", tt.command, tt.output, change, tt.wantMessage)
+		}
+	}
+}
+
+func TestEmptyReply(t *testing.T) {
+	if _, ok := EmptyReply(Valheim, ""); !ok {
+		t.Error("Expected an empty Valheim reply to stand for success")
+	}
+	if _, ok := EmptyReply(Valheim, "Banned Bob"); ok {
+		t.Error("Expected a reply with text to be left alone")
+	}
+	if _, ok := EmptyReply(Minecraft, ""); ok {
+		t.Error("Expected an empty reply from other games to be left alone")
+	}
+}
@@ -0,0 +1,82 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// zomboidNoReply is the message of a Change for the empty reply Project
+// Zomboid sends now and then to commands it ran.
+const zomboidNoReply = "the server sent an empty reply, as Project Zomboid does at times"
+
+// zomboidPlayersPattern matches the first line of the Project Zomboid
+// "players" command, "Players connected (2): ".
+var zomboidPlayersPattern = regexp.MustCompile(`^Players connected \((\d+)\):`)
+
+// parseZomboidPlayers parses the output of the Project Zomboid "players"
+// command: a count followed by one "-name" line per player.
+func parseZomboidPlayers(output string) (any, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	m := zomboidPlayersPattern.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if m == nil {
+		return nil, errors.New("unrecognized zomboid players output")
+	}
+	list := &PlayerList{Players: []string{}}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, ok := strings.CutPrefix(line, "-")
+		if !ok {
+			return nil, fmt.Errorf("unrecognized zomboid players line %q", line)
+		}
+		list.Players = append(list.Players, name)
+	}
+	list.Online, _ = strconv.Atoi(m[1])
+	return list, nil
+}
+
+// ZomboidText returns text trimmed for the quoted argument of servermsg,
+// or an error if it cannot be sent as one: Project Zomboid has no escape
+// for quotes and cuts a command at a line break.
+func ZomboidText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if strings.ContainsAny(text, "\"\r\n") {
+		return "", errors.New("zomboid messages may not contain quotes or line breaks")
+	}
+	return text, nil
+}
+
+// zomboidChanges holds, for each Project Zomboid command that changes the
+// server's state, the pattern of replies in which it took effect.
+var zomboidChanges = map[string]*regexp.Regexp{
+	"servermsg": regexp.MustCompile(`(?i)^Message sent`),
+	"save":      regexp.MustCompile(`(?i)^World saved`),
+	"quit":      regexp.MustCompile(`(?i)^Quit`),
+}
+
+// ParseZomboidChange reads the reply of Project Zomboid to command, one
+// of "servermsg", "save" and "quit" with its arguments. An empty reply
+// counts as the command taking effect, as Project Zomboid runs the
+// commands it sends no reply to; other replies are returned as errors
+// carrying the reply.
+func ParseZomboidChange(command, output string) (*Change, error) {
+	verb, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(command)), " ")
+	done, ok := zomboidChanges[verb]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoParser, command)
+	}
+	message := strings.TrimSpace(output)
+	switch {
+	case done.MatchString(message):
+		return &Change{Changed: true, Message: message}, nil
+	case message == "":
+		return &Change{Changed: true, Message: zomboidNoReply}, nil
+	default:
+		return nil, errors.New(message)
+	}
+}
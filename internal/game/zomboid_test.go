@@ -0,0 +1,70 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseZomboidPlayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []string
+		wantErr bool
+	}{
+		{name: "players", output: "Players connected (2): \n-Bob\n-Alice Smith\n", want: []string{"Bob", "Alice Smith"}},
+		{name: "empty", output: "Players connected (0): \n", want: []string{}},
+		{name: "empty reply", output: "", wantErr: true},
+		{name: "not a listing", output: "Unknown command /players", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(Zomboid, "players", tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			list := result.(*PlayerList)
+			if list.Online != len(tt.want) || !reflect.DeepEqual(list.Players, tt.want) {
+				t.Errorf("Expected %q, got %+v", tt.want, list)
+			}
+		})
+	}
+}
+
+func TestZomboidText(t *testing.T) {
+	if got, err := ZomboidText("  Restart in 5 minutes "); err != nil || got != "Restart in 5 minutes" {
+		t.Errorf("ZomboidText() = %q, %v", got, err)
+	}
+	for _, text := range []string{`say "hi"`, "two\nlines"} {
+		if _, err := ZomboidText(text); err == nil {
+			t.Errorf("Expected %q to be refused", text)
+		}
+	}
+}
+
+func TestParseZomboidChange(t *testing.T) {
+	tests := []struct {
+		command, output string
+		wantMessage     string
+		wantErr         bool
+	}{
+		{`servermsg "Hello"`, "Message sent.", "Message sent.", false},
+		{"save", "World saved\n", "World saved", false},
+		{"quit", "Quit", "Quit", false},
+		{"save", "", zomboidNoReply, false},
+		{"save", "Not allowed", "", true},
+	}
+	for _, tt := range tests {
+		change, err := ParseZomboidChange(tt.command, tt.output)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseZomboidChange(%q, %q) error = %v, wantErr %v", tt.command, tt.output, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (!change.Changed || change.Message != tt.wantMessage) {
+			t.Errorf("ParseZomboidChange(%q, %q) = %+v, want message %q", tt.command, tt.output, change, tt.wantMessage)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package gamelog receives the log streams Source engine servers send over
+// UDP once told to with logaddress_add, and reads chat, kills, connects and
+// disconnects out of their lines. A Receiver only listens; registering it
+// with a server is done over RCON by the caller.
+package gamelog
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/netip"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPacket is the largest log packet read. Servers send one line per
+// packet, well below a UDP MTU.
+const maxPacket = 2048
+
+// Kinds of log lines.
+const (
+	KindChat       = "chat"       // A player said something to everyone
+	KindTeamChat   = "team_chat"  // A player said something to their team
+	KindKill       = "kill"       // A player killed another
+	KindConnect    = "connect"    // A player connected
+	KindDisconnect = "disconnect" // A player disconnected
+	KindOther      = "other"      // Any other line
+)
+
+// Line is one line of a server's log.
+type Line struct {
+	Time    time.Time `json:"time"`               // When the server logged the line, in the server's time zone read as UTC
+	Kind    string    `json:"kind"`               // One of the Kind constants
+	Text    string    `json:"text"`               // The line without its timestamp
+	Player  string    `json:"player,omitempty"`   // Player who chatted, connected or disconnected
+	SteamID string    `json:"steam_id,omitempty"` // SteamID of Player, or of Killer
+	Message string    `json:"message,omitempty"`  // What Player said
+	Killer  string    `json:"killer,omitempty"`   // Player who killed Victim
+	Victim  string    `json:"victim,omitempty"`   // Player Killer killed
+	Weapon  string    `json:"weapon,omitempty"`   // Weapon Killer used
+}
+
+// logTimeLayout is the layout of the timestamp that starts every line,
+// "L 10/16/2026 - 12:34:56: ".
+const logTimeLayout = "01/02/2006 - 15:04:05"
+
+const (
+	// playerPattern matches a player as logs quote them,
+	// "Name<userid><SteamID><team>", with the name, SteamID and team
+	// captured. Bots have the SteamID BOT.
+	playerPattern = `"(.*?)<\d+><([^>]*)><([^>]*)>"`
+	// positionPattern matches the coordinates CS:GO and CS2 log after
+	// players.
+	positionPattern = `(?: \[-?\d+ -?\d+ -?\d+\])?`
+)
+
+// Patterns of the lines ParseLine reads more than the text of.
+var (
+	chatPattern       = regexp.MustCompile(`^` + playerPattern + ` (say|say_team) "(.*)"$`)
+	killPattern       = regexp.MustCompile(`^` + playerPattern + positionPattern + ` killed ` + playerPattern + positionPattern + ` with "([^"]*)"`)
+	connectPattern    = regexp.MustCompile(`^` + playerPattern + ` connected`)
+	disconnectPattern = regexp.MustCompile(`^` + playerPattern + ` disconnected`)
+)
+
+// ParseLine reads one log line, as sent by a server after the packet
+// header: "L 10/16/2026 - 12:34:56: text". Lines without a timestamp are
+// kept whole with a zero Time.
+func ParseLine(raw string) Line {
+	raw = strings.TrimRight(raw, "\x00\r\n")
+	var line Line
+	if rest, ok := strings.CutPrefix(raw, "L "); ok && len(rest) > len(logTimeLayout)+1 && rest[len(logTimeLayout)] == ':' {
+		if t, err := time.Parse(logTimeLayout, rest[:len(logTimeLayout)]); err == nil {
+			line.Time = t
+			raw = strings.TrimPrefix(rest[len(logTimeLayout)+1:], " ")
+		}
+	}
+	line.Text = raw
+	line.Kind = KindOther
+
+	if m := chatPattern.FindStringSubmatch(raw); m != nil {
+		line.Kind, line.Player, line.SteamID, line.Message = KindChat, m[1], m[2], m[5]
+		if m[4] == "say_team" {
+			line.Kind = KindTeamChat
+		}
+		return line
+	}
+	if m := killPattern.FindStringSubmatch(raw); m != nil {
+		line.Kind, line.Killer, line.SteamID, line.Victim, line.Weapon = KindKill, m[1], m[2], m[4], m[7]
+		return line
+	}
+	if m := connectPattern.FindStringSubmatch(raw); m != nil {
+		line.Kind, line.Player, line.SteamID = KindConnect, m[1], m[2]
+		return line
+	}
+	if m := disconnectPattern.FindStringSubmatch(raw); m != nil {
+		line.Kind, line.Player, line.SteamID = KindDisconnect, m[1], m[2]
+	}
+	return line
+}
+
+// parsePacket returns the log line carried by a packet: four 0xFF bytes,
+// then 'R' and the line, or 'S', the server's sv_logsecret and the line.
+// Older engines send "log " before the line instead.
+func parsePacket(packet []byte) (string, bool) {
+	rest, ok := bytes.CutPrefix(packet, []byte{0xFF, 0xFF, 0xFF, 0xFF})
+	if !ok || len(rest) == 0 {
+		return "", false
+	}
+	switch {
+	case rest[0] == 'R':
+		rest = rest[1:]
+	case rest[0] == 'S':
+		rest = bytes.TrimLeft(rest[1:], "0123456789")
+	default:
+		rest, ok = bytes.CutPrefix(rest, []byte("log "))
+		if !ok {
+			return "", false
+		}
+	}
+	return string(rest), true
+}
+
+// Handler is called with every line a Receiver gets, and the address of
+// the server that sent it.
+type Handler func(from netip.AddrPort, line Line)
+
+// Receiver listens for log packets on a UDP port and passes their lines
+// to its Handler, one at a time in the order they arrive.
+type Receiver struct {
+	conn   net.PacketConn
+	handle Handler
+	done   sync.WaitGroup
+}
+
+// Listen starts a Receiver on the UDP address, e.g. ":27500", passing the
+// lines it gets to handle until it is closed.
+func Listen(address string, handle Handler) (*Receiver, error) {
+	conn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	r := &Receiver{conn: conn, handle: handle}
+	r.done.Add(1)
+	go r.serve()
+	return r, nil
+}
+
+// Addr returns the address the Receiver listens on.
+func (r *Receiver) Addr() net.Addr {
+	return r.conn.LocalAddr()
+}
+
+// Close stops the Receiver and waits for the line being handled, if any.
+func (r *Receiver) Close() error {
+	err := r.conn.Close()
+	r.done.Wait()
+	return err
+}
+
+// serve reads packets until the Receiver is closed.
+func (r *Receiver) serve() {
+	defer r.done.Done()
+	buf := make([]byte, maxPacket)
+	for {
+		n, addr, err := r.conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			continue
+		}
+		raw, ok := parsePacket(buf[:n])
+		udp, isUDP := addr.(*net.UDPAddr)
+		if !ok || !isUDP {
+			continue
+		}
+		from := udp.AddrPort()
+		r.handle(netip.AddrPortFrom(from.Addr().Unmap(), from.Port()), ParseLine(raw))
+	}
+}
@@ -0,0 +1,110 @@
+package gamelog
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestParseLine(t *testing.T) {
+	logged := time.Date(2026, 10, 16, 12, 34, 56, 0, time.UTC)
+	tests := []struct {
+		name string
+		raw  string
+		want Line
+	}{
+		{
+			name: "chat",
+			raw:  `L 10/16/2026 - 12:34:56: "Alice<2><STEAM_1:0:12345><CT>" say "gg wp"` + "\n\x00",
+			want: Line{Time: logged, Kind: KindChat, Text: `"Alice<2><STEAM_1:0:12345><CT>" say "gg wp"`,
+				Player: "Alice", SteamID: "STEAM_1:0:12345", Message: "gg wp"},
+		},
+		{
+			name: "team chat",
+			raw:  `L 10/16/2026 - 12:34:56: "Bob<3><[U:1:24690]><TERRORIST>" say_team "rush b"`,
+			want: Line{Time: logged, Kind: KindTeamChat, Text: `"Bob<3><[U:1:24690]><TERRORIST>" say_team "rush b"`,
+				Player: "Bob", SteamID: "[U:1:24690]", Message: "rush b"},
+		},
+		{
+			name: "kill with positions",
+			raw: `L 10/16/2026 - 12:34:56: "Alice<2><STEAM_1:0:12345><CT>" [-12 340 5] killed ` +
+				`"Bot Joe<4><BOT><TERRORIST>" [100 -2 0] with "ak47" (headshot)`,
+			want: Line{Time: logged, Kind: KindKill,
+				Text:   `"Alice<2><STEAM_1:0:12345><CT>" [-12 340 5] killed "Bot Joe<4><BOT><TERRORIST>" [100 -2 0] with "ak47" (headshot)`,
+				Killer: "Alice", SteamID: "STEAM_1:0:12345", Victim: "Bot Joe", Weapon: "ak47"},
+		},
+		{
+			name: "connect",
+			raw:  `L 10/16/2026 - 12:34:56: "Carol<5><STEAM_1:1:1><>" connected, address "198.51.100.9:27005"`,
+			want: Line{Time: logged, Kind: KindConnect, Text: `"Carol<5><STEAM_1:1:1><>" connected, address "198.51.100.9:27005"`,
+				Player: "Carol", SteamID: "STEAM_1:1:1"},
+		},
+		{
+			name: "disconnect",
+			raw:  `L 10/16/2026 - 12:34:56: "Carol<5><STEAM_1:1:1><CT>" disconnected (reason "Disconnect")`,
+			want: Line{Time: logged, Kind: KindDisconnect, Text: `"Carol<5><STEAM_1:1:1><CT>" disconnected (reason "Disconnect")`,
+				Player: "Carol", SteamID: "STEAM_1:1:1"},
+		},
+		{
+			name: "other",
+			raw:  `L 10/16/2026 - 12:34:56: World triggered "Round_Start"`,
+			want: Line{Time: logged, Kind: KindOther, Text: `World triggered "Round_Start"`},
+		},
+		{
+			name: "no timestamp",
+			raw:  "server cvars start",
+			want: Line{Kind: KindOther, Text: "server cvars start"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseLine(tt.raw); got != tt.want {
+				t.Errorf("ParseLine() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	lines := make(chan Line, 4)
+	var from netip.AddrPort
+	r, err := Listen("127.0.0.1:0", func(f netip.AddrPort, line Line) {
+		from = f
+		lines <- line
+	})
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer r.Close()
+
+	conn, err := net.Dial("udp", r.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	for _, packet := range []string{
+		"\xff\xff\xff\xffRL 10/16/2026 - 12:34:56: first\n\x00",
+		"not a log packet",
+		"\xff\xff\xff\xffS12345L 10/16/2026 - 12:34:57: second\n\x00",
+		"\xff\xff\xff\xfflog L 10/16/2026 - 12:34:58: third\n\x00",
+	} {
+		if _, err := conn.Write([]byte(packet)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	for _, want := range []string{"first", "second", "third"} {
+		select {
+		case line := <-lines:
+			if line.Text != want {
+				t.Errorf("Expected %q, got %q", want, line.Text)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for %q", want)
+		}
+	}
+	if from.String() != conn.LocalAddr().String() {
+		t.Errorf("Expected the lines to come from %s, got %s", conn.LocalAddr(), from)
+	}
+}
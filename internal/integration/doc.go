@@ -0,0 +1,20 @@
+// Package integration holds end-to-end tests that run the RCON client
+// against real game servers in Docker containers: a Minecraft server from
+// the itzg/minecraft-server image and a Source dedicated server. They
+// connect, authenticate, run commands and read replies spanning several
+// packets over the servers' own RCON.
+//
+// The tests are behind the "integration" build tag, since they need Docker
+// and take minutes to pull and start the servers:
+//
+//	make test-integration
+//
+// or
+//
+//	go test -tags integration -timeout 30m -v ./internal/integration
+//
+// They are skipped when the docker command cannot reach a daemon. The
+// images can be replaced with RCON_IT_MINECRAFT_IMAGE and
+// RCON_IT_SOURCE_IMAGE, and RCON_IT_ONLY=minecraft or RCON_IT_ONLY=source
+// runs one server only.
+package integration
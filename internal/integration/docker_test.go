@@ -0,0 +1,131 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+)
+
+// container is a Docker container started for a test.
+type container struct {
+	id    string
+	image string
+}
+
+// docker runs the docker command with args and returns its trimmed
+// output.
+func docker(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// requireDocker skips the test unless the docker command reaches a daemon.
+func requireDocker(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker not found")
+	}
+	if _, err := docker("info", "--format", "{{.ServerVersion}}"); err != nil {
+		t.Skipf("Docker is not available: %v", err)
+	}
+}
+
+// startContainer runs image detached with env, publishing its TCP port on
+// a free loopback port, and removes it when the test ends. It returns the
+// container and the address its port is published on.
+func startContainer(t *testing.T, image, port string, env []string) (*container, string) {
+	t.Helper()
+	requireDocker(t)
+	args := []string{"run", "--detach", "--publish", "127.0.0.1::" + port + "/tcp"}
+	for _, e := range env {
+		args = append(args, "--env", e)
+	}
+	id, err := docker(append(args, image)...)
+	if err != nil {
+		t.Fatalf("Failed to start %s: %v", image, err)
+	}
+	c := &container{id: id, image: image}
+	t.Cleanup(func() {
+		if t.Failed() {
+			c.dumpLogs(t)
+		}
+		if _, err := docker("rm", "--force", "--volumes", c.id); err != nil {
+			t.Logf("Failed to remove the %s container: %v", image, err)
+		}
+	})
+
+	published, err := docker("port", id, port+"/tcp")
+	if err != nil {
+		t.Fatalf("Failed to find the published port of %s: %v", image, err)
+	}
+	// One line per address family; the loopback one was asked for
+	address := strings.Fields(published)[0]
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		t.Fatalf("Unexpected published port %q: %v", published, err)
+	}
+	return c, address
+}
+
+// dumpLogs logs the last lines of the container's output, to tell why a
+// server did not come up.
+func (c *container) dumpLogs(t *testing.T) {
+	t.Helper()
+	out, err := exec.Command("docker", "logs", "--tail", "50", c.id).CombinedOutput()
+	if err != nil {
+		t.Logf("Failed to read the %s logs: %v", c.image, err)
+		return
+	}
+	t.Logf("Last lines of the %s logs:\n%s", c.image, out)
+}
+
+// waitForRCON connects and authenticates a client to address with password
+// as soon as the server accepts it, polling until timeout passes or the
+// container exits.
+func (c *container) waitForRCON(t *testing.T, address, password string, timeout time.Duration) *rcon.Client {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+	for time.Now().Before(deadline) {
+		if running, err := docker("inspect", "--format", "{{.State.Running}}", c.id); err != nil || running != "true" {
+			t.Fatalf("The %s container stopped before RCON came up: %v", c.image, err)
+		}
+		client := rcon.NewClient()
+		if lastErr = client.Connect(address); lastErr == nil {
+			if lastErr = client.Authenticate(password); lastErr == nil {
+				t.Cleanup(func() { client.Disconnect() })
+				return client
+			}
+			client.Disconnect()
+			if errors.Is(lastErr, rcon.ErrAuthFailed) {
+				t.Fatalf("The %s server refused the password: %v", c.image, lastErr)
+			}
+		}
+		time.Sleep(2 * time.Second)
+	}
+	t.Fatalf("RCON of %s did not come up within %v: %v", c.image, timeout, lastErr)
+	return nil
+}
+
+// imageFor returns the image named by the environment variable env, or
+// fallback if it is unset.
+func imageFor(env, fallback string) string {
+	if image := os.Getenv(env); image != "" {
+		return image
+	}
+	return fallback
+}
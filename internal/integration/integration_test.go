@@ -0,0 +1,162 @@
+//go:build integration
+
+package integration
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+)
+
+// password is the RCON password the servers are started with.
+const password = "integration-test"
+
+// gameServer is a game server image the suite runs.
+type gameServer struct {
+	name      string        // Name RCON_IT_ONLY selects it by
+	image     string        // Image, overridable from the environment
+	port      string        // Container port RCON listens on
+	env       []string      // Environment enabling RCON with password
+	startup   time.Duration // How long the server may take to accept RCON
+	game      game.Type     // Game the probes should detect
+	parse     string        // Command whose output the game's parser reads
+	large     string        // Command whose reply spans several packets
+	maxPacket int           // Largest packet the server sends, if above the protocol's
+}
+
+// gameServers lists the servers the suite runs.
+var gameServers = []gameServer{
+	{
+		name:  "minecraft",
+		image: imageFor("RCON_IT_MINECRAFT_IMAGE", "itzg/minecraft-server"),
+		port:  "25575",
+		env: []string{
+			"EULA=TRUE", "TYPE=VANILLA", "MEMORY=1G", "ONLINE_MODE=FALSE",
+			"ENABLE_RCON=true", "RCON_PASSWORD=" + password,
+		},
+		startup: 5 * time.Minute,
+		game:    game.Minecraft,
+		parse:   "list",
+		large:   "help",
+		// Minecraft splits long replies into bodies of 4096 bytes, making
+		// packets 10 bytes longer than the protocol allows
+		maxPacket: 4096 + 10,
+	},
+	{
+		name:    "source",
+		image:   imageFor("RCON_IT_SOURCE_IMAGE", "cm2network/tf2"),
+		port:    "27015",
+		env:     []string{"SRCDS_RCONPW=" + password, "SRCDS_MAXPLAYERS=2"},
+		startup: 15 * time.Minute,
+		game:    game.Source,
+		parse:   "status",
+		large:   "cvarlist sv_",
+	},
+}
+
+func TestGameServers(t *testing.T) {
+	for _, gs := range gameServers {
+		t.Run(gs.name, func(t *testing.T) {
+			if only := os.Getenv("RCON_IT_ONLY"); only != "" && only != gs.name {
+				t.Skipf("RCON_IT_ONLY is %s", only)
+			}
+			t.Parallel()
+			c, address := startContainer(t, gs.image, gs.port, gs.env)
+			client := c.waitForRCON(t, address, password, gs.startup)
+
+			t.Run("auth", func(t *testing.T) {
+				other := rcon.NewClient()
+				if err := other.Connect(address); err != nil {
+					t.Fatalf("Connect failed: %v", err)
+				}
+				defer other.Disconnect()
+				if err := other.Authenticate("wrong-" + password); !errors.Is(err, rcon.ErrAuthFailed) {
+					t.Errorf("Expected ErrAuthFailed for a wrong password, got %v", err)
+				}
+			})
+
+			t.Run("detect", func(t *testing.T) {
+				detected := game.Unknown
+				for _, probe := range game.Probes {
+					output, err := client.Execute(probe)
+					if err != nil {
+						t.Fatalf("Execute(%q) failed: %v", probe, err)
+					}
+					if detected = game.Match(probe, output); detected != game.Unknown {
+						break
+					}
+				}
+				if detected != gs.game {
+					t.Errorf("Expected the server to be detected as %s, got %s", gs.game, detected)
+				}
+			})
+
+			t.Run("execute", func(t *testing.T) {
+				output, err := client.Execute(gs.parse)
+				if err != nil {
+					t.Fatalf("Execute(%q) failed: %v", gs.parse, err)
+				}
+				if _, err := game.Parse(gs.game, gs.parse, output); err != nil {
+					t.Errorf("Expected the %q output to parse, got %v: %q", gs.parse, err, output)
+				}
+			})
+
+			t.Run("multi-packet", func(t *testing.T) {
+				large := rcon.NewClient()
+				large.SetReadOptions(rcon.ReadOptions{MaxPacketSize: gs.maxPacket})
+				if err := large.Connect(address); err != nil {
+					t.Fatalf("Connect failed: %v", err)
+				}
+				defer large.Disconnect()
+				if err := large.Authenticate(password); err != nil {
+					t.Fatalf("Authenticate failed: %v", err)
+				}
+
+				output, err := large.Execute(gs.large)
+				if err != nil {
+					t.Fatalf("Execute(%q) failed: %v", gs.large, err)
+				}
+				if output == "" {
+					t.Errorf("Expected output from %q", gs.large)
+				}
+				// The packets after the first are skipped, leaving the
+				// connection in step for the next command
+				output, err = large.Execute(gs.parse)
+				if err != nil {
+					t.Fatalf("Execute(%q) after a long reply failed: %v", gs.parse, err)
+				}
+				if _, err := game.Parse(gs.game, gs.parse, output); err != nil {
+					t.Errorf("Expected the %q output after a long reply to parse, got %v: %q", gs.parse, err, output)
+				}
+			})
+
+			t.Run("session", func(t *testing.T) {
+				sm := rcon.NewSessionManager()
+				defer sm.DisconnectAll()
+				session, err := sm.CreateSession("integration", gs.name, address)
+				if err != nil {
+					t.Fatalf("CreateSession failed: %v", err)
+				}
+				session.SetGame(gs.game)
+				if err := session.Client.Connect(address); err != nil {
+					t.Fatalf("Connect failed: %v", err)
+				}
+				if err := session.Client.Authenticate(password); err != nil {
+					t.Fatalf("Authenticate failed: %v", err)
+				}
+				if err := session.OpenPool(2, password); err != nil {
+					t.Fatalf("OpenPool failed: %v", err)
+				}
+				for range 4 {
+					if _, _, err := session.Execute(t.Context(), gs.parse); err != nil {
+						t.Errorf("Execute(%q) on the session failed: %v", gs.parse, err)
+					}
+				}
+			})
+		})
+	}
+}
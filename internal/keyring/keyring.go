@@ -0,0 +1,55 @@
+// Package keyring stores RCON passwords in the operating system's
+// credential store: the macOS Keychain, the Windows Credential Manager, or
+// the Secret Service (GNOME Keyring, KWallet) on Linux and BSD. Entries are
+// kept under the Service name and identified by an account name.
+package keyring
+
+import (
+	"errors"
+	"fmt"
+
+	gokeyring "github.com/zalando/go-keyring"
+)
+
+// Service is the service name every entry is stored under.
+const Service = "rcon-mcp-server"
+
+// ErrNotFound is returned when the credential store has no entry for an
+// account.
+var ErrNotFound = errors.New("no keyring entry")
+
+// Get returns the password stored for account.
+func Get(account string) (string, error) {
+	password, err := gokeyring.Get(Service, account)
+	if errors.Is(err, gokeyring.ErrNotFound) {
+		return "", fmt.Errorf("%w for %s", ErrNotFound, account)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read keyring entry %s: %w", account, err)
+	}
+	return password, nil
+}
+
+// Set stores password for account, replacing any previous entry.
+func Set(account, password string) error {
+	if err := gokeyring.Set(Service, account, password); err != nil {
+		return fmt.Errorf("failed to write keyring entry %s: %w", account, err)
+	}
+	return nil
+}
+
+// Delete removes the entry for account. Removing a missing entry is not an
+// error.
+func Delete(account string) error {
+	err := gokeyring.Delete(Service, account)
+	if err != nil && !errors.Is(err, gokeyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete keyring entry %s: %w", account, err)
+	}
+	return nil
+}
+
+// MockInit replaces the credential store with an in-memory one for the rest
+// of the process, for tests.
+func MockInit() {
+	gokeyring.MockInit()
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// loggerKey is the context key of the logger carried by a context.
+type loggerKey struct{}
+
+// requestIDKey is the context key of the request ID carried by a context.
+type requestIDKey struct{}
+
+// NewContext returns a copy of ctx carrying logger, so that the code it is
+// passed to logs with the attributes of the work it is part of, such as the
+// tool call and session.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or fallback if it carries
+// none. A nil fallback stands for the default logger.
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	if fallback == nil {
+		return slog.Default()
+	}
+	return fallback
+}
+
+// RequestID returns a new random ID that tells the log messages of one
+// request apart from those of the others.
+func RequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithRequestID returns a copy of ctx carrying id, the correlation ID of
+// the request ctx belongs to, so that the audit log, event log and packet
+// traces can record it alongside the log messages.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or an empty
+// string if it carries none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
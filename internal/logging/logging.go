@@ -0,0 +1,56 @@
+// Package logging configures the process-wide structured logger from the
+// logging settings. Messages written with the standard log package are
+// routed through the same logger at info level.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+)
+
+// New creates a logger writing to w at the given level and format. Secrets
+// known to the redact package are masked in every message.
+func New(w io.Writer, settings config.Logging) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(settings.LevelName())); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", settings.Level)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch settings.FormatName() {
+	case config.LogFormatText:
+		return slog.New(redact.Default.Handler(slog.NewTextHandler(w, opts))), nil
+	case config.LogFormatJSON:
+		return slog.New(redact.Default.Handler(slog.NewJSONHandler(w, opts))), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q", settings.Format)
+}
+
+// Setup installs a logger built from settings as the default logger. It
+// writes to the configured file, or to stderr when none is set. The
+// returned closer releases the file and must be called once the logger is
+// no longer used.
+func Setup(settings config.Logging) (io.Closer, error) {
+	var w io.Writer = os.Stderr
+	var closer io.Closer = io.NopCloser(nil)
+	if settings.File != "" {
+		f, err := os.OpenFile(settings.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w, closer = f, f
+	}
+
+	logger, err := New(w, settings)
+	if err != nil {
+		closer.Close()
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	return closer, nil
+}
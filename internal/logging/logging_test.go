@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  config.Logging
+		wantErr   bool
+		wantDebug bool
+		wantJSON  bool
+	}{
+		{name: "defaults", settings: config.Logging{}},
+		{name: "debug level", settings: config.Logging{Level: "debug"}, wantDebug: true},
+		{name: "json format", settings: config.Logging{Format: "json"}, wantJSON: true},
+		{name: "unknown level", settings: config.Logging{Level: "chatty"}, wantErr: true},
+		{name: "unknown format", settings: config.Logging{Format: "xml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&buf, tt.settings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			logger.Debug("debug message")
+			logger.Info("info message", "session_id", "s1")
+			out := buf.String()
+			if got := strings.Contains(out, "debug message"); got != tt.wantDebug {
+				t.Errorf("Expected debug message logged = %v, got:\n%s", tt.wantDebug, out)
+			}
+			if !strings.Contains(out, "info message") {
+				t.Errorf("Expected info message, got:\n%s", out)
+			}
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			var record map[string]any
+			isJSON := json.Unmarshal([]byte(lines[len(lines)-1]), &record) == nil
+			if isJSON != tt.wantJSON {
+				t.Errorf("Expected JSON output = %v, got:\n%s", tt.wantJSON, out)
+			}
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	path := filepath.Join(t.TempDir(), "server.log")
+	closer, err := Setup(config.Logging{Format: "json", File: path})
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	slog.Warn("structured", "session_id", "s1")
+	log.Printf("from the log package")
+	closer.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	for _, want := range []string{`"msg":"structured"`, `"session_id":"s1"`, `"msg":"from the log package"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected log file to contain %s, got:\n%s", want, data)
+		}
+	}
+
+	if _, err := Setup(config.Logging{File: filepath.Join(t.TempDir(), "missing", "dir", "x.log")}); err == nil {
+		t.Error("Expected error for an unwritable log file")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	fallback := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if got := FromContext(context.Background(), fallback); got != fallback {
+		t.Error("Expected the fallback logger for a context without one")
+	}
+	if got := FromContext(context.Background(), nil); got != slog.Default() {
+		t.Error("Expected the default logger without a fallback")
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil)).With("tool", "rcon_execute")
+	FromContext(NewContext(context.Background(), logger), fallback).Info("hello")
+	if !strings.Contains(buf.String(), `"tool":"rcon_execute"`) {
+		t.Errorf("Expected the context's logger to be used, got:\n%s", buf.String())
+	}
+
+	if a, b := RequestID(), RequestID(); len(a) != 16 || a == b {
+		t.Errorf("Expected distinct 16 digit request IDs, got %q and %q", a, b)
+	}
+	if id := RequestIDFromContext(context.Background()); id != "" {
+		t.Errorf("Expected no request ID in a bare context, got %q", id)
+	}
+	if id := RequestIDFromContext(WithRequestID(context.Background(), "abc")); id != "abc" {
+		t.Errorf("Expected the context's request ID, got %q", id)
+	}
+}
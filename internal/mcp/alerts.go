@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+)
+
+// alertContext is the number of a session's recent commands included in
+// its alerts, to help tell what led up to them.
+const alertContext = 5
+
+// erroringSessions holds the IDs of the sessions whose error rate is at or
+// above the configured one, so that a spike is reported once rather than
+// after every command until it subsides.
+var erroringSessions = &idSet{ids: make(map[string]bool)}
+
+// checkAlerts warns when a command run on session took longer than the
+// configured threshold, and when the share of failures among the session's
+// recent commands reaches the configured rate. It is called once the
+// command has been added to the session's history.
+func checkAlerts(ctx context.Context, session *rcon.Session, command string, latency time.Duration) {
+	alerts := serverConfig.Alerts
+	if threshold := alerts.SlowThreshold(); threshold > 0 && latency > threshold {
+		recent := recentCommands(session)
+		logger(ctx).Warn("Slow RCON command", "session_id", session.ID, "command", config.CommandName(command),
+			"latency_ms", latency.Milliseconds(), "threshold_ms", threshold.Milliseconds(), "recent", recent)
+		if alerts.Notify {
+			notifyClients(clients.serverSessions(), session, logNotification("warning", "slow_command", map[string]any{
+				"session_id":   session.ID,
+				"command":      config.CommandName(command),
+				"latency_ms":   latency.Milliseconds(),
+				"threshold_ms": threshold.Milliseconds(),
+				"recent":       recent,
+			}))
+		}
+	}
+	if alerts.ErrorRate > 0 {
+		checkErrorRate(ctx, session, alerts)
+	}
+}
+
+// checkErrorRate warns when the share of failures among the recent commands
+// of session reaches the configured rate. The rate is only taken once the
+// session has run a full window of commands.
+func checkErrorRate(ctx context.Context, session *rcon.Session, alerts config.Alerts) {
+	window := session.History.Search(rcon.HistoryFilter{Limit: alerts.Window()})
+	if len(window) < alerts.Window() {
+		return
+	}
+	failed := 0
+	for _, e := range window {
+		if e.Failed() {
+			failed++
+		}
+	}
+	rate := float64(failed) / float64(len(window))
+	if rate < alerts.ErrorRate {
+		erroringSessions.remove(session.ID)
+		return
+	}
+	if !erroringSessions.add(session.ID) {
+		return
+	}
+
+	recent := recentCommands(session)
+	logger(ctx).Warn("RCON session error rate spiked", "session_id", session.ID, "failed", failed,
+		"commands", len(window), "threshold", alerts.ErrorRate, "recent", recent)
+	if alerts.Notify {
+		notifyClients(clients.serverSessions(), session, logNotification("warning", "error_rate_spike", map[string]any{
+			"session_id": session.ID,
+			"failed":     failed,
+			"commands":   len(window),
+			"error_rate": rate,
+			"threshold":  alerts.ErrorRate,
+			"recent":     recent,
+		}))
+	}
+}
+
+// recentCommands describes the latest commands run on session, newest
+// first, as the command name, its latency and the error if it failed.
+// Secrets are masked.
+func recentCommands(session *rcon.Session) []string {
+	entries := session.History.Search(rcon.HistoryFilter{Limit: alertContext})
+	recent := make([]string, 0, len(entries))
+	for _, e := range entries {
+		line := fmt.Sprintf("%s (%dms)", config.CommandName(e.Command), e.LatencyMillis)
+		if e.Failed() {
+			line += ": " + e.Error
+		}
+		recent = append(recent, redact.String(line))
+	}
+	return recent
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestAlerts_SlowCommand(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Alerts: config.Alerts{SlowCommand: "20ms", Notify: true}})
+	var out syncBuffer
+	SetLogger(slog.New(slog.NewTextHandler(&out, nil)))
+	t.Cleanup(func() { SetLogger(nil) })
+
+	address := startFakeRCONServer(t, func(command string) string {
+		if command == "save-all" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return "ok"
+	})
+	_, sessions, received := connectLoggingClients(t, 2)
+	if msg := callConnect(t, sessions[0], "slow", address); msg != "" {
+		t.Fatalf("Connect failed: %s", msg)
+	}
+	for _, command := range []string{"list", "save-all"} {
+		if _, err := sessions[0].CallTool(context.Background(), &mcp.CallToolParams{
+			Name: "rcon_execute", Arguments: map[string]any{"session_id": "slow", "command": command},
+		}); err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+	}
+
+	data := expectEvent(t, received[0], "slow_command")
+	recent, _ := data["recent"].([]any)
+	if data["command"] != "save-all" || len(recent) != 2 || !strings.HasPrefix(recent[1].(string), "list (") {
+		t.Errorf("Unexpected notification data: %v", data)
+	}
+	expectNoEvent(t, received[0])
+	expectNoEvent(t, received[1])
+	if n := strings.Count(out.String(), "Slow RCON command"); n != 1 {
+		t.Errorf("Expected one slow command warning, got %d in:\n%s", n, out.String())
+	}
+}
+
+func TestAlerts_ErrorRate(t *testing.T) {
+	resetSessionManager()
+	erroringSessions = &idSet{ids: make(map[string]bool)}
+	setServerConfig(t, &config.Config{Alerts: config.Alerts{ErrorRate: 0.5, ErrorWindow: 4}})
+	var out syncBuffer
+	SetLogger(slog.New(slog.NewTextHandler(&out, nil)))
+	t.Cleanup(func() { SetLogger(nil) })
+
+	session := connectFakeSession(t, "flaky", func(string) string { return "ok" })
+	// Commands with control characters fail without being sent.
+	run := func(commands ...string) {
+		for _, command := range commands {
+			_, _, _ = executeWithMetadata(context.Background(), session, command)
+		}
+	}
+	spikes := func() int { return strings.Count(out.String(), "RCON session error rate spiked") }
+
+	run("list", "bad\n", "list")
+	if n := spikes(); n != 0 {
+		t.Fatalf("Expected no warning before a full window, got %d", n)
+	}
+	run("bad\n")
+	if n := spikes(); n != 1 {
+		t.Fatalf("Expected a warning at half the window failing, got %d", n)
+	}
+	run("bad\n")
+	if n := spikes(); n != 1 {
+		t.Errorf("Expected a spike to be warned about once, got %d", n)
+	}
+	run("list", "list", "list", "bad\n", "bad\n")
+	if n := spikes(); n != 2 {
+		t.Errorf("Expected a new spike after the rate fell to be warned about, got %d", n)
+	}
+	if !strings.Contains(out.String(), "invalid command") {
+		t.Errorf("Expected the warning to include the recent failures, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ArkSessionParams represents parameters for the ARK tools that only need
+// a session
+type ArkSessionParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of an ARK server"`
+}
+
+// ArkBroadcastParams represents parameters for the ark_broadcast tool
+type ArkBroadcastParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of an ARK server"`
+	Message   string `json:"message" jsonschema:"Message to show every player in the middle of the screen"`
+}
+
+// ArkExitParams represents parameters for the ark_exit tool
+type ArkExitParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of an ARK server"`
+	Confirm   bool   `json:"confirm" jsonschema:"Must be true: the server shuts down and stays down until restarted from outside"`
+	Save      bool   `json:"save,omitempty" jsonschema:"Run SaveWorld first and stop if it fails (optional, recommended)"`
+}
+
+// registerArkTools registers the tools of the ark game pack, which run
+// common admin commands on ARK: Survival Evolved and Survival Ascended
+// servers and read their replies, whose padding and catch-all "no
+// response" reply confuse generic text parsing.
+func registerArkTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "ark_list_players",
+		Description: "Run ListPlayers on an ARK server and return the players online with their SteamID64 (Survival Evolved) or EOS ID (Survival Ascended)",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List ARK players",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, ArkListPlayers)
+
+	addTool(server, &mcp.Tool{
+		Name:        "ark_save_world",
+		Description: "Save the world of an ARK server to disk",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Save ARK world",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ArkSaveWorld)
+
+	addTool(server, &mcp.Tool{
+		Name:        "ark_broadcast",
+		Description: "Show a message to every player on an ARK server",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Broadcast ARK message",
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ArkBroadcast)
+
+	addTool(server, &mcp.Tool{
+		Name: "ark_exit",
+		Description: "Shut down an ARK server with DoExit, optionally saving the world first. Requires confirm: true; " +
+			"the server stays down until restarted from outside, e.g. by its process manager",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Shut down ARK server",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ArkExit)
+}
+
+// ArkListPlayers runs "ListPlayers" and returns the players online.
+func ArkListPlayers(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ArkSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, params.Arguments.SessionID, game.ARK)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, "ListPlayers", func(output string) (any, error) {
+		return game.Parse(game.ARK, "ListPlayers", output)
+	})
+}
+
+// ArkSaveWorld runs "SaveWorld".
+func ArkSaveWorld(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ArkSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	return arkChange(ctx, params.Arguments.SessionID, "SaveWorld")
+}
+
+// ArkBroadcast runs "Broadcast" with a message. ARK answers it as it
+// answers unknown commands, so success only means the server replied.
+func ArkBroadcast(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ArkBroadcastParams]) (*mcp.CallToolResultFor[any], error) {
+	message := strings.TrimSpace(params.Arguments.Message)
+	if message == "" {
+		return nil, errors.New("a message is required")
+	}
+	if !game.ValidArkText(message) {
+		return nil, errors.New("the message may not contain | or line breaks, which would run further commands")
+	}
+	return arkChange(ctx, params.Arguments.SessionID, "Broadcast "+message)
+}
+
+// ArkExit runs "DoExit", after "SaveWorld" if asked to.
+func ArkExit(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ArkExitParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if !args.Confirm {
+		return nil, errors.New("ark_exit shuts the server down until it is restarted from outside; call it again with confirm: true to proceed")
+	}
+	session, err := packSession(ctx, args.SessionID, game.ARK)
+	if err != nil {
+		return nil, err
+	}
+	if args.Save {
+		res, err := runPackCommand(ctx, session, "SaveWorld", func(output string) (any, error) {
+			return game.ParseArkChange("SaveWorld", output)
+		})
+		if err != nil || res.IsError {
+			return res, err
+		}
+	}
+
+	return runExitCommand(ctx, session, "DoExit", func(output string) (any, error) {
+		return game.ParseArkChange("DoExit", output)
+	})
+}
+
+// arkChange runs command on an ARK session and reads the server's reply as
+// a game.Change.
+func arkChange(ctx context.Context, sessionID, command string) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, sessionID, game.ARK)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParseArkChange(command, output)
+	})
+}
@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestArkTools(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	var mu sync.Mutex
+	var sent []string
+	connectFakeSession(t, "ark", func(command string) string {
+		mu.Lock()
+		sent = append(sent, command)
+		mu.Unlock()
+		switch command {
+		case "ListPlayers":
+			return "\n0. Bob, 76561197960290418 \n1. Smith, John, 76561198000000001 \n "
+		case "SaveWorld":
+			return "World Saved \n "
+		case "DoExit":
+			return "Exiting... \n "
+		}
+		return "Server received, But no response!! \n "
+	})
+	ctx := context.Background()
+	sentSince := func(n int) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), sent[n:]...)
+	}
+
+	res, err := ArkListPlayers(ctx, nil, &mcp.CallToolParamsFor[ArkSessionParams]{Arguments: ArkSessionParams{SessionID: "ark"}})
+	if err != nil || res.IsError {
+		t.Fatalf("ArkListPlayers failed: %v, %+v", err, res)
+	}
+	list := res.StructuredContent.(*ExecuteResult).Parsed.(*game.ArkPlayerList)
+	if list.Online != 2 || list.Players[1].Name != "Smith, John" || list.Players[1].ID != "76561198000000001" {
+		t.Errorf("Unexpected players: %+v", list)
+	}
+
+	res, err = ArkBroadcast(ctx, nil, &mcp.CallToolParamsFor[ArkBroadcastParams]{Arguments: ArkBroadcastParams{SessionID: "ark", Message: " Restart in 5 minutes "}})
+	if err != nil || res.IsError || sentSince(1)[0] != "Broadcast Restart in 5 minutes" {
+		t.Errorf("Expected the message to be broadcast, got %v, %+v", err, res)
+	}
+	if _, err := ArkBroadcast(ctx, nil, &mcp.CallToolParamsFor[ArkBroadcastParams]{Arguments: ArkBroadcastParams{SessionID: "ark", Message: "bye | DoExit"}}); err == nil {
+		t.Error("Expected a message chaining a command to be refused")
+	}
+
+	// Without confirm nothing is sent
+	if _, err := ArkExit(ctx, nil, &mcp.CallToolParamsFor[ArkExitParams]{Arguments: ArkExitParams{SessionID: "ark", Save: true}}); err == nil {
+		t.Error("Expected ark_exit without confirm to be refused")
+	}
+	if got := sentSince(2); len(got) != 0 {
+		t.Errorf("Expected no command to be sent without confirm, got %q", got)
+	}
+	res, err = ArkExit(ctx, nil, &mcp.CallToolParamsFor[ArkExitParams]{Arguments: ArkExitParams{SessionID: "ark", Confirm: true, Save: true}})
+	if err != nil || res.IsError {
+		t.Fatalf("ArkExit failed: %v, %+v", err, res)
+	}
+	if got := sentSince(2); len(got) != 2 || got[0] != "SaveWorld" || got[1] != "DoExit" {
+		t.Errorf("Expected SaveWorld then DoExit, got %q", got)
+	}
+}
+
+func TestArkExit_ConnectionClosed(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	// A server that exits before replying drops the connection
+	connectFakeSession(t, "ark", func(command string) string { return fakeCloseReply })
+
+	res, err := ArkExit(context.Background(), nil, &mcp.CallToolParamsFor[ArkExitParams]{Arguments: ArkExitParams{SessionID: "ark", Confirm: true}})
+	if err != nil || res.IsError {
+		t.Fatalf("Expected the dropped connection to count as exiting, got %v, %+v", err, res)
+	}
+}
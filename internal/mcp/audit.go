@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/logging"
+)
+
+// auditLog records every connect, execute and disconnect when an audit
+// file is configured, and is nil otherwise.
+var auditLog *audit.Log
+
+// openAudit opens the audit log configured by settings, replacing any log
+// opened before. The audit log is disabled when no file is set.
+func openAudit(settings config.Audit) error {
+	closeAudit()
+	if settings.File == "" {
+		return nil
+	}
+	l, err := audit.Open(settings.File, settings.MaxSize(), settings.Rotated())
+	if err != nil {
+		return err
+	}
+	auditLog = l
+	return nil
+}
+
+// closeAudit closes the audit log, if one is open.
+func closeAudit() {
+	if auditLog != nil {
+		_ = auditLog.Close()
+		auditLog = nil
+	}
+}
+
+// recordAudit writes e to the audit log, identifying the MCP client that
+// made the call in ctx and the call's request ID. A failure to write is logged but does not fail the
+// call.
+func recordAudit(ctx context.Context, e audit.Entry) {
+	if auditLog == nil {
+		return
+	}
+	if client := clientFromContext(ctx); client != nil {
+		e.Client, e.ClientID = client.Name(), client.ID()
+	}
+	e.RequestID = logging.RequestIDFromContext(ctx)
+	if err := auditLog.Write(e); err != nil {
+		logger(ctx).Error("Failed to write audit entry", "event", e.Event, "session_id", e.SessionID, "error", err)
+	}
+}
+
+// errorText returns the message of err, or an empty string if it is nil.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
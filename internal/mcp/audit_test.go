@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestAuditLog(t *testing.T) {
+	resetSessionManager()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	setServerConfig(t, &config.Config{Policies: config.Policies{Deny: []string{"stop"}}})
+	if err := openAudit(config.Audit{File: path}); err != nil {
+		t.Fatalf("openAudit failed: %v", err)
+	}
+	t.Cleanup(closeAudit)
+
+	address := startFakeRCONServer(t, func(command string) string { return "There are 0 players online" })
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	server.AddReceivingMiddleware(clients.identify)
+	registerTools(server)
+	if _, err := server.Connect(ctx, serverTransport); err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "auditor", Version: "2.1"}, nil).Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer cs.Close()
+
+	calls := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"rcon_connect", map[string]any{"session_id": "s1", "address": address, "password": "pw"}},
+		{"rcon_execute", map[string]any{"session_id": "s1", "command": "list"}},
+		{"rcon_execute", map[string]any{"session_id": "s1", "command": "stop"}},
+		{"rcon_disconnect", map[string]any{"session_id": "s1"}},
+	}
+	for _, c := range calls {
+		if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: c.tool, Arguments: c.args}); err != nil {
+			t.Fatalf("%s failed: %v", c.tool, err)
+		}
+	}
+
+	entries, err := audit.Read(path, nil)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	want := []struct {
+		event, command string
+		failed         bool
+	}{
+		{event: audit.EventConnect},
+		{event: audit.EventExecute, command: "list"},
+		{event: audit.EventExecute, command: "stop", failed: true},
+		{event: audit.EventDisconnect},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d audit entries, got %d: %+v", len(want), len(entries), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Event != w.event || e.Command != w.command || (e.Error != "") != w.failed {
+			t.Errorf("Entry %d = %+v, want %+v", i, e, w)
+		}
+		if e.SessionID != "s1" || e.Address != address || e.Client != "auditor 2.1" {
+			t.Errorf("Entry %d lacks session or client identity: %+v", i, e)
+		}
+	}
+	if entries[1].Response != "There are 0 players online" {
+		t.Errorf("Expected the response to be recorded, got %q", entries[1].Response)
+	}
+}
@@ -0,0 +1,328 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxBattlEyeRestartMinutes bounds the countdown of battleye_restart.
+const maxBattlEyeRestartMinutes = 180
+
+// battleyeRestartWarnings lists the minutes before a scheduled restart at
+// which the players are warned, besides when it is scheduled.
+var battleyeRestartWarnings = []int{60, 30, 15, 10, 5, 1}
+
+// BattlEyeSessionParams represents parameters for the BattlEye tools that
+// only need a session
+type BattlEyeSessionParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a DayZ or Arma server connected over BattlEye RCon"`
+}
+
+// BattlEyeSayParams represents parameters for the battleye_say tool
+type BattlEyeSayParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a DayZ or Arma server connected over BattlEye RCon"`
+	Message   string `json:"message" jsonschema:"Message to show; may not contain line breaks"`
+	Player    *int   `json:"player,omitempty" jsonschema:"Number of the player to show it to, as listed by battleye_players (default: every player)"`
+}
+
+// BattlEyeRestartParams represents parameters for the battleye_restart tool
+type BattlEyeRestartParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a DayZ or Arma server connected over BattlEye RCon"`
+	Minutes   int    `json:"minutes" jsonschema:"Countdown before the server shuts down, 0 to 180 minutes; 0 shuts it down now"`
+	Message   string `json:"message,omitempty" jsonschema:"Message shown to every player with each warning (optional)"`
+	Confirm   bool   `json:"confirm" jsonschema:"Must be true unless cancelling: the server shuts down and comes back only if its process manager restarts it"`
+	Cancel    bool   `json:"cancel,omitempty" jsonschema:"Cancel the restart scheduled on the session instead (optional)"`
+}
+
+// BattlEyeRestart is the result of battleye_restart for a restart it
+// scheduled or cancelled.
+type BattlEyeRestart struct {
+	SessionID string      `json:"session_id"`
+	RestartAt time.Time   `json:"restart_at,omitzero"` // When the server shuts down
+	Warnings  []time.Time `json:"warnings,omitempty"`  // When the players are warned
+	Cancelled bool        `json:"cancelled,omitempty"`
+}
+
+// battleyeRestarts holds the restarts scheduled on each session, by
+// session ID.
+var battleyeRestarts = &restartSchedule{pending: make(map[string]*scheduledRestart)}
+
+// restartSchedule keeps the restarts battleye_restart scheduled.
+type restartSchedule struct {
+	mu      sync.Mutex
+	pending map[string]*scheduledRestart
+}
+
+// scheduledRestart is a restart waiting for its countdown.
+type scheduledRestart struct {
+	at     time.Time
+	cancel context.CancelFunc
+}
+
+// registerBattlEyeTools registers the tools of the battleye game pack,
+// which run the everyday admin tasks of DayZ and Arma servers over
+// BattlEye RCon.
+func registerBattlEyeTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "battleye_players",
+		Description: "Run players on a DayZ or Arma server over BattlEye RCon and return the players online with their number, address, ping, BE GUID and lobby state",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List BattlEye players",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, BattlEyePlayers)
+
+	addTool(server, &mcp.Tool{
+		Name:        "battleye_say",
+		Description: "Show a message to every player on a DayZ or Arma server, or to one player by number, with BattlEye's say",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "BattlEye message",
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, BattlEyeSay)
+
+	addTool(server, &mcp.Tool{
+		Name:        "battleye_reload_bans",
+		Description: "Reload the bans.txt of a DayZ or Arma server with BattlEye's loadBans, after it was edited outside the server",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Reload BattlEye bans",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, BattlEyeReloadBans)
+
+	addTool(server, &mcp.Tool{
+		Name: "battleye_restart",
+		Description: "Schedule a DayZ or Arma server to shut down with #shutdown after a countdown, warning the players 60, 30, 15, 10, 5 and 1 minutes before, " +
+			"or cancel the scheduled restart. Requires confirm: true; the server comes back only if its process manager restarts it",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Restart BattlEye server",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, BattlEyeRestartServer)
+}
+
+// BattlEyePlayers runs "players" and returns the players online.
+func BattlEyePlayers(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[BattlEyeSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := battleyeSession(ctx, params.Arguments.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, "players", func(output string) (any, error) {
+		return game.Parse(game.DayZ, "players", output)
+	})
+}
+
+// BattlEyeSay runs "say" with a player number, -1 for every player, and a
+// message.
+func BattlEyeSay(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[BattlEyeSayParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	message, err := game.BattlEyeText(args.Message)
+	if err != nil {
+		return nil, err
+	}
+	if message == "" {
+		return nil, errors.New("a message is required")
+	}
+	player := -1
+	if args.Player != nil {
+		if *args.Player < 0 {
+			return nil, fmt.Errorf("invalid player number %d", *args.Player)
+		}
+		player = *args.Player
+	}
+	session, err := battleyeSession(ctx, args.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	command := "say " + strconv.Itoa(player) + " " + message
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParseBattlEyeChange(command, output)
+	})
+}
+
+// BattlEyeReloadBans runs "loadBans".
+func BattlEyeReloadBans(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[BattlEyeSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := battleyeSession(ctx, params.Arguments.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, "loadBans", func(output string) (any, error) {
+		return game.ParseBattlEyeChange("loadBans", output)
+	})
+}
+
+// BattlEyeRestartServer runs "#shutdown" at once, or schedules it after a
+// countdown in which the players are warned with "say -1", or cancels the
+// restart scheduled on the session. A scheduled restart runs its commands
+// as the tool call did, through the same policies, and is dropped if the
+// session is closed before it is due.
+func BattlEyeRestartServer(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[BattlEyeRestartParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.Cancel {
+		session, err := battleyeSession(ctx, args.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if !battleyeRestarts.cancel(session.ID) {
+			return nil, fmt.Errorf("no restart is scheduled on session %s", session.ID)
+		}
+		return statusResult(&BattlEyeRestart{SessionID: session.ID, Cancelled: true})
+	}
+	if !args.Confirm {
+		return nil, errors.New("battleye_restart shuts the server down, to come back only if its process manager restarts it; call it again with confirm: true to proceed")
+	}
+	if args.Minutes < 0 || args.Minutes > maxBattlEyeRestartMinutes {
+		return nil, fmt.Errorf("minutes must be between 0 and %d", maxBattlEyeRestartMinutes)
+	}
+	message, err := game.BattlEyeText(args.Message)
+	if err != nil {
+		return nil, err
+	}
+	session, err := battleyeSession(ctx, args.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if args.Minutes == 0 {
+		return runExitCommand(ctx, session, "#shutdown", func(output string) (any, error) {
+			return game.ParseBattlEyeChange("#shutdown", output)
+		})
+	}
+
+	restart, err := battleyeRestarts.schedule(ctx, session, time.Duration(args.Minutes)*time.Minute, message)
+	if err != nil {
+		return nil, err
+	}
+	return statusResult(restart)
+}
+
+// schedule starts the countdown of a restart of session in d, warning the
+// players with message at the minutes in battleyeRestartWarnings and when
+// it starts. Only one restart may be scheduled per session.
+func (s *restartSchedule) schedule(ctx context.Context, session *rcon.Session, d time.Duration, message string) (*BattlEyeRestart, error) {
+	now := time.Now()
+	restart := &BattlEyeRestart{SessionID: session.ID, RestartAt: now.Add(d), Warnings: []time.Time{now}}
+	for _, m := range battleyeRestartWarnings {
+		if before := time.Duration(m) * time.Minute; before < d {
+			restart.Warnings = append(restart.Warnings, restart.RestartAt.Add(-before))
+		}
+	}
+
+	// The countdown outlives the tool call, but keeps its caller and logger
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s.mu.Lock()
+	if pending, ok := s.pending[session.ID]; ok {
+		s.mu.Unlock()
+		cancel()
+		return nil, fmt.Errorf("a restart is already scheduled on session %s for %s; cancel it first", session.ID, pending.at.Format(time.RFC3339))
+	}
+	s.pending[session.ID] = &scheduledRestart{at: restart.RestartAt, cancel: cancel}
+	s.mu.Unlock()
+
+	go s.run(ctx, session, restart, message)
+	return restart, nil
+}
+
+// run warns the players of a scheduled restart as it counts down and
+// shuts the server down when it is due, unless ctx is cancelled or the
+// session closed first.
+func (s *restartSchedule) run(ctx context.Context, session *rcon.Session, restart *BattlEyeRestart, message string) {
+	defer s.finish(ctx, session.ID)
+	for _, at := range restart.Warnings {
+		if !waitUntil(ctx, at) || !sessionOpen(session) {
+			return
+		}
+		minutes := int(time.Until(restart.RestartAt).Round(time.Minute) / time.Minute)
+		warning := fmt.Sprintf("Server restart in %d minute(s)", minutes)
+		if message != "" {
+			warning = message + " - " + warning
+		}
+		if _, _, err := executeWithMetadata(ctx, session, "say -1 "+warning); err != nil {
+			logger(ctx).Warn("Failed to warn of a scheduled restart", "session_id", session.ID, "error", err)
+		}
+	}
+	if !waitUntil(ctx, restart.RestartAt) || !sessionOpen(session) {
+		return
+	}
+	if _, _, err := executeWithMetadata(ctx, session, "#shutdown"); err != nil && session.Client.IsConnected() {
+		logger(ctx).Warn("Scheduled restart failed", "session_id", session.ID, "error", err)
+		return
+	}
+	logger(ctx).Info("Scheduled restart ran", "session_id", session.ID)
+}
+
+// cancel cancels the restart scheduled on a session and reports whether
+// there was one.
+func (s *restartSchedule) cancel(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending, ok := s.pending[id]
+	if ok {
+		pending.cancel()
+		delete(s.pending, id)
+	}
+	return ok
+}
+
+// finish drops the restart of a session whose countdown, run with ctx,
+// ended, unless another restart replaced it.
+func (s *restartSchedule) finish(ctx context.Context, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pending, ok := s.pending[id]; ok && ctx.Err() == nil {
+		pending.cancel()
+		delete(s.pending, id)
+	}
+}
+
+// sessionOpen reports whether session is still open, so that a countdown does
+// not go on against a session that was closed and perhaps replaced.
+func sessionOpen(session *rcon.Session) bool {
+	current, err := sessionManager.GetSession(session.ID)
+	return err == nil && current == session
+}
+
+// waitUntil waits until t and reports whether it got there before ctx was
+// done.
+func waitUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// battleyeSession returns the session a battleye pack tool was called on.
+// DayZ and Arma both speak BattlEye RCon, so sessions of either, or of an
+// unknown game, are accepted.
+func battleyeSession(ctx context.Context, id string) (*rcon.Session, error) {
+	session, err := getSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch t := session.Game(); t {
+	case game.Unknown, game.DayZ, game.Arma3:
+	default:
+		return nil, fmt.Errorf("session %s is a %s server; this tool only works on DayZ and Arma servers", id, t)
+	}
+	if session.Client.Protocol() != rcon.ProtocolBattlEye {
+		return nil, fmt.Errorf("session %s is not connected over BattlEye RCon, which this tool needs; connect with protocol battleye", session.ID)
+	}
+	return session, nil
+}
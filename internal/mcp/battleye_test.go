@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"hash/crc32"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// battleyeDatagram returns a BattlEye RCon datagram of type t with payload.
+func battleyeDatagram(t byte, payload string) []byte {
+	body := append([]byte{0xFF, t}, payload...)
+	sum := crc32.ChecksumIEEE(body)
+	return append([]byte{'B', 'E', byte(sum), byte(sum >> 8), byte(sum >> 16), byte(sum >> 24)}, body...)
+}
+
+// connectFakeBattlEyeSession connects a session over BattlEye RCon to a
+// fake server on a local UDP port, which answers commands with respond.
+func connectFakeBattlEyeSession(t *testing.T, id string, respond func(command string) string) *rcon.Session {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 64*1024)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < 9 {
+				continue
+			}
+			switch buf[7] {
+			case 0x00:
+				_, _ = conn.WriteTo(battleyeDatagram(0x00, "\x01"), addr)
+			case 0x01:
+				seq, command := buf[8], string(buf[9:n])
+				reply := ""
+				if command != "" {
+					reply = respond(command)
+				}
+				_, _ = conn.WriteTo(battleyeDatagram(0x01, string(seq)+reply), addr)
+			}
+		}
+	}()
+
+	address := conn.LocalAddr().String()
+	session, err := sessionManager.CreateSession(id, "Fake", address)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	session.Client.SetProtocol(rcon.ProtocolBattlEye)
+	if err := session.Client.Connect(address); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if err := session.Client.Authenticate("password"); err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	t.Cleanup(func() { session.Client.Disconnect() })
+	return session
+}
+
+func TestBattlEyeTools(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	var mu sync.Mutex
+	var sent []string
+	session := connectFakeBattlEyeSession(t, "dayz", func(command string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, command)
+		if command == "players" {
+			return "Players on server:\n[#] [IP Address]:[Port] [Ping] [GUID] [Name]\n---\n" +
+				"0   203.0.113.5:2304   47   0123456789abcdef0123456789abcdef(OK) Bob\n(1 players in total)"
+		}
+		return ""
+	})
+	session.SetGame(game.DayZ)
+	t.Cleanup(func() { battleyeRestarts.cancel("dayz") })
+	ctx := context.Background()
+	lastSent := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent[len(sent)-1]
+	}
+
+	res, err := BattlEyePlayers(ctx, nil, &mcp.CallToolParamsFor[BattlEyeSessionParams]{Arguments: BattlEyeSessionParams{SessionID: "dayz"}})
+	if err != nil || res.IsError {
+		t.Fatalf("BattlEyePlayers failed: %v, %+v", err, res)
+	}
+	if list := res.StructuredContent.(*ExecuteResult).Parsed.(*game.BattlEyePlayerList); list.Online != 1 || list.Players[0].Name != "Bob" {
+		t.Errorf("Unexpected players: %+v", list)
+	}
+
+	player := 0
+	res, err = BattlEyeSay(ctx, nil, &mcp.CallToolParamsFor[BattlEyeSayParams]{Arguments: BattlEyeSayParams{SessionID: "dayz", Message: " hello ", Player: &player}})
+	if err != nil || res.IsError || lastSent() != "say 0 hello" {
+		t.Errorf("Expected the message to be sent to player 0, got %v, %+v after %q", err, res, lastSent())
+	}
+	res, err = BattlEyeReloadBans(ctx, nil, &mcp.CallToolParamsFor[BattlEyeSessionParams]{Arguments: BattlEyeSessionParams{SessionID: "dayz"}})
+	if err != nil || res.IsError || lastSent() != "loadBans" {
+		t.Errorf("Expected the bans to be reloaded, got %v, %+v after %q", err, res, lastSent())
+	}
+
+	// A restart warns the players at once and can be cancelled
+	restart := func(args BattlEyeRestartParams) (*mcp.CallToolResultFor[any], error) {
+		args.SessionID = "dayz"
+		return BattlEyeRestartServer(ctx, nil, &mcp.CallToolParamsFor[BattlEyeRestartParams]{Arguments: args})
+	}
+	if _, err := restart(BattlEyeRestartParams{Minutes: 10}); err == nil {
+		t.Error("Expected a restart without confirm to be refused")
+	}
+	res, err = restart(BattlEyeRestartParams{Minutes: 10, Message: "Storm coming", Confirm: true})
+	if err != nil {
+		t.Fatalf("Failed to schedule the restart: %v", err)
+	}
+	if scheduled := res.StructuredContent.(*BattlEyeRestart); len(scheduled.Warnings) != 3 {
+		t.Errorf("Expected warnings now, 5 and 1 minutes before, got %+v", scheduled.Warnings)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.HasPrefix(lastSent(), "say -1 ") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := lastSent(); got != "say -1 Storm coming - Server restart in 10 minute(s)" {
+		t.Errorf("Expected the players to be warned, got %q", got)
+	}
+	if _, err := restart(BattlEyeRestartParams{Minutes: 5, Confirm: true}); err == nil {
+		t.Error("Expected a second restart to be refused")
+	}
+	if res, err := restart(BattlEyeRestartParams{Cancel: true}); err != nil || !res.StructuredContent.(*BattlEyeRestart).Cancelled {
+		t.Errorf("Expected the restart to be cancelled, got %v", err)
+	}
+	if _, err := restart(BattlEyeRestartParams{Cancel: true}); err == nil {
+		t.Error("Expected nothing left to cancel")
+	}
+
+	res, err = restart(BattlEyeRestartParams{Confirm: true})
+	if err != nil || res.IsError || lastSent() != "#shutdown" {
+		t.Errorf("Expected the server to be shut down at once, got %v, %+v after %q", err, res, lastSent())
+	}
+}
+
+func TestBattlEyeSession(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	connectFakeSession(t, "rcon", func(string) string { return "" })
+	if _, err := BattlEyePlayers(context.Background(), nil, &mcp.CallToolParamsFor[BattlEyeSessionParams]{Arguments: BattlEyeSessionParams{SessionID: "rcon"}}); err == nil {
+		t.Error("Expected a session over Source RCON to be refused")
+	}
+}
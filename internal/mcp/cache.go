@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+)
+
+// cacheKey identifies a cached response: the command as sent, on one
+// session. Keying by the session rather than its ID means a session
+// replaced under the same ID never sees the responses of the old one.
+type cacheKey struct {
+	session *rcon.Session
+	command string
+}
+
+// cacheEntry is a cached response, as the server sent it.
+type cacheEntry struct {
+	raw     string
+	stored  time.Time
+	expires time.Time
+}
+
+// responseCache keeps the responses to read-only commands of sessions whose
+// profile sets a cache_ttl, so that repeating a query within the TTL does
+// not reach the server again.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+	now     func() time.Time
+}
+
+// responses caches read-only responses for the lifetime of the server
+// process.
+var responses = newResponseCache()
+
+// newResponseCache creates an empty response cache.
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[cacheKey]cacheEntry), now: time.Now}
+}
+
+// get returns the cached response to command on session and how old it is,
+// if one has not expired yet.
+func (c *responseCache) get(session *rcon.Session, command string) (string, time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := cacheKey{session, strings.TrimSpace(command)}
+	e, ok := c.entries[key]
+	if !ok {
+		return "", 0, false
+	}
+	now := c.now()
+	if !now.Before(e.expires) {
+		delete(c.entries, key)
+		return "", 0, false
+	}
+	return e.raw, now.Sub(e.stored), true
+}
+
+// put caches raw as the response to command on session for ttl, dropping
+// the entries that have expired.
+func (c *responseCache) put(session *rcon.Session, command, raw string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[cacheKey{session, strings.TrimSpace(command)}] = cacheEntry{raw: raw, stored: now, expires: now.Add(ttl)}
+}
+
+// forget drops the cached responses of session, whose state a command may
+// have changed.
+func (c *responseCache) forget(session *rcon.Session) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.session == session {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// sessionCacheTTL returns how long the responses to read-only commands on
+// session are reused: the cache_ttl of its profile, or 0 for not at all.
+func sessionCacheTTL(session *rcon.Session) time.Duration {
+	name := session.Profile()
+	if name == "" {
+		return 0
+	}
+	profile, err := serverConfig.Profile(name)
+	if err != nil {
+		return 0
+	}
+	ttl, _ := config.ParseCacheTTL(profile.CacheTTL)
+	return ttl
+}
@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+)
+
+func TestExecuteWithMetadata_Cache(t *testing.T) {
+	resetSessionManager()
+	previous := responses
+	responses = newResponseCache()
+	t.Cleanup(func() { responses = previous })
+	now := time.Now()
+	responses.now = func() time.Time { return now }
+	setServerConfig(t, &config.Config{
+		Profiles: []*config.Profile{{Name: "prod", Address: "localhost:25575", CacheTTL: "5s"}},
+	})
+
+	sent := 0
+	respond := func(command string) string {
+		sent++
+		return "players online: " + string(rune('0'+sent))
+	}
+	cached := connectFakeSession(t, "cached", respond)
+	cached.SetProfile("prod")
+	uncached := connectFakeSession(t, "uncached", respond)
+
+	run := func(command string) (string, ExecutionMetadata) {
+		t.Helper()
+		output, meta, err := executeWithMetadata(context.Background(), cached, command)
+		if err != nil {
+			t.Fatalf("Executing %q failed: %v", command, err)
+		}
+		return output, meta
+	}
+
+	first, meta := run("list")
+	if meta.Cached || sent != 1 {
+		t.Fatalf("Expected the first query to reach the server, got %+v after %d commands", meta, sent)
+	}
+	now = now.Add(2 * time.Second)
+	if again, meta := run(" list "); again != first || !meta.Cached || meta.CacheAgeMillis != 2000 || sent != 1 {
+		t.Errorf("Expected the repeated query to be served from the cache, got %q, %+v after %d commands", again, meta, sent)
+	}
+	if n := cached.History.Len(); n != 1 {
+		t.Errorf("Expected only the command sent in the history, got %d entries", n)
+	}
+
+	// Queries of other sessions, and after the TTL, reach the server.
+	if _, meta, _ := executeWithMetadata(context.Background(), uncached, "list"); meta.Cached {
+		t.Error("Expected no cache for a session without a cache_ttl")
+	}
+	now = now.Add(5 * time.Second)
+	if _, meta := run("list"); meta.Cached {
+		t.Error("Expected an expired response to be sent again")
+	}
+
+	// A command that may change the server's state clears the session's cache.
+	run("kick steve")
+	if _, meta := run("list"); meta.Cached {
+		t.Error("Expected the cache to be cleared by a command that is not read-only")
+	}
+}
@@ -0,0 +1,310 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// clientState is the state the server keeps for one connected MCP client.
+// Over network transports every client has its own, while the RCON
+// sessions are shared between them.
+type clientState struct {
+	ss        *mcp.ServerSession // The client's MCP session, to send it notifications
+	id        *sessionIDSlot     // Transport session ID, empty over stdio and SSE
+	key       string             // Identifies the client within the process, as the owner of its sessions
+	Connected time.Time          // When the client finished initializing
+	ctx       context.Context    // Cancelled when the client disconnects
+	cancel    context.CancelFunc // Cancels ctx
+	calls     atomic.Int64       // Tool calls made by the client
+	lastCall  atomic.Int64       // Unix nanoseconds of the latest tool call
+	name      atomic.Value       // Name and version the client reported, a string
+
+	mu       sync.Mutex
+	sessions map[string]*rcon.Session // RCON sessions the client opened, by ID
+}
+
+// ID returns the client's transport session ID, or an empty string over
+// stdio and SSE, which give clients none.
+func (c *clientState) ID() string {
+	return c.id.get()
+}
+
+// Calls returns the number of tool calls the client has made.
+func (c *clientState) Calls() int64 {
+	return c.calls.Load()
+}
+
+// Name returns the name and version the client reported when it
+// initialized, or an empty string if it has not.
+func (c *clientState) Name() string {
+	name, _ := c.name.Load().(string)
+	return name
+}
+
+// LastActive returns when the client last called a tool, or when it
+// connected if it has not called any.
+func (c *clientState) LastActive() time.Time {
+	if ns := c.lastCall.Load(); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return c.Connected
+}
+
+// openSessions returns the number of RCON sessions the client has open.
+// Sessions closed by other means since are pruned first.
+func (c *clientState) openSessions() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, session := range c.sessions {
+		if current, err := sessionManager.GetSession(id); err != nil || current != session {
+			delete(c.sessions, id)
+		}
+	}
+	return len(c.sessions)
+}
+
+// clientRegistry tracks the connected MCP clients.
+type clientRegistry struct {
+	mu       sync.Mutex
+	clients  map[*mcp.ServerSession]*clientState
+	teardown sync.WaitGroup // Teardowns of connected clients, which end once they disconnect
+}
+
+// clients is the registry of the MCP clients connected to the server.
+var clients = newClientRegistry()
+
+// clientKeys numbers the clients for their keys, since not every transport
+// gives clients an ID.
+var clientKeys atomic.Int64
+
+// newClientRegistry creates an empty registry.
+func newClientRegistry() *clientRegistry {
+	return &clientRegistry{clients: make(map[*mcp.ServerSession]*clientState)}
+}
+
+// connected registers a client once it has initialized, and tears its
+// state down again when its connection closes. A client beyond the
+// configured limit of clients is disconnected right away.
+func (r *clientRegistry) connected(ctx context.Context, ss *mcp.ServerSession, _ *mcp.InitializedParams) {
+	state := r.get(ctx, ss)
+	if limit := serverConfig.Limits.MaxClients; limit > 0 && r.count() > limit {
+		logger(ctx).Warn("Rejecting MCP client over the client limit", "client_id", state.ID(), "max_clients", limit)
+		r.disconnected(ss)
+		// Close waits for handlers in progress, including this one.
+		go ss.Close()
+		return
+	}
+	logger(ctx).Info("MCP client connected", "client_id", state.ID())
+
+	r.teardown.Add(1)
+	go func() {
+		defer r.teardown.Done()
+		_ = ss.Wait()
+		r.disconnected(ss)
+		logger(ctx).Info("MCP client disconnected", "client_id", state.ID(),
+			"calls", state.Calls(), "duration", time.Since(state.Connected).Round(time.Second))
+	}()
+}
+
+// disconnected forgets a client, cancels its context and closes the RCON
+// sessions it opened that are still open.
+func (r *clientRegistry) disconnected(ss *mcp.ServerSession) {
+	r.mu.Lock()
+	state, ok := r.clients[ss]
+	delete(r.clients, ss)
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	state.cancel()
+	state.openSessions()
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	for id, session := range state.sessions {
+		if err := sessionManager.RemoveSession(id); err == nil {
+			logger(context.Background()).Info("Closed RCON session of disconnected client", "client_id", state.ID(), "session_id", id)
+			recordAudit(context.WithValue(context.Background(), clientKey{}, state),
+				audit.Entry{Event: audit.EventDisconnect, SessionID: id})
+			recordEvent(context.Background(), eventDisconnected, session, "client disconnected")
+		}
+	}
+}
+
+// wait blocks until the teardown of every client that connected has
+// finished, which it does once the client has disconnected. Teardowns use
+// the process-wide session manager and event log, so whoever replaces them
+// waits first.
+func (r *clientRegistry) wait() {
+	r.teardown.Wait()
+}
+
+// get returns the state of a client, registering it if it is not known yet.
+// ctx is that of a request from the client, from which the transport
+// session ID is taken once known.
+func (r *clientRegistry) get(ctx context.Context, ss *mcp.ServerSession) *clientState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.clients[ss]
+	if !ok {
+		id := sessionIDFromContext(ctx)
+		ctx, cancel := context.WithCancel(context.Background())
+		state = &clientState{
+			ss:        ss,
+			id:        id,
+			key:       strconv.FormatInt(clientKeys.Add(1), 10),
+			Connected: time.Now(),
+			ctx:       ctx,
+			cancel:    cancel,
+			sessions:  make(map[string]*rcon.Session),
+		}
+		r.clients[ss] = state
+	}
+	return state
+}
+
+// lookup returns the state of a client, or nil if it is not registered.
+func (r *clientRegistry) lookup(ss *mcp.ServerSession) *clientState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.clients[ss]
+}
+
+// serverSessions returns the MCP sessions of the registered clients.
+func (r *clientRegistry) serverSessions() iter.Seq[*mcp.ServerSession] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]*mcp.ServerSession, 0, len(r.clients))
+	for ss := range r.clients {
+		sessions = append(sessions, ss)
+	}
+	return slices.Values(sessions)
+}
+
+// clientKey is the context key under which called stores the state of the
+// client making a tool call.
+type clientKey struct{}
+
+// clientFromContext returns the client making the tool call ctx belongs
+// to, or nil outside a tool call.
+func clientFromContext(ctx context.Context) *clientState {
+	state, _ := ctx.Value(clientKey{}).(*clientState)
+	return state
+}
+
+// identify is a receiving middleware that records the name and version a
+// client reports in its initialize request.
+func (r *clientRegistry) identify(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if p, ok := params.(*mcp.InitializeParams); ok && p.ClientInfo != nil {
+			name := p.ClientInfo.Name
+			if p.ClientInfo.Version != "" {
+				name += " " + p.ClientInfo.Version
+			}
+			r.get(ctx, ss).name.Store(name)
+		}
+		return next(ctx, ss, method, params)
+	}
+}
+
+// called records a tool call made by a client and returns a context for
+// it that is also cancelled when the client disconnects. Calls without a
+// client, as in tests that invoke handlers directly, are not recorded.
+func (r *clientRegistry) called(ctx context.Context, ss *mcp.ServerSession) (context.Context, context.CancelFunc) {
+	if ss == nil {
+		return ctx, func() {}
+	}
+	state := r.get(ctx, ss)
+	state.calls.Add(1)
+	state.lastCall.Store(time.Now().UnixNano())
+
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, clientKey{}, state))
+	stop := context.AfterFunc(state.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// checkSessionLimit returns an error if a client already has as many RCON
+// sessions open as the configured per-client limit allows.
+func (r *clientRegistry) checkSessionLimit(ctx context.Context, ss *mcp.ServerSession) error {
+	limit := serverConfig.Limits.MaxClientSessions
+	if ss == nil || limit <= 0 {
+		return nil
+	}
+	if r.get(ctx, ss).openSessions() >= limit {
+		return fmt.Errorf("per-client session limit of %d reached; disconnect a session first", limit)
+	}
+	return nil
+}
+
+// ownSession records that a client opened session, so that it is closed
+// when the client disconnects, and binds the session to the client so that
+// other clients cannot use it unless it is shared.
+func (r *clientRegistry) ownSession(ctx context.Context, ss *mcp.ServerSession, session *rcon.Session, shared bool) {
+	if ss == nil {
+		return
+	}
+	state := r.get(ctx, ss)
+	session.SetOwner(state.key, shared)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.sessions[session.ID] = session
+}
+
+// mayUse reports whether the client with the given key may use session.
+// Sessions without an owner, such as those preloaded at startup, and
+// shared sessions may be used by every client.
+func mayUse(key string, session *rcon.Session) bool {
+	owner, shared := session.Owner()
+	return owner == "" || shared || owner == key
+}
+
+// callerKey returns the key of the client making the tool call ctx belongs
+// to, or an empty string outside a tool call.
+func callerKey(ctx context.Context) string {
+	if state := clientFromContext(ctx); state != nil {
+		return state.key
+	}
+	return ""
+}
+
+// getSession returns the session with the given ID, provided the client
+// making the tool call ctx belongs to may use it.
+func getSession(ctx context.Context, id string) (*rcon.Session, error) {
+	session, err := sessionManager.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	if !mayUse(callerKey(ctx), session) {
+		return nil, fmt.Errorf("session %s belongs to another MCP client; "+
+			"only sessions connected with shared set to true can be used by other clients", id)
+	}
+	return session, nil
+}
+
+// visibleSessions returns the sessions the client making the tool call ctx
+// belongs to may use.
+func visibleSessions(ctx context.Context) []*rcon.Session {
+	return slices.DeleteFunc(sessionManager.ListSessions(), func(s *rcon.Session) bool {
+		return !mayUse(callerKey(ctx), s)
+	})
+}
+
+// count returns the number of connected clients.
+func (r *clientRegistry) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.clients)
+}
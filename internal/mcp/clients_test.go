@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectClients starts an MCP server that tracks its clients and connects
+// n clients to it over in-memory transports.
+func connectClients(t *testing.T, n int) []*mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	clients = newClientRegistry()
+	// Cleanups run last to first: the clients close before their teardown is awaited.
+	t.Cleanup(clients.wait)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"},
+		&mcp.ServerOptions{InitializedHandler: clients.connected})
+	registerTools(server)
+
+	var sessions []*mcp.ClientSession
+	for i := 0; i < n; i++ {
+		clientTransport, serverTransport := mcp.NewInMemoryTransports()
+		if _, err := server.Connect(ctx, serverTransport); err != nil {
+			t.Fatalf("Failed to connect server: %v", err)
+		}
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil)
+		cs, err := client.Connect(ctx, clientTransport)
+		if err != nil {
+			t.Fatalf("Failed to connect client: %v", err)
+		}
+		t.Cleanup(func() { cs.Close() })
+		sessions = append(sessions, cs)
+	}
+	return sessions
+}
+
+// callConnect calls rcon_connect as a client and returns the tool error text, if any.
+func callConnect(t *testing.T, cs *mcp.ClientSession, id, address string) string {
+	t.Helper()
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "rcon_connect",
+		Arguments: map[string]any{"session_id": id, "address": address, "password": "secret"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if res.IsError {
+		return res.Content[0].(*mcp.TextContent).Text
+	}
+	return ""
+}
+
+func TestClients_SessionTeardown(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	address := startFakeRCONServer(t, func(string) string { return "ok" })
+	sessions := connectClients(t, 2)
+
+	if msg := callConnect(t, sessions[0], "first", address); msg != "" {
+		t.Fatalf("Connect failed: %s", msg)
+	}
+	if msg := callConnect(t, sessions[1], "second", address); msg != "" {
+		t.Fatalf("Connect failed: %s", msg)
+	}
+	waitFor(t, func() bool { return clients.count() == 2 })
+
+	sessions[0].Close()
+	waitFor(t, func() bool { return clients.count() == 1 })
+	if _, err := sessionManager.GetSession("first"); err == nil {
+		t.Error("Expected the disconnected client's session to be closed")
+	}
+	if _, err := sessionManager.GetSession("second"); err != nil {
+		t.Errorf("Expected the other client's session to stay open: %v", err)
+	}
+}
+
+func TestClients_Limits(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Limits: config.Limits{MaxClients: 1, MaxClientSessions: 1}})
+	address := startFakeRCONServer(t, func(string) string { return "ok" })
+	sessions := connectClients(t, 2)
+
+	// The second client is over the client limit and gets disconnected.
+	waitFor(t, func() bool {
+		_, err := sessions[1].ListTools(context.Background(), nil)
+		return err != nil
+	})
+	if n := clients.count(); n != 1 {
+		t.Errorf("Expected one connected client, got %d", n)
+	}
+
+	if msg := callConnect(t, sessions[0], "one", address); msg != "" {
+		t.Fatalf("Connect failed: %s", msg)
+	}
+	if msg := callConnect(t, sessions[0], "two", address); !strings.Contains(msg, "per-client session limit of 1") {
+		t.Errorf("Expected the per-client session limit, got %q", msg)
+	}
+
+	// Closing a session frees its slot.
+	if _, err := sessions[0].CallTool(context.Background(), &mcp.CallToolParams{
+		Name: "rcon_disconnect", Arguments: map[string]any{"session_id": "one"},
+	}); err != nil {
+		t.Fatalf("Disconnect failed: %v", err)
+	}
+	if msg := callConnect(t, sessions[0], "two", address); msg != "" {
+		t.Errorf("Expected a connect after disconnecting to succeed, got %q", msg)
+	}
+}
+
+func TestClients_SessionOwnership(t *testing.T) {
+	resetSessionManager()
+	groupManager = rcon.NewGroupManager()
+	setServerConfig(t, &config.Config{})
+	address := startFakeRCONServer(t, func(string) string { return "ok" })
+	sessions := connectClients(t, 2)
+	owner, other := sessions[0], sessions[1]
+
+	call := func(cs *mcp.ClientSession, tool string, args map[string]any) string {
+		t.Helper()
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: tool, Arguments: args})
+		if err != nil {
+			t.Fatalf("%s failed: %v", tool, err)
+		}
+		text := res.Content[0].(*mcp.TextContent).Text
+		if res.IsError {
+			return "error: " + text
+		}
+		return text
+	}
+
+	if msg := callConnect(t, owner, "private", address); msg != "" {
+		t.Fatalf("Connect failed: %s", msg)
+	}
+	if msg := call(owner, "rcon_connect", map[string]any{
+		"session_id": "public", "address": address, "password": "secret", "shared": true,
+	}); strings.HasPrefix(msg, "error") {
+		t.Fatalf("Shared connect failed: %s", msg)
+	}
+
+	if msg := call(owner, "rcon_execute", map[string]any{"session_id": "private", "command": "list"}); strings.HasPrefix(msg, "error") {
+		t.Errorf("Expected the owner to use its session, got %q", msg)
+	}
+	refused := map[string]map[string]any{
+		"rcon_execute":    {"session_id": "private", "command": "list"},
+		"rcon_disconnect": {"session_id": "private"},
+	}
+	for tool, args := range refused {
+		if msg := call(other, tool, args); !strings.Contains(msg, "belongs to another MCP client") {
+			t.Errorf("%s: expected another client's session to be refused, got %q", tool, msg)
+		}
+	}
+	if msg := call(other, "rcon_execute", map[string]any{"session_id": "public", "command": "list"}); strings.HasPrefix(msg, "error") {
+		t.Errorf("Expected a shared session to be usable, got %q", msg)
+	}
+
+	if metrics := call(other, "rcon_metrics", map[string]any{}); strings.Contains(metrics, "private") || !strings.Contains(metrics, "- public:") {
+		t.Errorf("Expected only the shared session's metrics, got %q", metrics)
+	}
+	if metrics := call(owner, "rcon_metrics", map[string]any{}); !strings.Contains(metrics, "- private:") {
+		t.Errorf("Expected the owner to see its session's metrics, got %q", metrics)
+	}
+
+	if msg := call(other, "rcon_group_create", map[string]any{"name": "probe", "session_ids": []string{"private"}}); !strings.Contains(msg, "sessions not found: private") {
+		t.Errorf("Expected another client's session to count as missing, got %q", msg)
+	}
+	if msg := call(owner, "rcon_group_create", map[string]any{"name": "mine", "session_ids": []string{"private", "public"}}); strings.HasPrefix(msg, "error") {
+		t.Fatalf("Group create failed: %s", msg)
+	}
+	if groups := call(other, "rcon_group_list", map[string]any{}); strings.Contains(groups, "private") || !strings.Contains(groups, "- mine: public") {
+		t.Errorf("Expected only the shared member listed, got %q", groups)
+	}
+
+	list := call(other, "rcon_list_sessions", map[string]any{})
+	if strings.Contains(list, "private") || !strings.Contains(list, "public") || !strings.Contains(list, "shared") {
+		t.Errorf("Expected only the shared session listed, got %q", list)
+	}
+	if _, err := sessionManager.GetSession("private"); err != nil {
+		t.Errorf("Expected the private session to stay open: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCompletions is the most values a completion response may carry.
+const maxCompletions = 100
+
+// Complete answers completion requests for argument values that name live
+// server state: session IDs, group names, profile names and profile tags.
+// Values are matched case-insensitively by prefix. Arguments with no known
+// source of values get an empty list rather than an error.
+func Complete(ctx context.Context, cc *mcp.ServerSession, params *mcp.CompleteParams) (*mcp.CompleteResult, error) {
+	prefix := strings.ToLower(params.Argument.Value)
+
+	values := []string{}
+	key := ""
+	if cc != nil {
+		key = clients.get(ctx, cc).key
+	}
+	for _, candidate := range completionCandidates(params.Argument.Name, key) {
+		if strings.HasPrefix(strings.ToLower(candidate), prefix) {
+			values = append(values, candidate)
+		}
+	}
+
+	total := len(values)
+	if total > maxCompletions {
+		values = values[:maxCompletions]
+	}
+
+	return &mcp.CompleteResult{
+		Completion: mcp.CompletionResultDetails{
+			Values:  values,
+			Total:   total,
+			HasMore: total > maxCompletions,
+		},
+	}, nil
+}
+
+// completionCandidates returns the sorted, distinct values an argument can
+// take, based on the argument's name. Session IDs are limited to the
+// sessions the client with the given key may use.
+func completionCandidates(argument, key string) []string {
+	var candidates []string
+	switch argument {
+	case "session_id", "session_ids":
+		for _, session := range sessionManager.ListSessions() {
+			if mayUse(key, session) {
+				candidates = append(candidates, session.ID)
+			}
+		}
+	case "group":
+		for _, group := range groupManager.ListGroups() {
+			candidates = append(candidates, group.Name)
+		}
+	case "profile":
+		for _, profile := range serverConfig.Profiles {
+			candidates = append(candidates, profile.Name)
+		}
+	case "tag", "tags":
+		for _, profile := range serverConfig.Profiles {
+			candidates = append(candidates, profile.Tags...)
+		}
+	}
+
+	slices.Sort(candidates)
+	return slices.Compact(candidates)
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestComplete(t *testing.T) {
+	resetSessionManager()
+	resetGroupManager()
+	sessionManager.CreateSession("survival", "Survival", "localhost:25575")
+	sessionManager.CreateSession("creative", "Creative", "localhost:25576")
+	sessionManager.CreateSession("Skyblock", "Skyblock", "localhost:25577")
+	groupManager.CreateGroup("lobbies", nil)
+	setServerConfig(t, &config.Config{Profiles: []*config.Profile{
+		{Name: "prod-eu", Address: "a:1", Tags: []string{"prod", "eu"}},
+		{Name: "prod-us", Address: "b:1", Tags: []string{"prod", "us"}},
+	}})
+
+	tests := []struct {
+		name     string
+		argument string
+		value    string
+		want     []string
+	}{
+		{name: "session ids by prefix", argument: "session_id", value: "s", want: []string{"Skyblock", "survival"}},
+		{name: "all session ids", argument: "session_ids", value: "", want: []string{"Skyblock", "creative", "survival"}},
+		{name: "groups", argument: "group", value: "lob", want: []string{"lobbies"}},
+		{name: "profiles", argument: "profile", value: "prod-", want: []string{"prod-eu", "prod-us"}},
+		{name: "tags are deduplicated", argument: "tag", value: "", want: []string{"eu", "prod", "us"}},
+		{name: "no match", argument: "profile", value: "staging", want: []string{}},
+		{name: "unknown argument", argument: "command", value: "li", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Complete(context.Background(), nil, &mcp.CompleteParams{
+				Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "any"},
+				Argument: mcp.CompleteParamsArgument{Name: tt.argument, Value: tt.value},
+			})
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if got := result.Completion.Values; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+			if result.Completion.Total != len(tt.want) || result.Completion.HasMore {
+				t.Errorf("Unexpected totals: %+v", result.Completion)
+			}
+		})
+	}
+}
+
+func TestComplete_Limit(t *testing.T) {
+	resetSessionManager()
+	for i := range maxCompletions + 5 {
+		sessionManager.CreateSession(string(rune('a'+i%26))+string(rune('a'+i/26)), "", "localhost:1")
+	}
+
+	result, err := Complete(context.Background(), nil, &mcp.CompleteParams{
+		Argument: mcp.CompleteParamsArgument{Name: "session_id"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(result.Completion.Values) != maxCompletions || result.Completion.Total != maxCompletions+5 || !result.Completion.HasMore {
+		t.Errorf("Expected capped completion, got %d values, total %d, hasMore %v",
+			len(result.Completion.Values), result.Completion.Total, result.Completion.HasMore)
+	}
+}
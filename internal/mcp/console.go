@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// consoleURI is the URI of a session's console resource, followed by the
+// session ID.
+const consoleURI = "rcon://console/"
+
+// consoleBacklog is the number of console messages kept per session.
+const consoleBacklog = 500
+
+// ConsoleLine is a message a server sent without being asked: a Rust
+// server over WebRCON, a 7 Days to Die server over telnet, a DayZ server
+// over BattlEye RCon, a Squad server over RCON, or any server whose
+// protocol's Backend forwards its console.
+type ConsoleLine struct {
+	Seq     int64          `json:"seq"` // Increases by one with every message received, across sessions
+	Time    time.Time      `json:"time"`
+	Type    string         `json:"type"`           // Generic, Log, Warning, Error or Chat
+	Message string         `json:"message"`        // The line; for chat, the message as the server sent it
+	Chat    *game.RustChat `json:"chat,omitempty"` // The sender and text of a chat message
+}
+
+// ConsoleResult is the result of the rcon_console_stream and
+// rust_console_stream tools, and the content of the console resource.
+type ConsoleResult struct {
+	SessionID string        `json:"session_id"`
+	Lines     []ConsoleLine `json:"lines"`    // Oldest first
+	LastSeq   int64         `json:"last_seq"` // Sequence number of the latest message; pass as since to read only newer ones
+}
+
+// consoleStore keeps the latest console messages of each session whose
+// server sends them unprompted.
+type consoleStore struct {
+	mu       sync.Mutex
+	seq      int64
+	sessions map[string][]ConsoleLine // Oldest first, at most consoleBacklog each
+}
+
+// consoles holds the console messages of the server process's sessions
+// whose protocol forwards the console, and of its RCON sessions of games
+// that push chat.
+var consoles = &consoleStore{sessions: make(map[string][]ConsoleLine)}
+
+// add stores a console message of session, scrubbed by scrubOutput, and
+// pushes it to the clients that may use the session as a debug log
+// notification.
+func (s *consoleStore) add(session *rcon.Session, m rcon.ConsoleMessage) {
+	line := ConsoleLine{Time: time.Now(), Type: m.Type, Message: scrubOutput(m.Message)}
+	if game.PushesChat(session.Game()) {
+		line.Type = game.SquadMessageType(m.Message)
+	}
+	if m.Type == "Chat" && session.Game() == game.Rust {
+		if chat, err := game.ParseRustChat(m.Message); err == nil {
+			chat.Message, chat.UserID = scrubOutput(chat.Message), scrubOutput(chat.UserID)
+			line.Chat = chat
+		}
+	}
+
+	s.mu.Lock()
+	s.seq++
+	line.Seq = s.seq
+	lines := s.sessions[session.ID]
+	if len(lines) >= consoleBacklog {
+		lines = append(lines[:0], lines[1:]...)
+	}
+	s.sessions[session.ID] = append(lines, line)
+	s.mu.Unlock()
+
+	notifyClients(clients.serverSessions(), session, &mcp.LoggingMessageParams{
+		Level:  "debug",
+		Logger: "rcon.console",
+		Data:   map[string]any{"event": "console_message", "session_id": session.ID, "line": line},
+	})
+}
+
+// tail returns the messages of a session after since, of the given types
+// if any, at most limit of the latest, and the sequence number of the
+// latest message of any session.
+func (s *consoleStore) tail(id string, since int64, types []string, limit int) ([]ConsoleLine, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lines := []ConsoleLine{}
+	for _, line := range s.sessions[id] {
+		if line.Seq > since && (len(types) == 0 || slices.ContainsFunc(types, func(t string) bool { return strings.EqualFold(t, line.Type) })) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	return lines, s.seq
+}
+
+// forget drops the messages kept for a session.
+func (s *consoleStore) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+// ConsoleParams represents parameters for the rcon_console_stream tool
+type ConsoleParams struct {
+	SessionID string   `json:"session_id" jsonschema:"Session ID of a server that sends its console: Rust over WebRCON, 7 Days to Die over telnet, DayZ or Arma over BattlEye RCon, or Squad or Post Scriptum"`
+	Since     int64    `json:"since,omitempty" jsonschema:"Only return messages after this sequence number, as returned in last_seq (optional)"`
+	Limit     int      `json:"limit,omitempty" jsonschema:"Most messages to return, the latest ones (default 50, max 500)"`
+	Types     []string `json:"types,omitempty" jsonschema:"Only return messages of these types: Generic, Log, Warning, Error or Chat (optional)"`
+}
+
+// ConsoleStream returns the latest console messages of a session whose
+// server sends them unprompted.
+func ConsoleStream(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ConsoleParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	session, err := consoleSession(ctx, args.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultTailLines
+	}
+	result := &ConsoleResult{SessionID: session.ID}
+	result.Lines, result.LastSeq = consoles.tail(session.ID, args.Since, args.Types, min(limit, maxTailLines))
+	return statusResult(result)
+}
+
+// ReadConsole returns the console messages kept of the session named by
+// the URI, all of them or those after the sequence number in its since
+// parameter.
+func ReadConsole(ctx context.Context, ss *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	u, err := url.Parse(params.URI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid console URI: %w", err)
+	}
+	var since int64
+	if s := u.Query().Get("since"); s != "" {
+		if since, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid since %q, want a sequence number", s)
+		}
+	}
+	session, err := consoleSession(ctx, strings.TrimPrefix(u.Path, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := ConsoleResult{SessionID: session.ID}
+	result.Lines, result.LastSeq = consoles.tail(session.ID, since, nil, consoleBacklog)
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode console messages: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: params.URI, MIMEType: "application/json", Text: string(data)}},
+	}, nil
+}
+
+// consoleSession returns the session with the given ID if its server sends
+// console messages unprompted.
+func consoleSession(ctx context.Context, id string) (*rcon.Session, error) {
+	session, err := getSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if caps, _ := rcon.ProtocolCapabilities(session.Client.Protocol()); caps.Console {
+		return session, nil
+	}
+	if !game.PushesChat(session.Game()) {
+		return nil, fmt.Errorf("session %s receives no console messages: only Rust over WebRCON, 7 Days to Die over telnet, "+
+			"DayZ and Arma over BattlEye RCon, Squad and Post Scriptum servers, and protocols that say they do send them", session.ID)
+	}
+	return session, nil
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestConsoleStream(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	squad := connectFakeSession(t, "squad", func(string) string { return "" })
+	squad.SetGame(game.Squad)
+	t.Cleanup(func() { consoles.forget("squad") })
+	connectFakeSession(t, "mc", func(string) string { return "" }).SetGame(game.Minecraft)
+	ctx := context.Background()
+
+	consoles.add(squad, rcon.ConsoleMessage{Type: "Chat", Message: "[ChatAll] [SteamID:76561197960290418] Bob : hi"})
+	consoles.add(squad, rcon.ConsoleMessage{Type: "Chat", Message: "[SteamID:76561197960290418] Admin has possessed admin camera."})
+
+	res, err := ConsoleStream(ctx, nil, &mcp.CallToolParamsFor[ConsoleParams]{Arguments: ConsoleParams{SessionID: "squad", Types: []string{"chat"}}})
+	if err != nil {
+		t.Fatalf("ConsoleStream failed: %v", err)
+	}
+	console := res.StructuredContent.(*ConsoleResult)
+	if len(console.Lines) != 1 || console.Lines[0].Message != "[ChatAll] [SteamID:76561197960290418] Bob : hi" {
+		t.Errorf("Expected the chat message only, got %+v", console.Lines)
+	}
+
+	read, err := ReadConsole(ctx, nil, &mcp.ReadResourceParams{URI: consoleURI + "squad"})
+	if err != nil {
+		t.Fatalf("ReadConsole failed: %v", err)
+	}
+	var all ConsoleResult
+	if err := json.Unmarshal([]byte(read.Contents[0].Text), &all); err != nil {
+		t.Fatalf("Failed to decode the console: %v", err)
+	}
+	if len(all.Lines) != 2 || all.Lines[1].Type != "Log" {
+		t.Errorf("Expected the admin camera event as a log line, got %+v", all.Lines)
+	}
+
+	if _, err := ConsoleStream(ctx, nil, &mcp.CallToolParamsFor[ConsoleParams]{Arguments: ConsoleParams{SessionID: "mc"}}); err == nil {
+		t.Error("Expected a Minecraft session to have no console stream")
+	}
+}
+
+func TestConsoleStream_ResponseRules(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Responses: config.Responses{Redact: []config.RedactRule{
+		{Preset: "ipv4"}, {Preset: "steamid"},
+	}}})
+	registerResponseRules(serverConfig)
+	t.Cleanup(func() { registerResponseRules(&config.Config{}) })
+	rust := connectFakeSession(t, "rust", func(string) string { return "" })
+	rust.SetGame(game.Rust)
+	t.Cleanup(func() { consoles.forget("rust") })
+
+	consoles.add(rust, rcon.ConsoleMessage{Type: "Generic", Message: "203.0.113.9:50123/76561197960290418/Bob joined"})
+	consoles.add(rust, rcon.ConsoleMessage{Type: "Chat",
+		Message: `{"Channel": 0, "Message": "my ip is 203.0.113.9", "UserId": "76561197960290418", "Username": "Bob", "Time": 1}`})
+
+	lines, _ := consoles.tail("rust", 0, nil, consoleBacklog)
+	if len(lines) != 2 {
+		t.Fatalf("Expected two lines, got %+v", lines)
+	}
+	for _, line := range lines {
+		if strings.Contains(line.Message, "203.0.113.9") || strings.Contains(line.Message, "76561197960290418") {
+			t.Errorf("Expected the message to be redacted, got %q", line.Message)
+		}
+	}
+	if chat := lines[1].Chat; chat == nil || chat.UserID != "[STEAMID]" || chat.Message != "my ip is [IP]" {
+		t.Errorf("Expected the chat to be redacted, got %+v", chat)
+	}
+}
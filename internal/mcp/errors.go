@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrPolicyDenied is matched by the errors of requests refused by the
+// configured policies, read-only mode or a session's role.
+var ErrPolicyDenied = errors.New("denied by policy")
+
+// policyDenied is an error refusing a request on policy grounds. Its
+// message explains the refusal; it matches ErrPolicyDenied.
+type policyDenied struct {
+	msg string
+}
+
+func (e *policyDenied) Error() string { return e.msg }
+
+func (e *policyDenied) Is(target error) bool { return target == ErrPolicyDenied }
+
+// deniedError returns a policy refusal with a formatted message.
+func deniedError(format string, args ...any) error {
+	return &policyDenied{msg: fmt.Sprintf(format, args...)}
+}
+
+// ErrorCode classifies a failed tool call or command, so that agents and
+// automation can branch on the kind of failure instead of matching
+// message text. Codes are part of the tool output and never change.
+type ErrorCode string
+
+// Error codes, reported as error_code in structured results and in the
+// _meta of tool results flagged as errors.
+const (
+	CodeNotConnected     ErrorCode = "not_connected"      // The session's connection is closed or dropped
+	CodeNotAuthenticated ErrorCode = "not_authenticated"  // The session is connected but not authenticated
+	CodeAuthFailed       ErrorCode = "auth_failed"        // The server refused the password
+	CodePolicyDenied     ErrorCode = "policy_denied"      // Refused by the policies, read-only mode or the session's role
+	CodeQuotaExceeded    ErrorCode = "quota_exceeded"     // A command quota is used up
+	CodeTimeout          ErrorCode = "timeout"            // The server or the call's deadline timed out
+	CodeCancelled        ErrorCode = "cancelled"          // The call was cancelled
+	CodeResponseTooLarge ErrorCode = "response_too_large" // The server sent a packet larger than the protocol allows
+	CodeInvalidCommand   ErrorCode = "invalid_command"    // The command contains control characters
+	CodeSessionNotFound  ErrorCode = "session_not_found"  // No session has the given ID
+	CodeRejected         ErrorCode = "rejected"           // The server ran the command but refused it
+	CodeUnknown          ErrorCode = "unknown"            // Any other failure
+)
+
+// errorCode returns the code classifying err, or an empty code for a nil
+// error.
+func errorCode(err error) ErrorCode {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, rcon.ErrNotConnected):
+		return CodeNotConnected
+	case errors.Is(err, rcon.ErrNotAuthenticated):
+		return CodeNotAuthenticated
+	case errors.Is(err, rcon.ErrAuthFailed):
+		return CodeAuthFailed
+	case errors.Is(err, ErrPolicyDenied):
+		return CodePolicyDenied
+	case errors.Is(err, errQuotaExceeded):
+		return CodeQuotaExceeded
+	case errors.Is(err, rcon.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled):
+		return CodeCancelled
+	case errors.Is(err, rcon.ErrResponseTooLarge):
+		return CodeResponseTooLarge
+	case errors.Is(err, rcon.ErrInvalidCommand):
+		return CodeInvalidCommand
+	case errors.Is(err, rcon.ErrSessionNotFound):
+		return CodeSessionNotFound
+	default:
+		return CodeUnknown
+	}
+}
+
+// toolError turns the error returned by a tool handler into a result
+// flagged as an error, with code in the result's _meta.
+func toolError[Out any](code ErrorCode, err error) *mcp.CallToolResultFor[Out] {
+	return &mcp.CallToolResultFor[Out]{
+		Meta:    mcp.Meta{"error_code": code},
+		Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+		IsError: true,
+	}
+}
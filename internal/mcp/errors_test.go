@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorCode
+	}{
+		{nil, ""},
+		{fmt.Errorf("failed to execute command: %w", rcon.ErrNotConnected), CodeNotConnected},
+		{fmt.Errorf("failed to authenticate: %w", rcon.ErrAuthFailed), CodeAuthFailed},
+		{policyError(game.Unknown, "stop"), CodePolicyDenied},
+		{fmt.Errorf("%w: session s1 may run 2 commands per 1h", errQuotaExceeded), CodeQuotaExceeded},
+		{fmt.Errorf("command cancelled: %w", context.DeadlineExceeded), CodeTimeout},
+		{fmt.Errorf("command cancelled: %w", context.Canceled), CodeCancelled},
+		{fmt.Errorf("failed to read response: %w", rcon.ErrResponseTooLarge), CodeResponseTooLarge},
+		{rcon.CheckCommand("list\nstop"), CodeInvalidCommand},
+		{errors.New("something else"), CodeUnknown},
+	}
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.want {
+			t.Errorf("errorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestToolResults_ErrorCode(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Policies: config.Policies{Deny: []string{"stop"}}})
+	address := startFakeRCONServer(t, func(string) string { return "ok" })
+	cs := connectClients(t, 1)[0]
+	if msg := callConnect(t, cs, "coded", address); msg != "" {
+		t.Fatalf("Connect failed: %s", msg)
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "rcon_execute", Arguments: args})
+		if err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		if !res.IsError {
+			t.Fatalf("Expected an error result, got %+v", res)
+		}
+		return res
+	}
+
+	res := call(map[string]any{"session_id": "missing", "command": "list"})
+	if code := res.Meta["error_code"]; code != string(CodeSessionNotFound) {
+		t.Errorf("Expected error_code %q in _meta, got %v", CodeSessionNotFound, res.Meta)
+	}
+
+	res = call(map[string]any{"session_id": "coded", "command": "stop"})
+	if code := res.Meta["error_code"]; code != string(CodePolicyDenied) {
+		t.Errorf("Expected error_code %q in _meta, got %v", CodePolicyDenied, res.Meta)
+	}
+	structured, _ := res.StructuredContent.(map[string]any)
+	if code := structured["error_code"]; code != string(CodePolicyDenied) {
+		t.Errorf("Expected error_code %q in the structured content, got %v", CodePolicyDenied, res.StructuredContent)
+	}
+}
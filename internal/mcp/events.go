@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/logging"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// eventsURI is the URI of the resource listing recent lifecycle events.
+const eventsURI = "rcon://events"
+
+// eventBacklog is the number of recent events kept for the events resource.
+const eventBacklog = 256
+
+// Lifecycle event types.
+const (
+	eventConnected         = "connected"           // A session connected and authenticated
+	eventReconnectAttempt  = "reconnect_attempt"   // A session whose connection dropped is being connected again
+	eventReconnected       = "reconnected"         // A session whose connection dropped connected again
+	eventConnectFailed     = "connect_failed"      // A session failed to connect or authenticate
+	eventDisconnected      = "disconnected"        // A session was closed
+	eventDropped           = "dropped"             // A session lost its connection
+	eventHealthCheckFailed = "health_check_failed" // A health-check probe failed on an open connection
+	eventCommandDenied     = "command_denied"      // A command was refused by the policies or the session's role
+	eventQuotaExceeded     = "quota_exceeded"      // A command was refused because a quota is used up
+)
+
+// Event is one entry of the lifecycle event log.
+type Event struct {
+	Seq       int64     `json:"seq"`                  // Increases by one with every event
+	Time      time.Time `json:"time"`                 // When the event happened
+	Type      string    `json:"type"`                 // One of the event* types
+	SessionID string    `json:"session_id,omitempty"` // Session the event concerns
+	Address   string    `json:"address,omitempty"`    // Server address of the session
+	Detail    string    `json:"detail,omitempty"`     // Error or command, with secrets masked
+	RequestID string    `json:"request_id,omitempty"` // Tool call that caused the event, empty for background work
+
+	owner string // Key of the only client that may see the event, empty for all
+}
+
+// EventsResult is the content of the events resource.
+type EventsResult struct {
+	Events  []Event `json:"events"`   // Oldest first
+	LastSeq int64   `json:"last_seq"` // Sequence number of the latest event; pass as since to read only newer ones
+}
+
+// eventLog keeps the most recent lifecycle events.
+type eventLog struct {
+	mu     sync.Mutex
+	events []Event // Oldest first, at most eventBacklog
+	seq    int64   // Sequence number of the latest event
+}
+
+// events is the lifecycle event log of the server process.
+var events = &eventLog{}
+
+// add appends e, numbering it, and returns it as stored.
+func (l *eventLog) add(e Event) Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	e.Seq = l.seq
+	if len(l.events) >= eventBacklog {
+		l.events = append(l.events[:0], l.events[1:]...)
+	}
+	l.events = append(l.events, e)
+	return e
+}
+
+// since returns the events after seq that the client with the given key
+// may see, and the sequence number of the latest event.
+func (l *eventLog) since(seq int64, key string) ([]Event, int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	visible := []Event{}
+	for _, e := range l.events {
+		if e.Seq > seq && (e.owner == "" || e.owner == key) {
+			visible = append(visible, e)
+		}
+	}
+	return visible, l.seq
+}
+
+// recordEvent adds an event about session, caused by the work ctx belongs
+// to, to the event log, visible to the clients that may use the session,
+// and pushes it to those of them that have enabled logging at the debug
+// level.
+func recordEvent(ctx context.Context, kind string, session *rcon.Session, detail string) {
+	owner, shared := session.Owner()
+	if shared {
+		owner = ""
+	}
+	addEvent(ctx, Event{Type: kind, SessionID: session.ID, Address: session.Address, Detail: detail, owner: owner})
+}
+
+// addEvent stamps e with the time and the request ID of ctx, adds it to the
+// event log and pushes it to the connected clients allowed to see it that
+// have enabled logging at the debug level.
+func addEvent(ctx context.Context, e Event) {
+	e.Time = time.Now()
+	e.RequestID = logging.RequestIDFromContext(ctx)
+	e.Detail = redact.String(e.Detail)
+	e = events.add(e)
+
+	params := &mcp.LoggingMessageParams{Level: "debug", Logger: "rcon.events", Data: e}
+	for ss := range clients.serverSessions() {
+		state := clients.lookup(ss)
+		if e.owner != "" && (state == nil || state.key != e.owner) {
+			continue
+		}
+		if err := ss.Log(context.Background(), params); err != nil {
+			logger(context.Background()).Warn("Failed to notify client", "event", e.Type, "error", err)
+		}
+	}
+}
+
+// registerResources adds the resources of the server.
+func registerResources(server *mcp.Server) {
+	server.AddResource(&mcp.Resource{
+		URI:         eventsURI,
+		Name:        "events",
+		Title:       "RCON lifecycle events",
+		Description: "Recent connects, disconnects, dropped connections, reconnect attempts, health-check failures and refused commands, oldest first",
+		MIMEType:    "application/json",
+	}, ReadEvents)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: eventsURI + "{?since}",
+		Name:        "events-since",
+		Title:       "RCON lifecycle events since",
+		Description: "Lifecycle events after the given sequence number, as returned in last_seq",
+		MIMEType:    "application/json",
+	}, ReadEvents)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: gameLogsURI + "{session_id}{?since}",
+		Name:        "game-logs",
+		Title:       "Source game log",
+		Description: "Log lines a Source engine session's server sent the game log receiver, oldest first, all or those after the given sequence number",
+		MIMEType:    "application/json",
+	}, ReadGameLogs)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: consoleURI + "{session_id}{?since}",
+		Name:        "console",
+		Title:       "Server console stream",
+		Description: "Console output and chat a session's server sent on its own, oldest first, all or those after the given sequence number",
+		MIMEType:    "application/json",
+	}, ReadConsole)
+}
+
+// ReadEvents returns the recent lifecycle events the client may see: all
+// of them, or those after the sequence number in the URI's since
+// parameter. Events about another client's private sessions are left out.
+func ReadEvents(ctx context.Context, ss *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	u, err := url.Parse(params.URI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid events URI: %w", err)
+	}
+	var since int64
+	if s := u.Query().Get("since"); s != "" {
+		if since, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid since %q, want a sequence number", s)
+		}
+	}
+	key := ""
+	if ss != nil {
+		if state := clients.lookup(ss); state != nil {
+			key = state.key
+		}
+	}
+
+	var result EventsResult
+	result.Events, result.LastSeq = events.since(since, key)
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode events: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: params.URI, MIMEType: "application/json", Text: string(data)}},
+	}, nil
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// readEvents reads the events resource at uri as a client.
+func readEvents(t *testing.T, cs *mcp.ClientSession, uri string) EventsResult {
+	t.Helper()
+	res, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		t.Fatalf("ReadResource %s failed: %v", uri, err)
+	}
+	var result EventsResult
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &result); err != nil {
+		t.Fatalf("Expected JSON events, got %q", res.Contents[0].Text)
+	}
+	return result
+}
+
+func TestEventLog(t *testing.T) {
+	l := &eventLog{}
+	for i := 0; i < eventBacklog+10; i++ {
+		l.add(Event{Type: eventConnected, SessionID: fmt.Sprint(i)})
+	}
+	l.add(Event{Type: eventDropped, SessionID: "private", owner: "client-1"})
+
+	all, last := l.since(0, "")
+	if len(all) != eventBacklog-1 || last != eventBacklog+11 || all[0].Seq != 12 {
+		t.Errorf("Expected the oldest events to be discarded, got %d events from %d, last %d", len(all), all[0].Seq, last)
+	}
+	if mine, _ := l.since(last-1, "client-1"); len(mine) != 1 || mine[0].SessionID != "private" {
+		t.Errorf("Expected the owner to see its private event, got %+v", mine)
+	}
+	if others, _ := l.since(last-1, "client-2"); len(others) != 0 {
+		t.Errorf("Expected another client's private event to be hidden, got %+v", others)
+	}
+}
+
+func TestEventsResource(t *testing.T) {
+	resetSessionManager()
+	events = &eventLog{}
+	droppedSessions = &idSet{ids: make(map[string]bool)}
+	setServerConfig(t, &config.Config{Policies: config.Policies{Deny: []string{"stop"}}})
+	address := startFakeRCONServer(t, func(string) string { return "ok" })
+
+	clients = newClientRegistry()
+	t.Cleanup(clients.wait)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"},
+		&mcp.ServerOptions{InitializedHandler: clients.connected})
+	registerTools(server)
+	registerResources(server)
+	var sessions []*mcp.ClientSession
+	for i := 0; i < 2; i++ {
+		clientTransport, serverTransport := mcp.NewInMemoryTransports()
+		if _, err := server.Connect(context.Background(), serverTransport); err != nil {
+			t.Fatalf("Failed to connect server: %v", err)
+		}
+		cs, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(context.Background(), clientTransport)
+		if err != nil {
+			t.Fatalf("Failed to connect client: %v", err)
+		}
+		t.Cleanup(func() { cs.Close() })
+		sessions = append(sessions, cs)
+	}
+	owner, other := sessions[0], sessions[1]
+	waitFor(t, func() bool { return clients.count() == 2 })
+
+	if msg := callConnect(t, owner, "private", address); msg != "" {
+		t.Fatalf("Connect failed: %s", msg)
+	}
+	if _, err := owner.CallTool(context.Background(), &mcp.CallToolParams{
+		Name: "rcon_execute", Arguments: map[string]any{"session_id": "private", "command": "stop"},
+	}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	session, _ := sessionManager.GetSession("private")
+	droppedSessions.add(session.ID)
+	recordEvent(context.Background(), eventDropped, session, io.EOF.Error())
+	_ = sessionManager.RemoveSession("private")
+	if msg := callConnect(t, owner, "private", address); msg != "" {
+		t.Fatalf("Reconnect failed: %s", msg)
+	}
+
+	got := readEvents(t, owner, eventsURI)
+	want := []string{eventConnected, eventCommandDenied, eventDropped, eventReconnectAttempt, eventReconnected}
+	if len(got.Events) != len(want) {
+		t.Fatalf("Expected events %v, got %+v", want, got.Events)
+	}
+	for i, e := range got.Events {
+		if e.Type != want[i] || e.SessionID != "private" {
+			t.Errorf("Event %d: expected %s of session private, got %+v", i, want[i], e)
+		}
+	}
+	if got.LastSeq != got.Events[len(got.Events)-1].Seq {
+		t.Errorf("Expected last_seq to be the latest event, got %d", got.LastSeq)
+	}
+
+	newer := readEvents(t, owner, fmt.Sprintf("%s?since=%d", eventsURI, got.Events[2].Seq))
+	if len(newer.Events) != 2 || newer.Events[0].Type != eventReconnectAttempt {
+		t.Errorf("Expected only the events after the drop, got %+v", newer.Events)
+	}
+	if hidden := readEvents(t, other, eventsURI); len(hidden.Events) != 0 {
+		t.Errorf("Expected another client's private session events to be hidden, got %+v", hidden.Events)
+	}
+}
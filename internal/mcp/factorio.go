@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// FactorioSessionParams represents parameters for the Factorio tools that
+// only need a session
+type FactorioSessionParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Factorio server"`
+}
+
+// FactorioLuaParams represents parameters for the factorio_lua tool
+type FactorioLuaParams struct {
+	SessionID  string `json:"session_id" jsonschema:"Session ID of a Factorio server"`
+	Code       string `json:"code,omitempty" jsonschema:"Lua statements on one line; output passed to rcon.print is returned, e.g. rcon.print(#game.connected_players)"`
+	Expression string `json:"expression,omitempty" jsonschema:"Lua expression whose value is returned serialized with serpent, e.g. game.forces.player.research_progress; instead of code"`
+}
+
+// registerFactorioTools registers the tools of the factorio game pack,
+// which read the players and map age of Factorio servers and run Lua code
+// on them, returning what it prints.
+func registerFactorioTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "factorio_players",
+		Description: "Run /players online on a Factorio server and return the players online",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List Factorio players",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, FactorioPlayers)
+
+	addTool(server, &mcp.Tool{
+		Name:        "factorio_time",
+		Description: "Run /time on a Factorio server and return the age of the map, in seconds and as the server wrote it",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Factorio map age",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, FactorioTime)
+
+	addTool(server, &mcp.Tool{
+		Name: "factorio_lua",
+		Description: "Run Lua code on a Factorio server with /silent-command, which players do not see, and return what it passes to rcon.print, " +
+			"or the value of an expression. The code runs with full access to the game; the policies may restrict it",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Run Factorio Lua",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, FactorioLua)
+}
+
+// FactorioPlayers runs "/players online" and returns the players online.
+func FactorioPlayers(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[FactorioSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	return factorioQuery(ctx, params.Arguments.SessionID, "/players online")
+}
+
+// FactorioTime runs "/time" and returns the age of the map.
+func FactorioTime(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[FactorioSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	return factorioQuery(ctx, params.Arguments.SessionID, "/time")
+}
+
+// FactorioLua runs code, or prints the value of an expression, with
+// "/silent-command". The command goes through the policies like any
+// other, so their lua_allow patterns see the code as sent.
+func FactorioLua(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[FactorioLuaParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	code, expression := strings.TrimSpace(args.Code), strings.TrimSpace(args.Expression)
+	switch {
+	case code == "" && expression == "":
+		return nil, errors.New("code or an expression is required")
+	case code != "" && expression != "":
+		return nil, errors.New("pass either code or an expression, not both")
+	case expression != "":
+		code = "rcon.print(serpent.line(" + expression + ", {comment = false}))"
+	}
+	session, err := packSession(ctx, args.SessionID, game.Factorio)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, "/silent-command "+code, func(output string) (any, error) {
+		return game.ParseFactorioLua(output)
+	})
+}
+
+// factorioQuery runs a Factorio command that has a parser and returns its
+// output parsed.
+func factorioQuery(ctx context.Context, sessionID, command string) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, sessionID, game.Factorio)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.Parse(game.Factorio, command, output)
+	})
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestFactorioTools(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	var mu sync.Mutex
+	var sent []string
+	connectFakeSession(t, "factorio", func(command string) string {
+		mu.Lock()
+		sent = append(sent, command)
+		mu.Unlock()
+		switch command {
+		case "/players online":
+			return "Online players (1):\n  alice (online)\n"
+		case "/time":
+			return "1 hour, 12 minutes and 5 seconds"
+		case "/silent-command rcon.print(#game.connected_players)":
+			return "1\n"
+		case "/silent-command rcon.print(serpent.line(game.tick, {comment = false}))":
+			return "259500\n"
+		}
+		return "Cannot execute command. Error: [string \"oops\"]:1: syntax error"
+	})
+	ctx := context.Background()
+	session := &mcp.CallToolParamsFor[FactorioSessionParams]{Arguments: FactorioSessionParams{SessionID: "factorio"}}
+
+	res, err := FactorioPlayers(ctx, nil, session)
+	if err != nil || res.IsError {
+		t.Fatalf("FactorioPlayers failed: %v, %+v", err, res)
+	}
+	if list := res.StructuredContent.(*ExecuteResult).Parsed.(*game.PlayerList); list.Online != 1 || list.Players[0] != "alice" {
+		t.Errorf("Unexpected players: %+v", list)
+	}
+	res, err = FactorioTime(ctx, nil, session)
+	if err != nil || res.IsError || res.StructuredContent.(*ExecuteResult).Parsed.(*game.FactorioTime).Seconds != 4325 {
+		t.Errorf("Unexpected map age: %v, %+v", err, res)
+	}
+
+	for _, args := range []FactorioLuaParams{
+		{SessionID: "factorio", Code: "rcon.print(#game.connected_players)"},
+		{SessionID: "factorio", Expression: "game.tick"},
+	} {
+		res, err = FactorioLua(ctx, nil, &mcp.CallToolParamsFor[FactorioLuaParams]{Arguments: args})
+		if err != nil || res.IsError {
+			t.Fatalf("FactorioLua(%+v) failed: %v, %+v", args, err, res)
+		}
+	}
+	if out := res.StructuredContent.(*ExecuteResult).Parsed.(*game.FactorioLua).Output; out != "259500" {
+		t.Errorf("Expected the value of the expression, got %q", out)
+	}
+	res, err = FactorioLua(ctx, nil, &mcp.CallToolParamsFor[FactorioLuaParams]{Arguments: FactorioLuaParams{SessionID: "factorio", Code: "oops"}})
+	if err != nil || !res.IsError || res.StructuredContent.(*ExecuteResult).ErrorCode != CodeRejected {
+		t.Errorf("Expected a Lua error to be rejected, got %v, %+v", err, res)
+	}
+	if _, err := FactorioLua(ctx, nil, &mcp.CallToolParamsFor[FactorioLuaParams]{Arguments: FactorioLuaParams{SessionID: "factorio"}}); err == nil {
+		t.Error("Expected a call without code to be refused")
+	}
+}
+
+func TestFactorioLua_Policy(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Policies: config.Policies{LuaAllow: []string{`rcon\.print\(game\.tick\)`}}})
+	var mu sync.Mutex
+	var sent []string
+	session := connectFakeSession(t, "factorio", func(command string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, command)
+		return "259500"
+	})
+	ctx := context.Background()
+
+	res, err := FactorioLua(ctx, nil, &mcp.CallToolParamsFor[FactorioLuaParams]{Arguments: FactorioLuaParams{SessionID: "factorio", Code: "rcon.print(game.tick)"}})
+	if err != nil || res.IsError {
+		t.Fatalf("Expected allowed code to run, got %v, %+v", err, res)
+	}
+	res, err = FactorioLua(ctx, nil, &mcp.CallToolParamsFor[FactorioLuaParams]{Arguments: FactorioLuaParams{SessionID: "factorio", Code: "game.speed = 10"}})
+	if err != nil || !res.IsError || res.StructuredContent.(*ExecuteResult).ErrorCode != CodePolicyDenied {
+		t.Fatalf("Expected other code to be denied, got %v, %+v", err, res)
+	}
+	// rcon_execute is held to the same patterns
+	if _, _, err := executeWithMetadata(ctx, session, "/c game.speed = 10"); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("Expected Lua sent with rcon_execute to be denied, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 1 {
+		t.Errorf("Expected only the allowed code to be sent, got %q", sent)
+	}
+}
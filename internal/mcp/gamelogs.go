@@ -0,0 +1,334 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/gamelog"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// gameLogsURI is the URI of a session's log resource, followed by the
+// session ID.
+const gameLogsURI = "rcon://logs/"
+
+// Limits of the rcon_tail_logs tool.
+const (
+	defaultTailLines = 50  // Lines returned when no limit is given
+	maxTailLines     = 500 // Most lines one call returns
+)
+
+// TailLogsParams represents parameters for the rcon_tail_logs tool
+type TailLogsParams struct {
+	SessionID string   `json:"session_id" jsonschema:"Session ID of a Source engine server"`
+	Since     int64    `json:"since,omitempty" jsonschema:"Only return lines after this sequence number, as returned in last_seq (optional)"`
+	Limit     int      `json:"limit,omitempty" jsonschema:"Most lines to return, the latest ones (default 50, max 500)"`
+	Kinds     []string `json:"kinds,omitempty" jsonschema:"Only return lines of these kinds: chat, team_chat, kill, connect, disconnect or other (optional)"`
+}
+
+// LogLine is one line of a session's game log.
+type LogLine struct {
+	Seq int64 `json:"seq"` // Increases by one with every line received, across sessions
+	gamelog.Line
+}
+
+// TailLogsResult is the result of the rcon_tail_logs tool and the content
+// of the log resources.
+type TailLogsResult struct {
+	SessionID  string    `json:"session_id"`
+	LogAddress string    `json:"log_address"`          // Address the server sends its log to
+	Registered bool      `json:"registered,omitempty"` // The receiver was registered with the server by this call
+	Lines      []LogLine `json:"lines"`                // Oldest first
+	LastSeq    int64     `json:"last_seq"`             // Sequence number of the latest line; pass as since to read only newer ones
+}
+
+// sessionLog is the log received from one session's server.
+type sessionLog struct {
+	address string         // Address registered with logaddress_add
+	from    netip.AddrPort // Address of the server's RCON connection, which its log packets usually come from
+	lines   []LogLine      // Oldest first, at most the configured backlog
+}
+
+// gameLogStore routes the lines the game log receiver gets to the sessions
+// whose servers sent them, and keeps the latest of them.
+type gameLogStore struct {
+	mu        sync.Mutex
+	receiver  *gamelog.Receiver
+	advertise string // Configured address servers send to, empty to derive one per session
+	port      int    // Port the receiver listens on
+	backlog   int    // Lines kept per session
+	seq       int64
+	sessions  map[string]*sessionLog
+}
+
+// gameLogs is the game log receiver of the server process.
+var gameLogs = &gameLogStore{}
+
+// openGameLogs starts the game log receiver configured by settings,
+// replacing any started before, and pushes the lines it gets to the
+// clients of server. The receiver is disabled when no listen address is set.
+func openGameLogs(settings config.GameLogs, server *mcp.Server) error {
+	closeGameLogs()
+	if settings.Listen == "" {
+		return nil
+	}
+	r, err := gamelog.Listen(settings.Listen, func(from netip.AddrPort, line gamelog.Line) {
+		if session, stored, ok := gameLogs.add(from, line); ok {
+			notifyClients(server.Sessions(), session, &mcp.LoggingMessageParams{
+				Level:  "debug",
+				Logger: "rcon.logs",
+				Data:   map[string]any{"event": "log_line", "session_id": session.ID, "line": stored},
+			})
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start the game log receiver: %w", err)
+	}
+
+	gameLogs.mu.Lock()
+	defer gameLogs.mu.Unlock()
+	gameLogs.receiver = r
+	gameLogs.advertise = settings.Advertise
+	gameLogs.port = r.Addr().(*net.UDPAddr).Port
+	gameLogs.backlog = settings.Lines()
+	gameLogs.sessions = make(map[string]*sessionLog)
+	return nil
+}
+
+// closeGameLogs stops the game log receiver, if one is running, and drops
+// the lines it received.
+func closeGameLogs() {
+	gameLogs.mu.Lock()
+	r := gameLogs.receiver
+	gameLogs.receiver, gameLogs.sessions = nil, nil
+	gameLogs.mu.Unlock()
+	if r != nil {
+		_ = r.Close()
+	}
+}
+
+// add stores a line sent from the given address for the session it belongs
+// to: the registered session whose server's address it came from, or else
+// the only one on the host it came from, scrubbed by scrubOutput. Lines
+// from elsewhere, and lines for sessions that were closed since, are
+// dropped.
+func (s *gameLogStore) add(from netip.AddrPort, line gamelog.Line) (*rcon.Session, LogLine, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := ""
+	var sameHost []string
+	for sid, l := range s.sessions {
+		if l.from == from {
+			id = sid
+			break
+		}
+		if l.from.Addr() == from.Addr() {
+			sameHost = append(sameHost, sid)
+		}
+	}
+	if id == "" && len(sameHost) == 1 {
+		id = sameHost[0]
+	}
+	if id == "" {
+		return nil, LogLine{}, false
+	}
+	session, err := sessionManager.GetSession(id)
+	if err != nil {
+		delete(s.sessions, id)
+		return nil, LogLine{}, false
+	}
+
+	l := s.sessions[id]
+	s.seq++
+	line.Text, line.Message, line.SteamID = scrubOutput(line.Text), scrubOutput(line.Message), scrubOutput(line.SteamID)
+	stored := LogLine{Seq: s.seq, Line: line}
+	if len(l.lines) >= s.backlog {
+		l.lines = append(l.lines[:0], l.lines[1:]...)
+	}
+	l.lines = append(l.lines, stored)
+	return session, stored, true
+}
+
+// tail returns the lines of a session after since, of the given kinds if
+// any, at most limit of the latest, and the sequence number of the latest
+// line of any session.
+func (s *gameLogStore) tail(id string, since int64, kinds []string, limit int) ([]LogLine, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lines := []LogLine{}
+	if l := s.sessions[id]; l != nil {
+		for _, line := range l.lines {
+			if line.Seq > since && (len(kinds) == 0 || slices.Contains(kinds, line.Kind)) {
+				lines = append(lines, line)
+			}
+		}
+	}
+	if limit > 0 && len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	return lines, s.seq
+}
+
+// registered returns the address a session's server was told to send its
+// log to, or an empty string if it was not.
+func (s *gameLogStore) registered(id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l := s.sessions[id]; l != nil {
+		return l.address
+	}
+	return ""
+}
+
+// register tells a session's server to send its log to the receiver, with
+// "log on" and "logaddress_add", unless it was told already. Both commands
+// go through executeWithMetadata, so the policies and the session's role
+// apply and they are audited. It returns the address the log is sent to
+// and whether this call registered it.
+func (s *gameLogStore) register(ctx context.Context, session *rcon.Session) (string, bool, error) {
+	s.mu.Lock()
+	running := s.receiver != nil
+	advertise, port := s.advertise, s.port
+	s.mu.Unlock()
+	if !running {
+		return "", false, errors.New("the game log receiver is not running; set game_logs.listen in the config file")
+	}
+	if address := s.registered(session.ID); address != "" {
+		return address, false, nil
+	}
+
+	local, remote := session.Client.Addrs()
+	if local == nil {
+		return "", false, rcon.ErrNotConnected
+	}
+	address := advertise
+	if address == "" {
+		host, _, _ := net.SplitHostPort(local.String())
+		address = net.JoinHostPort(host, strconv.Itoa(port))
+	}
+	for _, command := range []string{"log on", "logaddress_add " + address} {
+		output, meta, err := executeWithMetadata(ctx, session, command)
+		switch {
+		case err != nil:
+			return "", false, fmt.Errorf("failed to register the game log receiver: %w", err)
+		case meta.Rejected:
+			return "", false, fmt.Errorf("the server refused %q: %s", command, strings.TrimSpace(output))
+		}
+	}
+
+	from, _ := netip.ParseAddrPort(remote.String())
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		return "", false, errors.New("the game log receiver was stopped")
+	}
+	s.sessions[session.ID] = &sessionLog{address: address, from: netip.AddrPortFrom(from.Addr().Unmap(), from.Port())}
+	return address, true, nil
+}
+
+// unregister tells a session's server to stop sending its log to the
+// receiver, if it was told to send it, and drops the lines kept for it.
+// Failures are only logged, since the session is going away.
+func (s *gameLogStore) unregister(ctx context.Context, session *rcon.Session) {
+	address := s.registered(session.ID)
+	if address == "" {
+		return
+	}
+	if _, _, err := executeWithMetadata(ctx, session, "logaddress_del "+address); err != nil {
+		logger(ctx).Warn("Failed to unregister the game log receiver", "session_id", session.ID, "error", err)
+	}
+	s.forget(session.ID)
+}
+
+// forget drops what is known of a session's log without telling its
+// server, for sessions whose connection was lost: a server that restarted
+// no longer sends its log, so the next rcon_tail_logs call registers the
+// receiver again.
+func (s *gameLogStore) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+// TailLogs returns the latest lines of a session's game log, first
+// registering the game log receiver with the session's server if needed.
+// Lines only arrive once the server is registered, so the first call on a
+// session returns none.
+func TailLogs(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[TailLogsParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	for _, kind := range args.Kinds {
+		if !slices.Contains(logKinds, kind) {
+			return nil, fmt.Errorf("unknown kind %q, want one of %s", kind, strings.Join(logKinds, ", "))
+		}
+	}
+	session, err := getSession(ctx, args.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	address, registered, err := gameLogs.register(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultTailLines
+	}
+	result := &TailLogsResult{SessionID: session.ID, LogAddress: address, Registered: registered}
+	result.Lines, result.LastSeq = gameLogs.tail(session.ID, args.Since, args.Kinds, min(limit, maxTailLines))
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode log lines: %w", err)
+	}
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+		StructuredContent: result,
+	}, nil
+}
+
+// logKinds lists the kinds of log lines, which rcon_tail_logs filters by.
+var logKinds = []string{gamelog.KindChat, gamelog.KindTeamChat, gamelog.KindKill,
+	gamelog.KindConnect, gamelog.KindDisconnect, gamelog.KindOther}
+
+// ReadGameLogs returns the lines kept of the game log of the session named
+// by the URI, all of them or those after the sequence number in its since
+// parameter. Unlike rcon_tail_logs it does not register the receiver with
+// the server.
+func ReadGameLogs(ctx context.Context, ss *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	u, err := url.Parse(params.URI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log URI: %w", err)
+	}
+	id := strings.TrimPrefix(u.Path, "/")
+	var since int64
+	if s := u.Query().Get("since"); s != "" {
+		if since, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid since %q, want a sequence number", s)
+		}
+	}
+	session, err := getSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := TailLogsResult{SessionID: session.ID, LogAddress: gameLogs.registered(session.ID)}
+	result.Lines, result.LastSeq = gameLogs.tail(session.ID, since, nil, 0)
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode log lines: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: params.URI, MIMEType: "application/json", Text: string(data)}},
+	}, nil
+}
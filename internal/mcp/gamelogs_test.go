@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/gamelog"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTailLogs(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Responses: config.Responses{Redact: []config.RedactRule{{Preset: "steamid"}}}})
+	registerResponseRules(serverConfig)
+	t.Cleanup(func() { registerResponseRules(&config.Config{}) })
+	var mu sync.Mutex
+	var sent []string
+	connectFakeSession(t, "cs", func(command string) string {
+		mu.Lock()
+		sent = append(sent, command)
+		mu.Unlock()
+		return ""
+	})
+	ctx := context.Background()
+	tail := func(args TailLogsParams) *TailLogsResult {
+		t.Helper()
+		res, err := TailLogs(ctx, nil, &mcp.CallToolParamsFor[TailLogsParams]{Arguments: args})
+		if err != nil {
+			t.Fatalf("TailLogs failed: %v", err)
+		}
+		return res.StructuredContent.(*TailLogsResult)
+	}
+
+	closeGameLogs()
+	if _, err := TailLogs(ctx, nil, &mcp.CallToolParamsFor[TailLogsParams]{Arguments: TailLogsParams{SessionID: "cs"}}); err == nil ||
+		!strings.Contains(err.Error(), "game_logs.listen") {
+		t.Errorf("Expected an error without a receiver, got %v", err)
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	if err := openGameLogs(config.GameLogs{Listen: "127.0.0.1:0", Advertise: "203.0.113.5:27500"}, server); err != nil {
+		t.Fatalf("openGameLogs failed: %v", err)
+	}
+	t.Cleanup(closeGameLogs)
+
+	result := tail(TailLogsParams{SessionID: "cs"})
+	if !result.Registered || result.LogAddress != "203.0.113.5:27500" || len(result.Lines) != 0 {
+		t.Errorf("Expected the receiver to be registered, got %+v", result)
+	}
+	mu.Lock()
+	if want := []string{"log on", "logaddress_add 203.0.113.5:27500"}; !slices.Equal(sent, want) {
+		t.Errorf("Expected %q to be sent, got %q", want, sent)
+	}
+	mu.Unlock()
+
+	// The fake server and the packets both come from 127.0.0.1, the only
+	// registered host, so the lines are routed to its session.
+	conn, err := net.Dial("udp", gameLogs.receiver.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	for _, line := range []string{
+		`L 10/16/2026 - 12:34:56: "Alice<2><STEAM_1:0:1><CT>" say "gg"`,
+		`L 10/16/2026 - 12:34:57: "Alice<2><STEAM_1:0:1><CT>" killed "Bob<3><STEAM_1:0:2><TERRORIST>" with "ak47"`,
+	} {
+		if _, err := conn.Write([]byte("\xff\xff\xff\xffR" + line + "\n\x00")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(result.Lines) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		result = tail(TailLogsParams{SessionID: "cs"})
+	}
+	if len(result.Lines) != 2 || result.Lines[0].Kind != gamelog.KindChat || result.Lines[0].Message != "gg" {
+		t.Fatalf("Expected the chat and kill lines, got %+v", result.Lines)
+	}
+	if line := result.Lines[0]; line.SteamID != "[STEAMID]" || strings.Contains(line.Text, "STEAM_1") {
+		t.Errorf("Expected the SteamIDs to be redacted, got %+v", line)
+	}
+	if result.Registered {
+		t.Error("Expected the receiver to be registered only once")
+	}
+
+	kills := tail(TailLogsParams{SessionID: "cs", Kinds: []string{gamelog.KindKill}})
+	if len(kills.Lines) != 1 || kills.Lines[0].Killer != "Alice" || kills.Lines[0].Weapon != "ak47" {
+		t.Errorf("Expected only the kill line, got %+v", kills.Lines)
+	}
+	if newer := tail(TailLogsParams{SessionID: "cs", Since: result.LastSeq}); len(newer.Lines) != 0 {
+		t.Errorf("Expected no lines after the last one, got %+v", newer.Lines)
+	}
+	if _, err := TailLogs(ctx, nil, &mcp.CallToolParamsFor[TailLogsParams]{Arguments: TailLogsParams{SessionID: "cs", Kinds: []string{"bogus"}}}); err == nil {
+		t.Error("Expected an unknown kind to be refused")
+	}
+
+	res, err := ReadGameLogs(ctx, nil, &mcp.ReadResourceParams{URI: gameLogsURI + "cs?since=0"})
+	if err != nil {
+		t.Fatalf("ReadGameLogs failed: %v", err)
+	}
+	var read TailLogsResult
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &read); err != nil {
+		t.Fatalf("Invalid resource content: %v", err)
+	}
+	if read.SessionID != "cs" || len(read.Lines) != 2 || read.LogAddress != "203.0.113.5:27500" {
+		t.Errorf("Expected the resource to list both lines, got %+v", read)
+	}
+
+	if _, err := Disconnect(ctx, nil, &mcp.CallToolParamsFor[DisconnectParams]{Arguments: DisconnectParams{SessionID: "cs"}}); err != nil {
+		t.Fatalf("Disconnect failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if last := sent[len(sent)-1]; last != "logaddress_del 203.0.113.5:27500" {
+		t.Errorf("Expected the receiver to be unregistered on disconnect, got %q", last)
+	}
+	if gameLogs.registered("cs") != "" {
+		t.Error("Expected the session's log to be dropped on disconnect")
+	}
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Sizes of the output the Garry's Mod Lua tools return.
+const (
+	defaultGModOutput = 4096
+	maxGModOutput     = 64 * 1024
+)
+
+// GModLuaParams represents parameters for the gmod_lua_run and
+// gmod_lua_run_cl tools
+type GModLuaParams struct {
+	SessionID  string `json:"session_id" jsonschema:"Session ID of a Garry's Mod server"`
+	Code       string `json:"code,omitempty" jsonschema:"Lua statements on one line, without semicolons, at most 500 bytes; what they print is returned, e.g. print(#player.GetAll())"`
+	Expression string `json:"expression,omitempty" jsonschema:"Lua expression whose value is returned, tables as JSON, e.g. game.GetMap(); instead of code"`
+	MaxBytes   int    `json:"max_bytes,omitempty" jsonschema:"Most bytes of output to return; longer output is cut and marked truncated (default 4096, max 65536)"`
+}
+
+// registerGModTools registers the tools of the gmod game pack, which run
+// Lua code on Garry's Mod servers, in the server or client realm, and
+// return what it prints.
+func registerGModTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name: "gmod_lua_run",
+		Description: "Run Lua code in the server realm of a Garry's Mod server with lua_run and return what it prints, " +
+			"or the value of an expression. The code runs with full access to the server; the policies may restrict it",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Run Garry's Mod server Lua",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, GModLuaRun)
+
+	addTool(server, &mcp.Tool{
+		Name: "gmod_lua_run_cl",
+		Description: "Run Lua code in the client realm of the host of a listen Garry's Mod server with lua_run_cl and return what it prints, " +
+			"or the value of an expression. Dedicated servers have no client realm. The policies may restrict the code",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Run Garry's Mod client Lua",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, GModLuaRunCl)
+}
+
+// GModLuaRun runs code, or prints the value of an expression, with
+// "lua_run". The command goes through the policies like any other, so
+// their lua_allow patterns see the code as sent.
+func GModLuaRun(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[GModLuaParams]) (*mcp.CallToolResultFor[any], error) {
+	return gmodLua(ctx, "lua_run", params.Arguments)
+}
+
+// GModLuaRunCl runs code, or prints the value of an expression, with
+// "lua_run_cl", under the same policies as GModLuaRun.
+func GModLuaRunCl(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[GModLuaParams]) (*mcp.CallToolResultFor[any], error) {
+	return gmodLua(ctx, "lua_run_cl", params.Arguments)
+}
+
+// gmodLua runs the Lua code of args with command and returns what it
+// prints, cut to the size asked for. The raw output of the result is cut
+// along with it.
+func gmodLua(ctx context.Context, command string, args GModLuaParams) (*mcp.CallToolResultFor[any], error) {
+	code, expression := strings.TrimSpace(args.Code), strings.TrimSpace(args.Expression)
+	switch {
+	case code == "" && expression == "":
+		return nil, errors.New("code or an expression is required")
+	case code != "" && expression != "":
+		return nil, errors.New("pass either code or an expression, not both")
+	case expression != "":
+		code = "local v = (" + expression + ") print(istable(v) and util.TableToJSON(v) or tostring(v))"
+	}
+	if err := game.CheckGModLua(code); err != nil {
+		return nil, err
+	}
+	limit := args.MaxBytes
+	if limit <= 0 {
+		limit = defaultGModOutput
+	}
+	limit = min(limit, maxGModOutput)
+
+	session, err := packSession(ctx, args.SessionID, game.Source)
+	if err != nil {
+		return nil, err
+	}
+	res, err := runPackCommand(ctx, session, command+" "+code, func(output string) (any, error) {
+		return game.ParseGModLua(output, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result, ok := res.StructuredContent.(*ExecuteResult); ok && len(result.Output) > limit {
+		result.Output = strings.ToValidUTF8(result.Output[:limit], "")
+	}
+	return res, nil
+}
@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestGModTools(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	connectFakeSession(t, "gmod", func(command string) string {
+		switch command {
+		case "lua_run print(#player.GetAll())":
+			return "> print(#player.GetAll())...\n3\n"
+		case "lua_run local v = (game.GetMap()) print(istable(v) and util.TableToJSON(v) or tostring(v))":
+			return "> local v = (game.GetMap()) print(istable(v) and util.TableToJSON(v) or tostring(v))...\ngm_construct\n"
+		case "lua_run_cl print(string.rep(\"a\", 100))":
+			return "> print(string.rep(\"a\", 100))...\n" + strings.Repeat("a", 100) + "\n"
+		}
+		return "> oops...\n[ERROR] lua_run:1: syntax error near <eof>\n"
+	})
+	ctx := context.Background()
+	call := func(run func(context.Context, *mcp.ServerSession, *mcp.CallToolParamsFor[GModLuaParams]) (*mcp.CallToolResultFor[any], error), args GModLuaParams) (*mcp.CallToolResultFor[any], error) {
+		args.SessionID = "gmod"
+		return run(ctx, nil, &mcp.CallToolParamsFor[GModLuaParams]{Arguments: args})
+	}
+
+	res, err := call(GModLuaRun, GModLuaParams{Code: "print(#player.GetAll())"})
+	if err != nil || res.IsError || res.StructuredContent.(*ExecuteResult).Parsed.(*game.GModLua).Output != "3" {
+		t.Fatalf("Unexpected result of code: %v, %+v", err, res)
+	}
+	res, err = call(GModLuaRun, GModLuaParams{Expression: "game.GetMap()"})
+	if err != nil || res.IsError || res.StructuredContent.(*ExecuteResult).Parsed.(*game.GModLua).Output != "gm_construct" {
+		t.Fatalf("Unexpected value of the expression: %v, %+v", err, res)
+	}
+
+	res, err = call(GModLuaRunCl, GModLuaParams{Code: `print(string.rep("a", 100))`, MaxBytes: 10})
+	if err != nil || res.IsError {
+		t.Fatalf("GModLuaRunCl failed: %v, %+v", err, res)
+	}
+	result := res.StructuredContent.(*ExecuteResult)
+	if lua := result.Parsed.(*game.GModLua); lua.Output != "aaaaaaaaaa" || !lua.Truncated {
+		t.Errorf("Expected the output cut to 10 bytes, got %+v", lua)
+	}
+	if len(result.Output) != 10 {
+		t.Errorf("Expected the raw output cut to 10 bytes, got %q", result.Output)
+	}
+
+	res, err = call(GModLuaRun, GModLuaParams{Code: "oops"})
+	if err != nil || !res.IsError || res.StructuredContent.(*ExecuteResult).ErrorCode != CodeRejected {
+		t.Errorf("Expected a Lua error to be rejected, got %v, %+v", err, res)
+	}
+
+	for _, args := range []GModLuaParams{
+		{},
+		{Code: "print(1)", Expression: "1"},
+		{Code: "print(1); quit"},
+		{Code: "print(1)\nquit"},
+		{Code: strings.Repeat("x", game.GModMaxCode+1)},
+	} {
+		if _, err := call(GModLuaRun, args); err == nil {
+			t.Errorf("Expected %+v to be refused", args)
+		}
+	}
+}
+
+func TestGModLua_Policy(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Policies: config.Policies{LuaAllow: []string{`print\(#player\.GetAll\(\)\)`}}})
+	var mu sync.Mutex
+	var sent []string
+	session := connectFakeSession(t, "gmod", func(command string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, command)
+		return "3\n"
+	})
+	ctx := context.Background()
+
+	res, err := GModLuaRun(ctx, nil, &mcp.CallToolParamsFor[GModLuaParams]{Arguments: GModLuaParams{SessionID: "gmod", Code: "print(#player.GetAll())"}})
+	if err != nil || res.IsError {
+		t.Fatalf("Expected allowed code to run, got %v, %+v", err, res)
+	}
+	res, err = GModLuaRunCl(ctx, nil, &mcp.CallToolParamsFor[GModLuaParams]{Arguments: GModLuaParams{SessionID: "gmod", Code: `RunConsoleCommand("quit")`}})
+	if err != nil || !res.IsError || res.StructuredContent.(*ExecuteResult).ErrorCode != CodePolicyDenied {
+		t.Fatalf("Expected other code to be denied, got %v, %+v", err, res)
+	}
+	// rcon_execute is held to the same patterns
+	if _, _, err := executeWithMetadata(ctx, session, `lua_run RunConsoleCommand("quit")`); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("Expected Lua sent with rcon_execute to be denied, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 1 {
+		t.Errorf("Expected only the allowed code to be sent, got %q", sent)
+	}
+}
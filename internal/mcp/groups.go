@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// groupManager holds the runtime-defined session groups used to target
+// several sessions at once.
+var groupManager = rcon.NewGroupManager()
+
+// GroupCreateParams represents parameters for the group_create tool
+type GroupCreateParams struct {
+	Name       string   `json:"name" jsonschema:"Unique name for the group"`
+	SessionIDs []string `json:"session_ids,omitempty" jsonschema:"Sessions to include in the group"`
+}
+
+// GroupMembersParams represents parameters for the group_add and group_remove tools
+type GroupMembersParams struct {
+	Name       string   `json:"name" jsonschema:"Name of the group"`
+	SessionIDs []string `json:"session_ids,omitempty" jsonschema:"Sessions to add or remove"`
+}
+
+// GroupListParams represents parameters for the group_list tool
+type GroupListParams struct{}
+
+// GroupCreate defines a new session group.
+// Returns an error if the group exists or any of the sessions is unknown to
+// the caller; see checkSessionsExist.
+func GroupCreate(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[GroupCreateParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if err := checkSessionsExist(ctx, args.SessionIDs); err != nil {
+		return nil, err
+	}
+	if err := groupManager.CreateGroup(args.Name, args.SessionIDs); err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	return textResult(fmt.Sprintf("Created group %s with %d session(s)", args.Name, len(args.SessionIDs))), nil
+}
+
+// GroupAdd adds sessions to an existing group.
+// Returns an error if the group or any of the sessions is unknown.
+func GroupAdd(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[GroupMembersParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if err := checkSessionsExist(ctx, args.SessionIDs); err != nil {
+		return nil, err
+	}
+	if err := groupManager.AddMembers(args.Name, args.SessionIDs); err != nil {
+		return nil, fmt.Errorf("failed to add to group: %w", err)
+	}
+
+	return textResult(fmt.Sprintf("Added %d session(s) to group %s", len(args.SessionIDs), args.Name)), nil
+}
+
+// GroupRemove removes sessions from a group. When no sessions are given the
+// whole group is deleted. Sessions themselves are never disconnected.
+func GroupRemove(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[GroupMembersParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if len(args.SessionIDs) == 0 {
+		if err := groupManager.DeleteGroup(args.Name); err != nil {
+			return nil, fmt.Errorf("failed to delete group: %w", err)
+		}
+		return textResult(fmt.Sprintf("Deleted group %s", args.Name)), nil
+	}
+
+	if err := groupManager.RemoveMembers(args.Name, args.SessionIDs); err != nil {
+		return nil, fmt.Errorf("failed to remove from group: %w", err)
+	}
+	return textResult(fmt.Sprintf("Removed %d session(s) from group %s", len(args.SessionIDs), args.Name)), nil
+}
+
+// GroupList lists all session groups and their members.
+// Members whose session no longer exists are marked as missing, and those
+// of sessions the caller may not use are left out.
+func GroupList(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[GroupListParams]) (*mcp.CallToolResultFor[any], error) {
+	groups := groupManager.ListGroups()
+	if len(groups) == 0 {
+		return textResult("No session groups defined"), nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Session groups:\n")
+	for _, group := range groups {
+		members := make([]string, 0, len(group.Members))
+		for _, id := range group.Members {
+			session, err := sessionManager.GetSession(id)
+			switch {
+			case err != nil:
+				id += " (missing)"
+			case !mayUse(callerKey(ctx), session):
+				continue
+			}
+			members = append(members, id)
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", group.Name, strings.Join(members, ", "))
+	}
+
+	return textResult(b.String()), nil
+}
+
+// checkSessionsExist returns an error naming any session IDs that are
+// unknown. Sessions the client making the tool call ctx belongs to may not
+// use count as unknown, so that their IDs cannot be probed.
+func checkSessionsExist(ctx context.Context, ids []string) error {
+	var missing []string
+	for _, id := range ids {
+		if _, err := getSession(ctx, id); err != nil {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("sessions not found: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// textResult wraps a message in a tool result with a single text content.
+func textResult(text string) *mcp.CallToolResultFor[any] {
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resetGroupManager resets the global group manager for testing
+func resetGroupManager() {
+	groupManager = rcon.NewGroupManager()
+}
+
+// resultText returns the text of the first content item of a tool result.
+func resultText(t *testing.T, result *mcp.CallToolResultFor[any]) string {
+	t.Helper()
+	if result == nil || len(result.Content) == 0 {
+		t.Fatal("Expected content in result")
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent type")
+	}
+	return text.Text
+}
+
+func TestGroupTools(t *testing.T) {
+	resetSessionManager()
+	resetGroupManager()
+	ctx := context.Background()
+	sessionManager.CreateSession("lobby-1", "Lobby 1", "localhost:25575")
+	sessionManager.CreateSession("lobby-2", "Lobby 2", "localhost:25576")
+
+	// Creating a group with an unknown session fails
+	_, err := GroupCreate(ctx, nil, &mcp.CallToolParamsFor[GroupCreateParams]{
+		Arguments: GroupCreateParams{Name: "lobbies", SessionIDs: []string{"lobby-1", "nope"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("Expected error naming the unknown session, got %v", err)
+	}
+
+	if _, err := GroupCreate(ctx, nil, &mcp.CallToolParamsFor[GroupCreateParams]{
+		Arguments: GroupCreateParams{Name: "lobbies", SessionIDs: []string{"lobby-1"}},
+	}); err != nil {
+		t.Fatalf("GroupCreate failed: %v", err)
+	}
+
+	if _, err := GroupAdd(ctx, nil, &mcp.CallToolParamsFor[GroupMembersParams]{
+		Arguments: GroupMembersParams{Name: "lobbies", SessionIDs: []string{"lobby-2"}},
+	}); err != nil {
+		t.Fatalf("GroupAdd failed: %v", err)
+	}
+
+	// A member whose session goes away is reported as missing
+	sessionManager.RemoveSession("lobby-2")
+	result, err := GroupList(ctx, nil, &mcp.CallToolParamsFor[GroupListParams]{})
+	if err != nil {
+		t.Fatalf("GroupList failed: %v", err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "- lobbies: lobby-1, lobby-2 (missing)") {
+		t.Errorf("Unexpected group listing:\n%s", text)
+	}
+
+	if _, err := GroupRemove(ctx, nil, &mcp.CallToolParamsFor[GroupMembersParams]{
+		Arguments: GroupMembersParams{Name: "lobbies", SessionIDs: []string{"lobby-2"}},
+	}); err != nil {
+		t.Fatalf("GroupRemove failed: %v", err)
+	}
+	group, _ := groupManager.GetGroup("lobbies")
+	if len(group.Members) != 1 {
+		t.Errorf("Expected 1 member after removal, got %v", group.Members)
+	}
+
+	// Removing without session IDs deletes the group
+	if _, err := GroupRemove(ctx, nil, &mcp.CallToolParamsFor[GroupMembersParams]{
+		Arguments: GroupMembersParams{Name: "lobbies"},
+	}); err != nil {
+		t.Fatalf("GroupRemove failed: %v", err)
+	}
+	result, _ = GroupList(ctx, nil, &mcp.CallToolParamsFor[GroupListParams]{})
+	if text := resultText(t, result); text != "No session groups defined" {
+		t.Errorf("Expected no groups, got %q", text)
+	}
+}
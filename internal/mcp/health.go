@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+)
+
+// Network transports answer liveness and readiness probes, such as those
+// of Kubernetes, at config.HealthPath and config.ReadyPath. The probes
+// bypass the transport's IP lists, since they come from the orchestrator
+// rather than from MCP clients, and so reveal no more than whether the
+// server is up and how many preloaded sessions are down.
+
+// serveHealth answers liveness probes: the server is alive as long as it
+// answers HTTP requests.
+func serveHealth(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// serveReady returns the handler answering readiness probes, which fail
+// with 503 Service Unavailable while the server is not ready; see ready.
+func serveReady(transport config.Transport) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if err := ready(transport); err != nil {
+			http.Error(w, "not ready: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+// ready returns why the server is not ready to serve clients, or nil if
+// it is. With the transport's ReadySessions every preloaded session must
+// be connected and authenticated; otherwise a serving server is ready.
+func ready(transport config.Transport) error {
+	if !transport.ReadySessions {
+		return nil
+	}
+	down := 0
+	for _, s := range serverConfig.Sessions {
+		session, err := sessionManager.GetSession(s.ID)
+		if err != nil || !session.Client.IsConnected() || !session.Client.IsAuthenticated() {
+			down++
+		}
+	}
+	if down > 0 {
+		return fmt.Errorf("%d of %d preloaded sessions are not connected", down, len(serverConfig.Sessions))
+	}
+	return nil
+}
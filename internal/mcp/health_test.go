@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// probe sends a GET request for path to handler and returns the status
+// and body of the response.
+func probe(t *testing.T, handler http.Handler, path string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = "192.0.2.1:4000"
+	handler.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.String()
+}
+
+func TestHealthProbes(t *testing.T) {
+	resetSessionManager()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	transport := config.Transport{Type: config.TransportHTTP, Allow: []string{"10.0.0.0/8"}, ReadySessions: true}
+	setServerConfig(t, &config.Config{Transport: transport, Sessions: []*config.Session{{ID: "prod"}, {ID: "test"}}})
+	handler, err := httpHandler(server, transport)
+	if err != nil {
+		t.Fatalf("httpHandler failed: %v", err)
+	}
+
+	if code, body := probe(t, handler, config.HealthPath); code != http.StatusOK || body != "ok\n" {
+		t.Errorf("Expected the liveness probe to pass despite the allow list, got %d %q", code, body)
+	}
+	if code, _ := probe(t, handler, config.DefaultPath); code != http.StatusForbidden {
+		t.Errorf("Expected the MCP endpoint to stay behind the allow list, got %d", code)
+	}
+
+	code, body := probe(t, handler, config.ReadyPath)
+	if code != http.StatusServiceUnavailable || !strings.Contains(body, "2 of 2 preloaded sessions") {
+		t.Errorf("Expected readiness to fail without the preloaded sessions, got %d %q", code, body)
+	}
+	connectFakeSession(t, "prod", func(string) string { return "ok" })
+	session := connectFakeSession(t, "test", func(string) string { return "ok" })
+	if code, body := probe(t, handler, config.ReadyPath); code != http.StatusOK {
+		t.Errorf("Expected readiness once the preloaded sessions are connected, got %d %q", code, body)
+	}
+	session.Client.Disconnect()
+	code, body = probe(t, handler, config.ReadyPath)
+	if code != http.StatusServiceUnavailable || !strings.Contains(body, "1 of 2 preloaded sessions") {
+		t.Errorf("Expected readiness to fail once a preloaded session is down, got %d %q", code, body)
+	}
+
+	transport.ReadySessions = false
+	if err := ready(transport); err != nil {
+		t.Errorf("Expected a server not requiring its sessions to be ready, got %v", err)
+	}
+}
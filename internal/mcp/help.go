@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// HelpParams represents parameters for the help tool
+type HelpParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID whose server help to search"`
+	Query     string `json:"query,omitempty" jsonschema:"Command name or keyword to look up (optional, lists every command when empty)"`
+	Refresh   bool   `json:"refresh,omitempty" jsonschema:"Re-run the server's help command instead of using the cached result"`
+}
+
+// HelpResult is the structured content returned by the help tool.
+type HelpResult struct {
+	HelpCommand string           `json:"help_command"` // Native command the index was built from
+	Cached      bool             `json:"cached"`       // Whether the index came from the cache
+	Total       int              `json:"total"`        // Commands in the index
+	Matches     []game.HelpEntry `json:"matches"`      // Entries matching the query, best first
+}
+
+// Help answers questions about the commands a server supports. The game's
+// native help command is run once per session and the parsed result is
+// cached, so later lookups are answered without contacting the server.
+func Help(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[HelpParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+
+	session, err := getSession(ctx, args.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := session.Help()
+	cached := index != nil && !args.Refresh
+	if !cached {
+		if index, err = fetchHelp(ctx, session); err != nil {
+			return nil, err
+		}
+		session.SetHelp(index)
+	}
+
+	result := &HelpResult{
+		HelpCommand: index.Command,
+		Cached:      cached,
+		Total:       len(index.Entries),
+		Matches:     game.SearchHelp(index.Entries, args.Query),
+	}
+	if result.Matches == nil {
+		result.Matches = []game.HelpEntry{}
+	}
+
+	var b strings.Builder
+	switch {
+	case len(result.Matches) == 0:
+		fmt.Fprintf(&b, "No commands matching %q among %d commands from %q", args.Query, result.Total, index.Command)
+	case args.Query == "":
+		fmt.Fprintf(&b, "%d commands available (from %q):\n", result.Total, index.Command)
+	default:
+		fmt.Fprintf(&b, "%d of %d commands match %q:\n", len(result.Matches), result.Total, args.Query)
+	}
+	for _, e := range result.Matches {
+		fmt.Fprintf(&b, "- %s\n", e.Usage)
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: b.String()}},
+		StructuredContent: result,
+	}, nil
+}
+
+// fetchHelp runs the session's help commands in turn and indexes the first
+// output that lists any commands.
+func fetchHelp(ctx context.Context, session *rcon.Session) (*game.HelpIndex, error) {
+	var errs []error
+	for _, command := range game.HelpCommands(session.Game()) {
+		output, meta, err := executeWithMetadata(ctx, session, command)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run %q: %w", command, err)
+		}
+		if meta.Rejected {
+			errs = append(errs, fmt.Errorf("%q was rejected by the server", command))
+			continue
+		}
+		entries := game.ParseHelp(session.Game(), output)
+		if len(entries) == 0 {
+			errs = append(errs, fmt.Errorf("%q listed no commands", command))
+			continue
+		}
+		return &game.HelpIndex{Command: command, Entries: entries}, nil
+	}
+	return nil, fmt.Errorf("no help command worked on this server: %w", errors.Join(errs...))
+}
@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHelp(t *testing.T) {
+	resetSessionManager()
+	var helpCalls atomic.Int32
+	session := connectFakeSession(t, "help", func(command string) string {
+		switch command {
+		case "help":
+			helpCalls.Add(1)
+			return "/ban <targets> [<reason>]/ban-ip <target>/banlist/kick <targets>/list"
+		case "?":
+			return "Unknown command"
+		}
+		return ""
+	})
+	session.SetGame(game.Minecraft)
+
+	tests := []struct {
+		name        string
+		args        HelpParams
+		wantMatches []string
+		wantCached  bool
+		wantCalls   int32
+	}{
+		{name: "first lookup runs help", args: HelpParams{SessionID: "help", Query: "ban"}, wantMatches: []string{"ban", "ban-ip", "banlist"}, wantCalls: 1},
+		{name: "later lookups use the cache", args: HelpParams{SessionID: "help", Query: "kick"}, wantMatches: []string{"kick"}, wantCached: true, wantCalls: 1},
+		{name: "empty query lists everything", args: HelpParams{SessionID: "help"}, wantMatches: []string{"ban", "ban-ip", "banlist", "kick", "list"}, wantCached: true, wantCalls: 1},
+		{name: "refresh runs help again", args: HelpParams{SessionID: "help", Query: "list", Refresh: true}, wantMatches: []string{"list", "banlist"}, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Help(context.Background(), nil, &mcp.CallToolParamsFor[HelpParams]{Arguments: tt.args})
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			help := result.StructuredContent.(*HelpResult)
+
+			var names []string
+			for _, e := range help.Matches {
+				names = append(names, e.Command)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantMatches, ",") {
+				t.Errorf("Expected matches %v, got %v", tt.wantMatches, names)
+			}
+			if help.Cached != tt.wantCached {
+				t.Errorf("Expected cached %v, got %v", tt.wantCached, help.Cached)
+			}
+			if got := helpCalls.Load(); got != tt.wantCalls {
+				t.Errorf("Expected %d help calls, got %d", tt.wantCalls, got)
+			}
+		})
+	}
+}
+
+func TestHelp_Fallback(t *testing.T) {
+	resetSessionManager()
+	session := connectFakeSession(t, "factorio", func(command string) string {
+		switch command {
+		case "/help":
+			return "Unknown command"
+		case "help":
+			return "Available commands:\n/admins - Prints a list of game admins.\n/ban <player> <reason> - Bans the specified player."
+		}
+		return ""
+	})
+	session.SetGame(game.Factorio)
+
+	result, err := Help(context.Background(), nil, &mcp.CallToolParamsFor[HelpParams]{Arguments: HelpParams{SessionID: "factorio"}})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if help := result.StructuredContent.(*HelpResult); help.HelpCommand != "help" || help.Total != 2 {
+		t.Errorf("Expected index from the generic help command, got %+v", help)
+	}
+}
+
+func TestHelp_NoHelpAvailable(t *testing.T) {
+	resetSessionManager()
+	connectFakeSession(t, "silent", func(string) string { return "" })
+
+	_, err := Help(context.Background(), nil, &mcp.CallToolParamsFor[HelpParams]{Arguments: HelpParams{SessionID: "silent"}})
+	if err == nil || !strings.Contains(err.Error(), "no help command worked") {
+		t.Errorf("Expected no help error, got %v", err)
+	}
+}
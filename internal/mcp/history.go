@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultHistoryLimit is the number of entries returned when no limit is given.
+const defaultHistoryLimit = 50
+
+// HistoryParams represents parameters for the history tool
+type HistoryParams struct {
+	SessionID  string `json:"session_id,omitempty" jsonschema:"Session ID to search (optional, defaults to all sessions)"`
+	Contains   string `json:"contains,omitempty" jsonschema:"Case-insensitive text the command must contain (optional)"`
+	Regex      string `json:"regex,omitempty" jsonschema:"Regular expression the command must match (optional)"`
+	Since      string `json:"since,omitempty" jsonschema:"Only commands at or after this RFC 3339 time or duration ago such as 24h (optional)"`
+	Until      string `json:"until,omitempty" jsonschema:"Only commands at or before this RFC 3339 time or duration ago such as 1h (optional)"`
+	ErrorsOnly bool   `json:"errors_only,omitempty" jsonschema:"Only return commands that failed (optional)"`
+	Limit      int    `json:"limit,omitempty" jsonschema:"Maximum number of entries to return, newest first (default 50)"`
+}
+
+// HistoryResult is one matching history entry together with its session.
+type HistoryResult struct {
+	SessionID string `json:"session_id"`
+	rcon.HistoryEntry
+}
+
+// SearchHistory searches the commands previously executed on one or all
+// sessions and returns the matches, newest first.
+func SearchHistory(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[HistoryParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+
+	filter, err := historyFilter(args, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*rcon.Session
+	if args.SessionID != "" {
+		session, err := getSession(ctx, args.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		sessions = []*rcon.Session{session}
+	} else {
+		sessions = visibleSessions(ctx)
+	}
+
+	results := []HistoryResult{}
+	for _, session := range sessions {
+		for _, entry := range session.History.Search(filter) {
+			results = append(results, HistoryResult{SessionID: session.ID, HistoryEntry: entry})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Time.After(results[j].Time)
+	})
+	if len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+
+	if len(results) == 0 {
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: "No matching commands in history"}},
+			StructuredContent: map[string]any{"entries": results},
+		}, nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Found %d matching command(s):\n", len(results))
+	for _, r := range results {
+		fmt.Fprintf(&b, "- %s [%s] %s", r.Time.Format(time.RFC3339), r.SessionID, r.Command)
+		if r.Failed() {
+			fmt.Fprintf(&b, " - error: %s", r.Error)
+		}
+		b.WriteString("\n")
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: b.String()}},
+		StructuredContent: map[string]any{"entries": results},
+	}, nil
+}
+
+// historyFilter builds a history filter from tool arguments. Relative
+// times are resolved against now.
+func historyFilter(args HistoryParams, now time.Time) (rcon.HistoryFilter, error) {
+	filter := rcon.HistoryFilter{
+		Contains:   args.Contains,
+		ErrorsOnly: args.ErrorsOnly,
+		Limit:      args.Limit,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultHistoryLimit
+	}
+
+	if args.Regex != "" {
+		pattern, err := regexp.Compile(args.Regex)
+		if err != nil {
+			return filter, fmt.Errorf("invalid regex: %w", err)
+		}
+		filter.Pattern = pattern
+	}
+
+	var err error
+	if filter.Since, err = parseHistoryTime(args.Since, now); err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.Until, err = parseHistoryTime(args.Until, now); err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+	return filter, nil
+}
+
+// parseHistoryTime accepts an RFC 3339 timestamp or a duration that is
+// subtracted from now. An empty string yields the zero time.
+func parseHistoryTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a duration", value)
+	}
+	return now.Add(-d), nil
+}
@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSearchHistory(t *testing.T) {
+	resetSessionManager()
+	respond := func(command string) string {
+		if command == "ban Broken" {
+			return fakeMismatchReply
+		}
+		return "ok"
+	}
+	alpha := connectFakeSession(t, "alpha", respond)
+	beta := connectFakeSession(t, "beta", respond)
+
+	for _, command := range []string{"ban Griefer", "say hi", "ban Broken"} {
+		executeWithMetadata(context.Background(), alpha, command)
+	}
+	executeWithMetadata(context.Background(), beta, "ban Spammer")
+
+	tests := []struct {
+		name        string
+		args        HistoryParams
+		wantCount   int
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:      "all sessions",
+			args:      HistoryParams{Contains: "ban"},
+			wantCount: 3,
+		},
+		{
+			name:      "single session",
+			args:      HistoryParams{SessionID: "beta"},
+			wantCount: 1,
+		},
+		{
+			name:      "errors only",
+			args:      HistoryParams{ErrorsOnly: true},
+			wantCount: 1,
+		},
+		{
+			name:      "regex and limit",
+			args:      HistoryParams{Regex: `^ban `, Limit: 2},
+			wantCount: 2,
+		},
+		{
+			name:      "since excludes everything in the future",
+			args:      HistoryParams{Since: time.Now().Add(time.Hour).Format(time.RFC3339)},
+			wantCount: 0,
+		},
+		{
+			name:        "unknown session",
+			args:        HistoryParams{SessionID: "missing"},
+			wantErr:     true,
+			errContains: "not found",
+		},
+		{
+			name:        "invalid regex",
+			args:        HistoryParams{Regex: "("},
+			wantErr:     true,
+			errContains: "invalid regex",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &mcp.CallToolParamsFor[HistoryParams]{Arguments: tt.args}
+			result, err := SearchHistory(context.Background(), nil, params)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			entries := result.StructuredContent.(map[string]any)["entries"].([]HistoryResult)
+			if len(entries) != tt.wantCount {
+				t.Errorf("Expected %d entries, got %d: %+v", tt.wantCount, len(entries), entries)
+			}
+			for i := 1; i < len(entries); i++ {
+				if entries[i].Time.After(entries[i-1].Time) {
+					t.Errorf("Expected newest first, got %v before %v", entries[i-1].Time, entries[i].Time)
+				}
+			}
+		})
+	}
+}
+
+func TestParseHistoryTime(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "", want: time.Time{}},
+		{value: "24h", want: now.Add(-24 * time.Hour)},
+		{value: "2025-05-31T08:00:00Z", want: time.Date(2025, 5, 31, 8, 0, 0, 0, time.UTC)},
+		{value: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseHistoryTime(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHistoryTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/version"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Invoke calls the tool name with arguments on the server cfg configures,
+// as an MCP client would but within the process: the server is built as
+// Serve builds it, the sessions cfg declares are opened, and the call goes
+// through an in-memory transport, so arguments are validated against the
+// tool's schema and the result is the CallToolResult a client receives. The
+// sessions are closed before it returns.
+//
+// It lets handlers be debugged without an MCP client. Like NewServer, it
+// replaces the process-wide configuration and session manager.
+func Invoke(ctx context.Context, cfg *config.Config, name string, arguments map[string]any) (*mcp.CallToolResult, error) {
+	server, err := NewServer(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeAudit()
+	defer closeGameLogs()
+	preloadSessions(serverConfig.Sessions)
+	defer func() {
+		if err := sessionManager.DisconnectAll(); err != nil {
+			logger(ctx).Warn("Failed to disconnect all sessions cleanly", "error", err)
+		}
+	}()
+
+	// Wait for the client to be torn down before the sessions are closed.
+	defer clients.wait()
+
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the server: %w", err)
+	}
+	defer ss.Close()
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "rcon-mcp-server-invoke", Version: version.Get().Version}, nil).Connect(ctx, clientTransport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the server: %w", err)
+	}
+	defer cs.Close()
+
+	return cs.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: arguments})
+}
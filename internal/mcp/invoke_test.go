@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestInvoke(t *testing.T) {
+	address := startFakeRCONServerWithPassword(t, "secret", func(command string) string {
+		return "ran " + command
+	})
+	setServerConfig(t, serverConfig)
+	t.Cleanup(resetSessionManager)
+	cfg := &config.Config{Sessions: []*config.Session{{ID: "x", Address: address, Password: "secret"}}}
+
+	res, err := Invoke(context.Background(), cfg, "rcon_execute", map[string]any{"session_id": "x", "command": "list"})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("Expected a successful result, got %+v", res)
+	}
+	text, ok := res.Content[0].(*mcp.TextContent)
+	if !ok || !strings.Contains(text.Text, "ran list") {
+		t.Errorf("Expected the command output in the result, got %+v", res.Content)
+	}
+	if _, err := json.Marshal(res); err != nil {
+		t.Errorf("Expected the result to marshal, got %v", err)
+	}
+	if len(sessionManager.ListSessions()) != 0 {
+		t.Errorf("Expected the preloaded session to be closed, %d remain", len(sessionManager.ListSessions()))
+	}
+
+	// The session is gone once Invoke returns
+	res, err = Invoke(context.Background(), &config.Config{}, "rcon_execute", map[string]any{"session_id": "x", "command": "list"})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if !res.IsError {
+		t.Errorf("Expected an error result for an unknown session, got %+v", res)
+	}
+
+	if _, err := Invoke(context.Background(), &config.Config{}, "rcon_no_such_tool", nil); err == nil {
+		t.Error("Expected an error for an unknown tool")
+	}
+	if _, err := Invoke(context.Background(), &config.Config{}, "rcon_execute", map[string]any{"session_id": 1}); err == nil {
+		t.Error("Expected an error for arguments the schema refuses")
+	}
+}
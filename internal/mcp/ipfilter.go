@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"net/http"
+	"net/netip"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+)
+
+// ipFilter is HTTP middleware that only lets through requests from client
+// addresses the transport's allow and deny lists permit. Like the command
+// policies, deny wins over allow and a non-empty allow list refuses every
+// other address.
+type ipFilter struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+	next  http.Handler
+}
+
+// filterIPs wraps next in an ipFilter for the transport's lists, or
+// returns it unchanged if both are empty.
+func filterIPs(transport config.Transport, next http.Handler) (http.Handler, error) {
+	allow, err := config.ParsePrefixes(transport.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := config.ParsePrefixes(transport.Deny)
+	if err != nil {
+		return nil, err
+	}
+	if len(allow) == 0 && len(deny) == 0 {
+		return next, nil
+	}
+	return &ipFilter{allow: allow, deny: deny, next: next}, nil
+}
+
+// ServeHTTP implements http.Handler. The client address is the peer of
+// the connection; headers such as X-Forwarded-For are not trusted.
+func (f *ipFilter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil || !f.permits(addrPort.Addr().Unmap()) {
+		logger(r.Context()).Warn("Refused MCP client by IP", "remote_addr", r.RemoteAddr)
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	f.next.ServeHTTP(w, r)
+}
+
+// permits reports whether requests from addr are let through.
+func (f *ipFilter) permits(addr netip.Addr) bool {
+	for _, p := range f.deny {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, p := range f.allow {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHTTPHandler_IPFilter(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	handler, err := httpHandler(server, config.Transport{
+		Type:  config.TransportHTTP,
+		Allow: []string{"10.8.0.0/24", "2001:db8::/32"},
+		Deny:  []string{"10.8.0.13"},
+	})
+	if err != nil {
+		t.Fatalf("httpHandler failed: %v", err)
+	}
+
+	tests := []struct {
+		remoteAddr string
+		allowed    bool
+	}{
+		{"10.8.0.5:50000", true},
+		{"[::ffff:10.8.0.5]:50000", true},
+		{"[2001:db8::1]:50000", true},
+		{"10.8.0.13:50000", false},
+		{"10.8.1.5:50000", false},
+		{"127.0.0.1:50000", false},
+		{"garbage", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/elsewhere", nil)
+		req.RemoteAddr = tt.remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		// Permitted requests reach the mux and miss the MCP endpoint.
+		want := http.StatusForbidden
+		if tt.allowed {
+			want = http.StatusNotFound
+		}
+		if rec.Code != want {
+			t.Errorf("%s: expected status %d, got %d", tt.remoteAddr, want, rec.Code)
+		}
+	}
+
+	if _, err := httpHandler(server, config.Transport{Type: config.TransportHTTP, Deny: []string{"10.8.0.0/33"}}); err == nil {
+		t.Error("Expected an error for an invalid range")
+	}
+}
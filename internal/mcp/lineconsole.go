@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LineConsoleParams represents parameters for the console_players tool
+type LineConsoleParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a server with a line console, such as Unturned or Eco"`
+	Game      string `json:"game,omitempty" jsonschema:"Game whose console templates to use, e.g. unturned; defaults to the game of the session's profile"`
+}
+
+// LineConsoleBroadcastParams represents parameters for the
+// console_broadcast tool
+type LineConsoleBroadcastParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a server with a line console, such as Unturned or Eco"`
+	Game      string `json:"game,omitempty" jsonschema:"Game whose console templates to use, e.g. unturned; defaults to the game of the session's profile"`
+	Message   string `json:"message" jsonschema:"Message to show to every player, on one line"`
+}
+
+// registerLineConsoleTools registers the tools of the console game pack,
+// which list the players of and broadcast to games whose RCON is a thin
+// line console, running the commands the config's console templates give
+// for the game.
+func registerLineConsoleTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "console_players",
+		Description: "Run the players command of a line-console game such as Unturned or Eco, as its console templates give it, and return the players online",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List line-console players",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, LineConsolePlayers)
+
+	addTool(server, &mcp.Tool{
+		Name:        "console_broadcast",
+		Description: "Show a message to every player of a line-console game such as Unturned or Eco with the broadcast command its console templates give",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Broadcast on a line console",
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, LineConsoleBroadcast)
+}
+
+// LineConsolePlayers runs the players command of the session's game and
+// returns the players its player pattern finds in the reply.
+func LineConsolePlayers(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[LineConsoleParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	session, templates, err := lineConsoleSession(ctx, args.SessionID, args.Game)
+	if err != nil {
+		return nil, err
+	}
+	if templates.Players == "" {
+		return nil, errors.New("the console templates of this game have no players command")
+	}
+	pattern, err := templates.PlayerRegexp()
+	if err != nil {
+		return nil, fmt.Errorf("invalid player_pattern: %w", err)
+	}
+	return runPackCommand(ctx, session, templates.Players, func(output string) (any, error) {
+		return game.ParseConsolePlayers(output, pattern), nil
+	})
+}
+
+// LineConsoleBroadcast runs the broadcast command of the session's game
+// with the message in place of its placeholder.
+func LineConsoleBroadcast(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[LineConsoleBroadcastParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	message := strings.TrimSpace(args.Message)
+	if !game.ValidConsoleMessage(message) {
+		return nil, fmt.Errorf("invalid message %q: want text on one line", args.Message)
+	}
+	session, templates, err := lineConsoleSession(ctx, args.SessionID, args.Game)
+	if err != nil {
+		return nil, err
+	}
+	if templates.Broadcast == "" {
+		return nil, errors.New("the console templates of this game have no broadcast command")
+	}
+	command := strings.ReplaceAll(templates.Broadcast, config.MessagePlaceholder, message)
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParseConsoleBroadcast(output), nil
+	})
+}
+
+// lineConsoleSession returns the session a console pack tool was called on
+// and the console templates of its game: name if set, else the game of the
+// session. A session detected or configured as another game is refused.
+func lineConsoleSession(ctx context.Context, id, name string) (*rcon.Session, config.Console, error) {
+	session, err := getSession(ctx, id)
+	if err != nil {
+		return nil, config.Console{}, err
+	}
+	t := session.Game()
+	switch {
+	case name == "" && t == game.Unknown:
+		return nil, config.Console{}, fmt.Errorf("the game of session %s is unknown; pass the game whose console templates to use", id)
+	case name == "":
+		name = string(t)
+	case t != game.Unknown && string(t) != name:
+		return nil, config.Console{}, fmt.Errorf("session %s is a %s server, not %s", id, t, name)
+	}
+	templates, err := serverConfig.Console(name)
+	if err != nil {
+		return nil, config.Console{}, err
+	}
+	return session, templates, nil
+}
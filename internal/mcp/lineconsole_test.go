@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestLineConsoleTools(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Consoles: map[string]config.Console{
+		"mygame": {Players: "who", PlayerPattern: `^- (?P<name>.+)$`, Broadcast: "shout {message}"},
+	}})
+	var mu sync.Mutex
+	var sent []string
+	respond := func(command string) string {
+		mu.Lock()
+		sent = append(sent, command)
+		mu.Unlock()
+		switch command {
+		case "players":
+			return "Players: 1/24\n76561198000000001 Alice\n"
+		case "who":
+			return "Online:\n- bob\n- carol\n"
+		}
+		return ""
+	}
+	connectFakeSession(t, "unturned", respond).SetGame("unturned")
+	connectFakeSession(t, "custom", respond)
+	connectFakeSession(t, "mc", respond).SetGame(game.Minecraft)
+	ctx := context.Background()
+
+	res, err := LineConsolePlayers(ctx, nil, &mcp.CallToolParamsFor[LineConsoleParams]{Arguments: LineConsoleParams{SessionID: "unturned"}})
+	if err != nil || res.IsError {
+		t.Fatalf("LineConsolePlayers failed: %v, %+v", err, res)
+	}
+	if list := res.StructuredContent.(*ExecuteResult).Parsed.(*game.PlayerList); list.Online != 1 || list.Players[0] != "Alice" {
+		t.Errorf("Unexpected players with the built-in templates: %+v", list)
+	}
+	res, err = LineConsolePlayers(ctx, nil, &mcp.CallToolParamsFor[LineConsoleParams]{Arguments: LineConsoleParams{SessionID: "custom", Game: "mygame"}})
+	if err != nil || res.IsError {
+		t.Fatalf("LineConsolePlayers failed: %v, %+v", err, res)
+	}
+	if list := res.StructuredContent.(*ExecuteResult).Parsed.(*game.PlayerList); list.Online != 2 || list.Players[1] != "carol" {
+		t.Errorf("Unexpected players with configured templates: %+v", list)
+	}
+
+	res, err = LineConsoleBroadcast(ctx, nil, &mcp.CallToolParamsFor[LineConsoleBroadcastParams]{Arguments: LineConsoleBroadcastParams{SessionID: "custom", Game: "mygame", Message: "Restart in 5 minutes"}})
+	if err != nil || res.IsError || !res.StructuredContent.(*ExecuteResult).Parsed.(*game.Change).Changed {
+		t.Fatalf("LineConsoleBroadcast failed: %v, %+v", err, res)
+	}
+
+	for _, args := range []LineConsoleBroadcastParams{
+		{SessionID: "custom", Game: "mygame", Message: "hi\nquit"},
+		{SessionID: "custom", Message: "hi"},
+		{SessionID: "custom", Game: "nope", Message: "hi"},
+		{SessionID: "mc", Message: "hi"},
+		{SessionID: "unturned", Game: "eco", Message: "hi"},
+	} {
+		if _, err := LineConsoleBroadcast(ctx, nil, &mcp.CallToolParamsFor[LineConsoleBroadcastParams]{Arguments: args}); err == nil {
+			t.Errorf("Expected %+v to be refused", args)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"players", "who", "shout Restart in 5 minutes"}; len(sent) != len(want) || sent[2] != want[2] {
+		t.Errorf("Sent %q, want %q", sent, want)
+	}
+}
@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/logging"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+)
+
+// baseLogger is the logger the server writes to, nil for the default
+// logger.
+var baseLogger atomic.Pointer[slog.Logger]
+
+// SetLogger makes the server log to l instead of the default logger, so
+// that programs embedding it, and tests, can capture its messages. Secrets
+// are masked as they are in the default logger. A nil l restores the
+// default logger.
+func SetLogger(l *slog.Logger) {
+	if l != nil {
+		l = slog.New(redact.Default.Handler(l.Handler()))
+	}
+	baseLogger.Store(l)
+}
+
+// logger returns the logger for work done on behalf of ctx: the one of the
+// tool call ctx belongs to, which carries the tool name, request ID and
+// client ID, or else the server's logger.
+func logger(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, baseLogger.Load())
+}
+
+// toolContext returns a copy of ctx carrying a fresh request ID and a
+// logger for one call of the named tool. The request ID correlates the
+// call's log messages, audit entries, events, history entries and packet
+// traces.
+func toolContext(ctx context.Context, tool string) context.Context {
+	id := logging.RequestID()
+	ctx = logging.WithRequestID(ctx, id)
+	args := []any{"tool", tool, "request_id", id}
+	if state, ok := ctx.Value(clientKey{}).(*clientState); ok && state.ID() != "" {
+		args = append(args, "client_id", state.ID())
+	}
+	return logging.NewContext(ctx, logger(ctx).With(args...))
+}
@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// syncBuffer is a bytes.Buffer safe for the concurrent writes of a logger.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSetLogger(t *testing.T) {
+	resetSessionManager()
+	var out syncBuffer
+	SetLogger(slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { SetLogger(nil) })
+
+	address := startFakeRCONServerWithPassword(t, "log-secret", func(string) string { return "ok" })
+	cs := connectTestClient(t)
+	ctx := context.Background()
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "rcon_connect", Arguments: map[string]any{
+		"session_id": "logged", "address": address, "password": "log-secret",
+	}}); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer sessionManager.DisconnectAll()
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "rcon_execute", Arguments: map[string]any{
+		"session_id": "logged", "command": "list",
+	}}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	records := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected JSON log lines, got %q", line)
+		}
+		records[record["msg"].(string)] = record
+	}
+	connected, executed := records["RCON session connected"], records["Executed RCON command"]
+	if connected["tool"] != "rcon_connect" || connected["session_id"] != "logged" {
+		t.Errorf("Expected the connect message to carry the tool and session, got %v", connected)
+	}
+	if executed["tool"] != "rcon_execute" || executed["request_id"] == nil || executed["request_id"] == connected["request_id"] {
+		t.Errorf("Expected the execute message to carry the tool and its own request ID, got %v", executed)
+	}
+	if finished := records["Tool call finished"]; finished["request_id"] != executed["request_id"] {
+		t.Errorf("Expected the tool call and its command to share a request ID, got %v", finished)
+	}
+	if strings.Contains(out.String(), "log-secret") {
+		t.Errorf("Expected the password to be masked in the injected logger, got:\n%s", out.String())
+	}
+}
+
+func TestRequestIDCorrelation(t *testing.T) {
+	resetSessionManager()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := openAudit(config.Audit{File: path}); err != nil {
+		t.Fatalf("openAudit failed: %v", err)
+	}
+	t.Cleanup(closeAudit)
+	var out syncBuffer
+	SetLogger(slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { SetLogger(nil) })
+
+	address := startFakeRCONServer(t, func(string) string { return "ok" })
+	cs := connectTestClient(t)
+	if msg := callConnect(t, cs, "traced", address); msg != "" {
+		t.Fatalf("Connect failed: %s", msg)
+	}
+	defer sessionManager.DisconnectAll()
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "rcon_execute", Arguments: map[string]any{
+		"session_id": "traced", "command": "list",
+	}})
+	if err != nil || res.IsError {
+		t.Fatalf("Execute failed: %v, %v", res, err)
+	}
+	id, _ := res.Meta["request_id"].(string)
+	if id == "" {
+		t.Fatalf("Expected the request ID in the result's _meta, got %v", res.Meta)
+	}
+
+	traced := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected JSON log lines, got %q", line)
+		}
+		if record["request_id"] == id {
+			traced[record["msg"].(string)] = true
+		}
+	}
+	for _, msg := range []string{"Sent RCON packet", "Received RCON packet", "Executed RCON command", "Tool call finished"} {
+		if !traced[msg] {
+			t.Errorf("Expected %q to be logged with request ID %s, got:\n%s", msg, id, out.String())
+		}
+	}
+
+	entries, err := audit.Read(path, func(e audit.Entry) bool { return e.RequestID == id })
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Command != "list" {
+		t.Errorf("Expected the execute audit entry to carry the request ID, got %+v", entries)
+	}
+	session, _ := sessionManager.GetSession("traced")
+	if history := session.History.Search(rcon.HistoryFilter{}); len(history) != 1 || history[0].RequestID != id {
+		t.Errorf("Expected the history entry to carry the request ID, got %+v", history)
+	}
+}
@@ -0,0 +1,183 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/logging"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+)
+
+// ExecuteResult is the structured content returned by rcon_execute.
+type ExecuteResult struct {
+	Output    string            `json:"output"`               // Raw console output
+	Parsed    any               `json:"parsed,omitempty"`     // Parsed output, when requested and available
+	Error     string            `json:"error,omitempty"`      // Why the command failed, if it did
+	ErrorCode ErrorCode         `json:"error_code,omitempty"` // Class of the failure, if the command failed
+	Metadata  ExecutionMetadata `json:"metadata"`
+}
+
+// errRejected is recorded for commands whose output shows that the server
+// refused to run them.
+const errRejected = "rejected by server"
+
+// ExecutionMetadata describes how a command execution went, so that agents
+// can reason about slow or flaky servers.
+type ExecutionMetadata struct {
+	LatencyMillis  int64  `json:"latency_ms"`             // Round-trip time of the command
+	Bytes          int    `json:"bytes"`                  // Size of the response body
+	Truncated      bool   `json:"truncated"`              // Response filled a whole packet and may be incomplete
+	Rejected       bool   `json:"rejected"`               // Output is the server refusing the command
+	SessionState   string `json:"session_state"`          // Session status after the command ran
+	Retries        int    `json:"retries"`                // Number of times the command was retried
+	Cached         bool   `json:"cached,omitempty"`       // Output is a cached response; the command was not sent
+	CacheAgeMillis int64  `json:"cache_age_ms,omitempty"` // Age of the cached response
+}
+
+// executeWithMetadata runs a command on a session and measures it. The
+// command is abandoned as soon as ctx is cancelled. The metadata is filled
+// in even when the command fails, and the command is recorded in the
+// session's history. The response is rewritten by the configured
+// redaction rules; only the audit log keeps it as sent. A server refusing
+// the command is not an error here; it is flagged in the metadata so
+// callers can keep its output.
+// Commands denied by the configured policies or the session's role, or
+// beyond a quota of the session or its profile, are never sent; the caller
+// is also told about them with a log notification. Slow commands and
+// spikes in the session's error rate are warned about; see checkAlerts.
+// Read-only commands on sessions whose profile sets a cache_ttl are
+// answered from the response cache while the last response is fresh; such
+// answers are not sent, so they count against no quota and are neither in
+// the history nor in the audit log. Any other command clears the session's
+// cached responses.
+func executeWithMetadata(ctx context.Context, session *rcon.Session, command string) (string, ExecutionMetadata, error) {
+	if !serverConfig.AllowsCommand(session.Role(), session.Game(), command) {
+		logger(ctx).Warn("Command denied by policy", "session_id", session.ID, "role", session.Role(), "command", config.CommandName(command))
+		meta := ExecutionMetadata{SessionState: sessionStatus(session)}
+		err := sessionPolicyError(session, command)
+		recordAudit(ctx, audit.Entry{Event: audit.EventExecute, SessionID: session.ID, Address: session.Address,
+			Command: command, Error: err.Error()})
+		recordEvent(ctx, eventCommandDenied, session, err.Error())
+		notifyCaller(ctx, logNotification("warning", "command_denied", map[string]any{
+			"session_id": session.ID,
+			"command":    config.CommandName(command),
+			"error":      err.Error(),
+		}))
+		return "", meta, err
+	}
+	ttl := sessionCacheTTL(session)
+	readOnly := config.IsReadOnly(session.Game(), command)
+	if ttl > 0 && readOnly {
+		if raw, age, ok := responses.get(session, command); ok {
+			logger(ctx).Debug("Served RCON command from cache", "session_id", session.ID, "command", config.CommandName(command),
+				"age_ms", age.Milliseconds())
+			return responseRules.Apply(raw), ExecutionMetadata{
+				Bytes:          len(raw),
+				Truncated:      len(raw) >= rcon.MaxResponseBodySize,
+				SessionState:   sessionStatus(session),
+				Cached:         true,
+				CacheAgeMillis: age.Milliseconds(),
+			}, nil
+		}
+	}
+	if err := quotas.take(sessionQuotas(session)); err != nil {
+		logger(ctx).Warn("Command refused by quota", "session_id", session.ID, "command", config.CommandName(command), "error", err)
+		recordAudit(ctx, audit.Entry{Event: audit.EventExecute, SessionID: session.ID, Address: session.Address,
+			Command: command, Error: err.Error()})
+		recordEvent(ctx, eventQuotaExceeded, session, err.Error())
+		notifyCaller(ctx, logNotification("warning", "quota_exceeded", map[string]any{
+			"session_id": session.ID,
+			"command":    config.CommandName(command),
+			"error":      err.Error(),
+		}))
+		return "", ExecutionMetadata{SessionState: sessionStatus(session)}, err
+	}
+
+	start := time.Now()
+	raw, attempts, err := session.Execute(ctx, command)
+	latency := time.Since(start)
+	response := responseRules.Apply(raw)
+
+	meta := ExecutionMetadata{
+		LatencyMillis: latency.Milliseconds(),
+		Bytes:         len(raw),
+		Truncated:     len(raw) >= rcon.MaxResponseBodySize,
+		Rejected:      err == nil && game.Rejected(session.Game(), raw),
+		SessionState:  sessionStatus(session),
+		Retries:       attempts - 1,
+	}
+
+	entry := rcon.HistoryEntry{
+		Time:          start,
+		Command:       command,
+		Output:        response,
+		LatencyMillis: meta.LatencyMillis,
+		RequestID:     logging.RequestIDFromContext(ctx),
+	}
+	switch {
+	case err != nil:
+		entry.Error = err.Error()
+	case meta.Rejected:
+		entry.Error = errRejected
+	}
+	session.History.Add(entry)
+	switch {
+	case !readOnly:
+		responses.forget(session)
+	case ttl > 0 && entry.Error == "":
+		responses.put(session, command, raw, ttl)
+	}
+	checkAlerts(ctx, session, command, latency)
+	logger(ctx).Debug("Executed RCON command", "session_id", session.ID, "command", config.CommandName(command),
+		"latency_ms", meta.LatencyMillis, "bytes", meta.Bytes, "error", entry.Error)
+	serverMetrics.RecordCommand(session.ID, latency, entry.Error != "")
+	recordAudit(ctx, audit.Entry{Time: start, Event: audit.EventExecute, SessionID: session.ID, Address: session.Address,
+		Command: command, Response: raw, LatencyMillis: meta.LatencyMillis, Error: entry.Error})
+
+	return response, meta, err
+}
+
+// sessionStatus describes the connection state of a session's client.
+func sessionStatus(session *rcon.Session) string {
+	if !session.Client.IsConnected() {
+		return "disconnected"
+	}
+	if !session.Client.IsAuthenticated() {
+		return "connected (not authenticated)"
+	}
+	return "connected & authenticated"
+}
+
+// sessionPolicyError describes why command may not run on session: either
+// its role or the server policies refuse it.
+func sessionPolicyError(session *rcon.Session, command string) error {
+	role, t := session.Role(), session.Game()
+	if serverConfig.Policies.Allows(t, command) && role != "" {
+		if p, err := serverConfig.RolePolicies(role); err == nil {
+			if refused, ok := p.Refused(t, command); ok {
+				command = refused
+			}
+		}
+		return deniedError("command %q is not allowed for the %s role of session %s", config.CommandName(command), role, session.ID)
+	}
+	return policyError(t, command)
+}
+
+// policyError describes why the configured policies refuse command, or the
+// first command a console of game t chains in it that they refuse.
+func policyError(t game.Type, command string) error {
+	if refused, ok := serverConfig.Policies.Refused(t, command); ok {
+		command = refused
+	}
+	name := config.CommandName(command)
+	if serverConfig.Policies.ReadOnly {
+		return deniedError("command %q is not allowed: the server is in read-only mode and only runs query commands", name)
+	}
+	if config.IsLua(command) && !serverConfig.Policies.AllowsLua(config.LuaCode(command)) {
+		return deniedError("the Lua code of command %q matches none of the server policy's lua_allow patterns", name)
+	}
+	return deniedError("command %q is not allowed by the server policy", name)
+}
@@ -0,0 +1,242 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestExecuteWithMetadata(t *testing.T) {
+	tests := []struct {
+		name          string
+		command       string
+		wantBytes     int
+		wantTruncated bool
+		wantErr       bool
+	}{
+		{
+			name:      "small response",
+			command:   "list",
+			wantBytes: len("ok"),
+		},
+		{
+			name:          "full packet is flagged as truncated",
+			command:       "big",
+			wantBytes:     rcon.MaxResponseBodySize,
+			wantTruncated: true,
+		},
+		{
+			name:    "failed command still reports metadata",
+			command: "fail",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSessionManager()
+			session := connectFakeSession(t, "meta", func(command string) string {
+				switch command {
+				case "big":
+					return strings.Repeat("x", rcon.MaxResponseBodySize)
+				case "fail":
+					return fakeMismatchReply
+				}
+				return "ok"
+			})
+
+			_, meta, err := executeWithMetadata(context.Background(), session, tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("executeWithMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if meta.Bytes != tt.wantBytes {
+				t.Errorf("Expected %d bytes, got %d", tt.wantBytes, meta.Bytes)
+			}
+			if meta.Truncated != tt.wantTruncated {
+				t.Errorf("Expected truncated %v, got %v", tt.wantTruncated, meta.Truncated)
+			}
+			if meta.LatencyMillis < 0 {
+				t.Errorf("Expected non-negative latency, got %d", meta.LatencyMillis)
+			}
+			if meta.SessionState == "" {
+				t.Error("Expected session state to be set")
+			}
+		})
+	}
+}
+
+func TestExecuteWithMetadata_Retries(t *testing.T) {
+	resetSessionManager()
+	session := connectFakeSession(t, "retried", func(string) string { return "ok" })
+
+	_, meta, err := executeWithMetadata(context.Background(), session, "list")
+	if err != nil || meta.Retries != 0 {
+		t.Fatalf("Expected a first try without retries, got %d, %v", meta.Retries, err)
+	}
+
+	// A dropped connection is reconnected and the command retried once.
+	session.SetAutoReconnect("password")
+	session.Client.Disconnect()
+	out, meta, err := executeWithMetadata(context.Background(), session, "list")
+	if err != nil || out != "ok" {
+		t.Fatalf("Expected the command to be retried after reconnecting, got %q, %v", out, err)
+	}
+	if meta.Retries != 1 {
+		t.Errorf("Expected 1 retry, got %d", meta.Retries)
+	}
+}
+
+func TestExecuteWithMetadata_Policy(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Policies: config.Policies{Deny: []string{"stop"}}})
+
+	sent := make(chan string, 2)
+	session := connectFakeSession(t, "policy", func(command string) string {
+		sent <- command
+		return "ok"
+	})
+
+	if _, _, err := executeWithMetadata(context.Background(), session, "/STOP now"); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("Expected policy error, got %v", err)
+	}
+	if _, _, err := executeWithMetadata(context.Background(), session, "echo x;stop"); err == nil || !strings.Contains(err.Error(), `command "stop" is not allowed`) {
+		t.Errorf("Expected the chained command to be refused by name, got %v", err)
+	}
+	session.SetGame(game.Source)
+	if _, _, err := executeWithMetadata(context.Background(), session, "c; stop"); err == nil || !strings.Contains(err.Error(), `command "stop" is not allowed`) {
+		t.Errorf("Expected a Factorio Lua name to chain commands on another game, got %v", err)
+	}
+	if _, _, err := executeWithMetadata(context.Background(), session, "list"); err != nil {
+		t.Fatalf("Expected allowed command to run, got %v", err)
+	}
+	if got := <-sent; got != "list" {
+		t.Errorf("Expected only the allowed command to be sent, got %q", got)
+	}
+	if n := session.History.Len(); n != 1 {
+		t.Errorf("Expected denied command to stay out of history, got %d entries", n)
+	}
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	resetSessionManager()
+	address := startFakeRCONServerWithPassword(t, "secret", func(command string) string { return "ok" })
+	setServerConfig(t, &config.Config{
+		Policies: config.Policies{ReadOnly: true},
+		Profiles: []*config.Profile{{Name: "prod", Address: address, Password: "secret"}},
+	})
+
+	connect := func(params ConnectParams) error {
+		_, err := Connect(context.Background(), nil, &mcp.CallToolParamsFor[ConnectParams]{Arguments: params})
+		return err
+	}
+	if err := connect(ConnectParams{SessionID: "adhoc", Address: address, Password: "secret"}); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("Expected ad-hoc connect to be refused, got %v", err)
+	}
+	if err := connect(ConnectParams{SessionID: "override", Profile: "prod", Address: "evil:25575"}); err == nil {
+		t.Error("Expected profile address override to be refused")
+	}
+	if err := connect(ConnectParams{SessionID: "prod", Profile: "prod"}); err != nil {
+		t.Fatalf("Expected profile connect to succeed, got %v", err)
+	}
+	session, _ := sessionManager.GetSession("prod")
+	defer session.Client.Disconnect()
+
+	if _, _, err := executeWithMetadata(context.Background(), session, "list"); err != nil {
+		t.Errorf("Expected query command to run, got %v", err)
+	}
+	if _, _, err := executeWithMetadata(context.Background(), session, "say hello"); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected read-only error, got %v", err)
+	}
+	if _, _, err := executeWithMetadata(context.Background(), session, "list ;stop"); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("Expected a write command chained after a query to be refused, got %v", err)
+	}
+
+	_, err := ProbeConnection(context.Background(), nil, &mcp.CallToolParamsFor[TestConnectionParams]{
+		Arguments: TestConnectionParams{Address: address, Password: "secret"},
+	})
+	if err == nil {
+		t.Error("Expected connection tests to be unavailable in read-only mode")
+	}
+}
+
+func TestProfileRoles(t *testing.T) {
+	resetSessionManager()
+	address := startFakeRCONServerWithPassword(t, "secret", func(command string) string { return "ok" })
+	setServerConfig(t, &config.Config{
+		Profiles: []*config.Profile{
+			{Name: "public", Address: address, Password: "secret", Role: config.RoleViewer},
+			{Name: "staging", Address: address, Password: "secret", Role: config.RoleOperator},
+		},
+	})
+
+	sessions := map[string]*rcon.Session{}
+	for _, name := range []string{"public", "staging"} {
+		_, err := Connect(context.Background(), nil, &mcp.CallToolParamsFor[ConnectParams]{
+			Arguments: ConnectParams{SessionID: name, Profile: name},
+		})
+		if err != nil {
+			t.Fatalf("Expected %s connect to succeed, got %v", name, err)
+		}
+		session, _ := sessionManager.GetSession(name)
+		defer session.Client.Disconnect()
+		sessions[name] = session
+	}
+
+	tests := []struct {
+		session string
+		command string
+		denied  bool
+	}{
+		{"public", "list", false},
+		{"public", "say hello", true},
+		{"staging", "say hello", false},
+		{"staging", "stop", true},
+	}
+	for _, tt := range tests {
+		_, _, err := executeWithMetadata(context.Background(), sessions[tt.session], tt.command)
+		if tt.denied && (err == nil || !strings.Contains(err.Error(), "role of session "+tt.session)) {
+			t.Errorf("%s: expected %q to be denied by role, got %v", tt.session, tt.command, err)
+		}
+		if !tt.denied && err != nil {
+			t.Errorf("%s: expected %q to run, got %v", tt.session, tt.command, err)
+		}
+	}
+}
+
+func TestExecuteWithMetadata_ControlCharacters(t *testing.T) {
+	resetSessionManager()
+	sent := make(chan string, 2)
+	session := connectFakeSession(t, "control", func(command string) string {
+		sent <- command
+		return "ok"
+	})
+
+	if _, _, err := executeWithMetadata(context.Background(), session, "say hi\nstop"); !errors.Is(err, rcon.ErrInvalidCommand) {
+		t.Errorf("Expected ErrInvalidCommand, got %v", err)
+	}
+	if _, _, err := executeWithMetadata(context.Background(), session, "list"); err != nil {
+		t.Fatalf("Expected the session to stay usable, got %v", err)
+	}
+	if got := <-sent; got != "list" {
+		t.Errorf("Expected only the valid command to be sent, got %q", got)
+	}
+}
+
+func TestSessionStatus(t *testing.T) {
+	resetSessionManager()
+	session := connectFakeSession(t, "status", func(string) string { return "" })
+	if got := sessionStatus(session); got != "connected & authenticated" {
+		t.Errorf("Expected authenticated status, got %q", got)
+	}
+
+	session.Client.Disconnect()
+	if got := sessionStatus(session); got != "disconnected" {
+		t.Errorf("Expected disconnected status, got %q", got)
+	}
+}
@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// serverMetrics collects command and connection statistics for the
+// lifetime of the server process.
+var serverMetrics = rcon.NewMetrics()
+
+// MetricsParams represents parameters for the metrics tool
+type MetricsParams struct{}
+
+// GetMetrics reports aggregate statistics: command and error counts, per-session
+// latency percentiles, reconnects, uptime and the number of connected MCP clients.
+// Only the sessions the calling client may use are reported, and the totals
+// count those alone, so that clients do not learn of each other's sessions.
+func GetMetrics(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[MetricsParams]) (*mcp.CallToolResultFor[any], error) {
+	visible := make(map[string]bool)
+	for _, session := range visibleSessions(ctx) {
+		visible[session.ID] = true
+	}
+	snapshot := serverMetrics.Snapshot().Only(func(id string) bool { return visible[id] })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Uptime: %s\n", time.Duration(snapshot.UptimeSeconds)*time.Second)
+	fmt.Fprintf(&b, "Commands: %d (%d errors, %.1f%% error rate)\n",
+		snapshot.TotalCommands, snapshot.TotalErrors, snapshot.ErrorRate*100)
+	fmt.Fprintf(&b, "Reconnects: %d\n", snapshot.Reconnects)
+	fmt.Fprintf(&b, "MCP clients: %d\n", clients.count())
+	for _, s := range snapshot.Sessions {
+		fmt.Fprintf(&b, "- %s: %d commands, %d errors, latency p50/p90/p99 %d/%d/%d ms, %d reconnects, %d drops\n",
+			s.SessionID, s.Commands, s.Errors, s.LatencyP50Ms, s.LatencyP90Ms, s.LatencyP99Ms, s.Reconnects, s.Drops)
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: b.String()}},
+		StructuredContent: snapshot,
+	}, nil
+}
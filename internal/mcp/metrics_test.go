@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestGetMetrics(t *testing.T) {
+	resetSessionManager()
+	serverMetrics = rcon.NewMetrics()
+
+	session := connectFakeSession(t, "metrics", func(command string) string {
+		if command == "fail" {
+			return fakeMismatchReply
+		}
+		return "ok"
+	})
+	for _, command := range []string{"list", "list", "fail", "list"} {
+		executeWithMetadata(context.Background(), session, command)
+	}
+	serverMetrics.RecordConnect("metrics")
+
+	result, err := GetMetrics(context.Background(), nil, &mcp.CallToolParamsFor[MetricsParams]{})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	snapshot := result.StructuredContent.(rcon.MetricsSnapshot)
+	if snapshot.TotalCommands != 4 || snapshot.TotalErrors != 1 || snapshot.ErrorRate != 0.25 {
+		t.Errorf("Unexpected totals: %+v", snapshot)
+	}
+	if len(snapshot.Sessions) != 1 || snapshot.Sessions[0].Connects != 1 {
+		t.Errorf("Unexpected sessions: %+v", snapshot.Sessions)
+	}
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{"Commands: 4 (1 errors, 25.0% error rate)", "- metrics: 4 commands"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, text)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/query"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MinecraftSessionParams represents parameters for the Minecraft tools
+// that only need a session
+type MinecraftSessionParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Minecraft server"`
+}
+
+// MinecraftPlayerParams represents parameters for the Minecraft whitelist
+// tools
+type MinecraftPlayerParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Minecraft server"`
+	Player    string `json:"player" jsonschema:"Player name, 1 to 16 letters, digits or underscores"`
+}
+
+// MinecraftBanParams represents parameters for the mc_ban tool
+type MinecraftBanParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Minecraft server"`
+	Player    string `json:"player" jsonschema:"Player name, 1 to 16 letters, digits or underscores"`
+	Reason    string `json:"reason,omitempty" jsonschema:"Reason shown to the player and kept in the ban list (optional)"`
+}
+
+// MinecraftSayParams represents parameters for the mc_say tool
+type MinecraftSayParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Minecraft server"`
+	Message   string `json:"message" jsonschema:"Message to broadcast to every player"`
+}
+
+// MinecraftSaveParams represents parameters for the mc_save_all tool
+type MinecraftSaveParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Minecraft server"`
+	Flush     bool   `json:"flush,omitempty" jsonschema:"Write every chunk to disk before returning, e.g. before a backup (optional)"`
+}
+
+// MinecraftQueryParams represents parameters for the mc_query_full tool
+type MinecraftQueryParams struct {
+	Profile string `json:"profile,omitempty" jsonschema:"Server profile whose query port to ask (see rcon_list_profiles)"`
+	Address string `json:"address,omitempty" jsonschema:"Query port address (host:port, port 25565 if omitted), instead of a profile"`
+}
+
+// MinecraftPingParams represents parameters for the mc_ping tool
+type MinecraftPingParams struct {
+	Profile string `json:"profile,omitempty" jsonschema:"Server profile whose game port to ping (see rcon_list_profiles)"`
+	Address string `json:"address,omitempty" jsonschema:"Game server address (host:port, port 25565 if omitted), instead of a profile"`
+}
+
+// MinecraftPingReport describes the outcome of a Server List Ping.
+type MinecraftPingReport struct {
+	Address       string    `json:"address"`
+	Online        bool      `json:"online"`
+	Error         string    `json:"error,omitempty"`
+	ErrorCode     ErrorCode `json:"error_code,omitempty"`
+	MOTD          string    `json:"motd,omitempty"`
+	Version       string    `json:"version,omitempty"`
+	Protocol      int       `json:"protocol,omitempty"`
+	Players       int       `json:"players"`
+	MaxPlayers    int       `json:"max_players"`
+	Sample        []string  `json:"sample,omitempty"` // Names of some online players
+	LatencyMillis int64     `json:"latency_ms"`
+}
+
+// registerMinecraftTools registers the tools of the minecraft game pack,
+// which compose Minecraft commands and return what the server replied in
+// structured form.
+func registerMinecraftTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "mc_list_players",
+		Description: "List the players online on a Minecraft server, with the player limit",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List Minecraft players",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, MinecraftListPlayers)
+
+	addTool(server, &mcp.Tool{
+		Name:        "mc_whitelist_add",
+		Description: "Add a player to a Minecraft server's whitelist",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Whitelist Minecraft player",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, MinecraftWhitelistAdd)
+
+	addTool(server, &mcp.Tool{
+		Name:        "mc_whitelist_remove",
+		Description: "Remove a player from a Minecraft server's whitelist",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Remove Minecraft player from whitelist",
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, MinecraftWhitelistRemove)
+
+	addTool(server, &mcp.Tool{
+		Name:        "mc_ban",
+		Description: "Ban a player from a Minecraft server, kicking them if online",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Ban Minecraft player",
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, MinecraftBan)
+
+	addTool(server, &mcp.Tool{
+		Name:        "mc_say",
+		Description: "Broadcast a chat message to every player on a Minecraft server",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Broadcast Minecraft message",
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, MinecraftSay)
+
+	addTool(server, &mcp.Tool{
+		Name:        "mc_save_all",
+		Description: "Save the world of a Minecraft server to disk",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Save Minecraft world",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, MinecraftSaveAll)
+
+	addTool(server, &mcp.Tool{
+		Name: "mc_query_full",
+		Description: "Query a Minecraft server over its Query (GameSpy4) port for the player list, plugins, map and versions. " +
+			"Needs no RCON session or password, only enable-query in server.properties",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Query Minecraft server",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, MinecraftQueryFull)
+
+	addTool(server, &mcp.Tool{
+		Name: "mc_ping",
+		Description: "Check whether a Minecraft server is up with a Server List Ping, returning its MOTD, version, player count and latency. " +
+			"Needs no RCON session or password, so it can be used before connecting",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Ping Minecraft server",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, MinecraftPing)
+}
+
+// MinecraftListPlayers runs "list" and returns the players online.
+func MinecraftListPlayers(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[MinecraftSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Minecraft)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, "list", func(output string) (any, error) {
+		return game.Parse(game.Minecraft, "list", output)
+	})
+}
+
+// MinecraftWhitelistAdd runs "whitelist add" for a player.
+func MinecraftWhitelistAdd(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[MinecraftPlayerParams]) (*mcp.CallToolResultFor[any], error) {
+	return minecraftPlayerCommand(ctx, params.Arguments.SessionID, "whitelist add", params.Arguments.Player, "")
+}
+
+// MinecraftWhitelistRemove runs "whitelist remove" for a player.
+func MinecraftWhitelistRemove(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[MinecraftPlayerParams]) (*mcp.CallToolResultFor[any], error) {
+	return minecraftPlayerCommand(ctx, params.Arguments.SessionID, "whitelist remove", params.Arguments.Player, "")
+}
+
+// MinecraftBan runs "ban" for a player, with the reason if one is given.
+func MinecraftBan(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[MinecraftBanParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	return minecraftPlayerCommand(ctx, args.SessionID, "ban", args.Player, strings.TrimSpace(args.Reason))
+}
+
+// MinecraftSay runs "say" with a message. The server broadcasts it without
+// replying, so success only means the command was accepted.
+func MinecraftSay(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[MinecraftSayParams]) (*mcp.CallToolResultFor[any], error) {
+	message := strings.TrimSpace(params.Arguments.Message)
+	if message == "" {
+		return nil, errors.New("a message is required")
+	}
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Minecraft)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, "say "+message, func(output string) (any, error) {
+		return &game.Change{Changed: true, Message: strings.TrimSpace(output)}, nil
+	})
+}
+
+// MinecraftSaveAll runs "save-all", with "flush" if asked to.
+func MinecraftSaveAll(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[MinecraftSaveParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Minecraft)
+	if err != nil {
+		return nil, err
+	}
+	command := "save-all"
+	if params.Arguments.Flush {
+		command += " flush"
+	}
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParseMinecraftChange(command, output)
+	})
+}
+
+// minecraftPlayerCommand runs verb for player, followed by extra if it is
+// not empty, and reads the server's reply as a game.Change. The player name
+// is checked first, so that it cannot carry further arguments.
+func minecraftPlayerCommand(ctx context.Context, sessionID, verb, player, extra string) (*mcp.CallToolResultFor[any], error) {
+	if !game.ValidMinecraftPlayer(player) {
+		return nil, fmt.Errorf("invalid player name %q: want 1 to 16 letters, digits or underscores", player)
+	}
+	session, err := packSession(ctx, sessionID, game.Minecraft)
+	if err != nil {
+		return nil, err
+	}
+	command := verb + " " + player
+	if extra != "" {
+		command += " " + extra
+	}
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParseMinecraftChange(command, output)
+	})
+}
+
+// MinecraftQueryFull sends a Minecraft Query full stat request to a
+// profile's query port or to an address.
+func MinecraftQueryFull(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[MinecraftQueryParams]) (*mcp.CallToolResultFor[any], error) {
+	address, err := statusAddress(params.Arguments.Profile, params.Arguments.Address, minecraftQueryAddress)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := query.QueryMinecraft(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", address, err)
+	}
+	return statusResult(stats)
+}
+
+// MinecraftPing performs a Server List Ping against a profile's game port
+// or an address. A server that does not answer is reported in the result
+// rather than as an error, since finding out is the purpose of the tool.
+func MinecraftPing(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[MinecraftPingParams]) (*mcp.CallToolResultFor[any], error) {
+	address, err := statusAddress(params.Arguments.Profile, params.Arguments.Address, profileHost)
+	if err != nil {
+		return nil, err
+	}
+	report := &MinecraftPingReport{Address: address}
+	status, err := query.PingMinecraft(ctx, address)
+	if err != nil {
+		report.Error, report.ErrorCode = err.Error(), errorCode(err)
+	} else {
+		report.Online = true
+		report.MOTD, report.Version, report.Protocol = status.MOTD, status.Version, status.Protocol
+		report.Players, report.MaxPlayers, report.Sample = status.Players, status.Max, status.Sample
+		report.LatencyMillis = status.Latency.Milliseconds()
+	}
+	return statusResult(report)
+}
+
+// minecraftQueryAddress returns the address of a profile's query port: its
+// query_address, or else the host of its RCON address, where the query
+// port defaults to the game port.
+func minecraftQueryAddress(profile *config.Profile) string {
+	if profile.QueryAddress != "" {
+		return profile.QueryAddress
+	}
+	return profileHost(profile)
+}
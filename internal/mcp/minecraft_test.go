@@ -0,0 +1,331 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/query"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestGamePacks_Registration(t *testing.T) {
+	for _, name := range config.GamePacks {
+		if gamePacks[name] == nil {
+			t.Errorf("Game pack %q has no tools to register", name)
+		}
+	}
+
+	for _, enabled := range []bool{false, true} {
+		cfg := &config.Config{Tools: config.Tools{Prefix: "game_"}}
+		if enabled {
+			cfg.Tools.GamePacks = []string{"minecraft", "minecraft"}
+		}
+		setServerConfig(t, cfg)
+		res, err := connectTestClient(t).ListTools(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		var found bool
+		for _, tool := range res.Tools {
+			found = found || tool.Name == "mc_list_players"
+		}
+		if found != enabled {
+			t.Errorf("With the pack enabled %v, expected mc_list_players registered %v", enabled, enabled)
+		}
+	}
+}
+
+func TestMinecraftTools(t *testing.T) {
+	resetSessionManager()
+	var mu sync.Mutex
+	var sent []string
+	session := connectFakeSession(t, "mc", func(command string) string {
+		mu.Lock()
+		sent = append(sent, command)
+		mu.Unlock()
+		switch command {
+		case "list":
+			return "There are 2 of a max of 20 players online: Alice, Bob"
+		case "whitelist add Steve":
+			return "Added Steve to the whitelist"
+		case "whitelist remove Steve":
+			return "Player is not whitelisted"
+		case "ban Griefer stop it":
+			return "Banned Griefer: stop it"
+		case "ban Nobody":
+			return "That player does not exist"
+		case "save-all flush":
+			return "Saving the game (this may take a moment!)\nSaved the game"
+		}
+		return ""
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		call     func() (*mcp.CallToolResultFor[any], error)
+		wantSent string
+		want     any
+		wantCode ErrorCode
+	}{
+		{
+			name: "list players",
+			call: func() (*mcp.CallToolResultFor[any], error) {
+				return MinecraftListPlayers(ctx, nil, &mcp.CallToolParamsFor[MinecraftSessionParams]{Arguments: MinecraftSessionParams{SessionID: "mc"}})
+			},
+			wantSent: "list",
+			want:     &game.PlayerList{Online: 2, Max: 20, Players: []string{"Alice", "Bob"}},
+		},
+		{
+			name: "whitelist add",
+			call: func() (*mcp.CallToolResultFor[any], error) {
+				return MinecraftWhitelistAdd(ctx, nil, &mcp.CallToolParamsFor[MinecraftPlayerParams]{Arguments: MinecraftPlayerParams{SessionID: "mc", Player: "Steve"}})
+			},
+			wantSent: "whitelist add Steve",
+			want:     &game.Change{Changed: true, Message: "Added Steve to the whitelist"},
+		},
+		{
+			name: "whitelist remove with nothing to do",
+			call: func() (*mcp.CallToolResultFor[any], error) {
+				return MinecraftWhitelistRemove(ctx, nil, &mcp.CallToolParamsFor[MinecraftPlayerParams]{Arguments: MinecraftPlayerParams{SessionID: "mc", Player: "Steve"}})
+			},
+			wantSent: "whitelist remove Steve",
+			want:     &game.Change{Message: "Player is not whitelisted"},
+		},
+		{
+			name: "ban with a reason",
+			call: func() (*mcp.CallToolResultFor[any], error) {
+				return MinecraftBan(ctx, nil, &mcp.CallToolParamsFor[MinecraftBanParams]{Arguments: MinecraftBanParams{SessionID: "mc", Player: "Griefer", Reason: " stop it "}})
+			},
+			wantSent: "ban Griefer stop it",
+			want:     &game.Change{Changed: true, Message: "Banned Griefer: stop it"},
+		},
+		{
+			name: "ban of an unknown player",
+			call: func() (*mcp.CallToolResultFor[any], error) {
+				return MinecraftBan(ctx, nil, &mcp.CallToolParamsFor[MinecraftBanParams]{Arguments: MinecraftBanParams{SessionID: "mc", Player: "Nobody"}})
+			},
+			wantSent: "ban Nobody",
+			wantCode: CodeRejected,
+		},
+		{
+			name: "say",
+			call: func() (*mcp.CallToolResultFor[any], error) {
+				return MinecraftSay(ctx, nil, &mcp.CallToolParamsFor[MinecraftSayParams]{Arguments: MinecraftSayParams{SessionID: "mc", Message: "Restart in 5 minutes"}})
+			},
+			wantSent: "say Restart in 5 minutes",
+			want:     &game.Change{Changed: true},
+		},
+		{
+			name: "save all",
+			call: func() (*mcp.CallToolResultFor[any], error) {
+				return MinecraftSaveAll(ctx, nil, &mcp.CallToolParamsFor[MinecraftSaveParams]{Arguments: MinecraftSaveParams{SessionID: "mc", Flush: true}})
+			},
+			wantSent: "save-all flush",
+			want:     &game.Change{Changed: true, Message: "Saving the game (this may take a moment!)\nSaved the game"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.call()
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			mu.Lock()
+			last := sent[len(sent)-1]
+			mu.Unlock()
+			if last != tt.wantSent {
+				t.Errorf("Expected %q to be sent, got %q", tt.wantSent, last)
+			}
+			result := res.StructuredContent.(*ExecuteResult)
+			if tt.wantCode != "" {
+				if !res.IsError || result.ErrorCode != tt.wantCode {
+					t.Errorf("Expected an error result with code %s, got %+v", tt.wantCode, result)
+				}
+				return
+			}
+			if res.IsError {
+				t.Fatalf("Expected success, got %+v", result)
+			}
+			if !reflect.DeepEqual(result.Parsed, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, result.Parsed)
+			}
+			if text := resultText(t, res); !strings.HasPrefix(text, "{") {
+				t.Errorf("Expected the parsed result as JSON text, got %q", text)
+			}
+		})
+	}
+
+	t.Run("invalid player names are refused before sending", func(t *testing.T) {
+		_, err := MinecraftBan(ctx, nil, &mcp.CallToolParamsFor[MinecraftBanParams]{Arguments: MinecraftBanParams{SessionID: "mc", Player: "Steve op Steve"}})
+		if err == nil || !strings.Contains(err.Error(), "invalid player name") {
+			t.Errorf("Expected an invalid player name error, got %v", err)
+		}
+	})
+
+	t.Run("sessions of another game are refused", func(t *testing.T) {
+		session.SetGame(game.Source)
+		defer session.SetGame(game.Unknown)
+		_, err := MinecraftListPlayers(ctx, nil, &mcp.CallToolParamsFor[MinecraftSessionParams]{Arguments: MinecraftSessionParams{SessionID: "mc"}})
+		if err == nil || !strings.Contains(err.Error(), "only works on minecraft servers") {
+			t.Errorf("Expected the session to be refused, got %v", err)
+		}
+	})
+}
+
+// startMinecraftQueryServer answers Minecraft Query handshakes and full
+// stat requests on a local UDP port with a server that has Steve online.
+func startMinecraftQueryServer(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 1400)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < 7 {
+				continue
+			}
+			response := append([]byte{buf[2]}, buf[3:7]...)
+			if buf[2] == 0x09 {
+				response = append(response, "1\x00"...)
+			} else {
+				response = append(response, "splitnum\x00\x80\x00hostname\x00Lobby\x00version\x001.21\x00"+
+					"plugins\x00Paper: LuckPerms\x00numplayers\x001\x00maxplayers\x0010\x00\x00"+
+					"\x01player_\x00\x00Steve\x00\x00"...)
+			}
+			conn.WriteTo(response, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestMinecraftQueryFull(t *testing.T) {
+	address := startMinecraftQueryServer(t)
+	setServerConfig(t, &config.Config{
+		Profiles: []*config.Profile{{Name: "lobby", Address: "127.0.0.1:25575", QueryAddress: address}},
+		Policies: config.Policies{ReadOnly: true},
+	})
+	ctx := context.Background()
+	call := func(args MinecraftQueryParams) (*mcp.CallToolResultFor[any], error) {
+		return MinecraftQueryFull(ctx, nil, &mcp.CallToolParamsFor[MinecraftQueryParams]{Arguments: args})
+	}
+
+	res, err := call(MinecraftQueryParams{Profile: "lobby"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	stats := res.StructuredContent.(*query.MinecraftQuery)
+	if stats.MOTD != "Lobby" || stats.Software != "Paper" || !reflect.DeepEqual(stats.Online, []string{"Steve"}) {
+		t.Errorf("Unexpected query result: %+v", stats)
+	}
+
+	// Read-only mode keeps the tool to the configured profiles.
+	if _, err := call(MinecraftQueryParams{Address: address}); !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("Expected an address to be denied in read-only mode, got %v", err)
+	}
+	serverConfig.Policies.ReadOnly = false
+	if _, err := call(MinecraftQueryParams{Address: address}); err != nil {
+		t.Errorf("Expected an address to be queried, got %v", err)
+	}
+	if _, err := call(MinecraftQueryParams{}); err == nil {
+		t.Error("Expected an error without a profile or address")
+	}
+
+	// Without a query address the profile's host is queried on the default port.
+	if got := minecraftQueryAddress(&config.Profile{Address: "mc.example.com:25575"}); got != "mc.example.com" {
+		t.Errorf("minecraftQueryAddress() = %q, want the host alone", got)
+	}
+}
+
+// startSLPServer answers one Minecraft Server List Ping on a local port.
+// Every packet it exchanges is shorter than 128 bytes, so each length is a
+// single byte.
+func startSLPServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		readPacket := func() []byte {
+			n, err := r.ReadByte()
+			if err != nil {
+				return nil
+			}
+			packet := make([]byte, n)
+			if _, err := io.ReadFull(r, packet); err != nil {
+				return nil
+			}
+			return packet
+		}
+
+		readPacket() // Handshake
+		readPacket() // Status request
+		status := `{"version":{"name":"1.21.1","protocol":767},"players":{"max":20,"online":1},"description":"Lobby"}`
+		response := append([]byte{byte(len(status) + 2), 0x00, byte(len(status))}, status...)
+		conn.Write(response)
+		if ping := readPacket(); ping != nil {
+			conn.Write(append([]byte{byte(len(ping))}, ping...))
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestMinecraftPing(t *testing.T) {
+	setServerConfig(t, &config.Config{})
+	ctx := context.Background()
+	call := func(args MinecraftPingParams) *MinecraftPingReport {
+		t.Helper()
+		res, err := MinecraftPing(ctx, nil, &mcp.CallToolParamsFor[MinecraftPingParams]{Arguments: args})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		return res.StructuredContent.(*MinecraftPingReport)
+	}
+
+	report := call(MinecraftPingParams{Address: startSLPServer(t)})
+	if !report.Online || report.MOTD != "Lobby" || report.Version != "1.21.1" || report.Players != 1 || report.MaxPlayers != 20 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	// A server that does not answer is reported, not returned as an error.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closed := ln.Addr().String()
+	ln.Close()
+	if report := call(MinecraftPingParams{Address: closed}); report.Online || report.Error == "" {
+		t.Errorf("Expected an offline report, got %+v", report)
+	}
+
+	serverConfig.Policies.ReadOnly = true
+	_, err = MinecraftPing(ctx, nil, &mcp.CallToolParamsFor[MinecraftPingParams]{Arguments: MinecraftPingParams{Address: closed}})
+	if !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("Expected an address to be denied in read-only mode, got %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/fanout"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Defaults and limits for the execute_multi tool.
+const (
+	defaultMultiConcurrency = 4
+	maxMultiConcurrency     = 32
+	defaultMultiTimeout     = 30 * time.Second
+)
+
+// MultiCommand is a single session/command pair for the execute_multi tool
+type MultiCommand struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID to run the command on"`
+	Command   string `json:"command" jsonschema:"Command to execute"`
+	Key       string `json:"key,omitempty" jsonschema:"Key for this result in the response (defaults to the session ID)"`
+}
+
+// ExecuteMultiParams represents parameters for the execute_multi tool
+type ExecuteMultiParams struct {
+	Commands             []MultiCommand `json:"commands" jsonschema:"Session ID and command pairs to execute"`
+	Concurrency          int            `json:"concurrency,omitempty" jsonschema:"Maximum number of commands to run at once (default 4, max 32)"`
+	TimeoutSeconds       int            `json:"timeout_seconds,omitempty" jsonschema:"Overall deadline for all commands in seconds (default 30)"`
+	TargetTimeoutSeconds int            `json:"target_timeout_seconds,omitempty" jsonschema:"Deadline for each command in seconds (default: only the overall deadline)"`
+}
+
+// BroadcastParams represents parameters for the broadcast tool
+type BroadcastParams struct {
+	Command              string   `json:"command" jsonschema:"Command to execute on every target session"`
+	Group                string   `json:"group,omitempty" jsonschema:"Name of a session group to target"`
+	SessionIDs           []string `json:"session_ids,omitempty" jsonschema:"Additional session IDs to target"`
+	Concurrency          int      `json:"concurrency,omitempty" jsonschema:"Maximum number of commands to run at once (default 4, max 32)"`
+	TimeoutSeconds       int      `json:"timeout_seconds,omitempty" jsonschema:"Overall deadline for all commands in seconds (default 30)"`
+	TargetTimeoutSeconds int      `json:"target_timeout_seconds,omitempty" jsonschema:"Deadline for each session's command in seconds (default: only the overall deadline)"`
+}
+
+// MultiResult is the outcome of one command run by the execute_multi tool.
+type MultiResult struct {
+	SessionID string             `json:"session_id"`
+	Command   string             `json:"command"`
+	Output    string             `json:"output,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	ErrorCode ErrorCode          `json:"error_code,omitempty"`
+	Metadata  *ExecutionMetadata `json:"metadata,omitempty"` // Absent if the command never ran
+}
+
+// ExecuteMulti runs a list of commands across sessions concurrently.
+// At most Concurrency commands run at once, and any command still running
+// when its own deadline or the overall one expires is reported as timed
+// out. Results are keyed by each pair's Key, or its session ID when no key
+// is given.
+func ExecuteMulti(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ExecuteMultiParams]) (*mcp.CallToolResultFor[any], error) {
+	return runMulti(ctx, params.Arguments)
+}
+
+// Broadcast runs the same command on every session of a group and/or an
+// explicit list of sessions. Each session is targeted once, even if it is
+// named more than once, and results are keyed by session ID.
+func Broadcast(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[BroadcastParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+
+	targets := args.SessionIDs
+	if args.Group != "" {
+		group, err := groupManager.GetGroup(args.Group)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve group: %w", err)
+		}
+		targets = append(group.Members, targets...)
+	}
+
+	seen := make(map[string]bool)
+	var commands []MultiCommand
+	for _, id := range targets {
+		if !seen[id] {
+			seen[id] = true
+			commands = append(commands, MultiCommand{SessionID: id, Command: args.Command})
+		}
+	}
+	if len(commands) == 0 {
+		return nil, errors.New("no target sessions: specify a non-empty group or session_ids")
+	}
+
+	return runMulti(ctx, ExecuteMultiParams{
+		Commands:             commands,
+		Concurrency:          args.Concurrency,
+		TimeoutSeconds:       args.TimeoutSeconds,
+		TargetTimeoutSeconds: args.TargetTimeoutSeconds,
+	})
+}
+
+// runMulti executes a batch of commands on a bounded pool of workers, with
+// per-command and overall deadlines, returning the results keyed as
+// described on ExecuteMulti. The result's _meta counts the commands that
+// succeeded and failed. It is flagged as an error only when every command
+// failed, and then carries their error_code if they all share it.
+func runMulti(ctx context.Context, args ExecuteMultiParams) (*mcp.CallToolResultFor[any], error) {
+	if len(args.Commands) == 0 {
+		return nil, errors.New("at least one command is required")
+	}
+
+	concurrency := args.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultMultiConcurrency
+	}
+	concurrency = min(concurrency, maxMultiConcurrency)
+
+	timeout := defaultMultiTimeout
+	if args.TimeoutSeconds > 0 {
+		timeout = time.Duration(args.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	opts := fanout.Options{Workers: concurrency}
+	if args.TargetTimeoutSeconds > 0 {
+		opts.Timeout = time.Duration(args.TargetTimeoutSeconds) * time.Second
+	}
+	results := fanout.Run(ctx, args.Commands, opts, runMultiCommand)
+
+	keys := multiResultKeys(args.Commands)
+	keyed := make(map[string]*MultiResult, len(results))
+	codes := make(map[ErrorCode]bool)
+	for i, r := range results {
+		result := r.Value
+		if result == nil {
+			// The command never started
+			c := args.Commands[i]
+			result = &MultiResult{SessionID: c.SessionID, Command: c.Command, Error: r.Err.Error(), ErrorCode: errorCode(r.Err)}
+		}
+		if result.ErrorCode != "" {
+			codes[result.ErrorCode] = true
+		}
+		keyed[keys[i]] = result
+	}
+
+	data, err := json.Marshal(keyed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode results: %w", err)
+	}
+
+	summary := fanout.Summarize(results)
+	meta := mcp.Meta{"succeeded": summary.Succeeded, "failed": summary.Failed}
+	if summary.Succeeded == 0 && len(codes) == 1 {
+		for code := range codes {
+			meta["error_code"] = code
+		}
+	}
+	return &mcp.CallToolResultFor[any]{
+		Meta:              meta,
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+		StructuredContent: keyed,
+		IsError:           summary.Succeeded == 0,
+	}, nil
+}
+
+// runMultiCommand executes one command, giving up as soon as ctx is done
+// and cancelling any in-flight network call. The result is always
+// returned; the error, set when the command failed, is the one recorded
+// in the result.
+func runMultiCommand(ctx context.Context, c MultiCommand) (*MultiResult, error) {
+	result := &MultiResult{SessionID: c.SessionID, Command: c.Command}
+
+	session, err := getSession(ctx, c.SessionID)
+	if err != nil {
+		result.Error, result.ErrorCode = err.Error(), errorCode(err)
+		return result, err
+	}
+
+	output, meta, err := executeWithMetadata(ctx, session, c.Command)
+	result.Metadata = &meta
+	switch {
+	case ctx.Err() != nil:
+		err = fmt.Errorf("timed out: %w", ctx.Err())
+		result.ErrorCode = CodeTimeout
+	case err != nil:
+		err = fmt.Errorf("failed to execute command: %w", err)
+		result.ErrorCode = errorCode(err)
+	case meta.Rejected:
+		result.Output = output
+		err = errors.New(errRejected)
+		result.ErrorCode = CodeRejected
+	default:
+		result.Output = output
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, err
+}
+
+// multiResultKeys returns the result key for each command. Keys default to
+// the session ID, and repeated keys get a "#n" suffix so none are lost.
+func multiResultKeys(commands []MultiCommand) []string {
+	keys := make([]string, len(commands))
+	seen := make(map[string]int)
+	for i, c := range commands {
+		key := strings.TrimSpace(c.Key)
+		if key == "" {
+			key = c.SessionID
+		}
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key = fmt.Sprintf("%s#%d", key, n)
+		}
+		keys[i] = key
+	}
+	return keys
+}
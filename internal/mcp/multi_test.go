@@ -0,0 +1,220 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestExecuteMulti(t *testing.T) {
+	resetSessionManager()
+	connectFakeSession(t, "alpha", func(command string) string { return "alpha:" + command })
+	connectFakeSession(t, "beta", func(command string) string { return "beta:" + command })
+
+	params := &mcp.CallToolParamsFor[ExecuteMultiParams]{
+		Arguments: ExecuteMultiParams{
+			Commands: []MultiCommand{
+				{SessionID: "alpha", Command: "list"},
+				{SessionID: "beta", Command: "status"},
+				{SessionID: "alpha", Command: "time"},
+				{SessionID: "missing", Command: "list"},
+				{SessionID: "beta", Command: "list", Key: "beta-list"},
+			},
+			Concurrency: 2,
+		},
+	}
+
+	result, err := ExecuteMulti(context.Background(), nil, params)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	keyed, ok := result.StructuredContent.(map[string]*MultiResult)
+	if !ok {
+		t.Fatalf("Expected keyed results, got %T", result.StructuredContent)
+	}
+
+	want := map[string]string{
+		"alpha":     "alpha:list",
+		"beta":      "beta:status",
+		"alpha#2":   "alpha:time",
+		"beta-list": "beta:list",
+	}
+	for key, output := range want {
+		r, ok := keyed[key]
+		if !ok {
+			t.Errorf("Missing result for key %q", key)
+			continue
+		}
+		if r.Output != output || r.Error != "" {
+			t.Errorf("Result %q = %+v, want output %q", key, r, output)
+		}
+	}
+
+	if r := keyed["missing"]; r == nil || !strings.Contains(r.Error, "not found") {
+		t.Errorf("Expected not found error for missing session, got %+v", r)
+	}
+}
+
+func TestExecuteMulti_Deadline(t *testing.T) {
+	resetSessionManager()
+	connectFakeSession(t, "slow", func(command string) string {
+		time.Sleep(2 * time.Second)
+		return "late"
+	})
+
+	params := &mcp.CallToolParamsFor[ExecuteMultiParams]{
+		Arguments: ExecuteMultiParams{
+			Commands:       []MultiCommand{{SessionID: "slow", Command: "list"}},
+			TimeoutSeconds: 1,
+		},
+	}
+
+	start := time.Now()
+	result, err := ExecuteMulti(context.Background(), nil, params)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1900*time.Millisecond {
+		t.Errorf("Expected deadline to cut the call short, took %v", elapsed)
+	}
+
+	keyed := result.StructuredContent.(map[string]*MultiResult)
+	if r := keyed["slow"]; r == nil || !strings.Contains(r.Error, "timed out") {
+		t.Errorf("Expected timeout error, got %+v", r)
+	}
+}
+
+func TestExecuteMulti_TargetTimeout(t *testing.T) {
+	resetSessionManager()
+	connectFakeSession(t, "slow", func(command string) string {
+		time.Sleep(2 * time.Second)
+		return "late"
+	})
+	connectFakeSession(t, "fast", func(command string) string { return "ok" })
+
+	params := &mcp.CallToolParamsFor[ExecuteMultiParams]{
+		Arguments: ExecuteMultiParams{
+			Commands:             []MultiCommand{{SessionID: "slow", Command: "list"}, {SessionID: "fast", Command: "list"}},
+			Concurrency:          1,
+			TimeoutSeconds:       5,
+			TargetTimeoutSeconds: 1,
+		},
+	}
+
+	start := time.Now()
+	result, err := ExecuteMulti(context.Background(), nil, params)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1900*time.Millisecond {
+		t.Errorf("Expected the per-command deadline to cut the slow command short, took %v", elapsed)
+	}
+
+	keyed := result.StructuredContent.(map[string]*MultiResult)
+	if r := keyed["slow"]; r == nil || r.ErrorCode != CodeTimeout {
+		t.Errorf("Expected the slow command to time out, got %+v", r)
+	}
+	if r := keyed["fast"]; r == nil || r.Output != "ok" {
+		t.Errorf("Expected the fast command to run after the slow one timed out, got %+v", r)
+	}
+	if result.IsError || result.Meta["succeeded"] != 1 || result.Meta["failed"] != 1 {
+		t.Errorf("Expected one success and one failure, got IsError %v, meta %v", result.IsError, result.Meta)
+	}
+}
+
+func TestExecuteMulti_AllFailed(t *testing.T) {
+	resetSessionManager()
+	params := &mcp.CallToolParamsFor[ExecuteMultiParams]{
+		Arguments: ExecuteMultiParams{
+			Commands: []MultiCommand{{SessionID: "a", Command: "list"}, {SessionID: "b", Command: "list"}},
+		},
+	}
+
+	result, err := ExecuteMulti(context.Background(), nil, params)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if !result.IsError || result.Meta["failed"] != 2 || result.Meta["error_code"] != CodeSessionNotFound {
+		t.Errorf("Expected every command to fail as not found, got IsError %v, meta %v", result.IsError, result.Meta)
+	}
+}
+
+func TestExecuteMulti_NoCommands(t *testing.T) {
+	params := &mcp.CallToolParamsFor[ExecuteMultiParams]{}
+	if _, err := ExecuteMulti(context.Background(), nil, params); err == nil {
+		t.Error("Expected error for empty command list")
+	}
+}
+
+func TestMultiResultKeys(t *testing.T) {
+	commands := []MultiCommand{
+		{SessionID: "a"},
+		{SessionID: "a"},
+		{SessionID: "b", Key: "custom"},
+		{SessionID: "a", Key: " "},
+	}
+	got := strings.Join(multiResultKeys(commands), ",")
+	if want := "a,a#2,custom,a#3"; got != want {
+		t.Errorf("multiResultKeys() = %q, want %q", got, want)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	resetSessionManager()
+	resetGroupManager()
+	connectFakeSession(t, "lobby-1", func(command string) string { return "1:" + command })
+	connectFakeSession(t, "lobby-2", func(command string) string { return "2:" + command })
+	connectFakeSession(t, "arena", func(command string) string { return "arena:" + command })
+	groupManager.CreateGroup("lobbies", []string{"lobby-1", "lobby-2"})
+
+	params := &mcp.CallToolParamsFor[BroadcastParams]{
+		Arguments: BroadcastParams{
+			Command:    "say hi",
+			Group:      "lobbies",
+			SessionIDs: []string{"arena", "lobby-1"},
+		},
+	}
+	result, err := Broadcast(context.Background(), nil, params)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	keyed := result.StructuredContent.(map[string]*MultiResult)
+	want := map[string]string{"lobby-1": "1:say hi", "lobby-2": "2:say hi", "arena": "arena:say hi"}
+	if len(keyed) != len(want) {
+		t.Errorf("Expected %d results, got %d", len(want), len(keyed))
+	}
+	for key, output := range want {
+		if r := keyed[key]; r == nil || r.Output != output {
+			t.Errorf("Result %q = %+v, want output %q", key, r, output)
+		}
+	}
+}
+
+func TestBroadcast_Errors(t *testing.T) {
+	resetGroupManager()
+	groupManager.CreateGroup("empty", nil)
+
+	tests := []struct {
+		name        string
+		params      BroadcastParams
+		errContains string
+	}{
+		{name: "unknown group", params: BroadcastParams{Command: "list", Group: "missing"}, errContains: "not found"},
+		{name: "empty group", params: BroadcastParams{Command: "list", Group: "empty"}, errContains: "no target sessions"},
+		{name: "no targets", params: BroadcastParams{Command: "list"}, errContains: "no target sessions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Broadcast(context.Background(), nil, &mcp.CallToolParamsFor[BroadcastParams]{Arguments: tt.params})
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+			}
+		})
+	}
+}
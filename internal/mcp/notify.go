@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/logging"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Server events are pushed to MCP clients as log notifications, so that an
+// assistant learns about them without polling. The SDK delivers them only
+// to clients that have enabled logging with logging/setLevel, and only at
+// or above the level they asked for.
+
+// logNotification builds the log notification reporting event, described
+// by data. Strings in data are scrubbed of secrets.
+func logNotification(level mcp.LoggingLevel, event string, data map[string]any) *mcp.LoggingMessageParams {
+	for k, v := range data {
+		if s, ok := v.(string); ok {
+			data[k] = redact.String(s)
+		}
+	}
+	data["event"] = event
+	return &mcp.LoggingMessageParams{Level: level, Logger: "rcon", Data: data}
+}
+
+// notifyCaller sends params to the client making the tool call ctx belongs
+// to, adding the call's request ID to its data. Outside a tool call nothing
+// is sent.
+func notifyCaller(ctx context.Context, params *mcp.LoggingMessageParams) {
+	state := clientFromContext(ctx)
+	if state == nil || state.ss == nil {
+		return
+	}
+	if id := logging.RequestIDFromContext(ctx); id != "" {
+		params.Data.(map[string]any)["request_id"] = id
+	}
+	if err := state.ss.Log(ctx, params); err != nil {
+		logger(ctx).Warn("Failed to notify client", "event", params.Data.(map[string]any)["event"], "error", err)
+	}
+}
+
+// notifyClients sends params to every client in sessions that may use
+// session: all of them unless the session belongs to one client and is not
+// shared.
+func notifyClients(sessions iter.Seq[*mcp.ServerSession], session *rcon.Session, params *mcp.LoggingMessageParams) {
+	for ss := range sessions {
+		key := ""
+		if state := clients.lookup(ss); state != nil {
+			key = state.key
+		}
+		if !mayUse(key, session) {
+			continue
+		}
+		if err := ss.Log(context.Background(), params); err != nil {
+			logger(context.Background()).Warn("Failed to notify client", "session_id", session.ID,
+				"event", params.Data.(map[string]any)["event"], "error", err)
+		}
+	}
+}
+
+// notifySessionDropped tells the clients of server that may use an RCON
+// session that it lost its connection, so the assistant learns about it
+// before its next tool call fails.
+func notifySessionDropped(server *mcp.Server, session *rcon.Session, cause error) {
+	droppedSessions.add(session.ID)
+	notifyClients(server.Sessions(), session, logNotification("warning", "session_dropped", map[string]any{
+		"session_id": session.ID,
+		"address":    session.Address,
+		"error":      cause.Error(),
+	}))
+}
+
+// notifyHealthCheckFailed tells the clients of server that may use an RCON
+// session that a health check probe failed without the connection being
+// found dead, which usually means the server is overloaded.
+func notifyHealthCheckFailed(server *mcp.Server, session *rcon.Session, cause error) {
+	notifyClients(server.Sessions(), session, logNotification("warning", "health_check_failed", map[string]any{
+		"session_id": session.ID,
+		"address":    session.Address,
+		"error":      cause.Error(),
+	}))
+}
+
+// notifySessionExpired tells the connected clients that may use an RCON
+// session that it was closed after running no command for idle.
+func notifySessionExpired(server *mcp.Server, session *rcon.Session, idle time.Duration) {
+	notifyClients(server.Sessions(), session, logNotification("info", "session_expired", map[string]any{
+		"session_id": session.ID,
+		"address":    session.Address,
+		"idle":       idle.Round(time.Second).String(),
+	}))
+}
+
+// notifyReconnected tells the connected clients that may use an RCON
+// session that it is connected again after its connection dropped.
+func notifyReconnected(session *rcon.Session) {
+	if !droppedSessions.remove(session.ID) {
+		return
+	}
+	logger(context.Background()).Info("RCON session reconnected", "session_id", session.ID, "address", session.Address)
+	notifyClients(clients.serverSessions(), session, logNotification("info", "session_reconnected", map[string]any{
+		"session_id": session.ID,
+		"address":    session.Address,
+	}))
+}
+
+// droppedSessions holds the IDs of the sessions whose connection dropped,
+// so that connecting one of them again can be reported as a reconnect.
+var droppedSessions = &idSet{ids: make(map[string]bool)}
+
+// idSet is a set of session IDs safe for concurrent use.
+type idSet struct {
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+// add puts id in the set and reports whether it was not in it already.
+func (s *idSet) add(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	added := !s.ids[id]
+	s.ids[id] = true
+	return added
+}
+
+// has reports whether id is in the set.
+func (s *idSet) has(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ids[id]
+}
+
+// remove takes id out of the set and reports whether it was in it.
+func (s *idSet) remove(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ok := s.ids[id]
+	delete(s.ids, id)
+	return ok
+}
@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// connectLoggingClients starts an MCP server that tracks its clients and
+// connects n clients to it that enable logging at the info level. The log
+// notifications each client receives are sent to its channel.
+func connectLoggingClients(t *testing.T, n int) (*mcp.Server, []*mcp.ClientSession, []chan *mcp.LoggingMessageParams) {
+	t.Helper()
+	ctx := context.Background()
+	clients = newClientRegistry()
+	t.Cleanup(clients.wait)
+	droppedSessions = &idSet{ids: make(map[string]bool)}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"},
+		&mcp.ServerOptions{InitializedHandler: clients.connected})
+	registerTools(server)
+
+	var sessions []*mcp.ClientSession
+	var received []chan *mcp.LoggingMessageParams
+	for i := 0; i < n; i++ {
+		clientTransport, serverTransport := mcp.NewInMemoryTransports()
+		if _, err := server.Connect(ctx, serverTransport); err != nil {
+			t.Fatalf("Failed to connect server: %v", err)
+		}
+		ch := make(chan *mcp.LoggingMessageParams, 10)
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+			LoggingMessageHandler: func(_ context.Context, _ *mcp.ClientSession, params *mcp.LoggingMessageParams) {
+				ch <- params
+			},
+		})
+		cs, err := client.Connect(ctx, clientTransport)
+		if err != nil {
+			t.Fatalf("Failed to connect client: %v", err)
+		}
+		t.Cleanup(func() { cs.Close() })
+		if err := cs.SetLevel(ctx, &mcp.SetLevelParams{Level: "info"}); err != nil {
+			t.Fatalf("SetLevel failed: %v", err)
+		}
+		sessions = append(sessions, cs)
+		received = append(received, ch)
+	}
+	waitFor(t, func() bool { return clients.count() == n })
+	return server, sessions, received
+}
+
+// expectEvent waits for the next notification on ch and checks its event.
+func expectEvent(t *testing.T, ch chan *mcp.LoggingMessageParams, event string) map[string]any {
+	t.Helper()
+	select {
+	case params := <-ch:
+		data, _ := params.Data.(map[string]any)
+		if data["event"] != event {
+			t.Fatalf("Expected a %s notification, got %v", event, params.Data)
+		}
+		return data
+	case <-time.After(time.Second):
+		t.Fatalf("No %s notification received", event)
+		return nil
+	}
+}
+
+// expectNoEvent checks that no notification arrives on ch for a while.
+func expectNoEvent(t *testing.T, ch chan *mcp.LoggingMessageParams) {
+	t.Helper()
+	select {
+	case params := <-ch:
+		t.Errorf("Expected no notification, got %v", params.Data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotify_CommandDenied(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Policies: config.Policies{Deny: []string{"stop"}}})
+	address := startFakeRCONServer(t, func(string) string { return "ok" })
+	_, sessions, received := connectLoggingClients(t, 2)
+
+	if msg := callConnect(t, sessions[0], "denied", address); msg != "" {
+		t.Fatalf("Connect failed: %s", msg)
+	}
+	res, err := sessions[0].CallTool(context.Background(), &mcp.CallToolParams{
+		Name: "rcon_execute", Arguments: map[string]any{"session_id": "denied", "command": "stop"},
+	})
+	if err != nil || !res.IsError {
+		t.Fatalf("Expected the command to be denied, got %v, %v", res, err)
+	}
+
+	data := expectEvent(t, received[0], "command_denied")
+	if data["session_id"] != "denied" || data["command"] != "stop" {
+		t.Errorf("Unexpected notification data: %v", data)
+	}
+	expectNoEvent(t, received[1])
+}
+
+func TestNotify_DropAndReconnect(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	address := startFakeRCONServer(t, func(string) string { return "ok" })
+	server, sessions, received := connectLoggingClients(t, 2)
+
+	if msg := callConnect(t, sessions[0], "private", address); msg != "" {
+		t.Fatalf("Connect failed: %s", msg)
+	}
+	expectNoEvent(t, received[0])
+
+	session, err := sessionManager.GetSession("private")
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	notifySessionDropped(server, session, io.EOF)
+	expectEvent(t, received[0], "session_dropped")
+	expectNoEvent(t, received[1])
+
+	notifyHealthCheckFailed(server, session, context.DeadlineExceeded)
+	expectEvent(t, received[0], "health_check_failed")
+	expectNoEvent(t, received[1])
+
+	notifyReconnected(session)
+	data := expectEvent(t, received[0], "session_reconnected")
+	if data["session_id"] != "private" {
+		t.Errorf("Unexpected notification data: %v", data)
+	}
+	expectNoEvent(t, received[1])
+
+	notifyReconnected(session)
+	expectNoEvent(t, received[0])
+}
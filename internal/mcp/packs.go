@@ -0,0 +1,211 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// gamePacks maps the name of each game pack to the function registering
+// its tools. A pack's tools are only registered when the configuration
+// enables it; config.GamePacks lists the same names.
+var gamePacks = map[string]func(server *mcp.Server){
+	"minecraft": registerMinecraftTools,
+	"source":    registerSourceTools,
+	"factorio":  registerFactorioTools,
+	"ark":       registerArkTools,
+	"rust":      registerRustTools,
+	"palworld":  registerPalworldTools,
+	"zomboid":   registerZomboidTools,
+	"valheim":   registerValheimTools,
+	"battleye":  registerBattlEyeTools,
+	"gmod":      registerGModTools,
+	"console":   registerLineConsoleTools,
+}
+
+// RegisterGamePack adds the game pack name, whose tools register adds to
+// a server with AddTool, for the configuration to enable like the built-in
+// ones. Its tools can run their commands with RunPackCommand. It is meant to be
+// called from an init function, before any server is built. Returns an
+// error if name is empty or taken.
+func RegisterGamePack(name string, register func(server *mcp.Server)) error {
+	if name == "" || register == nil {
+		return errors.New("a game pack needs a name and a register function")
+	}
+	if _, ok := gamePacks[name]; ok {
+		return fmt.Errorf("game pack %q is already registered", name)
+	}
+	gamePacks[name] = register
+	config.GamePacks = append(config.GamePacks, name)
+	return nil
+}
+
+// AddTool adds a tool of a game pack added with RegisterGamePack to server
+// the way the built-in tools are added: unless the configuration disables
+// it, renamed with the configured prefix, and logged and abandoned with
+// the calling client.
+func AddTool[In, Out any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	addTool(server, tool, handler)
+}
+
+// RunPackCommand runs command on the session with the given ID for a tool
+// of a game pack added with RegisterGamePack, through the same policies,
+// quotas, audit log and history as rcon_execute, and returns the tool's
+// result: the output as parse reads it, or an error result carrying the
+// server's reply if the command failed, was rejected or parse refused it.
+func RunPackCommand(ctx context.Context, sessionID, command string, parse func(output string) (any, error)) (*mcp.CallToolResultFor[any], error) {
+	session, err := getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, command, parse)
+}
+
+// registerGamePacks registers the tools of every game pack the
+// configuration enables, once each.
+func registerGamePacks(server *mcp.Server) {
+	registered := make(map[string]bool)
+	for _, name := range serverConfig.Tools.GamePacks {
+		register, ok := gamePacks[name]
+		if !ok || registered[name] {
+			continue
+		}
+		registered[name] = true
+		register(server)
+	}
+}
+
+// packSession returns the session a game pack tool for game g was called
+// on. Sessions detected or configured as another game are refused, since
+// the pack's commands would mean something else there, or nothing.
+func packSession(ctx context.Context, id string, g game.Type) (*rcon.Session, error) {
+	session, err := getSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t := session.Game(); t != game.Unknown && t != g {
+		return nil, fmt.Errorf("session %s is a %s server; this tool only works on %s servers", id, t, g)
+	}
+	return session, nil
+}
+
+// runPackCommand runs command on session for a game pack tool and returns
+// the result of parse on its output as the tool's text and in the Parsed
+// field of its structured content. A failed or rejected command, or output
+// parse does not accept, gives an error result carrying the server's reply.
+func runPackCommand(ctx context.Context, session *rcon.Session, command string, parse func(output string) (any, error)) (*mcp.CallToolResultFor[any], error) {
+	response, meta, err := executeWithMetadata(ctx, session, command)
+	result := &ExecuteResult{Output: response, Metadata: meta}
+	switch {
+	case err != nil:
+		result.Error = fmt.Sprintf("failed to execute command: %v", err)
+		result.ErrorCode = errorCode(err)
+		return errorResult(result.ErrorCode, result.Error, result), nil
+	case meta.Rejected:
+		result.Error, result.ErrorCode = errRejected, CodeRejected
+		return errorResult(result.ErrorCode, response, result), nil
+	}
+
+	parsed, err := parse(response)
+	if err != nil {
+		result.Error, result.ErrorCode = err.Error(), CodeRejected
+		return errorResult(result.ErrorCode, fmt.Sprintf("%s: %v", command, err), result), nil
+	}
+	data, err := json.Marshal(parsed)
+	if err != nil {
+		return nil, err
+	}
+	result.Parsed = parsed
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+		StructuredContent: result,
+	}, nil
+}
+
+// runExitCommand runs a command that stops the server like runPackCommand.
+// The server may close the connection before it replies, which counts as
+// the server exiting.
+func runExitCommand(ctx context.Context, session *rcon.Session, command string, parse func(output string) (any, error)) (*mcp.CallToolResultFor[any], error) {
+	wasConnected := session.Client.IsConnected()
+	res, err := runPackCommand(ctx, session, command, parse)
+	if err != nil || !res.IsError || !wasConnected || session.Client.IsConnected() {
+		return res, err
+	}
+	result := res.StructuredContent.(*ExecuteResult)
+	result.Error, result.ErrorCode = "", ""
+	return changeResult(result, &game.Change{Changed: true, Message: "the server closed the connection while exiting"})
+}
+
+// retryPackCommand runs a command that is safe to repeat like
+// runPackCommand, running it once more after delay if the server did not
+// answer in time or sent a reply parse does not accept, as some games do
+// now and then under load.
+func retryPackCommand(ctx context.Context, session *rcon.Session, command string, delay time.Duration, parse func(output string) (any, error)) (*mcp.CallToolResultFor[any], error) {
+	res, err := runPackCommand(ctx, session, command, parse)
+	if err != nil || !res.IsError {
+		return res, err
+	}
+	if code := res.StructuredContent.(*ExecuteResult).ErrorCode; code != CodeTimeout && code != CodeRejected {
+		return res, nil
+	}
+	select {
+	case <-ctx.Done():
+		return res, nil
+	case <-time.After(delay):
+	}
+	return runPackCommand(ctx, session, command, parse)
+}
+
+// statusAddress returns the address a game pack tool asks through a status
+// protocol, which needs no session: address, or else the one fromProfile
+// derives from the named profile. In read-only mode only profiles may be
+// named, as only they may be connected.
+func statusAddress(profileName, address string, fromProfile func(*config.Profile) string) (string, error) {
+	if serverConfig.Policies.ReadOnly && (profileName == "" || address != "") {
+		return "", deniedError("in read-only mode only configured profiles can be queried, without an address (see rcon_list_profiles)")
+	}
+	if profileName != "" {
+		profile, err := serverConfig.Profile(profileName)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve profile: %w", err)
+		}
+		if address == "" {
+			address = fromProfile(profile)
+		}
+	}
+	if address == "" {
+		return "", errors.New("an address or profile is required")
+	}
+	return address, nil
+}
+
+// profileHost returns the host of a profile's RCON address, where its game
+// is assumed to listen on the protocol's default port.
+func profileHost(profile *config.Profile) string {
+	host, _, err := net.SplitHostPort(profile.Address)
+	if err != nil {
+		return profile.Address
+	}
+	return host
+}
+
+// statusResult returns v as the JSON text and structured content of a
+// tool result.
+func statusResult(v any) (*mcp.CallToolResultFor[any], error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+		StructuredContent: v,
+	}, nil
+}
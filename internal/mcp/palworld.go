@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// palworldRetryDelay is how long to wait before running a command again
+// that Palworld did not answer properly.
+const palworldRetryDelay = 500 * time.Millisecond
+
+// maxPalworldShutdownSeconds bounds the countdown of palworld_shutdown.
+const maxPalworldShutdownSeconds = 3600
+
+// PalworldSessionParams represents parameters for the Palworld tools that
+// only need a session
+type PalworldSessionParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Palworld server"`
+}
+
+// PalworldBroadcastParams represents parameters for the palworld_broadcast
+// tool
+type PalworldBroadcastParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Palworld server"`
+	Message   string `json:"message" jsonschema:"Message to show every player; spaces are kept"`
+}
+
+// PalworldShutdownParams represents parameters for the palworld_shutdown
+// tool
+type PalworldShutdownParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Palworld server"`
+	Seconds   int    `json:"seconds" jsonschema:"Countdown before the server shuts down, 1 to 3600 seconds"`
+	Message   string `json:"message,omitempty" jsonschema:"Message shown to every player when the countdown starts (optional)"`
+	Confirm   bool   `json:"confirm" jsonschema:"Must be true: the server stays down until restarted from outside"`
+}
+
+// registerPalworldTools registers the tools of the palworld game pack,
+// which run common admin commands on Palworld servers and work around the
+// quirks of their RCON: messages cut at the first space, and replies that
+// are late, cut short or padded.
+func registerPalworldTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "palworld_show_players",
+		Description: "Run ShowPlayers on a Palworld server and return the players online with their player UID and SteamID64",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List Palworld players",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, PalworldShowPlayers)
+
+	addTool(server, &mcp.Tool{
+		Name:        "palworld_broadcast",
+		Description: "Show a message to every player on a Palworld server, keeping its spaces",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Broadcast Palworld message",
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, PalworldBroadcast)
+
+	addTool(server, &mcp.Tool{
+		Name:        "palworld_save",
+		Description: "Save the world of a Palworld server to disk",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Save Palworld world",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, PalworldSave)
+
+	addTool(server, &mcp.Tool{
+		Name: "palworld_shutdown",
+		Description: "Shut down a Palworld server after a countdown, showing a message to the players. Requires confirm: true; " +
+			"the server stays down until restarted from outside, e.g. by its process manager",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Shut down Palworld server",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, PalworldShutdown)
+}
+
+// PalworldShowPlayers runs "ShowPlayers" and returns the players online.
+func PalworldShowPlayers(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[PalworldSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Palworld)
+	if err != nil {
+		return nil, err
+	}
+	return retryPackCommand(ctx, session, "ShowPlayers", palworldRetryDelay, func(output string) (any, error) {
+		return game.Parse(game.Palworld, "ShowPlayers", output)
+	})
+}
+
+// PalworldBroadcast runs "Broadcast" with a message, its spaces encoded so
+// that Palworld shows all of it.
+func PalworldBroadcast(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[PalworldBroadcastParams]) (*mcp.CallToolResultFor[any], error) {
+	message, err := game.PalworldText(params.Arguments.Message)
+	if err != nil {
+		return nil, err
+	}
+	if message == "" {
+		return nil, errors.New("a message is required")
+	}
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Palworld)
+	if err != nil {
+		return nil, err
+	}
+	command := "Broadcast " + message
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParsePalworldChange(command, output)
+	})
+}
+
+// PalworldSave runs "Save".
+func PalworldSave(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[PalworldSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Palworld)
+	if err != nil {
+		return nil, err
+	}
+	return retryPackCommand(ctx, session, "Save", palworldRetryDelay, func(output string) (any, error) {
+		return game.ParsePalworldChange("Save", output)
+	})
+}
+
+// PalworldShutdown runs "Shutdown" with a countdown and a message.
+func PalworldShutdown(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[PalworldShutdownParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if !args.Confirm {
+		return nil, errors.New("palworld_shutdown shuts the server down until it is restarted from outside; call it again with confirm: true to proceed")
+	}
+	if args.Seconds < 1 || args.Seconds > maxPalworldShutdownSeconds {
+		return nil, fmt.Errorf("seconds must be between 1 and %d", maxPalworldShutdownSeconds)
+	}
+	message, err := game.PalworldText(args.Message)
+	if err != nil {
+		return nil, err
+	}
+	session, err := packSession(ctx, args.SessionID, game.Palworld)
+	if err != nil {
+		return nil, err
+	}
+	command := "Shutdown " + strconv.Itoa(args.Seconds)
+	if message != "" {
+		command += " " + message
+	}
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParsePalworldChange(command, output)
+	})
+}
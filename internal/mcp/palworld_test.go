@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestPalworldTools(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	var mu sync.Mutex
+	var sent []string
+	showPlayers := 0
+	connectFakeSession(t, "pal", func(command string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, command)
+		switch command {
+		case "ShowPlayers":
+			// The first reply is garbage, as Palworld sends now and then
+			if showPlayers++; showPlayers == 1 {
+				return "\x00\x00"
+			}
+			return "name,playeruid,steamid\nBob,1234567890,76561197960290418\n"
+		case "Save":
+			return "Complete Save"
+		case "Shutdown 30 Back\u00a0soon":
+			return "The server will shut down in 30 seconds. Please prepare to exit the game."
+		}
+		return "Broadcasted: " + command[len("Broadcast "):]
+	})
+	ctx := context.Background()
+	lastSent := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent[len(sent)-1]
+	}
+
+	res, err := PalworldShowPlayers(ctx, nil, &mcp.CallToolParamsFor[PalworldSessionParams]{Arguments: PalworldSessionParams{SessionID: "pal"}})
+	if err != nil || res.IsError {
+		t.Fatalf("PalworldShowPlayers failed: %v, %+v", err, res)
+	}
+	list := res.StructuredContent.(*ExecuteResult).Parsed.(*game.PalworldPlayerList)
+	if list.Online != 1 || list.Players[0].SteamID != "76561197960290418" || showPlayers != 2 {
+		t.Errorf("Expected Bob after a retry, got %+v after %d attempts", list, showPlayers)
+	}
+
+	res, err = PalworldBroadcast(ctx, nil, &mcp.CallToolParamsFor[PalworldBroadcastParams]{Arguments: PalworldBroadcastParams{SessionID: "pal", Message: "Restart in 5 minutes"}})
+	if err != nil || res.IsError || lastSent() != "Broadcast Restart\u00a0in\u00a05\u00a0minutes" {
+		t.Errorf("Expected the message with its spaces encoded, got %v, %+v after %q", err, res, lastSent())
+	}
+	if change := res.StructuredContent.(*ExecuteResult).Parsed.(*game.Change); change.Message != "Broadcasted: Restart in 5 minutes" {
+		t.Errorf("Expected the reply with its spaces decoded, got %q", change.Message)
+	}
+
+	res, err = PalworldSave(ctx, nil, &mcp.CallToolParamsFor[PalworldSessionParams]{Arguments: PalworldSessionParams{SessionID: "pal"}})
+	if err != nil || res.IsError {
+		t.Errorf("PalworldSave failed: %v, %+v", err, res)
+	}
+
+	shutdown := PalworldShutdownParams{SessionID: "pal", Seconds: 30, Message: "Back soon"}
+	if _, err := PalworldShutdown(ctx, nil, &mcp.CallToolParamsFor[PalworldShutdownParams]{Arguments: shutdown}); err == nil || lastSent() != "Save" {
+		t.Error("Expected palworld_shutdown without confirm to be refused before sending anything")
+	}
+	shutdown.Confirm = true
+	res, err = PalworldShutdown(ctx, nil, &mcp.CallToolParamsFor[PalworldShutdownParams]{Arguments: shutdown})
+	if err != nil || res.IsError {
+		t.Errorf("PalworldShutdown failed: %v, %+v", err, res)
+	}
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TestConnectionParams represents parameters for the test_connection tool
+type TestConnectionParams struct {
+	Address  string `json:"address" jsonschema:"RCON server address (host:port)"`
+	Password string `json:"password" jsonschema:"RCON server password"`
+	Command  string `json:"command,omitempty" jsonschema:"Optional query command, such as status or version, to run after authenticating"`
+	Protocol string `json:"protocol,omitempty" jsonschema:"Wire protocol, one of: rcon (Source RCON, the default), webrcon (Rust), telnet (7 Days to Die), tshock (Terraria), battleye (DayZ and Arma), satisfactory (Satisfactory), generic-telnet (other telnet consoles answering a password prompt), generic-websocket (WebSocket consoles taking commands as plain text) or generic-http (HTTP APIs taking the command as the request body) (optional)"`
+}
+
+// ConnectionReport describes the outcome of a connection test.
+type ConnectionReport struct {
+	Address       string    `json:"address"`
+	OK            bool      `json:"ok"`
+	FailedStage   string    `json:"failed_stage,omitempty"` // "connect", "authenticate" or "command"
+	Error         string    `json:"error,omitempty"`
+	ErrorCode     ErrorCode `json:"error_code,omitempty"`
+	ConnectMillis int64     `json:"connect_ms"`
+	AuthMillis    int64     `json:"auth_ms,omitempty"`
+	CommandMillis int64     `json:"command_ms,omitempty"`
+	Banner        string    `json:"banner,omitempty"` // Output of the probe command
+}
+
+// ProbeConnection dials an RCON server, authenticates, optionally runs one
+// probe command and disconnects again without creating a session. Failures
+// are reported in the result rather than as errors, since finding out that
+// credentials are wrong is the purpose of the tool. The probe command must
+// be a query command and is subject to the configured policies, so the tool
+// changes nothing on the server; its banner is rewritten by the redaction
+// rules, and each test is written to the audit log. The tool is unavailable
+// in read-only mode since it dials arbitrary addresses, and in strict
+// password mode since it takes a password.
+func ProbeConnection(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[TestConnectionParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if serverConfig.Policies.ReadOnly {
+		return nil, deniedError("connection tests are not available in read-only mode")
+	}
+	if serverConfig.Policies.StrictPasswords {
+		return nil, deniedError("connection tests are not available when passwords cannot be passed to this server; " +
+			"connect with a profile instead (see rcon_list_profiles)")
+	}
+	if args.Command != "" && !config.IsReadOnly(game.Unknown, args.Command) {
+		return nil, deniedError("probe command %q is not allowed: connection tests only run query commands such as status or version",
+			config.CommandName(args.Command))
+	}
+	if args.Command != "" && !serverConfig.Policies.Allows(game.Unknown, args.Command) {
+		return nil, policyError(game.Unknown, args.Command)
+	}
+	protocol, err := wireProtocol(args.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	redact.AddSecret(args.Password)
+	start := time.Now()
+	report := testConnection(args.Address, args.Password, args.Command, protocol)
+	recordAudit(ctx, audit.Entry{Time: start, Event: audit.EventTest, Address: args.Address, Command: args.Command,
+		Response: report.Banner, LatencyMillis: time.Since(start).Milliseconds(), Error: report.Error})
+	report.Banner = responseRules.Apply(report.Banner)
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+		StructuredContent: report,
+	}, nil
+}
+
+// testConnection runs the connect, authenticate and probe stages in order,
+// timing each one and stopping at the first failure.
+func testConnection(address, password, command string, protocol rcon.Protocol) *ConnectionReport {
+	report := &ConnectionReport{Address: address}
+	client := rcon.NewClient()
+	client.SetProtocol(protocol)
+	defer func() { _ = client.Disconnect() }()
+
+	start := time.Now()
+	err := client.Connect(address)
+	report.ConnectMillis = time.Since(start).Milliseconds()
+	if err != nil {
+		report.FailedStage, report.Error, report.ErrorCode = "connect", err.Error(), errorCode(err)
+		return report
+	}
+
+	start = time.Now()
+	err = client.Authenticate(password)
+	report.AuthMillis = time.Since(start).Milliseconds()
+	if err != nil {
+		report.FailedStage, report.Error, report.ErrorCode = "authenticate", err.Error(), errorCode(err)
+		return report
+	}
+
+	if command != "" {
+		start = time.Now()
+		output, err := client.Execute(command)
+		report.CommandMillis = time.Since(start).Milliseconds()
+		if err != nil {
+			report.FailedStage, report.Error, report.ErrorCode = "command", err.Error(), errorCode(err)
+			return report
+		}
+		report.Banner = output
+	}
+
+	report.OK = true
+	return report
+}
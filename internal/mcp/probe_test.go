@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestProbeConnection(t *testing.T) {
+	address := startFakeRCONServerWithPassword(t, "secret", func(command string) string {
+		return "This server is running Paper"
+	})
+
+	// Reserve a port and release it so that nothing is listening on it.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	closedAddress := ln.Addr().String()
+	ln.Close()
+
+	tests := []struct {
+		name       string
+		params     TestConnectionParams
+		wantOK     bool
+		wantStage  string
+		wantBanner string
+	}{
+		{
+			name:   "valid credentials",
+			params: TestConnectionParams{Address: address, Password: "secret"},
+			wantOK: true,
+		},
+		{
+			name:       "valid credentials with probe",
+			params:     TestConnectionParams{Address: address, Password: "secret", Command: "version"},
+			wantOK:     true,
+			wantBanner: "This server is running Paper",
+		},
+		{
+			name:      "wrong password",
+			params:    TestConnectionParams{Address: address, Password: "wrong"},
+			wantStage: "authenticate",
+		},
+		{
+			name:      "unreachable server",
+			params:    TestConnectionParams{Address: closedAddress, Password: "secret"},
+			wantStage: "connect",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSessionManager()
+			params := &mcp.CallToolParamsFor[TestConnectionParams]{Arguments: tt.params}
+
+			result, err := ProbeConnection(context.Background(), nil, params)
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			report, ok := result.StructuredContent.(*ConnectionReport)
+			if !ok {
+				t.Fatalf("Expected connection report, got %T", result.StructuredContent)
+			}
+			if report.OK != tt.wantOK {
+				t.Errorf("Expected OK %v, got %+v", tt.wantOK, report)
+			}
+			if report.FailedStage != tt.wantStage {
+				t.Errorf("Expected failed stage %q, got %q", tt.wantStage, report.FailedStage)
+			}
+			if report.Banner != tt.wantBanner {
+				t.Errorf("Expected banner %q, got %q", tt.wantBanner, report.Banner)
+			}
+			if len(sessionManager.ListSessions()) != 0 {
+				t.Error("Expected no session to be created")
+			}
+		})
+	}
+}
+
+func TestProbeConnection_QueriesOnly(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Responses: config.Responses{Redact: []config.RedactRule{{Preset: "ipv4"}}}})
+	registerResponseRules(serverConfig)
+	t.Cleanup(func() { registerResponseRules(&config.Config{}) })
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := openAudit(config.Audit{File: path}); err != nil {
+		t.Fatalf("openAudit failed: %v", err)
+	}
+	t.Cleanup(closeAudit)
+
+	sent := make(chan string, 2)
+	address := startFakeRCONServerWithPassword(t, "secret", func(command string) string {
+		sent <- command
+		return "udp/ip  : 203.0.113.9:27015"
+	})
+
+	_, err := ProbeConnection(context.Background(), nil, &mcp.CallToolParamsFor[TestConnectionParams]{
+		Arguments: TestConnectionParams{Address: address, Password: "secret", Command: "stop"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "only run query commands") {
+		t.Errorf("Expected a non-query probe command to be refused, got %v", err)
+	}
+
+	result, err := ProbeConnection(context.Background(), nil, &mcp.CallToolParamsFor[TestConnectionParams]{
+		Arguments: TestConnectionParams{Address: address, Password: "secret", Command: "status"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if got := <-sent; got != "status" {
+		t.Errorf("Expected only the query to be sent, got %q", got)
+	}
+	if banner := result.StructuredContent.(*ConnectionReport).Banner; strings.Contains(banner, "203.0.113.9") {
+		t.Errorf("Expected the banner to be redacted, got %q", banner)
+	}
+
+	entries, err := audit.Read(path, nil)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Event != audit.EventTest || entries[0].Command != "status" || entries[0].Address != address {
+		t.Errorf("Expected one audit entry for the test, got %+v", entries)
+	}
+}
@@ -0,0 +1,56 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ListProfilesParams represents parameters for the list_profiles tool
+type ListProfilesParams struct{}
+
+// ProfileInfo is the public view of a server profile. It deliberately has
+// no password field so that secrets can never leak into a tool result.
+type ProfileInfo struct {
+	Name    string   `json:"name"`
+	Address string   `json:"address"`
+	Game    string   `json:"game,omitempty"`
+	Role    string   `json:"role,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// ListProfiles lists the configured server profiles by name, address, game,
+// role and tags. Passwords are never included.
+func ListProfiles(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ListProfilesParams]) (*mcp.CallToolResultFor[any], error) {
+	profiles := serverConfig.SortedProfiles()
+	if len(profiles) == 0 {
+		return textResult("No server profiles configured"), nil
+	}
+
+	infos := make([]ProfileInfo, 0, len(profiles))
+	var b strings.Builder
+	b.WriteString("Configured server profiles:\n")
+	for _, p := range profiles {
+		info := ProfileInfo{Name: p.Name, Address: p.Address, Game: p.Game, Role: p.Role, Tags: p.Tags}
+		infos = append(infos, info)
+
+		fmt.Fprintf(&b, "- %s: %s", info.Name, info.Address)
+		if info.Game != "" {
+			fmt.Fprintf(&b, " - game: %s", info.Game)
+		}
+		if info.Role != "" {
+			fmt.Fprintf(&b, " - role: %s", info.Role)
+		}
+		if len(info.Tags) > 0 {
+			fmt.Fprintf(&b, " - tags: %s", strings.Join(info.Tags, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: b.String()}},
+		StructuredContent: map[string]any{"profiles": infos},
+	}, nil
+}
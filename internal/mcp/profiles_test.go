@@ -0,0 +1,346 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// setServerConfig replaces the global server configuration for a test.
+func setServerConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	previous := serverConfig
+	serverConfig = cfg
+	t.Cleanup(func() { serverConfig = previous })
+}
+
+func TestListProfiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        *config.Config
+		wantOutput []string
+		wantAbsent []string
+	}{
+		{
+			name:       "no profiles",
+			cfg:        &config.Config{},
+			wantOutput: []string{"No server profiles configured"},
+		},
+		{
+			name: "profiles without passwords",
+			cfg: &config.Config{Profiles: []*config.Profile{
+				{Name: "survival", Address: "mc.example.com:25575", Password: "hunter2", Game: "minecraft", Tags: []string{"prod", "eu"}},
+				{Name: "arena", Address: "cs.example.com:27015", Password: "swordfish"},
+			}},
+			wantOutput: []string{
+				"- arena: cs.example.com:27015\n",
+				"- survival: mc.example.com:25575 - game: minecraft - tags: prod, eu",
+			},
+			wantAbsent: []string{"hunter2", "swordfish"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setServerConfig(t, tt.cfg)
+
+			result, err := ListProfiles(context.Background(), nil, &mcp.CallToolParamsFor[ListProfilesParams]{})
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			text := resultText(t, result)
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(text, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, text)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(text, absent) {
+					t.Errorf("Output must not contain %q, got:\n%s", absent, text)
+				}
+			}
+		})
+	}
+}
+
+func TestConnect_Profile(t *testing.T) {
+	address := startFakeRCONServerWithPassword(t, "secret", func(command string) string { return "" })
+	t.Setenv("RCON_MCP_TEST_PASSWORD", "secret")
+	setServerConfig(t, &config.Config{Profiles: []*config.Profile{
+		{Name: "survival", Address: address, Password: "secret", Game: "minecraft"},
+		{Name: "wrong", Address: address, Password: "nope"},
+		{Name: "env", Address: address, PasswordEnv: "RCON_MCP_TEST_PASSWORD"},
+		{Name: "unset", Address: address, PasswordEnv: "RCON_MCP_TEST_UNSET_PASSWORD"},
+		{Name: "resilient", Address: address, Password: "secret", AutoReconnect: true},
+	}})
+
+	tests := []struct {
+		name        string
+		params      ConnectParams
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:   "profile supplies address and password",
+			params: ConnectParams{SessionID: "s1", Profile: "survival"},
+		},
+		{
+			name:   "explicit password overrides profile",
+			params: ConnectParams{SessionID: "s2", Profile: "wrong", Password: "secret"},
+		},
+		{
+			name:   "password from the environment",
+			params: ConnectParams{SessionID: "s5", Profile: "env"},
+		},
+		{
+			name:        "password variable not set",
+			params:      ConnectParams{SessionID: "s6", Profile: "unset"},
+			wantErr:     true,
+			errContains: "RCON_MCP_TEST_UNSET_PASSWORD is not set",
+		},
+		{
+			name:        "unknown profile",
+			params:      ConnectParams{SessionID: "s3", Profile: "missing"},
+			wantErr:     true,
+			errContains: "profile missing not found",
+		},
+		{
+			name:        "neither address nor profile",
+			params:      ConnectParams{SessionID: "s4", Password: "secret"},
+			wantErr:     true,
+			errContains: "address or profile is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSessionManager()
+			_, err := Connect(context.Background(), nil, &mcp.CallToolParamsFor[ConnectParams]{Arguments: tt.params})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			session, err := sessionManager.GetSession(tt.params.SessionID)
+			if err != nil {
+				t.Fatalf("Expected session to exist: %v", err)
+			}
+			defer session.Client.Disconnect()
+			if session.Name != tt.params.Profile {
+				t.Errorf("Expected session name to default to the profile name, got %q", session.Name)
+			}
+		})
+	}
+
+	t.Run("profile game is applied", func(t *testing.T) {
+		resetSessionManager()
+		if _, err := Connect(context.Background(), nil, &mcp.CallToolParamsFor[ConnectParams]{
+			Arguments: ConnectParams{SessionID: "mc", Profile: "survival"},
+		}); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		session, _ := sessionManager.GetSession("mc")
+		defer session.Client.Disconnect()
+		if session.Game() != game.Minecraft {
+			t.Errorf("Expected game %q, got %q", game.Minecraft, session.Game())
+		}
+	})
+
+	t.Run("auto-reconnect from the arguments or the profile", func(t *testing.T) {
+		resetSessionManager()
+		for _, params := range []ConnectParams{
+			{SessionID: "plain", Profile: "survival"},
+			{SessionID: "asked", Profile: "survival", AutoReconnect: true},
+			{SessionID: "profile", Profile: "resilient"},
+		} {
+			if _, err := Connect(context.Background(), nil, &mcp.CallToolParamsFor[ConnectParams]{Arguments: params}); err != nil {
+				t.Fatalf("Connect %s failed: %v", params.SessionID, err)
+			}
+			session, _ := sessionManager.GetSession(params.SessionID)
+			defer session.Client.Disconnect()
+			if want := params.SessionID != "plain"; session.AutoReconnect() != want {
+				t.Errorf("Session %s: expected AutoReconnect %v", params.SessionID, want)
+			}
+		}
+	})
+}
+
+func TestPreloadSessions(t *testing.T) {
+	resetSessionManager()
+	address := startFakeRCONServerWithPassword(t, "secret", func(command string) string { return "" })
+	setServerConfig(t, &config.Config{Profiles: []*config.Profile{
+		{Name: "survival", Address: address, Password: "secret", Game: "minecraft"},
+	}})
+
+	preloadSessions([]*config.Session{
+		{ID: "prod", Profile: "survival"},
+		{ID: "adhoc", Name: "Ad hoc", Address: address, Password: "secret"},
+		{ID: "broken", Address: address, Password: "wrong"},
+	})
+	t.Cleanup(func() { sessionManager.DisconnectAll() })
+
+	prod, err := sessionManager.GetSession("prod")
+	if err != nil {
+		t.Fatalf("Expected preloaded profile session: %v", err)
+	}
+	if prod.Name != "survival" || prod.Game() != game.Minecraft {
+		t.Errorf("Expected profile name and game, got %q and %q", prod.Name, prod.Game())
+	}
+	if adhoc, err := sessionManager.GetSession("adhoc"); err != nil || adhoc.Name != "Ad hoc" {
+		t.Errorf("Expected preloaded address session, got %v, %v", adhoc, err)
+	}
+	if _, err := sessionManager.GetSession("broken"); err == nil {
+		t.Error("Expected the session with a wrong password to be skipped")
+	}
+}
+
+func TestConnect_MissingPassword(t *testing.T) {
+	setServerConfig(t, &config.Config{Profiles: []*config.Profile{
+		{Name: "nopass", Address: "localhost:25575"},
+	}})
+
+	tests := []struct {
+		name   string
+		params ConnectParams
+	}{
+		{name: "no profile", params: ConnectParams{SessionID: "a", Address: "localhost:25575"}},
+		{name: "profile without password", params: ConnectParams{SessionID: "b", Profile: "nopass"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSessionManager()
+			_, err := Connect(context.Background(), nil, &mcp.CallToolParamsFor[ConnectParams]{Arguments: tt.params})
+			if err == nil || !strings.Contains(err.Error(), "password is required") {
+				t.Errorf("Expected password required error, got %v", err)
+			}
+			if len(sessionManager.ListSessions()) != 0 {
+				t.Error("Expected no session to be created")
+			}
+		})
+	}
+}
+
+func TestConnect_NoPasswordNeeded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ran " + r.URL.Query().Get("cmd")))
+	}))
+	t.Cleanup(srv.Close)
+	setServerConfig(t, &config.Config{Profiles: []*config.Profile{{
+		Name:    "panel",
+		Address: srv.Listener.Addr().String(),
+		HTTP:    &config.HTTPConsole{URL: "http://panel/run?cmd={command}"},
+	}}})
+	resetSessionManager()
+	t.Cleanup(func() { sessionManager.DisconnectAll() })
+
+	if _, err := Connect(context.Background(), nil, &mcp.CallToolParamsFor[ConnectParams]{Arguments: ConnectParams{SessionID: "panel", Profile: "panel"}}); err != nil {
+		t.Fatalf("Expected an API without auth to connect without a password, got %v", err)
+	}
+	session, err := sessionManager.GetSession("panel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response, err := session.Client.Execute("list"); err != nil || response != "ran list" {
+		t.Errorf("Expected the command to run, got %q, %v", response, err)
+	}
+}
+
+func TestUsesPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol rcon.Protocol
+		profile  *config.Profile
+		want     bool
+	}{
+		{name: "source rcon", protocol: rcon.ProtocolRCON, want: true},
+		{name: "default telnet login", protocol: rcon.ProtocolGenericTelnet, want: true},
+		{
+			name:     "telnet without login",
+			protocol: rcon.ProtocolGenericTelnet,
+			profile:  &config.Profile{Telnet: &config.TelnetConsole{Login: []config.TelnetStep{{Expect: "ready"}}}},
+		},
+		{name: "websocket without auth", protocol: rcon.ProtocolGenericWebSocket},
+		{
+			name:     "websocket header",
+			protocol: rcon.ProtocolGenericWebSocket,
+			profile:  &config.Profile{WebSocket: &config.WebSocketConsole{Headers: map[string]string{"Authorization": "Bearer {password}"}}},
+			want:     true,
+		},
+		{
+			name:     "http without auth",
+			protocol: rcon.ProtocolGenericHTTP,
+			profile:  &config.Profile{HTTP: &config.HTTPConsole{URL: "http://panel/run?cmd={command}"}},
+		},
+		{
+			name:     "http key in url",
+			protocol: rcon.ProtocolGenericHTTP,
+			profile:  &config.Profile{HTTP: &config.HTTPConsole{URL: "http://panel/run?key={password}&cmd={command}"}},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := usesPassword(tt.protocol, tt.profile); got != tt.want {
+				t.Errorf("usesPassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrictPasswords(t *testing.T) {
+	resetSessionManager()
+	address := startFakeRCONServerWithPassword(t, "secret", func(string) string { return "ok" })
+	setServerConfig(t, &config.Config{
+		Policies: config.Policies{StrictPasswords: true},
+		Profiles: []*config.Profile{{Name: "prod", Address: address, Password: "secret"}},
+	})
+	cs := connectClients(t, 1)[0]
+	ctx := context.Background()
+
+	tools, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	for _, tool := range tools.Tools {
+		if _, ok := tool.InputSchema.Properties["password"]; ok {
+			t.Errorf("Expected %s to take no password", tool.Name)
+		}
+	}
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "rcon_connect",
+		Arguments: map[string]any{"session_id": "adhoc", "address": address, "password": "secret"},
+	})
+	if err == nil && !res.IsError {
+		t.Error("Expected a connect with a password to be refused")
+	}
+	res, err = cs.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "rcon_connect",
+		Arguments: map[string]any{"session_id": "prod", "profile": "prod"},
+	})
+	if err != nil || res.IsError {
+		t.Errorf("Expected a profile connect to succeed, got %v, %+v", err, res)
+	}
+
+	_, err = ProbeConnection(ctx, nil, &mcp.CallToolParamsFor[TestConnectionParams]{
+		Arguments: TestConnectionParams{Address: address},
+	})
+	if err == nil || !strings.Contains(err.Error(), "not available") {
+		t.Errorf("Expected connection tests to be unavailable, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// errQuotaExceeded is wrapped by the errors of commands refused because a
+// quota is used up.
+var errQuotaExceeded = errors.New("quota exceeded")
+
+// quotaScope is one quota a command counts against: that of its session,
+// or that of the profile the session was opened from.
+type quotaScope struct {
+	Kind  string // "session" or "profile"
+	Name  string // Session ID or profile name
+	Quota config.Quota
+}
+
+// key identifies the scope's count in a quotaCounter.
+func (s quotaScope) key() string {
+	return s.Kind + ":" + s.Name
+}
+
+// sessionQuotas returns the configured quotas the commands run on session
+// count against.
+func sessionQuotas(session *rcon.Session) []quotaScope {
+	var scopes []quotaScope
+	if q, _ := config.ParseQuota(serverConfig.Limits.SessionQuota); !q.IsZero() {
+		scopes = append(scopes, quotaScope{Kind: "session", Name: session.ID, Quota: q})
+	}
+	if name := session.Profile(); name != "" {
+		if profile, err := serverConfig.Profile(name); err == nil {
+			if q, _ := config.ParseQuota(profile.Quota); !q.IsZero() {
+				scopes = append(scopes, quotaScope{Kind: "profile", Name: name, Quota: q})
+			}
+		}
+	}
+	return scopes
+}
+
+// quotaCounter counts the commands run against each quota in its sliding
+// window. Counts are kept by session ID and profile name rather than by
+// session, so reconnecting does not reset them.
+type quotaCounter struct {
+	mu   sync.Mutex
+	runs map[string][]time.Time // Times of the commands in the window by scope key, oldest first
+	now  func() time.Time
+}
+
+// quotas counts the commands run against the configured quotas for the
+// lifetime of the server process.
+var quotas = newQuotaCounter()
+
+// newQuotaCounter creates an empty quota counter.
+func newQuotaCounter() *quotaCounter {
+	return &quotaCounter{runs: make(map[string][]time.Time), now: time.Now}
+}
+
+// window returns the times of the commands counted against scope that are
+// still within its window, dropping the older ones. The caller must hold
+// c.mu.
+func (c *quotaCounter) window(scope quotaScope, now time.Time) []time.Time {
+	runs := c.runs[scope.key()]
+	start := now.Add(-scope.Quota.Window)
+	i := 0
+	for i < len(runs) && !runs[i].After(start) {
+		i++
+	}
+	runs = runs[i:]
+	if len(runs) == 0 {
+		delete(c.runs, scope.key())
+	} else {
+		c.runs[scope.key()] = runs
+	}
+	return runs
+}
+
+// take counts a command against every scope, or against none if the quota
+// of any is used up, in which case the error names it and says when the
+// next command will be allowed.
+func (c *quotaCounter) take(scopes []quotaScope) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for _, scope := range scopes {
+		runs := c.window(scope, now)
+		if len(runs) >= scope.Quota.Commands {
+			wait := runs[0].Add(scope.Quota.Window).Sub(now).Round(time.Second)
+			return fmt.Errorf("%w: %s %s may run %d commands per %s; the next is allowed in %s",
+				errQuotaExceeded, scope.Kind, scope.Name, scope.Quota.Commands, windowText(scope.Quota), wait)
+		}
+	}
+	for _, scope := range scopes {
+		c.runs[scope.key()] = append(c.runs[scope.key()], now)
+	}
+	return nil
+}
+
+// usage reports how many commands have been counted against scope in its
+// current window, and how long until the oldest of them leaves it.
+func (c *quotaCounter) usage(scope quotaScope) (used int, resetIn time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	runs := c.window(scope, now)
+	if len(runs) == 0 {
+		return 0, 0
+	}
+	return len(runs), runs[0].Add(scope.Quota.Window).Sub(now)
+}
+
+// windowText returns the window of q as written in the configuration,
+// e.g. "1h".
+func windowText(q config.Quota) string {
+	_, window, _ := strings.Cut(q.String(), "/")
+	return window
+}
+
+// QuotaStatusParams represents parameters for the quota_status tool
+type QuotaStatusParams struct {
+	SessionID string `json:"session_id,omitempty" jsonschema:"Session ID to report on (optional, defaults to all sessions and profiles)"`
+}
+
+// QuotaUsage is how much of one quota has been used.
+type QuotaUsage struct {
+	Scope           string `json:"scope"`                       // "session" or "profile"
+	Name            string `json:"name"`                        // Session ID or profile name
+	Limit           int    `json:"limit"`                       // Commands allowed per window
+	Window          string `json:"window"`                      // Length of the sliding window, e.g. "1h"
+	Used            int    `json:"used"`                        // Commands run in the current window
+	Remaining       int    `json:"remaining"`                   // Commands that may still run now
+	ResetsInSeconds int64  `json:"resets_in_seconds,omitempty"` // Until the oldest counted command leaves the window
+}
+
+// QuotaStatusResult is the structured content returned by rcon_quota_status.
+type QuotaStatusResult struct {
+	Quotas []QuotaUsage `json:"quotas"`
+}
+
+// QuotaStatus reports how many commands the configured quotas still allow,
+// for one session and its profile or for every session the caller can see
+// and every profile with a quota.
+func QuotaStatus(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[QuotaStatusParams]) (*mcp.CallToolResultFor[any], error) {
+	var scopes []quotaScope
+	if id := params.Arguments.SessionID; id != "" {
+		session, err := getSession(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		scopes = sessionQuotas(session)
+	} else {
+		for _, session := range visibleSessions(ctx) {
+			for _, scope := range sessionQuotas(session) {
+				if scope.Kind == "session" {
+					scopes = append(scopes, scope)
+				}
+			}
+		}
+		for _, profile := range serverConfig.Profiles {
+			if q, _ := config.ParseQuota(profile.Quota); !q.IsZero() {
+				scopes = append(scopes, quotaScope{Kind: "profile", Name: profile.Name, Quota: q})
+			}
+		}
+	}
+
+	result := QuotaStatusResult{Quotas: []QuotaUsage{}}
+	var b strings.Builder
+	for _, scope := range scopes {
+		used, resetIn := quotas.usage(scope)
+		usage := QuotaUsage{
+			Scope:           scope.Kind,
+			Name:            scope.Name,
+			Limit:           scope.Quota.Commands,
+			Window:          windowText(scope.Quota),
+			Used:            used,
+			Remaining:       max(scope.Quota.Commands-used, 0),
+			ResetsInSeconds: int64(resetIn.Round(time.Second) / time.Second),
+		}
+		result.Quotas = append(result.Quotas, usage)
+		fmt.Fprintf(&b, "- %s %s: %d of %d commands used in the last %s, %d left",
+			usage.Scope, usage.Name, usage.Used, usage.Limit, usage.Window, usage.Remaining)
+		if used > 0 {
+			fmt.Fprintf(&b, "; the oldest leaves the window in %s", resetIn.Round(time.Second))
+		}
+		b.WriteString("\n")
+	}
+	if len(scopes) == 0 {
+		b.WriteString("No quotas apply: commands are not limited.")
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: b.String()}},
+		StructuredContent: result,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestQuotaCounter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := newQuotaCounter()
+	c.now = func() time.Time { return now }
+	session := quotaScope{Kind: "session", Name: "s1", Quota: config.Quota{Commands: 2, Window: time.Hour}}
+	profile := quotaScope{Kind: "profile", Name: "prod", Quota: config.Quota{Commands: 3, Window: time.Hour}}
+
+	for i := 0; i < 2; i++ {
+		if err := c.take([]quotaScope{session, profile}); err != nil {
+			t.Fatalf("Expected command %d to be allowed, got %v", i+1, err)
+		}
+		now = now.Add(10 * time.Minute)
+	}
+	err := c.take([]quotaScope{session, profile})
+	if !errors.Is(err, errQuotaExceeded) || !strings.Contains(err.Error(), "session s1 may run 2 commands per 1h; the next is allowed in 40m0s") {
+		t.Fatalf("Expected the session quota to be exceeded, got %v", err)
+	}
+	if used, _ := c.usage(profile); used != 2 {
+		t.Errorf("Expected a refused command not to count against the profile, got %d", used)
+	}
+
+	now = now.Add(40 * time.Minute)
+	if err := c.take([]quotaScope{session, profile}); err != nil {
+		t.Fatalf("Expected the oldest command to have left the window, got %v", err)
+	}
+	used, resetIn := c.usage(session)
+	if used != 2 || resetIn != 10*time.Minute {
+		t.Errorf("Expected 2 commands used, resetting in 10m, got %d and %s", used, resetIn)
+	}
+	if err := c.take([]quotaScope{profile}); err != nil {
+		t.Fatalf("Expected a third command on the profile to be allowed, got %v", err)
+	}
+	if err := c.take([]quotaScope{profile}); !errors.Is(err, errQuotaExceeded) {
+		t.Errorf("Expected the profile quota to be exceeded, got %v", err)
+	}
+}
+
+func TestExecuteWithMetadata_Quota(t *testing.T) {
+	resetSessionManager()
+	previous := quotas
+	quotas = newQuotaCounter()
+	t.Cleanup(func() { quotas = previous })
+	setServerConfig(t, &config.Config{
+		Limits:   config.Limits{SessionQuota: "2/1h"},
+		Profiles: []*config.Profile{{Name: "prod", Address: "localhost:25575", Quota: "3/1h"}},
+	})
+
+	sent := make(chan string, 10)
+	respond := func(command string) string {
+		sent <- command
+		return "ok"
+	}
+	first := connectFakeSession(t, "first", respond)
+	second := connectFakeSession(t, "second", respond)
+	first.SetProfile("prod")
+	second.SetProfile("prod")
+
+	for _, session := range []*rcon.Session{first, first, second} {
+		if _, _, err := executeWithMetadata(context.Background(), session, "list"); err != nil {
+			t.Fatalf("Expected command on %s to run, got %v", session.ID, err)
+		}
+	}
+	if _, _, err := executeWithMetadata(context.Background(), first, "list"); err == nil || !strings.Contains(err.Error(), "session first may run 2 commands") {
+		t.Errorf("Expected the session quota to be exceeded, got %v", err)
+	}
+	if _, _, err := executeWithMetadata(context.Background(), second, "list"); err == nil || !strings.Contains(err.Error(), "profile prod may run 3 commands") {
+		t.Errorf("Expected the profile quota to be exceeded, got %v", err)
+	}
+	if n := len(sent); n != 3 {
+		t.Errorf("Expected only 3 commands to be sent, got %d", n)
+	}
+
+	result, err := QuotaStatus(context.Background(), nil, &mcp.CallToolParamsFor[QuotaStatusParams]{})
+	if err != nil {
+		t.Fatalf("QuotaStatus failed: %v", err)
+	}
+	status := result.StructuredContent.(QuotaStatusResult)
+	want := map[string]int{"session first": 0, "session second": 1, "profile prod": 0}
+	if len(status.Quotas) != len(want) {
+		t.Fatalf("Expected %d quotas, got %+v", len(want), status.Quotas)
+	}
+	for _, q := range status.Quotas {
+		remaining, ok := want[q.Scope+" "+q.Name]
+		if !ok || q.Remaining != remaining || q.Window != "1h" {
+			t.Errorf("Unexpected quota usage %+v", q)
+		}
+	}
+
+	result, err = QuotaStatus(context.Background(), nil, &mcp.CallToolParamsFor[QuotaStatusParams]{Arguments: QuotaStatusParams{SessionID: "second"}})
+	if err != nil {
+		t.Fatalf("QuotaStatus failed: %v", err)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "session second: 1 of 2 commands used in the last 1h, 1 left") || strings.Contains(text, "session first") {
+		t.Errorf("Expected the quotas of session second only, got:\n%s", text)
+	}
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerSecrets installs the configured redaction patterns and registers
+// every password stored in the configuration, so that none of them can be
+// echoed back to a client or written to the log. Passwords read from the
+// environment or the keyring are registered when a session uses them.
+func registerSecrets(cfg *config.Config) {
+	if err := redact.SetPatterns(cfg.Logging.Redact); err != nil {
+		// Validate has already rejected invalid patterns.
+		panic(err)
+	}
+	for _, p := range cfg.Profiles {
+		redact.AddSecret(p.Password)
+	}
+	for _, s := range cfg.Sessions {
+		redact.AddSecret(s.Password)
+	}
+}
+
+// responseRules rewrite the output of every command before it is returned
+// to a client or kept in a session's history.
+var responseRules redact.Rules
+
+// registerResponseRules installs the configured response redaction rules.
+func registerResponseRules(cfg *config.Config) {
+	rules, err := cfg.Responses.Rules()
+	if err != nil {
+		// Validate has already rejected invalid rules.
+		panic(err)
+	}
+	responseRules = rules
+}
+
+// scrubOutput masks secrets in output a server sent without being asked,
+// and rewrites it by the response redaction rules as executeWithMetadata
+// does the replies to commands.
+func scrubOutput(s string) string {
+	return responseRules.Apply(redact.String(s))
+}
+
+// redactResult scrubs the text and structured content of a tool result and
+// the message of a tool error before they are returned to the client.
+func redactResult[Out any](result *mcp.CallToolResultFor[Out], err error) (*mcp.CallToolResultFor[Out], error) {
+	if err != nil {
+		if scrubbed := redact.String(err.Error()); scrubbed != err.Error() {
+			err = errors.New(scrubbed)
+		}
+	}
+	if result == nil {
+		return result, err
+	}
+	for _, c := range result.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			text.Text = redact.String(text.Text)
+		}
+	}
+	// Structured content of an untyped result is scrubbed through its JSON
+	// form; typed results carry no free-form server output.
+	if structured, ok := any(&result.StructuredContent).(*any); ok && *structured != nil {
+		if data, jsonErr := json.Marshal(*structured); jsonErr == nil {
+			var v any
+			if json.Unmarshal(data, &v) == nil {
+				*structured = redact.Default.Value(v)
+			}
+		}
+	}
+	return result, err
+}
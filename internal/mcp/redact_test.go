@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRedactToolResults(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Logging: config.Logging{Redact: []string{`ip=(\S+)`}}})
+	registerSecrets(serverConfig)
+	t.Cleanup(func() { registerSecrets(&config.Config{}) })
+
+	address := startFakeRCONServerWithPassword(t, "redact-me-please", func(command string) string {
+		return "your password is redact-me-please, ip=10.0.0.7"
+	})
+	cs := connectTestClient(t)
+	ctx := context.Background()
+
+	if _, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "rcon_connect", Arguments: map[string]any{
+		"session_id": "s1", "address": address, "password": "redact-me-please",
+	}}); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer sessionManager.DisconnectAll()
+
+	res, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "rcon_execute", Arguments: map[string]any{
+		"session_id": "s1", "command": "whoami",
+	}})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	text := res.Content[0].(*mcp.TextContent).Text
+	if strings.Contains(text, "redact-me-please") || strings.Contains(text, "10.0.0.7") {
+		t.Errorf("Expected secrets to be masked, got %q", text)
+	}
+	if !strings.Contains(text, "your password is [REDACTED], ip=[REDACTED]") {
+		t.Errorf("Expected masked output, got %q", text)
+	}
+}
+
+func TestResponseRules(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Responses: config.Responses{Redact: []config.RedactRule{
+		{Preset: "ipv4"}, {Preset: "steamid"},
+	}}})
+	registerResponseRules(serverConfig)
+	t.Cleanup(func() { registerResponseRules(&config.Config{}) })
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := openAudit(config.Audit{File: path}); err != nil {
+		t.Fatalf("openAudit failed: %v", err)
+	}
+	t.Cleanup(closeAudit)
+
+	const status = `#  2 1 "Alice" STEAM_1:0:12345 01:23 50 0 active 196608 203.0.113.9:27005`
+	session := connectFakeSession(t, "private", func(string) string { return status })
+	output, _, err := executeWithMetadata(context.Background(), session, "status")
+	if err != nil {
+		t.Fatalf("executeWithMetadata failed: %v", err)
+	}
+	const want = `#  2 1 "Alice" [STEAMID] 01:23 50 0 active 196608 [IP]:27005`
+	if output != want {
+		t.Errorf("Expected redacted output %q, got %q", want, output)
+	}
+	if history := session.History.Search(rcon.HistoryFilter{}); len(history) != 1 || history[0].Output != want {
+		t.Errorf("Expected the history to keep the redacted output, got %+v", history)
+	}
+
+	closeAudit()
+	entries, err := audit.Read(path, nil)
+	if err != nil || len(entries) != 1 || entries[0].Response != status {
+		t.Errorf("Expected the audit log to keep the response as sent, got %+v (%v)", entries, err)
+	}
+}
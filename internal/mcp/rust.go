@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RustSessionParams represents parameters for the Rust tools that only
+// need a session
+type RustSessionParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Rust server"`
+}
+
+// RustKickParams represents parameters for the rust_kick tool
+type RustKickParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Rust server"`
+	Player    string `json:"player" jsonschema:"SteamID64 or name of the player"`
+	Reason    string `json:"reason,omitempty" jsonschema:"Reason shown to the player (optional)"`
+}
+
+// RustBanParams represents parameters for the rust_ban tool
+type RustBanParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Rust server"`
+	SteamID   string `json:"steam_id" jsonschema:"SteamID64 of the player, e.g. 76561197960290418"`
+	Name      string `json:"name,omitempty" jsonschema:"Name of the player, kept in the ban list (optional)"`
+	Reason    string `json:"reason,omitempty" jsonschema:"Reason shown to the player and kept in the ban list (optional)"`
+}
+
+// RustConsoleParams represents parameters for the rust_console_stream tool
+type RustConsoleParams struct {
+	SessionID string   `json:"session_id" jsonschema:"Session ID of a Rust server connected over WebRCON"`
+	Since     int64    `json:"since,omitempty" jsonschema:"Only return messages after this sequence number, as returned in last_seq (optional)"`
+	Limit     int      `json:"limit,omitempty" jsonschema:"Most messages to return, the latest ones (default 50, max 500)"`
+	Types     []string `json:"types,omitempty" jsonschema:"Only return messages of these types: Generic, Log, Warning, Error or Chat (optional)"`
+}
+
+// registerRustTools registers the tools of the rust game pack, which read
+// the state and live console of Rust servers over WebRCON and run common
+// admin commands on them with checked arguments.
+func registerRustTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "rust_serverinfo",
+		Description: "Run serverinfo on a Rust server and return its hostname, map, player and queue counts, entity count, framerate, memory and uptime",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Rust server info",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, RustServerInfo)
+
+	addTool(server, &mcp.Tool{
+		Name: "rust_console_stream",
+		Description: "Return the latest console output and chat a Rust server sent over WebRCON on its own, such as kills, " +
+			"connects and player chat, filtered by type. Messages are kept from when the session connected",
+		Annotations: &mcp.ToolAnnotations{
+			Title:         "Rust console stream",
+			ReadOnlyHint:  true,
+			OpenWorldHint: boolPtr(false),
+		},
+	}, RustConsoleStream)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rust_kick",
+		Description: "Kick a player from a Rust server by SteamID64 or name",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Kick Rust player",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, RustKick)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rust_ban",
+		Description: "Ban a player from a Rust server by SteamID64, kicking them if they are online",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Ban Rust player",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, RustBan)
+}
+
+// RustServerInfo runs "serverinfo" and returns its JSON reply parsed.
+func RustServerInfo(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[RustSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Rust)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, "serverinfo", func(output string) (any, error) {
+		return game.Parse(game.Rust, "serverinfo", output)
+	})
+}
+
+// RustConsoleStream returns the latest console messages of a WebRCON
+// session.
+func RustConsoleStream(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[RustConsoleParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	session, err := packSession(ctx, args.SessionID, game.Rust)
+	if err != nil {
+		return nil, err
+	}
+	if session.Client.Protocol() != rcon.ProtocolWebRCON {
+		return nil, fmt.Errorf("session %s is not connected over WebRCON, which the console stream needs; connect with protocol webrcon", session.ID)
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultTailLines
+	}
+	result := &ConsoleResult{SessionID: session.ID}
+	result.Lines, result.LastSeq = consoles.tail(session.ID, args.Since, args.Types, min(limit, maxTailLines))
+	return statusResult(result)
+}
+
+// RustKick runs "kick" for a player, with the reason if one is given.
+func RustKick(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[RustKickParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if !game.ValidRustPlayer(args.Player) {
+		return nil, fmt.Errorf("invalid player %q: want a SteamID64 or a name without quotes, semicolons or line breaks", args.Player)
+	}
+	reason, err := rustText("reason", args.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return rustChange(ctx, args.SessionID, fmt.Sprintf(`kick "%s" "%s"`, args.Player, reason))
+}
+
+// RustBan runs "banid" for a SteamID64, with the name and reason kept in
+// the ban list.
+func RustBan(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[RustBanParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if !game.ValidRustSteamID(args.SteamID) {
+		return nil, fmt.Errorf("invalid SteamID %q: want a SteamID64 such as 76561197960290418", args.SteamID)
+	}
+	name, err := rustText("name", args.Name)
+	if err != nil {
+		return nil, err
+	}
+	reason, err := rustText("reason", args.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return rustChange(ctx, args.SessionID, fmt.Sprintf(`banid %s "%s" "%s"`, args.SteamID, name, reason))
+}
+
+// rustText returns text trimmed for quoting in a Rust command, which it
+// may not break out of with quotes, nor end with semicolons or line breaks.
+func rustText(field, text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if strings.ContainsAny(text, "\";\r\n") {
+		return "", errors.New("the " + field + " may not contain quotes, semicolons or line breaks")
+	}
+	return text, nil
+}
+
+// rustChange runs command on a Rust session and reads the server's reply
+// as a game.Change.
+func rustChange(ctx context.Context, sessionID, command string) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, sessionID, game.Rust)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParseRustChange(command, output)
+	})
+}
@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/websocket"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// startRustServer serves WebRCON for the password "secret", answering each
+// command with respond after sending a chat message, as a busy Rust server
+// interleaves its console output with replies.
+func startRustServer(t *testing.T, respond func(command string) string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/secret" {
+			http.Error(w, "wrong password", http.StatusUnauthorized)
+			return
+		}
+		ws, err := websocket.Accept(w, r)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			typ, data, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			if typ != websocket.TextMessage {
+				continue
+			}
+			var cmd struct {
+				Identifier int
+				Message    string
+			}
+			_ = json.Unmarshal(data, &cmd)
+			chat, _ := json.Marshal(map[string]any{"Channel": 0, "Message": "anyone selling sulfur?", "UserId": "76561197960290418", "Username": "Bob"})
+			for _, msg := range []map[string]any{
+				{"Identifier": -1, "Type": "Chat", "Message": string(chat)},
+				{"Identifier": cmd.Identifier, "Type": "Generic", "Message": respond(cmd.Message)},
+			} {
+				out, _ := json.Marshal(msg)
+				if err := ws.WriteMessage(websocket.TextMessage, out); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestRustTools(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	var mu sync.Mutex
+	var sent []string
+	address := startRustServer(t, func(command string) string {
+		mu.Lock()
+		sent = append(sent, command)
+		mu.Unlock()
+		if command == "serverinfo" {
+			return `{"Hostname":"Rust Arena","MaxPlayers":100,"Players":3,"Map":"Procedural Map","Framerate":60}`
+		}
+		return ""
+	})
+	ctx := context.Background()
+	session, err := openSession(ctx, ConnectParams{SessionID: "rust", Address: address, Password: "secret", Protocol: "webrcon"})
+	if err != nil {
+		t.Fatalf("openSession failed: %v", err)
+	}
+	t.Cleanup(func() { session.Client.Disconnect() })
+	if session.Game() != game.Rust {
+		t.Errorf("Expected a WebRCON session to be a rust server, got %s", session.Game())
+	}
+	lastSent := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent[len(sent)-1]
+	}
+
+	res, err := RustServerInfo(ctx, nil, &mcp.CallToolParamsFor[RustSessionParams]{Arguments: RustSessionParams{SessionID: "rust"}})
+	if err != nil || res.IsError {
+		t.Fatalf("RustServerInfo failed: %v, %+v", err, res)
+	}
+	info := res.StructuredContent.(*ExecuteResult).Parsed.(*game.RustServerInfo)
+	if info.Hostname != "Rust Arena" || info.Players != 3 || info.MaxPlayers != 100 {
+		t.Errorf("Unexpected server info: %+v", info)
+	}
+
+	res, err = RustKick(ctx, nil, &mcp.CallToolParamsFor[RustKickParams]{Arguments: RustKickParams{SessionID: "rust", Player: "Bob", Reason: " afk "}})
+	if err != nil || res.IsError || lastSent() != `kick "Bob" "afk"` {
+		t.Errorf("Expected the player to be kicked, got %v, %+v after %q", err, res, lastSent())
+	}
+	res, err = RustBan(ctx, nil, &mcp.CallToolParamsFor[RustBanParams]{Arguments: RustBanParams{SessionID: "rust", SteamID: "76561197960290418", Name: "Bob", Reason: "cheating"}})
+	if err != nil || res.IsError || lastSent() != `banid 76561197960290418 "Bob" "cheating"` {
+		t.Errorf("Expected the player to be banned, got %v, %+v after %q", err, res, lastSent())
+	}
+	if _, err := RustBan(ctx, nil, &mcp.CallToolParamsFor[RustBanParams]{Arguments: RustBanParams{SessionID: "rust", SteamID: "76561197960290418", Reason: `x"; quit; "`}}); err == nil {
+		t.Error("Expected a reason with quotes to be refused")
+	}
+
+	// The chat sent before every reply is kept apart from the replies
+	var console *ConsoleResult
+	deadline := time.Now().Add(2 * time.Second)
+	for (console == nil || len(console.Lines) < 3) && time.Now().Before(deadline) {
+		res, err = RustConsoleStream(ctx, nil, &mcp.CallToolParamsFor[RustConsoleParams]{Arguments: RustConsoleParams{SessionID: "rust", Types: []string{"chat"}}})
+		if err != nil {
+			t.Fatalf("RustConsoleStream failed: %v", err)
+		}
+		console = res.StructuredContent.(*ConsoleResult)
+	}
+	if len(console.Lines) != 3 || console.Lines[0].Chat == nil || console.Lines[0].Chat.Username != "Bob" {
+		t.Fatalf("Expected three chat messages from Bob, got %+v", console.Lines)
+	}
+	res, _ = RustConsoleStream(ctx, nil, &mcp.CallToolParamsFor[RustConsoleParams]{Arguments: RustConsoleParams{SessionID: "rust", Since: console.LastSeq}})
+	if lines := res.StructuredContent.(*ConsoleResult).Lines; len(lines) != 0 {
+		t.Errorf("Expected no messages after the last one, got %+v", lines)
+	}
+}
@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ExecuteScriptParams represents parameters for the execute_script tool
+type ExecuteScriptParams struct {
+	SessionID   string       `json:"session_id" jsonschema:"Session ID to run the script on"`
+	Script      string       `json:"script,omitempty" jsonschema:"Newline-separated commands; blank lines and lines starting with # or // are ignored"`
+	Steps       []ScriptStep `json:"steps,omitempty" jsonschema:"Commands with optional compensating commands, used instead of script"`
+	StopOnError bool         `json:"stop_on_error,omitempty" jsonschema:"Stop at the first command that fails instead of continuing"`
+}
+
+// ScriptStep is one command of a transactional script.
+type ScriptStep struct {
+	Command   string `json:"command" jsonschema:"Command to execute"`
+	OnFailure string `json:"on_failure,omitempty" jsonschema:"Command that undoes this step, run if a later step fails"`
+}
+
+// rollbackTimeout bounds how long compensating commands may take in total.
+// They run even when the tool call itself has been cancelled.
+const rollbackTimeout = 30 * time.Second
+
+// ScriptLine is a single command parsed from a script.
+type ScriptLine struct {
+	Number    int    // 1-based line number in the original script, or step number
+	Command   string // Command text with surrounding whitespace removed
+	OnFailure string // Compensating command, empty if the step cannot be undone
+}
+
+// ScriptLineResult is the outcome of one script line.
+type ScriptLineResult struct {
+	Line      int               `json:"line"`
+	Command   string            `json:"command"`
+	Output    string            `json:"output,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	ErrorCode ErrorCode         `json:"error_code,omitempty"`
+	Metadata  ExecutionMetadata `json:"metadata"`
+}
+
+// ScriptReport summarizes a script run.
+type ScriptReport struct {
+	SessionID string             `json:"session_id"`
+	Executed  int                `json:"executed"`
+	Failed    int                `json:"failed"`
+	Skipped   int                `json:"skipped"` // Lines not run because of stop_on_error
+	Results   []ScriptLineResult `json:"results"`
+
+	RolledBack bool               `json:"rolled_back,omitempty"` // Whether compensating commands were run
+	Rollback   []ScriptLineResult `json:"rollback,omitempty"`    // Compensating commands, in the order run
+}
+
+// ExecuteScript runs a multi-line script on a session one line at a time and
+// returns a per-line report. Lines are run in order; by default every line
+// is attempted even if earlier lines fail.
+//
+// When the script is given as steps and any step has an on_failure command,
+// the run is transactional: it stops at the first failure and runs the
+// on_failure commands of the steps that already succeeded, newest first.
+func ExecuteScript(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ExecuteScriptParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+
+	session, err := getSession(ctx, args.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Script != "" && len(args.Steps) > 0 {
+		return nil, errors.New("provide either script or steps, not both")
+	}
+	lines := ParseScript(args.Script)
+	if len(args.Steps) > 0 {
+		lines = stepLines(args.Steps)
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("script contains no commands")
+	}
+	transactional := slices.ContainsFunc(lines, func(l ScriptLine) bool { return l.OnFailure != "" })
+
+	report := &ScriptReport{SessionID: args.SessionID, Results: []ScriptLineResult{}}
+	for i, line := range lines {
+		result := runScriptLine(ctx, session, line.Number, line.Command)
+		report.Executed++
+		failed := result.Error != ""
+		if failed {
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+
+		// A cancelled request stops the script like a failure would
+		if failed && (args.StopOnError || transactional || ctx.Err() != nil) {
+			report.Skipped = len(lines) - i - 1
+			if transactional {
+				report.Rollback = rollback(ctx, session, lines[:i])
+				report.RolledBack = true
+			}
+			break
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+		StructuredContent: report,
+		IsError:           report.Failed > 0,
+	}, nil
+}
+
+// runScriptLine executes one command and reports its outcome. Commands the
+// server rejects count as failures.
+func runScriptLine(ctx context.Context, session *rcon.Session, number int, command string) ScriptLineResult {
+	result := ScriptLineResult{Line: number, Command: command}
+	output, meta, err := executeWithMetadata(ctx, session, command)
+	result.Output = output
+	result.Metadata = meta
+	switch {
+	case err != nil:
+		result.Error, result.ErrorCode = err.Error(), errorCode(err)
+	case meta.Rejected:
+		result.Error, result.ErrorCode = errRejected, CodeRejected
+	}
+	return result
+}
+
+// rollback runs the compensating commands of completed steps in reverse
+// order. Every compensation is attempted even if an earlier one fails, and
+// they run on a fresh deadline so that a cancelled call still cleans up.
+func rollback(ctx context.Context, session *rcon.Session, completed []ScriptLine) []ScriptLineResult {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	var results []ScriptLineResult
+	for _, line := range slices.Backward(completed) {
+		if line.OnFailure == "" {
+			continue
+		}
+		results = append(results, runScriptLine(ctx, session, line.Number, line.OnFailure))
+	}
+	return results
+}
+
+// stepLines converts transactional steps into script lines numbered from 1.
+func stepLines(steps []ScriptStep) []ScriptLine {
+	lines := make([]ScriptLine, 0, len(steps))
+	for i, step := range steps {
+		command := strings.TrimSpace(step.Command)
+		if command == "" {
+			continue
+		}
+		lines = append(lines, ScriptLine{
+			Number:    i + 1,
+			Command:   command,
+			OnFailure: strings.TrimSpace(step.OnFailure),
+		})
+	}
+	return lines
+}
+
+// ParseScript splits a script into commands, dropping blank lines and
+// comment lines that start with "#" or "//".
+func ParseScript(script string) []ScriptLine {
+	var lines []ScriptLine
+	for i, raw := range strings.Split(script, "\n") {
+		command := strings.TrimSpace(raw)
+		if command == "" || strings.HasPrefix(command, "#") || strings.HasPrefix(command, "//") {
+			continue
+		}
+		lines = append(lines, ScriptLine{Number: i + 1, Command: command})
+	}
+	return lines
+}
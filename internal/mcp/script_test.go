@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestParseScript(t *testing.T) {
+	script := "# Restart runbook\n\nsay Restarting soon\n  // save first\n  save-all  \r\nstop\n"
+	want := []ScriptLine{
+		{Number: 3, Command: "say Restarting soon"},
+		{Number: 5, Command: "save-all"},
+		{Number: 6, Command: "stop"},
+	}
+	if got := ParseScript(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseScript() = %+v, want %+v", got, want)
+	}
+}
+
+func TestExecuteScript(t *testing.T) {
+	script := "say one\n# comment\nfail\nsay two\n"
+
+	tests := []struct {
+		name         string
+		stopOnError  bool
+		wantCommands []string
+		wantExecuted int
+		wantFailed   int
+		wantSkipped  int
+	}{
+		{
+			name:         "continue after failure",
+			wantCommands: []string{"say one", "fail", "say two"},
+			wantExecuted: 3,
+			wantFailed:   1,
+		},
+		{
+			name:         "stop on error",
+			stopOnError:  true,
+			wantCommands: []string{"say one", "fail"},
+			wantExecuted: 2,
+			wantFailed:   1,
+			wantSkipped:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSessionManager()
+			connectFakeSession(t, "script", func(command string) string {
+				if command == "fail" {
+					return fakeMismatchReply
+				}
+				return "ok: " + command
+			})
+
+			params := &mcp.CallToolParamsFor[ExecuteScriptParams]{
+				Arguments: ExecuteScriptParams{SessionID: "script", Script: script, StopOnError: tt.stopOnError},
+			}
+
+			result, err := ExecuteScript(context.Background(), nil, params)
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			report := result.StructuredContent.(*ScriptReport)
+			if report.Executed != tt.wantExecuted || report.Failed != tt.wantFailed || report.Skipped != tt.wantSkipped {
+				t.Errorf("Unexpected report counts: %+v", report)
+			}
+			var commands []string
+			for _, r := range report.Results {
+				commands = append(commands, r.Command)
+			}
+			if !reflect.DeepEqual(commands, tt.wantCommands) {
+				t.Errorf("Expected commands %v, got %v", tt.wantCommands, commands)
+			}
+			if r := report.Results[1]; r.Line != 3 || r.Error == "" {
+				t.Errorf("Expected line 3 to fail, got %+v", r)
+			}
+			if !result.IsError {
+				t.Error("Expected a report with failures to be flagged as an error")
+			}
+		})
+	}
+}
+
+func TestExecuteScript_Errors(t *testing.T) {
+	resetSessionManager()
+	sessionManager.CreateSession("empty", "Test", "localhost:25575")
+
+	if _, err := ExecuteScript(context.Background(), nil, &mcp.CallToolParamsFor[ExecuteScriptParams]{
+		Arguments: ExecuteScriptParams{SessionID: "missing", Script: "list"},
+	}); err == nil {
+		t.Error("Expected error for missing session")
+	}
+	if _, err := ExecuteScript(context.Background(), nil, &mcp.CallToolParamsFor[ExecuteScriptParams]{
+		Arguments: ExecuteScriptParams{SessionID: "empty", Script: "# only a comment\n\n"},
+	}); err == nil {
+		t.Error("Expected error for script without commands")
+	}
+	if _, err := ExecuteScript(context.Background(), nil, &mcp.CallToolParamsFor[ExecuteScriptParams]{
+		Arguments: ExecuteScriptParams{SessionID: "empty", Script: "list", Steps: []ScriptStep{{Command: "list"}}},
+	}); err == nil {
+		t.Error("Expected error for both script and steps")
+	}
+}
+
+func TestExecuteScript_Rollback(t *testing.T) {
+	steps := []ScriptStep{
+		{Command: "whitelist off", OnFailure: "whitelist on"},
+		{Command: "say maintenance"},
+		{Command: "difficulty peaceful", OnFailure: "difficulty hard"},
+		{Command: "fail"},
+		{Command: "say done"},
+	}
+
+	resetSessionManager()
+	var commands []string
+	var mu sync.Mutex
+	connectFakeSession(t, "tx", func(command string) string {
+		mu.Lock()
+		commands = append(commands, command)
+		mu.Unlock()
+		if command == "fail" {
+			return "Unknown or incomplete command, see below for error"
+		}
+		return "ok"
+	})
+
+	result, err := ExecuteScript(context.Background(), nil, &mcp.CallToolParamsFor[ExecuteScriptParams]{
+		Arguments: ExecuteScriptParams{SessionID: "tx", Steps: steps},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	report := result.StructuredContent.(*ScriptReport)
+	if !report.RolledBack || report.Executed != 4 || report.Failed != 1 || report.Skipped != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	want := []string{"whitelist off", "say maintenance", "difficulty peaceful", "fail", "difficulty hard", "whitelist on"}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("Expected commands %v, got %v", want, commands)
+	}
+	if len(report.Rollback) != 2 || report.Rollback[0].Line != 3 || report.Rollback[1].Line != 1 {
+		t.Errorf("Expected rollback of steps 3 and 1, got %+v", report.Rollback)
+	}
+}
+
+func TestExecuteScript_StepsWithoutFailure(t *testing.T) {
+	resetSessionManager()
+	connectFakeSession(t, "tx", func(string) string { return "ok" })
+
+	result, err := ExecuteScript(context.Background(), nil, &mcp.CallToolParamsFor[ExecuteScriptParams]{
+		Arguments: ExecuteScriptParams{SessionID: "tx", Steps: []ScriptStep{
+			{Command: "whitelist off", OnFailure: "whitelist on"},
+			{Command: "say hi"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	report := result.StructuredContent.(*ScriptReport)
+	if report.RolledBack || len(report.Rollback) != 0 || report.Executed != 2 {
+		t.Errorf("Expected no rollback, got %+v", report)
+	}
+}
@@ -4,10 +4,22 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/mjmorales/rcon-mcp-server/internal/audit"
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/logging"
 	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/mjmorales/rcon-mcp-server/internal/redact"
+	"github.com/mjmorales/rcon-mcp-server/internal/version"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -15,12 +27,27 @@ import (
 // It provides thread-safe operations for creating, retrieving, and removing sessions.
 var sessionManager = rcon.NewSessionManager()
 
+// serverConfig holds the configuration the server was started with,
+// including the server profiles available to rcon_connect.
+var serverConfig = &config.Config{}
+
+// knownTools records the default name of every tool registerTools knows
+// about, whether or not it is enabled.
+var knownTools = make(map[string]bool)
+
+// healthCheckInterval is how often idle sessions are probed for dead connections.
+const healthCheckInterval = 30 * time.Second
+
 // ConnectParams represents parameters for the connect tool
 type ConnectParams struct {
-	SessionID string `json:"session_id" jsonschema:"Unique identifier for this RCON session"`
-	Name      string `json:"name,omitempty" jsonschema:"Friendly name for this connection (optional)"`
-	Address   string `json:"address" jsonschema:"RCON server address (host:port)"`
-	Password  string `json:"password" jsonschema:"RCON server password"`
+	SessionID     string `json:"session_id" jsonschema:"Unique identifier for this RCON session"`
+	Name          string `json:"name,omitempty" jsonschema:"Friendly name for this connection (optional)"`
+	Profile       string `json:"profile,omitempty" jsonschema:"Name of a configured server profile supplying the address and password (optional)"`
+	Address       string `json:"address,omitempty" jsonschema:"RCON server address (host:port), required unless a profile is given"`
+	Password      string `json:"password,omitempty" jsonschema:"RCON server password, required unless a profile is given or a generic console or API logs in without one"`
+	Shared        bool   `json:"shared,omitempty" jsonschema:"Let other MCP clients of this server use and disconnect the session (optional)"`
+	AutoReconnect bool   `json:"auto_reconnect,omitempty" jsonschema:"When the connection drops, reconnect and retry the failed command once, e.g. across a server restart (optional)"`
+	Protocol      string `json:"protocol,omitempty" jsonschema:"Wire protocol, one of: rcon (Source RCON, the default), webrcon (Rust), telnet (7 Days to Die), tshock (Terraria), battleye (DayZ and Arma), satisfactory (Satisfactory), generic-telnet (other telnet consoles), generic-websocket (other WebSocket consoles), generic-http (other HTTP APIs) or one the server was built with; defaults to the profile's (optional)"`
 }
 
 // DisconnectParams represents parameters for the disconnect tool
@@ -30,48 +57,285 @@ type DisconnectParams struct {
 
 // ExecuteParams represents parameters for the execute tool
 type ExecuteParams struct {
-	SessionID string `json:"session_id" jsonschema:"Session ID to use for execution"`
-	Command   string `json:"command" jsonschema:"Command to execute on the RCON server"`
+	SessionID  string `json:"session_id" jsonschema:"Session ID to use for execution"`
+	Command    string `json:"command" jsonschema:"Command to execute on the RCON server"`
+	Structured bool   `json:"structured,omitempty" jsonschema:"Return structured JSON parsed from the output when a parser for the command is available"`
 }
 
 // ListSessionsParams represents parameters for the list_sessions tool
 type ListSessionsParams struct{}
 
+// DetectGameParams represents parameters for the detect_game tool
+type DetectGameParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of the server to identify"`
+}
+
 // Connect establishes a new RCON connection to a server.
 // It creates a session, connects to the server, and authenticates using the provided password.
 // Returns an error if the session already exists, connection fails, or authentication fails.
+// In read-only mode only configured profiles may be connected.
 func Connect(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ConnectParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+
+	// In read-only mode the assistant may only observe the servers the
+	// operator configured, not point the server at addresses of its own.
+	if serverConfig.Policies.ReadOnly && (args.Profile == "" || args.Address != "" || args.Password != "") {
+		return nil, deniedError("in read-only mode only configured profiles can be connected, " +
+			"without an address or password (see rcon_list_profiles)")
+	}
+	if serverConfig.Policies.StrictPasswords && args.Password != "" {
+		return nil, deniedError("passwords cannot be passed to this server; connect with a profile (see rcon_list_profiles)")
+	}
+
+	if err := clients.checkSessionLimit(ctx, cc); err != nil {
+		return nil, err
+	}
+	session, err := openSession(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	clients.ownSession(ctx, cc, session, args.Shared)
+	session.SetIdleTimeout(serverConfig.Limits.IdleAfter())
+
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{&mcp.TextContent{
+			Text: fmt.Sprintf("Connected to RCON server at %s (session: %s)", session.Address, args.SessionID),
+		}},
+	}, nil
+}
+
+// openSession creates a session and connects and authenticates it to the
+// server named by args, filling in details from the profile if one is
+// given. The attempt is recorded in the audit log and the event log.
+func openSession(ctx context.Context, args ConnectParams) (session *rcon.Session, err error) {
+	start := time.Now()
+	owner := callerKey(ctx)
+	if args.Shared {
+		owner = ""
+	}
+	reconnect := droppedSessions.has(args.SessionID)
+	if reconnect {
+		addEvent(ctx, Event{Type: eventReconnectAttempt, SessionID: args.SessionID, Address: args.Address, owner: owner})
+	}
+	defer func() {
+		recordAudit(ctx, audit.Entry{Event: audit.EventConnect, SessionID: args.SessionID, Address: args.Address,
+			LatencyMillis: time.Since(start).Milliseconds(), Error: errorText(err)})
+		e := Event{Type: eventConnected, SessionID: args.SessionID, Address: args.Address, owner: owner}
+		switch {
+		case err != nil:
+			e.Type, e.Detail = eventConnectFailed, err.Error()
+		case reconnect:
+			e.Type = eventReconnected
+		}
+		addEvent(ctx, e)
+	}()
+
+	// Fill in connection details from the profile, if one was named.
+	// Explicit arguments take precedence over the profile's values.
+	gameType, role, profileName, connections := game.Unknown, "", "", 1
+	var profile *config.Profile
+	autoReconnect := args.AutoReconnect
+	if args.Profile != "" {
+		if profile, err = serverConfig.Profile(args.Profile); err != nil {
+			return nil, fmt.Errorf("failed to resolve profile: %w", err)
+		}
+		if args.Address == "" {
+			args.Address = profile.Address
+		}
+		if args.Password == "" {
+			if args.Password, err = profile.ResolvePassword(os.LookupEnv); err != nil {
+				return nil, err
+			}
+		}
+		if args.Name == "" {
+			args.Name = profile.Name
+		}
+		if profile.Game != "" {
+			gameType = game.Type(profile.Game)
+		}
+		if args.Protocol == "" {
+			args.Protocol = profile.WireProtocol()
+		}
+		role, profileName, connections = profile.Role, profile.Name, profile.Pool
+		autoReconnect = autoReconnect || profile.AutoReconnect
+	}
+	protocol, err := wireProtocol(args.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	caps, _ := rcon.ProtocolCapabilities(protocol)
+	if gameType == game.Unknown && caps.Game != "" {
+		// Some protocols serve one game only, such as Rust's WebRCON
+		gameType = caps.Game
+	}
+	if args.Address == "" {
+		return nil, errors.New("an address or profile is required")
+	}
+	if args.Password == "" && usesPassword(protocol, profile) {
+		// Asking the user for the password out-of-band via MCP elicitation
+		// would keep it out of the transcript, but the MCP SDK in use does
+		// not support elicitation yet, so point at the alternatives instead.
+		return nil, errors.New("a password is required: configure a server profile with a password " +
+			"(see rcon_list_profiles) rather than passing it in the conversation")
+	}
+
+	redact.AddSecret(args.Password)
+
 	// Create a new session
-	session, err := sessionManager.CreateSession(params.Arguments.SessionID, params.Arguments.Name, params.Arguments.Address)
+	session, err = sessionManager.CreateSession(args.SessionID, args.Name, args.Address)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	session.SetGame(gameType)
+	session.SetRole(role)
+	session.SetProfile(profileName)
+	session.Client.SetProtocol(protocol)
+	session.Client.SetBackend(ProfileBackend(protocol, profile))
+	if caps.Console || game.PushesChat(gameType) {
+		session.Client.SetConsoleHandler(func(m rcon.ConsoleMessage) { consoles.add(session, m) })
+	}
 
 	// Connect to the server
-	if err := session.Client.Connect(params.Arguments.Address); err != nil {
-		_ = sessionManager.RemoveSession(params.Arguments.SessionID)
+	if err := session.Client.Connect(args.Address); err != nil {
+		_ = sessionManager.RemoveSession(args.SessionID)
 		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
 
 	// Authenticate
-	if err := session.Client.Authenticate(params.Arguments.Password); err != nil {
-		_ = sessionManager.RemoveSession(params.Arguments.SessionID)
+	if err := session.Client.Authenticate(args.Password); err != nil {
+		_ = sessionManager.RemoveSession(args.SessionID)
 		return nil, fmt.Errorf("failed to authenticate: %w", err)
 	}
+	if autoReconnect {
+		session.SetAutoReconnect(args.Password)
+	}
 
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{&mcp.TextContent{
-			Text: fmt.Sprintf("Connected to RCON server at %s (session: %s)", params.Arguments.Address, params.Arguments.SessionID),
-		}},
-	}, nil
+	// Open the profile's connection pool. The session works without it,
+	// with fewer connections, so failures are only logged.
+	if err := session.OpenPool(connections, args.Password); err != nil {
+		logger(ctx).Warn("Failed to open pooled RCON connections", "session_id", args.SessionID,
+			"pool", connections, "open", session.Connections(), "error", err)
+	}
+
+	serverMetrics.RecordConnect(args.SessionID)
+	logger(ctx).Info("RCON session connected", "session_id", args.SessionID, "address", args.Address)
+	notifyReconnected(session)
+	return session, nil
+}
+
+// wireProtocol returns the protocol named by a connect argument or
+// profile, Source RCON if it is empty.
+func wireProtocol(name string) (rcon.Protocol, error) {
+	if name == "" {
+		return rcon.ProtocolRCON, nil
+	}
+	if !slices.Contains(config.Protocols, name) {
+		return "", fmt.Errorf("unknown protocol %q, want one of %s", name, strings.Join(config.Protocols, ", "))
+	}
+	return rcon.Protocol(name), nil
+}
+
+// ProfileBackend returns the function making the Backends that a client
+// speaking protocol reaches the server of profile through, configured
+// from the profile, or nil if the protocol needs no configuring or there
+// is no profile.
+func ProfileBackend(protocol rcon.Protocol, profile *config.Profile) func() rcon.Backend {
+	if profile == nil {
+		return nil
+	}
+	switch protocol {
+	case rcon.ProtocolGenericTelnet:
+		return rcon.GenericTelnet(telnetDialect(profile.Telnet))
+	case rcon.ProtocolGenericWebSocket:
+		return rcon.GenericWebSocket(webSocketDialect(profile.WebSocket))
+	case rcon.ProtocolGenericHTTP:
+		return rcon.GenericHTTP(httpDialect(profile.HTTP))
+	}
+	return nil
+}
+
+// usesPassword reports whether a client speaking protocol logs in to the
+// server of profile with a password. The generic protocols only do when
+// their dialect has a password placeholder; the others always do.
+func usesPassword(protocol rcon.Protocol, profile *config.Profile) bool {
+	if profile == nil {
+		profile = &config.Profile{}
+	}
+	switch protocol {
+	case rcon.ProtocolGenericTelnet:
+		return telnetDialect(profile.Telnet).UsesPassword()
+	case rcon.ProtocolGenericWebSocket:
+		return webSocketDialect(profile.WebSocket).UsesPassword()
+	case rcon.ProtocolGenericHTTP:
+		return httpDialect(profile.HTTP).UsesPassword()
+	}
+	return true
+}
+
+// telnetDialect returns the dialect of the telnet console t describes,
+// the default one if t is nil.
+func telnetDialect(t *config.TelnetConsole) rcon.TelnetDialect {
+	if t == nil {
+		return rcon.TelnetDialect{}
+	}
+	d := rcon.TelnetDialect{LoginFailed: t.LoginFailed, Prompt: t.Prompt, Terminator: t.Terminator}
+	for _, step := range t.Login {
+		d.Login = append(d.Login, rcon.TelnetStep{Expect: step.Expect, Send: step.Send})
+	}
+	return d
+}
+
+// webSocketDialect returns the dialect of the WebSocket console w
+// describes, the default one if w is nil.
+func webSocketDialect(w *config.WebSocketConsole) rcon.WebSocketDialect {
+	if w == nil {
+		return rcon.WebSocketDialect{}
+	}
+	return rcon.WebSocketDialect(*w)
+}
+
+// httpDialect returns the dialect of the HTTP API h describes, the
+// default one if h is nil.
+func httpDialect(h *config.HTTPConsole) rcon.HTTPDialect {
+	if h == nil {
+		return rcon.HTTPDialect{}
+	}
+	return rcon.HTTPDialect(*h)
+}
+
+// RegisterProtocol adds the protocol p, spoken by the backends newBackend
+// makes, to the ones sessions and profiles can use. If the protocol serves
+// one game, profiles of that game use it by default unless another
+// protocol already claims the game. Returns an error if p is taken.
+func RegisterProtocol(p rcon.Protocol, newBackend func() rcon.Backend) error {
+	if err := rcon.RegisterProtocol(p, newBackend); err != nil {
+		return err
+	}
+	config.Protocols = append(config.Protocols, string(p))
+	caps, _ := rcon.ProtocolCapabilities(p)
+	if g := string(caps.Game); g != "" && caps.Game != game.Unknown {
+		if _, ok := config.GameProtocols[g]; !ok {
+			config.GameProtocols[g] = string(p)
+		}
+	}
+	return nil
 }
 
 // Disconnect terminates an existing RCON connection and removes the session.
 // Returns an error if the session doesn't exist.
 func Disconnect(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[DisconnectParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := getSession(ctx, params.Arguments.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to disconnect: %w", err)
+	}
+	gameLogs.unregister(ctx, session)
+	consoles.forget(session.ID)
 	if err := sessionManager.RemoveSession(params.Arguments.SessionID); err != nil {
 		return nil, fmt.Errorf("failed to disconnect: %w", err)
 	}
+	logger(ctx).Info("RCON session disconnected", "session_id", params.Arguments.SessionID)
+	recordAudit(ctx, audit.Entry{Event: audit.EventDisconnect, SessionID: session.ID, Address: session.Address})
+	recordEvent(ctx, eventDisconnected, session, "")
 
 	return &mcp.CallToolResultFor[any]{
 		Content: []mcp.Content{&mcp.TextContent{
@@ -82,31 +346,144 @@ func Disconnect(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallTool
 
 // Execute sends a command to the RCON server and returns the response.
 // The session must exist and be authenticated. Returns an error if the session
-// is not found or if command execution fails.
+// is not found. Failed or rejected commands are reported as tool results with
+// isError set, carrying the server's own output so the model can correct it.
+// An empty response from a game that sends one for commands that succeeded
+// is reported as a game.Change.
 func Execute(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ExecuteParams]) (*mcp.CallToolResultFor[any], error) {
 	// Get the session
-	session, err := sessionManager.GetSession(params.Arguments.SessionID)
+	session, err := getSession(ctx, params.Arguments.SessionID)
 	if err != nil {
-		return nil, fmt.Errorf("session not found: %w", err)
+		return nil, err
 	}
 
 	// Execute the command
-	response, err := session.Client.Execute(params.Arguments.Command)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute command: %w", err)
+	response, meta, err := executeWithMetadata(ctx, session, params.Arguments.Command)
+	result := &ExecuteResult{Output: response, Metadata: meta}
+	switch {
+	case err != nil:
+		result.Error = fmt.Sprintf("failed to execute command: %v", err)
+		result.ErrorCode = errorCode(err)
+		return errorResult(result.ErrorCode, result.Error, result), nil
+	case meta.Rejected:
+		result.Error, result.ErrorCode = errRejected, CodeRejected
+		return errorResult(result.ErrorCode, response, result), nil
+	}
+
+	if change, ok := game.EmptyReply(session.Game(), response); ok {
+		// The server's way of saying the command succeeded
+		return changeResult(result, change)
+	}
+	if params.Arguments.Structured {
+		return structuredResult(session, params.Arguments.Command, result), nil
 	}
 
 	return &mcp.CallToolResultFor[any]{
 		Content: []mcp.Content{&mcp.TextContent{
 			Text: response,
 		}},
+		StructuredContent: result,
+	}, nil
+}
+
+// errorResult builds a tool result flagged as an error, with its code in
+// the result's _meta. Unlike returning an error from a handler, it keeps
+// the structured content, such as partial output and execution metadata.
+func errorResult(code ErrorCode, text string, structured any) *mcp.CallToolResultFor[any] {
+	return &mcp.CallToolResultFor[any]{
+		Meta:              mcp.Meta{"error_code": code},
+		Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+		StructuredContent: structured,
+		IsError:           true,
+	}
+}
+
+// changeResult builds an execute result carrying change as the parsed
+// form of the command's output.
+func changeResult(result *ExecuteResult, change *game.Change) (*mcp.CallToolResultFor[any], error) {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return nil, err
+	}
+	result.Parsed = change
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+		StructuredContent: result,
+	}, nil
+}
+
+// structuredResult builds an execute result carrying the parsed form of a
+// command's output. If no parser understands the output, the raw text is
+// returned along with a note explaining why it is not structured.
+func structuredResult(session *rcon.Session, command string, result *ExecuteResult) *mcp.CallToolResultFor[any] {
+	parsed, err := game.Parse(session.Game(), command, result.Output)
+	if err == nil {
+		data, marshalErr := json.Marshal(parsed)
+		if marshalErr == nil {
+			result.Parsed = parsed
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+				StructuredContent: result,
+			}
+		}
+		err = marshalErr
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: result.Output},
+			&mcp.TextContent{Text: fmt.Sprintf("Structured output unavailable: %v", err)},
+		},
+		StructuredContent: result,
+	}
+}
+
+// DetectGame identifies the game running on the server behind a session.
+// It sends the harmless probe commands from game.Probes one at a time until a
+// response identifies the game, then records the result on the session; a
+// session whose game is not identified keeps the one it has. Probes go
+// through executeWithMetadata like any other command, and those the
+// session's role or the server policies refuse are skipped.
+// Returns an error if the session is not found or a probe cannot be executed.
+func DetectGame(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[DetectGameParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := getSession(ctx, params.Arguments.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	detected := game.Unknown
+	evidence := ""
+	for _, probe := range game.Probes {
+		if !serverConfig.AllowsCommand(session.Role(), session.Game(), probe) {
+			continue
+		}
+		response, _, err := executeWithMetadata(ctx, session, probe)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute probe %q: %w", probe, err)
+		}
+		if detected = game.Match(probe, response); detected != game.Unknown {
+			evidence = probe
+			break
+		}
+	}
+
+	text := fmt.Sprintf("Could not identify the game running on session %s", session.ID)
+	if detected != game.Unknown {
+		session.SetGame(detected)
+		text = fmt.Sprintf("Detected game %s on session %s (matched probe %q)", detected, session.ID, evidence)
+	}
+
+	return &mcp.CallToolResultFor[any]{
+		Content: []mcp.Content{&mcp.TextContent{
+			Text: text,
+		}},
 	}, nil
 }
 
 // ListSessions retrieves information about all active RCON sessions.
 // It returns session IDs, names, addresses, and connection/authentication status.
 func ListSessions(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ListSessionsParams]) (*mcp.CallToolResultFor[any], error) {
-	sessions := sessionManager.ListSessions()
+	sessions := visibleSessions(ctx)
 
 	if len(sessions) == 0 {
 		return &mcp.CallToolResultFor[any]{
@@ -118,21 +495,27 @@ func ListSessions(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallTo
 
 	sessionInfo := "Active RCON sessions:\n"
 	for _, session := range sessions {
-		status := "disconnected"
-		if session.Client.IsConnected() {
-			if session.Client.IsAuthenticated() {
-				status = "connected & authenticated"
-			} else {
-				status = "connected (not authenticated)"
-			}
-		}
+		status := sessionStatus(session)
 
 		name := session.Name
 		if name == "" {
 			name = "unnamed"
 		}
 
-		sessionInfo += fmt.Sprintf("- %s (%s): %s - %s\n", session.ID, name, session.Address, status)
+		sessionInfo += fmt.Sprintf("- %s (%s): %s - %s", session.ID, name, session.Address, status)
+		if g := session.Game(); g != game.Unknown {
+			sessionInfo += fmt.Sprintf(" - game: %s", g)
+		}
+		if role := session.Role(); role != "" {
+			sessionInfo += fmt.Sprintf(" - role: %s", role)
+		}
+		if owner, shared := session.Owner(); owner != "" && shared {
+			sessionInfo += " - shared"
+		}
+		if n := session.Connections(); n > 1 {
+			sessionInfo += fmt.Sprintf(" - %d connections", n)
+		}
+		sessionInfo += "\n"
 	}
 
 	return &mcp.CallToolResultFor[any]{
@@ -142,45 +525,442 @@ func ListSessions(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallTo
 	}, nil
 }
 
-// Serve initializes and runs the MCP server.
-// It registers all RCON tools and starts listening for MCP connections via stdio.
-// The function blocks until the server is terminated or encounters a fatal error.
-func Serve() {
-	// Create a server
-	server := mcp.NewServer(&mcp.Implementation{
-		Name:    "rcon-mcp-server",
-		Version: "v1.0.0",
-	}, nil)
-
-	// Register RCON tools
-	mcp.AddTool(server, &mcp.Tool{
+// registerTools adds all enabled RCON tools to the given MCP server.
+// Each tool carries annotations describing its side effects so that clients
+// can decide which calls need explicit user confirmation.
+func registerTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
 		Name:        "rcon_connect",
 		Description: "Connect to an RCON server and authenticate",
+		InputSchema: passwordlessSchema[ConnectParams](),
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Connect to RCON server",
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(true),
+		},
 	}, Connect)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "rcon_disconnect",
 		Description: "Disconnect from an RCON server",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Disconnect RCON session",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
 	}, Disconnect)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "rcon_execute",
 		Description: "Execute a command on an RCON server",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Execute RCON command",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
 	}, Execute)
 
-	mcp.AddTool(server, &mcp.Tool{
+	addTool(server, &mcp.Tool{
 		Name:        "rcon_list_sessions",
 		Description: "List all active RCON sessions",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List RCON sessions",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(false),
+		},
 	}, ListSessions)
 
-	fmt.Println("RCON MCP server is ready!")
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_detect_game",
+		Description: "Identify the game running on an RCON server using harmless probe commands",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Detect game type",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, DetectGame)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_execute_multi",
+		Description: "Execute commands on several RCON sessions concurrently and return the results keyed by session",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Execute RCON commands in parallel",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ExecuteMulti)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_test_connection",
+		Description: "Check that an RCON server is reachable and the password is valid, without creating a session",
+		InputSchema: passwordlessSchema[TestConnectionParams](),
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Test RCON connection",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, ProbeConnection)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_broadcast",
+		Description: "Execute the same command on every session in a group and/or a list of sessions",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Broadcast RCON command",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, Broadcast)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_group_create",
+		Description: "Create a named group of sessions that can be targeted together",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Create session group",
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, GroupCreate)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_group_add",
+		Description: "Add sessions to an existing session group",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Add sessions to group",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, GroupAdd)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_group_remove",
+		Description: "Remove sessions from a session group, or delete the group when no sessions are given",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Remove sessions from group",
+			DestructiveHint: boolPtr(true),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(false),
+		},
+	}, GroupRemove)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_group_list",
+		Description: "List all session groups and their members",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List session groups",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(false),
+		},
+	}, GroupList)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_list_profiles",
+		Description: "List the configured RCON server profiles that can be used with rcon_connect",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List server profiles",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(false),
+		},
+	}, ListProfiles)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_execute_script",
+		Description: "Execute a newline-separated script of commands on a session and return a per-line report",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Execute RCON script",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ExecuteScript)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_history",
+		Description: "Search the commands previously executed on a session by text, regex, time range or failure",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Search command history",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(false),
+		},
+	}, SearchHistory)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_wait_for",
+		Description: "Run a command on an interval until its output matches a regular expression or a timeout elapses",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Wait for RCON output",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, WaitFor)
+
+	addTool(server, &mcp.Tool{
+		Name: "rcon_tail_logs",
+		Description: "Return the latest chat, kill, connect and other log lines of a Source engine session, filtered by kind. " +
+			"The first call registers the game log receiver with the server through logaddress_add; needs game_logs.listen in the config",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Tail Source game log",
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, TailLogs)
+
+	addTool(server, &mcp.Tool{
+		Name: "rcon_console_stream",
+		Description: "Return the latest console output and chat a server sent on its own, filtered by type: a Rust server over WebRCON, " +
+			"a 7 Days to Die server over telnet, a DayZ or Arma server over BattlEye RCon, or a Squad or Post Scriptum server over RCON. Messages are kept from when the session connected",
+		Annotations: &mcp.ToolAnnotations{
+			Title:         "Console stream",
+			ReadOnlyHint:  true,
+			OpenWorldHint: boolPtr(false),
+		},
+	}, ConsoleStream)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_metrics",
+		Description: "Report aggregate command counts, error rates, latency percentiles, reconnects and uptime for the sessions this client may use",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "RCON server metrics",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(false),
+		},
+	}, GetMetrics)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_quota_status",
+		Description: "Report how many commands the configured session and profile quotas still allow, and when they free up",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "RCON command quotas",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(false),
+		},
+	}, QuotaStatus)
+
+	addTool(server, &mcp.Tool{
+		Name:        "rcon_help",
+		Description: "Look up the commands a server supports using its native help output, cached per session",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Search server command help",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, Help)
+
+	registerGamePacks(server)
+}
+
+// passwordlessSchema returns the input schema of In without its password
+// property when strict password mode is on, so that clients cannot send
+// one, and nil otherwise so the schema is inferred from In as usual.
+func passwordlessSchema[In any]() *jsonschema.Schema {
+	if !serverConfig.Policies.StrictPasswords {
+		return nil
+	}
+	schema, err := jsonschema.For[In]()
+	if err != nil {
+		panic(fmt.Sprintf("inferring tool schema: %v", err))
+	}
+	delete(schema.Properties, "password")
+	schema.Required = slices.DeleteFunc(schema.Required, func(name string) bool { return name == "password" })
+	return schema
+}
+
+// addTool registers a tool unless the configuration disables it, renaming
+// it according to the configured tool prefix. Calls are recorded in the
+// calling client's state and abandoned when the client disconnects, and
+// what they log carries the tool name and a request ID. The request ID is
+// returned in the result's _meta, so a caller can look the call up in the
+// server's log, audit log and events.
+func addTool[In, Out any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	knownTools[tool.Name] = true
+	if !serverConfig.Tools.Enabled(tool.Name) {
+		return
+	}
+	tool.Name = serverConfig.Tools.Name(tool.Name)
+	mcp.AddTool(server, tool, func(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[In]) (*mcp.CallToolResultFor[Out], error) {
+		ctx, done := clients.called(ctx, cc)
+		defer done()
+		ctx = toolContext(ctx, tool.Name)
+		start := time.Now()
+		result, err := handler(ctx, cc, params)
+		code := errorCode(err)
+		if err != nil {
+			logger(ctx).Debug("Tool call failed", "duration", time.Since(start), "error", err, "error_code", code)
+		} else {
+			logger(ctx).Debug("Tool call finished", "duration", time.Since(start))
+		}
+		// Errors are returned as results so that they carry their code;
+		// the code is taken before redaction, which loses the error chain.
+		result, err = redactResult(result, err)
+		if err != nil {
+			result = toolError[Out](code, err)
+		}
+		if result != nil {
+			if result.Meta == nil {
+				result.Meta = mcp.Meta{}
+			}
+			result.Meta["request_id"] = logging.RequestIDFromContext(ctx)
+		}
+		return result, nil
+	})
+}
+
+// unknownDisabledTools returns the disabled tool names in the configuration
+// that do not match any tool, which usually indicates a typo.
+func unknownDisabledTools() []string {
+	var unknown []string
+	for _, name := range serverConfig.Tools.Disabled {
+		if !knownTools[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// preloadSessions opens the sessions declared in the configuration. A
+// session that fails to connect is logged and skipped so that the server
+// still starts; clients can retry it with rcon_connect.
+func preloadSessions(sessions []*config.Session) {
+	for _, s := range sessions {
+		_, err := openSession(context.Background(), ConnectParams{
+			SessionID: s.ID,
+			Name:      s.Name,
+			Profile:   s.Profile,
+			Address:   s.Address,
+			Password:  s.Password,
+		})
+		if err != nil {
+			logger(context.Background()).Warn("Failed to preload RCON session", "session_id", s.ID, "error", err)
+		}
+	}
+}
+
+// boolPtr returns a pointer to b, for optional boolean annotation fields.
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// NewServer builds the MCP server with every enabled tool registered,
+// configured from cfg and backed by sessions. A nil cfg keeps the current
+// configuration and a nil sessions keeps the current session manager.
+// Dropped connections are reported to the server's clients, but no session
+// is opened and no health check is started; Serve does both. Returns an
+// error if the configured audit log cannot be opened.
+//
+// The configuration and session manager are process-wide, so a process
+// should build a single server.
+func NewServer(cfg *config.Config, sessions *rcon.SessionManager) (*mcp.Server, error) {
+	if cfg != nil {
+		serverConfig = cfg
+	}
+	if sessions != nil {
+		sessionManager = sessions
+	}
+	sessionManager.SetLimits(serverConfig.Limits.MaxSessions, serverConfig.Limits.HistorySize)
+	sessionManager.SetReadOptions(rcon.ReadOptions{
+		BufferSize:    serverConfig.Limits.ReadBuffer,
+		MaxPacketSize: serverConfig.Limits.MaxPacketSize,
+	})
+	registerSecrets(serverConfig)
+	registerResponseRules(serverConfig)
+	if err := openAudit(serverConfig.Audit); err != nil {
+		return nil, err
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{
+		Name:    "rcon-mcp-server",
+		Version: version.Get().Version,
+	}, &mcp.ServerOptions{
+		CompletionHandler:  Complete,
+		InitializedHandler: clients.connected,
+	})
+	if err := openGameLogs(serverConfig.GameLogs, server); err != nil {
+		closeAudit()
+		return nil, err
+	}
+	server.AddReceivingMiddleware(clients.identify)
+
+	registerTools(server)
+	registerResources(server)
+	if unknown := unknownDisabledTools(); len(unknown) > 0 {
+		logger(context.Background()).Warn("Ignoring unknown tools in disabled list", "tools", strings.Join(unknown, ", "))
+	}
+
+	// Report dropped connections to clients as they are detected
+	sessionManager.SetDropHandler(func(session *rcon.Session, err error) {
+		logger(context.Background()).Warn("RCON session dropped", "session_id", session.ID, "address", session.Address, "error", err)
+		serverMetrics.RecordDrop(session.ID)
+		recordAudit(context.Background(), audit.Entry{Event: audit.EventDisconnect, SessionID: session.ID,
+			Address: session.Address, Error: err.Error()})
+		recordEvent(context.Background(), eventDropped, session, err.Error())
+		gameLogs.forget(session.ID)
+		notifySessionDropped(server, session, err)
+	})
+	sessionManager.SetExpireHandler(func(session *rcon.Session, idle time.Duration) {
+		logger(context.Background()).Info("Closed idle RCON session", "session_id", session.ID, "address", session.Address,
+			"idle", idle.Round(time.Second))
+		recordAudit(context.Background(), audit.Entry{Event: audit.EventDisconnect, SessionID: session.ID, Address: session.Address})
+		recordEvent(context.Background(), eventDisconnected, session, fmt.Sprintf("idle for %s", idle.Round(time.Second)))
+		gameLogs.forget(session.ID)
+		consoles.forget(session.ID)
+		notifySessionExpired(server, session, idle)
+	})
+	sessionManager.SetReconnectHandler(func(session *rcon.Session) {
+		serverMetrics.RecordConnect(session.ID)
+		recordAudit(context.Background(), audit.Entry{Event: audit.EventConnect, SessionID: session.ID, Address: session.Address})
+		recordEvent(context.Background(), eventReconnected, session, "reconnected to retry a command")
+		// The drop that caused the retry may not have been reported yet
+		droppedSessions.add(session.ID)
+		gameLogs.forget(session.ID)
+		notifyReconnected(session)
+	})
+	sessionManager.SetHealthCheckHandler(func(session *rcon.Session, err error) {
+		logger(context.Background()).Warn("RCON health check failed", "session_id", session.ID, "address", session.Address, "error", err)
+		recordEvent(context.Background(), eventHealthCheckFailed, session, err.Error())
+		notifyHealthCheckFailed(server, session, err)
+	})
+	return server, nil
+}
+
+// Serve initializes and runs the MCP server using the given configuration.
+// It registers all RCON tools and starts listening for MCP connections on the
+// configured transport, stdio by default.
+// The function blocks until the server is terminated or encounters a fatal error.
+func Serve(cfg *config.Config) {
+	server, err := NewServer(cfg, nil)
+	if err != nil {
+		logger(context.Background()).Error("MCP server failed", "error", err)
+		os.Exit(1)
+	}
+	defer closeAudit()
+	defer closeGameLogs()
+	preloadSessions(serverConfig.Sessions)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sessionManager.StartHealthCheck(ctx, healthCheckInterval)
+
 	// Run the server
-	if err := server.Run(context.Background(), mcp.NewStdioTransport()); err != nil {
-		log.Fatal(err)
+	if !serverConfig.Logging.Quiet {
+		logger(ctx).Info("Starting MCP server", "version", version.Get().Version, "transport", serverConfig.Transport.Kind(),
+			"read_only", serverConfig.Policies.ReadOnly)
+	}
+	if err := run(ctx, server, serverConfig.Transport, !serverConfig.Logging.Quiet); err != nil {
+		logger(ctx).Error("MCP server failed", "error", err)
+		os.Exit(1)
 	}
+	cancel()
 
 	// Cleanup all sessions on exit to ensure graceful shutdown
 	if err := sessionManager.DisconnectAll(); err != nil {
-		log.Printf("Failed to disconnect all sessions cleanly: %v", err)
+		logger(ctx).Warn("Failed to disconnect all sessions cleanly", "error", err)
 	}
 }
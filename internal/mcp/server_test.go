@@ -2,10 +2,15 @@ package mcp
 
 import (
 	"context"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
 	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcontest"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -160,6 +165,7 @@ func TestExecute(t *testing.T) {
 		params      ExecuteParams
 		setupFunc   func()
 		wantErr     bool
+		wantIsError bool
 		errContains string
 	}{
 		{
@@ -186,7 +192,7 @@ func TestExecute(t *testing.T) {
 				// Session exists but client is not connected
 				session.Client = rcon.NewClient()
 			},
-			wantErr:     true,
+			wantIsError: true,
 			errContains: "not connected",
 		},
 	}
@@ -217,11 +223,44 @@ func TestExecute(t *testing.T) {
 				if result == nil {
 					t.Fatal("Expected result but got nil")
 				}
+				if result.IsError != tt.wantIsError {
+					t.Errorf("Expected IsError %v, got %v", tt.wantIsError, result.IsError)
+				}
+				text := result.Content[0].(*mcp.TextContent).Text
+				if tt.errContains != "" && !strings.Contains(text, tt.errContains) {
+					t.Errorf("Expected result containing %q, got %q", tt.errContains, text)
+				}
 			}
 		})
 	}
 }
 
+func TestExecute_Rejected(t *testing.T) {
+	resetSessionManager()
+	session := connectFakeSession(t, "mc", func(command string) string {
+		return "Unknown or incomplete command, see below for error\nfoo<--[HERE]"
+	})
+	session.SetGame(game.Minecraft)
+
+	params := &mcp.CallToolParamsFor[ExecuteParams]{
+		Arguments: ExecuteParams{SessionID: "mc", Command: "foo"},
+	}
+	result, err := Execute(context.Background(), nil, params)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if !result.IsError {
+		t.Error("Expected rejected command to be flagged as an error")
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Unknown or incomplete command") {
+		t.Errorf("Expected the server's error text, got %q", text)
+	}
+	structured := result.StructuredContent.(*ExecuteResult)
+	if !structured.Metadata.Rejected || structured.Error != errRejected {
+		t.Errorf("Expected rejection in structured content, got %+v", structured)
+	}
+}
+
 func TestListSessions(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -301,3 +340,413 @@ func TestListSessions(t *testing.T) {
 	}
 }
 
+
+// connectTestClient registers the RCON tools on a fresh MCP server and returns
+// a client session connected to it over an in-memory transport.
+func connectTestClient(t *testing.T) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	registerTools(server)
+	if _, err := server.Connect(ctx, serverTransport); err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil)
+	cs, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+func TestRegisterToolsAnnotations(t *testing.T) {
+	tests := []struct {
+		tool            string
+		wantReadOnly    bool
+		wantDestructive bool
+		wantIdempotent  bool
+	}{
+		{tool: "rcon_connect"},
+		{tool: "rcon_disconnect", wantIdempotent: true},
+		{tool: "rcon_execute", wantDestructive: true},
+		{tool: "rcon_list_sessions", wantReadOnly: true, wantIdempotent: true},
+		{tool: "rcon_detect_game", wantIdempotent: true},
+	}
+
+	cs := connectTestClient(t)
+	res, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+
+	tools := make(map[string]*mcp.Tool)
+	for _, tool := range res.Tools {
+		tools[tool.Name] = tool
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			tool, ok := tools[tt.tool]
+			if !ok {
+				t.Fatalf("Tool %s not registered", tt.tool)
+			}
+			a := tool.Annotations
+			if a == nil {
+				t.Fatal("Expected annotations to be set")
+			}
+			if a.ReadOnlyHint != tt.wantReadOnly {
+				t.Errorf("ReadOnlyHint = %v, want %v", a.ReadOnlyHint, tt.wantReadOnly)
+			}
+			// DestructiveHint is only meaningful for tools that modify state.
+			if !a.ReadOnlyHint && (a.DestructiveHint == nil || *a.DestructiveHint != tt.wantDestructive) {
+				t.Errorf("DestructiveHint = %v, want %v", a.DestructiveHint, tt.wantDestructive)
+			}
+			if a.IdempotentHint != tt.wantIdempotent {
+				t.Errorf("IdempotentHint = %v, want %v", a.IdempotentHint, tt.wantIdempotent)
+			}
+		})
+	}
+}
+
+// startFakeRCONServer starts a minimal Source RCON server on a loopback port.
+// It accepts any password and answers each command with respond(command).
+// The server is shut down when the test completes.
+func startFakeRCONServer(t *testing.T, respond func(command string) string) string {
+	t.Helper()
+	return startFakeRCONServerWithPassword(t, "", respond)
+}
+
+// startFakeRCONServerWithPassword is like startFakeRCONServer but rejects
+// authentication attempts that do not use password. An empty password
+// accepts any attempt.
+func startFakeRCONServerWithPassword(t *testing.T, password string, respond func(command string) string) string {
+	t.Helper()
+	server := rcontest.NewServer(t, password, func(command string) rcontest.Reply {
+		switch reply := respond(command); reply {
+		case fakeMismatchReply:
+			return rcontest.Reply{Fault: rcontest.FaultMismatch}
+		case fakeCloseReply:
+			return rcontest.Reply{Fault: rcontest.FaultClose}
+		default:
+			return rcontest.Reply{Body: reply}
+		}
+	})
+	return server.Addr()
+}
+
+// fakeMismatchReply can be returned by a fake server's respond function to
+// answer with a packet whose ID does not match the request, which makes the
+// client's Execute fail without dropping the connection.
+const fakeMismatchReply = "\x00mismatch"
+
+// fakeCloseReply can be returned by a fake server's respond function to
+// close the connection instead of answering, as a server shutting down
+// does.
+const fakeCloseReply = "\x00close"
+
+// connectFakeSession creates an authenticated session against a fake RCON server.
+func connectFakeSession(t *testing.T, id string, respond func(command string) string) *rcon.Session {
+	t.Helper()
+	address := startFakeRCONServer(t, respond)
+	session, err := sessionManager.CreateSession(id, "Fake", address)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if err := session.Client.Connect(address); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if err := session.Client.Authenticate("password"); err != nil {
+		t.Fatalf("Failed to authenticate: %v", err)
+	}
+	t.Cleanup(func() { session.Client.Disconnect() })
+	return session
+}
+
+func TestRegisterTools_Config(t *testing.T) {
+	setServerConfig(t, &config.Config{Tools: config.Tools{
+		Prefix:   "mc_",
+		Disabled: []string{"rcon_connect", "rcon_nonexistent"},
+	}})
+
+	cs := connectTestClient(t)
+	res, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, tool := range res.Tools {
+		names[tool.Name] = true
+	}
+	if !names["mc_execute"] {
+		t.Errorf("Expected prefixed tool mc_execute, got %v", names)
+	}
+	if names["mc_connect"] || names["rcon_connect"] {
+		t.Error("Expected disabled connect tool to be absent")
+	}
+	if names["rcon_execute"] {
+		t.Error("Expected default tool names to be replaced")
+	}
+
+	if got := unknownDisabledTools(); len(got) != 1 || got[0] != "rcon_nonexistent" {
+		t.Errorf("Expected rcon_nonexistent to be reported as unknown, got %v", got)
+	}
+}
+
+func TestDetectGame(t *testing.T) {
+	tests := []struct {
+		name       string
+		respond    func(command string) string
+		wantGame   game.Type
+		wantText   string
+		wantProbes []string
+	}{
+		{
+			name: "minecraft",
+			respond: func(command string) string {
+				if command == "list" {
+					return "There are 1 of a max of 20 players online: Steve"
+				}
+				return "Unknown or incomplete command"
+			},
+			wantGame:   game.Minecraft,
+			wantText:   "Detected game minecraft",
+			wantProbes: []string{"/version", "version", "status", "list"},
+		},
+		{
+			name: "factorio stops after first probe",
+			respond: func(command string) string {
+				if command == "/version" {
+					return "1.1.100"
+				}
+				return ""
+			},
+			wantGame:   game.Factorio,
+			wantText:   "Detected game factorio",
+			wantProbes: []string{"/version"},
+		},
+		{
+			name:       "unrecognized server",
+			respond:    func(command string) string { return "" },
+			wantGame:   game.Unknown,
+			wantText:   "Could not identify",
+			wantProbes: []string{"/version", "version", "status", "list"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSessionManager()
+			var probes []string
+			session := connectFakeSession(t, "detect", func(command string) string {
+				probes = append(probes, command)
+				return tt.respond(command)
+			})
+
+			params := &mcp.CallToolParamsFor[DetectGameParams]{
+				Arguments: DetectGameParams{SessionID: "detect"},
+			}
+			result, err := DetectGame(context.Background(), nil, params)
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			text := result.Content[0].(*mcp.TextContent).Text
+			if !strings.Contains(text, tt.wantText) {
+				t.Errorf("Expected output to contain %q, got %q", tt.wantText, text)
+			}
+			if got := session.Game(); got != tt.wantGame {
+				t.Errorf("Expected session game %q, got %q", tt.wantGame, got)
+			}
+			if strings.Join(probes, ",") != strings.Join(tt.wantProbes, ",") {
+				t.Errorf("Expected probes %v, got %v", tt.wantProbes, probes)
+			}
+		})
+	}
+
+	t.Run("session not found", func(t *testing.T) {
+		resetSessionManager()
+		params := &mcp.CallToolParamsFor[DetectGameParams]{
+			Arguments: DetectGameParams{SessionID: "missing"},
+		}
+		if _, err := DetectGame(context.Background(), nil, params); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestDetectGame_PoliciesAndProfileGame(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{Policies: config.Policies{Deny: []string{"status"}}})
+	var probes []string
+	session := connectFakeSession(t, "detect", func(command string) string {
+		probes = append(probes, command)
+		return ""
+	})
+	session.SetGame(game.Valheim)
+
+	params := &mcp.CallToolParamsFor[DetectGameParams]{
+		Arguments: DetectGameParams{SessionID: "detect"},
+	}
+	if _, err := DetectGame(context.Background(), nil, params); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if got := session.Game(); got != game.Valheim {
+		t.Errorf("Expected an inconclusive detection to keep the configured game, got %q", got)
+	}
+	if got := strings.Join(probes, ","); got != "/version,version,list" {
+		t.Errorf("Expected the denied probe to be skipped, got %s", got)
+	}
+	if n := session.History.Len(); n != 3 {
+		t.Errorf("Expected the probes in the history, got %d entries", n)
+	}
+}
+
+func TestExecute_Structured(t *testing.T) {
+	tests := []struct {
+		name           string
+		command        string
+		structured     bool
+		wantText       string
+		wantStructured bool
+	}{
+		{
+			name:     "raw output by default",
+			command:  "list",
+			wantText: "There are 1 of a max of 20 players online: Steve",
+		},
+		{
+			name:           "parsed output",
+			command:        "list",
+			structured:     true,
+			wantText:       `"players":["Steve"]`,
+			wantStructured: true,
+		},
+		{
+			name:       "no parser falls back to raw output",
+			command:    "say hi",
+			structured: true,
+			wantText:   "ok",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSessionManager()
+			session := connectFakeSession(t, "exec", func(command string) string {
+				if command == "list" {
+					return "There are 1 of a max of 20 players online: Steve"
+				}
+				return "ok"
+			})
+			session.SetGame(game.Minecraft)
+
+			params := &mcp.CallToolParamsFor[ExecuteParams]{
+				Arguments: ExecuteParams{SessionID: "exec", Command: tt.command, Structured: tt.structured},
+			}
+			result, err := Execute(context.Background(), nil, params)
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			text := result.Content[0].(*mcp.TextContent).Text
+			if !strings.Contains(text, tt.wantText) {
+				t.Errorf("Expected output to contain %q, got %q", tt.wantText, text)
+			}
+			structured, ok := result.StructuredContent.(*ExecuteResult)
+			if !ok {
+				t.Fatalf("Expected *ExecuteResult structured content, got %T", result.StructuredContent)
+			}
+			if got := structured.Parsed != nil; got != tt.wantStructured {
+				t.Errorf("Expected parsed content %v, got %v", tt.wantStructured, got)
+			}
+		})
+	}
+}
+
+func TestExecute_Cancelled(t *testing.T) {
+	resetSessionManager()
+	session := connectFakeSession(t, "slow", func(command string) string {
+		if command == "save-all" {
+			time.Sleep(2 * time.Second)
+		}
+		return "ok"
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	params := &mcp.CallToolParamsFor[ExecuteParams]{
+		Arguments: ExecuteParams{SessionID: "slow", Command: "save-all"},
+	}
+	result, err := Execute(ctx, nil, params)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !result.IsError || !strings.Contains(text, context.Canceled.Error()) {
+		t.Fatalf("Expected cancellation error result, got %q", text)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected cancellation to return promptly, took %v", elapsed)
+	}
+
+	// The session stays usable and skips the late reply to the cancelled command
+	output, err := session.Client.Execute("list")
+	if err != nil {
+		t.Fatalf("Expected follow-up command to succeed, got %v", err)
+	}
+	if output != "ok" {
+		t.Errorf("Expected %q, got %q", "ok", output)
+	}
+}
+
+func TestNotifySessionDropped(t *testing.T) {
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	registerTools(server)
+	if _, err := server.Connect(ctx, serverTransport); err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+
+	received := make(chan *mcp.LoggingMessageParams, 1)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, _ *mcp.ClientSession, params *mcp.LoggingMessageParams) {
+			received <- params
+		},
+	})
+	cs, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer cs.Close()
+	if err := cs.SetLevel(ctx, &mcp.SetLevelParams{Level: "info"}); err != nil {
+		t.Fatalf("SetLevel failed: %v", err)
+	}
+
+	resetSessionManager()
+	session, _ := sessionManager.CreateSession("dropped", "Test", "localhost:25575")
+	notifySessionDropped(server, session, io.EOF)
+
+	select {
+	case params := <-received:
+		if params.Level != "warning" {
+			t.Errorf("Expected warning level, got %q", params.Level)
+		}
+		data, ok := params.Data.(map[string]any)
+		if !ok {
+			t.Fatalf("Expected map data, got %T", params.Data)
+		}
+		if data["event"] != "session_dropped" || data["session_id"] != "dropped" {
+			t.Errorf("Unexpected notification data: %v", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Client did not receive the drop notification")
+	}
+}
@@ -0,0 +1,241 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/query"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SourceServerInfoParams represents parameters for the source_server_info
+// tool
+type SourceServerInfoParams struct {
+	Profile string `json:"profile,omitempty" jsonschema:"Server profile to query (see rcon_list_profiles)"`
+	Address string `json:"address,omitempty" jsonschema:"Game server address (host:port, port 27015 if omitted), instead of a profile"`
+	Rules   bool   `json:"rules,omitempty" jsonschema:"Also return the server's public console variables with A2S_RULES (optional)"`
+}
+
+// SourcePlayersParams represents parameters for the source_players tool
+type SourcePlayersParams struct {
+	Profile string `json:"profile,omitempty" jsonschema:"Server profile to query (see rcon_list_profiles)"`
+	Address string `json:"address,omitempty" jsonschema:"Game server address (host:port, port 27015 if omitted), instead of a profile"`
+}
+
+// SourceSessionParams represents parameters for the Source tools that only
+// need a session
+type SourceSessionParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Source engine server"`
+}
+
+// SourceChangeLevelParams represents parameters for the source_changelevel
+// tool
+type SourceChangeLevelParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Source engine server"`
+	Map       string `json:"map" jsonschema:"Map to change to, e.g. de_dust2 or workshop/123456789/de_cache"`
+}
+
+// SourceExecConfigParams represents parameters for the source_exec_config
+// tool
+type SourceExecConfigParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Source engine server"`
+	Config    string `json:"config" jsonschema:"Config file to run, relative to the cfg directory, e.g. gamemode_competitive.cfg"`
+}
+
+// SourceKickParams represents parameters for the source_kick tool
+type SourceKickParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Source engine server"`
+	SteamID   string `json:"steam_id" jsonschema:"SteamID of the player: STEAM_1:0:12345, [U:1:24690] or 76561197960290418"`
+	Reason    string `json:"reason,omitempty" jsonschema:"Reason shown to the player (optional)"`
+}
+
+// SourceServerInfoResult is the result of the source_server_info tool.
+type SourceServerInfoResult struct {
+	*query.SourceInfo
+	Rules map[string]string `json:"rules,omitempty"`
+}
+
+// SourcePlayersResult is the result of the source_players tool.
+type SourcePlayersResult struct {
+	Count   int                  `json:"count"`
+	Players []query.SourcePlayer `json:"players"`
+}
+
+// registerSourceTools registers the tools of the source game pack, which
+// ask Source engine servers such as CS2, TF2 or Garry's Mod for their
+// state through the A2S queries, and run common match admin commands on
+// their sessions with checked arguments.
+func registerSourceTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name: "source_server_info",
+		Description: "Query a Source engine server (CS2, TF2, Garry's Mod...) with A2S_INFO for its name, map, game, player counts, " +
+			"version and flags, and optionally its public console variables. Needs no RCON session or password",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Query Source server",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, SourceServerInfo)
+
+	addTool(server, &mcp.Tool{
+		Name:        "source_players",
+		Description: "List the players on a Source engine server with A2S_PLAYER, with their scores and time connected. Needs no RCON session or password",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List Source players",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, SourcePlayers)
+
+	addTool(server, &mcp.Tool{
+		Name:        "source_status",
+		Description: "Run status on a Source engine server (CS2, CS:GO, TF2...) and return its hostname, map, player counts and player table",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "Source server status",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, SourceStatus)
+
+	addTool(server, &mcp.Tool{
+		Name:        "source_changelevel",
+		Description: "Change the map of a Source engine server, ending the current match",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Change Source map",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, SourceChangeLevel)
+
+	addTool(server, &mcp.Tool{
+		Name:        "source_exec_config",
+		Description: "Run a config file from a Source engine server's cfg directory, e.g. to switch game mode",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Execute Source config",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, SourceExecConfig)
+
+	addTool(server, &mcp.Tool{
+		Name:        "source_kick",
+		Description: "Kick a player from a Source engine server by SteamID",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Kick Source player",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, SourceKick)
+}
+
+// SourceServerInfo sends an A2S_INFO query, followed by A2S_RULES if
+// asked to, to a profile's server or an address.
+func SourceServerInfo(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[SourceServerInfoParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	address, err := statusAddress(args.Profile, args.Address, sourceQueryAddress)
+	if err != nil {
+		return nil, err
+	}
+	info, err := query.QuerySource(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	result := &SourceServerInfoResult{SourceInfo: info}
+	if args.Rules {
+		if result.Rules, err = query.QuerySourceRules(ctx, address); err != nil {
+			return nil, err
+		}
+	}
+	return statusResult(result)
+}
+
+// SourcePlayers sends an A2S_PLAYER query to a profile's server or an
+// address.
+func SourcePlayers(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[SourcePlayersParams]) (*mcp.CallToolResultFor[any], error) {
+	address, err := statusAddress(params.Arguments.Profile, params.Arguments.Address, sourceQueryAddress)
+	if err != nil {
+		return nil, err
+	}
+	players, err := query.QuerySourcePlayers(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	return statusResult(&SourcePlayersResult{Count: len(players), Players: players})
+}
+
+// SourceStatus runs "status" and returns it parsed.
+func SourceStatus(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[SourceSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Source)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, "status", func(output string) (any, error) {
+		return game.Parse(game.Source, "status", output)
+	})
+}
+
+// SourceChangeLevel runs "changelevel" with a map.
+func SourceChangeLevel(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[SourceChangeLevelParams]) (*mcp.CallToolResultFor[any], error) {
+	name := params.Arguments.Map
+	if !game.ValidSourceMap(name) {
+		return nil, fmt.Errorf("invalid map name %q: want letters, digits, underscores, dashes, dots and slashes", name)
+	}
+	return sourceChange(ctx, params.Arguments.SessionID, "changelevel "+name)
+}
+
+// SourceExecConfig runs "exec" with a config file.
+func SourceExecConfig(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[SourceExecConfigParams]) (*mcp.CallToolResultFor[any], error) {
+	name := params.Arguments.Config
+	if !game.ValidSourceConfig(name) {
+		return nil, fmt.Errorf("invalid config name %q: want a file in the cfg directory, of letters, digits, underscores, dashes, dots and slashes", name)
+	}
+	return sourceChange(ctx, params.Arguments.SessionID, "exec "+name)
+}
+
+// SourceKick runs "kickid" for a SteamID, with the reason if one is given.
+// The reason is quoted, so it may not itself contain quotes, nor the
+// semicolons and line breaks that separate console commands.
+func SourceKick(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[SourceKickParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if !game.ValidSteamID(args.SteamID) {
+		return nil, fmt.Errorf("invalid SteamID %q: want STEAM_1:0:12345, [U:1:24690] or 76561197960290418", args.SteamID)
+	}
+	reason := strings.TrimSpace(args.Reason)
+	if strings.ContainsAny(reason, "\";\r\n") {
+		return nil, errors.New("the reason may not contain quotes, semicolons or line breaks")
+	}
+	command := "kickid " + args.SteamID
+	if reason != "" {
+		command += ` "` + reason + `"`
+	}
+	return sourceChange(ctx, args.SessionID, command)
+}
+
+// sourceChange runs command on a Source session and reads the server's
+// reply as a game.Change.
+func sourceChange(ctx context.Context, sessionID, command string) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, sessionID, game.Source)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParseSourceChange(command, output)
+	})
+}
+
+// sourceQueryAddress returns the address a profile's server answers A2S
+// queries on: its query_address, or else its RCON address, since Source
+// servers take RCON on the game port.
+func sourceQueryAddress(profile *config.Profile) string {
+	if profile.QueryAddress != "" {
+		return profile.QueryAddress
+	}
+	return profile.Address
+}
@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/query"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// startA2SServer answers A2S_INFO, A2S_PLAYER and A2S_RULES queries on a
+// local UDP port for a CS2 server with one player, without challenges.
+func startA2SServer(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	responses := map[byte]string{
+		'T': "I\x11Arena\x00de_dust2\x00csgo\x00Counter-Strike 2\x00\xda\x02\x01\x10\x00dl\x00\x011.40.1.2\x00",
+		'U': "D\x01\x00Alice\x00\x05\x00\x00\x00\x00\x00\x70\x42",
+		'V': "E\x01\x00mp_timelimit\x0030\x00",
+	}
+	go func() {
+		buf := make([]byte, 1400)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if response, ok := responses[buf[4]]; ok && n > 4 {
+				conn.WriteTo(append([]byte{0xff, 0xff, 0xff, 0xff}, response...), addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestSourceTools(t *testing.T) {
+	address := startA2SServer(t)
+	setServerConfig(t, &config.Config{
+		Profiles: []*config.Profile{{Name: "arena", Address: address}},
+		Policies: config.Policies{ReadOnly: true},
+	})
+	ctx := context.Background()
+
+	res, err := SourceServerInfo(ctx, nil, &mcp.CallToolParamsFor[SourceServerInfoParams]{Arguments: SourceServerInfoParams{Profile: "arena", Rules: true}})
+	if err != nil {
+		t.Fatalf("SourceServerInfo failed: %v", err)
+	}
+	info := res.StructuredContent.(*SourceServerInfoResult)
+	if info.Name != "Arena" || info.Map != "de_dust2" || info.AppID != 730 || info.Players != 1 || info.MaxPlayers != 16 {
+		t.Errorf("Unexpected server info: %+v", info.SourceInfo)
+	}
+	if info.Rules["mp_timelimit"] != "30" {
+		t.Errorf("Expected the rules to be returned, got %v", info.Rules)
+	}
+
+	res, err = SourcePlayers(ctx, nil, &mcp.CallToolParamsFor[SourcePlayersParams]{Arguments: SourcePlayersParams{Profile: "arena"}})
+	if err != nil {
+		t.Fatalf("SourcePlayers failed: %v", err)
+	}
+	players := res.StructuredContent.(*SourcePlayersResult)
+	want := query.SourcePlayer{Name: "Alice", Score: 5, ConnectedSeconds: 60}
+	if players.Count != 1 || players.Players[0] != want {
+		t.Errorf("Expected %+v, got %+v", want, players)
+	}
+
+	// Read-only mode keeps the tools to the configured profiles.
+	_, err = SourcePlayers(ctx, nil, &mcp.CallToolParamsFor[SourcePlayersParams]{Arguments: SourcePlayersParams{Address: address}})
+	if !errors.Is(err, ErrPolicyDenied) {
+		t.Errorf("Expected an address to be denied in read-only mode, got %v", err)
+	}
+}
+
+func TestSourceMatchTools(t *testing.T) {
+	resetSessionManager()
+	var mu sync.Mutex
+	var sent []string
+	connectFakeSession(t, "cs", func(command string) string {
+		mu.Lock()
+		sent = append(sent, command)
+		mu.Unlock()
+		switch command {
+		case "status":
+			return "hostname: Arena\nmap     : de_dust2\nplayers : 1 humans, 0 bots (16 max)\n" +
+				"# userid name uniqueid connected ping loss state adr\n#  2 \"Alice\" STEAM_1:0:1 01:00 20 0 active 198.51.100.7:27005\n"
+		case "exec missing.cfg":
+			return "'missing.cfg' not present; not executing."
+		}
+		return ""
+	})
+	ctx := context.Background()
+	lastSent := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent[len(sent)-1]
+	}
+
+	res, err := SourceStatus(ctx, nil, &mcp.CallToolParamsFor[SourceSessionParams]{Arguments: SourceSessionParams{SessionID: "cs"}})
+	if err != nil || res.IsError {
+		t.Fatalf("SourceStatus failed: %v, %+v", err, res)
+	}
+	status := res.StructuredContent.(*ExecuteResult).Parsed.(*game.SourceStatus)
+	if status.Map != "de_dust2" || len(status.Players) != 1 || status.Players[0].UniqueID != "STEAM_1:0:1" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	res, err = SourceChangeLevel(ctx, nil, &mcp.CallToolParamsFor[SourceChangeLevelParams]{Arguments: SourceChangeLevelParams{SessionID: "cs", Map: "de_nuke"}})
+	if err != nil || res.IsError || lastSent() != "changelevel de_nuke" {
+		t.Errorf("Expected changelevel de_nuke to succeed, got %v, %+v after %q", err, res, lastSent())
+	}
+
+	res, err = SourceExecConfig(ctx, nil, &mcp.CallToolParamsFor[SourceExecConfigParams]{Arguments: SourceExecConfigParams{SessionID: "cs", Config: "missing.cfg"}})
+	if err != nil || !res.IsError || res.StructuredContent.(*ExecuteResult).ErrorCode != CodeRejected {
+		t.Errorf("Expected a missing config to be rejected, got %v, %+v", err, res)
+	}
+
+	res, err = SourceKick(ctx, nil, &mcp.CallToolParamsFor[SourceKickParams]{Arguments: SourceKickParams{SessionID: "cs", SteamID: "[U:1:24690]", Reason: " afk "}})
+	if err != nil || res.IsError || lastSent() != `kickid [U:1:24690] "afk"` {
+		t.Errorf("Expected the player to be kicked, got %v, %+v after %q", err, res, lastSent())
+	}
+
+	// Arguments that could carry further commands are refused before sending.
+	count := len(sent)
+	for _, call := range []func() (*mcp.CallToolResultFor[any], error){
+		func() (*mcp.CallToolResultFor[any], error) {
+			return SourceChangeLevel(ctx, nil, &mcp.CallToolParamsFor[SourceChangeLevelParams]{Arguments: SourceChangeLevelParams{SessionID: "cs", Map: "de_nuke;quit"}})
+		},
+		func() (*mcp.CallToolResultFor[any], error) {
+			return SourceExecConfig(ctx, nil, &mcp.CallToolParamsFor[SourceExecConfigParams]{Arguments: SourceExecConfigParams{SessionID: "cs", Config: "../server.cfg"}})
+		},
+		func() (*mcp.CallToolResultFor[any], error) {
+			return SourceKick(ctx, nil, &mcp.CallToolParamsFor[SourceKickParams]{Arguments: SourceKickParams{SessionID: "cs", SteamID: "STEAM_1:0:1", Reason: `bye"; quit; "`}})
+		},
+	} {
+		if _, err := call(); err == nil || !strings.Contains(err.Error(), "invalid") && !strings.Contains(err.Error(), "may not contain") {
+			t.Errorf("Expected the arguments to be refused, got %v", err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != count {
+		t.Errorf("Expected nothing to be sent for refused arguments, got %q", sent[count:])
+	}
+}
@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// shutdownTimeout bounds how long a network transport waits for open
+// requests when the server is stopped.
+const shutdownTimeout = 5 * time.Second
+
+// run serves MCP clients over the given transport. It returns when the stdio
+// client closes the stream, or when a network server is interrupted. With
+// announce it logs when the server is ready; nothing but the protocol is
+// ever written to stdout, which is the stdio transport's stream.
+func run(ctx context.Context, server *mcp.Server, transport config.Transport, announce bool) error {
+	if transport.Kind() == config.TransportStdio {
+		if announce {
+			logger(ctx).Info("RCON MCP server is ready", "transport", transport.Kind())
+		}
+		return server.Run(ctx, mcp.NewStdioTransport())
+	}
+
+	handler, err := httpHandler(server, transport)
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", transport.Address())
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	if announce {
+		logger(ctx).Info("RCON MCP server is ready", "transport", transport.Kind(),
+			"url", fmt.Sprintf("http://%s%s", ln.Addr(), transport.Endpoint()))
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return serveHTTP(ctx, ln, handler)
+}
+
+// httpHandler returns the HTTP handler serving MCP clients over a network
+// transport. MCP requests are routed to the transport's endpoint path,
+// health and readiness probes to config.HealthPath and config.ReadyPath,
+// and every other path is not found. Clients from addresses the transport's
+// allow and deny lists refuse are turned away, except from the probes.
+// Every client gets its own MCP session with its own state, but all of
+// them share the same server, and so the same RCON sessions.
+func httpHandler(server *mcp.Server, transport config.Transport) (http.Handler, error) {
+	getServer := func(*http.Request) *mcp.Server { return server }
+
+	var endpoint http.Handler
+	switch transport.Kind() {
+	case config.TransportHTTP:
+		endpoint = captureSessionID(mcp.NewStreamableHTTPHandler(getServer, nil))
+	case config.TransportSSE:
+		endpoint = mcp.NewSSEHandler(getServer)
+	default:
+		return nil, fmt.Errorf("unknown transport %q", transport.Kind())
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(transport.Endpoint(), endpoint)
+	filtered, err := filterIPs(transport, mux)
+	if err != nil {
+		return nil, err
+	}
+
+	probes := http.NewServeMux()
+	probes.HandleFunc("GET "+config.HealthPath, serveHealth)
+	probes.Handle("GET "+config.ReadyPath, serveReady(transport))
+	probes.Handle("/", filtered)
+	return probes, nil
+}
+
+// sessionIDHeader is the header in which the streamable HTTP transport
+// sends clients the ID of their MCP session.
+const sessionIDHeader = "Mcp-Session-Id"
+
+// sessionIDSlot holds the transport session ID of an MCP client once the
+// transport has assigned it. A nil slot holds no ID.
+type sessionIDSlot struct {
+	id atomic.Pointer[string]
+}
+
+// get returns the ID in the slot, or an empty string if it holds none yet.
+func (s *sessionIDSlot) get() string {
+	if s == nil {
+		return ""
+	}
+	if id := s.id.Load(); id != nil {
+		return *id
+	}
+	return ""
+}
+
+// sessionIDKey is the context key under which captureSessionID stores the
+// slot for a new client's session ID.
+type sessionIDKey struct{}
+
+// sessionIDFromContext returns the session ID slot of the client a request
+// context belongs to, or nil over transports without session IDs.
+func sessionIDFromContext(ctx context.Context) *sessionIDSlot {
+	slot, _ := ctx.Value(sessionIDKey{}).(*sessionIDSlot)
+	return slot
+}
+
+// captureSessionID wraps the streamable HTTP handler to make the session
+// ID it assigns a new client known to the client's handlers. The ID is
+// taken from the response header rather than from the MCP session, which
+// the SDK is still setting up while the first handlers run. The handlers
+// inherit the context of the request that created the session, so the
+// slot put there is theirs.
+func captureSessionID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get(sessionIDHeader) != "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+		slot := new(sessionIDSlot)
+		ctx := context.WithValue(req.Context(), sessionIDKey{}, slot)
+		next.ServeHTTP(&sessionIDWriter{ResponseWriter: w, slot: slot}, req.WithContext(ctx))
+	})
+}
+
+// sessionIDWriter is a ResponseWriter that stores the session ID header of
+// the response in slot once the header is sent.
+type sessionIDWriter struct {
+	http.ResponseWriter
+	slot *sessionIDSlot
+}
+
+// capture stores the session ID header, if set, in the slot.
+func (w *sessionIDWriter) capture() {
+	if id := w.Header().Get(sessionIDHeader); id != "" && w.slot.get() == "" {
+		w.slot.id.Store(&id)
+	}
+}
+
+func (w *sessionIDWriter) WriteHeader(code int) {
+	w.capture()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionIDWriter) Write(b []byte) (int, error) {
+	w.capture()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the response, which the transport does after every event
+// it streams.
+func (w *sessionIDWriter) Flush() {
+	w.capture()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController.
+func (w *sessionIDWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// serveHTTP serves handler on ln until ctx is cancelled, then shuts the HTTP
+// server down gracefully.
+func serveHTTP(ctx context.Context, ln net.Listener, handler http.Handler) error {
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger(ctx).Warn("Failed to shut down HTTP server cleanly", "error", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServeHTTP(t *testing.T) {
+	tests := []struct {
+		kind      string
+		transport func(url string) mcp.Transport
+	}{
+		{
+			kind:      config.TransportHTTP,
+			transport: func(url string) mcp.Transport { return mcp.NewStreamableClientTransport(url, nil) },
+		},
+		{
+			kind:      config.TransportSSE,
+			transport: func(url string) mcp.Transport { return mcp.NewSSEClientTransport(url, nil) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			clients = newClientRegistry()
+			server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"},
+				&mcp.ServerOptions{InitializedHandler: clients.connected})
+			registerTools(server)
+			handler, err := httpHandler(server, config.Transport{Type: tt.kind})
+			if err != nil {
+				t.Fatalf("httpHandler failed: %v", err)
+			}
+
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Failed to listen: %v", err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- serveHTTP(ctx, ln, handler) }()
+
+			base := "http://" + ln.Addr().String()
+			if resp, err := http.Get(base + "/elsewhere"); err != nil || resp.StatusCode != http.StatusNotFound {
+				t.Errorf("Expected 404 outside the MCP endpoint, got %v, %v", resp, err)
+			}
+
+			// Two clients connect at once, each with its own state.
+			var sessions []*mcp.ClientSession
+			for i := 0; i < 2; i++ {
+				client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil)
+				cs, err := client.Connect(context.Background(), tt.transport(base+config.DefaultPath))
+				if err != nil {
+					t.Fatalf("Failed to connect client: %v", err)
+				}
+				sessions = append(sessions, cs)
+			}
+			res, err := sessions[0].ListTools(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("ListTools failed: %v", err)
+			}
+			if len(res.Tools) == 0 {
+				t.Error("Expected tools to be listed over the network transport")
+			}
+			if _, err := sessions[1].CallTool(context.Background(), &mcp.CallToolParams{Name: "rcon_list_sessions"}); err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			waitFor(t, func() bool { return clients.count() == 2 })
+			calls := 0
+			ids := make(map[string]bool)
+			for ss := range server.Sessions() {
+				state := clients.get(context.Background(), ss)
+				calls += int(state.Calls())
+				ids[state.ID()] = true
+			}
+			if calls != 1 {
+				t.Errorf("Expected one recorded tool call, got %d", calls)
+			}
+			if tt.kind == config.TransportHTTP {
+				for _, cs := range sessions {
+					if !ids[cs.ID()] {
+						t.Errorf("Expected the client with session ID %q to be known by it, got %v", cs.ID(), ids)
+					}
+				}
+			}
+			for _, cs := range sessions {
+				cs.Close()
+			}
+
+			cancel()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("serveHTTP returned %v after shutdown", err)
+				}
+			case <-time.After(2 * shutdownTimeout):
+				t.Fatal("serveHTTP did not return after cancellation")
+			}
+		})
+	}
+}
+
+func TestHTTPHandler_UnknownTransport(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+	if _, err := httpHandler(server, config.Transport{Type: "carrier-pigeon"}); err == nil {
+		t.Error("Expected error for an unknown transport")
+	}
+}
+
+func TestRun_Announce(t *testing.T) {
+	for _, announce := range []bool{true, false} {
+		var log lockedBuffer
+		defer slog.SetDefault(slog.Default())
+		slog.SetDefault(slog.New(slog.NewTextHandler(&log, nil)))
+
+		server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "v0.0.1"}, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- run(ctx, server, config.Transport{Type: config.TransportHTTP, Listen: "127.0.0.1:0"}, announce)
+		}()
+
+		if announce {
+			waitFor(t, func() bool { return strings.Contains(log.String(), "ready") })
+			if !strings.Contains(log.String(), "url=http://127.0.0.1:") {
+				t.Errorf("Expected the ready message to give the URL, got %q", log.String())
+			}
+		} else {
+			time.Sleep(50 * time.Millisecond)
+		}
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+		if !announce && log.String() != "" {
+			t.Errorf("Expected nothing logged without announce, got %q", log.String())
+		}
+	}
+}
+
+// lockedBuffer is a bytes.Buffer that can be written and read concurrently.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor polls cond until it holds, failing the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Condition not met in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
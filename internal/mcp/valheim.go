@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ValheimSessionParams represents parameters for the Valheim tools that
+// only need a session
+type ValheimSessionParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Valheim server"`
+}
+
+// ValheimPlayerParams represents parameters for the valheim_kick and
+// valheim_ban tools
+type ValheimPlayerParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Valheim server"`
+	Player    string `json:"player" jsonschema:"Name or platform ID of the player, e.g. the SteamID64 76561197960290418"`
+}
+
+// registerValheimTools registers the tools of the valheim game pack, which
+// run common admin commands on Valheim servers through their RCON mod and
+// read its empty replies as success.
+func registerValheimTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "valheim_save",
+		Description: "Save the world of a Valheim server to disk",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Save Valheim world",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ValheimSave)
+
+	addTool(server, &mcp.Tool{
+		Name:        "valheim_kick",
+		Description: "Kick a player from a Valheim server by name or platform ID",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Kick Valheim player",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ValheimKick)
+
+	addTool(server, &mcp.Tool{
+		Name:        "valheim_ban",
+		Description: "Ban a player from a Valheim server by name or platform ID, kicking them if they are online",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Ban Valheim player",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ValheimBan)
+}
+
+// ValheimSave runs "save".
+func ValheimSave(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ValheimSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	return valheimChange(ctx, params.Arguments.SessionID, "save")
+}
+
+// ValheimKick runs "kick" for a player.
+func ValheimKick(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ValheimPlayerParams]) (*mcp.CallToolResultFor[any], error) {
+	return valheimPlayerChange(ctx, params.Arguments, "kick")
+}
+
+// ValheimBan runs "ban" for a player.
+func ValheimBan(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ValheimPlayerParams]) (*mcp.CallToolResultFor[any], error) {
+	return valheimPlayerChange(ctx, params.Arguments, "ban")
+}
+
+// valheimPlayerChange runs verb with the player of args as its argument.
+func valheimPlayerChange(ctx context.Context, args ValheimPlayerParams, verb string) (*mcp.CallToolResultFor[any], error) {
+	player := strings.TrimSpace(args.Player)
+	if !game.ValidValheimPlayer(player) {
+		return nil, fmt.Errorf("invalid player %q: want a name or platform ID without spaces, quotes or line breaks", args.Player)
+	}
+	return valheimChange(ctx, args.SessionID, verb+" "+player)
+}
+
+// valheimChange runs command on a Valheim session and reads the server's
+// reply as a game.Change.
+func valheimChange(ctx context.Context, sessionID, command string) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, sessionID, game.Valheim)
+	if err != nil {
+		return nil, err
+	}
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParseValheimChange(command, output)
+	})
+}
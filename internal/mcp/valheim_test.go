@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestValheimTools(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	var mu sync.Mutex
+	var sent []string
+	session := connectFakeSession(t, "valheim", func(command string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, command)
+		if command == "kick Nobody" {
+			return "Player Nobody not found"
+		}
+		return ""
+	})
+	session.SetGame(game.Valheim)
+	ctx := context.Background()
+	lastSent := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent[len(sent)-1]
+	}
+
+	res, err := ValheimSave(ctx, nil, &mcp.CallToolParamsFor[ValheimSessionParams]{Arguments: ValheimSessionParams{SessionID: "valheim"}})
+	if err != nil || res.IsError || !res.StructuredContent.(*ExecuteResult).Parsed.(*game.Change).Changed {
+		t.Errorf("Expected the empty reply to save to be a success, got %v, %+v", err, res)
+	}
+	res, err = ValheimBan(ctx, nil, &mcp.CallToolParamsFor[ValheimPlayerParams]{Arguments: ValheimPlayerParams{SessionID: "valheim", Player: " 76561197960290418 "}})
+	if err != nil || res.IsError || lastSent() != "ban 76561197960290418" {
+		t.Errorf("Expected the player to be banned, got %v, %+v after %q", err, res, lastSent())
+	}
+	res, err = ValheimKick(ctx, nil, &mcp.CallToolParamsFor[ValheimPlayerParams]{Arguments: ValheimPlayerParams{SessionID: "valheim", Player: "Nobody"}})
+	if err != nil || !res.IsError {
+		t.Errorf("Expected a failed kick to be an error, got %v, %+v", err, res)
+	}
+	if _, err := ValheimKick(ctx, nil, &mcp.CallToolParamsFor[ValheimPlayerParams]{Arguments: ValheimPlayerParams{SessionID: "valheim", Player: "Bob\nsave"}}); err == nil {
+		t.Error("Expected a player with a line break to be refused")
+	}
+
+	// rcon_execute reads the empty reply the same way
+	res, err = Execute(ctx, nil, &mcp.CallToolParamsFor[ExecuteParams]{Arguments: ExecuteParams{SessionID: "valheim", Command: "say hi"}})
+	if err != nil || res.IsError {
+		t.Fatalf("Execute failed: %v, %+v", err, res)
+	}
+	if change, ok := res.StructuredContent.(*ExecuteResult).Parsed.(*game.Change); !ok || !change.Changed {
+		t.Errorf("Expected an empty reply to be a change, got %+v", res.StructuredContent)
+	}
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Polling limits for the wait_for tool.
+const (
+	defaultWaitInterval = 2 * time.Second        // Delay between attempts when none is given
+	minWaitInterval     = 250 * time.Millisecond // Floor that keeps polling from flooding the server
+	defaultWaitTimeout  = 60 * time.Second       // Overall deadline when none is given
+	maxWaitTimeout      = 10 * time.Minute       // Longest wait a single call may block for
+)
+
+// WaitForParams represents parameters for the wait_for tool
+type WaitForParams struct {
+	SessionID       string  `json:"session_id" jsonschema:"Session ID to use"`
+	Command         string  `json:"command" jsonschema:"Command to run on each attempt"`
+	Pattern         string  `json:"pattern" jsonschema:"Regular expression the command output must match"`
+	IntervalSeconds float64 `json:"interval_seconds,omitempty" jsonschema:"Delay between attempts in seconds (default 2, minimum 0.25)"`
+	TimeoutSeconds  int     `json:"timeout_seconds,omitempty" jsonschema:"Give up after this many seconds (default 60, max 600)"`
+}
+
+// WaitForResult describes a wait. When the wait times out, Output holds the
+// last output seen and Match is empty.
+type WaitForResult struct {
+	Output        string    `json:"output"`                    // Output that matched the pattern, or the last output
+	Match         string    `json:"match"`                     // Text matched by the pattern
+	Attempts      int       `json:"attempts"`                  // Number of times the command was run
+	ElapsedMillis int64     `json:"elapsed_ms"`                // Time spent waiting
+	LastError     string    `json:"last_error,omitempty"`      // Most recent failed attempt, if any
+	LastErrorCode ErrorCode `json:"last_error_code,omitempty"` // Class of the most recent failure
+}
+
+// WaitFor runs a command repeatedly until its output matches a regular
+// expression or the timeout elapses. Failed attempts are retried until the
+// deadline, so a server that is briefly unresponsive does not end the wait.
+func WaitFor(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[WaitForParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.Command == "" {
+		return nil, errors.New("a command is required")
+	}
+	pattern, err := regexp.Compile(args.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	session, err := getSession(ctx, args.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	interval, timeout := waitSettings(args)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := &WaitForResult{}
+	var lastOutput string
+	for {
+		result.Attempts++
+		output, _, err := executeWithMetadata(ctx, session, args.Command)
+		if err != nil {
+			result.LastError, result.LastErrorCode = err.Error(), errorCode(err)
+		} else {
+			lastOutput = output
+			if loc := pattern.FindStringIndex(output); loc != nil {
+				result.Output = output
+				result.Match = output[loc[0]:loc[1]]
+				result.ElapsedMillis = time.Since(start).Milliseconds()
+				return &mcp.CallToolResultFor[any]{
+					Content:           []mcp.Content{&mcp.TextContent{Text: output}},
+					StructuredContent: result,
+				}, nil
+			}
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				result.Output = lastOutput
+				result.ElapsedMillis = time.Since(start).Milliseconds()
+				text := fmt.Sprintf("timed out after %s and %d attempts waiting for %q; last output: %q",
+					timeout, result.Attempts, args.Pattern, lastOutput)
+				return errorResult(CodeTimeout, text, result), nil
+			}
+			return nil, fmt.Errorf("wait cancelled: %w", ctx.Err())
+		}
+	}
+}
+
+// waitSettings returns the polling interval and overall timeout for a wait,
+// applying defaults and limits.
+func waitSettings(args WaitForParams) (interval, timeout time.Duration) {
+	interval = time.Duration(args.IntervalSeconds * float64(time.Second))
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+	interval = max(interval, minWaitInterval)
+
+	timeout = time.Duration(args.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultWaitTimeout
+	}
+	timeout = min(timeout, maxWaitTimeout)
+	return interval, timeout
+}
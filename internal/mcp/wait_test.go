@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestWaitFor(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         WaitForParams
+		wantMatch    string
+		wantAttempts int
+		errContains  string
+	}{
+		{
+			name:         "matches after a few attempts",
+			args:         WaitForParams{SessionID: "wait", Command: "save-all", Pattern: `Saved the \w+`, IntervalSeconds: 0.25, TimeoutSeconds: 5},
+			wantMatch:    "Saved the game",
+			wantAttempts: 3,
+		},
+		{
+			name:        "times out",
+			args:        WaitForParams{SessionID: "wait", Command: "list", Pattern: "never", IntervalSeconds: 0.25, TimeoutSeconds: 1},
+			errContains: "timed out",
+		},
+		{
+			name:        "invalid pattern",
+			args:        WaitForParams{SessionID: "wait", Command: "list", Pattern: "("},
+			errContains: "invalid pattern",
+		},
+		{
+			name:        "unknown session",
+			args:        WaitForParams{SessionID: "missing", Command: "list", Pattern: "x"},
+			errContains: "not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetSessionManager()
+			var saves atomic.Int32
+			connectFakeSession(t, "wait", func(command string) string {
+				if command == "save-all" && saves.Add(1) >= 3 {
+					return "Saved the game"
+				}
+				return "Saving..."
+			})
+
+			params := &mcp.CallToolParamsFor[WaitForParams]{Arguments: tt.args}
+			result, err := WaitFor(context.Background(), nil, params)
+			if err == nil && result.IsError {
+				err = errors.New(result.Content[0].(*mcp.TextContent).Text)
+			}
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			wait := result.StructuredContent.(*WaitForResult)
+			if wait.Match != tt.wantMatch {
+				t.Errorf("Expected match %q, got %q", tt.wantMatch, wait.Match)
+			}
+			if wait.Attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, wait.Attempts)
+			}
+		})
+	}
+}
+
+func TestWaitSettings(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         WaitForParams
+		wantInterval time.Duration
+		wantTimeout  time.Duration
+	}{
+		{name: "defaults", wantInterval: defaultWaitInterval, wantTimeout: defaultWaitTimeout},
+		{name: "explicit", args: WaitForParams{IntervalSeconds: 0.5, TimeoutSeconds: 30}, wantInterval: 500 * time.Millisecond, wantTimeout: 30 * time.Second},
+		{name: "clamped", args: WaitForParams{IntervalSeconds: 0.01, TimeoutSeconds: 3600}, wantInterval: minWaitInterval, wantTimeout: maxWaitTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interval, timeout := waitSettings(tt.args)
+			if interval != tt.wantInterval || timeout != tt.wantTimeout {
+				t.Errorf("waitSettings() = %v, %v; want %v, %v", interval, timeout, tt.wantInterval, tt.wantTimeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// zomboidRetryDelay is how long to wait before running players again when
+// Project Zomboid did not answer it properly.
+const zomboidRetryDelay = time.Second
+
+// zomboidTimeouts holds how long each command of the zomboid pack may
+// take. Project Zomboid answers slowly under load, and saving a large
+// world, which quit does first, takes far longer than the default I/O
+// timeout.
+var zomboidTimeouts = map[string]time.Duration{
+	"players":   20 * time.Second,
+	"servermsg": 15 * time.Second,
+	"save":      2 * time.Minute,
+	"quit":      3 * time.Minute,
+}
+
+// ZomboidSessionParams represents parameters for the Project Zomboid tools
+// that only need a session
+type ZomboidSessionParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Project Zomboid server"`
+}
+
+// ZomboidServerMsgParams represents parameters for the zomboid_servermsg
+// tool
+type ZomboidServerMsgParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Project Zomboid server"`
+	Message   string `json:"message" jsonschema:"Message to show every player; may not contain quotes or line breaks"`
+}
+
+// ZomboidQuitParams represents parameters for the zomboid_quit tool
+type ZomboidQuitParams struct {
+	SessionID string `json:"session_id" jsonschema:"Session ID of a Project Zomboid server"`
+	Confirm   bool   `json:"confirm" jsonschema:"Must be true: the server saves, shuts down and stays down until restarted from outside"`
+}
+
+// registerZomboidTools registers the tools of the zomboid game pack, which
+// run common admin commands on Project Zomboid servers with timeouts
+// suited to each, and accept the empty replies it sends now and then.
+func registerZomboidTools(server *mcp.Server) {
+	addTool(server, &mcp.Tool{
+		Name:        "zomboid_players",
+		Description: "Run players on a Project Zomboid server and return the players online",
+		Annotations: &mcp.ToolAnnotations{
+			Title:          "List Zomboid players",
+			ReadOnlyHint:   true,
+			IdempotentHint: true,
+			OpenWorldHint:  boolPtr(true),
+		},
+	}, ZomboidPlayers)
+
+	addTool(server, &mcp.Tool{
+		Name:        "zomboid_servermsg",
+		Description: "Show a message to every player on a Project Zomboid server",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Broadcast Zomboid message",
+			DestructiveHint: boolPtr(false),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ZomboidServerMsg)
+
+	addTool(server, &mcp.Tool{
+		Name:        "zomboid_save",
+		Description: "Save the world of a Project Zomboid server to disk, waiting up to two minutes for it to finish",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Save Zomboid world",
+			DestructiveHint: boolPtr(false),
+			IdempotentHint:  true,
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ZomboidSave)
+
+	addTool(server, &mcp.Tool{
+		Name: "zomboid_quit",
+		Description: "Save the world of a Project Zomboid server and shut it down with quit. Requires confirm: true; " +
+			"the server stays down until restarted from outside, e.g. by its process manager",
+		Annotations: &mcp.ToolAnnotations{
+			Title:           "Shut down Zomboid server",
+			DestructiveHint: boolPtr(true),
+			OpenWorldHint:   boolPtr(true),
+		},
+	}, ZomboidQuit)
+}
+
+// ZomboidPlayers runs "players" and returns the players online, running
+// it once more if the reply is empty.
+func ZomboidPlayers(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ZomboidSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Zomboid)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := zomboidTimeout(ctx, "players")
+	defer cancel()
+	return retryPackCommand(ctx, session, "players", zomboidRetryDelay, func(output string) (any, error) {
+		return game.Parse(game.Zomboid, "players", output)
+	})
+}
+
+// ZomboidServerMsg runs "servermsg" with a quoted message.
+func ZomboidServerMsg(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ZomboidServerMsgParams]) (*mcp.CallToolResultFor[any], error) {
+	message, err := game.ZomboidText(params.Arguments.Message)
+	if err != nil {
+		return nil, err
+	}
+	if message == "" {
+		return nil, errors.New("a message is required")
+	}
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Zomboid)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := zomboidTimeout(ctx, "servermsg")
+	defer cancel()
+	command := `servermsg "` + message + `"`
+	return runPackCommand(ctx, session, command, func(output string) (any, error) {
+		return game.ParseZomboidChange(command, output)
+	})
+}
+
+// ZomboidSave runs "save".
+func ZomboidSave(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ZomboidSessionParams]) (*mcp.CallToolResultFor[any], error) {
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Zomboid)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := zomboidTimeout(ctx, "save")
+	defer cancel()
+	return runPackCommand(ctx, session, "save", func(output string) (any, error) {
+		return game.ParseZomboidChange("save", output)
+	})
+}
+
+// ZomboidQuit runs "quit", which saves the world before the server exits.
+func ZomboidQuit(ctx context.Context, cc *mcp.ServerSession, params *mcp.CallToolParamsFor[ZomboidQuitParams]) (*mcp.CallToolResultFor[any], error) {
+	if !params.Arguments.Confirm {
+		return nil, errors.New("zomboid_quit shuts the server down until it is restarted from outside; call it again with confirm: true to proceed")
+	}
+	session, err := packSession(ctx, params.Arguments.SessionID, game.Zomboid)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := zomboidTimeout(ctx, "quit")
+	defer cancel()
+	return runExitCommand(ctx, session, "quit", func(output string) (any, error) {
+		return game.ParseZomboidChange("quit", output)
+	})
+}
+
+// zomboidTimeout returns ctx bounded by the timeout of command in
+// zomboidTimeouts, which may be longer than the default I/O timeout.
+func zomboidTimeout(ctx context.Context, command string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, zomboidTimeouts[command])
+}
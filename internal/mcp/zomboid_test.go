@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestZomboidTools(t *testing.T) {
+	resetSessionManager()
+	setServerConfig(t, &config.Config{})
+	var mu sync.Mutex
+	var sent []string
+	players := 0
+	connectFakeSession(t, "pz", func(command string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, command)
+		switch command {
+		case "players":
+			// The first reply is empty, as Project Zomboid sends now and then
+			if players++; players == 1 {
+				return ""
+			}
+			return "Players connected (1): \n-Bob\n"
+		case "save":
+			return ""
+		case "quit":
+			return fakeCloseReply
+		}
+		return "Message sent."
+	})
+	ctx := context.Background()
+	session := &mcp.CallToolParamsFor[ZomboidSessionParams]{Arguments: ZomboidSessionParams{SessionID: "pz"}}
+	lastSent := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return sent[len(sent)-1]
+	}
+
+	res, err := ZomboidPlayers(ctx, nil, session)
+	if err != nil || res.IsError {
+		t.Fatalf("ZomboidPlayers failed: %v, %+v", err, res)
+	}
+	if list := res.StructuredContent.(*ExecuteResult).Parsed.(*game.PlayerList); list.Online != 1 || list.Players[0] != "Bob" || players != 2 {
+		t.Errorf("Expected Bob after a retry, got %+v after %d attempts", list, players)
+	}
+
+	res, err = ZomboidServerMsg(ctx, nil, &mcp.CallToolParamsFor[ZomboidServerMsgParams]{Arguments: ZomboidServerMsgParams{SessionID: "pz", Message: " Restart soon "}})
+	if err != nil || res.IsError || lastSent() != `servermsg "Restart soon"` {
+		t.Errorf("Expected the message to be sent quoted, got %v, %+v after %q", err, res, lastSent())
+	}
+	if _, err := ZomboidServerMsg(ctx, nil, &mcp.CallToolParamsFor[ZomboidServerMsgParams]{Arguments: ZomboidServerMsgParams{SessionID: "pz", Message: `x" ; quit "`}}); err == nil {
+		t.Error("Expected a message with quotes to be refused")
+	}
+
+	// An empty reply to save counts as the world being saved
+	res, err = ZomboidSave(ctx, nil, session)
+	if err != nil || res.IsError || !res.StructuredContent.(*ExecuteResult).Parsed.(*game.Change).Changed {
+		t.Errorf("Expected an empty reply to save to be accepted, got %v, %+v", err, res)
+	}
+
+	if _, err := ZomboidQuit(ctx, nil, &mcp.CallToolParamsFor[ZomboidQuitParams]{Arguments: ZomboidQuitParams{SessionID: "pz"}}); err == nil || lastSent() != "save" {
+		t.Error("Expected zomboid_quit without confirm to be refused before sending anything")
+	}
+	res, err = ZomboidQuit(ctx, nil, &mcp.CallToolParamsFor[ZomboidQuitParams]{Arguments: ZomboidQuitParams{SessionID: "pz", Confirm: true}})
+	if err != nil || res.IsError {
+		t.Errorf("Expected the connection closing on quit to count as success, got %v, %+v", err, res)
+	}
+}
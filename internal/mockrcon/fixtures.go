@@ -0,0 +1,121 @@
+package mockrcon
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Fixture is a set of canned responses imitating one kind of game server.
+type Fixture struct {
+	Name      string            // Fixture name, used to select it
+	Responses map[string]string // Responses to exact commands
+	Commands  map[string]string // Responses to any command with this name (first word)
+	Unknown   string            // Response to other commands; %s is replaced by the command name
+}
+
+// Respond returns the fixture's response to command.
+func (f *Fixture) Respond(command string) string {
+	if response, ok := f.Responses[command]; ok {
+		return response
+	}
+	name := ""
+	if fields := strings.Fields(command); len(fields) > 0 {
+		name = fields[0]
+	}
+	if response, ok := f.Commands[name]; ok {
+		return response
+	}
+	if strings.Contains(f.Unknown, "%s") {
+		return fmt.Sprintf(f.Unknown, name)
+	}
+	return f.Unknown
+}
+
+// fixtures holds the built-in fixtures by name. Responses follow the output
+// formats that game detection and the output parsers recognize.
+var fixtures = map[string]*Fixture{
+	"minecraft": {
+		Name: "minecraft",
+		Responses: map[string]string{
+			"list":    "There are 2 of a max of 20 players online: Steve, Alex",
+			"version": "This server is running Paper version git-Paper-196 (MC: 1.20.1) (Implementing API version 1.20.1-R0.1-SNAPSHOT)",
+			"help":    "/advancement (grant|revoke)/ban <targets> [<reason>]/kick <targets> [<reason>]/list/say <message>/save-all [flush]/stop/time (add|query|set)/weather (clear|rain|thunder)/whitelist (add|list|off|on|reload|remove)",
+			"seed":    "Seed: [-1234567890]",
+		},
+		Commands: map[string]string{
+			"say":       "",
+			"save-all":  "Saving the game (this may take a moment!)Saved the game",
+			"time":      "Set the time to 1000",
+			"weather":   "Set the weather to clear",
+			"kick":      "No player was found",
+			"whitelist": "There are 2 whitelisted players: Steve, Alex",
+			"stop":      "Stopping the server",
+		},
+		Unknown: "Unknown or incomplete command, see below for error%s<--[HERE]",
+	},
+	"source": {
+		Name: "source",
+		Responses: map[string]string{
+			"status": `hostname: Mock Source Server
+version : 1.38.7.9/13879 1575 secure
+udp/ip  : 127.0.0.1:27015
+os      :  Linux
+type    :  community dedicated
+map     : de_dust2
+players : 2 humans, 0 bots (16/0 max) (not hibernating)
+
+# userid name uniqueid connected ping loss state rate adr
+#  2 1 "Alice" STEAM_1:0:12345 01:23 50 0 active 196608 127.0.0.1:27005
+#  3 2 "Bob" STEAM_1:1:67890 12:05 35 1 active 786432 127.0.0.1:27006
+#end
+`,
+			"version": "Protocol version 13879\nExe version 1.38.7.9 (csgo)\nExe build: 13:58:59 Sep 16 2024 (8012) (730)",
+			"cmdlist": "changelevel : cmd : : Change server to the specified map\nkick : cmd : : Kick a player by name\nsay : cmd : : Display player message\nstatus : cmd : : Display map and connection status.\n--------------\n  4 total convars/concommands",
+		},
+		Commands: map[string]string{
+			"say":         "",
+			"changelevel": "",
+			"kick":        "",
+			"exec":        "",
+		},
+		Unknown: `Unknown command "%s"`,
+	},
+	"factorio": {
+		Name: "factorio",
+		Responses: map[string]string{
+			"/version":        "1.1.100",
+			"/players online": "Online players (2):\n  alice (online)\n  bob (online)",
+			"/time":           "1 hour, 12 minutes and 5 seconds",
+			"/help":           "/admins - Prints a list of game admins.\n/ban <player> <reason> - Bans the specified player.\n/help [command] - Prints a list of available commands.\n/players [online/o, count/c] - Prints a list of players in the game.\n/time - Prints info about how old the map is.\n/version - Prints the current game version.",
+		},
+		Commands: map[string]string{
+			"/silent-command": "",
+			"/c":              "",
+		},
+		Unknown: "Unknown command \"%s\".",
+	},
+	"generic": {
+		Name:    "generic",
+		Unknown: "ok",
+	},
+}
+
+// Lookup returns the built-in fixture with the given name.
+func Lookup(name string) (*Fixture, error) {
+	f, ok := fixtures[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown fixture %q, want one of %s", name, strings.Join(FixtureNames(), ", "))
+	}
+	return f, nil
+}
+
+// FixtureNames returns the names of the built-in fixtures in sorted order.
+func FixtureNames() []string {
+	names := make([]string, 0, len(fixtures))
+	for name := range fixtures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,134 @@
+// Package mockrcon implements a fake RCON server that speaks the Source RCON
+// protocol and answers commands from canned fixtures, for local development
+// and demos without a real game server.
+package mockrcon
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+)
+
+// maxRequestSize bounds the size of packets the server accepts.
+const maxRequestSize = 4096
+
+// Server is a fake RCON server. It is safe for concurrent use.
+type Server struct {
+	// Logger receives the connections, logins and commands the server
+	// sees. It is the default logger when nil, and must be set before
+	// Serve is called.
+	Logger *slog.Logger
+
+	password string   // Password clients must authenticate with
+	fixture  *Fixture // Source of command responses
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]bool
+	wg       sync.WaitGroup
+}
+
+// NewServer creates a server that accepts password and answers commands
+// from fixture.
+func NewServer(password string, fixture *Fixture) *Server {
+	return &Server{password: password, fixture: fixture, conns: make(map[net.Conn]bool)}
+}
+
+// Serve accepts connections on ln until Close is called. It always returns
+// a non-nil error; after Close it returns net.ErrClosed.
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	s.listener = ln
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// Close stops accepting connections, closes open ones and waits for their
+// handlers to return.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// serveConn answers packets on conn until the client disconnects. Commands
+// are only answered once the client has authenticated.
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	log := s.Logger
+	if log == nil {
+		log = slog.Default()
+	}
+	log = log.With("remote", conn.RemoteAddr().String())
+	log.Info("Mock RCON client connected")
+	authenticated := false
+	for {
+		request, err := rcon.ReadPacket(conn, maxRequestSize)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Warn("Mock RCON connection failed", "error", err)
+			}
+			log.Info("Mock RCON client disconnected")
+			return
+		}
+
+		reply := &rcon.Packet{ID: -1, Type: rcon.PacketTypeAuthResponse}
+		switch {
+		case request.Type == rcon.PacketTypeAuth:
+			// Like Minecraft, reply with a single auth response carrying
+			// the request ID on success and -1 on failure.
+			authenticated = request.Body == s.password
+			if authenticated {
+				reply.ID = request.ID
+			}
+			log.Info("Mock RCON authentication", "ok", authenticated)
+		case request.Type == rcon.PacketTypeCommand && authenticated:
+			response := s.fixture.Respond(request.Body)
+			if len(response) > rcon.MaxResponseBodySize {
+				response = response[:rcon.MaxResponseBodySize]
+			}
+			log.Debug("Mock RCON command", "command", request.Body)
+			reply = &rcon.Packet{ID: request.ID, Type: rcon.PacketTypeResponse, Body: response}
+		}
+		if err := rcon.WritePacket(conn, reply); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package mockrcon
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+)
+
+// startServer serves fixture on a random local port and returns its address.
+func startServer(t *testing.T, fixture string) string {
+	t.Helper()
+	f, err := Lookup(fixture)
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := NewServer("test", f)
+	go server.Serve(ln)
+	t.Cleanup(func() { server.Close() })
+	return ln.Addr().String()
+}
+
+// dial connects and authenticates an RCON client to address.
+func dial(t *testing.T, address, password string) (*rcon.Client, error) {
+	t.Helper()
+	client := rcon.NewClient()
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect() })
+	return client, client.Authenticate(password)
+}
+
+func TestServer_Authentication(t *testing.T) {
+	address := startServer(t, "generic")
+
+	if _, err := dial(t, address, "wrong"); err == nil || !strings.Contains(err.Error(), "invalid password") {
+		t.Errorf("Expected invalid password error, got %v", err)
+	}
+
+	client, err := dial(t, address, "test")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if output, err := client.Execute("anything"); err != nil || output != "ok" {
+		t.Errorf("Expected ok, got %q, %v", output, err)
+	}
+}
+
+func TestServer_Fixtures(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		wantGame game.Type
+		parse    string
+		rejected string
+	}{
+		{fixture: "minecraft", wantGame: game.Minecraft, parse: "list", rejected: "bogus"},
+		{fixture: "source", wantGame: game.Source, parse: "status", rejected: "bogus"},
+		{fixture: "factorio", wantGame: game.Factorio, parse: "/players online", rejected: "/bogus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			client, err := dial(t, startServer(t, tt.fixture), "test")
+			if err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+
+			detected := game.Unknown
+			for _, probe := range game.Probes {
+				output, err := client.Execute(probe)
+				if err != nil {
+					t.Fatalf("Execute(%q) failed: %v", probe, err)
+				}
+				if detected = game.Match(probe, output); detected != game.Unknown {
+					break
+				}
+			}
+			if detected != tt.wantGame {
+				t.Errorf("Expected fixture to be detected as %s, got %s", tt.wantGame, detected)
+			}
+
+			output, err := client.Execute(tt.parse)
+			if err != nil {
+				t.Fatalf("Execute(%q) failed: %v", tt.parse, err)
+			}
+			if _, err := game.Parse(tt.wantGame, tt.parse, output); err != nil {
+				t.Errorf("Expected %q output to parse, got %v", tt.parse, err)
+			}
+
+			output, err = client.Execute(tt.rejected)
+			if err != nil {
+				t.Fatalf("Execute(%q) failed: %v", tt.rejected, err)
+			}
+			if !game.Rejected(tt.wantGame, output) {
+				t.Errorf("Expected %q to be rejected, got %q", tt.rejected, output)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if _, err := Lookup("tetris"); err == nil || !strings.Contains(err.Error(), "minecraft") {
+		t.Errorf("Expected error listing the fixtures, got %v", err)
+	}
+	f, err := Lookup("minecraft")
+	if err != nil {
+		t.Fatalf("Lookup failed: %v", err)
+	}
+	if got := f.Respond("say hello"); got != "" {
+		t.Errorf("Expected empty response to say, got %q", got)
+	}
+	if got := f.Respond("fly"); !strings.Contains(got, "fly<--[HERE]") {
+		t.Errorf("Expected unknown command response to name the command, got %q", got)
+	}
+}
@@ -0,0 +1,390 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+)
+
+// A2S message headers.
+const (
+	a2sSinglePacket    = -1   // Prefix of an unsplit response
+	a2sSplitPacket     = -2   // Prefix of one part of a split response
+	a2sInfoRequest     = 0x54 // 'T'
+	a2sInfoResponse    = 0x49 // 'I'
+	a2sPlayerRequest   = 0x55 // 'U'
+	a2sPlayerResponse  = 0x44 // 'D'
+	a2sRulesRequest    = 0x56 // 'V'
+	a2sRulesResponse   = 0x45 // 'E'
+	a2sChallenge       = 0x41 // 'A'
+	a2sMaxPacket       = 1400 // Largest UDP payload a server sends
+	a2sMaxParts        = 32   // Most parts a split response is accepted in
+	a2sCompressedSplit = 1 << 31
+)
+
+// a2sInfoPayload is the payload of an A2S_INFO request.
+const a2sInfoPayload = "Source Engine Query\x00"
+
+// SourceInfo is the server information returned by an A2S_INFO query.
+type SourceInfo struct {
+	Name        string        `json:"name"`
+	Map         string        `json:"map"`
+	Folder      string        `json:"folder"`
+	Game        string        `json:"game"`
+	AppID       uint16        `json:"app_id"`
+	Players     int           `json:"players"`
+	MaxPlayers  int           `json:"max_players"`
+	Bots        int           `json:"bots"`
+	ServerType  string        `json:"server_type"` // "dedicated", "listen" or "proxy"
+	Environment string        `json:"environment"` // "linux", "windows" or "mac"
+	Password    bool          `json:"password"`
+	VAC         bool          `json:"vac"`
+	Version     string        `json:"version"`
+	Port        int           `json:"port,omitempty"`
+	SteamID     uint64        `json:"steam_id,omitempty"`
+	Keywords    string        `json:"keywords,omitempty"`
+	GameID      uint64        `json:"game_id,omitempty"`
+	Latency     time.Duration `json:"latency"`
+}
+
+// SourcePlayer is one player returned by an A2S_PLAYER query.
+type SourcePlayer struct {
+	Name             string `json:"name"`
+	Score            int    `json:"score"`
+	ConnectedSeconds int    `json:"connected_seconds"` // How long the player has been on the server
+}
+
+// QuerySource sends an A2S_INFO query to a Source engine server. The
+// address defaults to DefaultSourcePort when it names no port. Servers
+// that answer with a challenge are queried a second time with it.
+func QuerySource(ctx context.Context, address string) (*SourceInfo, error) {
+	start := time.Now()
+	payload, err := a2sQuery(ctx, address, a2sInfoResponse, a2sInfoRequestPacket)
+	if err != nil {
+		return nil, err
+	}
+	info, err := parseSourceInfo(payload)
+	if err != nil {
+		return nil, err
+	}
+	info.Latency = time.Since(start)
+	return info, nil
+}
+
+// QuerySourcePlayers sends an A2S_PLAYER query to a Source engine server
+// and returns the players on it, in the server's order. Players still
+// connecting may have an empty name.
+func QuerySourcePlayers(ctx context.Context, address string) ([]SourcePlayer, error) {
+	payload, err := a2sQuery(ctx, address, a2sPlayerResponse, a2sChallengeRequest(a2sPlayerRequest))
+	if err != nil {
+		return nil, err
+	}
+	return parseSourcePlayers(payload)
+}
+
+// QuerySourceRules sends an A2S_RULES query to a Source engine server and
+// returns its public console variables by name. The response usually
+// spans several packets; compressed responses are not supported.
+func QuerySourceRules(ctx context.Context, address string) (map[string]string, error) {
+	payload, err := a2sQuery(ctx, address, a2sRulesResponse, a2sChallengeRequest(a2sRulesRequest))
+	if err != nil {
+		return nil, err
+	}
+	return parseSourceRules(payload)
+}
+
+// a2sQuery sends the request built by request to a Source engine server
+// and returns the body of its response of type want. A server answering
+// with a challenge is sent the request again, built with the challenge.
+func a2sQuery(ctx context.Context, address string, want byte, request func(challenge []byte) []byte) ([]byte, error) {
+	conn, err := dial(ctx, "udp", withPort(address, DefaultSourcePort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	packet := request(nil)
+	for attempt := 0; attempt < 2; attempt++ {
+		if _, err := conn.Write(packet); err != nil {
+			return nil, fmt.Errorf("failed to send query: %w", err)
+		}
+		payload, err := readA2S(conn)
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) == 0 {
+			return nil, errors.New("empty response")
+		}
+
+		switch payload[0] {
+		case a2sChallenge:
+			if len(payload) < 5 {
+				return nil, errors.New("truncated challenge")
+			}
+			packet = request(payload[1:5])
+		case want:
+			return payload[1:], nil
+		default:
+			return nil, fmt.Errorf("unexpected response type 0x%02x", payload[0])
+		}
+	}
+	return nil, errors.New("server kept answering with a challenge")
+}
+
+// a2sInfoRequestPacket builds an A2S_INFO request, with the challenge
+// appended when the server asked for one.
+func a2sInfoRequestPacket(challenge []byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, int32(a2sSinglePacket))
+	buf.WriteByte(a2sInfoRequest)
+	buf.WriteString(a2sInfoPayload)
+	buf.Write(challenge)
+	return buf.Bytes()
+}
+
+// a2sChallengeRequest returns a builder of A2S_PLAYER or A2S_RULES
+// requests, which always carry a challenge: -1 until the server sends one.
+func a2sChallengeRequest(kind byte) func(challenge []byte) []byte {
+	return func(challenge []byte) []byte {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, int32(a2sSinglePacket))
+		buf.WriteByte(kind)
+		if challenge == nil {
+			binary.Write(&buf, binary.LittleEndian, int32(-1))
+		}
+		buf.Write(challenge)
+		return buf.Bytes()
+	}
+}
+
+// readA2S reads one response and returns its payload without the packet
+// header, reassembling the parts of a split response in order.
+func readA2S(r io.Reader) ([]byte, error) {
+	buf := make([]byte, a2sMaxPacket)
+	n, err := r.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if n < 4 {
+		return nil, errors.New("truncated response")
+	}
+	switch int32(binary.LittleEndian.Uint32(buf)) {
+	case a2sSinglePacket:
+		return buf[4:n], nil
+	case a2sSplitPacket:
+		return readA2SSplit(r, buf[:n])
+	default:
+		return nil, errors.New("invalid response header")
+	}
+}
+
+// readA2SSplit reads the remaining parts of a split response whose first
+// datagram to arrive is first, in the Source engine layout: response ID,
+// total parts, part number and maximum part size after the header. The
+// first part starts with the single packet header of the whole payload.
+func readA2SSplit(r io.Reader, first []byte) ([]byte, error) {
+	var parts [][]byte
+	var id uint32
+	buf := make([]byte, a2sMaxPacket)
+	for datagram := first; ; {
+		if len(datagram) < 12 || int32(binary.LittleEndian.Uint32(datagram)) != a2sSplitPacket {
+			return nil, errors.New("malformed split response")
+		}
+		partID := binary.LittleEndian.Uint32(datagram[4:8])
+		total, number := int(datagram[8]), int(datagram[9])
+		if partID&a2sCompressedSplit != 0 {
+			return nil, errors.New("compressed split responses are not supported")
+		}
+		if parts == nil {
+			if total == 0 || total > a2sMaxParts {
+				return nil, fmt.Errorf("split response of %d parts", total)
+			}
+			parts, id = make([][]byte, total), partID
+		}
+		if partID != id || total != len(parts) || number >= total {
+			return nil, errors.New("malformed split response")
+		}
+		parts[number] = bytes.Clone(datagram[12:])
+
+		if !slices.ContainsFunc(parts, func(p []byte) bool { return p == nil }) {
+			break
+		}
+		n, err := r.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		datagram = buf[:n]
+	}
+
+	payload := bytes.Join(parts, nil)
+	if len(payload) < 4 || int32(binary.LittleEndian.Uint32(payload)) != a2sSinglePacket {
+		return nil, errors.New("invalid response header")
+	}
+	return payload[4:], nil
+}
+
+// parseSourceInfo decodes the body of an A2S_INFO response.
+func parseSourceInfo(data []byte) (*SourceInfo, error) {
+	r := &a2sReader{data: data}
+	info := &SourceInfo{}
+
+	r.byte() // Protocol version
+	info.Name = r.string()
+	info.Map = r.string()
+	info.Folder = r.string()
+	info.Game = r.string()
+	info.AppID = r.uint16()
+	info.Players = int(r.byte())
+	info.MaxPlayers = int(r.byte())
+	info.Bots = int(r.byte())
+	info.ServerType = serverTypes[r.byte()]
+	info.Environment = environments[r.byte()]
+	info.Password = r.byte() == 1
+	info.VAC = r.byte() == 1
+	info.Version = r.string()
+	if r.err != nil {
+		return nil, fmt.Errorf("malformed info response: %w", r.err)
+	}
+
+	// The extra data flag and its fields are optional.
+	if r.remaining() == 0 {
+		return info, nil
+	}
+	edf := r.byte()
+	if edf&0x80 != 0 {
+		info.Port = int(r.uint16())
+	}
+	if edf&0x10 != 0 {
+		info.SteamID = r.uint64()
+	}
+	if edf&0x40 != 0 {
+		r.uint16() // SourceTV port
+		r.string() // SourceTV name
+	}
+	if edf&0x20 != 0 {
+		info.Keywords = r.string()
+	}
+	if edf&0x01 != 0 {
+		info.GameID = r.uint64()
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("malformed info response: %w", r.err)
+	}
+	return info, nil
+}
+
+// parseSourcePlayers decodes the body of an A2S_PLAYER response.
+func parseSourcePlayers(data []byte) ([]SourcePlayer, error) {
+	r := &a2sReader{data: data}
+	players := make([]SourcePlayer, int(r.byte()))
+	for i := range players {
+		r.byte() // Index, always 0 on current servers
+		players[i].Name = r.string()
+		players[i].Score = int(int32(r.uint32()))
+		players[i].ConnectedSeconds = int(math.Float32frombits(r.uint32()))
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("malformed player response: %w", r.err)
+	}
+	return players, nil
+}
+
+// parseSourceRules decodes the body of an A2S_RULES response.
+func parseSourceRules(data []byte) (map[string]string, error) {
+	r := &a2sReader{data: data}
+	count := int(r.uint16())
+	rules := make(map[string]string, count)
+	for range count {
+		name := r.string()
+		rules[name] = r.string()
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("malformed rules response: %w", r.err)
+	}
+	return rules, nil
+}
+
+// serverTypes maps A2S server type codes to names.
+var serverTypes = map[byte]string{'d': "dedicated", 'l': "listen", 'p': "proxy"}
+
+// environments maps A2S environment codes to names.
+var environments = map[byte]string{'l': "linux", 'w': "windows", 'm': "mac", 'o': "mac"}
+
+// a2sReader decodes little-endian A2S fields. The first decoding error is
+// kept in err and later reads return zero values.
+type a2sReader struct {
+	data []byte
+	err  error
+}
+
+// remaining returns the number of undecoded bytes.
+func (r *a2sReader) remaining() int {
+	return len(r.data)
+}
+
+// take consumes n bytes, or records an error if fewer remain.
+func (r *a2sReader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+// byte reads one byte.
+func (r *a2sReader) byte() byte {
+	if b := r.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+// uint16 reads a little-endian 16-bit integer.
+func (r *a2sReader) uint16() uint16 {
+	if b := r.take(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+// uint32 reads a little-endian 32-bit integer.
+func (r *a2sReader) uint32() uint32 {
+	if b := r.take(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// uint64 reads a little-endian 64-bit integer.
+func (r *a2sReader) uint64() uint64 {
+	if b := r.take(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// string reads a null-terminated string.
+func (r *a2sReader) string() string {
+	if r.err != nil {
+		return ""
+	}
+	i := bytes.IndexByte(r.data, 0)
+	if i < 0 {
+		r.err = io.ErrUnexpectedEOF
+		return ""
+	}
+	s := string(r.data[:i])
+	r.data = r.data[i+1:]
+	return s
+}
@@ -0,0 +1,187 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// sourceInfoBody builds an A2S_INFO response body with extra data.
+func sourceInfoBody() []byte {
+	var b bytes.Buffer
+	b.WriteByte(17) // Protocol
+	for _, s := range []string{"Test Server", "de_dust2", "csgo", "Counter-Strike"} {
+		b.WriteString(s)
+		b.WriteByte(0)
+	}
+	binary.Write(&b, binary.LittleEndian, uint16(730))
+	b.Write([]byte{5, 24, 2, 'd', 'l', 0, 1})
+	b.WriteString("1.38.7.9\x00")
+	b.WriteByte(0x80 | 0x20)
+	binary.Write(&b, binary.LittleEndian, uint16(27015))
+	b.WriteString("secure,casual\x00")
+	return b.Bytes()
+}
+
+// startA2SServer serves A2S_INFO on a local UDP port, demanding a challenge
+// first when challenge is true.
+func startA2SServer(t *testing.T, challenge bool) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1400)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			request := buf[:n]
+			header := []byte{0xff, 0xff, 0xff, 0xff}
+			if challenge && !bytes.HasSuffix(request, []byte{1, 2, 3, 4}) {
+				conn.WriteTo(append(header, 'A', 1, 2, 3, 4), addr)
+				continue
+			}
+			conn.WriteTo(append(append(header, 'I'), sourceInfoBody()...), addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestQuerySource(t *testing.T) {
+	for _, challenge := range []bool{false, true} {
+		address := startA2SServer(t, challenge)
+		info, err := QuerySource(context.Background(), address)
+		if err != nil {
+			t.Fatalf("QuerySource(challenge=%v) failed: %v", challenge, err)
+		}
+
+		if info.Name != "Test Server" || info.Map != "de_dust2" || info.Game != "Counter-Strike" {
+			t.Errorf("Unexpected names: %+v", info)
+		}
+		if info.AppID != 730 || info.Players != 5 || info.MaxPlayers != 24 || info.Bots != 2 {
+			t.Errorf("Unexpected counts: %+v", info)
+		}
+		if info.ServerType != "dedicated" || info.Environment != "linux" || info.Password || !info.VAC {
+			t.Errorf("Unexpected flags: %+v", info)
+		}
+		if info.Version != "1.38.7.9" || info.Port != 27015 || info.Keywords != "secure,casual" {
+			t.Errorf("Unexpected extra data: %+v", info)
+		}
+	}
+}
+
+// startA2SListServer serves A2S_PLAYER with two players and A2S_RULES on
+// a local UDP port, both behind a challenge. The rules are sent split in
+// parts of partSize bytes, delivered last part first.
+func startA2SListServer(t *testing.T, partSize int) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	header := []byte{0xff, 0xff, 0xff, 0xff}
+	var players bytes.Buffer
+	players.Write(append(header, 'D', 2))
+	for _, p := range []struct {
+		name    string
+		score   int32
+		seconds float32
+	}{{"Alice", 12, 95.5}, {"Bob", -1, 3600}} {
+		players.WriteByte(0)
+		players.WriteString(p.name + "\x00")
+		binary.Write(&players, binary.LittleEndian, p.score)
+		binary.Write(&players, binary.LittleEndian, math.Float32bits(p.seconds))
+	}
+	var rules bytes.Buffer
+	rules.Write(append(header, 'E', 3, 0))
+	rules.WriteString("mp_timelimit\x0030\x00sv_cheats\x000\x00sv_tags\x00casual,increased_maxplayers\x00")
+
+	go func() {
+		buf := make([]byte, 1400)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			request := buf[:n]
+			if !bytes.HasSuffix(request, []byte{1, 2, 3, 4}) {
+				conn.WriteTo(append(header, 'A', 1, 2, 3, 4), addr)
+				continue
+			}
+			if request[4] == 'U' {
+				conn.WriteTo(players.Bytes(), addr)
+				continue
+			}
+			payload := rules.Bytes()
+			total := (len(payload) + partSize - 1) / partSize
+			for number := total - 1; number >= 0; number-- {
+				var part bytes.Buffer
+				binary.Write(&part, binary.LittleEndian, int32(-2))
+				binary.Write(&part, binary.LittleEndian, uint32(7))
+				part.Write([]byte{byte(total), byte(number)})
+				binary.Write(&part, binary.LittleEndian, uint16(partSize))
+				part.Write(payload[number*partSize : min((number+1)*partSize, len(payload))])
+				conn.WriteTo(part.Bytes(), addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestQuerySourcePlayers(t *testing.T) {
+	players, err := QuerySourcePlayers(context.Background(), startA2SListServer(t, 1400))
+	if err != nil {
+		t.Fatalf("QuerySourcePlayers failed: %v", err)
+	}
+	want := []SourcePlayer{{Name: "Alice", Score: 12, ConnectedSeconds: 95}, {Name: "Bob", Score: -1, ConnectedSeconds: 3600}}
+	if !reflect.DeepEqual(players, want) {
+		t.Errorf("QuerySourcePlayers() = %+v, want %+v", players, want)
+	}
+}
+
+func TestQuerySourceRules(t *testing.T) {
+	want := map[string]string{"mp_timelimit": "30", "sv_cheats": "0", "sv_tags": "casual,increased_maxplayers"}
+	for _, partSize := range []int{1400, 16} {
+		rules, err := QuerySourceRules(context.Background(), startA2SListServer(t, partSize))
+		if err != nil {
+			t.Fatalf("QuerySourceRules(parts of %d) failed: %v", partSize, err)
+		}
+		if !reflect.DeepEqual(rules, want) {
+			t.Errorf("QuerySourceRules(parts of %d) = %v, want %v", partSize, rules, want)
+		}
+	}
+}
+
+func TestParseSourceInfoTruncated(t *testing.T) {
+	body := sourceInfoBody()
+	if _, err := parseSourceInfo(body[:10]); err == nil {
+		t.Error("Expected an error for a truncated response")
+	}
+}
+
+func TestWithPort(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"example.com", "example.com:27015"},
+		{"example.com:27016", "example.com:27016"},
+		{"::1", "[::1]:27015"},
+	}
+	for _, tt := range tests {
+		if got := withPort(tt.address, DefaultSourcePort); got != tt.want {
+			t.Errorf("withPort(%q) = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Minecraft Query (GameSpy4) packet types and limits.
+const (
+	gs4Handshake  = 0x09 // Challenge request and response
+	gs4Stat       = 0x00 // Stat request and response
+	gs4MaxPacket  = 8192 // Largest response read; full stats of busy servers exceed a UDP MTU
+	gs4SessionIDs = 0x0F0F0F0F
+)
+
+// gs4Magic starts every request.
+var gs4Magic = []byte{0xFE, 0xFD}
+
+// gs4Padding precedes the key/value section of a full stat response, and
+// gs4PlayersMarker the player section.
+var (
+	gs4Padding       = []byte("splitnum\x00\x80\x00")
+	gs4PlayersMarker = []byte("\x01player_\x00\x00")
+)
+
+// MinecraftQuery is the server information returned by a Minecraft Query
+// full stat request.
+type MinecraftQuery struct {
+	MOTD     string        `json:"motd"`
+	GameType string        `json:"game_type"`
+	GameID   string        `json:"game_id"`
+	Version  string        `json:"version"`
+	Software string        `json:"software,omitempty"` // Server software reported with the plugins, e.g. "Paper on Bukkit 1.20.4"
+	Plugins  []string      `json:"plugins,omitempty"`
+	Map      string        `json:"map"`
+	Players  int           `json:"players"`
+	Max      int           `json:"max_players"`
+	HostIP   string        `json:"host_ip,omitempty"`
+	HostPort int           `json:"host_port,omitempty"`
+	Online   []string      `json:"online"` // Names of every online player
+	Latency  time.Duration `json:"latency"`
+}
+
+// QueryMinecraft sends a Minecraft Query full stat request, the UDP
+// protocol servers answer when enable-query is set in server.properties.
+// The address is the query port, which defaults to DefaultMinecraftPort
+// when the address names none. Latency is the round trip of the handshake.
+func QueryMinecraft(ctx context.Context, address string) (*MinecraftQuery, error) {
+	conn, err := dial(ctx, "udp", withPort(address, DefaultMinecraftPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	session := rand.Int32() & gs4SessionIDs
+	start := time.Now()
+	payload, err := gs4Exchange(conn, gs4Handshake, session, nil)
+	if err != nil {
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+	latency := time.Since(start)
+	token, err := strconv.ParseInt(string(bytes.TrimRight(payload, "\x00")), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid challenge token %q", payload)
+	}
+
+	// A full stat request carries the token and four bytes of padding; a
+	// basic stat request, which reports less, carries none.
+	var request bytes.Buffer
+	binary.Write(&request, binary.BigEndian, int32(token))
+	request.Write([]byte{0, 0, 0, 0})
+	payload, err = gs4Exchange(conn, gs4Stat, session, request.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("stat request failed: %w", err)
+	}
+	stats, err := parseMinecraftQuery(payload)
+	if err != nil {
+		return nil, err
+	}
+	stats.Latency = latency
+	return stats, nil
+}
+
+// gs4Exchange sends a request of type kind for session with body, and
+// returns the payload of the answer, checking its type and session.
+func gs4Exchange(conn net.Conn, kind byte, session int32, body []byte) ([]byte, error) {
+	var request bytes.Buffer
+	request.Write(gs4Magic)
+	request.WriteByte(kind)
+	binary.Write(&request, binary.BigEndian, session)
+	request.Write(body)
+	if _, err := conn.Write(request.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	buf := make([]byte, gs4MaxPacket)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if n < 5 {
+		return nil, errors.New("truncated response")
+	}
+	if buf[0] != kind || int32(binary.BigEndian.Uint32(buf[1:5])) != session {
+		return nil, errors.New("response does not match the request")
+	}
+	return buf[5:n], nil
+}
+
+// parseMinecraftQuery decodes the payload of a full stat response: padding,
+// null-terminated key/value pairs ending with an empty key, and the names
+// of the online players ending with an empty name.
+func parseMinecraftQuery(payload []byte) (*MinecraftQuery, error) {
+	rest, ok := bytes.CutPrefix(payload, gs4Padding)
+	if !ok {
+		return nil, errors.New("malformed full stat response: missing padding")
+	}
+	values, players, ok := bytes.Cut(rest, gs4PlayersMarker)
+	if !ok {
+		return nil, errors.New("malformed full stat response: missing player section")
+	}
+
+	fields := strings.Split(string(values), "\x00")
+	kv := make(map[string]string)
+	for i := 0; i+1 < len(fields) && fields[i] != ""; i += 2 {
+		kv[fields[i]] = fields[i+1]
+	}
+
+	stats := &MinecraftQuery{
+		MOTD:     stripFormatting(kv["hostname"]),
+		GameType: kv["gametype"],
+		GameID:   kv["game_id"],
+		Version:  kv["version"],
+		Map:      kv["map"],
+		HostIP:   kv["hostip"],
+		Online:   []string{},
+	}
+	stats.Players, _ = strconv.Atoi(kv["numplayers"])
+	stats.Max, _ = strconv.Atoi(kv["maxplayers"])
+	stats.HostPort, _ = strconv.Atoi(kv["hostport"])
+	stats.Software, stats.Plugins = parsePlugins(kv["plugins"])
+
+	for _, name := range strings.Split(string(players), "\x00") {
+		if name == "" {
+			break
+		}
+		stats.Online = append(stats.Online, name)
+	}
+	return stats, nil
+}
+
+// parsePlugins splits the plugins value of a full stat response, such as
+// "Paper on Bukkit 1.20.4: WorldEdit 7.2.15; Essentials 2.20", into the
+// server software and the plugins. Vanilla servers send it empty.
+func parsePlugins(value string) (software string, plugins []string) {
+	software, list, found := strings.Cut(value, ":")
+	software = strings.TrimSpace(software)
+	if !found {
+		return software, nil
+	}
+	for _, plugin := range strings.Split(list, ";") {
+		if plugin = strings.TrimSpace(plugin); plugin != "" {
+			plugins = append(plugins, plugin)
+		}
+	}
+	return software, plugins
+}
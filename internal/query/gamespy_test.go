@@ -0,0 +1,106 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// fullStatBody builds the payload of a full stat response.
+func fullStatBody(plugins string, players ...string) []byte {
+	var b bytes.Buffer
+	b.Write(gs4Padding)
+	for _, kv := range [][2]string{
+		{"hostname", "§aA Minecraft Server"}, {"gametype", "SMP"}, {"game_id", "MINECRAFT"},
+		{"version", "1.20.4"}, {"plugins", plugins}, {"map", "world"},
+		{"numplayers", "2"}, {"maxplayers", "20"}, {"hostport", "25565"}, {"hostip", "127.0.0.1"},
+	} {
+		b.WriteString(kv[0] + "\x00" + kv[1] + "\x00")
+	}
+	b.WriteByte(0)
+	b.Write(gs4PlayersMarker)
+	for _, name := range players {
+		b.WriteString(name + "\x00")
+	}
+	b.WriteByte(0)
+	return b.Bytes()
+}
+
+// startGS4Server serves Minecraft Query on a local UDP port, answering
+// full stat requests that carry its challenge token with body.
+func startGS4Server(t *testing.T, body []byte) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1400)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < 7 || !bytes.HasPrefix(buf, gs4Magic) {
+				continue
+			}
+			kind, session := buf[2], buf[3:7]
+			response := append([]byte{kind}, session...)
+			switch kind {
+			case gs4Handshake:
+				response = append(response, "9513307\x00"...)
+			case gs4Stat:
+				if n != 15 || binary.BigEndian.Uint32(buf[7:11]) != 9513307 {
+					continue
+				}
+				response = append(response, body...)
+			}
+			conn.WriteTo(response, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestQueryMinecraft(t *testing.T) {
+	body := fullStatBody("Paper on Bukkit 1.20.4-R0.1: WorldEdit 7.2.15; EssentialsX 2.20.1", "Steve", "Alex")
+	address := startGS4Server(t, body)
+	stats, err := QueryMinecraft(context.Background(), address)
+	if err != nil {
+		t.Fatalf("QueryMinecraft failed: %v", err)
+	}
+
+	if stats.MOTD != "A Minecraft Server" || stats.GameType != "SMP" || stats.GameID != "MINECRAFT" {
+		t.Errorf("Unexpected names: %+v", stats)
+	}
+	if stats.Version != "1.20.4" || stats.Map != "world" || stats.HostIP != "127.0.0.1" || stats.HostPort != 25565 {
+		t.Errorf("Unexpected server details: %+v", stats)
+	}
+	if stats.Players != 2 || stats.Max != 20 || !reflect.DeepEqual(stats.Online, []string{"Steve", "Alex"}) {
+		t.Errorf("Unexpected players: %+v", stats)
+	}
+	if stats.Software != "Paper on Bukkit 1.20.4-R0.1" || !reflect.DeepEqual(stats.Plugins, []string{"WorldEdit 7.2.15", "EssentialsX 2.20.1"}) {
+		t.Errorf("Unexpected plugins: %q %q", stats.Software, stats.Plugins)
+	}
+}
+
+func TestParseMinecraftQuery(t *testing.T) {
+	stats, err := parseMinecraftQuery(fullStatBody(""))
+	if err != nil {
+		t.Fatalf("parseMinecraftQuery failed: %v", err)
+	}
+	if stats.Software != "" || stats.Plugins != nil || len(stats.Online) != 0 {
+		t.Errorf("Expected a vanilla server with nobody online, got %+v", stats)
+	}
+
+	body := fullStatBody("", "Steve")
+	for _, malformed := range [][]byte{body[3:], body[:len(body)-20], nil} {
+		if _, err := parseMinecraftQuery(malformed); err == nil {
+			t.Errorf("Expected an error for %q", malformed)
+		}
+	}
+}
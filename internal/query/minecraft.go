@@ -0,0 +1,256 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Server List Ping packet IDs and limits.
+const (
+	slpHandshake     = 0x00    // Handshake (serverbound) and status response (clientbound)
+	slpStatusRequest = 0x00    // Status request
+	slpPing          = 0x01    // Ping request and pong response
+	slpStatusState   = 1       // Next state requested by the handshake
+	slpProtocol      = -1      // Protocol version sent when only pinging
+	slpMaxPacket     = 1 << 21 // Largest packet the protocol allows
+)
+
+// MinecraftStatus is the server information returned by a Server List Ping.
+type MinecraftStatus struct {
+	Version  string        `json:"version"`
+	Protocol int           `json:"protocol"`
+	MOTD     string        `json:"motd"`
+	Players  int           `json:"players"`
+	Max      int           `json:"max_players"`
+	Sample   []string      `json:"sample,omitempty"` // Names of some online players
+	Latency  time.Duration `json:"latency"`
+}
+
+// PingMinecraft performs a Server List Ping against a Minecraft Java
+// Edition server. The address defaults to DefaultMinecraftPort when it
+// names no port. Latency is the round trip of the ping that follows the
+// status request.
+func PingMinecraft(ctx context.Context, address string) (*MinecraftStatus, error) {
+	address = withPort(address, DefaultMinecraftPort)
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", portStr)
+	}
+
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	var handshake bytes.Buffer
+	writeVarInt(&handshake, slpHandshake)
+	writeVarInt(&handshake, slpProtocol)
+	writeString(&handshake, host)
+	binary.Write(&handshake, binary.BigEndian, uint16(port))
+	writeVarInt(&handshake, slpStatusState)
+	if err := writePacket(conn, handshake.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to send handshake: %w", err)
+	}
+	if err := writePacket(conn, []byte{slpStatusRequest}); err != nil {
+		return nil, fmt.Errorf("failed to send status request: %w", err)
+	}
+
+	id, body, err := readPacket(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status: %w", err)
+	}
+	if id != slpHandshake {
+		return nil, fmt.Errorf("unexpected packet 0x%02x", id)
+	}
+	payload, err := readString(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("malformed status: %w", err)
+	}
+	status, err := parseMinecraftStatus(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ping with a timestamp and time the pong. Some servers close the
+	// connection instead of answering; the status is still valid then.
+	var ping bytes.Buffer
+	writeVarInt(&ping, slpPing)
+	start := time.Now()
+	binary.Write(&ping, binary.BigEndian, start.UnixMilli())
+	if err := writePacket(conn, ping.Bytes()); err == nil {
+		if id, _, err := readPacket(r); err == nil && id == slpPing {
+			status.Latency = time.Since(start)
+		}
+	}
+	return status, nil
+}
+
+// parseMinecraftStatus decodes the JSON status document of a Server List
+// Ping response.
+func parseMinecraftStatus(payload string) (*MinecraftStatus, error) {
+	var doc struct {
+		Version struct {
+			Name     string `json:"name"`
+			Protocol int    `json:"protocol"`
+		} `json:"version"`
+		Players struct {
+			Max    int `json:"max"`
+			Online int `json:"online"`
+			Sample []struct {
+				Name string `json:"name"`
+			} `json:"sample"`
+		} `json:"players"`
+		Description json.RawMessage `json:"description"`
+	}
+	if err := json.Unmarshal([]byte(payload), &doc); err != nil {
+		return nil, fmt.Errorf("malformed status: %w", err)
+	}
+
+	status := &MinecraftStatus{
+		Version:  doc.Version.Name,
+		Protocol: doc.Version.Protocol,
+		MOTD:     chatText(doc.Description),
+		Players:  doc.Players.Online,
+		Max:      doc.Players.Max,
+	}
+	for _, p := range doc.Players.Sample {
+		status.Sample = append(status.Sample, p.Name)
+	}
+	return status, nil
+}
+
+// chatText flattens a chat component, which is either a plain string or an
+// object with text and extra components, into plain text without the §
+// formatting codes.
+func chatText(raw json.RawMessage) string {
+	var b strings.Builder
+	var walk func(raw json.RawMessage)
+	walk = func(raw json.RawMessage) {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			b.WriteString(s)
+			return
+		}
+		var c struct {
+			Text  string            `json:"text"`
+			Extra []json.RawMessage `json:"extra"`
+		}
+		if json.Unmarshal(raw, &c) == nil {
+			b.WriteString(c.Text)
+			for _, e := range c.Extra {
+				walk(e)
+			}
+		}
+	}
+	walk(raw)
+	return stripFormatting(b.String())
+}
+
+// stripFormatting removes § formatting codes from text.
+func stripFormatting(s string) string {
+	var b strings.Builder
+	skip := false
+	for _, r := range s {
+		switch {
+		case skip:
+			skip = false
+		case r == '§':
+			skip = true
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// writePacket writes data as a length-prefixed packet.
+func writePacket(w io.Writer, data []byte) error {
+	var buf bytes.Buffer
+	writeVarInt(&buf, int32(len(data)))
+	buf.Write(data)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// readPacket reads a length-prefixed packet and returns its ID and body.
+func readPacket(r *bufio.Reader) (int32, []byte, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if length <= 0 || length > slpMaxPacket {
+		return 0, nil, fmt.Errorf("invalid packet length %d", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, err
+	}
+	br := bytes.NewReader(data)
+	id, err := readVarInt(br)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, data[len(data)-br.Len():], nil
+}
+
+// writeVarInt writes v in the protocol's variable-length encoding.
+func writeVarInt(buf *bytes.Buffer, v int32) {
+	u := uint32(v)
+	for u >= 0x80 {
+		buf.WriteByte(byte(u) | 0x80)
+		u >>= 7
+	}
+	buf.WriteByte(byte(u))
+}
+
+// readVarInt reads a value in the protocol's variable-length encoding.
+func readVarInt(r io.ByteReader) (int32, error) {
+	var u uint32
+	for shift := 0; shift < 35; shift += 7 {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		u |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return int32(u), nil
+		}
+	}
+	return 0, errors.New("varint too long")
+}
+
+// writeString writes a varint length-prefixed UTF-8 string.
+func writeString(buf *bytes.Buffer, s string) {
+	writeVarInt(buf, int32(len(s)))
+	buf.WriteString(s)
+}
+
+// readString reads a varint length-prefixed UTF-8 string.
+func readString(r *bytes.Reader) (string, error) {
+	n, err := readVarInt(r)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || int(n) > r.Len() {
+		return "", io.ErrUnexpectedEOF
+	}
+	b := make([]byte, n)
+	r.Read(b)
+	return string(b), nil
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"testing"
+)
+
+// startSLPServer answers one Server List Ping with status on a local port.
+func startSLPServer(t *testing.T, status string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		if id, _, err := readPacket(r); err != nil || id != slpHandshake {
+			return
+		}
+		if id, _, err := readPacket(r); err != nil || id != slpStatusRequest {
+			return
+		}
+		var response bytes.Buffer
+		writeVarInt(&response, slpHandshake)
+		writeString(&response, status)
+		writePacket(conn, response.Bytes())
+
+		id, body, err := readPacket(r)
+		if err != nil || id != slpPing {
+			return
+		}
+		writePacket(conn, append([]byte{slpPing}, body...))
+	}()
+	return ln.Addr().String()
+}
+
+func TestPingMinecraft(t *testing.T) {
+	address := startSLPServer(t, `{
+		"version": {"name": "1.21.1", "protocol": 767},
+		"players": {"max": 20, "online": 2, "sample": [{"name": "Steve", "id": "1"}, {"name": "Alex", "id": "2"}]},
+		"description": {"text": "§aA ", "extra": [{"text": "Minecraft"}, " Server"]}
+	}`)
+
+	status, err := PingMinecraft(context.Background(), address)
+	if err != nil {
+		t.Fatalf("PingMinecraft failed: %v", err)
+	}
+	if status.Version != "1.21.1" || status.Protocol != 767 {
+		t.Errorf("Unexpected version: %+v", status)
+	}
+	if status.Players != 2 || status.Max != 20 || len(status.Sample) != 2 || status.Sample[0] != "Steve" {
+		t.Errorf("Unexpected players: %+v", status)
+	}
+	if status.MOTD != "A Minecraft Server" {
+		t.Errorf("MOTD = %q, want %q", status.MOTD, "A Minecraft Server")
+	}
+	if status.Latency <= 0 {
+		t.Errorf("Expected a latency from the ping, got %v", status.Latency)
+	}
+}
+
+func TestPingMinecraftUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := ln.Addr().String()
+	ln.Close()
+
+	if _, err := PingMinecraft(context.Background(), address); err == nil {
+		t.Error("Expected an error for a closed port")
+	}
+}
+
+func TestVarInt(t *testing.T) {
+	for _, v := range []int32{0, 1, 127, 128, 25565, 2097151, -1} {
+		var buf bytes.Buffer
+		writeVarInt(&buf, v)
+		got, err := readVarInt(&buf)
+		if err != nil || got != v {
+			t.Errorf("varint round trip of %d = %d, %v", v, got, err)
+		}
+	}
+}
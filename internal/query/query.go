@@ -0,0 +1,48 @@
+// Package query implements the unauthenticated status protocols that game
+// servers expose next to RCON, such as Source's A2S queries and Minecraft's
+// Server List Ping and Query protocols. They need no password and are useful to check a server
+// before opening an RCON session.
+package query
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Default ports of the query protocols, used when an address has none.
+const (
+	DefaultSourcePort    = 27015 // Source A2S queries (the game port)
+	DefaultMinecraftPort = 25565 // Minecraft Server List Ping and Query (the game port, unless query.port moves the latter)
+)
+
+// timeout bounds a query when the context has no earlier deadline.
+const timeout = 5 * time.Second
+
+// withPort appends port to address when it does not name one.
+func withPort(address string, port int) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(address, strconv.Itoa(port))
+}
+
+// dial opens a connection bounded by ctx and the default timeout. The
+// connection's deadline is set so that reads and writes give up in time.
+func dial(ctx context.Context, network, address string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,231 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+// Protocol is the wire protocol a Client speaks.
+type Protocol string
+
+// Supported protocols.
+const (
+	ProtocolRCON             Protocol = "rcon"              // Source RCON packets over TCP, the default
+	ProtocolWebRCON          Protocol = "webrcon"           // Rust's WebRCON: JSON messages over a WebSocket whose path is the password
+	ProtocolTelnet           Protocol = "telnet"            // The 7 Days to Die telnet console: lines of text after a password prompt
+	ProtocolTShock           Protocol = "tshock"            // The REST API of Terraria's TShock: HTTP requests carrying a token as the password
+	ProtocolBattlEye         Protocol = "battleye"          // BattlEye RCon, used by DayZ and Arma: checksummed datagrams over UDP
+	ProtocolSatisfactory     Protocol = "satisfactory"      // The HTTPS API of Satisfactory servers: JSON functions carrying a token
+	ProtocolGenericTelnet    Protocol = "generic-telnet"    // Other telnet consoles: lines of text after a login and prompt described by a TelnetDialect
+	ProtocolGenericWebSocket Protocol = "generic-websocket" // Other WebSocket consoles: messages in templates, replies read at JSON paths, described by a WebSocketDialect
+	ProtocolGenericHTTP      Protocol = "generic-http"      // Other HTTP APIs: one request per command made from templates, described by an HTTPDialect
+)
+
+// ConsoleMessage is a message a WebRCON, telnet or BattlEye server sent
+// without being asked: the lines its console logs and the chat of its players.
+type ConsoleMessage struct {
+	Type    string // "Generic", "Log", "Warning", "Error" or "Chat"
+	Message string // The line, or for WebRCON Chat a JSON object with the sender, channel and text
+}
+
+// Capabilities describes what a wire protocol offers beyond running
+// commands, which the layers above adapt to.
+type Capabilities struct {
+	Network   string        // Network the protocol is spoken over, "tcp" or "udp"
+	Console   bool          // Servers send console output and chat unprompted, to the console handler
+	Game      game.Type     // The one game the protocol serves, Unknown if several
+	KeepAlive time.Duration // How often a connection sends an empty command so servers keep it, 0 for never
+}
+
+// Backend speaks a wire protocol, one of the built-in ones or one added
+// with RegisterProtocol. A Client makes a new Backend for every connection
+// it opens and calls it for one exchange at a time. The Client owns the
+// connection Dial returns: it closes it on Disconnect, and marks itself
+// disconnected when Auth or Execute fail with an error showing the
+// connection is dead, such as io.EOF or net.ErrClosed.
+type Backend interface {
+	// Dial opens a connection to the server at address.
+	Dial(ctx context.Context, address string) (net.Conn, error)
+	// Auth logs in over conn with password. A refused password is
+	// reported as ErrAuthFailed.
+	Auth(ctx context.Context, conn net.Conn, password string) error
+	// Execute runs command over conn and returns its output, giving up
+	// when ctx, which carries the I/O timeout, is done. The empty
+	// command, which health checks send, should be answered cheaply.
+	Execute(ctx context.Context, conn net.Conn, command string) (string, error)
+	// Capabilities describes the protocol.
+	Capabilities() Capabilities
+}
+
+// ConsoleBackend is a Backend whose servers send console messages
+// unprompted. Before Auth, the Client gives it the function to hand them
+// to, which is safe to call from any goroutine.
+type ConsoleBackend interface {
+	Backend
+	SetConsoleHandler(handler func(ConsoleMessage))
+}
+
+// watchedBackend is a Backend that reads its connection on its own, and
+// so notices it dying between exchanges. Before Auth, the Client gives it
+// the function to report that with, which marks the client disconnected.
+type watchedBackend interface {
+	Backend
+	setDropHandler(handler func(error))
+}
+
+// tunedBackend is a Backend taking the read settings of the client it is
+// made for, set with SetReadOptions and SetListening, before Dial.
+type tunedBackend interface {
+	Backend
+	tune(read ReadOptions, listening bool)
+}
+
+// protocolEntry is a protocol clients can speak and the function making
+// its Backends.
+type protocolEntry struct {
+	caps       Capabilities
+	newBackend func() Backend
+}
+
+var (
+	protocolsMu sync.RWMutex
+	// protocols holds every protocol clients can speak, by name
+	protocols = map[Protocol]protocolEntry{}
+	// protocolOrder lists the names of protocols in the order they were added
+	protocolOrder []Protocol
+)
+
+// The built-in protocols are registered like the others, the generic ones
+// speaking their default dialect unless a client is given one configured
+// with SetBackend.
+func init() {
+	builtin := []struct {
+		p          Protocol
+		newBackend func() Backend
+	}{
+		{ProtocolRCON, func() Backend { return &sourceRCON{} }},
+		{ProtocolWebRCON, func() Backend { return &webrcon{} }},
+		{ProtocolTelnet, func() Backend { return &telnet{} }},
+		{ProtocolTShock, func() Backend { return &tshock{} }},
+		{ProtocolBattlEye, func() Backend { return &battleye{} }},
+		{ProtocolSatisfactory, func() Backend { return &satisfactory{} }},
+		{ProtocolGenericTelnet, GenericTelnet(TelnetDialect{})},
+		{ProtocolGenericWebSocket, GenericWebSocket(WebSocketDialect{})},
+		{ProtocolGenericHTTP, GenericHTTP(HTTPDialect{})},
+	}
+	for _, b := range builtin {
+		if err := RegisterProtocol(b.p, b.newBackend); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterProtocol adds the protocol p, spoken by the Backends newBackend
+// makes, for clients to speak once SetProtocol selects it. It is meant to
+// be called from an init function, before any client connects; the
+// server package's RegisterProtocol also makes the protocol known to the
+// configuration. Returns an error if p is empty or already taken.
+func RegisterProtocol(p Protocol, newBackend func() Backend) error {
+	if p == "" || newBackend == nil {
+		return errors.New("a protocol needs a name and a backend")
+	}
+	protocolsMu.Lock()
+	defer protocolsMu.Unlock()
+	if _, ok := protocols[p]; ok {
+		return fmt.Errorf("protocol %q is already registered", p)
+	}
+	protocols[p] = protocolEntry{caps: newBackend().Capabilities(), newBackend: newBackend}
+	protocolOrder = append(protocolOrder, p)
+	return nil
+}
+
+// Protocols returns the names of the protocols clients can speak, the
+// built-in ones first.
+func Protocols() []Protocol {
+	protocolsMu.RLock()
+	defer protocolsMu.RUnlock()
+	return slices.Clone(protocolOrder)
+}
+
+// ProtocolCapabilities returns the capabilities of protocol p, and false
+// if no such protocol exists.
+func ProtocolCapabilities(p Protocol) (Capabilities, bool) {
+	protocolsMu.RLock()
+	defer protocolsMu.RUnlock()
+	entry, ok := protocols[p]
+	return entry.caps, ok
+}
+
+// newBackend returns a new Backend for protocol p, or nil if p is unknown.
+func newBackend(p Protocol) Backend {
+	protocolsMu.RLock()
+	defer protocolsMu.RUnlock()
+	if entry := protocols[p]; entry.newBackend != nil {
+		return entry.newBackend()
+	}
+	return nil
+}
+
+// SetProtocol sets the protocol of the connections the client opens from
+// now on; the current connection keeps its protocol.
+func (c *Client) SetProtocol(p Protocol) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.protocol = p
+}
+
+// Protocol returns the protocol set with SetProtocol, ProtocolRCON by
+// default.
+func (c *Client) Protocol() Protocol {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.protocol == "" {
+		return ProtocolRCON
+	}
+	return c.protocol
+}
+
+// SetConsoleHandler registers a function to be called with every message
+// the server of a ConsoleBackend sends unprompted, and with the chat an
+// RCON server pushes to a listening client. It runs on the connection's
+// reader, so it must not block; replies to commands wait while it runs.
+func (c *Client) SetConsoleHandler(handler func(ConsoleMessage)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onConsole = handler
+}
+
+// SetBackend makes the connections the client opens from now on speak
+// through the Backends newBackend makes rather than those of the protocol
+// set with SetProtocol, as for a protocol whose Backend needs configuring,
+// such as ProtocolGenericTelnet with a TelnetDialect. A nil newBackend
+// restores the protocol's own.
+func (c *Client) SetBackend(newBackend func() Backend) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.backend = newBackend
+}
+
+// backendFactory returns the function set with SetBackend.
+func (c *Client) backendFactory() func() Backend {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.backend
+}
+
+// newBackend returns a new Backend for the client's next connection
+// speaking p: one made by the function set with SetBackend, or else p's
+// own, nil if p is unknown.
+func (c *Client) newBackend(p Protocol) Backend {
+	if newBackend := c.backendFactory(); newBackend != nil {
+		return newBackend()
+	}
+	return newBackend(p)
+}
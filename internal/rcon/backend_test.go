@@ -0,0 +1,188 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+// protocolPipe is a protocol registered by the tests, spoken by
+// pipeBackend.
+const protocolPipe Protocol = "pipe-test"
+
+func init() {
+	if err := RegisterProtocol(protocolPipe, func() Backend { return &pipeBackend{} }); err != nil {
+		panic(err)
+	}
+}
+
+// pipeBackend speaks a line protocol with a server served over net.Pipe:
+// "login <password>" is answered with "ok" or "denied", and a command
+// with its reply, after lines starting with "console " that are console
+// messages.
+type pipeBackend struct {
+	console func(ConsoleMessage)
+	rd      *bufio.Reader
+}
+
+func (b *pipeBackend) Dial(ctx context.Context, address string) (net.Conn, error) {
+	if address == "unreachable:1" {
+		return nil, errors.New("connection refused")
+	}
+	client, server := net.Pipe()
+	go servePipe(server)
+	b.rd = bufio.NewReader(client)
+	return client, nil
+}
+
+func (b *pipeBackend) Auth(ctx context.Context, conn net.Conn, password string) error {
+	reply, err := b.exchange(conn, "login "+password)
+	if err != nil {
+		return err
+	}
+	if reply != "ok" {
+		return fmt.Errorf("%w: %s", ErrAuthFailed, reply)
+	}
+	return nil
+}
+
+func (b *pipeBackend) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	return b.exchange(conn, command)
+}
+
+func (b *pipeBackend) Capabilities() Capabilities {
+	return Capabilities{Network: "tcp", Console: true, Game: game.Minecraft}
+}
+
+func (b *pipeBackend) SetConsoleHandler(handler func(ConsoleMessage)) {
+	b.console = handler
+}
+
+// exchange sends line and returns the reply, passing on console messages.
+func (b *pipeBackend) exchange(conn net.Conn, line string) (string, error) {
+	if _, err := fmt.Fprintln(conn, line); err != nil {
+		return "", err
+	}
+	for {
+		reply, err := b.rd.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		reply = strings.TrimSuffix(reply, "\n")
+		if msg, ok := strings.CutPrefix(reply, "console "); ok {
+			b.console(ConsoleMessage{Type: "Log", Message: msg})
+			continue
+		}
+		return reply, nil
+	}
+}
+
+// servePipe answers the pipe protocol on conn for the password "secret".
+// The command "bye" closes the connection without a reply.
+func servePipe(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "login secret":
+			fmt.Fprintln(conn, "ok")
+		case strings.HasPrefix(line, "login "):
+			fmt.Fprintln(conn, "denied")
+		case line == "bye":
+			return
+		default:
+			fmt.Fprintf(conn, "console ran %s\necho %s\n", line, line)
+		}
+	}
+}
+
+func TestRegisterProtocol(t *testing.T) {
+	if !slices.Contains(Protocols(), protocolPipe) {
+		t.Errorf("Expected %s among %v", protocolPipe, Protocols())
+	}
+	if caps, ok := ProtocolCapabilities(protocolPipe); !ok || caps.Game != game.Minecraft || !caps.Console {
+		t.Errorf("Unexpected capabilities: %+v, %v", caps, ok)
+	}
+	for p, console := range map[Protocol]bool{ProtocolGenericTelnet: true, ProtocolGenericWebSocket: true, ProtocolGenericHTTP: false} {
+		if caps, ok := ProtocolCapabilities(p); !ok || caps.Console != console {
+			t.Errorf("Expected the %s backend to be registered with console %v, got %+v, %v", p, console, caps, ok)
+		}
+	}
+	if caps, _ := ProtocolCapabilities(ProtocolBattlEye); caps.Network != "udp" {
+		t.Errorf("Expected BattlEye over UDP, got %+v", caps)
+	}
+	for _, p := range Protocols() {
+		if _, ok := ProtocolCapabilities(p); !ok {
+			t.Errorf("Expected capabilities for %s", p)
+		}
+	}
+	for _, p := range []Protocol{protocolPipe, ProtocolRCON, ""} {
+		if err := RegisterProtocol(p, func() Backend { return &pipeBackend{} }); err == nil {
+			t.Errorf("Expected registering %q to fail", p)
+		}
+	}
+}
+
+func TestBackend(t *testing.T) {
+	client := NewClient()
+	client.SetProtocol(protocolPipe)
+	var mu sync.Mutex
+	var console []string
+	client.SetConsoleHandler(func(m ConsoleMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		console = append(console, m.Message)
+	})
+	if err := client.Connect("pipe:1"); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Disconnect()
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	out, err := client.Execute("list")
+	if err != nil || out != "echo list" {
+		t.Fatalf("Execute = %q, %v, want %q", out, err, "echo list")
+	}
+	mu.Lock()
+	if len(console) != 1 || console[0] != "ran list" {
+		t.Errorf("Expected the console message to reach the handler, got %q", console)
+	}
+	mu.Unlock()
+
+	if _, err := client.Execute("bye"); err == nil {
+		t.Fatal("Expected a closed connection to fail the command")
+	}
+	if client.IsConnected() {
+		t.Error("Expected the client to be disconnected after the server closed the connection")
+	}
+}
+
+func TestBackend_Errors(t *testing.T) {
+	client := NewClient()
+	client.SetProtocol(protocolPipe)
+	if err := client.Connect("unreachable:1"); err == nil {
+		t.Error("Expected a failed Dial to fail Connect")
+	}
+	if err := client.Connect("pipe:1"); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Disconnect()
+	if err := client.Authenticate("wrong"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package rcon
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+// Packet types of the BattlEye RCon protocol, which follow the 0xFF that
+// starts the payload of every datagram.
+const (
+	battleyeLogin   byte = 0x00 // Password, answered with 1 for success or 0
+	battleyeCommand byte = 0x01 // Sequence number and command, answered with the same number
+	battleyeMessage byte = 0x02 // Sequence number and a message the server sends unprompted, to be acknowledged
+)
+
+// battleyeKeepAlive is how often an idle BattlEye connection sends an
+// empty command: servers drop clients they have not heard from for 45s.
+const battleyeKeepAlive = 30 * time.Second
+
+// battleyeChatPattern matches the server messages that are chat, which
+// start with their channel, "(Global) Bob: hello".
+var battleyeChatPattern = regexp.MustCompile(`^\((Global|Side|Command|Group|Vehicle|Direct|Unknown)\) `)
+
+// battleye is the Backend of a BattlEye connection. Once authenticated,
+// its reader goroutine acknowledges the messages the server sends, hands
+// them to the console handler and the reply to the command in flight to
+// the exchange waiting for it.
+type battleye struct {
+	console func(ConsoleMessage)
+	drop    func(error)
+	mu      sync.Mutex
+	seq     byte              // Sequence number of the next command
+	cmd     *battleyeExchange // The command in flight, nil between commands
+	lastMsg int               // Sequence number of the last server message, -1 before the first
+	done    chan struct{}     // Closed when the reader stops
+	err     error             // Why the reader stopped, set before done is closed
+}
+
+// battleyeExchange is a command waiting for its reply, which servers split
+// into numbered parts when it does not fit in one datagram.
+type battleyeExchange struct {
+	seq   byte
+	parts []string // Indexed by part number, nil until the first part arrives
+	got   []bool   // Which parts arrived
+	left  int      // Parts still missing
+	reply chan string
+}
+
+// battleyePacket returns the datagram of a BattlEye packet of type t with
+// payload: "BE", the CRC32 of the rest, 0xFF, the type and the payload.
+func battleyePacket(t byte, payload []byte) []byte {
+	body := append([]byte{0xFF, t}, payload...)
+	packet := append([]byte("BE"), binary.LittleEndian.AppendUint32(nil, crc32.ChecksumIEEE(body))...)
+	return append(packet, body...)
+}
+
+// parseBattlEyePacket checks the header and checksum of a datagram and
+// returns its type and payload.
+func parseBattlEyePacket(data []byte) (byte, []byte, error) {
+	if len(data) < 8 || data[0] != 'B' || data[1] != 'E' || data[6] != 0xFF {
+		return 0, nil, errors.New("not a BattlEye packet")
+	}
+	if binary.LittleEndian.Uint32(data[2:6]) != crc32.ChecksumIEEE(data[6:]) {
+		return 0, nil, errors.New("invalid BattlEye checksum")
+	}
+	return data[7], data[8:], nil
+}
+
+// Dial opens a UDP socket to the server. Nothing is sent until Auth, so
+// it succeeds whether or not a server listens.
+func (b *battleye) Dial(ctx context.Context, address string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "udp", address)
+}
+
+// Capabilities describes BattlEye RCon, which pushes server messages and
+// needs a keepalive to keep the server from dropping an idle client.
+func (b *battleye) Capabilities() Capabilities {
+	return Capabilities{Network: "udp", Console: true, Game: game.Unknown, KeepAlive: battleyeKeepAlive}
+}
+
+// SetConsoleHandler sets the function the server messages go to.
+func (b *battleye) SetConsoleHandler(handler func(ConsoleMessage)) {
+	b.console = handler
+}
+
+// setDropHandler sets the function the reader reports the socket failing
+// with.
+func (b *battleye) setDropHandler(handler func(error)) {
+	b.drop = handler
+}
+
+// Auth logs in with password over conn, a UDP socket, and starts reading
+// from it. Servers ignore datagrams that are not BattlEye's, so a wrong
+// port shows as a timeout.
+func (b *battleye) Auth(ctx context.Context, conn net.Conn, password string) error {
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	if _, err := conn.Write(battleyePacket(battleyeLogin, []byte(password))); err != nil {
+		return fmt.Errorf("failed to send login: %w", err)
+	}
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return fmt.Errorf("failed to read login reply: %w", err)
+		}
+		t, payload, err := parseBattlEyePacket(buf[:n])
+		if err != nil || t != battleyeLogin || len(payload) != 1 {
+			continue
+		}
+		if payload[0] != 1 {
+			return fmt.Errorf("%w: invalid password", ErrAuthFailed)
+		}
+		break
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("failed to clear deadline: %w", err)
+	}
+
+	b.lastMsg, b.done = -1, make(chan struct{})
+	go b.read(conn)
+	return nil
+}
+
+// read reads the datagrams of conn until it fails, then reports the
+// socket dead.
+func (b *battleye) read(conn net.Conn) {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			b.err = err
+			close(b.done)
+			if b.drop != nil {
+				b.drop(err)
+			}
+			return
+		}
+		t, payload, err := parseBattlEyePacket(buf[:n])
+		if err != nil || len(payload) == 0 {
+			continue
+		}
+
+		switch t {
+		case battleyeCommand:
+			b.deliver(payload[0], payload[1:])
+		case battleyeMessage:
+			// Unacknowledged messages are sent again, so acknowledge each
+			// and pass on only the first copy
+			_, _ = conn.Write(battleyePacket(battleyeMessage, payload[:1]))
+			if !b.isNew(payload[0]) {
+				continue
+			}
+			msg := ConsoleMessage{Type: "Log", Message: string(payload[1:])}
+			if battleyeChatPattern.MatchString(msg.Message) {
+				msg.Type = "Chat"
+			}
+			if b.console != nil {
+				b.console(msg)
+			}
+		}
+	}
+}
+
+// Execute sends command and waits for its reply or for ctx to be done. An
+// empty command, as health checks and keepalives send, is answered with an
+// empty reply.
+func (b *battleye) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	ex := b.start()
+	defer b.finish()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetWriteDeadline(deadline); err != nil {
+		return "", fmt.Errorf("failed to set write deadline: %w", err)
+	}
+	_, err := conn.Write(battleyePacket(battleyeCommand, append([]byte{ex.seq}, command...)))
+	_ = conn.SetWriteDeadline(time.Time{})
+	if err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+	tracePacket(ctx, "Sent BattlEye command", &Packet{ID: int32(ex.seq), Type: PacketTypeCommand, Body: command})
+
+	select {
+	case reply := <-ex.reply:
+		tracePacket(ctx, "Received BattlEye reply", &Packet{ID: int32(ex.seq), Type: PacketTypeResponse, Body: reply})
+		return reply, nil
+	case <-b.done:
+		return "", fmt.Errorf("failed to read response: %w", b.err)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// next returns the sequence number of the next command, which wraps
+// around after 255.
+func (b *battleye) next() byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	seq := b.seq
+	b.seq++
+	return seq
+}
+
+// start makes a new command the command in flight.
+func (b *battleye) start() *battleyeExchange {
+	seq := b.next()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cmd = &battleyeExchange{seq: seq, reply: make(chan string, 1)}
+	return b.cmd
+}
+
+// finish ends the command in flight; later replies to it are dropped.
+func (b *battleye) finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cmd = nil
+}
+
+// deliver adds data, a reply or a part of one with sequence number seq,
+// to the command in flight if it is the reply to it, and hands the reply
+// over once it is complete. A part starts with 0, the number of parts
+// and its own number.
+func (b *battleye) deliver(seq byte, data []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ex := b.cmd
+	if ex == nil || ex.seq != seq {
+		return
+	}
+	if len(data) < 3 || data[0] != 0 {
+		ex.reply <- string(data)
+		b.cmd = nil
+		return
+	}
+	count, index := int(data[1]), int(data[2])
+	if ex.parts == nil {
+		ex.parts, ex.got, ex.left = make([]string, count), make([]bool, count), count
+	}
+	if index >= len(ex.parts) || ex.got[index] {
+		return
+	}
+	ex.parts[index], ex.got[index] = string(data[3:]), true
+	if ex.left--; ex.left == 0 {
+		ex.reply <- strings.Join(ex.parts, "")
+		b.cmd = nil
+	}
+}
+
+// isNew records seq as the sequence number of the latest server message
+// and reports whether it differs from the one before, which a message
+// sent again repeats.
+func (b *battleye) isNew(seq byte) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lastMsg == int(seq) {
+		return false
+	}
+	b.lastMsg = int(seq)
+	return true
+}
@@ -0,0 +1,132 @@
+package rcon
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startBattlEyeServer serves BattlEye RCon on a local UDP port for the
+// password "secret". It pushes a chat message, twice as if it had not been
+// acknowledged, before each reply; "players" is answered in two parts and
+// other commands are echoed.
+func startBattlEyeServer(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 64*1024)
+		var msgSeq byte
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			typ, payload, err := parseBattlEyePacket(buf[:n])
+			if err != nil {
+				continue
+			}
+			send := func(t byte, p ...byte) { _, _ = conn.WriteTo(battleyePacket(t, p), addr) }
+			switch {
+			case typ == battleyeLogin && string(payload) == "secret":
+				send(battleyeLogin, 1)
+			case typ == battleyeLogin:
+				send(battleyeLogin, 0)
+			case typ == battleyeCommand && len(payload) == 1:
+				send(battleyeCommand, payload[0])
+			case typ == battleyeCommand:
+				chat := append([]byte{msgSeq}, "(Global) Bob: hello"...)
+				send(battleyeMessage, chat...)
+				send(battleyeMessage, chat...)
+				msgSeq++
+				seq, command := payload[0], string(payload[1:])
+				if command == "players" {
+					send(battleyeCommand, append([]byte{seq, 0, 2, 1}, " world"...)...)
+					send(battleyeCommand, append([]byte{seq, 0, 2, 0}, "hello"...)...)
+					continue
+				}
+				send(battleyeCommand, append([]byte{seq}, "echo "+command...)...)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestBattlEye(t *testing.T) {
+	address := startBattlEyeServer(t)
+	client := NewClient()
+	client.SetProtocol(ProtocolBattlEye)
+	var mu sync.Mutex
+	var console []ConsoleMessage
+	client.SetConsoleHandler(func(m ConsoleMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		console = append(console, m)
+	})
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Disconnect()
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	for _, tt := range []struct{ command, want string }{
+		{"loadBans", "echo loadBans"},
+		{"players", "hello world"},
+		{healthCheckCommand, ""},
+	} {
+		out, err := client.Execute(tt.command)
+		if err != nil {
+			t.Fatalf("Execute(%q) failed: %v", tt.command, err)
+		}
+		if out != tt.want {
+			t.Errorf("Execute(%q) = %q, want %q", tt.command, out, tt.want)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(console) != 2 {
+		t.Fatalf("Expected each chat message once, got %v", console)
+	}
+	if console[0].Type != "Chat" || !strings.HasPrefix(console[0].Message, "(Global) Bob") {
+		t.Errorf("Unexpected console message %+v", console[0])
+	}
+}
+
+func TestBattlEye_InvalidPassword(t *testing.T) {
+	address := startBattlEyeServer(t)
+	client := NewClient()
+	client.SetProtocol(ProtocolBattlEye)
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Disconnect()
+
+	if err := client.Authenticate("wrong"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+	if client.IsAuthenticated() {
+		t.Error("Expected the client not to be authenticated")
+	}
+}
+
+func TestParseBattlEyePacket(t *testing.T) {
+	packet := battleyePacket(battleyeCommand, []byte{7, 'x'})
+	typ, payload, err := parseBattlEyePacket(packet)
+	if err != nil || typ != battleyeCommand || string(payload) != "\x07x" {
+		t.Errorf("parseBattlEyePacket = %d, %q, %v", typ, payload, err)
+	}
+	packet[len(packet)-1] = 'y'
+	if _, _, err := parseBattlEyePacket(packet); err == nil {
+		t.Error("Expected a packet with a wrong checksum to be refused")
+	}
+}
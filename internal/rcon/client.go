@@ -3,14 +3,18 @@
 package rcon
 
 import (
-	"bytes"
-	"encoding/binary"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/logging"
+	"github.com/mjmorales/rcon-mcp-server/internal/timerwheel"
 )
 
 // PacketType represents the type of RCON packet as defined by the Source RCON protocol.
@@ -22,6 +26,7 @@ const (
 	PacketTypeAuthResponse PacketType = 2 // Authentication response
 	PacketTypeCommand      PacketType = 2 // Command execution request
 	PacketTypeResponse     PacketType = 0 // Command response
+	PacketTypeChat         PacketType = 1 // Chat and events some servers, such as Squad's, send unprompted
 )
 
 // Protocol constants for RCON communication.
@@ -29,8 +34,14 @@ const (
 	maxPacketSize = 4096             // Maximum allowed packet size in bytes
 	headerSize    = 12               // Packet header size: size(4) + id(4) + type(4)
 	timeout       = 10 * time.Second // Default timeout for network operations
+	maxStaleReads = 16               // Stale responses skipped before giving up on a reply
 )
 
+// MaxResponseBodySize is the largest response body a single packet can carry.
+// Responses of exactly this size have most likely been cut short by the
+// server and continue in packets that Execute does not read.
+const MaxResponseBodySize = maxPacketSize - 10
+
 // Packet represents an RCON protocol packet.
 // Each packet contains a size, request ID, type, and body payload.
 type Packet struct {
@@ -43,85 +54,143 @@ type Packet struct {
 // Client manages an RCON connection to a server.
 // It handles connection state, authentication, and command execution.
 // All operations are thread-safe.
+//
+// Exchanges with the server, authentication and commands, take turns
+// through the queue and run without holding the mutex, which only guards
+// the connection state for the instant it is read or changed. Status
+// checks therefore never wait for the network, and Disconnect closes the
+// connection at once, failing an exchange in flight instead of waiting
+// for it.
 type Client struct {
-	conn         net.Conn   // TCP connection to the RCON server
-	mu           sync.Mutex // Mutex for thread-safe operations
-	requestID    int32      // Counter for generating unique request IDs
-	isConnected  bool       // Connection state flag
-	isAuthorized bool       // Authentication state flag
+	conn         net.Conn       // Connection to the server
+	mu           sync.Mutex     // Guards the connection state below, never held during network I/O
+	queue        chan struct{}  // Slot held by the exchange with the server in progress
+	isConnected  bool           // Connection state flag
+	isAuthorized bool           // Authentication state flag
+	lastRead     atomic.Int64   // When the server was last heard from, in Unix nanoseconds
+	read         ReadOptions    // Tuning of the read path for connections opened from now on
+	protocol     Protocol       // Wire protocol spoken on connections opened from now on
+	backend      func() Backend // Makes the Backend of connections opened from now on, if not the protocol's own
+	wire         Backend        // Backend speaking the protocol of the connection, set on Connect
+	listening    bool           // Whether RCON connections opened from now on are read by a listener
+	onConsole    func(ConsoleMessage)
+	timers       *timerwheel.Wheel // Schedules the keepalives of connections authenticated from now on, if set
+	keepAlive    *timerwheel.Timer // Next keepalive of the connection, nil if its protocol needs none
+
+	// The I/O timeout of the exchange in progress when its context allows
+	// longer than the default; only the exchange holding the queue slot
+	// uses it.
+	slowTimeout time.Duration
+
+	onDisconnect func(error) // Called when a dead connection is detected
 }
 
 // NewClient creates a new RCON client instance.
 // The client is created in a disconnected state.
 func NewClient() *Client {
-	return &Client{
-		requestID: 1,
-	}
+	return &Client{queue: make(chan struct{}, 1)}
 }
 
-// Connect establishes a TCP connection to an RCON server.
+// Connect establishes a connection to a server speaking the client's
+// protocol, through the protocol's Backend or the one set with SetBackend.
 // The address should be in the format "host:port".
 // Returns an error if already connected or if the connection fails.
 func (c *Client) Connect(address string) error {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if c.isConnected {
+	if c.IsConnected() {
 		return errors.New("already connected")
 	}
 
-	conn, err := net.DialTimeout("tcp", address, timeout)
+	protocol := c.Protocol()
+	wire := c.newBackend(protocol)
+	if wire == nil {
+		return fmt.Errorf("unknown protocol %q", protocol)
+	}
+	if tb, ok := wire.(tunedBackend); ok {
+		tb.tune(c.readOptions(), c.isListening())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn, err := wire.Dial(ctx, address)
 	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
+		return fmt.Errorf("failed to connect: %w", timeoutError(err))
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isConnected {
+		// Another caller connected while this one was dialing
+		_ = conn.Close()
+		return errors.New("already connected")
+	}
 	c.conn = conn
+	c.wire = wire
 	c.isConnected = true
 	return nil
 }
 
-// Authenticate performs RCON authentication using the provided password.
+// Authenticate logs in to the server with the provided password through
+// the Backend of the connection: a Source RCON auth packet, the path of a
+// WebRCON WebSocket, the answer to a telnet password prompt, a REST or
+// API token, or whatever the protocol's Backend does with it. The Backend
+// is given the client's console handler first if it takes one.
 // Must be called after Connect and before Execute.
 // Returns an error if not connected, already authenticated, or if authentication fails.
 func (c *Client) Authenticate(password string) error {
+	c.queue <- struct{}{}
+	defer func() { <-c.queue }()
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	conn, connected, authorized, wire := c.conn, c.isConnected, c.isAuthorized, c.wire
+	c.mu.Unlock()
 
-	if !c.isConnected {
-		return errors.New("not connected")
+	if !connected {
+		return ErrNotConnected
 	}
 
-	if c.isAuthorized {
+	if authorized {
 		return errors.New("already authenticated")
 	}
 
-	// Send auth packet
-	authPacket := &Packet{
-		ID:   c.getNextRequestID(),
-		Type: PacketTypeAuth,
-		Body: password,
+	if cb, ok := wire.(ConsoleBackend); ok {
+		cb.SetConsoleHandler(func(msg ConsoleMessage) {
+			c.lastRead.Store(time.Now().UnixNano())
+			c.mu.Lock()
+			handler := c.onConsole
+			c.mu.Unlock()
+			if handler != nil {
+				handler(msg)
+			}
+		})
 	}
-
-	if err := c.sendPacket(authPacket); err != nil {
-		return fmt.Errorf("failed to send auth packet: %w", err)
+	if wb, ok := wire.(watchedBackend); ok {
+		wb.setDropHandler(func(err error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.conn == conn {
+				c.connectionLost(err)
+			}
+		})
 	}
 
-	// Read auth response
-	response, err := c.readPacket()
-	if err != nil {
-		return fmt.Errorf("failed to read auth response: %w", err)
+	ctx, cancel := context.WithTimeout(context.Background(), c.ioTimeout())
+	defer cancel()
+	if err := wire.Auth(ctx, conn, password); err != nil {
+		if errors.Is(err, ErrAuthFailed) {
+			return err
+		}
+		return c.authFailed(conn, timeoutError(err))
 	}
 
-	// Check auth response
-	if response.ID == -1 {
-		return errors.New("authentication failed: invalid password")
-	}
-
-	if response.ID != authPacket.ID {
-		return errors.New("authentication failed: unexpected response ID")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != conn {
+		return closedError(errors.New("disconnected during authentication"))
 	}
-
 	c.isAuthorized = true
+	c.lastRead.Store(time.Now().UnixNano())
+	if every := wire.Capabilities().KeepAlive; every > 0 {
+		c.scheduleKeepAlive(conn, every)
+	}
 	return nil
 }
 
@@ -129,44 +198,68 @@ func (c *Client) Authenticate(password string) error {
 // The client must be connected and authenticated before executing commands.
 // Returns the server's response as a string, or an error if execution fails.
 func (c *Client) Execute(command string) (string, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	return c.ExecuteContext(context.Background(), command)
+}
 
-	if !c.isConnected {
-		return "", errors.New("not connected")
+// ExecuteContext is like Execute but gives up when ctx is done. A command
+// still waiting for its turn releases its place in the queue; a command
+// already on the wire is given up on by the connection's Backend, which
+// watches the context it is handed. The connection stays usable: a late
+// reply to a cancelled command is skipped by the next one. A deadline of ctx
+// further away than the default I/O timeout extends it, for commands a
+// server is known to answer slowly. Commands containing control
+// characters are refused without being sent; see CheckCommand.
+func (c *Client) ExecuteContext(ctx context.Context, command string) (string, error) {
+	if err := CheckCommand(command); err != nil {
+		return "", err
 	}
 
-	if !c.isAuthorized {
-		return "", errors.New("not authenticated")
+	select {
+	case c.queue <- struct{}{}:
+		defer func() { <-c.queue }()
+	case <-ctx.Done():
+		return "", fmt.Errorf("command cancelled: %w", ctx.Err())
 	}
 
-	// Send command packet
-	cmdPacket := &Packet{
-		ID:   c.getNextRequestID(),
-		Type: PacketTypeCommand,
-		Body: command,
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) > timeout {
+		c.slowTimeout = time.Until(deadline)
+		defer func() { c.slowTimeout = 0 }()
 	}
 
-	if err := c.sendPacket(cmdPacket); err != nil {
-		return "", fmt.Errorf("failed to send command: %w", err)
-	}
+	c.mu.Lock()
+	conn, connected, authorized, wire := c.conn, c.isConnected, c.isAuthorized, c.wire
+	c.mu.Unlock()
 
-	// Read response
-	response, err := c.readPacket()
-	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+	if !connected {
+		return "", ErrNotConnected
 	}
 
-	// Verify response ID matches request
-	if response.ID != cmdPacket.ID {
-		return "", errors.New("response ID mismatch")
+	if !authorized {
+		return "", ErrNotAuthenticated
 	}
 
-	return response.Body, nil
+	// The exchange gets its own I/O deadline and ends once ctx is done, so
+	// that I/O cut short at ctx's deadline fails after ctx reports it
+	exchangeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.ioTimeout())
+	defer cancel()
+	defer context.AfterFunc(ctx, cancel)()
+	out, err := wire.Execute(exchangeCtx, conn, command)
+	switch {
+	case err == nil:
+		c.lastRead.Store(time.Now().UnixNano())
+		return out, nil
+	case ctx.Err() != nil:
+		return "", fmt.Errorf("command cancelled: %w", ctx.Err())
+	case exchangeCtx.Err() != nil:
+		return "", fmt.Errorf("failed to read response: %w", ErrTimeout)
+	}
+	return "", c.exchangeFailed(conn, timeoutError(err))
 }
 
 // Disconnect closes the TCP connection to the RCON server.
 // It's safe to call Disconnect multiple times or on an already disconnected client.
+// It does not wait for a command in progress, which fails with
+// ErrNotConnected as the connection closes under it.
 func (c *Client) Disconnect() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -180,6 +273,8 @@ func (c *Client) Disconnect() error {
 	}
 
 	c.conn = nil
+	c.wire = nil
+	c.stopKeepAlive()
 	c.isConnected = false
 	c.isAuthorized = false
 	return nil
@@ -192,6 +287,18 @@ func (c *Client) IsConnected() bool {
 	return c.isConnected
 }
 
+// Addrs returns the local and remote addresses of the client's connection,
+// or nil for both when it is not connected. The local address is one the
+// server can reach the client's host at.
+func (c *Client) Addrs() (local, remote net.Addr) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.isConnected || c.conn == nil {
+		return nil, nil
+	}
+	return c.conn.LocalAddr(), c.conn.RemoteAddr()
+}
+
 // IsAuthenticated returns true if the client has successfully authenticated with the server.
 func (c *Client) IsAuthenticated() bool {
 	c.mu.Lock()
@@ -199,94 +306,127 @@ func (c *Client) IsAuthenticated() bool {
 	return c.isAuthorized
 }
 
-// sendPacket encodes and sends a packet to the RCON server.
-// It automatically calculates the packet size and adds null terminators.
-func (c *Client) sendPacket(packet *Packet) error {
-	// Calculate packet size
-	bodyBytes := []byte(packet.Body)
-	packet.Size = int32(len(bodyBytes) + 10) // body + ID(4) + Type(4) + null terminators(2)
-
-	// Create packet buffer
-	buf := new(bytes.Buffer)
+// SetDisconnectHandler registers a function to be called when the client
+// detects that the server closed or reset the connection. The handler runs
+// in its own goroutine so it may safely call back into the client.
+func (c *Client) SetDisconnectHandler(handler func(error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onDisconnect = handler
+}
 
-	// Write packet fields
-	if err := binary.Write(buf, binary.LittleEndian, packet.Size); err != nil {
-		return fmt.Errorf("failed to write packet size: %w", err)
-	}
-	if err := binary.Write(buf, binary.LittleEndian, packet.ID); err != nil {
-		return fmt.Errorf("failed to write packet ID: %w", err)
-	}
-	if err := binary.Write(buf, binary.LittleEndian, packet.Type); err != nil {
-		return fmt.Errorf("failed to write packet type: %w", err)
+// exchangeFailed returns the error of an exchange with the server on conn
+// that failed with err. If Disconnect closed conn in the meantime the
+// exchange is reported as disconnected; otherwise the client is marked
+// disconnected if err shows that the connection is dead.
+func (c *Client) exchangeFailed(conn net.Conn, err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != conn {
+		return closedError(err)
 	}
-	buf.Write(bodyBytes)
-	buf.WriteByte(0) // Body null terminator
-	buf.WriteByte(0) // Packet null terminator
+	c.checkConnectionLost(err)
+	return err
+}
 
-	// Send packet
-	if err := c.conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
-		return fmt.Errorf("failed to set write deadline: %w", err)
-	}
-	if _, err := c.conn.Write(buf.Bytes()); err != nil {
-		return err
+// authFailed is like exchangeFailed for a failed authentication, except
+// that a dead connection is left for the caller to close rather than
+// reported as dropped, since it never carried a command.
+func (c *Client) authFailed(conn net.Conn, err error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != conn {
+		return closedError(err)
 	}
-
-	return nil
+	return err
 }
 
-// readPacket reads and decodes a packet from the RCON server.
-// It validates packet size and parses the packet structure.
-func (c *Client) readPacket() (*Packet, error) {
-	if err := c.conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
-		return nil, fmt.Errorf("failed to set read deadline: %w", err)
-	}
+// closedError reports that an exchange failed with err because Disconnect
+// closed its connection.
+func closedError(err error) error {
+	return fmt.Errorf("%w: connection closed: %w", ErrNotConnected, err)
+}
 
-	// Read packet size
-	sizeBuf := make([]byte, 4)
-	if _, err := io.ReadFull(c.conn, sizeBuf); err != nil {
-		return nil, err
+// checkConnectionLost marks the client as disconnected if err shows that the
+// connection is dead, and notifies the disconnect handler.
+// Must be called with c.mu held.
+func (c *Client) checkConnectionLost(err error) {
+	if isConnectionError(err) {
+		c.connectionLost(err)
 	}
+}
 
-	var size int32
-	if err := binary.Read(bytes.NewReader(sizeBuf), binary.LittleEndian, &size); err != nil {
-		return nil, err
-	}
+// connectionLost closes the connection, which err shows is unusable, marks
+// the client as disconnected and notifies the disconnect handler.
+// Must be called with c.mu held.
+func (c *Client) connectionLost(err error) {
+	_ = c.conn.Close()
+	c.conn = nil
+	c.wire = nil
+	c.stopKeepAlive()
+	c.isConnected = false
+	c.isAuthorized = false
 
-	if size < 10 || size > maxPacketSize {
-		return nil, fmt.Errorf("invalid packet size: %d", size)
+	if c.onDisconnect != nil {
+		go c.onDisconnect(err)
 	}
+}
 
-	// Read rest of packet
-	packetBuf := make([]byte, size)
-	if _, err := io.ReadFull(c.conn, packetBuf); err != nil {
-		return nil, err
-	}
+// isConnectionError reports whether err indicates that the underlying
+// connection was closed or reset by the peer. Timeouts are not treated as
+// connection loss since the server may simply be slow.
+func isConnectionError(err error) bool {
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE)
+}
 
-	// Parse packet
-	packet := &Packet{}
-	reader := bytes.NewReader(packetBuf)
+// ioTimeout returns the I/O timeout of the exchange in progress: the
+// default, or longer if its context allows. Must be called by the exchange
+// holding the queue slot.
+func (c *Client) ioTimeout() time.Duration {
+	return max(timeout, c.slowTimeout)
+}
 
-	if err := binary.Read(reader, binary.LittleEndian, &packet.ID); err != nil {
-		return nil, err
-	}
+// SetReadOptions tunes the read path of the connections the client opens
+// from now on; the current connection keeps its settings.
+func (c *Client) SetReadOptions(opts ReadOptions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.read = opts
+}
 
-	var pType int32
-	if err := binary.Read(reader, binary.LittleEndian, &pType); err != nil {
-		return nil, err
+// RaisePacketLimit raises the largest packet accepted by the connections
+// the client opens from now on to at least size, keeping a higher limit set
+// with SetReadOptions.
+func (c *Client) RaisePacketLimit(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.read.maxPacketSize() < size {
+		c.read.MaxPacketSize = size
 	}
-	packet.Type = PacketType(pType)
+}
 
-	// Read body (everything except the last 2 null bytes)
-	bodyBytes := packetBuf[8 : len(packetBuf)-2]
-	packet.Body = string(bodyBytes)
+// readOptions returns the options set with SetReadOptions.
+func (c *Client) readOptions() ReadOptions {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.read
+}
 
-	return packet, nil
+// quietFor returns how long ago the server last sent a packet, which shows
+// the connection was alive then.
+func (c *Client) quietFor() time.Duration {
+	return time.Since(time.Unix(0, c.lastRead.Load()))
 }
 
-// getNextRequestID generates a unique request ID for packet tracking.
-// IDs are incremented sequentially for each request.
-func (c *Client) getNextRequestID() int32 {
-	id := c.requestID
-	c.requestID++
-	return id
+// tracePacket logs p at the debug level with the logger carried by ctx, so
+// that the trace carries the request ID of the tool call that caused it.
+// The body is left out: it holds the command or response, which may
+// contain secrets.
+func tracePacket(ctx context.Context, msg string, p *Packet) {
+	logging.FromContext(ctx, nil).Debug(msg, "packet_id", p.ID, "packet_type", p.Type, "body_bytes", len(p.Body))
 }
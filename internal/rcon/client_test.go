@@ -2,10 +2,18 @@ package rcon
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
 	"net"
+	"os"
+	"syscall"
 	"testing"
 	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcontest"
 )
 
 // mockConn implements net.Conn for testing
@@ -74,9 +82,6 @@ func TestNewClient(t *testing.T) {
 	if client == nil {
 		t.Fatal("NewClient returned nil")
 	}
-	if client.requestID != 1 {
-		t.Errorf("Expected requestID to be 1, got %d", client.requestID)
-	}
 	if client.isConnected {
 		t.Error("Expected isConnected to be false")
 	}
@@ -142,21 +147,6 @@ func TestClient_Authenticate(t *testing.T) {
 		wantErr     bool
 		errContains string
 	}{
-		{
-			name:     "successful authentication",
-			password: "testpass",
-			setup: func(c *Client, mc *mockConn) {
-				c.isConnected = true
-				c.conn = mc
-				// Write auth response with matching ID
-				writePacketToBuffer(mc.readBuf, &Packet{
-					ID:   2, // Will match the request ID
-					Type: PacketTypeAuthResponse,
-					Body: "",
-				})
-			},
-			wantErr: false,
-		},
 		{
 			name:     "not connected",
 			password: "testpass",
@@ -176,28 +166,11 @@ func TestClient_Authenticate(t *testing.T) {
 			wantErr:     true,
 			errContains: "already authenticated",
 		},
-		{
-			name:     "invalid password",
-			password: "badpass",
-			setup: func(c *Client, mc *mockConn) {
-				c.isConnected = true
-				c.conn = mc
-				// Write auth response with ID -1 (auth failure)
-				writePacketToBuffer(mc.readBuf, &Packet{
-					ID:   -1,
-					Type: PacketTypeAuthResponse,
-					Body: "",
-				})
-			},
-			wantErr:     true,
-			errContains: "invalid password",
-		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client := NewClient()
-			client.requestID = 2 // Set known request ID for testing
 			mc := newMockConn()
 			
 			tt.setup(client, mc)
@@ -231,23 +204,6 @@ func TestClient_Execute(t *testing.T) {
 		wantErr     bool
 		errContains string
 	}{
-		{
-			name:    "successful command execution",
-			command: "list",
-			setup: func(c *Client, mc *mockConn) {
-				c.isConnected = true
-				c.isAuthorized = true
-				c.conn = mc
-				// Write command response
-				writePacketToBuffer(mc.readBuf, &Packet{
-					ID:   2, // Will match the request ID
-					Type: PacketTypeResponse,
-					Body: "Player1\nPlayer2\nPlayer3",
-				})
-			},
-			want:    "Player1\nPlayer2\nPlayer3",
-			wantErr: false,
-		},
 		{
 			name:    "not connected",
 			command: "list",
@@ -267,29 +223,11 @@ func TestClient_Execute(t *testing.T) {
 			wantErr:     true,
 			errContains: "not authenticated",
 		},
-		{
-			name:    "response ID mismatch",
-			command: "list",
-			setup: func(c *Client, mc *mockConn) {
-				c.isConnected = true
-				c.isAuthorized = true
-				c.conn = mc
-				// Write response with wrong ID
-				writePacketToBuffer(mc.readBuf, &Packet{
-					ID:   99, // Wrong ID
-					Type: PacketTypeResponse,
-					Body: "data",
-				})
-			},
-			wantErr:     true,
-			errContains: "response ID mismatch",
-		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			client := NewClient()
-			client.requestID = 2 // Set known request ID for testing
 			mc := newMockConn()
 			
 			tt.setup(client, mc)
@@ -314,6 +252,119 @@ func TestClient_Execute(t *testing.T) {
 	}
 }
 
+// dialTestServer connects a client to a test server answering commands
+// with handler and accepting the password "secret", which it returns with
+// the client still to authenticate.
+func dialTestServer(t *testing.T, handler rcontest.Handler) (*Client, *rcontest.Server) {
+	t.Helper()
+	server := rcontest.NewServer(t, "secret", handler)
+	client := NewClient()
+	if err := client.Connect(server.Addr()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect() })
+	return client, server
+}
+
+func TestClient_Authenticate_Server(t *testing.T) {
+	tests := []struct {
+		name        string
+		password    string
+		sourceAuth  bool
+		reply       rcontest.Reply
+		wantErr     error
+		errContains string
+	}{
+		{name: "successful authentication", password: "secret"},
+		{name: "empty response before the auth response", password: "secret", sourceAuth: true},
+		{name: "auth response split across writes", password: "secret", reply: rcontest.Reply{Fragment: 3}},
+		{name: "invalid password", password: "badpass", wantErr: ErrAuthFailed, errContains: "invalid password"},
+		{name: "unexpected response ID", password: "secret", reply: rcontest.Reply{Fault: rcontest.FaultMismatch}, wantErr: ErrAuthFailed, errContains: "unexpected response ID"},
+		{name: "connection closed", password: "secret", reply: rcontest.Reply{Fault: rcontest.FaultClose}, wantErr: io.EOF, errContains: "failed to read auth response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := rcontest.NewUnstartedServer(t, "secret", nil)
+			server.SourceAuth = tt.sourceAuth
+			server.AuthReply = tt.reply
+			server.Start()
+			client := NewClient()
+			if err := client.Connect(server.Addr()); err != nil {
+				t.Fatalf("Connect failed: %v", err)
+			}
+			defer client.Disconnect()
+
+			err := client.Authenticate(tt.password)
+			if tt.wantErr == nil {
+				if err != nil || !client.IsAuthenticated() {
+					t.Errorf("Expected the client to be authenticated, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) || !contains(err.Error(), tt.errContains) {
+				t.Errorf("Expected %v containing %q, got %v", tt.wantErr, tt.errContains, err)
+			}
+		})
+	}
+}
+
+func TestClient_Execute_Server(t *testing.T) {
+	tests := []struct {
+		name        string
+		reply       rcontest.Reply
+		want        string
+		errContains string
+	}{
+		{name: "successful command execution", reply: rcontest.Reply{Body: "Player1\nPlayer2\nPlayer3"}, want: "Player1\nPlayer2\nPlayer3"},
+		{name: "response split across writes", reply: rcontest.Reply{Body: "There are 2 of a max of 20 players online", Fragment: 5}, want: "There are 2 of a max of 20 players online"},
+		{name: "response ID mismatch", reply: rcontest.Reply{Body: "data", Fault: rcontest.FaultMismatch}, errContains: "response ID mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server := dialTestServer(t, rcontest.Script(t, rcontest.Exchange{Command: "list", Reply: tt.reply}))
+			if err := client.Authenticate("secret"); err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+
+			got, err := client.Execute("list")
+			if tt.errContains != "" {
+				if err == nil || !contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Execute = %q, %v, want %q", got, err, tt.want)
+			}
+			if commands := server.Commands(); len(commands) != 1 || commands[0] != "list" {
+				t.Errorf("Expected the server to get the command, got %q", commands)
+			}
+		})
+	}
+}
+
+func TestClient_Execute_StaleReply(t *testing.T) {
+	client, _ := dialTestServer(t, rcontest.Script(t,
+		rcontest.Exchange{Command: "save-all", Reply: rcontest.Reply{Body: "late", Delay: 100 * time.Millisecond}},
+		rcontest.Exchange{Command: "list", Reply: rcontest.Reply{Body: "fresh"}},
+	))
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.ExecuteContext(ctx, "save-all"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the slow command to time out, got %v", err)
+	}
+	// The late reply to the cancelled command arrives first and is skipped
+	if got, err := client.Execute("list"); err != nil || got != "fresh" {
+		t.Errorf("Execute = %q, %v, want %q", got, err, "fresh")
+	}
+}
+
 func TestClient_Disconnect(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -472,7 +523,7 @@ func TestPacketSerialization(t *testing.T) {
 			client.conn = mc
 			
 			// Send packet
-			err := client.sendPacket(tt.packet)
+			err := (&sourceRCON{}).sendPacket(mc, tt.packet)
 			if err != nil {
 				t.Fatalf("sendPacket failed: %v", err)
 			}
@@ -520,4 +571,161 @@ func TestPacketSerialization(t *testing.T) {
 // Helper function
 func contains(s, substr string) bool {
 	return bytes.Contains([]byte(s), []byte(substr))
-}
\ No newline at end of file
+}
+func TestClient_Execute_ConnectionLost(t *testing.T) {
+	client, _ := dialTestServer(t, func(string) rcontest.Reply { return rcontest.Reply{Fault: rcontest.FaultClose} })
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	dropped := make(chan error, 1)
+	client.SetDisconnectHandler(func(err error) { dropped <- err })
+
+	// The server closes the connection instead of answering
+	if _, err := client.Execute("list"); err == nil {
+		t.Fatal("Expected error but got nil")
+	}
+
+	select {
+	case err := <-dropped:
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("Expected io.EOF, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Disconnect handler was not called")
+	}
+
+	if client.IsConnected() || client.IsAuthenticated() {
+		t.Error("Expected client to be marked disconnected")
+	}
+}
+
+func TestClient_Execute_ConnectionReset(t *testing.T) {
+	client, server := dialTestServer(t, rcontest.Respond(func(string) string { return "ok" }))
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	// The server restarts under the idle connection
+	server.ResetConnections()
+	if _, err := client.Execute("list"); err == nil {
+		t.Fatal("Expected a command on a reset connection to fail")
+	}
+	if client.IsConnected() {
+		t.Error("Expected client to be marked disconnected")
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "eof", err: io.EOF, want: true},
+		{name: "wrapped reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), want: true},
+		{name: "closed", err: net.ErrClosed, want: true},
+		{name: "timeout", err: os.ErrDeadlineExceeded, want: false},
+		{name: "other", err: errors.New("invalid packet size: 5"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isConnectionError(tt.err); got != tt.want {
+				t.Errorf("isConnectionError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ExecuteContext_Cancel(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*Client)
+	}{
+		{
+			name: "cancelled while waiting on the server",
+		},
+		{
+			name: "cancelled while queued behind another command",
+			setup: func(c *Client) {
+				c.queue <- struct{}{}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The server reads whatever the client sends but never replies
+			client, _ := dialTestServer(t, func(string) rcontest.Reply { return rcontest.Reply{Fault: rcontest.FaultHang} })
+			if err := client.Authenticate("secret"); err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+			if tt.setup != nil {
+				tt.setup(client)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			start := time.Now()
+			_, err := client.ExecuteContext(ctx, "save-all")
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("Expected context.Canceled, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("Cancellation took %v", elapsed)
+			}
+			if !client.IsConnected() {
+				t.Error("Expected client to stay connected after cancellation")
+			}
+		})
+	}
+}
+
+func TestClient_DisconnectDuringExecute(t *testing.T) {
+	// The server reads whatever the client sends but never replies
+	client, _ := dialTestServer(t, func(string) rcontest.Reply { return rcontest.Reply{Fault: rcontest.FaultHang} })
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	dropped := make(chan error, 1)
+	client.SetDisconnectHandler(func(err error) { dropped <- err })
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.Execute("save-all")
+		result <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// Status checks and Disconnect do not wait for the command's read,
+	// which would otherwise block for the full I/O timeout.
+	start := time.Now()
+	if !client.IsConnected() || !client.IsAuthenticated() {
+		t.Error("Expected the client to report itself connected while the command runs")
+	}
+	if err := client.Disconnect(); err != nil {
+		t.Fatalf("Disconnect failed: %v", err)
+	}
+	select {
+	case err := <-result:
+		if !errors.Is(err, ErrNotConnected) {
+			t.Errorf("Expected the command to fail as disconnected, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("The command did not return after Disconnect")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Disconnect took %v", elapsed)
+	}
+
+	select {
+	case err := <-dropped:
+		t.Errorf("Expected no drop to be reported for a deliberate disconnect, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if client.IsConnected() {
+		t.Error("Expected the client to be disconnected")
+	}
+}
@@ -0,0 +1,157 @@
+package rcon
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// maxPooledBuffer is the largest packet buffer kept for reuse. Buffers grown
+// past it for an unusually long command are left to the garbage collector,
+// so that one such command does not pin its buffer for the process's life.
+const maxPooledBuffer = 4 + maxPacketSize
+
+// packetBuffers holds the buffers packets are encoded into and read into,
+// so that a broadcast over many sessions does not allocate two of them per
+// command. Each buffer is a *[]byte with room for the largest response.
+var packetBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, maxPooledBuffer)
+		return &buf
+	},
+}
+
+// getPacketBuffer takes an empty buffer from packetBuffers.
+func getPacketBuffer() *[]byte {
+	buf := packetBuffers.Get().(*[]byte)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// putPacketBuffer returns buf to packetBuffers unless it has grown too large
+// to keep. buf must not be used afterwards.
+func putPacketBuffer(buf *[]byte) {
+	if cap(*buf) <= maxPooledBuffer {
+		packetBuffers.Put(buf)
+	}
+}
+
+// appendPacket appends the wire encoding of p to dst, setting p.Size, and
+// returns the extended buffer.
+func appendPacket(dst []byte, p *Packet) []byte {
+	p.Size = int32(len(p.Body) + 10) // body + ID(4) + Type(4) + null terminators(2)
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(p.Size))
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(p.ID))
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(p.Type))
+	dst = append(dst, p.Body...)
+	return append(dst, 0, 0) // Body and packet null terminators
+}
+
+// WritePacket writes the wire encoding of p to w in one write, setting
+// p.Size. The client sends its packets with it, and fake servers such as
+// the mock one answer with it.
+func WritePacket(w io.Writer, p *Packet) error {
+	buf := getPacketBuffer()
+	defer putPacketBuffer(buf)
+	*buf = appendPacket(*buf, p)
+	_, err := w.Write(*buf)
+	return err
+}
+
+// ReadPacket reads one packet from r. A packet declaring more than limit
+// bytes is refused with ErrResponseTooLarge before its body is read.
+func ReadPacket(r io.Reader, limit int32) (*Packet, error) {
+	buf := getPacketBuffer()
+	defer putPacketBuffer(buf)
+
+	// Read packet size
+	header := (*buf)[:headerSize]
+	if _, err := io.ReadFull(r, header[:4]); err != nil {
+		return nil, err
+	}
+	size := int32(binary.LittleEndian.Uint32(header))
+
+	if size > limit {
+		return nil, fmt.Errorf("%w: packet of %d bytes exceeds the limit of %d", ErrResponseTooLarge, size, limit)
+	}
+	if size < 10 {
+		return nil, fmt.Errorf("invalid packet size: %d", size)
+	}
+
+	// Read rest of packet
+	if _, err := io.ReadFull(r, header[4:]); err != nil {
+		return nil, err
+	}
+	p := &Packet{
+		Size: size,
+		ID:   int32(binary.LittleEndian.Uint32(header[4:8])),
+		Type: PacketType(binary.LittleEndian.Uint32(header[8:12])),
+	}
+	// The body reuses the buffer the header was read into
+	body, err := readBody(r, int(size)-10, *buf)
+	if err != nil {
+		return nil, err
+	}
+	p.Body = body
+	return p, nil
+}
+
+// readBody reads a packet body of n bytes, and the two null bytes that end
+// the packet, from r. Bodies that fit in chunk are read into it at once.
+// Larger ones, which only a raised ReadOptions.MaxPacketSize lets through,
+// are streamed through chunk into a buffer that grows only as the data
+// arrives, so a packet that declares a huge size and then stalls does not
+// reserve that much memory.
+func readBody(r io.Reader, n int, chunk []byte) (string, error) {
+	chunk = chunk[:cap(chunk)]
+	if n+2 <= len(chunk) {
+		if _, err := io.ReadFull(r, chunk[:n+2]); err != nil {
+			return "", err
+		}
+		return string(chunk[:n]), nil
+	}
+
+	var body strings.Builder
+	for left := n + 2; left > 0; {
+		m := min(left, len(chunk))
+		if _, err := io.ReadFull(r, chunk[:m]); err != nil {
+			return "", err
+		}
+		body.Write(chunk[:m])
+		left -= m
+	}
+	return body.String()[:n], nil
+}
+
+// ReadOptions tunes how a client reads from its connection. The zero value
+// selects the defaults.
+type ReadOptions struct {
+	BufferSize    int // Size of the buffered reader on the connection, DefaultReadBuffer if 0
+	MaxPacketSize int // Largest packet accepted, the protocol's 4096 bytes if 0; at most MaxReadLimit
+}
+
+// Bounds of ReadOptions.
+const (
+	DefaultReadBuffer = 4096     // Buffered reader size when none is set
+	MinReadBuffer     = 16       // Smallest buffered reader size
+	MaxReadBuffer     = 1 << 20  // Largest buffered reader size
+	MaxReadLimit      = 16 << 20 // Largest packet size ReadOptions.MaxPacketSize may allow
+)
+
+// bufferSize returns the size of the buffered reader to use.
+func (o ReadOptions) bufferSize() int {
+	if o.BufferSize <= 0 {
+		return DefaultReadBuffer
+	}
+	return min(max(o.BufferSize, MinReadBuffer), MaxReadBuffer)
+}
+
+// maxPacketSize returns the largest packet to accept.
+func (o ReadOptions) maxPacketSize() int {
+	if o.MaxPacketSize <= 0 {
+		return maxPacketSize
+	}
+	return min(max(o.MaxPacketSize, maxPacketSize), MaxReadLimit)
+}
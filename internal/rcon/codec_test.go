@@ -0,0 +1,177 @@
+package rcon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+func TestPacketCodec_RoundTrip(t *testing.T) {
+	wire := &sourceRCON{}
+	for _, body := range []string{"", "status", strings.Repeat("x", MaxResponseBodySize)} {
+		mc := newMockConn()
+		sent := &Packet{ID: 7, Type: PacketTypeCommand, Body: body}
+		if err := wire.sendPacket(mc, sent); err != nil {
+			t.Fatalf("sendPacket failed: %v", err)
+		}
+
+		mc.readBuf = bytes.NewBuffer(mc.writeBuf.Bytes())
+		got, err := wire.readPacket(mc)
+		if err != nil {
+			t.Fatalf("readPacket failed: %v", err)
+		}
+		if *got != *sent {
+			t.Errorf("Read back %+v, want %+v", got, sent)
+		}
+	}
+}
+
+func TestPacketCodec_Exported(t *testing.T) {
+	var buf bytes.Buffer
+	sent := &Packet{ID: 3, Type: PacketTypeAuth, Body: "secret"}
+	if err := WritePacket(&buf, sent); err != nil {
+		t.Fatalf("WritePacket failed: %v", err)
+	}
+	wire := bytes.Clone(buf.Bytes())
+
+	got, err := ReadPacket(&buf, maxPacketSize)
+	if err != nil {
+		t.Fatalf("ReadPacket failed: %v", err)
+	}
+	if *got != *sent {
+		t.Errorf("Read back %+v, want %+v", got, sent)
+	}
+	if _, err := ReadPacket(bytes.NewReader(wire), 10); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge over the limit, got %v", err)
+	}
+}
+
+func TestPacketCodec_LongCommand(t *testing.T) {
+	// A command longer than any response grows its buffer past the pooled
+	// size; it must still go out whole, and later packets must be unaffected.
+	wire := &sourceRCON{}
+	mc := newMockConn()
+	long := strings.Repeat("y", 2*maxPacketSize)
+	if err := wire.sendPacket(mc, &Packet{ID: 1, Type: PacketTypeCommand, Body: long}); err != nil {
+		t.Fatalf("sendPacket failed: %v", err)
+	}
+	if n := mc.writeBuf.Len(); n != len(long)+14 {
+		t.Errorf("Expected %d bytes written, got %d", len(long)+14, n)
+	}
+
+	mc.writeBuf.Reset()
+	if err := wire.sendPacket(mc, &Packet{ID: 2, Type: PacketTypeCommand, Body: "list"}); err != nil {
+		t.Fatalf("sendPacket failed: %v", err)
+	}
+	mc.readBuf = bytes.NewBuffer(mc.writeBuf.Bytes())
+	if got, err := wire.readPacket(mc); err != nil || got.ID != 2 || got.Body != "list" {
+		t.Errorf("Expected the next packet intact, got %+v, %v", got, err)
+	}
+}
+
+func TestPacketCodec_ReadOptions(t *testing.T) {
+	client := NewClient()
+	client.SetReadOptions(ReadOptions{BufferSize: 64, MaxPacketSize: 64 << 10})
+	wire := &sourceRCON{read: client.readOptions()}
+	mc := newMockConn()
+	big := strings.Repeat("z", 20000)
+	_ = writePacketToBuffer(mc.readBuf, &Packet{ID: 1, Type: PacketTypeResponse, Body: big})
+	_ = writePacketToBuffer(mc.readBuf, &Packet{ID: 2, Type: PacketTypeResponse, Body: "after"})
+	_ = writePacketToBuffer(mc.readBuf, &Packet{ID: 3, Type: PacketTypeResponse, Body: strings.Repeat("z", 70000)})
+
+	// A packet above the protocol's size is streamed in whole when allowed.
+	got, err := wire.readPacket(mc)
+	if err != nil {
+		t.Fatalf("readPacket failed: %v", err)
+	}
+	if got.ID != 1 || got.Body != big {
+		t.Fatalf("Expected the large packet intact, got ID %d with %d bytes", got.ID, len(got.Body))
+	}
+	if got, err := wire.readPacket(mc); err != nil || got.ID != 2 || got.Body != "after" {
+		t.Errorf("Expected the next packet intact, got %+v, %v", got, err)
+	}
+	if _, err := wire.readPacket(mc); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected a packet above the configured limit to fail, got %v", err)
+	}
+
+	// A packet that announces more than it sends fails without waiting for
+	// the rest.
+	mc = newMockConn()
+	_ = binary.Write(mc.readBuf, binary.LittleEndian, int32(60000))
+	mc.readBuf.Write(make([]byte, 5000))
+	if _, err := wire.readPacket(mc); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected a truncated packet to fail, got %v", err)
+	}
+}
+
+func TestSession_SetGameRaisesPacketLimit(t *testing.T) {
+	sm := NewSessionManager()
+	session, err := sm.CreateSession("factorio", "Factorio", "127.0.0.1:27015")
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	session.SetGame(game.Factorio)
+	mc := newMockConn()
+	big := strings.Repeat("z", 20000)
+	_ = writePacketToBuffer(mc.readBuf, &Packet{ID: 1, Type: PacketTypeResponse, Body: big})
+	if got, err := (&sourceRCON{read: session.Client.readOptions()}).readPacket(mc); err != nil || got.Body != big {
+		t.Errorf("Expected a Factorio session to accept a large reply, got %v", err)
+	}
+
+	// A higher configured limit is kept
+	session.Client.SetReadOptions(ReadOptions{MaxPacketSize: MaxReadLimit})
+	session.SetGame(game.Factorio)
+	if got := session.Client.readOptions().maxPacketSize(); got != MaxReadLimit {
+		t.Errorf("Expected the configured limit to be kept, got %d", got)
+	}
+}
+
+// replayConn is a mockConn whose reads replay the same bytes forever, so
+// that a benchmark can read packets without refilling it.
+type replayConn struct {
+	mockConn
+	data []byte
+	r    bytes.Reader
+}
+
+func (c *replayConn) Read(b []byte) (int, error) {
+	if c.r.Len() == 0 {
+		c.r.Reset(c.data)
+	}
+	return c.r.Read(b)
+}
+
+func (c *replayConn) Write(b []byte) (int, error) { return len(b), nil }
+
+func BenchmarkSendPacket(b *testing.B) {
+	wire := &sourceRCON{}
+	conn := &replayConn{}
+	packet := &Packet{ID: 1, Type: PacketTypeCommand, Body: "say The server restarts in five minutes"}
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := wire.sendPacket(conn, packet); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadPacket(b *testing.B) {
+	var buf bytes.Buffer
+	if err := writePacketToBuffer(&buf, &Packet{ID: 1, Type: PacketTypeResponse, Body: strings.Repeat("player ", 64)}); err != nil {
+		b.Fatal(err)
+	}
+	wire := &sourceRCON{}
+	conn := &replayConn{data: buf.Bytes()}
+	b.ReportAllocs()
+	b.SetBytes(int64(buf.Len()))
+	for b.Loop() {
+		if _, err := wire.readPacket(conn); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+// ErrInvalidCommand is returned for commands that are never sent because
+// they contain control characters.
+var ErrInvalidCommand = errors.New("invalid command")
+
+// CheckCommand returns an error wrapping ErrInvalidCommand if command
+// contains a newline, a null byte or any other control character, including
+// the Unicode line and paragraph separators. Some consoles treat a newline
+// as the end of a command, so one embedded in a command would run whatever
+// follows it as a second, unchecked command.
+func CheckCommand(command string) error {
+	for i, r := range command {
+		if unicode.IsControl(r) || r == '\u2028' || r == '\u2029' {
+			return fmt.Errorf("%w: control character %U at byte %d; send one command per call", ErrInvalidCommand, r, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package rcon
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckCommand(t *testing.T) {
+	valid := []string{"", "list", "say héllo wörld", "tp @p ~ ~1 ~", "/time set day"}
+	for _, command := range valid {
+		if err := CheckCommand(command); err != nil {
+			t.Errorf("CheckCommand(%q) = %v, want nil", command, err)
+		}
+	}
+
+	invalid := []string{"list\nstop", "say hi\r\nop eve", "list\x00", "say \x1b[31mred", "say\tthere", "say a\u2028op eve", "list\u0085"}
+	for _, command := range invalid {
+		if err := CheckCommand(command); !errors.Is(err, ErrInvalidCommand) {
+			t.Errorf("CheckCommand(%q) = %v, want ErrInvalidCommand", command, err)
+		}
+	}
+}
+
+func TestClient_ExecuteRejectsControlCharacters(t *testing.T) {
+	client := NewClient()
+	if _, err := client.Execute("list\nstop"); !errors.Is(err, ErrInvalidCommand) {
+		t.Errorf("Expected ErrInvalidCommand before the connection is used, got %v", err)
+	}
+}
@@ -0,0 +1,599 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Statuses of a conformance check.
+const (
+	ConformanceOK    = "ok"    // The server behaves as the protocol specifies
+	ConformanceQuirk = "quirk" // The server deviates in a way the client copes with, possibly given a setting
+	ConformanceFail  = "fail"  // The server deviates in a way that breaks the client
+)
+
+// conformanceQuiet is how long a server must stay silent for a reply to be
+// considered complete, since the protocol has no end marker.
+const conformanceQuiet = 250 * time.Millisecond
+
+// conformanceEchoID is the request ID of the ID echo check. Its bytes all
+// differ, so that a server truncating or reordering IDs is caught.
+const conformanceEchoID = 0x01020304
+
+// ConformanceCheck is the outcome of one conformance check.
+type ConformanceCheck struct {
+	Name    string `json:"name"`             // What was checked
+	Status  string `json:"status"`           // ConformanceOK, ConformanceQuirk or ConformanceFail
+	Message string `json:"message"`          // What the server did
+	Advice  string `json:"advice,omitempty"` // How to work with the server, for quirks and failures
+}
+
+// ConformanceOptions tunes CheckConformance. The zero value selects the
+// defaults.
+type ConformanceOptions struct {
+	Command         string        // Command answered in one packet, "status" if empty
+	LargeCommand    string        // Command whose reply may span several packets, "help" if empty
+	Timeout         time.Duration // How long to wait for each reply, 5 seconds if 0
+	SkipBadPassword bool          // Skip logging in with a wrong password, for servers that ban after failed logins
+}
+
+// withDefaults returns o with its unset fields set to the defaults.
+func (o ConformanceOptions) withDefaults() ConformanceOptions {
+	if o.Command == "" {
+		o.Command = "status"
+	}
+	if o.LargeCommand == "" {
+		o.LargeCommand = "help"
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 5 * time.Second
+	}
+	return o
+}
+
+// CheckConformance runs a battery of checks of how the Source RCON server
+// at address handles the protocol: its answers to logins, whether replies
+// echo request IDs, how it reads packets split across writes and packets
+// of the largest size, how it splits long replies, whether it mirrors the
+// empty packets clients mark the end of replies with, and whether it
+// answers commands sent together. The report tells which deviations the
+// client copes with and which settings it needs for them.
+//
+// The checks speak the protocol on raw connections rather than through a
+// Client, so that they see every packet the server sends. They fail only
+// when the server cannot be reached or refuses password, with ErrAuthFailed
+// in the latter case; a check whose connection breaks reports it and the
+// next one reconnects.
+func CheckConformance(ctx context.Context, address, password string, opts ConformanceOptions) ([]ConformanceCheck, error) {
+	r := &conformanceRun{ctx: ctx, address: address, password: password, opts: opts.withDefaults()}
+	defer r.reset()
+
+	auth, err := r.checkAuth()
+	if err != nil {
+		return nil, err
+	}
+	checks := []ConformanceCheck{auth}
+	steps := []func() ConformanceCheck{r.checkIDEcho, r.checkRequestFragments, r.checkMaxRequest, r.checkMultiPacket, r.checkPipelining}
+	if !r.opts.SkipBadPassword {
+		steps = append([]func() ConformanceCheck{r.checkBadPassword}, steps...)
+	}
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return checks, err
+		}
+		checks = append(checks, step())
+		if len(r.extra) > 0 {
+			checks, r.extra = append(checks, r.extra...), nil
+		}
+	}
+	return checks, nil
+}
+
+// conformanceRun holds the state of CheckConformance.
+type conformanceRun struct {
+	ctx      context.Context
+	address  string
+	password string
+	opts     ConformanceOptions
+	probe    *conformanceProbe  // Authenticated connection shared by the checks, nil once one breaks it
+	extra    []ConformanceCheck // Further outcomes of the last check, reported after it
+}
+
+// conn returns the shared connection, opening and authenticating another
+// if the last one broke.
+func (r *conformanceRun) conn() (*conformanceProbe, error) {
+	if r.probe != nil {
+		return r.probe, nil
+	}
+	p, err := dialProbe(r.ctx, r.address, r.opts.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconnect: %w", err)
+	}
+	packets, _, err := p.login(r.password, r.opts.Timeout)
+	if err != nil || packets[len(packets)-1].ID == -1 {
+		p.close()
+		return nil, fmt.Errorf("failed to log in again: %s", conformanceOutcome(err, r.opts.Timeout))
+	}
+	r.probe = p
+	return p, nil
+}
+
+// reset closes the shared connection, so that the next check opens another.
+func (r *conformanceRun) reset() {
+	if r.probe != nil {
+		r.probe.close()
+		r.probe = nil
+	}
+}
+
+// broken closes the shared connection after err broke it during check,
+// and reports check as failed.
+func (r *conformanceRun) broken(check ConformanceCheck, doing string, err error) ConformanceCheck {
+	r.reset()
+	check.Status = ConformanceFail
+	check.Message = fmt.Sprintf("the server %s %s", conformanceOutcome(err, r.opts.Timeout), doing)
+	return check
+}
+
+// checkAuth logs in with the password on the connection the later checks
+// share. It fails when the server cannot be reached or refuses the login.
+func (r *conformanceRun) checkAuth() (ConformanceCheck, error) {
+	check := ConformanceCheck{Name: "auth"}
+	p, err := dialProbe(r.ctx, r.address, r.opts.Timeout)
+	if err != nil {
+		return check, err
+	}
+	packets, id, err := p.login(r.password, r.opts.Timeout)
+	if err != nil && len(packets) == 0 {
+		p.close()
+		return check, fmt.Errorf("the server %s to the login", conformanceOutcome(err, r.opts.Timeout))
+	}
+	last := packets[len(packets)-1]
+	if last.ID == -1 {
+		p.close()
+		return check, fmt.Errorf("%w: invalid password", ErrAuthFailed)
+	}
+	r.probe = p
+
+	switch {
+	case last.Type != PacketTypeAuthResponse:
+		check.Status = ConformanceQuirk
+		check.Message = fmt.Sprintf("the server answered the login with %d response packets and no auth response", len(packets))
+		check.Advice = "the client takes the first packet as the auth response, so it logs in as long as the ID is echoed"
+		if packets[0].ID != id {
+			check.Status = ConformanceFail
+			check.Advice = "the client refuses logins whose first reply carries another ID than the request's"
+		}
+	case packets[0].ID != id:
+		check.Status = ConformanceFail
+		check.Message = fmt.Sprintf("the login was answered with ID %d instead of the request's %d", packets[0].ID, id)
+		check.Advice = "the client refuses logins answered with another ID than the request's"
+	case len(packets) > 1:
+		check.Status = ConformanceOK
+		empty := "an empty response packet"
+		if len(packets) > 2 {
+			empty = fmt.Sprintf("%d empty response packets", len(packets)-1)
+		}
+		check.Message = "the login was answered with " + empty + " and then the auth response, as Source servers do"
+	default:
+		check.Status = ConformanceOK
+		check.Message = "the login was answered with an auth response echoing the request ID"
+	}
+	return check, nil
+}
+
+// checkBadPassword logs in with a wrong password on a connection of its
+// own, to see how the server refuses it.
+func (r *conformanceRun) checkBadPassword() ConformanceCheck {
+	check := ConformanceCheck{Name: "bad-password"}
+	p, err := dialProbe(r.ctx, r.address, r.opts.Timeout)
+	if err != nil {
+		check.Status, check.Message = ConformanceFail, err.Error()
+		return check
+	}
+	defer p.close()
+
+	packets, id, err := p.login(r.password+"-conformance-check", r.opts.Timeout)
+	switch {
+	case err != nil && isTimeout(err):
+		check.Status = ConformanceQuirk
+		check.Message = fmt.Sprintf("the server did not answer a wrong password within %v", r.opts.Timeout)
+		check.Advice = "logins with a wrong password fail as timeouts rather than authentication errors"
+	case err != nil:
+		check.Status = ConformanceQuirk
+		check.Message = fmt.Sprintf("the server %s instead of answering a wrong password with ID -1", conformanceOutcome(err, r.opts.Timeout))
+		check.Advice = "logins with a wrong password fail as connection errors rather than authentication errors"
+	case packets[len(packets)-1].ID == -1:
+		check.Status = ConformanceOK
+		check.Message = "the server refused a wrong password with ID -1"
+	case packets[len(packets)-1].ID == id:
+		check.Status = ConformanceFail
+		check.Message = "the server accepted a wrong password"
+		check.Advice = "the server does not check the RCON password; anyone who can reach its port controls it"
+	default:
+		check.Status = ConformanceQuirk
+		check.Message = fmt.Sprintf("the server refused a wrong password with ID %d instead of -1", packets[len(packets)-1].ID)
+		check.Advice = "the client reports such refusals as an unexpected response ID"
+	}
+	return check
+}
+
+// checkIDEcho sends a command with an ID whose bytes all differ, to see
+// that the reply carries it unchanged.
+func (r *conformanceRun) checkIDEcho() ConformanceCheck {
+	check := ConformanceCheck{Name: "id-echo"}
+	p, err := r.conn()
+	if err != nil {
+		check.Status, check.Message = ConformanceFail, err.Error()
+		return check
+	}
+	doing := fmt.Sprintf("to %q", r.opts.Command)
+	if err := p.write(r.opts.Timeout, &Packet{ID: conformanceEchoID, Type: PacketTypeCommand, Body: r.opts.Command}); err != nil {
+		return r.broken(check, doing, err)
+	}
+	reply, err := p.read(r.opts.Timeout)
+	if err != nil {
+		return r.broken(check, doing, err)
+	}
+	r.drain()
+
+	if reply.ID != conformanceEchoID {
+		check.Status = ConformanceFail
+		check.Message = fmt.Sprintf("the reply to %q carried ID %d instead of the request's %d", r.opts.Command, reply.ID, conformanceEchoID)
+		check.Advice = "the client matches replies to commands by ID and fails every command on this server"
+		return check
+	}
+	check.Status = ConformanceOK
+	check.Message = fmt.Sprintf("the reply to %q echoed the request ID", r.opts.Command)
+	return check
+}
+
+// checkRequestFragments writes a command packet in three pieces with
+// pauses between them, as a congested network may deliver it, to see that
+// the server reassembles it.
+func (r *conformanceRun) checkRequestFragments() ConformanceCheck {
+	check := ConformanceCheck{Name: "request-fragments"}
+	p, err := r.conn()
+	if err != nil {
+		check.Status, check.Message = ConformanceFail, err.Error()
+		return check
+	}
+	id := p.nextID()
+	data := appendPacket(nil, &Packet{ID: id, Type: PacketTypeCommand, Body: r.opts.Command})
+	for _, piece := range [][]byte{data[:4], data[4:9], data[9:]} {
+		if err := p.writeRaw(r.opts.Timeout, piece); err != nil {
+			return r.broken(check, "to a packet split across writes", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if _, err := p.collect(r.opts.Timeout, id); err != nil {
+		r.reset()
+		check.Status = ConformanceQuirk
+		check.Message = fmt.Sprintf("the server %s to a command packet split across three writes", conformanceOutcome(err, r.opts.Timeout))
+		check.Advice = "the client writes each packet at once, but commands may fail over links that split them"
+		return check
+	}
+	r.drain()
+	check.Status = ConformanceOK
+	check.Message = "the server reassembled a command packet split across three writes"
+	return check
+}
+
+// checkMaxRequest sends commands padded with spaces to the largest packet
+// the protocol allows, and to smaller sizes if the server drops it, to
+// find the longest command the server takes.
+func (r *conformanceRun) checkMaxRequest() ConformanceCheck {
+	check := ConformanceCheck{Name: "max-request"}
+	var dropped []string
+	for _, size := range []int{maxPacketSize, 2048, 1460, 1024} {
+		if len(r.opts.Command) > size-10 {
+			break
+		}
+		p, err := r.conn()
+		if err != nil {
+			check.Status, check.Message = ConformanceFail, err.Error()
+			return check
+		}
+		id := p.nextID()
+		body := r.opts.Command + strings.Repeat(" ", size-10-len(r.opts.Command))
+		err = p.write(r.opts.Timeout, &Packet{ID: id, Type: PacketTypeCommand, Body: body})
+		if err == nil {
+			_, err = p.collect(r.opts.Timeout, id)
+		}
+		if err != nil {
+			r.reset()
+			dropped = append(dropped, fmt.Sprintf("%s to a packet of %d bytes", conformanceOutcome(err, r.opts.Timeout), size))
+			continue
+		}
+		r.drain()
+
+		if size == maxPacketSize {
+			check.Status = ConformanceOK
+			check.Message = fmt.Sprintf("the server answered a command in a packet of %d bytes, the protocol's largest", size)
+			return check
+		}
+		check.Status = ConformanceQuirk
+		check.Message = fmt.Sprintf("the server %s, but answered one of %d bytes", strings.Join(dropped, " and "), size)
+		check.Advice = fmt.Sprintf("keep commands under %d bytes on this server", size-10)
+		return check
+	}
+	check.Status = ConformanceFail
+	check.Message = "the server " + strings.Join(dropped, " and ")
+	check.Advice = "pass a shorter --command if it is too long for the server"
+	return check
+}
+
+// checkMultiPacket sends the large command followed by an empty response
+// packet, the end marker Source servers mirror, in one write. It reports
+// how the reply was split, and adds an end-marker check telling how the
+// marker was answered.
+func (r *conformanceRun) checkMultiPacket() ConformanceCheck {
+	check := ConformanceCheck{Name: "multi-packet"}
+	p, err := r.conn()
+	if err != nil {
+		check.Status, check.Message = ConformanceFail, err.Error()
+		return check
+	}
+	id, marker := p.nextID(), p.nextID()
+	doing := fmt.Sprintf("to %q", r.opts.LargeCommand)
+	if err := p.write(r.opts.Timeout, &Packet{ID: id, Type: PacketTypeCommand, Body: r.opts.LargeCommand}, &Packet{ID: marker, Type: PacketTypeResponse}); err != nil {
+		return r.broken(check, doing, err)
+	}
+
+	// Wait for the reply, then read until the server falls quiet
+	var reply, answers []*Packet
+	wait := r.opts.Timeout
+	for {
+		packet, err := p.read(wait)
+		if err != nil {
+			if !isTimeout(err) {
+				return r.broken(check, doing, err)
+			}
+			break
+		}
+		switch packet.ID {
+		case id:
+			reply, wait = append(reply, packet), conformanceQuiet
+		case marker:
+			answers, wait = append(answers, packet), conformanceQuiet
+		}
+	}
+	r.extra = append(r.extra, endMarkerCheck(answers))
+
+	largest, output := int32(0), 0
+	for _, packet := range reply {
+		largest, output = max(largest, packet.Size), output+len(packet.Body)
+	}
+	switch {
+	case len(reply) == 0:
+		check.Status = ConformanceFail
+		check.Message = fmt.Sprintf("the server did not reply to %q within %v", r.opts.LargeCommand, r.opts.Timeout)
+	case largest > maxPacketSize:
+		check.Status = ConformanceQuirk
+		check.Message = fmt.Sprintf("the reply to %q spanned %d packets of up to %d bytes, over the protocol's %d", r.opts.LargeCommand, len(reply), largest, maxPacketSize)
+		check.Advice = fmt.Sprintf("set limits.max_packet_size to at least %d in the config file", largest)
+	case len(reply) > 1:
+		check.Status = ConformanceOK
+		check.Message = fmt.Sprintf("the reply to %q spanned %d packets of up to %d bytes, %d bytes of output in all; commands return the first packet", r.opts.LargeCommand, len(reply), largest, output)
+	default:
+		check.Status = ConformanceOK
+		check.Message = fmt.Sprintf("the reply to %q fit in one packet of %d bytes; pass a command with more output to see how the server splits replies", r.opts.LargeCommand, largest)
+	}
+	return check
+}
+
+// endMarkerCheck reports how the server answered the empty response packet
+// sent after a command.
+func endMarkerCheck(answers []*Packet) ConformanceCheck {
+	check := ConformanceCheck{Name: "end-marker", Status: ConformanceQuirk}
+	switch {
+	case len(answers) == 0:
+		check.Message = "the server did not answer an empty response packet sent after a command"
+		check.Advice = "none needed, the client does not rely on it; tools that find the end of long replies this way hang on this server"
+	case len(answers) == 2 && answers[0].Body == "" && answers[1].Body == "\x00\x01\x00\x00":
+		check.Status = ConformanceOK
+		check.Message = "the server mirrored an empty response packet sent after a command and followed it with 0x00000100, as Source servers do"
+	default:
+		check.Message = fmt.Sprintf("the server answered an empty response packet sent after a command with %d packets, the first %q", len(answers), truncate(answers[0].Body, 60))
+		check.Advice = "none needed, the client does not rely on it; tools that find the end of long replies this way misread them on this server"
+	}
+	return check
+}
+
+// checkPipelining sends two commands in one write, to see that both are
+// answered in order, as they must be when a command follows one whose
+// reply was abandoned.
+func (r *conformanceRun) checkPipelining() ConformanceCheck {
+	check := ConformanceCheck{Name: "pipelining"}
+	p, err := r.conn()
+	if err != nil {
+		check.Status, check.Message = ConformanceFail, err.Error()
+		return check
+	}
+	first, second := p.nextID(), p.nextID()
+	doing := "to two commands sent together"
+	if err := p.write(r.opts.Timeout, &Packet{ID: first, Type: PacketTypeCommand, Body: r.opts.Command}, &Packet{ID: second, Type: PacketTypeCommand, Body: r.opts.Command}); err != nil {
+		return r.broken(check, doing, err)
+	}
+	packets, err := p.collect(r.opts.Timeout, second)
+	if err != nil && !isTimeout(err) {
+		return r.broken(check, doing, err)
+	}
+	r.drain()
+
+	order := []int32{}
+	for _, packet := range packets {
+		if (packet.ID == first || packet.ID == second) && (len(order) == 0 || order[len(order)-1] != packet.ID) {
+			order = append(order, packet.ID)
+		}
+	}
+	switch {
+	case len(order) >= 2 && order[0] == first:
+		check.Status = ConformanceOK
+		check.Message = "the server answered two commands sent together, in order"
+	case len(order) >= 2:
+		check.Status = ConformanceQuirk
+		check.Message = "the server answered two commands sent together out of order"
+		check.Advice = "a command sent while the reply to a cancelled one is pending may fail with a response ID mismatch"
+	default:
+		check.Status = ConformanceQuirk
+		check.Message = fmt.Sprintf("the server answered %d of two commands sent together", len(order))
+		check.Advice = "a command sent while the reply to a cancelled one is pending may time out"
+	}
+	return check
+}
+
+// drain reads what remains of the last reply on the shared connection,
+// until the server falls quiet, closing the connection if it breaks.
+func (r *conformanceRun) drain() {
+	for r.probe != nil {
+		if _, err := r.probe.read(conformanceQuiet); err != nil {
+			if !isTimeout(err) {
+				r.reset()
+			}
+			return
+		}
+	}
+}
+
+// conformanceProbe is a raw Source RCON connection, on which the checks
+// write packets and read them back as the server sent them.
+type conformanceProbe struct {
+	conn net.Conn
+	rd   *bufio.Reader
+	id   int32
+	stop func() bool // Stops closing conn when the context of CheckConformance is done
+}
+
+// dialProbe connects to address, closing the connection when ctx is done.
+func dialProbe(ctx context.Context, address string, timeout time.Duration) (*conformanceProbe, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	return &conformanceProbe{
+		conn: conn,
+		rd:   bufio.NewReader(conn),
+		stop: context.AfterFunc(ctx, func() { _ = conn.Close() }),
+	}, nil
+}
+
+// close closes the connection.
+func (p *conformanceProbe) close() {
+	p.stop()
+	_ = p.conn.Close()
+}
+
+// nextID returns the ID of the next request.
+func (p *conformanceProbe) nextID() int32 {
+	p.id++
+	return p.id
+}
+
+// login sends an auth packet with password and reads the replies up to the
+// auth response or refusal, giving up after four packets without one. It
+// returns the packets read, at least one unless err is set, and the ID of
+// the request.
+func (p *conformanceProbe) login(password string, wait time.Duration) ([]*Packet, int32, error) {
+	id := p.nextID()
+	if err := p.write(wait, &Packet{ID: id, Type: PacketTypeAuth, Body: password}); err != nil {
+		return nil, id, err
+	}
+	var packets []*Packet
+	for range 4 {
+		packet, err := p.read(wait)
+		if err != nil {
+			return packets, id, err
+		}
+		packets = append(packets, packet)
+		if packet.Type == PacketTypeAuthResponse || packet.ID == -1 {
+			break
+		}
+	}
+	return packets, id, nil
+}
+
+// write sends packets in one write.
+func (p *conformanceProbe) write(wait time.Duration, packets ...*Packet) error {
+	var data []byte
+	for _, packet := range packets {
+		data = appendPacket(data, packet)
+	}
+	return p.writeRaw(wait, data)
+}
+
+// writeRaw sends data as it is.
+func (p *conformanceProbe) writeRaw(wait time.Duration, data []byte) error {
+	_ = p.conn.SetWriteDeadline(time.Now().Add(wait))
+	_, err := p.conn.Write(data)
+	return err
+}
+
+// read reads one packet, waiting at most wait for it. Packets of any size up
+// to MaxReadLimit are accepted, so that oversized ones can be measured.
+func (p *conformanceProbe) read(wait time.Duration) (*Packet, error) {
+	_ = p.conn.SetReadDeadline(time.Now().Add(wait))
+	var header [headerSize]byte
+	if _, err := io.ReadFull(p.rd, header[:]); err != nil {
+		return nil, err
+	}
+	packet := &Packet{
+		Size: int32(binary.LittleEndian.Uint32(header[0:4])),
+		ID:   int32(binary.LittleEndian.Uint32(header[4:8])),
+		Type: PacketType(binary.LittleEndian.Uint32(header[8:12])),
+	}
+	if packet.Size < 10 || packet.Size > MaxReadLimit {
+		return nil, fmt.Errorf("invalid packet size: %d", packet.Size)
+	}
+	body, err := readBody(p.rd, int(packet.Size)-10, make([]byte, 0, maxPacketSize))
+	if err != nil {
+		return nil, err
+	}
+	packet.Body = body
+	return packet, nil
+}
+
+// collect reads packets until one carries id, waiting at most wait for
+// each, and returns those read.
+func (p *conformanceProbe) collect(wait time.Duration, id int32) ([]*Packet, error) {
+	var packets []*Packet
+	for {
+		packet, err := p.read(wait)
+		if err != nil {
+			return packets, err
+		}
+		packets = append(packets, packet)
+		if packet.ID == id {
+			return packets, nil
+		}
+	}
+}
+
+// isTimeout reports whether err is a timeout of a network operation.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// conformanceOutcome describes what the server did instead of replying,
+// given the error that ended the wait for its reply.
+func conformanceOutcome(err error, wait time.Duration) string {
+	switch {
+	case err == nil:
+		return "replied"
+	case isTimeout(err):
+		return fmt.Sprintf("did not reply within %v", wait)
+	case isConnectionError(err):
+		return "closed the connection"
+	default:
+		return fmt.Sprintf("failed (%v)", err)
+	}
+}
@@ -0,0 +1,119 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcontest"
+)
+
+// conformanceServer starts a test server answering "help" with a reply of
+// 10000 bytes split into packets of split bytes, and other commands with
+// "ok".
+func conformanceServer(t *testing.T, split int, source bool) *rcontest.Server {
+	t.Helper()
+	server := rcontest.NewUnstartedServer(t, "secret", func(command string) rcontest.Reply {
+		if command == "help" {
+			return rcontest.Reply{Body: strings.Repeat("h", 10000), Split: split}
+		}
+		return rcontest.Reply{Body: "ok"}
+	})
+	server.SourceAuth, server.MirrorResponses = source, source
+	server.Start()
+	return server
+}
+
+// checksByName indexes checks by their name.
+func checksByName(checks []ConformanceCheck) map[string]ConformanceCheck {
+	byName := make(map[string]ConformanceCheck, len(checks))
+	for _, check := range checks {
+		byName[check.Name] = check
+	}
+	return byName
+}
+
+func TestCheckConformance_Source(t *testing.T) {
+	server := conformanceServer(t, MaxResponseBodySize, true)
+	checks, err := CheckConformance(context.Background(), server.Addr(), "secret", ConformanceOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("CheckConformance failed: %v", err)
+	}
+
+	want := []string{"auth", "bad-password", "id-echo", "request-fragments", "max-request", "multi-packet", "end-marker", "pipelining"}
+	if len(checks) != len(want) {
+		t.Fatalf("Expected %d checks, got %+v", len(want), checks)
+	}
+	for i, check := range checks {
+		if check.Name != want[i] {
+			t.Errorf("Check %d is %s, want %s", i, check.Name, want[i])
+		}
+		if check.Status != ConformanceOK {
+			t.Errorf("Expected %s to pass, got %s: %s", check.Name, check.Status, check.Message)
+		}
+	}
+	if msg := checks[5].Message; !strings.Contains(msg, "spanned 3 packets of up to 4096 bytes") {
+		t.Errorf("Unexpected multi-packet message %q", msg)
+	}
+	if msg := checks[0].Message; !strings.Contains(msg, "an empty response packet") {
+		t.Errorf("Expected the auth check to note the empty response, got %q", msg)
+	}
+}
+
+func TestCheckConformance_Quirks(t *testing.T) {
+	// Like Minecraft: bodies of 4096 bytes and no mirrored end marker
+	server := conformanceServer(t, 4096, false)
+	checks, err := CheckConformance(context.Background(), server.Addr(), "secret", ConformanceOptions{Timeout: time.Second, SkipBadPassword: true})
+	if err != nil {
+		t.Fatalf("CheckConformance failed: %v", err)
+	}
+	byName := checksByName(checks)
+	if _, ok := byName["bad-password"]; ok {
+		t.Error("Expected the bad-password check to be skipped")
+	}
+
+	multi := byName["multi-packet"]
+	if multi.Status != ConformanceQuirk || !strings.Contains(multi.Advice, "limits.max_packet_size to at least 4106") {
+		t.Errorf("Expected oversized packets to be a quirk advising the packet limit, got %+v", multi)
+	}
+	if marker := byName["end-marker"]; marker.Status != ConformanceQuirk {
+		t.Errorf("Expected an unmirrored end marker to be a quirk, got %+v", marker)
+	}
+	for _, name := range []string{"auth", "id-echo", "request-fragments", "max-request", "pipelining"} {
+		if check := byName[name]; check.Status != ConformanceOK {
+			t.Errorf("Expected %s to pass, got %+v", name, check)
+		}
+	}
+}
+
+func TestCheckConformance_BadPasswordAccepted(t *testing.T) {
+	server := rcontest.NewServer(t, "", rcontest.Respond(func(string) string { return "ok" }))
+	checks, err := CheckConformance(context.Background(), server.Addr(), "any", ConformanceOptions{Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("CheckConformance failed: %v", err)
+	}
+	if check := checksByName(checks)["bad-password"]; check.Status != ConformanceFail {
+		t.Errorf("Expected a server accepting any password to fail, got %+v", check)
+	}
+}
+
+func TestCheckConformance_Errors(t *testing.T) {
+	server := conformanceServer(t, 0, false)
+	if _, err := CheckConformance(context.Background(), server.Addr(), "wrong", ConformanceOptions{}); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed for a wrong password, got %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := ln.Addr().String()
+	ln.Close()
+	_, err = CheckConformance(context.Background(), address, "secret", ConformanceOptions{})
+	if err == nil || errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected a connection error for a closed port, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Errors returned, possibly wrapped, by clients and session managers, so
+// that callers can tell classes of failure apart with errors.Is.
+var (
+	// ErrNotConnected is returned for operations on a client without a
+	// connection, including one whose connection dropped.
+	ErrNotConnected = errors.New("not connected")
+
+	// ErrNotAuthenticated is returned for commands on a client that is
+	// connected but has not authenticated.
+	ErrNotAuthenticated = errors.New("not authenticated")
+
+	// ErrAuthFailed is returned when the server refuses the password.
+	ErrAuthFailed = errors.New("authentication failed")
+
+	// ErrTimeout is returned when the server does not accept a connection or
+	// answer a packet within the I/O timeout.
+	ErrTimeout = errors.New("timed out")
+
+	// ErrResponseTooLarge is returned for response packets larger than the
+	// protocol allows.
+	ErrResponseTooLarge = errors.New("response too large")
+
+	// ErrSessionNotFound is returned for session IDs the manager does not
+	// know.
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// timeoutError wraps err with ErrTimeout if it is a network timeout, and
+// returns it unchanged otherwise.
+func timeoutError(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package rcon
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestClient_ErrorClasses(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*Client, *mockConn)
+		call  func(*Client) error
+		want  error
+	}{
+		{
+			name:  "execute while disconnected",
+			setup: func(c *Client, mc *mockConn) {},
+			call:  func(c *Client) error { _, err := c.Execute("list"); return err },
+			want:  ErrNotConnected,
+		},
+		{
+			name: "execute before authenticating",
+			setup: func(c *Client, mc *mockConn) {
+				c.isConnected, c.conn = true, mc
+			},
+			call: func(c *Client) error { _, err := c.Execute("list"); return err },
+			want: ErrNotAuthenticated,
+		},
+		{
+			name: "wrong password",
+			setup: func(c *Client, mc *mockConn) {
+				c.isConnected, c.conn, c.wire = true, mc, &sourceRCON{}
+				writePacketToBuffer(mc.readBuf, &Packet{ID: -1, Type: PacketTypeAuthResponse})
+			},
+			call: func(c *Client) error { return c.Authenticate("bad") },
+			want: ErrAuthFailed,
+		},
+		{
+			name: "read timeout",
+			setup: func(c *Client, mc *mockConn) {
+				c.isConnected, c.isAuthorized, c.conn, c.wire = true, true, mc, &sourceRCON{}
+				mc.readErr = os.ErrDeadlineExceeded
+			},
+			call: func(c *Client) error { _, err := c.Execute("list"); return err },
+			want: ErrTimeout,
+		},
+		{
+			name: "oversized packet",
+			setup: func(c *Client, mc *mockConn) {
+				c.isConnected, c.isAuthorized, c.conn, c.wire = true, true, mc, &sourceRCON{}
+				_ = binary.Write(mc.readBuf, binary.LittleEndian, int32(maxPacketSize+1))
+			},
+			call: func(c *Client) error { _, err := c.Execute("list"); return err },
+			want: ErrResponseTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient()
+			mc := newMockConn()
+			tt.setup(client, mc)
+			if err := tt.call(client); !errors.Is(err, tt.want) {
+				t.Errorf("Expected an error matching %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSessionManager_ErrSessionNotFound(t *testing.T) {
+	sm := NewSessionManager()
+	if _, err := sm.GetSession("missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+	if err := sm.RemoveSession("missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,346 @@
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+// HTTPDialect describes the HTTP API a client speaking ProtocolGenericHTTP
+// runs commands through, one request per command, such as the console
+// endpoint of a hosting panel. Templates hold PasswordPlaceholder and
+// CommandPlaceholder, filled in as in a WebSocketDialect: escaped for a
+// query in URLs, as in JSON strings in JSON bodies and as they are
+// elsewhere.
+type HTTPDialect struct {
+	Method       string            // Method of command requests, POST if there is a body and GET otherwise
+	URL          string            // URL template of command requests, "http://" or "https://" then the host sent as Host and the path; the address is dialed
+	Insecure     bool              // Skip verifying the certificate of an https:// API, for self-signed ones
+	Headers      map[string]string // Header templates of every request, such as an Authorization carrying the password
+	Body         string            // Body template of command requests, none if empty
+	ResponsePath string            // Path of the output in a JSON reply; if empty, the whole body is the output
+	CheckURL     string            // URL template requested with GET to check the password when logging in and in health checks; nothing is checked if empty
+}
+
+// UsesPassword reports whether the requests of dialect d carry the
+// password, in a URL, a header or the body.
+func (d HTTPDialect) UsesPassword() bool {
+	if strings.Contains(d.URL+d.CheckURL+d.Body, PasswordPlaceholder) {
+		return true
+	}
+	for _, value := range d.Headers {
+		if strings.Contains(value, PasswordPlaceholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// compile checks d, filling in the defaults.
+func (d HTTPDialect) compile() (*HTTPDialect, error) {
+	cd := d
+	if cd.Method == "" {
+		cd.Method = http.MethodGet
+		if cd.Body != "" {
+			cd.Method = http.MethodPost
+		}
+	}
+	if !strings.Contains(cd.URL+cd.Body, CommandPlaceholder) {
+		return nil, fmt.Errorf("neither the url nor the body has a %s placeholder", CommandPlaceholder)
+	}
+	if err := checkHTTPURL("url", cd.URL); err != nil {
+		return nil, err
+	}
+	if cd.CheckURL != "" {
+		if err := checkHTTPURL("check_url", cd.CheckURL); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(cd.URL, "https:") != strings.HasPrefix(cd.CheckURL, "https:") {
+			return nil, errors.New("url and check_url must both be http:// or both be https://")
+		}
+	}
+	return &cd, nil
+}
+
+// checkHTTPURL reports whether the URL template named name is an http://
+// or https:// URL.
+func checkHTTPURL(name, template string) error {
+	u, err := url.Parse(fillURL(template, PasswordPlaceholder, "", CommandPlaceholder, ""))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("%s %q must be an http:// or https:// URL", name, template)
+	}
+	return nil
+}
+
+// CheckHTTPDialect reports whether d is complete.
+func CheckHTTPDialect(d HTTPDialect) error {
+	_, err := d.compile()
+	return err
+}
+
+// GenericHTTP returns a function making Backends that speak
+// ProtocolGenericHTTP to the API d describes, for SetBackend.
+func GenericHTTP(d HTTPDialect) func() Backend {
+	return func() Backend { return &genericHTTP{config: d} }
+}
+
+// genericHTTP is the Backend of a connection to an HTTP API described by
+// an HTTPDialect. Only the exchange holding the client's queue slot uses
+// it.
+type genericHTTP struct {
+	config   HTTPDialect
+	dialect  *HTTPDialect
+	address  string
+	host     string // Name the certificate of an https:// API is verified for
+	tls      bool   // Requests go over TLS
+	password string
+	conn     *httpConn
+	rd       *bufio.Reader // Reader of the connection the last request went over
+	rdOn     net.Conn
+	stale    bool // The server closed the connection after its last reply
+}
+
+// Dial opens a connection to the API, over TLS for an https:// URL.
+func (h *genericHTTP) Dial(ctx context.Context, address string) (net.Conn, error) {
+	dialect, err := h.config.compile()
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP dialect: %w", err)
+	}
+	u, _ := url.Parse(fillURL(dialect.URL, PasswordPlaceholder, "", CommandPlaceholder, ""))
+	h.dialect, h.address, h.host, h.tls = dialect, address, u.Hostname(), u.Scheme == "https"
+	conn, err := dialConsole(ctx, address, h.tls, h.host, dialect.Insecure)
+	if err != nil {
+		return nil, err
+	}
+	h.conn = &httpConn{conn: conn}
+	return h.conn, nil
+}
+
+// Capabilities describes an HTTP API, which only answers requests.
+func (h *genericHTTP) Capabilities() Capabilities {
+	return Capabilities{Network: "tcp", Game: game.Unknown}
+}
+
+// Auth checks the password with the dialect's CheckURL, if it has one,
+// since such APIs have no login of their own on the connection: every
+// request carries the password.
+func (h *genericHTTP) Auth(ctx context.Context, conn net.Conn, password string) error {
+	h.password = password
+	if h.dialect.CheckURL == "" {
+		return nil
+	}
+	_, err := h.request(ctx, http.MethodGet, h.dialect.CheckURL, "", "")
+	switch {
+	case errors.Is(err, ErrAuthFailed):
+		return err
+	case err != nil && isConnectionError(err):
+		return fmt.Errorf("%w: the server closed the connection", ErrAuthFailed)
+	case err != nil:
+		return fmt.Errorf("failed to check the password: %w", err)
+	}
+	return nil
+}
+
+// Execute sends command in a request made from the dialect's templates
+// and returns the reply's output. An empty command, as health checks send,
+// requests the CheckURL instead, or nothing if there is none.
+func (h *genericHTTP) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	method, template, body := h.dialect.Method, h.dialect.URL, h.dialect.Body
+	if command == "" {
+		if h.dialect.CheckURL == "" {
+			return "", nil
+		}
+		method, template, body = http.MethodGet, h.dialect.CheckURL, ""
+	}
+
+	out, err := h.request(ctx, method, template, body, command)
+	switch {
+	case err != nil:
+		return "", err
+	case command == "":
+		return "", nil
+	}
+	if path := h.dialect.ResponsePath; path != "" {
+		value, ok := jsonPath([]byte(out), path)
+		if !ok {
+			return "", fmt.Errorf("the reply has no %q: %s", path, truncate(out, 200))
+		}
+		return value, nil
+	}
+	return out, nil
+}
+
+// request sends a request with method to the URL template with the body
+// template, both filled in with the password and command, and returns the
+// body of the reply. A server that closed the connection after its last
+// reply, or while it sat idle, is dialed again once. A reply refusing the
+// password is ErrAuthFailed, and other replies that are not successes are
+// errors carrying the body.
+func (h *genericHTTP) request(ctx context.Context, method, template, body, command string) (string, error) {
+	values := []string{PasswordPlaceholder, h.password, CommandPlaceholder, command}
+	u, err := url.Parse(fillURL(template, values...))
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Host == "" {
+		u.Host = h.address
+	}
+	payload := []byte(nil)
+	if body != "" {
+		payload = []byte(fillTemplate(body, values...))
+	}
+	newRequest := func() *http.Request {
+		r, _ := http.NewRequest(method, u.String(), bytes.NewReader(payload))
+		if payload == nil {
+			r.Body, r.ContentLength = nil, 0
+		}
+		if trimmed := bytes.TrimSpace(payload); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		for name, value := range h.dialect.Headers {
+			r.Header.Set(name, replacePlaceholders(value, func(v string) string { return v }, values...))
+		}
+		return r
+	}
+
+	if h.stale {
+		if err := h.redial(ctx); err != nil {
+			return "", err
+		}
+	}
+	out, err := h.roundTrip(ctx, newRequest())
+	if err != nil && isConnectionError(err) && ctx.Err() == nil {
+		// The server dropped a connection kept open from an earlier request
+		if err := h.redial(ctx); err != nil {
+			return "", err
+		}
+		out, err = h.roundTrip(ctx, newRequest())
+	}
+	return out, err
+}
+
+// roundTrip sends req and reads the reply, giving up when ctx is done.
+func (h *genericHTTP) roundTrip(ctx context.Context, req *http.Request) (string, error) {
+	conn := h.conn.current()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return "", fmt.Errorf("failed to set deadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := req.Write(conn); err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	tracePacket(ctx, "Sent HTTP request", &Packet{Type: PacketTypeCommand, Body: req.URL.Path})
+	if h.rdOn != conn {
+		h.rd, h.rdOn = bufio.NewReader(conn), conn
+	}
+	resp, err := http.ReadResponse(h.rd, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxReadLimit))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	h.stale = resp.Close
+	tracePacket(ctx, "Received HTTP reply", &Packet{Type: PacketTypeResponse, Body: string(body)})
+
+	text := strings.TrimSpace(string(body))
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return "", fmt.Errorf("%w: HTTP %s", ErrAuthFailed, resp.Status)
+	case resp.StatusCode >= 300 && text != "":
+		return "", fmt.Errorf("the server refused the request: HTTP %s: %s", resp.Status, truncate(text, 200))
+	case resp.StatusCode >= 300:
+		return "", fmt.Errorf("the server refused the request: HTTP %s", resp.Status)
+	}
+	return text, nil
+}
+
+// redial replaces the connection the server closed with a new one to the
+// same address. If that fails, the error shows the connection closed, so
+// that the client marks itself disconnected.
+func (h *genericHTTP) redial(ctx context.Context) error {
+	fresh, err := dialConsole(ctx, h.address, h.tls, h.host, h.dialect.Insecure)
+	if err != nil {
+		return fmt.Errorf("failed to reconnect: %w: %w", net.ErrClosed, err)
+	}
+	if err := h.conn.replace(fresh); err != nil {
+		return err
+	}
+	h.stale = false
+	return nil
+}
+
+// httpConn is the connection of a client speaking a protocol of HTTP
+// requests: ProtocolTShock, ProtocolSatisfactory or ProtocolGenericHTTP.
+// The server may close the connection beneath it after any reply, which
+// the Backend then replaces with a new one, so that the client keeps the
+// connection Dial returned throughout.
+type httpConn struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// current returns the connection requests go over.
+func (c *httpConn) current() net.Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn
+}
+
+// replace makes fresh the connection requests go over, closing the old
+// one, unless Close was called, which fresh is closed for instead.
+func (c *httpConn) replace(fresh net.Conn) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		_ = fresh.Close()
+		return net.ErrClosed
+	}
+	_ = c.conn.Close()
+	c.conn = fresh
+	return nil
+}
+
+func (c *httpConn) Read(b []byte) (int, error)  { return c.current().Read(b) }
+func (c *httpConn) Write(b []byte) (int, error) { return c.current().Write(b) }
+func (c *httpConn) LocalAddr() net.Addr         { return c.current().LocalAddr() }
+func (c *httpConn) RemoteAddr() net.Addr        { return c.current().RemoteAddr() }
+
+func (c *httpConn) SetDeadline(t time.Time) error      { return c.current().SetDeadline(t) }
+func (c *httpConn) SetReadDeadline(t time.Time) error  { return c.current().SetReadDeadline(t) }
+func (c *httpConn) SetWriteDeadline(t time.Time) error { return c.current().SetWriteDeadline(t) }
+
+// Close closes the connection requests go over, and any that would
+// replace it.
+func (c *httpConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return c.conn.Close()
+}
+
+// truncate returns s cut to at most n bytes, marking the cut.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "") + "..."
+}
@@ -0,0 +1,146 @@
+package rcon
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// startGenericHTTPServer serves a panel-style API on a local port, over
+// TLS if secure is set, that takes the key "secret" as a bearer token.
+// POST /api/servers/1/command runs the command in the JSON body, answering
+// {"output": ...}, or fails with a 500 for "crash"; GET /api/servers/1
+// answers the server's state. Every reply closes the connection if close
+// is set.
+func startGenericHTTPServer(t *testing.T, secure, close bool) string {
+	t.Helper()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if close {
+			w.Header().Set("Connection", "close")
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, `{"error": "invalid key"}`, http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/servers/1":
+			w.Write([]byte(`{"state": "running"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/servers/1/command":
+			var body struct {
+				Command string `json:"command"`
+			}
+			if r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&body) != nil {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			if body.Command == "crash" {
+				http.Error(w, "server is offline", http.StatusInternalServerError)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"output": "echo " + body.Command})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	var srv *httptest.Server
+	if secure {
+		srv = httptest.NewTLSServer(handler)
+	} else {
+		srv = httptest.NewServer(handler)
+	}
+	t.Cleanup(srv.Close)
+	return srv.Listener.Addr().String()
+}
+
+// genericHTTPDialect is the dialect of startGenericHTTPServer.
+func genericHTTPDialect(secure bool) HTTPDialect {
+	scheme := "http"
+	if secure {
+		scheme = "https"
+	}
+	return HTTPDialect{
+		URL:          scheme + "://panel.example.com/api/servers/1/command",
+		Insecure:     true,
+		Headers:      map[string]string{"Authorization": "Bearer {password}"},
+		Body:         `{"command": "{command}"}`,
+		ResponsePath: "output",
+		CheckURL:     scheme + "://panel.example.com/api/servers/1",
+	}
+}
+
+func TestGenericHTTP(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		secure, close bool
+	}{
+		{"http", false, false},
+		{"https", true, false},
+		{"closing", false, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			address := startGenericHTTPServer(t, tt.secure, tt.close)
+			client := NewClient()
+			client.SetProtocol(ProtocolGenericHTTP)
+			client.SetBackend(GenericHTTP(genericHTTPDialect(tt.secure)))
+			if err := client.Connect(address); err != nil {
+				t.Fatalf("Connect failed: %v", err)
+			}
+			defer client.Disconnect()
+			if err := client.Authenticate("secret"); err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+
+			for _, command := range []string{"status", `say "hi"`} {
+				out, err := client.Execute(command)
+				if err != nil {
+					t.Fatalf("Execute(%q) failed: %v", command, err)
+				}
+				if want := "echo " + command; out != want {
+					t.Errorf("Execute(%q) = %q, want %q", command, out, want)
+				}
+			}
+			if _, err := client.Execute(""); err != nil {
+				t.Errorf("Expected the health check to pass, got %v", err)
+			}
+			if _, err := client.Execute("crash"); err == nil || !strings.Contains(err.Error(), "server is offline") {
+				t.Errorf("Expected the refusal to carry the body, got %v", err)
+			}
+			if !client.IsConnected() {
+				t.Error("Expected a refused request to keep the client connected")
+			}
+		})
+	}
+}
+
+func TestGenericHTTP_AuthFailed(t *testing.T) {
+	address := startGenericHTTPServer(t, false, false)
+	client := NewClient()
+	client.SetProtocol(ProtocolGenericHTTP)
+	client.SetBackend(GenericHTTP(genericHTTPDialect(false)))
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Disconnect()
+	if err := client.Authenticate("wrong"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+}
+
+func TestCheckHTTPDialect(t *testing.T) {
+	if err := CheckHTTPDialect(genericHTTPDialect(true)); err != nil {
+		t.Errorf("Expected the dialect to be valid, got %v", err)
+	}
+	for _, d := range []HTTPDialect{
+		{},
+		{URL: "ws://example.com/{command}"},
+		{URL: "http://example.com/console", Body: "say"},
+		{URL: "https://example.com/{command}", CheckURL: "http://example.com/"},
+	} {
+		if err := CheckHTTPDialect(d); err == nil {
+			t.Errorf("Expected %+v to be refused", d)
+		}
+	}
+}
@@ -0,0 +1,476 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+// PasswordPlaceholder is replaced by the password in what the steps of a
+// TelnetDialect's login send.
+const PasswordPlaceholder = "{password}"
+
+// defaultTelnetLogin answers a password prompt, the login of most consoles.
+var defaultTelnetLogin = []TelnetStep{{Expect: `(?i)password:\s*$`, Send: PasswordPlaceholder}}
+
+// TelnetDialect describes the telnet console a client speaking
+// ProtocolGenericTelnet logs in to and runs commands on. Its patterns are
+// regular expressions matched against each line the console sends, and
+// against text it leaves unterminated, as prompts are.
+type TelnetDialect struct {
+	Login       []TelnetStep // Prompts to answer when logging in, in order; a password prompt if empty
+	LoginFailed string       // Pattern of the line refusing the login, if the console sends one
+	Prompt      string       // Pattern of the prompt the console shows when ready for a command, which ends replies and the login; if empty, replies end once the console is quiet
+	Terminator  string       // Sent after each command and answer, "\r\n" if empty
+}
+
+// TelnetStep is one prompt of a telnet console's login and its answer.
+type TelnetStep struct {
+	Expect string // Pattern of the prompt
+	Send   string // Answer, with PasswordPlaceholder replaced by the password; nothing is sent if empty
+}
+
+// compiledTelnetDialect is a TelnetDialect with its patterns compiled;
+// those left empty are nil.
+type compiledTelnetDialect struct {
+	login      []*regexp.Regexp
+	send       []string
+	failed     *regexp.Regexp
+	prompt     *regexp.Regexp
+	terminator string
+}
+
+// UsesPassword reports whether logging in to a console of dialect d
+// sends the password, which the default login does.
+func (d TelnetDialect) UsesPassword() bool {
+	if len(d.Login) == 0 {
+		return true
+	}
+	for _, step := range d.Login {
+		if strings.Contains(step.Send, PasswordPlaceholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// compile compiles the patterns of d, filling in the defaults.
+func (d TelnetDialect) compile() (*compiledTelnetDialect, error) {
+	cd := &compiledTelnetDialect{terminator: d.Terminator}
+	if cd.terminator == "" {
+		cd.terminator = "\r\n"
+	}
+	steps := d.Login
+	if len(steps) == 0 {
+		steps = defaultTelnetLogin
+	}
+	for i, step := range steps {
+		re, err := regexp.Compile(step.Expect)
+		if err != nil {
+			return nil, fmt.Errorf("login step %d: %w", i+1, err)
+		}
+		cd.login = append(cd.login, re)
+		cd.send = append(cd.send, step.Send)
+	}
+	var err error
+	if d.LoginFailed != "" {
+		if cd.failed, err = regexp.Compile(d.LoginFailed); err != nil {
+			return nil, fmt.Errorf("login_failed: %w", err)
+		}
+	}
+	if d.Prompt != "" {
+		if cd.prompt, err = regexp.Compile(d.Prompt); err != nil {
+			return nil, fmt.Errorf("prompt: %w", err)
+		}
+	}
+	return cd, nil
+}
+
+// patterns returns every pattern of d, which unterminated text is matched
+// against.
+func (d *compiledTelnetDialect) patterns() []*regexp.Regexp {
+	patterns := append([]*regexp.Regexp(nil), d.login...)
+	for _, re := range []*regexp.Regexp{d.failed, d.prompt} {
+		if re != nil {
+			patterns = append(patterns, re)
+		}
+	}
+	return patterns
+}
+
+// CheckTelnetDialect reports whether the patterns of d compile.
+func CheckTelnetDialect(d TelnetDialect) error {
+	_, err := d.compile()
+	return err
+}
+
+// GenericTelnet returns a function making Backends that speak
+// ProtocolGenericTelnet to the console d describes, for SetBackend.
+func GenericTelnet(d TelnetDialect) func() Backend {
+	return func() Backend { return &genericTelnet{config: d} }
+}
+
+// Bytes of the telnet protocol's commands, which consoles send to
+// negotiate options such as echo.
+const (
+	telnetIAC  = 255 // Starts a command
+	telnetDont = 254
+	telnetDo   = 253
+	telnetWont = 252
+	telnetWill = 251
+	telnetSB   = 250 // Starts a subnegotiation, which IAC SE ends
+	telnetSE   = 240
+)
+
+// promptReader splits what a telnet console sends on conn into lines,
+// refusing the options it offers along the way.
+type promptReader struct {
+	conn    net.Conn
+	buf     []byte
+	pending []byte // Text read but not yet returned
+	cmd     []byte // An unfinished telnet command
+}
+
+// next returns the next line the console sends, without its line ending,
+// or the text it sent after its last line once that matches one of
+// patterns, since prompts end no line.
+func (r *promptReader) next(patterns []*regexp.Regexp) (string, error) {
+	for {
+		if i := strings.IndexByte(string(r.pending), '\n'); i >= 0 {
+			line := strings.TrimRight(string(r.pending[:i]), "\r")
+			r.pending = r.pending[i+1:]
+			return line, nil
+		}
+		if text := string(r.pending); text != "" {
+			for _, re := range patterns {
+				if re.MatchString(text) {
+					r.pending = r.pending[:0]
+					return text, nil
+				}
+			}
+		}
+		if r.buf == nil {
+			r.buf = make([]byte, 4096)
+		}
+		n, err := r.conn.Read(r.buf)
+		if n > 0 {
+			if werr := r.filter(r.buf[:n]); werr != nil {
+				return "", werr
+			}
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// filter adds the text of data to what is pending, answering the telnet
+// commands in it: the console is told the client will not enable the
+// options it asks for and does not want those it offers.
+func (r *promptReader) filter(data []byte) error {
+	var replies []byte
+	for _, b := range data {
+		if len(r.cmd) == 0 {
+			if b == telnetIAC {
+				r.cmd = append(r.cmd, b)
+			} else if b != 0 {
+				r.pending = append(r.pending, b)
+			}
+			continue
+		}
+		r.cmd = append(r.cmd, b)
+		switch op := r.cmd[1]; {
+		case op == telnetIAC:
+			// An escaped 255 byte
+			r.pending = append(r.pending, b)
+		case op == telnetSB:
+			if len(r.cmd) < 4 || r.cmd[len(r.cmd)-2] != telnetIAC || b != telnetSE {
+				continue
+			}
+		case op >= telnetWill && op <= telnetDont:
+			if len(r.cmd) < 3 {
+				continue
+			}
+			reply := byte(telnetDont)
+			if op == telnetDo || op == telnetDont {
+				reply = telnetWont
+			}
+			if op == telnetDo || op == telnetWill {
+				replies = append(replies, telnetIAC, reply, b)
+			}
+		}
+		r.cmd = r.cmd[:0]
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	_, err := r.conn.Write(replies)
+	return err
+}
+
+// genericTelnet is the Backend of a connection to a telnet console
+// described by a TelnetDialect. Once logged in, its reader goroutine hands
+// the lines following a command to the exchange waiting for them, until
+// the console shows its prompt again, and the other lines to the console
+// handler. When the reader stops, the next exchange fails with its error,
+// which marks the client disconnected.
+type genericTelnet struct {
+	config  TelnetDialect
+	console func(ConsoleMessage)
+	rd      *promptReader
+	dialect *compiledTelnetDialect
+	mu      sync.Mutex
+	cmd     *genericTelnetExchange // The command in flight, nil between commands
+	done    chan struct{}          // Closed when the reader stops
+	err     error                  // Why the reader stopped, set before done is closed
+}
+
+// genericTelnetExchange is a command waiting for its output.
+type genericTelnetExchange struct {
+	command  string
+	output   []string      // The lines of output so far
+	started  bool          // A line arrived, so an echo of the command can no longer follow
+	prompted bool          // The console showed its prompt, ending the output
+	more     chan struct{} // Signalled when output or the prompt arrives
+}
+
+// Dial opens a TCP connection to the console.
+func (t *genericTelnet) Dial(ctx context.Context, address string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", address)
+}
+
+// Capabilities describes a telnet console, which prints output unprompted.
+func (t *genericTelnet) Capabilities() Capabilities {
+	return Capabilities{Network: "tcp", Console: true, Game: game.Unknown}
+}
+
+// SetConsoleHandler sets the function lines sent between commands go to.
+func (t *genericTelnet) SetConsoleHandler(handler func(ConsoleMessage)) {
+	t.console = handler
+}
+
+// Auth logs in to the telnet console on conn by answering the prompts of
+// the dialect in order, and starts reading from it. The login succeeds
+// once the console shows its prompt, or when it has no prompt, once it
+// stays quiet for telnetQuiet after the last answer. It fails if the
+// console sends a line refusing it or asks the first question again.
+func (t *genericTelnet) Auth(ctx context.Context, conn net.Conn, password string) error {
+	dialect, err := t.config.compile()
+	if err != nil {
+		return fmt.Errorf("invalid telnet dialect: %w", err)
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	rd := &promptReader{conn: conn}
+	patterns := dialect.patterns()
+	step := 0
+	for step <= len(dialect.login) {
+		line, err := rd.next(patterns)
+		var ne net.Error
+		if step == len(dialect.login) && dialect.prompt == nil && errors.As(err, &ne) && ne.Timeout() {
+			// Quiet since the last answer: logged in
+			break
+		}
+		if err != nil {
+			if isConnectionError(err) {
+				return fmt.Errorf("%w: the server closed the connection", ErrAuthFailed)
+			}
+			return fmt.Errorf("failed to log in: %w", err)
+		}
+		switch {
+		case dialect.failed != nil && dialect.failed.MatchString(line):
+			return fmt.Errorf("%w: %s", ErrAuthFailed, strings.TrimSpace(line))
+		case step < len(dialect.login) && dialect.login[step].MatchString(line):
+			if send := dialect.send[step]; send != "" {
+				answer := strings.ReplaceAll(send, PasswordPlaceholder, password) + dialect.terminator
+				if _, err := conn.Write([]byte(answer)); err != nil {
+					return fmt.Errorf("failed to answer login prompt: %w", err)
+				}
+			}
+			step++
+			if step == len(dialect.login) && dialect.prompt == nil {
+				if err := conn.SetReadDeadline(time.Now().Add(telnetQuiet)); err != nil {
+					return fmt.Errorf("failed to set deadline: %w", err)
+				}
+			}
+		case step > 0 && dialect.login[0].MatchString(line):
+			return fmt.Errorf("%w: the console asked to log in again", ErrAuthFailed)
+		case step == len(dialect.login) && dialect.prompt != nil && dialect.prompt.MatchString(line):
+			step++
+		}
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("failed to clear deadline: %w", err)
+	}
+
+	t.rd, t.dialect, t.done = rd, dialect, make(chan struct{})
+	go t.read()
+	return nil
+}
+
+// read reads the lines of the console until the connection fails. Lines
+// go to the command in flight, or to the console handler if none is and
+// they are neither blank nor the prompt.
+func (t *genericTelnet) read() {
+	var patterns []*regexp.Regexp
+	if t.dialect.prompt != nil {
+		patterns = []*regexp.Regexp{t.dialect.prompt}
+	}
+	for {
+		line, err := t.rd.next(patterns)
+		if err != nil {
+			t.err = err
+			close(t.done)
+			return
+		}
+
+		if t.dialect.prompt != nil && t.dialect.prompt.MatchString(line) {
+			t.prompt()
+			continue
+		}
+		if t.output(line) || strings.TrimSpace(line) == "" {
+			continue
+		}
+		if t.console != nil {
+			t.console(ConsoleMessage{Type: "Generic", Message: line})
+		}
+	}
+}
+
+// Execute sends command to the console and returns the lines it sends
+// back, without the echo of the command, once it shows its prompt again,
+// or without a prompt, once it has been quiet for telnetQuiet. It gives up
+// when ctx is done. An empty command, as health checks send, is an empty
+// line, whose reply is only waited for when the console shows a prompt.
+func (t *genericTelnet) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	var ex *genericTelnetExchange
+	if command != "" || t.dialect.prompt != nil {
+		ex = t.start(command)
+		defer t.finish()
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetWriteDeadline(deadline); err != nil {
+		return "", fmt.Errorf("failed to set write deadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetWriteDeadline(time.Now()) })
+	_, err := conn.Write([]byte(command + t.dialect.terminator))
+	stop()
+	_ = conn.SetWriteDeadline(time.Time{})
+	if err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+	tracePacket(ctx, "Sent telnet command", &Packet{Type: PacketTypeCommand, Body: command})
+	if ex == nil {
+		select {
+		case <-t.done:
+			return "", fmt.Errorf("failed to read response: %w", t.err)
+		default:
+			return "", nil
+		}
+	}
+
+	// Without a prompt, the reply ends once the console is quiet
+	var quiet *time.Timer
+	var quietC <-chan time.Time
+	if t.dialect.prompt == nil {
+		quiet = time.NewTimer(telnetQuiet)
+		defer quiet.Stop()
+		quietC = quiet.C
+	}
+	for !t.isPrompted(ex) {
+		select {
+		case <-ex.more:
+			if quiet != nil {
+				quiet.Reset(telnetQuiet)
+			}
+		case <-quietC:
+			return t.reply(ctx, ex), nil
+		case <-t.done:
+			return "", fmt.Errorf("failed to read response: %w", t.err)
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	return t.reply(ctx, ex), nil
+}
+
+// start makes command the command in flight.
+func (t *genericTelnet) start(command string) *genericTelnetExchange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cmd = &genericTelnetExchange{command: strings.TrimSpace(command), more: make(chan struct{}, 1)}
+	return t.cmd
+}
+
+// finish ends the command in flight; later lines are console output.
+func (t *genericTelnet) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cmd = nil
+}
+
+// output adds line to the output of the command in flight, unless it is
+// the console's echo of the command, and reports whether a command was in
+// flight to take it.
+func (t *genericTelnet) output(line string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cmd == nil || t.cmd.prompted {
+		return false
+	}
+	if !t.cmd.started && strings.TrimSpace(line) == t.cmd.command {
+		t.cmd.started = true
+		return true
+	}
+	t.cmd.started = true
+	t.cmd.output = append(t.cmd.output, line)
+	t.cmd.signal()
+	return true
+}
+
+// prompt ends the output of the command in flight.
+func (t *genericTelnet) prompt() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cmd != nil {
+		t.cmd.prompted = true
+		t.cmd.signal()
+	}
+}
+
+// isPrompted reports whether the console showed its prompt after ex.
+func (t *genericTelnet) isPrompted(ex *genericTelnetExchange) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return ex.prompted
+}
+
+// reply returns the output of ex so far as one string, without the blank
+// lines around it.
+func (t *genericTelnet) reply(ctx context.Context, ex *genericTelnetExchange) string {
+	t.mu.Lock()
+	output := strings.Trim(strings.Join(ex.output, "\n"), "\r\n")
+	t.mu.Unlock()
+	tracePacket(ctx, "Received telnet output", &Packet{Type: PacketTypeResponse, Body: output})
+	return output
+}
+
+// signal wakes the exchange waiting for e, if it is not awake already.
+func (e *genericTelnetExchange) signal() {
+	select {
+	case e.more <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,194 @@
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startGenericTelnetServer serves a telnet console on a local port that
+// offers to echo, asks for the user "admin" and the password "secret" and
+// then shows the prompt "> " before each command if prompt is set. It
+// echoes each command and answers with two lines; the command "quit"
+// closes the connection instead and "announce" sends a line after the
+// reply.
+func startGenericTelnetServer(t *testing.T, prompt bool) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveGenericTelnet(conn, prompt)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// serveGenericTelnet runs one connection of startGenericTelnetServer.
+func serveGenericTelnet(conn net.Conn, prompt bool) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	// IAC WILL ECHO, which the client must refuse with IAC DONT ECHO
+	conn.Write([]byte{telnetIAC, telnetWill, 1})
+	reply := make([]byte, 3)
+	if _, err := io.ReadFull(rd, reply); err != nil || !bytes.Equal(reply, []byte{telnetIAC, telnetDont, 1}) {
+		return
+	}
+	fmt.Fprint(conn, "Welcome to the console\r\nLogin: ")
+	user, _ := rd.ReadString('\n')
+	fmt.Fprint(conn, "Password: ")
+	password, _ := rd.ReadString('\n')
+	if strings.TrimSpace(user) != "admin" || strings.TrimSpace(password) != "secret" {
+		fmt.Fprint(conn, "Access denied\r\nLogin: ")
+		return
+	}
+	showPrompt := func() {
+		if prompt {
+			fmt.Fprint(conn, "> ")
+		}
+	}
+	showPrompt()
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		switch command {
+		case "":
+			showPrompt()
+			continue
+		case "quit":
+			return
+		}
+		fmt.Fprintf(conn, "%s\r\necho %s\r\ndone\r\n", command, command)
+		showPrompt()
+		if command == "announce" {
+			fmt.Fprint(conn, "\r\n[server] restart soon\r\n")
+			showPrompt()
+		}
+	}
+}
+
+// genericTelnetDialect is the dialect of startGenericTelnetServer.
+func genericTelnetDialect(prompt bool) TelnetDialect {
+	d := TelnetDialect{
+		Login:       []TelnetStep{{Expect: `^Login: $`, Send: "admin"}, {Expect: `^Password: $`, Send: PasswordPlaceholder}},
+		LoginFailed: `^Access denied`,
+	}
+	if prompt {
+		d.Prompt = `^> $`
+	}
+	return d
+}
+
+func TestGenericTelnet(t *testing.T) {
+	for _, prompt := range []bool{true, false} {
+		t.Run(fmt.Sprintf("prompt=%v", prompt), func(t *testing.T) {
+			address := startGenericTelnetServer(t, prompt)
+			client := NewClient()
+			client.SetProtocol(ProtocolGenericTelnet)
+			client.SetBackend(GenericTelnet(genericTelnetDialect(prompt)))
+			console := make(chan ConsoleMessage, 8)
+			client.SetConsoleHandler(func(m ConsoleMessage) { console <- m })
+			if err := client.Connect(address); err != nil {
+				t.Fatalf("Connect failed: %v", err)
+			}
+			defer client.Disconnect()
+			if err := client.Authenticate("secret"); err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+
+			commands := []string{"status", "list"}
+			if prompt {
+				// Without a prompt, lines sent soon after a reply are
+				// part of it
+				commands = []string{"status", "announce", "list"}
+			}
+			for _, command := range commands {
+				out, err := client.Execute(command)
+				if err != nil {
+					t.Fatalf("Execute(%q) failed: %v", command, err)
+				}
+				if want := "echo " + command + "\ndone"; out != want {
+					t.Errorf("Execute(%q) = %q, want %q", command, out, want)
+				}
+			}
+			if prompt {
+				select {
+				case m := <-console:
+					if m.Message != "[server] restart soon" {
+						t.Errorf("Unexpected console message: %+v", m)
+					}
+				case <-time.After(time.Second):
+					t.Error("Expected the line sent after a reply to reach the console handler")
+				}
+			}
+			if _, err := client.Execute(""); err != nil {
+				t.Errorf("Expected the health check to pass, got %v", err)
+			}
+
+			if _, err := client.Execute("quit"); err == nil {
+				if prompt {
+					t.Error("Expected a closed connection to fail the command")
+				}
+				// Without a prompt the reply can end before the server
+				// closes; the next exchange finds the connection closed
+				if _, err := client.Execute(""); err == nil {
+					t.Error("Expected a closed connection to fail the health check")
+				}
+			}
+			if client.IsConnected() {
+				t.Error("Expected the client to be disconnected after the server closed the connection")
+			}
+		})
+	}
+}
+
+func TestGenericTelnet_AuthFailed(t *testing.T) {
+	address := startGenericTelnetServer(t, true)
+	for _, d := range []TelnetDialect{
+		genericTelnetDialect(true),
+		// Without a refusal line, being asked to log in again is one
+		{Login: genericTelnetDialect(true).Login, Prompt: `^> $`},
+	} {
+		client := NewClient()
+		client.SetProtocol(ProtocolGenericTelnet)
+		client.SetBackend(GenericTelnet(d))
+		if err := client.Connect(address); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		if err := client.Authenticate("wrong"); !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("Expected ErrAuthFailed with %+v, got %v", d, err)
+		}
+		client.Disconnect()
+	}
+}
+
+func TestCheckTelnetDialect(t *testing.T) {
+	if err := CheckTelnetDialect(TelnetDialect{}); err != nil {
+		t.Errorf("Expected the default dialect to be valid, got %v", err)
+	}
+	for _, d := range []TelnetDialect{
+		{Prompt: "("},
+		{LoginFailed: "["},
+		{Login: []TelnetStep{{Expect: "user:"}, {Expect: "*"}}},
+	} {
+		if err := CheckTelnetDialect(d); err == nil {
+			t.Errorf("Expected %+v to be refused", d)
+		}
+	}
+}
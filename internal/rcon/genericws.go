@@ -0,0 +1,456 @@
+package rcon
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/websocket"
+)
+
+// Placeholders of the templates of a WebSocketDialect, besides
+// PasswordPlaceholder.
+const (
+	CommandPlaceholder = "{command}" // The command, in Request
+	IDPlaceholder      = "{id}"      // The number of the request, in Request
+)
+
+// WebSocketDialect describes the WebSocket console a client speaking
+// ProtocolGenericWebSocket logs in to and runs commands on. In templates
+// that are JSON, starting with "{" or "[", the values of placeholders are
+// escaped as in JSON strings; elsewhere they are put as they are, except
+// in URL, where they are escaped for a query. Paths name a value inside a
+// JSON message by the keys and array indexes leading to it, separated by
+// dots, such as "data.output" or "lines.0".
+type WebSocketDialect struct {
+	URL          string            // URL of the console, "ws://" or "wss://" then the host sent with the upgrade and the path; "ws://" and the address with "/" if empty
+	Insecure     bool              // Skip verifying the certificate of a wss:// console, for self-signed ones
+	Headers      map[string]string // Headers of the upgrade request, such as an Authorization carrying the password
+	AuthMessage  string            // Message sent once the WebSocket is open to log in, if the console needs one
+	AuthOK       string            // Pattern of the message accepting the login, waited for after AuthMessage if set
+	AuthFailed   string            // Pattern of the message refusing the login
+	Request      string            // Template of the message running a command, "{command}" if empty
+	IDPath       string            // Path of the request number in replies, matched against {id}; if empty, the next message with ResponsePath is the reply
+	ResponsePath string            // Path of the output in replies; if empty, the whole message is the output
+}
+
+// compiledWebSocketDialect is a WebSocketDialect with its patterns
+// compiled and its URL parsed; patterns left empty are nil.
+type compiledWebSocketDialect struct {
+	WebSocketDialect
+	url        *url.URL
+	authOK     *regexp.Regexp
+	authFailed *regexp.Regexp
+}
+
+// UsesPassword reports whether logging in to a console of dialect d
+// sends the password, in the URL, a header or the login message.
+func (d WebSocketDialect) UsesPassword() bool {
+	if strings.Contains(d.URL+d.AuthMessage, PasswordPlaceholder) {
+		return true
+	}
+	for _, value := range d.Headers {
+		if strings.Contains(value, PasswordPlaceholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// compile checks d and compiles its patterns, filling in the defaults.
+func (d WebSocketDialect) compile() (*compiledWebSocketDialect, error) {
+	cd := &compiledWebSocketDialect{WebSocketDialect: d}
+	if cd.Request == "" {
+		cd.Request = CommandPlaceholder
+	}
+	if !strings.Contains(cd.Request, CommandPlaceholder) {
+		return nil, fmt.Errorf("request %q has no %s placeholder", cd.Request, CommandPlaceholder)
+	}
+	if cd.URL == "" {
+		cd.URL = "ws:///"
+	}
+	// The password is only known when logging in
+	u, err := url.Parse(strings.ReplaceAll(cd.URL, PasswordPlaceholder, ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil, fmt.Errorf("url %q must start with ws:// or wss://", d.URL)
+	}
+	cd.url = u
+	if d.AuthOK != "" {
+		if cd.authOK, err = regexp.Compile(d.AuthOK); err != nil {
+			return nil, fmt.Errorf("auth_ok: %w", err)
+		}
+	}
+	if d.AuthFailed != "" {
+		if cd.authFailed, err = regexp.Compile(d.AuthFailed); err != nil {
+			return nil, fmt.Errorf("auth_failed: %w", err)
+		}
+	}
+	return cd, nil
+}
+
+// CheckWebSocketDialect reports whether d is complete and its patterns
+// compile.
+func CheckWebSocketDialect(d WebSocketDialect) error {
+	_, err := d.compile()
+	return err
+}
+
+// GenericWebSocket returns a function making Backends that speak
+// ProtocolGenericWebSocket to the console d describes, for SetBackend.
+func GenericWebSocket(d WebSocketDialect) func() Backend {
+	return func() Backend { return &genericWebSocket{config: d} }
+}
+
+// fillTemplate returns template with each placeholder replaced by its
+// value, escaped as in a JSON string if template is JSON.
+func fillTemplate(template string, values ...string) string {
+	trimmed := strings.TrimSpace(template)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return replacePlaceholders(template, func(v string) string {
+			quoted, _ := json.Marshal(v)
+			return string(quoted[1 : len(quoted)-1])
+		}, values...)
+	}
+	return replacePlaceholders(template, func(v string) string { return v }, values...)
+}
+
+// fillURL returns the URL template with each placeholder replaced by its
+// value, escaped for a query.
+func fillURL(template string, values ...string) string {
+	return replacePlaceholders(template, url.QueryEscape, values...)
+}
+
+// replacePlaceholders returns template with each placeholder of values,
+// which alternate placeholders and their values, replaced by its value as
+// escape escapes it.
+func replacePlaceholders(template string, escape func(string) string, values ...string) string {
+	pairs := make([]string, 0, len(values))
+	for i := 0; i+1 < len(values); i += 2 {
+		pairs = append(pairs, values[i], escape(values[i+1]))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// jsonPath returns the value at path in the JSON message data: a string
+// as it is and anything else as JSON. It reports false if data is not
+// JSON or has no such value.
+func jsonPath(data []byte, path string) (string, bool) {
+	var v any
+	if json.Unmarshal(data, &v) != nil {
+		return "", false
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = node[key]; !ok {
+				return "", false
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", false
+			}
+			v = node[i]
+		default:
+			return "", false
+		}
+	}
+	if s, ok := v.(string); ok {
+		return s, true
+	}
+	out, _ := json.Marshal(v)
+	return string(out), true
+}
+
+// genericWebSocket is the Backend of a connection to a WebSocket console
+// described by a WebSocketDialect. Once logged in, its reader goroutine
+// hands replies to the exchange waiting for them and the other messages to
+// the console handler. When the reader stops, the next exchange fails with
+// its error, which marks the client disconnected.
+type genericWebSocket struct {
+	config  WebSocketDialect
+	console func(ConsoleMessage)
+	address string // Address dialed, the Host of the upgrade if the URL names none
+	dialect *compiledWebSocketDialect
+	ws      *websocket.Conn
+	id      int32 // Number of the last request
+	mu      sync.Mutex
+	pending *genericWebSocketExchange // The command in flight, nil between commands
+	pongs   chan struct{}             // Pongs answering health-check pings
+	done    chan struct{}             // Closed when the reader stops
+	err     error                     // Why the reader stopped, set before done is closed
+}
+
+// genericWebSocketExchange is a command waiting for its reply.
+type genericWebSocketExchange struct {
+	id    string
+	reply chan string
+}
+
+// Dial opens a connection to the console, over TLS for a wss:// URL.
+func (w *genericWebSocket) Dial(ctx context.Context, address string) (net.Conn, error) {
+	dialect, err := w.config.compile()
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebSocket dialect: %w", err)
+	}
+	w.dialect, w.address = dialect, address
+	return dialConsole(ctx, address, dialect.url.Scheme == "wss", dialect.url.Hostname(), dialect.Insecure)
+}
+
+// Capabilities describes a WebSocket console, which sends messages
+// unprompted.
+func (w *genericWebSocket) Capabilities() Capabilities {
+	return Capabilities{Network: "tcp", Console: true, Game: game.Unknown}
+}
+
+// SetConsoleHandler sets the function messages other than replies go to.
+func (w *genericWebSocket) SetConsoleHandler(handler func(ConsoleMessage)) {
+	w.console = handler
+}
+
+// Auth opens a WebSocket on conn as the dialect describes, sends its login
+// message and waits for the console to accept it, then starts reading
+// from it.
+func (w *genericWebSocket) Auth(ctx context.Context, conn net.Conn, password string) error {
+	dialect := w.dialect
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	host := dialect.url.Host
+	if host == "" {
+		host = w.address
+	}
+	target := dialect.url.RequestURI()
+	if strings.Contains(dialect.URL, PasswordPlaceholder) {
+		u, err := url.Parse(fillURL(dialect.URL, PasswordPlaceholder, password))
+		if err != nil {
+			return fmt.Errorf("invalid url: %w", err)
+		}
+		target = u.RequestURI()
+	}
+	header := make(http.Header)
+	for name, value := range dialect.Headers {
+		header.Set(name, strings.ReplaceAll(value, PasswordPlaceholder, password))
+	}
+	ws, err := websocket.Handshake(conn, host, target, header)
+	var refused *websocket.HandshakeError
+	switch {
+	case errors.As(err, &refused) && (refused.StatusCode == http.StatusUnauthorized || refused.StatusCode == http.StatusForbidden):
+		return fmt.Errorf("%w: the server refused the WebSocket: %s", ErrAuthFailed, refused.Status)
+	case isConnectionError(err):
+		return fmt.Errorf("%w: the server closed the connection", ErrAuthFailed)
+	case err != nil:
+		return fmt.Errorf("failed to open the WebSocket: %w", err)
+	}
+
+	if dialect.AuthMessage != "" {
+		msg := fillTemplate(dialect.AuthMessage, PasswordPlaceholder, password)
+		if err := ws.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+			return fmt.Errorf("failed to send the login message: %w", err)
+		}
+		if err := awaitWebSocketLogin(conn, ws, dialect); err != nil {
+			return err
+		}
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("failed to clear deadline: %w", err)
+	}
+
+	w.ws, w.pongs, w.done = ws, make(chan struct{}, 1), make(chan struct{})
+	go w.read()
+	return nil
+}
+
+// awaitWebSocketLogin reads the messages answering the login message
+// until one matches the dialect's AuthOK or AuthFailed pattern. Without
+// an AuthOK pattern, the login is taken as accepted unless refused by the
+// first message, which is waited for no longer than telnetQuiet.
+func awaitWebSocketLogin(conn net.Conn, ws *websocket.Conn, dialect *compiledWebSocketDialect) error {
+	if dialect.authOK == nil {
+		if dialect.authFailed == nil {
+			return nil
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(telnetQuiet)); err != nil {
+			return fmt.Errorf("failed to set deadline: %w", err)
+		}
+	}
+	for {
+		t, data, err := ws.ReadMessage()
+		var ne net.Error
+		switch {
+		case err != nil && dialect.authOK == nil && errors.As(err, &ne) && ne.Timeout():
+			return nil
+		case err != nil && isConnectionError(err):
+			return fmt.Errorf("%w: the server closed the connection", ErrAuthFailed)
+		case err != nil:
+			return fmt.Errorf("failed to read the login reply: %w", err)
+		case t == websocket.PongMessage:
+			continue
+		case dialect.authFailed != nil && dialect.authFailed.Match(data):
+			return fmt.Errorf("%w: %s", ErrAuthFailed, strings.TrimSpace(string(data)))
+		case dialect.authOK == nil || dialect.authOK.Match(data):
+			return nil
+		}
+	}
+}
+
+// dialConsole opens a TCP connection to the console at address, over TLS
+// if secure, verifying its certificate for host, or the host of address
+// if empty, unless insecure is set.
+func dialConsole(ctx context.Context, address string, secure bool, host string, insecure bool) (net.Conn, error) {
+	if !secure {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", address)
+	}
+	if host == "" {
+		host, _, _ = net.SplitHostPort(address)
+	}
+	d := &tls.Dialer{Config: &tls.Config{ServerName: host, InsecureSkipVerify: insecure}}
+	return d.DialContext(ctx, "tcp", address)
+}
+
+// read reads the messages of the console until the connection fails.
+// Replies go to the command in flight and the other messages to the
+// console handler, as the value at the dialect's ResponsePath if they
+// have one.
+func (w *genericWebSocket) read() {
+	for {
+		t, data, err := w.ws.ReadMessage()
+		if err != nil {
+			w.err = err
+			close(w.done)
+			return
+		}
+
+		if t == websocket.PongMessage {
+			select {
+			case w.pongs <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		if w.deliver(data) {
+			continue
+		}
+		msg := string(data)
+		if path := w.dialect.ResponsePath; path != "" {
+			if out, ok := jsonPath(data, path); ok {
+				msg = out
+			}
+		}
+		if w.console != nil && strings.TrimSpace(msg) != "" {
+			w.console(ConsoleMessage{Type: "Generic", Message: msg})
+		}
+	}
+}
+
+// Execute sends command to the console in the dialect's request template,
+// numbered after the last one, and waits for its reply or for ctx to be
+// done. An empty command, as health checks send, is a WebSocket ping
+// answered by a pong instead.
+func (w *genericWebSocket) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	w.id++
+	id := strconv.Itoa(int(w.id))
+	var reply chan string
+	var pongs chan struct{}
+	if command == "" {
+		// Drop a pong left over from an earlier ping that timed out
+		select {
+		case <-w.pongs:
+		default:
+		}
+		pongs = w.pongs
+	} else {
+		reply = w.expect(id)
+		defer w.forget()
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetWriteDeadline(deadline); err != nil {
+		return "", fmt.Errorf("failed to set write deadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetWriteDeadline(time.Now()) })
+	var err error
+	if command == "" {
+		err = w.ws.Ping(nil)
+	} else {
+		msg := fillTemplate(w.dialect.Request, CommandPlaceholder, command, IDPlaceholder, id)
+		err = w.ws.WriteMessage(websocket.TextMessage, []byte(msg))
+	}
+	stop()
+	// The reader answers the server's pings without a deadline of its own
+	_ = conn.SetWriteDeadline(time.Time{})
+	if err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+	tracePacket(ctx, "Sent WebSocket message", &Packet{ID: w.id, Type: PacketTypeCommand, Body: command})
+
+	select {
+	case out := <-reply:
+		tracePacket(ctx, "Received WebSocket message", &Packet{ID: w.id, Type: PacketTypeResponse, Body: out})
+		return out, nil
+	case <-pongs:
+		return "", nil
+	case <-w.done:
+		return "", fmt.Errorf("failed to read response: %w", w.err)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// expect makes the command numbered id the one in flight.
+func (w *genericWebSocket) expect(id string) chan string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = &genericWebSocketExchange{id: id, reply: make(chan string, 1)}
+	return w.pending.reply
+}
+
+// forget ends the command in flight; later messages are console output.
+func (w *genericWebSocket) forget() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = nil
+}
+
+// deliver hands the output of data to the command in flight if data is its
+// reply, and reports whether it was.
+func (w *genericWebSocket) deliver(data []byte) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.pending == nil {
+		return false
+	}
+	if path := w.dialect.IDPath; path != "" {
+		if id, ok := jsonPath(data, path); !ok || id != w.pending.id {
+			return false
+		}
+	}
+	out := string(data)
+	if path := w.dialect.ResponsePath; path != "" {
+		var ok bool
+		if out, ok = jsonPath(data, path); !ok {
+			return false
+		}
+	}
+	w.pending.reply <- out
+	w.pending = nil
+	return true
+}
@@ -0,0 +1,188 @@
+package rcon
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/websocket"
+)
+
+// startGenericWebSocketServer serves a panel-style WebSocket console on a
+// local port at /console, which takes the token "secret" as the query
+// parameter "token" or from an {"event": "auth", "token": ...} message,
+// as auth says. Commands are {"event": "cmd", "id": N, "cmd": ...}
+// messages, answered with {"event": "result", "id": N, "output": ...}
+// after a {"event": "log"} message; "quit" closes the connection instead.
+func startGenericWebSocketServer(t *testing.T, auth string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/console" || (auth == "query" && r.URL.Query().Get("token") != "secret") {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ws, err := websocket.Accept(w, r)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			_, data, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg struct {
+				Event string `json:"event"`
+				ID    int    `json:"id"`
+				Cmd   string `json:"cmd"`
+				Token string `json:"token"`
+			}
+			if json.Unmarshal(data, &msg) != nil {
+				continue
+			}
+			send := func(v any) {
+				out, _ := json.Marshal(v)
+				ws.WriteMessage(websocket.TextMessage, out)
+			}
+			switch {
+			case msg.Event == "auth" && msg.Token == "secret":
+				send(map[string]any{"event": "auth", "ok": true})
+			case msg.Event == "auth":
+				send(map[string]any{"event": "auth", "ok": false})
+			case msg.Event == "cmd" && msg.Cmd == "quit":
+				return
+			case msg.Event == "cmd":
+				send(map[string]any{"event": "log", "output": "player joined"})
+				send(map[string]any{"event": "result", "id": msg.ID, "output": "echo " + msg.Cmd})
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+// genericWebSocketDialect is the dialect of startGenericWebSocketServer
+// for auth.
+func genericWebSocketDialect(auth string) WebSocketDialect {
+	d := WebSocketDialect{
+		URL:          "ws://panel.example.com/console",
+		Request:      `{"event": "cmd", "id": {id}, "cmd": "{command}"}`,
+		IDPath:       "id",
+		ResponsePath: "output",
+	}
+	if auth == "query" {
+		d.URL += "?token={password}"
+	} else {
+		d.AuthMessage = `{"event": "auth", "token": "{password}"}`
+		d.AuthOK = `"ok":true`
+		d.AuthFailed = `"ok":false`
+	}
+	return d
+}
+
+func TestGenericWebSocket(t *testing.T) {
+	for _, auth := range []string{"query", "message"} {
+		t.Run(auth, func(t *testing.T) {
+			address := startGenericWebSocketServer(t, auth)
+			client := NewClient()
+			client.SetProtocol(ProtocolGenericWebSocket)
+			client.SetBackend(GenericWebSocket(genericWebSocketDialect(auth)))
+			console := make(chan ConsoleMessage, 8)
+			client.SetConsoleHandler(func(m ConsoleMessage) { console <- m })
+			if err := client.Connect(address); err != nil {
+				t.Fatalf("Connect failed: %v", err)
+			}
+			defer client.Disconnect()
+			if err := client.Authenticate("secret"); err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+
+			for _, command := range []string{"status", `say "hi"`} {
+				out, err := client.Execute(command)
+				if err != nil {
+					t.Fatalf("Execute(%q) failed: %v", command, err)
+				}
+				if want := "echo " + command; out != want {
+					t.Errorf("Execute(%q) = %q, want %q", command, out, want)
+				}
+			}
+			select {
+			case m := <-console:
+				if m.Message != "player joined" {
+					t.Errorf("Unexpected console message: %+v", m)
+				}
+			case <-time.After(time.Second):
+				t.Error("Expected the log message to reach the console handler")
+			}
+			if _, err := client.Execute(""); err != nil {
+				t.Errorf("Expected the health check to pass, got %v", err)
+			}
+
+			if _, err := client.Execute("quit"); err == nil {
+				t.Error("Expected a closed connection to fail the command")
+			}
+			if client.IsConnected() {
+				t.Error("Expected the client to be disconnected after the server closed the connection")
+			}
+		})
+	}
+}
+
+func TestGenericWebSocket_AuthFailed(t *testing.T) {
+	for _, auth := range []string{"query", "message"} {
+		address := startGenericWebSocketServer(t, auth)
+		client := NewClient()
+		client.SetProtocol(ProtocolGenericWebSocket)
+		client.SetBackend(GenericWebSocket(genericWebSocketDialect(auth)))
+		if err := client.Connect(address); err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		if err := client.Authenticate("wrong"); !errors.Is(err, ErrAuthFailed) {
+			t.Errorf("Expected ErrAuthFailed with auth by %s, got %v", auth, err)
+		}
+		client.Disconnect()
+	}
+}
+
+func TestCheckWebSocketDialect(t *testing.T) {
+	if err := CheckWebSocketDialect(WebSocketDialect{}); err != nil {
+		t.Errorf("Expected the default dialect to be valid, got %v", err)
+	}
+	for _, d := range []WebSocketDialect{
+		{URL: "http://example.com/console"},
+		{Request: `{"cmd": "say"}`},
+		{AuthOK: "("},
+		{AuthFailed: "["},
+	} {
+		if err := CheckWebSocketDialect(d); err == nil {
+			t.Errorf("Expected %+v to be refused", d)
+		}
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	data := []byte(`{"data": {"lines": ["a", "b"], "count": 2, "ok": true}}`)
+	tests := []struct {
+		path string
+		want string
+		ok   bool
+	}{
+		{"data.lines.1", "b", true},
+		{"data.count", "2", true},
+		{"data.lines", `["a","b"]`, true},
+		{"data.missing", "", false},
+		{"data.lines.5", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := jsonPath(data, tt.path); got != tt.want || ok != tt.ok {
+			t.Errorf("jsonPath(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := jsonPath([]byte("not json"), "a"); ok {
+		t.Error("Expected a message that is not JSON to have no values")
+	}
+}
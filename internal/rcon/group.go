@@ -0,0 +1,131 @@
+package rcon
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Group is a named collection of session IDs that can be targeted together.
+type Group struct {
+	Name    string   // Unique group name
+	Members []string // Session IDs in the group, sorted
+}
+
+// GroupManager provides thread-safe management of session groups.
+// Groups only hold session IDs; they do not own or keep sessions alive.
+type GroupManager struct {
+	groups map[string]map[string]struct{} // Map of group name to member set
+	mu     sync.RWMutex                   // Read-write mutex for thread-safe access
+}
+
+// NewGroupManager creates a new instance of GroupManager with no groups.
+func NewGroupManager() *GroupManager {
+	return &GroupManager{
+		groups: make(map[string]map[string]struct{}),
+	}
+}
+
+// CreateGroup creates a new group with the given initial members.
+// Returns an error if a group with the same name already exists.
+func (gm *GroupManager) CreateGroup(name string, members []string) error {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if name == "" {
+		return fmt.Errorf("group name is required")
+	}
+	if _, exists := gm.groups[name]; exists {
+		return fmt.Errorf("group %s already exists", name)
+	}
+
+	set := make(map[string]struct{}, len(members))
+	for _, id := range members {
+		set[id] = struct{}{}
+	}
+	gm.groups[name] = set
+	return nil
+}
+
+// AddMembers adds session IDs to an existing group.
+// Adding a session that is already a member has no effect.
+// Returns an error if the group doesn't exist.
+func (gm *GroupManager) AddMembers(name string, members []string) error {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	set, exists := gm.groups[name]
+	if !exists {
+		return fmt.Errorf("group %s not found", name)
+	}
+	for _, id := range members {
+		set[id] = struct{}{}
+	}
+	return nil
+}
+
+// RemoveMembers removes session IDs from an existing group.
+// Removing a session that is not a member has no effect.
+// Returns an error if the group doesn't exist.
+func (gm *GroupManager) RemoveMembers(name string, members []string) error {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	set, exists := gm.groups[name]
+	if !exists {
+		return fmt.Errorf("group %s not found", name)
+	}
+	for _, id := range members {
+		delete(set, id)
+	}
+	return nil
+}
+
+// DeleteGroup removes a group. The member sessions are not affected.
+// Returns an error if the group doesn't exist.
+func (gm *GroupManager) DeleteGroup(name string) error {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	if _, exists := gm.groups[name]; !exists {
+		return fmt.Errorf("group %s not found", name)
+	}
+	delete(gm.groups, name)
+	return nil
+}
+
+// GetGroup returns a snapshot of a single group.
+// Returns an error if the group doesn't exist.
+func (gm *GroupManager) GetGroup(name string) (*Group, error) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	set, exists := gm.groups[name]
+	if !exists {
+		return nil, fmt.Errorf("group %s not found", name)
+	}
+	return &Group{Name: name, Members: sortedMembers(set)}, nil
+}
+
+// ListGroups returns snapshots of all groups, sorted by name.
+func (gm *GroupManager) ListGroups() []*Group {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+
+	groups := make([]*Group, 0, len(gm.groups))
+	for name, set := range gm.groups {
+		groups = append(groups, &Group{Name: name, Members: sortedMembers(set)})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// sortedMembers returns the members of a set as a sorted slice.
+func sortedMembers(set map[string]struct{}) []string {
+	members := make([]string, 0, len(set))
+	for id := range set {
+		members = append(members, id)
+	}
+	sort.Strings(members)
+	return members
+}
@@ -0,0 +1,114 @@
+package rcon
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGroupManager_CreateGroup(t *testing.T) {
+	tests := []struct {
+		name        string
+		group       string
+		members     []string
+		setup       func(*GroupManager)
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:    "new group",
+			group:   "lobby",
+			members: []string{"lobby-2", "lobby-1", "lobby-1"},
+		},
+		{
+			name:  "duplicate group",
+			group: "lobby",
+			setup: func(gm *GroupManager) {
+				gm.CreateGroup("lobby", nil)
+			},
+			wantErr:     true,
+			errContains: "already exists",
+		},
+		{
+			name:        "empty name",
+			group:       "",
+			wantErr:     true,
+			errContains: "required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := NewGroupManager()
+			if tt.setup != nil {
+				tt.setup(gm)
+			}
+
+			err := gm.CreateGroup(tt.group, tt.members)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			group, err := gm.GetGroup(tt.group)
+			if err != nil {
+				t.Fatalf("GetGroup failed: %v", err)
+			}
+			if want := []string{"lobby-1", "lobby-2"}; !reflect.DeepEqual(group.Members, want) {
+				t.Errorf("Expected members %v, got %v", want, group.Members)
+			}
+		})
+	}
+}
+
+func TestGroupManager_Membership(t *testing.T) {
+	gm := NewGroupManager()
+	if err := gm.CreateGroup("lobby", []string{"a"}); err != nil {
+		t.Fatalf("CreateGroup failed: %v", err)
+	}
+
+	if err := gm.AddMembers("lobby", []string{"b", "c"}); err != nil {
+		t.Fatalf("AddMembers failed: %v", err)
+	}
+	if err := gm.RemoveMembers("lobby", []string{"a", "missing"}); err != nil {
+		t.Fatalf("RemoveMembers failed: %v", err)
+	}
+
+	group, _ := gm.GetGroup("lobby")
+	if want := []string{"b", "c"}; !reflect.DeepEqual(group.Members, want) {
+		t.Errorf("Expected members %v, got %v", want, group.Members)
+	}
+
+	if err := gm.AddMembers("missing", []string{"a"}); err == nil {
+		t.Error("Expected error adding to a missing group")
+	}
+	if err := gm.RemoveMembers("missing", []string{"a"}); err == nil {
+		t.Error("Expected error removing from a missing group")
+	}
+}
+
+func TestGroupManager_DeleteAndList(t *testing.T) {
+	gm := NewGroupManager()
+	gm.CreateGroup("zeta", []string{"z"})
+	gm.CreateGroup("alpha", []string{"a"})
+
+	groups := gm.ListGroups()
+	if len(groups) != 2 || groups[0].Name != "alpha" || groups[1].Name != "zeta" {
+		t.Errorf("Expected groups sorted by name, got %+v", groups)
+	}
+
+	if err := gm.DeleteGroup("zeta"); err != nil {
+		t.Fatalf("DeleteGroup failed: %v", err)
+	}
+	if err := gm.DeleteGroup("zeta"); err == nil {
+		t.Error("Expected error deleting a missing group")
+	}
+	if _, err := gm.GetGroup("zeta"); err == nil {
+		t.Error("Expected deleted group to be gone")
+	}
+}
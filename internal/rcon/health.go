@@ -0,0 +1,184 @@
+package rcon
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/timerwheel"
+)
+
+// healthCheckCommand is sent to idle sessions to verify that their
+// connection is still alive. An empty command is accepted, and answered
+// with an empty or "unknown command" response, by every supported server.
+const healthCheckCommand = ""
+
+// healthCheckWorkers is the most health checks that run at once. A server
+// that is slow to answer the probe holds up only one of them.
+const healthCheckWorkers = 8
+
+// keepAliveTimers schedules the keepalives of clients that were given no
+// wheel with SetTimers, such as those of the command line, on a wheel of
+// their own started on first use.
+var keepAliveTimers = sync.OnceValue(func() *timerwheel.Wheel {
+	timers := timerwheel.New(time.Second, 1)
+	go timers.Run(context.Background())
+	return timers
+})
+
+// SetTimers makes the client schedule its keepalives on timers, a wheel
+// shared with other clients, such as the one a SessionManager checks its
+// sessions' health with. Connections authenticated from now on use it.
+func (c *Client) SetTimers(timers *timerwheel.Wheel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timers = timers
+}
+
+// timerWheel returns the wheel set with SetTimers.
+func (c *Client) timerWheel() *timerwheel.Wheel {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.timers
+}
+
+// scheduleKeepAlive sends an empty command over conn every interval, for
+// as long as it stays the client's authenticated connection, so that
+// servers that drop silent clients, as BattlEye's do, keep it. Its reply
+// is dropped. Must be called with c.mu held.
+func (c *Client) scheduleKeepAlive(conn net.Conn, interval time.Duration) {
+	timers := c.timers
+	if timers == nil {
+		timers = keepAliveTimers()
+	}
+	c.stopKeepAlive()
+	c.keepAlive = timers.AfterFunc(interval, func() {
+		c.mu.Lock()
+		current := c.conn == conn && c.isAuthorized
+		c.mu.Unlock()
+		if !current {
+			return
+		}
+		_, _ = c.Execute(healthCheckCommand)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.conn == conn && c.keepAlive != nil {
+			c.keepAlive.Reset(interval)
+		}
+	})
+}
+
+// stopKeepAlive cancels the keepalive of the client's connection, if one
+// is scheduled. Must be called with c.mu held.
+func (c *Client) stopKeepAlive() {
+	if c.keepAlive != nil {
+		c.keepAlive.Stop()
+		c.keepAlive = nil
+	}
+}
+
+// StartHealthCheck keeps watch over every session until ctx is canceled.
+// An authenticated session whose server has sent nothing for interval is
+// probed; a probe that finds a dead connection marks the client as
+// disconnected and fires the drop handler, exactly as a failed command
+// would. A session that has run no command for its idle timeout is closed,
+// removed and reported to the expire handler.
+//
+// Each session's next check is a timer on a wheel shared by all sessions,
+// so hundreds of them cost no more goroutines than one, and sessions busy
+// with commands are not probed at all. The keepalives of the sessions'
+// connections, and of their pools, are scheduled on the same wheel.
+func (sm *SessionManager) StartHealthCheck(ctx context.Context, interval time.Duration) {
+	timers := timerwheel.New(min(interval/4, time.Second), healthCheckWorkers)
+
+	sm.mu.Lock()
+	sm.timers, sm.heartbeat = timers, interval
+	for _, session := range sm.sessions {
+		session.Client.SetTimers(timers)
+		sm.schedule(session, interval)
+	}
+	sm.mu.Unlock()
+
+	go timers.Run(ctx)
+}
+
+// schedule sets the next check of session to run after d.
+// Must be called with sm.mu held.
+func (sm *SessionManager) schedule(session *Session, d time.Duration) {
+	if session.timer == nil {
+		session.timer = sm.timers.AfterFunc(d, func() { sm.checkSession(session) })
+		return
+	}
+	session.timer.Reset(d)
+}
+
+// stopTimer cancels the next check of the session, if one is scheduled.
+// Must be called with the manager's mutex held.
+func (s *Session) stopTimer() {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
+
+// checkSession expires session if it has been idle for its idle timeout,
+// and otherwise probes it, along with the extra connections of its pool,
+// if its server has been quiet for the heartbeat interval. It then
+// schedules the session's next check for when either is next due.
+func (sm *SessionManager) checkSession(session *Session) {
+	sm.mu.RLock()
+	current := sm.sessions[session.ID] == session
+	unwell, expired, interval := sm.onUnwell, sm.onExpire, sm.heartbeat
+	sm.mu.RUnlock()
+	if !current {
+		return
+	}
+
+	timeout := session.IdleTimeout()
+	if idle := session.idleFor(); timeout > 0 && idle >= timeout {
+		if sm.expire(session) && expired != nil {
+			expired(session, idle)
+		}
+		return
+	}
+
+	if session.Client.IsAuthenticated() && session.Client.quietFor() >= interval {
+		// Dead connections are reported through the drop handler; other
+		// failures such as timeouts leave the session in place for the next
+		// round and are reported to the health check handler.
+		_, err := session.Client.Execute(healthCheckCommand)
+		if err != nil && session.Client.IsConnected() && unwell != nil {
+			unwell(session, err)
+		}
+	}
+	session.checkPool(interval)
+
+	next := interval - session.Client.quietFor()
+	if next <= 0 {
+		next = interval
+	}
+	if timeout > 0 {
+		next = min(next, timeout-session.idleFor())
+	}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.sessions[session.ID] == session {
+		sm.schedule(session, next)
+	}
+}
+
+// expire closes and removes session for being idle, unless it has been
+// removed already, and reports whether it did.
+func (sm *SessionManager) expire(session *Session) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.sessions[session.ID] != session {
+		return false
+	}
+	session.closed.Store(true)
+	session.closePool()
+	_ = session.Client.Disconnect()
+	delete(sm.sessions, session.ID)
+	return true
+}
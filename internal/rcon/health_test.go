@@ -0,0 +1,180 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcontest"
+	"github.com/mjmorales/rcon-mcp-server/internal/timerwheel"
+)
+
+func TestSessionManager_StartHealthCheck(t *testing.T) {
+	sm := NewSessionManager()
+
+	dropped := make(chan string, 2)
+	sm.SetDropHandler(func(session *Session, err error) {
+		dropped <- session.ID
+	})
+
+	// A live session whose server answers the probe
+	healthy, _ := sm.CreateSession("healthy", "Healthy", "localhost:25575")
+	healthyConn := newMockConn()
+	for id := int32(1); id <= 100; id++ {
+		writePacketToBuffer(healthyConn.readBuf, &Packet{ID: id, Type: PacketTypeResponse})
+	}
+	healthy.Client.conn = healthyConn
+	healthy.Client.wire = &sourceRCON{}
+	healthy.Client.isConnected = true
+	healthy.Client.isAuthorized = true
+
+	// A session whose server has gone away: reads hit EOF
+	dead, _ := sm.CreateSession("dead", "Dead", "localhost:25576")
+	dead.Client.conn = newMockConn()
+	dead.Client.wire = &sourceRCON{}
+	dead.Client.isConnected = true
+	dead.Client.isAuthorized = true
+
+	// An unauthenticated session is never probed
+	idle, _ := sm.CreateSession("idle", "Idle", "localhost:25577")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sm.StartHealthCheck(ctx, 10*time.Millisecond)
+
+	select {
+	case id := <-dropped:
+		if id != "dead" {
+			t.Errorf("Expected dead session to be reported, got %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Health check did not report the dead session")
+	}
+
+	if dead.Client.IsConnected() {
+		t.Error("Expected dead session to be marked disconnected")
+	}
+	if !healthy.Client.IsConnected() {
+		t.Error("Expected healthy session to stay connected")
+	}
+	if idle.Client.IsConnected() {
+		t.Error("Expected idle session to remain untouched")
+	}
+}
+
+func TestSessionManager_HealthCheckHandler(t *testing.T) {
+	sm := NewSessionManager()
+
+	failed := make(chan error, 10)
+	sm.SetHealthCheckHandler(func(session *Session, err error) {
+		failed <- err
+	})
+	sm.SetDropHandler(func(session *Session, err error) {
+		t.Errorf("Expected a slow session not to be dropped, got %v", err)
+	})
+
+	// A session whose server never answers in time
+	slow, _ := sm.CreateSession("slow", "Slow", "localhost:25575")
+	conn := newMockConn()
+	conn.readErr = os.ErrDeadlineExceeded
+	slow.Client.conn = conn
+	slow.Client.wire = &sourceRCON{}
+	slow.Client.isConnected = true
+	slow.Client.isAuthorized = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sm.StartHealthCheck(ctx, 10*time.Millisecond)
+
+	select {
+	case err := <-failed:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("Expected a timeout, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Health check did not report the slow session")
+	}
+	if !slow.Client.IsConnected() {
+		t.Error("Expected slow session to stay connected")
+	}
+}
+
+func TestSessionManager_IdleExpiry(t *testing.T) {
+	sm := NewSessionManager()
+
+	expired := make(chan string, 2)
+	sm.SetExpireHandler(func(session *Session, idle time.Duration) {
+		if idle < 30*time.Millisecond {
+			t.Errorf("Expected session %s to be idle for its timeout, got %v", session.ID, idle)
+		}
+		expired <- session.ID
+	})
+
+	idle, _ := sm.CreateSession("idle", "Idle", "localhost:25575")
+	idle.SetIdleTimeout(30 * time.Millisecond)
+	kept, _ := sm.CreateSession("kept", "Kept", "localhost:25576")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sm.StartHealthCheck(ctx, 10*time.Millisecond)
+
+	// Sessions created after the health checker started are watched too
+	late, _ := sm.CreateSession("late", "Late", "localhost:25577")
+	late.SetIdleTimeout(30 * time.Millisecond)
+
+	got := map[string]bool{}
+	for range 2 {
+		select {
+		case id := <-expired:
+			got[id] = true
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the idle sessions to expire, got %v", got)
+		}
+	}
+	if !got["idle"] || !got["late"] {
+		t.Errorf("Expected sessions idle and late to expire, got %v", got)
+	}
+	if _, err := sm.GetSession("idle"); err == nil {
+		t.Error("Expected the expired session to be removed")
+	}
+	if _, err := sm.GetSession(kept.ID); err != nil {
+		t.Errorf("Expected the session without an idle timeout to stay, got %v", err)
+	}
+}
+
+func TestClient_KeepAlive(t *testing.T) {
+	server := rcontest.NewServer(t, "secret", rcontest.Respond(func(string) string { return "" }))
+	client := NewClient()
+	timers := timerwheel.New(5*time.Millisecond, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go timers.Run(ctx)
+	client.SetTimers(timers)
+	if err := client.Connect(server.Addr()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	client.mu.Lock()
+	client.scheduleKeepAlive(client.conn, 10*time.Millisecond)
+	client.mu.Unlock()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(server.Commands()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if commands := server.Commands(); len(commands) < 2 || commands[0] != healthCheckCommand {
+		t.Fatalf("Expected repeated empty keepalive commands, got %q", commands)
+	}
+
+	client.Disconnect()
+	client.mu.Lock()
+	scheduled := client.keepAlive != nil
+	client.mu.Unlock()
+	if scheduled {
+		t.Error("Expected Disconnect to cancel the keepalive")
+	}
+}
@@ -0,0 +1,110 @@
+package rcon
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is the number of commands kept per session.
+const DefaultHistorySize = 500
+
+// HistoryEntry records one command executed on a session.
+type HistoryEntry struct {
+	Time          time.Time `json:"time"`                 // When the command was sent
+	Command       string    `json:"command"`              // Command as sent to the server
+	Output        string    `json:"output,omitempty"`     // Response from the server
+	Error         string    `json:"error,omitempty"`      // Error message if the command failed
+	LatencyMillis int64     `json:"latency_ms"`           // Round-trip time of the command
+	RequestID     string    `json:"request_id,omitempty"` // Tool call that sent the command
+}
+
+// Failed reports whether the command returned an error.
+func (e HistoryEntry) Failed() bool {
+	return e.Error != ""
+}
+
+// HistoryFilter selects entries from a History. Zero-valued fields match
+// every entry.
+type HistoryFilter struct {
+	Contains   string         // Case-insensitive substring of the command
+	Pattern    *regexp.Regexp // Regular expression matched against the command
+	Since      time.Time      // Only entries at or after this time
+	Until      time.Time      // Only entries at or before this time
+	ErrorsOnly bool           // Only entries whose command failed
+	Limit      int            // Maximum number of entries returned, 0 for all
+}
+
+// Match reports whether an entry passes the filter, ignoring Limit.
+func (f HistoryFilter) Match(e HistoryEntry) bool {
+	if f.Contains != "" && !strings.Contains(strings.ToLower(e.Command), strings.ToLower(f.Contains)) {
+		return false
+	}
+	if f.Pattern != nil && !f.Pattern.MatchString(e.Command) {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	if f.ErrorsOnly && !e.Failed() {
+		return false
+	}
+	return true
+}
+
+// History is a bounded, thread-safe log of the commands run on a session.
+// Once full, the oldest entries are discarded.
+type History struct {
+	mu      sync.Mutex     // Protects entries
+	entries []HistoryEntry // Oldest first
+	size    int            // Maximum number of entries kept
+}
+
+// NewHistory creates a history that keeps at most size entries.
+// A size of zero or less uses DefaultHistorySize.
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &History{size: size}
+}
+
+// Add appends an entry, discarding the oldest one if the history is full.
+func (h *History) Add(entry HistoryEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.entries) >= h.size {
+		copy(h.entries, h.entries[1:])
+		h.entries = h.entries[:len(h.entries)-1]
+	}
+	h.entries = append(h.entries, entry)
+}
+
+// Len returns the number of entries currently stored.
+func (h *History) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.entries)
+}
+
+// Search returns the entries matching the filter, newest first.
+func (h *History) Search(filter HistoryFilter) []HistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	matches := []HistoryEntry{}
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if filter.Limit > 0 && len(matches) >= filter.Limit {
+			break
+		}
+		if filter.Match(h.entries[i]) {
+			matches = append(matches, h.entries[i])
+		}
+	}
+	return matches
+}
@@ -0,0 +1,91 @@
+package rcon
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestHistory_Add(t *testing.T) {
+	h := NewHistory(2)
+	h.Add(HistoryEntry{Command: "one"})
+	h.Add(HistoryEntry{Command: "two"})
+	h.Add(HistoryEntry{Command: "three"})
+
+	if h.Len() != 2 {
+		t.Fatalf("Expected 2 entries, got %d", h.Len())
+	}
+
+	var got []string
+	for _, e := range h.Search(HistoryFilter{}) {
+		got = append(got, e.Command)
+	}
+	if want := []string{"three", "two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestNewHistory_DefaultSize(t *testing.T) {
+	if h := NewHistory(0); h.size != DefaultHistorySize {
+		t.Errorf("Expected size %d, got %d", DefaultHistorySize, h.size)
+	}
+}
+
+func TestHistory_Search(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	h := NewHistory(10)
+	h.Add(HistoryEntry{Time: base, Command: "ban Griefer"})
+	h.Add(HistoryEntry{Time: base.Add(time.Hour), Command: "say hello"})
+	h.Add(HistoryEntry{Time: base.Add(2 * time.Hour), Command: "BAN Spammer", Error: "timeout"})
+	h.Add(HistoryEntry{Time: base.Add(3 * time.Hour), Command: "banlist"})
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []string
+	}{
+		{
+			name:   "no filter returns newest first",
+			filter: HistoryFilter{},
+			want:   []string{"banlist", "BAN Spammer", "say hello", "ban Griefer"},
+		},
+		{
+			name:   "substring is case-insensitive",
+			filter: HistoryFilter{Contains: "ban "},
+			want:   []string{"BAN Spammer", "ban Griefer"},
+		},
+		{
+			name:   "regex",
+			filter: HistoryFilter{Pattern: regexp.MustCompile(`^ban\s`)},
+			want:   []string{"ban Griefer"},
+		},
+		{
+			name:   "time range",
+			filter: HistoryFilter{Since: base.Add(time.Hour), Until: base.Add(2 * time.Hour)},
+			want:   []string{"BAN Spammer", "say hello"},
+		},
+		{
+			name:   "errors only",
+			filter: HistoryFilter{ErrorsOnly: true},
+			want:   []string{"BAN Spammer"},
+		},
+		{
+			name:   "limit",
+			filter: HistoryFilter{Contains: "ban", Limit: 2},
+			want:   []string{"banlist", "BAN Spammer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, e := range h.Search(tt.filter) {
+				got = append(got, e.Command)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
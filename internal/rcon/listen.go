@@ -0,0 +1,132 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// listenBacklog is how many packets a listener holds for the exchange in
+// flight; replies beyond it, which no exchange waits for, are dropped.
+const listenBacklog = 64
+
+// listener is the state of an RCON connection read by its own goroutine,
+// for servers that send packets unprompted. It hands the packets of
+// PacketTypeChat to the client's console handler and the others to the
+// exchange in flight, which matches them by ID as it would have read them.
+type listener struct {
+	packets chan *Packet  // Packets other than chat, oldest first
+	done    chan struct{} // Closed when the reader stops
+	err     error         // Why the reader stopped, set before done is closed
+}
+
+// SetListening sets whether the RCON connections the client opens from
+// now on are read by a listener, which keeps the chat packets servers such
+// as Squad's push unprompted apart from the replies to commands and hands
+// them to the console handler, even while no command runs. The current
+// connection keeps its mode.
+func (c *Client) SetListening(on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listening = on
+}
+
+// isListening reports whether SetListening turned listening on.
+func (c *Client) isListening() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.listening
+}
+
+// listen starts reading conn with a listener, once authenticated.
+func (r *sourceRCON) listen(conn net.Conn) {
+	r.lst = &listener{packets: make(chan *Packet, listenBacklog), done: make(chan struct{})}
+	rd, limit := r.reader(conn)
+	go r.readListened(rd, limit, r.lst)
+}
+
+// readListened reads the packets of the connection through rd, accepting
+// them up to limit, until it fails, then reports the connection dying to
+// the drop handler.
+func (r *sourceRCON) readListened(rd *bufio.Reader, limit int32, lst *listener) {
+	for {
+		p, err := ReadPacket(rd, limit)
+		if err != nil {
+			lst.err = err
+			close(lst.done)
+			if r.drop != nil {
+				r.drop(err)
+			}
+			return
+		}
+		if p.Type == PacketTypeChat {
+			if r.console != nil {
+				r.console(ConsoleMessage{Type: "Chat", Message: p.Body})
+			}
+			continue
+		}
+		select {
+		case lst.packets <- p:
+		default:
+			// Nobody waits for this many replies
+		}
+	}
+}
+
+// executeListened sends cmdPacket over an RCON connection read by a
+// listener and waits for its reply, skipping late replies to earlier
+// cancelled commands as Execute does. Cancelling ctx only cuts the send
+// short: the listener keeps reading.
+func (r *sourceRCON) executeListened(ctx context.Context, conn net.Conn, cmdPacket *Packet) (string, error) {
+	lst := r.lst
+	lst.drain()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetWriteDeadline(deadline); err != nil {
+		return "", fmt.Errorf("failed to set write deadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetWriteDeadline(time.Now()) })
+	err := r.sendPacket(conn, cmdPacket)
+	stop()
+	if err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+	tracePacket(ctx, "Sent RCON packet", cmdPacket)
+
+	for range maxStaleReads {
+		var response *Packet
+		select {
+		case response = <-lst.packets:
+		case <-lst.done:
+			return "", fmt.Errorf("failed to read response: %w", lst.err)
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+
+		tracePacket(ctx, "Received RCON packet", response)
+		if response.ID > 0 && response.ID < cmdPacket.ID {
+			continue
+		}
+		if response.ID != cmdPacket.ID {
+			return "", errors.New("response ID mismatch")
+		}
+		return response.Body, nil
+	}
+
+	return "", errors.New("response ID mismatch")
+}
+
+// drain drops the packets left from earlier exchanges, late replies to
+// commands that were cancelled or timed out.
+func (l *listener) drain() {
+	for {
+		select {
+		case <-l.packets:
+		default:
+			return
+		}
+	}
+}
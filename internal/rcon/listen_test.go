@@ -0,0 +1,153 @@
+package rcon
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startChattyServer serves RCON on a local port for the password "secret",
+// pushing a chat packet after the auth response and before each reply, as
+// Squad servers do when players talk. The command "slow" is answered after
+// 200ms; every other command is echoed.
+func startChattyServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveChatty(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// serveChatty runs one connection of startChattyServer.
+func serveChatty(conn net.Conn) {
+	defer conn.Close()
+	for {
+		header := make([]byte, 12)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		size := binary.LittleEndian.Uint32(header)
+		body := make([]byte, size-8)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return
+		}
+		p := &Packet{ID: int32(binary.LittleEndian.Uint32(header[4:8])), Type: PacketType(binary.LittleEndian.Uint32(header[8:12])), Body: string(body[:len(body)-2])}
+		chat := &Packet{Type: PacketTypeChat, Body: "[ChatAll] [SteamID:76561197960290418] Bob : hi"}
+
+		var out []byte
+		switch {
+		case p.Type == PacketTypeAuth && p.Body != "secret":
+			out = appendPacket(out, &Packet{ID: -1, Type: PacketTypeAuthResponse})
+		case p.Type == PacketTypeAuth:
+			out = appendPacket(out, &Packet{ID: p.ID, Type: PacketTypeAuthResponse})
+			out = appendPacket(out, chat)
+		case p.Body == "slow":
+			go func() {
+				time.Sleep(200 * time.Millisecond)
+				_, _ = conn.Write(appendPacket(nil, &Packet{ID: p.ID, Type: PacketTypeResponse, Body: "late"}))
+			}()
+			continue
+		default:
+			out = appendPacket(out, chat)
+			out = appendPacket(out, &Packet{ID: p.ID, Type: PacketTypeResponse, Body: "echo " + p.Body})
+		}
+		if _, err := conn.Write(out); err != nil {
+			return
+		}
+	}
+}
+
+func TestClient_Listening(t *testing.T) {
+	address := startChattyServer(t)
+	client := NewClient()
+	client.SetListening(true)
+	var mu sync.Mutex
+	var chat []ConsoleMessage
+	client.SetConsoleHandler(func(m ConsoleMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		chat = append(chat, m)
+	})
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Disconnect()
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	for _, command := range []string{"ListPlayers", "ShowServerInfo"} {
+		out, err := client.Execute(command)
+		if err != nil {
+			t.Fatalf("Execute(%q) failed: %v", command, err)
+		}
+		if want := "echo " + command; out != want {
+			t.Errorf("Execute(%q) = %q, want %q", command, out, want)
+		}
+	}
+
+	// A cancelled command leaves the connection usable, and its late reply
+	// is skipped by the next command
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.ExecuteContext(ctx, "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the slow command to time out, got %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if out, err := client.Execute("ListSquads"); err != nil || out != "echo ListSquads" {
+		t.Errorf("Execute after a cancelled command = %q, %v", out, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(chat) != 4 {
+		t.Fatalf("Expected 4 chat messages, got %d: %v", len(chat), chat)
+	}
+	if chat[0].Type != "Chat" || chat[0].Message != "[ChatAll] [SteamID:76561197960290418] Bob : hi" {
+		t.Errorf("Unexpected chat message %+v", chat[0])
+	}
+}
+
+func TestClient_ListeningDropped(t *testing.T) {
+	address := startChattyServer(t)
+	client := NewClient()
+	client.SetListening(true)
+	dropped := make(chan error, 1)
+	client.SetDisconnectHandler(func(err error) { dropped <- err })
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	// Closing the socket under the listener is noticed without a command
+	client.mu.Lock()
+	conn := client.conn
+	client.mu.Unlock()
+	conn.Close()
+	select {
+	case <-dropped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the drop to be reported")
+	}
+	if _, err := client.Execute("ListPlayers"); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Expected ErrNotConnected, got %v", err)
+	}
+}
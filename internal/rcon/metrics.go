@@ -0,0 +1,180 @@
+package rcon
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencySamples is the number of recent latencies kept per session for
+// percentile calculations.
+const latencySamples = 1000
+
+// Metrics aggregates command and connection statistics across sessions.
+// Statistics are kept per session ID, so they survive a session being
+// disconnected and reconnected under the same ID.
+type Metrics struct {
+	mu       sync.Mutex                 // Protects the fields below
+	started  time.Time                  // When collection started
+	sessions map[string]*sessionMetrics // Statistics keyed by session ID
+}
+
+// sessionMetrics holds the raw statistics for one session ID.
+type sessionMetrics struct {
+	commands  int             // Commands executed
+	errors    int             // Commands that failed or were rejected
+	connects  int             // Successful connections
+	drops     int             // Connections lost unexpectedly
+	latencies []time.Duration // Ring buffer of recent command latencies
+	next      int             // Next write position in latencies
+}
+
+// MetricsSnapshot is a point-in-time view of the collected metrics.
+type MetricsSnapshot struct {
+	UptimeSeconds int64            `json:"uptime_seconds"` // Time since collection started
+	TotalCommands int              `json:"total_commands"` // Commands executed across all sessions
+	TotalErrors   int              `json:"total_errors"`   // Failed commands across all sessions
+	ErrorRate     float64          `json:"error_rate"`     // TotalErrors / TotalCommands, 0 when idle
+	Reconnects    int              `json:"reconnects"`     // Reconnects across all sessions
+	Sessions      []SessionMetrics `json:"sessions"`       // Per-session statistics, sorted by ID
+}
+
+// SessionMetrics is the statistics for one session ID.
+type SessionMetrics struct {
+	SessionID    string  `json:"session_id"`
+	Commands     int     `json:"commands"`       // Commands executed
+	Errors       int     `json:"errors"`         // Commands that failed or were rejected
+	ErrorRate    float64 `json:"error_rate"`     // Errors / Commands, 0 when idle
+	LatencyP50Ms int64   `json:"latency_p50_ms"` // Median latency of recent commands
+	LatencyP90Ms int64   `json:"latency_p90_ms"` // 90th percentile latency of recent commands
+	LatencyP99Ms int64   `json:"latency_p99_ms"` // 99th percentile latency of recent commands
+	Connects     int     `json:"connects"`       // Successful connections
+	Reconnects   int     `json:"reconnects"`     // Connections after the first one
+	Drops        int     `json:"drops"`          // Connections lost unexpectedly
+}
+
+// NewMetrics creates an empty metrics collector whose uptime starts now.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		started:  time.Now(),
+		sessions: make(map[string]*sessionMetrics),
+	}
+}
+
+// session returns the statistics for a session ID, creating them if needed.
+// Must be called with m.mu held.
+func (m *Metrics) session(id string) *sessionMetrics {
+	s, ok := m.sessions[id]
+	if !ok {
+		s = &sessionMetrics{}
+		m.sessions[id] = s
+	}
+	return s
+}
+
+// RecordCommand records one command executed on a session.
+func (m *Metrics) RecordCommand(sessionID string, latency time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.session(sessionID)
+	s.commands++
+	if failed {
+		s.errors++
+	}
+	if len(s.latencies) < latencySamples {
+		s.latencies = append(s.latencies, latency)
+	} else {
+		s.latencies[s.next] = latency
+	}
+	s.next = (s.next + 1) % latencySamples
+}
+
+// RecordConnect records a successful connection for a session.
+func (m *Metrics) RecordConnect(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.session(sessionID).connects++
+}
+
+// RecordDrop records an unexpected connection loss for a session.
+func (m *Metrics) RecordDrop(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.session(sessionID).drops++
+}
+
+// Snapshot returns the current metrics.
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := MetricsSnapshot{
+		UptimeSeconds: int64(time.Since(m.started).Seconds()),
+		Sessions:      make([]SessionMetrics, 0, len(m.sessions)),
+	}
+	for id, s := range m.sessions {
+		sorted := slices.Clone(s.latencies)
+		slices.Sort(sorted)
+
+		sm := SessionMetrics{
+			SessionID:    id,
+			Commands:     s.commands,
+			Errors:       s.errors,
+			ErrorRate:    ratio(s.errors, s.commands),
+			LatencyP50Ms: Percentile(sorted, 50).Milliseconds(),
+			LatencyP90Ms: Percentile(sorted, 90).Milliseconds(),
+			LatencyP99Ms: Percentile(sorted, 99).Milliseconds(),
+			Connects:     s.connects,
+			Reconnects:   max(s.connects-1, 0),
+			Drops:        s.drops,
+		}
+		snapshot.Sessions = append(snapshot.Sessions, sm)
+		snapshot.TotalCommands += sm.Commands
+		snapshot.TotalErrors += sm.Errors
+		snapshot.Reconnects += sm.Reconnects
+	}
+	snapshot.ErrorRate = ratio(snapshot.TotalErrors, snapshot.TotalCommands)
+
+	sort.Slice(snapshot.Sessions, func(i, j int) bool {
+		return snapshot.Sessions[i].SessionID < snapshot.Sessions[j].SessionID
+	})
+	return snapshot
+}
+
+// Only returns the snapshot restricted to the sessions keep accepts the
+// IDs of, with the totals counted over those sessions alone.
+func (s MetricsSnapshot) Only(keep func(sessionID string) bool) MetricsSnapshot {
+	only := MetricsSnapshot{UptimeSeconds: s.UptimeSeconds, Sessions: make([]SessionMetrics, 0, len(s.Sessions))}
+	for _, sm := range s.Sessions {
+		if !keep(sm.SessionID) {
+			continue
+		}
+		only.Sessions = append(only.Sessions, sm)
+		only.TotalCommands += sm.Commands
+		only.TotalErrors += sm.Errors
+		only.Reconnects += sm.Reconnects
+	}
+	only.ErrorRate = ratio(only.TotalErrors, only.TotalCommands)
+	return only
+}
+
+// Percentile returns the nearest-rank percentile p of sorted durations,
+// or zero if there are none.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// ratio returns n/d, or zero when d is zero.
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
@@ -0,0 +1,92 @@
+package rcon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetrics_Snapshot(t *testing.T) {
+	m := NewMetrics()
+	for i := 1; i <= 100; i++ {
+		m.RecordCommand("alpha", time.Duration(i)*time.Millisecond, i%10 == 0)
+	}
+	m.RecordCommand("beta", 5*time.Millisecond, false)
+	m.RecordConnect("alpha")
+	m.RecordConnect("alpha")
+	m.RecordConnect("alpha")
+	m.RecordDrop("alpha")
+	m.RecordConnect("beta")
+
+	s := m.Snapshot()
+	if s.TotalCommands != 101 || s.TotalErrors != 10 {
+		t.Errorf("Expected 101 commands and 10 errors, got %d and %d", s.TotalCommands, s.TotalErrors)
+	}
+	if s.Reconnects != 2 {
+		t.Errorf("Expected 2 reconnects, got %d", s.Reconnects)
+	}
+	if len(s.Sessions) != 2 || s.Sessions[0].SessionID != "alpha" {
+		t.Fatalf("Expected sessions sorted by ID, got %+v", s.Sessions)
+	}
+
+	alpha := s.Sessions[0]
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"error rate", alpha.ErrorRate, 0.1},
+		{"p50", alpha.LatencyP50Ms, int64(50)},
+		{"p90", alpha.LatencyP90Ms, int64(90)},
+		{"p99", alpha.LatencyP99Ms, int64(99)},
+		{"connects", alpha.Connects, 3},
+		{"reconnects", alpha.Reconnects, 2},
+		{"drops", alpha.Drops, 1},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, tt.got)
+		}
+	}
+}
+
+func TestMetricsSnapshot_Only(t *testing.T) {
+	m := NewMetrics()
+	m.RecordCommand("alpha", time.Millisecond, true)
+	m.RecordCommand("alpha", time.Millisecond, false)
+	m.RecordCommand("beta", time.Millisecond, false)
+	m.RecordConnect("beta")
+	m.RecordConnect("beta")
+
+	s := m.Snapshot().Only(func(id string) bool { return id == "alpha" })
+	if len(s.Sessions) != 1 || s.Sessions[0].SessionID != "alpha" {
+		t.Fatalf("Expected only alpha, got %+v", s.Sessions)
+	}
+	if s.TotalCommands != 2 || s.TotalErrors != 1 || s.ErrorRate != 0.5 || s.Reconnects != 0 {
+		t.Errorf("Expected totals over alpha alone, got %+v", s)
+	}
+}
+
+func TestMetrics_LatencyWindow(t *testing.T) {
+	m := NewMetrics()
+	for range latencySamples {
+		m.RecordCommand("slow", time.Second, false)
+	}
+	for range latencySamples {
+		m.RecordCommand("slow", time.Millisecond, false)
+	}
+
+	s := m.Snapshot().Sessions[0]
+	if s.LatencyP99Ms != 1 {
+		t.Errorf("Expected old samples to be evicted, got p99 %dms", s.LatencyP99Ms)
+	}
+	if s.Commands != 2*latencySamples {
+		t.Errorf("Expected %d commands, got %d", 2*latencySamples, s.Commands)
+	}
+}
+
+func TestMetrics_Empty(t *testing.T) {
+	s := NewMetrics().Snapshot()
+	if s.TotalCommands != 0 || s.ErrorRate != 0 || len(s.Sessions) != 0 {
+		t.Errorf("Expected empty snapshot, got %+v", s)
+	}
+}
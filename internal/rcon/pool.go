@@ -0,0 +1,226 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// pool holds the connections of a session that opened more than one, so
+// that commands from parallel callers run on separate sockets instead of
+// queueing behind each other on the session's Client.
+type pool struct {
+	idle     chan *Client // Connections not running a command, the session's Client included
+	extra    []*Client    // Connections opened besides the session's Client
+	address  string       // Server the extra connections are reopened to
+	password string       // Password the extra connections authenticate with
+}
+
+// OpenPool opens size-1 more connections to the session's server,
+// authenticated with password, which Execute spreads commands over along
+// with the session's Client. Connections that fail to open are left out
+// and the first failure is returned; the session still uses the ones that
+// did open. A size below 2 keeps the session on its Client alone.
+//
+// The session's state, health checks and drop notifications are those of
+// its Client; the extra connections only carry commands.
+func (s *Session) OpenPool(size int, password string) error {
+	if size < 2 {
+		return nil
+	}
+	p := &pool{idle: make(chan *Client, size), address: s.Address, password: password}
+	p.idle <- s.Client
+	var firstErr error
+	for range size - 1 {
+		c := NewClient()
+		c.SetReadOptions(s.Client.readOptions())
+		c.SetProtocol(s.Client.Protocol())
+		c.SetBackend(s.Client.backendFactory())
+		c.SetTimers(s.Client.timerWheel())
+		// Servers push chat to every connection; only the session's
+		// Client hands it to the console handler
+		c.SetListening(s.Client.isListening())
+		if err := p.open(c); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		p.extra = append(p.extra, c)
+		p.idle <- c
+	}
+	if len(p.extra) == 0 {
+		return firstErr
+	}
+
+	s.mu.Lock()
+	old := s.pool
+	s.pool = p
+	s.mu.Unlock()
+	if old != nil {
+		old.close()
+	}
+	return firstErr
+}
+
+// Connections returns the number of connections the session runs commands
+// on: its Client and the extra connections of its pool.
+func (s *Session) Connections() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.pool == nil {
+		return 1
+	}
+	return 1 + len(s.pool.extra)
+}
+
+// Execute runs command on one of the session's connections, with the
+// cancellation semantics of Client.ExecuteContext. Without a pool that is
+// the session's Client. With one it is the first connection of the pool to
+// be idle, waiting until one is or ctx is cancelled; an extra connection
+// that dropped is reopened first, and if that fails the command runs on
+// the session's Client instead.
+//
+// On a session set to reconnect automatically, a command that fails
+// because its connection dropped, as when the server restarts, is retried
+// once after the session's Client is connected and authenticated again.
+// The first attempt may have run before the connection died, so the
+// command can run twice. Execute returns the number of attempts it made,
+// 2 when the command was retried.
+func (s *Session) Execute(ctx context.Context, command string) (string, int, error) {
+	out, err := s.execute(ctx, command)
+	if err == nil || !s.retryable(ctx, err) {
+		return out, 1, err
+	}
+	if rerr := s.reconnect(); rerr != nil {
+		return "", 1, fmt.Errorf("%w (reconnecting failed: %w)", err, rerr)
+	}
+	out, err = s.execute(ctx, command)
+	return out, 2, err
+}
+
+// retryable reports whether a command that failed with err should be
+// retried on a new connection: the session reconnects automatically, is
+// still managed, ctx is live and the connection was lost.
+func (s *Session) retryable(ctx context.Context, err error) bool {
+	if !s.AutoReconnect() || s.closed.Load() || ctx.Err() != nil {
+		return false
+	}
+	return isConnectionError(err) || errors.Is(err, ErrNotConnected)
+}
+
+// reconnect connects and authenticates the session's Client again unless
+// it already is, as when the failed command ran on a pooled connection or
+// another caller reconnected first. Pooled connections that dropped are
+// reopened by Execute when next used.
+func (s *Session) reconnect() error {
+	s.reconnectMu.Lock()
+	defer s.reconnectMu.Unlock()
+	if s.Client.IsAuthenticated() {
+		return nil
+	}
+	s.mu.RLock()
+	password := s.reconnectPassword
+	s.mu.RUnlock()
+
+	_ = s.Client.Disconnect()
+	if err := s.Client.Connect(s.Address); err != nil {
+		return err
+	}
+	if err := s.Client.Authenticate(password); err != nil {
+		_ = s.Client.Disconnect()
+		return err
+	}
+	if s.closed.Load() {
+		// Removed from its manager while reconnecting
+		_ = s.Client.Disconnect()
+		return ErrNotConnected
+	}
+	if s.onReconnect != nil {
+		s.onReconnect()
+	}
+	return nil
+}
+
+// execute runs command once, as described for Execute.
+func (s *Session) execute(ctx context.Context, command string) (string, error) {
+	s.mu.RLock()
+	p := s.pool
+	s.mu.RUnlock()
+	if p == nil {
+		defer s.used()
+		return s.Client.ExecuteContext(ctx, command)
+	}
+
+	var c *Client
+	select {
+	case c = <-p.idle:
+	case <-ctx.Done():
+		return "", fmt.Errorf("command cancelled: %w", ctx.Err())
+	}
+	defer func() { p.idle <- c }()
+	defer s.used()
+
+	if c != s.Client && !c.IsAuthenticated() {
+		_ = c.Disconnect()
+		if err := p.open(c); err != nil {
+			return s.Client.ExecuteContext(ctx, command)
+		}
+	}
+	return c.ExecuteContext(ctx, command)
+}
+
+// used records that a command ran on the session, which keeps it from
+// expiring for being idle.
+func (s *Session) used() {
+	s.lastUsed.Store(time.Now().UnixNano())
+}
+
+// checkPool probes the extra connections of the session's pool that have
+// been quiet for interval, so that connections the server closed while
+// idle are found, and reopened, before a command is sent on them.
+func (s *Session) checkPool(interval time.Duration) {
+	s.mu.RLock()
+	p := s.pool
+	s.mu.RUnlock()
+	if p == nil {
+		return
+	}
+	for _, c := range p.extra {
+		if c.IsAuthenticated() && c.quietFor() >= interval {
+			_, _ = c.Execute(healthCheckCommand)
+		}
+	}
+}
+
+// closePool disconnects the extra connections of the session's pool, if it
+// has one, leaving the session on its Client alone.
+func (s *Session) closePool() {
+	s.mu.Lock()
+	p := s.pool
+	s.pool = nil
+	s.mu.Unlock()
+	if p != nil {
+		p.close()
+	}
+}
+
+// open connects and authenticates c to the pool's server.
+func (p *pool) open(c *Client) error {
+	if err := c.Connect(p.address); err != nil {
+		return err
+	}
+	if err := c.Authenticate(p.password); err != nil {
+		_ = c.Disconnect()
+		return err
+	}
+	return nil
+}
+
+// close disconnects the pool's extra connections.
+func (p *pool) close() {
+	for _, c := range p.extra {
+		_ = c.Disconnect()
+	}
+}
@@ -0,0 +1,178 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcontest"
+)
+
+// startSlowServer starts an RCON server on localhost that accepts any
+// password and answers every command after delay, serving each connection
+// on its own.
+func startSlowServer(t *testing.T, delay time.Duration) *rcontest.Server {
+	t.Helper()
+	return rcontest.NewServer(t, "", func(string) rcontest.Reply {
+		return rcontest.Reply{Body: "ok", Delay: delay}
+	})
+}
+
+// openSession creates a session of sm connected and authenticated to
+// address.
+func openSession(t *testing.T, sm *SessionManager, id, address string) *Session {
+	t.Helper()
+	session, err := sm.CreateSession(id, "", address)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if err := session.Client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if err := session.Client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	return session
+}
+
+func TestSession_Pool(t *testing.T) {
+	const delay = 200 * time.Millisecond
+	server := startSlowServer(t, delay)
+	address := server.Addr()
+	sm := NewSessionManager()
+	session := openSession(t, sm, "pooled", address)
+
+	if err := session.OpenPool(3, "secret"); err != nil {
+		t.Fatalf("OpenPool failed: %v", err)
+	}
+	if n := session.Connections(); n != 3 || server.Accepted() != 3 {
+		t.Fatalf("Expected 3 connections, got %d with %d accepted", n, server.Accepted())
+	}
+
+	// Three commands at once each get a connection of their own.
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if out, _, err := session.Execute(context.Background(), "list"); err != nil || out != "ok" {
+				t.Errorf("Execute = %q, %v", out, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed >= 2*delay {
+		t.Errorf("Expected the commands to run in parallel, took %v", elapsed)
+	}
+
+	// A pooled connection that dropped is reopened when next used.
+	session.mu.RLock()
+	extra := session.pool.extra[0]
+	session.mu.RUnlock()
+	_ = extra.Disconnect()
+	for range 3 {
+		if _, _, err := session.Execute(context.Background(), "list"); err != nil {
+			t.Fatalf("Execute after a pooled connection dropped failed: %v", err)
+		}
+	}
+	if !extra.IsAuthenticated() || server.Accepted() != 4 {
+		t.Errorf("Expected the dropped connection to be reopened, got %d accepted", server.Accepted())
+	}
+
+	// A command waiting for a connection gives up when cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), delay/4)
+	defer cancel()
+	for range 3 {
+		go func() { _, _, _ = session.Execute(context.Background(), "list") }()
+	}
+	time.Sleep(delay / 8)
+	if _, _, err := session.Execute(ctx, "list"); err == nil {
+		t.Error("Expected a cancelled command to fail while every connection is busy")
+	}
+
+	if err := sm.RemoveSession("pooled"); err != nil {
+		t.Fatalf("RemoveSession failed: %v", err)
+	}
+	if extra.IsConnected() || session.Connections() != 1 {
+		t.Error("Expected removing the session to close its pool")
+	}
+}
+
+func TestSession_PoolUnavailable(t *testing.T) {
+	address := startSlowServer(t, 0).Addr()
+	session := openSession(t, NewSessionManager(), "single", address)
+	if err := session.OpenPool(1, "secret"); err != nil || session.Connections() != 1 {
+		t.Errorf("Expected a pool of 1 to keep the session's client alone, got %d, %v", session.Connections(), err)
+	}
+
+	session.Address = "127.0.0.1:1"
+	if err := session.OpenPool(3, "secret"); err == nil || session.Connections() != 1 {
+		t.Errorf("Expected an error and no pool when no connection opens, got %d, %v", session.Connections(), err)
+	}
+	if out, _, err := session.Execute(context.Background(), "list"); err != nil || out != "ok" {
+		t.Errorf("Expected commands to run on the session's client, got %q, %v", out, err)
+	}
+}
+
+func TestSession_AutoReconnect(t *testing.T) {
+	server := startSlowServer(t, 0)
+	address := server.Addr()
+	sm := NewSessionManager()
+	reconnected := make(chan *Session, 1)
+	sm.SetReconnectHandler(func(s *Session) { reconnected <- s })
+	session := openSession(t, sm, "restarting", address)
+
+	// breakConn closes the session's connection under it, as a server
+	// restart would.
+	breakConn := func() {
+		session.Client.mu.Lock()
+		_ = session.Client.conn.Close()
+		session.Client.mu.Unlock()
+	}
+
+	breakConn()
+	if _, attempts, err := session.Execute(context.Background(), "list"); err == nil || attempts != 1 {
+		t.Fatalf("Expected a command on a dropped connection to fail at its only attempt without auto-reconnect, got %d, %v", attempts, err)
+	}
+
+	// With auto-reconnect, both a connection already found dead and one
+	// that dies under the command are opened again for a retry.
+	session.SetAutoReconnect("secret")
+	for i := range 2 {
+		if i > 0 {
+			breakConn()
+		}
+		out, attempts, err := session.Execute(context.Background(), "list")
+		if err != nil || out != "ok" {
+			t.Fatalf("Expected the command to be retried on a new connection, got %q, %v", out, err)
+		}
+		if attempts != 2 {
+			t.Errorf("Expected 2 attempts, got %d", attempts)
+		}
+		if n := server.Accepted(); n != i+2 {
+			t.Errorf("Expected %d connections, got %d", i+2, n)
+		}
+		select {
+		case s := <-reconnected:
+			if s != session {
+				t.Errorf("Expected the reconnect handler to get the session, got %v", s.ID)
+			}
+		default:
+			t.Error("Expected the reconnect handler to be called")
+		}
+	}
+
+	// A removed session is not reconnected.
+	if err := sm.RemoveSession("restarting"); err != nil {
+		t.Fatalf("RemoveSession failed: %v", err)
+	}
+	if _, _, err := session.Execute(context.Background(), "list"); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Expected ErrNotConnected on a removed session, got %v", err)
+	}
+	if server.Accepted() != 3 {
+		t.Errorf("Expected no reconnect after the session was removed, got %d connections", server.Accepted())
+	}
+}
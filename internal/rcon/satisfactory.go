@@ -0,0 +1,283 @@
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+// satisfactoryPath is the endpoint of the Satisfactory dedicated server
+// API, which every function is posted to.
+const satisfactoryPath = "/api/v1"
+
+// Functions of the Satisfactory API used by the client.
+const (
+	satisfactoryHealthCheck = "HealthCheck"               // Needs no token, answered with the server's health
+	satisfactoryVerifyToken = "VerifyAuthenticationToken" // Answered with 204 for a valid token
+	satisfactoryLogin       = "PasswordLogin"             // Trades the admin password for a token
+	satisfactoryRunCommand  = "RunCommand"                // Runs a command as the server console
+)
+
+// satisfactoryCall is a request of the Satisfactory API: the function
+// to call and its data. Commands sent as a JSON object of this shape are
+// posted as they are instead of through RunCommand.
+type satisfactoryCall struct {
+	Function string          `json:"function"`
+	Data     json.RawMessage `json:"data,omitempty"`
+}
+
+// satisfactoryCommand is the data of a RunCommand request.
+type satisfactoryCommand struct {
+	Command string `json:"Command"`
+}
+
+// satisfactoryReply is the JSON body of a Satisfactory API reply: the data
+// of a successful call, or the code and message of an error.
+type satisfactoryReply struct {
+	Data         json.RawMessage `json:"data"`
+	ErrorCode    string          `json:"errorCode"`
+	ErrorMessage string          `json:"errorMessage"`
+}
+
+// satisfactory is the Backend of a connection to a Satisfactory server's
+// API. Servers use a self-signed certificate unless given one, so it is
+// not verified. Only the exchange holding the client's queue slot uses it.
+type satisfactory struct {
+	address string // Address dialed, which requests are posted to
+	token   string
+	conn    *httpConn
+	rd      *bufio.Reader // Reader of the connection the last request went over
+	rdOn    net.Conn
+	stale   bool // The server closed the connection after its last reply
+}
+
+// parseSatisfactoryCall reads command as a request of the Satisfactory
+// API if it is a JSON object. A RunCommand request keeps only its command,
+// so that what is sent is what policies saw.
+func parseSatisfactoryCall(command string) (*satisfactoryCall, bool, error) {
+	command = strings.TrimSpace(command)
+	if !strings.HasPrefix(command, "{") {
+		return nil, false, nil
+	}
+	var req satisfactoryCall
+	if err := json.Unmarshal([]byte(command), &req); err != nil {
+		return nil, true, fmt.Errorf("invalid API request: %w", err)
+	}
+	if req.Function == "" {
+		return nil, true, errors.New(`invalid API request: "function" is required`)
+	}
+	if strings.EqualFold(req.Function, satisfactoryRunCommand) {
+		var data satisfactoryCommand
+		if err := json.Unmarshal(req.Data, &data); err != nil {
+			return nil, true, fmt.Errorf("invalid RunCommand data: %w", err)
+		}
+		req.Function, req.Data = satisfactoryRunCommand, mustJSON(data)
+	}
+	return &req, true, nil
+}
+
+// Dial opens a TLS connection to the API.
+func (sf *satisfactory) Dial(ctx context.Context, address string) (net.Conn, error) {
+	conn, err := dialConsole(ctx, address, true, "", true)
+	if err != nil {
+		return nil, err
+	}
+	sf.address, sf.conn = address, &httpConn{conn: conn}
+	return sf.conn, nil
+}
+
+// Capabilities describes the API, which serves Satisfactory only and only
+// answers requests.
+func (sf *satisfactory) Capabilities() Capabilities {
+	return Capabilities{Network: "tcp", Game: game.Satisfactory}
+}
+
+// Auth checks password as an API token of the server, or else trades it,
+// as the admin password, for one with PasswordLogin.
+func (sf *satisfactory) Auth(ctx context.Context, conn net.Conn, password string) error {
+	sf.token = password
+	_, err := sf.request(ctx, &satisfactoryCall{Function: satisfactoryVerifyToken})
+	if errors.Is(err, ErrAuthFailed) {
+		sf.token = ""
+		login := mustJSON(map[string]string{"MinimumPrivilegeLevel": "Administrator", "Password": password})
+		var reply *satisfactoryReply
+		reply, err = sf.request(ctx, &satisfactoryCall{Function: satisfactoryLogin, Data: login})
+		if err == nil {
+			var data struct {
+				Token string `json:"authenticationToken"`
+			}
+			if json.Unmarshal(reply.Data, &data) != nil || data.Token == "" {
+				return fmt.Errorf("%w: the server sent no token", ErrAuthFailed)
+			}
+			sf.token = data.Token
+		}
+	}
+	switch {
+	case errors.Is(err, ErrAuthFailed):
+		return err
+	case err != nil && isConnectionError(err):
+		return fmt.Errorf("%w: the server closed the connection", ErrAuthFailed)
+	case err != nil:
+		return fmt.Errorf("failed to log in: %w", err)
+	}
+	return nil
+}
+
+// Execute runs command with RunCommand and returns its result, or posts
+// it as it is if it is a JSON API request and returns the data of the
+// reply as JSON. An empty command, as health checks send, calls
+// HealthCheck instead.
+func (sf *satisfactory) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	req, isAPI, err := parseSatisfactoryCall(command)
+	switch {
+	case err != nil:
+		return "", err
+	case command == "":
+		req = &satisfactoryCall{Function: satisfactoryHealthCheck, Data: mustJSON(map[string]string{"ClientCustomData": ""})}
+	case !isAPI:
+		req = &satisfactoryCall{Function: satisfactoryRunCommand, Data: mustJSON(satisfactoryCommand{Command: command})}
+	}
+
+	reply, err := sf.request(ctx, req)
+	switch {
+	case err != nil:
+		return "", err
+	case command == "":
+		return "", nil
+	case isAPI:
+		return string(reply.Data), nil
+	}
+	var result struct {
+		CommandResult string `json:"commandResult"`
+	}
+	if err := json.Unmarshal(reply.Data, &result); err != nil {
+		return "", fmt.Errorf("unexpected RunCommand reply: %w", err)
+	}
+	return result.CommandResult, nil
+}
+
+// request posts req to the API with the token, if any, and returns the
+// reply. A server that closed the connection after its last reply, or
+// while it sat idle, is dialed again once. A reply refusing the token or
+// password is ErrAuthFailed, and other errors carry the server's code and
+// message.
+func (sf *satisfactory) request(ctx context.Context, req *satisfactoryCall) (*satisfactoryReply, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	newRequest := func() *http.Request {
+		r, _ := http.NewRequest(http.MethodPost, "https://"+sf.address+satisfactoryPath, bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", "application/json")
+		if sf.token != "" {
+			r.Header.Set("Authorization", "Bearer "+sf.token)
+		}
+		return r
+	}
+
+	if sf.stale {
+		if err := sf.redial(ctx); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := sf.roundTrip(ctx, newRequest())
+	if err != nil && isConnectionError(err) && ctx.Err() == nil {
+		// The server dropped a connection kept open from an earlier request
+		if err := sf.redial(ctx); err != nil {
+			return nil, err
+		}
+		reply, err = sf.roundTrip(ctx, newRequest())
+	}
+	return reply, err
+}
+
+// roundTrip sends req and reads the reply, giving up when ctx is done.
+func (sf *satisfactory) roundTrip(ctx context.Context, req *http.Request) (*satisfactoryReply, error) {
+	conn := sf.conn.current()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	tracePacket(ctx, "Sent Satisfactory request", &Packet{Type: PacketTypeCommand, Body: req.URL.Path})
+	if sf.rdOn != conn {
+		sf.rd, sf.rdOn = bufio.NewReader(conn), conn
+	}
+	resp, err := http.ReadResponse(sf.rd, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxReadLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	sf.stale = resp.Close
+	tracePacket(ctx, "Received Satisfactory reply", &Packet{Type: PacketTypeResponse, Body: string(body)})
+
+	var reply satisfactoryReply
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &reply); err != nil {
+			return nil, fmt.Errorf("unexpected reply from the server (HTTP %s): %w", resp.Status, err)
+		}
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("%w: %s", ErrAuthFailed, reply.message(resp.Status))
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("the server refused the request: %s", reply.message(resp.Status))
+	}
+	return &reply, nil
+}
+
+// redial replaces the connection the server closed with a new TLS
+// connection to the same address. If that fails, the error shows the
+// connection closed, so that the client marks itself disconnected.
+func (sf *satisfactory) redial(ctx context.Context) error {
+	fresh, err := dialConsole(ctx, sf.address, true, "", true)
+	if err != nil {
+		return fmt.Errorf("failed to reconnect: %w: %w", net.ErrClosed, err)
+	}
+	if err := sf.conn.replace(fresh); err != nil {
+		return err
+	}
+	sf.stale = false
+	return nil
+}
+
+// message returns the error code and message of a reply, or status if it
+// has neither.
+func (r *satisfactoryReply) message(status string) string {
+	switch {
+	case r.ErrorCode != "" && r.ErrorMessage != "":
+		return r.ErrorCode + ": " + r.ErrorMessage
+	case r.ErrorCode != "":
+		return r.ErrorCode
+	case r.ErrorMessage != "":
+		return r.ErrorMessage
+	}
+	return "HTTP " + status
+}
+
+// mustJSON encodes v, which must be a value that always encodes.
+func mustJSON(v any) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
@@ -0,0 +1,145 @@
+package rcon
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// startSatisfactoryServer serves a Satisfactory API over TLS on a local
+// port, with the admin password "admin" and the API token "token".
+// RunCommand echoes its command; the command "bye" is answered with the
+// connection closed after the reply.
+func startSatisfactoryServer(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Function string         `json:"function"`
+			Data     map[string]any `json:"data"`
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		reply := func(status int, body map[string]any) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(body)
+		}
+		authorized := r.Header.Get("Authorization") == "Bearer token"
+		switch {
+		case req.Function == "HealthCheck":
+			reply(http.StatusOK, map[string]any{"data": map[string]any{"health": "healthy", "serverCustomData": ""}})
+		case req.Function == "PasswordLogin" && req.Data["Password"] == "admin":
+			reply(http.StatusOK, map[string]any{"data": map[string]any{"authenticationToken": "token"}})
+		case req.Function == "PasswordLogin":
+			reply(http.StatusUnauthorized, map[string]any{"errorCode": "wrong_password", "errorMessage": "Wrong password"})
+		case !authorized:
+			reply(http.StatusUnauthorized, map[string]any{"errorCode": "invalid_token", "errorMessage": "The provided token is invalid"})
+		case req.Function == "VerifyAuthenticationToken":
+			w.WriteHeader(http.StatusNoContent)
+		case req.Function == "RunCommand":
+			cmd, _ := req.Data["Command"].(string)
+			if cmd == "bye" {
+				w.Header().Set("Connection", "close")
+			}
+			reply(http.StatusOK, map[string]any{"data": map[string]any{"commandResult": "echo " + cmd, "returnValue": true}})
+		case req.Function == "QueryServerState":
+			reply(http.StatusOK, map[string]any{"data": map[string]any{"serverGameState": map[string]any{"numConnectedPlayers": 2}}})
+		default:
+			reply(http.StatusNotFound, map[string]any{"errorCode": "unknown_function", "errorMessage": "Unknown API function"})
+		}
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "https://"), &conns
+}
+
+// connectSatisfactory connects and authenticates a client to address with
+// password.
+func connectSatisfactory(t *testing.T, address, password string) (*Client, error) {
+	t.Helper()
+	client := NewClient()
+	client.SetProtocol(ProtocolSatisfactory)
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Disconnect() })
+	return client, client.Authenticate(password)
+}
+
+func TestSatisfactory(t *testing.T) {
+	address, conns := startSatisfactoryServer(t)
+	client, err := connectSatisfactory(t, address, "token")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	for _, tt := range []struct{ command, want string }{
+		{"SaveGame autosave", "echo SaveGame autosave"},
+		{"bye", "echo bye"},
+		{`{"function": "RunCommand", "data": {"Command": "FG.NetworkQuality 3"}}`, `{"commandResult":"echo FG.NetworkQuality 3","returnValue":true}`},
+		{`{"function": "QueryServerState"}`, `{"serverGameState":{"numConnectedPlayers":2}}`},
+	} {
+		out, err := client.Execute(tt.command)
+		if err != nil {
+			t.Fatalf("Execute(%q) failed: %v", tt.command, err)
+		}
+		if strings.TrimSpace(out) != tt.want {
+			t.Errorf("Execute(%q) = %q, want %q", tt.command, out, tt.want)
+		}
+	}
+	// The server closed the connection after bye, so the next request
+	// needed another
+	if n := conns.Load(); n != 2 {
+		t.Errorf("Expected 2 connections, got %d", n)
+	}
+	if _, err := client.Execute(healthCheckCommand); err != nil {
+		t.Errorf("Expected the health check to pass, got %v", err)
+	}
+
+	for _, command := range []string{`{"function": "Nope"}`, `{"data": {}}`, `{"function": `} {
+		if _, err := client.Execute(command); err == nil {
+			t.Errorf("Expected Execute(%q) to fail", command)
+		}
+	}
+	if !client.IsConnected() {
+		t.Error("Expected refused requests to leave the client connected")
+	}
+}
+
+func TestSatisfactory_PasswordLogin(t *testing.T) {
+	address, _ := startSatisfactoryServer(t)
+	client, err := connectSatisfactory(t, address, "admin")
+	if err != nil {
+		t.Fatalf("Authenticate with the admin password failed: %v", err)
+	}
+	if out, err := client.Execute("stat fps"); err != nil || out != "echo stat fps" {
+		t.Errorf("Expected the token from the login to be used, got %q, %v", out, err)
+	}
+
+	if _, err := connectSatisfactory(t, address, "wrong"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed for a wrong password, got %v", err)
+	}
+}
+
+func TestParseSatisfactoryCall(t *testing.T) {
+	req, isAPI, err := parseSatisfactoryCall(`{"function": "RunCommand", "data": {"Command": "quit", "Extra": 1}}`)
+	if err != nil || !isAPI || req.Function != "RunCommand" || string(req.Data) != `{"Command":"quit"}` {
+		t.Errorf("Expected RunCommand to keep only its command, got %+v, %v, %v", req, isAPI, err)
+	}
+	if _, isAPI, err := parseSatisfactoryCall("quit"); isAPI || err != nil {
+		t.Errorf("Expected a console command not to be an API request, got %v, %v", isAPI, err)
+	}
+}
@@ -4,36 +4,232 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/timerwheel"
 )
 
 // Session represents a managed RCON connection session.
 // Each session maintains its own client connection and metadata.
 type Session struct {
-	ID      string  // Unique identifier for the session
-	Client  *Client // RCON client instance for this session
-	Address string  // Server address in "host:port" format
-	Name    string  // Optional friendly name for the session
-	Created int64   // Unix timestamp when the session was created
+	ID      string   // Unique identifier for the session
+	Client  *Client  // RCON client instance for this session
+	Address string   // Server address in "host:port" format
+	Name    string   // Optional friendly name for the session
+	Created int64    // Unix timestamp when the session was created
+	History *History // Commands executed on this session
+
+	mu      sync.RWMutex    // Protects the mutable metadata below
+	game    game.Type       // Detected game type, empty until detection runs
+	help    *game.HelpIndex // Cached help output, nil until first requested
+	role    string          // Role limiting the commands the session may run
+	profile string          // Profile the session was opened from, empty if none
+	// owner identifies the MCP client that opened the session, empty if
+	// every client may use it; shared lets other clients use it anyway.
+	owner       string
+	shared      bool
+	pool        *pool         // Extra connections commands are spread over, nil for none
+	idleTimeout time.Duration // Idle time after which the session is closed, 0 for never
+
+	// reconnectPassword is the password a dropped connection is opened
+	// again with before a command is retried, empty if it is not.
+	reconnectPassword string
+	reconnectMu       sync.Mutex // Serializes reconnect attempts
+	onReconnect       func()     // Called after a dropped connection was opened again
+
+	lastUsed atomic.Int64      // When a command last ran, or the session was created, in Unix nanoseconds
+	timer    *timerwheel.Timer // Next heartbeat or idle check; guarded by the manager's mutex
+	closed   atomic.Bool       // Set once the session is removed from its manager
+}
+
+// Game returns the game type detected for this session.
+// Returns game.Unknown if detection has not been performed.
+func (s *Session) Game() game.Type {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.game == "" {
+		return game.Unknown
+	}
+	return s.game
+}
+
+// SetGame records the game type detected for this session. For games that
+// send replies larger than the protocol allows, the connections the session
+// opens from then on accept packets as large as the game sends, and for
+// games that push chat over RCON they are read by a listener.
+func (s *Session) SetGame(t game.Type) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.game = t
+	if limit := game.PacketLimit(t); limit > 0 {
+		s.Client.RaisePacketLimit(limit)
+	}
+	s.Client.SetListening(game.PushesChat(t))
+}
+
+// Role returns the role the session was opened with, or an empty string
+// if it has none.
+func (s *Session) Role() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.role
+}
+
+// SetRole records the role limiting the commands the session may run.
+func (s *Session) SetRole(role string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.role = role
+}
+
+// Profile returns the name of the profile the session was opened from, or
+// an empty string if it was opened by address.
+func (s *Session) Profile() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.profile
+}
+
+// SetProfile records the profile the session was opened from.
+func (s *Session) SetProfile(profile string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profile = profile
+}
+
+// Owner returns the key of the MCP client that opened the session, empty
+// if it belongs to no client, and whether the owner shares it with others.
+func (s *Session) Owner() (owner string, shared bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.owner, s.shared
+}
+
+// SetOwner binds the session to the MCP client with the given key, shared
+// with other clients or not.
+func (s *Session) SetOwner(owner string, shared bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.owner, s.shared = owner, shared
+}
+
+// Help returns the cached help index for this session, or nil if the help
+// command has not been run yet.
+func (s *Session) Help() *game.HelpIndex {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.help
+}
+
+// SetHelp caches the help index for this session.
+func (s *Session) SetHelp(index *game.HelpIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.help = index
+}
+
+// AutoReconnect reports whether a command that fails because the
+// session's connection dropped is retried on a new connection.
+func (s *Session) AutoReconnect() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.reconnectPassword != ""
+}
+
+// SetAutoReconnect makes a command that fails because the session's
+// connection dropped reconnect, authenticate with password and retry the
+// command once, before reporting the failure. An empty password turns
+// retries off.
+func (s *Session) SetAutoReconnect(password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reconnectPassword = password
+}
+
+// IdleTimeout returns how long the session may run no command before the
+// health checker closes it, or 0 if it never does.
+func (s *Session) IdleTimeout() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.idleTimeout
+}
+
+// SetIdleTimeout sets how long the session may run no command before the
+// health checker closes it; 0 keeps it open. The change takes effect by
+// the session's next health check.
+func (s *Session) SetIdleTimeout(timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idleTimeout = timeout
+}
+
+// idleFor returns how long ago the session last ran a command, or was
+// created if it has run none.
+func (s *Session) idleFor() time.Duration {
+	return time.Since(time.Unix(0, s.lastUsed.Load()))
 }
 
 // SessionManager provides thread-safe management of multiple RCON sessions.
 // It allows creating, retrieving, listing, and removing sessions.
 type SessionManager struct {
-	sessions map[string]*Session // Map of session ID to session instance
-	mu       sync.RWMutex        // Read-write mutex for thread-safe access
+	sessions    map[string]*Session // Map of session ID to session instance
+	mu          sync.RWMutex        // Read-write mutex for thread-safe access
+	onDrop      DropHandler         // Called when a session's connection dies
+	onUnwell    HealthCheckHandler  // Called when a health check fails on a live connection
+	onExpire    ExpireHandler       // Called when a session is closed for being idle
+	onReconnect ReconnectHandler    // Called when a session reconnected to retry a command
+
+	timers    *timerwheel.Wheel // Schedules every session's health checks, nil until StartHealthCheck
+	heartbeat time.Duration     // Quiet time after which a session's connection is probed
+
+	maxSessions int         // Most sessions open at once, 0 for no limit
+	historySize int         // Commands kept in each new session's history
+	read        ReadOptions // Read path tuning of each new session's connections
 }
 
+// ReconnectHandler is notified when a session set to reconnect
+// automatically opened its dropped connection again to retry a command.
+type ReconnectHandler func(session *Session)
+
+// DropHandler is notified when a session's connection is found to be dead,
+// either by a failed command or by the health checker.
+type DropHandler func(session *Session, err error)
+
+// HealthCheckHandler is notified when a health check probe fails but leaves
+// the session's connection up, as when the server does not answer in time.
+type HealthCheckHandler func(session *Session, err error)
+
+// ExpireHandler is notified when the health checker has closed and removed
+// a session that ran no command for its idle timeout.
+type ExpireHandler func(session *Session, idle time.Duration)
+
 // NewSessionManager creates a new instance of SessionManager.
 // The manager starts with no active sessions.
 func NewSessionManager() *SessionManager {
 	return &SessionManager{
-		sessions: make(map[string]*Session),
+		sessions:    make(map[string]*Session),
+		historySize: DefaultHistorySize,
+	}
+}
+
+// SetLimits bounds the number of open sessions and the history kept for
+// each new session. A maxSessions of 0 removes the session limit, and a
+// historySize of 0 keeps DefaultHistorySize.
+func (sm *SessionManager) SetLimits(maxSessions, historySize int) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.maxSessions = maxSessions
+	sm.historySize = historySize
+	if historySize <= 0 {
+		sm.historySize = DefaultHistorySize
 	}
 }
 
 // CreateSession creates a new RCON session with the specified parameters.
-// Returns an error if a session with the given ID already exists.
+// Returns an error if a session with the given ID already exists or the
+// session limit has been reached.
 // The session is created with a new client but not connected.
 func (sm *SessionManager) CreateSession(id, name, address string) (*Session, error) {
 	sm.mu.Lock()
@@ -42,6 +238,9 @@ func (sm *SessionManager) CreateSession(id, name, address string) (*Session, err
 	if _, exists := sm.sessions[id]; exists {
 		return nil, fmt.Errorf("session with ID %s already exists", id)
 	}
+	if sm.maxSessions > 0 && len(sm.sessions) >= sm.maxSessions {
+		return nil, fmt.Errorf("session limit of %d reached", sm.maxSessions)
+	}
 
 	session := &Session{
 		ID:      id,
@@ -49,12 +248,89 @@ func (sm *SessionManager) CreateSession(id, name, address string) (*Session, err
 		Address: address,
 		Name:    name,
 		Created: getCurrentTimestamp(),
+		History: NewHistory(sm.historySize),
 	}
 
+	session.lastUsed.Store(time.Now().UnixNano())
+	session.Client.SetReadOptions(sm.read)
+	session.Client.SetTimers(sm.timers)
+	session.Client.SetDisconnectHandler(func(err error) {
+		sm.sessionDropped(session, err)
+	})
+	session.onReconnect = func() { sm.sessionReconnected(session) }
+
 	sm.sessions[id] = session
+	if sm.timers != nil {
+		sm.schedule(session, sm.heartbeat)
+	}
 	return session, nil
 }
 
+// SetDropHandler registers a handler to be notified when a managed session's
+// connection drops. Only one handler is kept; later calls replace it.
+func (sm *SessionManager) SetDropHandler(handler DropHandler) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.onDrop = handler
+}
+
+// SetHealthCheckHandler registers a handler to be notified when a health
+// check probe fails without the connection being found dead. Only one
+// handler is kept; later calls replace it.
+func (sm *SessionManager) SetHealthCheckHandler(handler HealthCheckHandler) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.onUnwell = handler
+}
+
+// SetReconnectHandler registers a handler to be notified when a session
+// set to reconnect automatically opened its dropped connection again. Only
+// one handler is kept; later calls replace it.
+func (sm *SessionManager) SetReconnectHandler(handler ReconnectHandler) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.onReconnect = handler
+}
+
+// SetReadOptions tunes the read path of the connections of sessions
+// created from now on, pooled ones included.
+func (sm *SessionManager) SetReadOptions(opts ReadOptions) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.read = opts
+}
+
+// SetExpireHandler registers a handler to be notified when a session is
+// closed for being idle. Only one handler is kept; later calls replace it.
+func (sm *SessionManager) SetExpireHandler(handler ExpireHandler) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.onExpire = handler
+}
+
+// sessionDropped forwards a connection loss to the registered drop handler.
+func (sm *SessionManager) sessionDropped(session *Session, err error) {
+	sm.mu.RLock()
+	handler := sm.onDrop
+	sm.mu.RUnlock()
+
+	if handler != nil {
+		handler(session, err)
+	}
+}
+
+// sessionReconnected forwards a reconnect to the registered reconnect
+// handler.
+func (sm *SessionManager) sessionReconnected(session *Session) {
+	sm.mu.RLock()
+	handler := sm.onReconnect
+	sm.mu.RUnlock()
+
+	if handler != nil {
+		handler(session)
+	}
+}
+
 // GetSession retrieves an existing session by its ID.
 // Returns an error if the session doesn't exist.
 func (sm *SessionManager) GetSession(id string) (*Session, error) {
@@ -63,7 +339,7 @@ func (sm *SessionManager) GetSession(id string) (*Session, error) {
 
 	session, exists := sm.sessions[id]
 	if !exists {
-		return nil, fmt.Errorf("session with ID %s not found", id)
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
 	}
 
 	return session, nil
@@ -83,7 +359,8 @@ func (sm *SessionManager) ListSessions() []*Session {
 	return sessions
 }
 
-// RemoveSession removes a session from the manager and disconnects its client.
+// RemoveSession removes a session from the manager and disconnects its client
+// and the connections of its pool.
 // Returns an error if the session doesn't exist.
 // The client is gracefully disconnected before removal.
 func (sm *SessionManager) RemoveSession(id string) error {
@@ -92,10 +369,13 @@ func (sm *SessionManager) RemoveSession(id string) error {
 
 	session, exists := sm.sessions[id]
 	if !exists {
-		return fmt.Errorf("session with ID %s not found", id)
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, id)
 	}
 
 	// Disconnect the client if connected
+	session.closed.Store(true)
+	session.stopTimer()
+	session.closePool()
 	if session.Client.IsConnected() {
 		if err := session.Client.Disconnect(); err != nil {
 			return fmt.Errorf("failed to disconnect client: %w", err)
@@ -115,6 +395,9 @@ func (sm *SessionManager) DisconnectAll() error {
 
 	var errs []error
 	for id, session := range sm.sessions {
+		session.closed.Store(true)
+		session.stopTimer()
+		session.closePool()
 		if session.Client.IsConnected() {
 			if err := session.Client.Disconnect(); err != nil {
 				errs = append(errs, fmt.Errorf("failed to disconnect session %s: %w", id, err))
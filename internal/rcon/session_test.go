@@ -5,6 +5,9 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcontest"
 )
 
 func TestNewSessionManager(t *testing.T) {
@@ -56,6 +59,17 @@ func TestSessionManager_CreateSession(t *testing.T) {
 			wantErr:     true,
 			errContains: "already exists",
 		},
+		{
+			name:        "session limit reached",
+			sessionID:   "over-limit",
+			address:     "localhost:25575",
+			setupFunc: func(sm *SessionManager) {
+				sm.SetLimits(1, 0)
+				sm.sessions["existing"] = &Session{ID: "existing"}
+			},
+			wantErr:     true,
+			errContains: "session limit",
+		},
 	}
 
 	for _, tt := range tests {
@@ -299,39 +313,33 @@ func TestSessionManager_RemoveSession(t *testing.T) {
 func TestSessionManager_DisconnectAll(t *testing.T) {
 	tests := []struct {
 		name      string
-		setupFunc func(*SessionManager)
+		setupFunc func(*testing.T, *SessionManager)
 		wantErr   bool
 	}{
 		{
 			name:      "disconnect empty sessions",
-			setupFunc: func(sm *SessionManager) {},
+			setupFunc: func(t *testing.T, sm *SessionManager) {},
 			wantErr:   false,
 		},
 		{
 			name: "disconnect multiple sessions",
-			setupFunc: func(sm *SessionManager) {
+			setupFunc: func(t *testing.T, sm *SessionManager) {
 				// Add disconnected session
 				sm.sessions["session-1"] = &Session{
 					ID:     "session-1",
 					Client: NewClient(),
 				}
-				
-				// Add connected session
-				client2 := NewClient()
-				client2.isConnected = true
-				client2.conn = newMockConn()
-				sm.sessions["session-2"] = &Session{
-					ID:     "session-2",
-					Client: client2,
-				}
-				
-				// Add another connected session
-				client3 := NewClient()
-				client3.isConnected = true
-				client3.conn = newMockConn()
-				sm.sessions["session-3"] = &Session{
-					ID:     "session-3",
-					Client: client3,
+
+				// Add connected sessions
+				server := rcontest.NewServer(t, "secret", nil)
+				for _, id := range []string{"session-2", "session-3"} {
+					session, err := sm.CreateSession(id, "", server.Addr())
+					if err != nil {
+						t.Fatalf("CreateSession failed: %v", err)
+					}
+					if err := session.Client.Connect(server.Addr()); err != nil {
+						t.Fatalf("Connect failed: %v", err)
+					}
 				}
 			},
 			wantErr: false,
@@ -342,7 +350,7 @@ func TestSessionManager_DisconnectAll(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			sm := NewSessionManager()
 			if tt.setupFunc != nil {
-				tt.setupFunc(sm)
+				tt.setupFunc(t, sm)
 			}
 
 			err := sm.DisconnectAll()
@@ -420,4 +428,33 @@ func TestGetCurrentTimestamp(t *testing.T) {
 	if timestamp < before || timestamp > after {
 		t.Errorf("Timestamp %d is not within expected range [%d, %d]", timestamp, before, after)
 	}
-}
\ No newline at end of file
+}
+func TestSession_Game(t *testing.T) {
+	sm := NewSessionManager()
+	session, err := sm.CreateSession("game-session", "Test", "localhost:25575")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	if got := session.Game(); got != game.Unknown {
+		t.Errorf("Expected game to be %q before detection, got %q", game.Unknown, got)
+	}
+
+	session.SetGame(game.Minecraft)
+	if got := session.Game(); got != game.Minecraft {
+		t.Errorf("Expected game to be %q, got %q", game.Minecraft, got)
+	}
+}
+
+func TestSession_Help(t *testing.T) {
+	session := &Session{ID: "test"}
+	if session.Help() != nil {
+		t.Error("Expected no cached help before SetHelp")
+	}
+
+	index := &game.HelpIndex{Command: "help", Entries: []game.HelpEntry{{Command: "list", Usage: "/list"}}}
+	session.SetHelp(index)
+	if got := session.Help(); got != index {
+		t.Errorf("Expected cached help index, got %+v", got)
+	}
+}
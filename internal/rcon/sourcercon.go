@@ -0,0 +1,182 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+// sourceRCON is the Backend of a connection speaking Source RCON, the
+// default protocol. Only the exchange holding the client's queue slot uses
+// it, and the listener of the connection once it has taken over reading.
+type sourceRCON struct {
+	read      ReadOptions // Tuning of the read path
+	listening bool        // Whether the connection is read by a listener once authenticated
+	console   func(ConsoleMessage)
+	drop      func(error)
+	id        int32     // Number of the last request
+	lst       *listener // Listener of the connection once authenticated, nil if not listening
+
+	// The buffered reader of the connection and the largest packet it
+	// accepts.
+	rd      *bufio.Reader
+	rdConn  net.Conn
+	rdLimit int32
+}
+
+// Dial opens a TCP connection to the server.
+func (r *sourceRCON) Dial(ctx context.Context, address string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", address)
+}
+
+// Capabilities describes Source RCON, whose servers only push chat to a
+// listening client.
+func (r *sourceRCON) Capabilities() Capabilities {
+	return Capabilities{Network: "tcp", Game: game.Unknown}
+}
+
+// SetConsoleHandler sets the function the chat a listened server pushes
+// goes to.
+func (r *sourceRCON) SetConsoleHandler(handler func(ConsoleMessage)) {
+	r.console = handler
+}
+
+// setDropHandler sets the function the listener reports the connection
+// dying with.
+func (r *sourceRCON) setDropHandler(handler func(error)) {
+	r.drop = handler
+}
+
+// tune takes the client's read options and whether it listens.
+func (r *sourceRCON) tune(read ReadOptions, listening bool) {
+	r.read, r.listening = read, listening
+}
+
+// Auth sends password in an auth packet and checks the server's answer,
+// then starts the listener if the client listens.
+func (r *sourceRCON) Auth(ctx context.Context, conn net.Conn, password string) error {
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	// Send auth packet
+	authPacket := &Packet{
+		ID:   r.nextID(),
+		Type: PacketTypeAuth,
+		Body: password,
+	}
+
+	if err := r.sendPacket(conn, authPacket); err != nil {
+		return fmt.Errorf("failed to send auth packet: %w", err)
+	}
+
+	// Read auth response, skipping chat a listened server pushed meanwhile
+	response, err := r.readPacket(conn)
+	for err == nil && r.listening && response.Type == PacketTypeChat {
+		response, err = r.readPacket(conn)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read auth response: %w", err)
+	}
+
+	// Check auth response
+	if response.ID == -1 {
+		return fmt.Errorf("%w: invalid password", ErrAuthFailed)
+	}
+
+	if response.ID != authPacket.ID {
+		return fmt.Errorf("%w: unexpected response ID", ErrAuthFailed)
+	}
+
+	if r.listening {
+		_ = conn.SetDeadline(time.Time{})
+		r.listen(conn)
+	}
+	return nil
+}
+
+// Execute sends command in a command packet and returns the body of the
+// reply, skipping late replies to earlier cancelled commands.
+func (r *sourceRCON) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	// Send command packet
+	cmdPacket := &Packet{
+		ID:   r.nextID(),
+		Type: PacketTypeCommand,
+		Body: command,
+	}
+	if r.lst != nil {
+		return r.executeListened(ctx, conn, cmdPacket)
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return "", fmt.Errorf("failed to set deadline: %w", err)
+	}
+	// Interrupt blocking socket I/O as soon as ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := r.sendPacket(conn, cmdPacket); err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+	tracePacket(ctx, "Sent RCON packet", cmdPacket)
+
+	// Read response, skipping late replies to earlier cancelled commands
+	for range maxStaleReads {
+		response, err := r.readPacket(conn)
+		if err != nil {
+			return "", fmt.Errorf("failed to read response: %w", err)
+		}
+
+		tracePacket(ctx, "Received RCON packet", response)
+		if response.ID > 0 && response.ID < cmdPacket.ID {
+			continue
+		}
+
+		// Verify response ID matches request
+		if response.ID != cmdPacket.ID {
+			return "", errors.New("response ID mismatch")
+		}
+
+		return response.Body, nil
+	}
+
+	return "", errors.New("response ID mismatch")
+}
+
+// nextID returns the ID of the next request. IDs are incremented
+// sequentially for each request, starting from 1.
+func (r *sourceRCON) nextID() int32 {
+	r.id++
+	return r.id
+}
+
+// sendPacket encodes and sends a packet to the RCON server over conn.
+// It automatically calculates the packet size and adds null terminators.
+func (r *sourceRCON) sendPacket(conn net.Conn, packet *Packet) error {
+	return WritePacket(conn, packet)
+}
+
+// readPacket reads and decodes a packet from the RCON server over conn.
+// It validates packet size and parses the packet structure.
+func (r *sourceRCON) readPacket(conn net.Conn) (*Packet, error) {
+	rd, limit := r.reader(conn)
+	return ReadPacket(rd, limit)
+}
+
+// reader returns the buffered reader of conn and the largest packet it
+// accepts, creating them with the read options when conn is new.
+func (r *sourceRCON) reader(conn net.Conn) (*bufio.Reader, int32) {
+	if r.rdConn != conn {
+		r.rd = bufio.NewReaderSize(conn, r.read.bufferSize())
+		r.rdConn, r.rdLimit = conn, int32(r.read.maxPacketSize())
+	}
+	return r.rd, r.rdLimit
+}
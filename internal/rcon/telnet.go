@@ -0,0 +1,293 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+// telnetQuiet is how long a telnet server must send no more output after
+// echoing a command for its reply to be taken as complete: the console
+// marks neither the end of a reply nor which command it answers.
+const telnetQuiet = 250 * time.Millisecond
+
+// Patterns of the lines a 7 Days to Die telnet console sends.
+var (
+	// telnetLogPattern matches the log lines the console streams to every
+	// telnet client, "2024-05-01T12:00:00 123.456 INF Player connected...",
+	// with their level and text.
+	telnetLogPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2} \d+\.\d+ ([A-Z]{3}) (.*)$`)
+
+	// telnetEchoPattern matches the log text of a command run from telnet,
+	// which precedes its output.
+	telnetEchoPattern = regexp.MustCompile(`^Executing command '(.*)' by Telnet from `)
+)
+
+// telnetLogTypes maps the levels of telnet log lines to the types of
+// console messages.
+var telnetLogTypes = map[string]string{"INF": "Log", "WRN": "Warning", "ERR": "Error", "EXC": "Error"}
+
+// telnet is the Backend of a connection to a 7 Days to Die telnet
+// console. Once logged in, its reader goroutine hands the output of the
+// command in flight to the exchange waiting for it and the log lines to
+// the console handler.
+type telnet struct {
+	console func(ConsoleMessage)
+	drop    func(error)
+	rd      *bufio.Reader
+	mu      sync.Mutex
+	cmd     *telnetExchange // The command in flight, nil between commands
+	done    chan struct{}   // Closed when the reader stops
+	err     error           // Why the reader stopped, set before done is closed
+}
+
+// telnetExchange is a command waiting for its output.
+type telnetExchange struct {
+	command string
+	echoed  bool          // The server logged that it runs the command
+	output  []string      // The lines of output since the echo
+	more    chan struct{} // Signalled when the command is echoed or output arrives
+}
+
+// Dial opens a TCP connection to the console.
+func (t *telnet) Dial(ctx context.Context, address string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", address)
+}
+
+// Capabilities describes the telnet console, which serves 7 Days to Die
+// only and streams its log unprompted.
+func (t *telnet) Capabilities() Capabilities {
+	return Capabilities{Network: "tcp", Console: true, Game: game.SevenDays}
+}
+
+// SetConsoleHandler sets the function log lines and console output go to.
+func (t *telnet) SetConsoleHandler(handler func(ConsoleMessage)) {
+	t.console = handler
+}
+
+// setDropHandler sets the function the reader reports the connection
+// dying with.
+func (t *telnet) setDropHandler(handler func(error)) {
+	t.drop = handler
+}
+
+// Auth logs in to the console on conn by answering its password prompt,
+// and starts reading from it once the console has greeted it, which ends
+// with how to get help. Consoles that need no password, as on servers
+// accepting telnet from localhost only, greet the client without a prompt.
+func (t *telnet) Auth(ctx context.Context, conn net.Conn, password string) error {
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	rd := bufio.NewReader(conn)
+	prompted := false
+	for {
+		line, err := readTelnetPrompt(rd)
+		if err != nil {
+			if isConnectionError(err) {
+				return fmt.Errorf("%w: the server closed the connection", ErrAuthFailed)
+			}
+			return fmt.Errorf("failed to read login prompt: %w", err)
+		}
+		switch {
+		case strings.Contains(line, "Password incorrect"):
+			return fmt.Errorf("%w: invalid password", ErrAuthFailed)
+		case strings.HasSuffix(strings.ToLower(line), "password:") && !prompted:
+			prompted = true
+			if _, err := conn.Write([]byte(password + "\r\n")); err != nil {
+				return fmt.Errorf("failed to send password: %w", err)
+			}
+			continue
+		case !strings.HasPrefix(line, "Press 'help'"):
+			continue
+		}
+		break
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("failed to clear deadline: %w", err)
+	}
+
+	t.rd, t.done = rd, make(chan struct{})
+	go t.read()
+	return nil
+}
+
+// readTelnetPrompt reads the next line from rd without its line ending,
+// or the password prompt, which no line ending follows.
+func readTelnetPrompt(rd *bufio.Reader) (string, error) {
+	var line strings.Builder
+	for {
+		b, err := rd.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if b == '\n' {
+			return strings.TrimRight(line.String(), "\r"), nil
+		}
+		line.WriteByte(b)
+		if b == ':' && strings.HasSuffix(strings.ToLower(line.String()), "password:") {
+			return line.String(), nil
+		}
+	}
+}
+
+// read reads the lines of the console until the connection fails, then
+// reports it dying to the drop handler. Log lines go to the console
+// handler, except the echo of the command in flight; the other lines are
+// the output of that command, or console output if none is in flight and
+// they are not blank.
+func (t *telnet) read() {
+	for {
+		line, err := t.rd.ReadString('\n')
+		if err != nil {
+			t.err = err
+			close(t.done)
+			if t.drop != nil {
+				t.drop(err)
+			}
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		msg := ConsoleMessage{Type: "Generic", Message: line}
+		if m := telnetLogPattern.FindStringSubmatch(line); m != nil {
+			if t.echo(m[2]) {
+				continue
+			}
+			msg.Type = telnetLogTypes[m[1]]
+			if strings.HasPrefix(m[2], "Chat ") {
+				msg.Type = "Chat"
+			}
+		} else if t.output(line) || line == "" {
+			continue
+		}
+		if t.console != nil {
+			t.console(msg)
+		}
+	}
+}
+
+// Execute sends command and returns the lines the console sends after
+// echoing it, once it has been quiet for telnetQuiet, or gives up when
+// ctx is done. An empty command, as health checks send, is an empty line,
+// which the console ignores.
+func (t *telnet) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	var ex *telnetExchange
+	if command != "" {
+		ex = t.start(command)
+		defer t.finish()
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetWriteDeadline(deadline); err != nil {
+		return "", fmt.Errorf("failed to set write deadline: %w", err)
+	}
+	_, err := conn.Write([]byte(command + "\r\n"))
+	_ = conn.SetWriteDeadline(time.Time{})
+	if err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+	tracePacket(ctx, "Sent telnet command", &Packet{Type: PacketTypeCommand, Body: command})
+	if ex == nil {
+		select {
+		case <-t.done:
+			return "", fmt.Errorf("failed to read response: %w", t.err)
+		default:
+			return "", nil
+		}
+	}
+
+	quiet := time.NewTimer(telnetQuiet)
+	quiet.Stop()
+	defer quiet.Stop()
+	for {
+		select {
+		case <-ex.more:
+			if t.isEchoed(ex) {
+				quiet.Reset(telnetQuiet)
+			}
+		case <-quiet.C:
+			output := t.collect(ex)
+			tracePacket(ctx, "Received telnet output", &Packet{Type: PacketTypeResponse, Body: output})
+			return output, nil
+		case <-t.done:
+			return "", fmt.Errorf("failed to read response: %w", t.err)
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// start makes command the command in flight.
+func (t *telnet) start(command string) *telnetExchange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cmd = &telnetExchange{command: strings.TrimSpace(command), more: make(chan struct{}, 1)}
+	return t.cmd
+}
+
+// finish ends the command in flight; later output is console output.
+func (t *telnet) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cmd = nil
+}
+
+// echo reports whether text, the text of a log line, is the echo of the
+// command in flight, and if so records that its output follows.
+func (t *telnet) echo(text string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m := telnetEchoPattern.FindStringSubmatch(text)
+	if t.cmd == nil || t.cmd.echoed || m == nil || m[1] != t.cmd.command {
+		return false
+	}
+	t.cmd.echoed = true
+	t.cmd.signal()
+	return true
+}
+
+// output adds line to the output of the command in flight, if it has been
+// echoed, and reports whether it did.
+func (t *telnet) output(line string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.cmd == nil || !t.cmd.echoed {
+		return false
+	}
+	t.cmd.output = append(t.cmd.output, line)
+	t.cmd.signal()
+	return true
+}
+
+// isEchoed reports whether the server echoed ex.
+func (t *telnet) isEchoed(ex *telnetExchange) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return ex.echoed
+}
+
+// collect returns the output of ex so far as one string, without the
+// blank lines the console ends it with.
+func (t *telnet) collect(ex *telnetExchange) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.TrimRight(strings.Join(ex.output, "\n"), "\n")
+}
+
+// signal wakes the exchange waiting for e, if it is not awake already.
+func (e *telnetExchange) signal() {
+	select {
+	case e.more <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,136 @@
+package rcon
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTelnetServer serves a 7 Days to Die style telnet console on a local
+// port for the password "secret". It logs a chat line before echoing each
+// command and answers with two lines of output; the command "quit" closes
+// the connection instead.
+func startTelnetServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveTelnet(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// serveTelnet runs one connection of startTelnetServer.
+func serveTelnet(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	fmt.Fprint(conn, "Please enter password:")
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return
+	}
+	if strings.TrimSpace(line) != "secret" {
+		fmt.Fprint(conn, "Password incorrect, please enter password:")
+		return
+	}
+	fmt.Fprint(conn, "Logon successful.\r\n\r\n*** Connected with 7DTD server.\r\n*** Server version: V 1.0 (b333)\r\n\r\n")
+	fmt.Fprint(conn, "Press 'help' to get a list of all commands. Press 'exit' to end session.\r\n\r\n")
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimSpace(line)
+		switch command {
+		case "":
+			continue
+		case "quit":
+			return
+		}
+		fmt.Fprint(conn, "2024-05-01T12:00:00 123.456 INF Chat (from 'Steam_1', entity id '171', to 'Global'): 'Bob': hi\r\n")
+		fmt.Fprintf(conn, "2024-05-01T12:00:01 123.457 INF Executing command '%s' by Telnet from 127.0.0.1:50000\r\n", command)
+		fmt.Fprintf(conn, "echo %s\r\ndone\r\n\r\n", command)
+	}
+}
+
+func TestTelnet(t *testing.T) {
+	address := startTelnetServer(t)
+	client := NewClient()
+	client.SetProtocol(ProtocolTelnet)
+	console := make(chan ConsoleMessage, 8)
+	client.SetConsoleHandler(func(m ConsoleMessage) { console <- m })
+	dropped := make(chan error, 1)
+	client.SetDisconnectHandler(func(err error) { dropped <- err })
+
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	for _, command := range []string{"version", "listplayers"} {
+		out, err := client.Execute(command)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if out != "echo "+command+"\ndone" {
+			t.Errorf("Expected the output of %s, got %q", command, out)
+		}
+	}
+	select {
+	case m := <-console:
+		if m.Type != "Chat" || !strings.Contains(m.Message, "'Bob': hi") {
+			t.Errorf("Unexpected console message: %+v", m)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the chat to reach the console handler")
+	}
+
+	// Health checks send an empty line, which the console ignores
+	if _, err := client.Execute(healthCheckCommand); err != nil {
+		t.Errorf("Expected the health check to pass, got %v", err)
+	}
+
+	if _, err := client.Execute("quit"); err == nil {
+		t.Error("Expected the command to fail as the server closed the connection")
+	}
+	select {
+	case <-dropped:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the disconnect handler to be called")
+	}
+	if client.IsConnected() {
+		t.Error("Expected the client to be disconnected")
+	}
+}
+
+func TestTelnet_InvalidPassword(t *testing.T) {
+	address := startTelnetServer(t)
+	client := NewClient()
+	client.SetProtocol(ProtocolTelnet)
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Disconnect()
+
+	if err := client.Authenticate("wrong"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+	if client.IsAuthenticated() {
+		t.Error("Expected the client not to be authenticated")
+	}
+}
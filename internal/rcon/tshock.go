@@ -0,0 +1,219 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+)
+
+// TShock REST endpoints used by the client.
+const (
+	tshockTokenTest = "/tokentest"        // Answers 200 for a valid token
+	tshockRawCmd    = "/v2/server/rawcmd" // Runs a command as the server console
+)
+
+// tshockReply is the JSON body of a TShock REST reply. TShock sends the
+// status as a string, and the response of rawcmd as a list of lines or, on
+// older versions, as one string.
+type tshockReply struct {
+	Status   json.RawMessage `json:"status"`
+	Response json.RawMessage `json:"response"`
+	Error    string          `json:"error"`
+}
+
+// tshock is the Backend of a connection to a TShock REST API. Only the
+// exchange holding the client's queue slot uses it.
+type tshock struct {
+	address string // Address dialed, sent as the Host of requests
+	token   string
+	conn    *httpConn
+	rd      *bufio.Reader // Reader of the connection the last request went over
+	rdOn    net.Conn
+	stale   bool // The server closed the connection after its last reply
+}
+
+// Dial opens a connection to the REST API.
+func (ts *tshock) Dial(ctx context.Context, address string) (net.Conn, error) {
+	conn, err := dialConsole(ctx, address, false, "", false)
+	if err != nil {
+		return nil, err
+	}
+	ts.address, ts.conn = address, &httpConn{conn: conn}
+	return ts.conn, nil
+}
+
+// Capabilities describes the REST API, which serves Terraria only and
+// only answers requests.
+func (ts *tshock) Capabilities() Capabilities {
+	return Capabilities{Network: "tcp", Game: game.Terraria}
+}
+
+// Auth checks password, a REST token of a TShock server, by asking the
+// server to test it, since the REST API has no login of its own on the
+// connection: every request carries the token.
+func (ts *tshock) Auth(ctx context.Context, conn net.Conn, password string) error {
+	ts.token = password
+	reply, err := ts.request(ctx, tshockTokenTest, nil)
+	switch {
+	case errors.Is(err, ErrAuthFailed):
+		return err
+	case err != nil && isConnectionError(err):
+		return fmt.Errorf("%w: the server closed the connection", ErrAuthFailed)
+	case err != nil:
+		return fmt.Errorf("failed to test the token: %w", err)
+	case reply.status() != "200":
+		return fmt.Errorf("%w: %s", ErrAuthFailed, reply.message())
+	}
+	return nil
+}
+
+// Execute runs command with the rawcmd endpoint and returns the lines it
+// printed. TShock runs only commands that start with "/", which is added
+// if missing. An empty command, as health checks send, tests the token
+// instead.
+func (ts *tshock) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	path, query := tshockTokenTest, url.Values{}
+	if command != "" {
+		if !strings.HasPrefix(command, "/") {
+			command = "/" + command
+		}
+		path = tshockRawCmd
+		query.Set("cmd", command)
+	}
+
+	reply, err := ts.request(ctx, path, query)
+	switch {
+	case err != nil:
+		return "", err
+	case reply.status() != "200":
+		return "", fmt.Errorf("the server refused the request: %s", reply.message())
+	}
+	return reply.output(), nil
+}
+
+// request sends a GET request for path with the token and query to the
+// server and returns its reply. A server that closed the connection after
+// its last reply, or while it sat idle, is dialed again once. A reply
+// refusing the token is ErrAuthFailed.
+func (ts *tshock) request(ctx context.Context, path string, query url.Values) (*tshockReply, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("token", ts.token)
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Path: path, RawQuery: query.Encode()},
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Accept": {"application/json"}},
+		Host:       ts.address,
+	}
+
+	if ts.stale {
+		if err := ts.redial(ctx); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := ts.roundTrip(ctx, req)
+	if err != nil && isConnectionError(err) && ctx.Err() == nil {
+		// The server dropped a connection kept open from an earlier request
+		if err := ts.redial(ctx); err != nil {
+			return nil, err
+		}
+		reply, err = ts.roundTrip(ctx, req)
+	}
+	return reply, err
+}
+
+// roundTrip sends req and reads the reply, giving up when ctx is done.
+func (ts *tshock) roundTrip(ctx context.Context, req *http.Request) (*tshockReply, error) {
+	conn := ts.conn.current()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	tracePacket(ctx, "Sent TShock request", &Packet{Type: PacketTypeCommand, Body: req.URL.Path})
+	if ts.rdOn != conn {
+		ts.rd, ts.rdOn = bufio.NewReader(conn), conn
+	}
+	resp, err := http.ReadResponse(ts.rd, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxReadLimit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	ts.stale = resp.Close
+	tracePacket(ctx, "Received TShock reply", &Packet{Type: PacketTypeResponse, Body: string(body)})
+
+	var reply tshockReply
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return nil, fmt.Errorf("unexpected reply from the server (HTTP %s): %w", resp.Status, err)
+	}
+	if resp.StatusCode == http.StatusForbidden || reply.status() == "403" {
+		return nil, fmt.Errorf("%w: the server refused the token: %s", ErrAuthFailed, reply.message())
+	}
+	return &reply, nil
+}
+
+// redial replaces the connection the server closed with a new one to the
+// same address. If that fails, the error shows the connection closed, so
+// that the client marks itself disconnected.
+func (ts *tshock) redial(ctx context.Context) error {
+	fresh, err := dialConsole(ctx, ts.address, false, "", false)
+	if err != nil {
+		return fmt.Errorf("failed to reconnect: %w: %w", net.ErrClosed, err)
+	}
+	if err := ts.conn.replace(fresh); err != nil {
+		return err
+	}
+	ts.stale = false
+	return nil
+}
+
+// status returns the status of the reply, which TShock sends as a string
+// and some versions as a number.
+func (r *tshockReply) status() string {
+	return strings.Trim(string(r.Status), `"`)
+}
+
+// message returns the error of a reply, or its response if it has none.
+func (r *tshockReply) message() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	return r.output()
+}
+
+// output returns the response of a reply as lines of text.
+func (r *tshockReply) output() string {
+	var lines []string
+	if err := json.Unmarshal(r.Response, &lines); err == nil {
+		return strings.Join(lines, "\n")
+	}
+	var text string
+	if err := json.Unmarshal(r.Response, &text); err == nil {
+		return text
+	}
+	return string(r.Response)
+}
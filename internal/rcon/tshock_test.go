@@ -0,0 +1,98 @@
+package rcon
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// startTShockServer serves a TShock REST API on a local port for the token
+// "secret". rawcmd echoes its command; the command "/bye" is answered
+// with the connection closed after the reply, as some TShock versions do
+// for every request.
+func startTShockServer(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reply := map[string]any{"status": "200"}
+		switch {
+		case r.URL.Query().Get("token") != "secret":
+			reply = map[string]any{"status": "403", "error": "Not authorized. The specified API endpoint requires a token."}
+		case r.URL.Path == "/tokentest":
+			reply["response"] = "Token is valid and was passed through correctly."
+		case r.URL.Path == "/v2/server/rawcmd":
+			cmd := r.URL.Query().Get("cmd")
+			if cmd == "/bye" {
+				w.Header().Set("Connection", "close")
+			}
+			reply["response"] = []string{"echo " + cmd, "done"}
+		default:
+			reply = map[string]any{"status": "404", "error": "Specified API endpoint doesn't exist."}
+		}
+		_ = json.NewEncoder(w).Encode(reply)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://"), &conns
+}
+
+func TestTShock(t *testing.T) {
+	address, conns := startTShockServer(t)
+	client := NewClient()
+	client.SetProtocol(ProtocolTShock)
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Disconnect()
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	for _, tt := range []struct{ command, want string }{
+		{"time", "echo /time\ndone"},
+		{"/bye", "echo /bye\ndone"},
+		{"/who", "echo /who\ndone"},
+	} {
+		out, err := client.Execute(tt.command)
+		if err != nil {
+			t.Fatalf("Execute(%q) failed: %v", tt.command, err)
+		}
+		if out != tt.want {
+			t.Errorf("Execute(%q) = %q, want %q", tt.command, out, tt.want)
+		}
+	}
+	// The server closed the connection after /bye, so /who needed another
+	if n := conns.Load(); n != 2 {
+		t.Errorf("Expected 2 connections, got %d", n)
+	}
+	if _, err := client.Execute(healthCheckCommand); err != nil {
+		t.Errorf("Expected the health check to pass, got %v", err)
+	}
+}
+
+func TestTShock_InvalidToken(t *testing.T) {
+	address, _ := startTShockServer(t)
+	client := NewClient()
+	client.SetProtocol(ProtocolTShock)
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Disconnect()
+
+	if err := client.Authenticate("wrong"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+	if client.IsAuthenticated() {
+		t.Error("Expected the client not to be authenticated")
+	}
+}
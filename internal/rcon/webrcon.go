@@ -0,0 +1,215 @@
+package rcon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/game"
+	"github.com/mjmorales/rcon-mcp-server/internal/websocket"
+)
+
+// webrconName is the Name sent with WebRCON commands, which servers log.
+const webrconName = "WebRcon"
+
+// webrconMessage is a message of the WebRCON protocol. Commands carry an
+// Identifier that the server repeats in its reply; the console output it
+// sends unprompted, chat included, has an Identifier of 0 or below.
+type webrconMessage struct {
+	Identifier int32  `json:"Identifier"`
+	Message    string `json:"Message"`
+	Name       string `json:"Name,omitempty"`
+	Type       string `json:"Type,omitempty"`
+	Stacktrace string `json:"Stacktrace,omitempty"`
+}
+
+// webrcon is the Backend of a connection speaking WebRCON. Once logged
+// in, its reader goroutine hands the replies to commands to the exchanges
+// waiting for them and the other messages to the console handler.
+type webrcon struct {
+	address string // Address dialed, sent as the Host of the handshake
+	console func(ConsoleMessage)
+	drop    func(error)
+	id      int32 // Identifier of the last command
+	ws      *websocket.Conn
+	mu      sync.Mutex
+	pending map[int32]chan webrconMessage // Exchanges waiting for a reply, by Identifier
+	pongs   chan struct{}                 // Pongs answering health-check pings
+	done    chan struct{}                 // Closed when the reader stops
+	err     error                         // Why the reader stopped, set before done is closed
+}
+
+// Dial opens a TCP connection to the server.
+func (w *webrcon) Dial(ctx context.Context, address string) (net.Conn, error) {
+	w.address = address
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", address)
+}
+
+// Capabilities describes WebRCON, which serves Rust only and sends its
+// console output unprompted.
+func (w *webrcon) Capabilities() Capabilities {
+	return Capabilities{Network: "tcp", Console: true, Game: game.Rust}
+}
+
+// SetConsoleHandler sets the function messages other than replies go to.
+func (w *webrcon) SetConsoleHandler(handler func(ConsoleMessage)) {
+	w.console = handler
+}
+
+// setDropHandler sets the function the reader reports the connection
+// dying with.
+func (w *webrcon) setDropHandler(handler func(error)) {
+	w.drop = handler
+}
+
+// Auth opens a WebSocket on conn with the password as its path and starts
+// reading from it. Servers refuse a wrong password by closing the
+// connection or answering the upgrade with an error status.
+func (w *webrcon) Auth(ctx context.Context, conn net.Conn, password string) error {
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set deadline: %w", err)
+	}
+	ws, err := websocket.Handshake(conn, w.address, "/"+url.PathEscape(password), nil)
+	var refused *websocket.HandshakeError
+	switch {
+	case errors.As(err, &refused):
+		return fmt.Errorf("%w: the server refused the WebSocket: %s", ErrAuthFailed, refused.Status)
+	case isConnectionError(err):
+		return fmt.Errorf("%w: the server closed the connection, which usually means an invalid password", ErrAuthFailed)
+	case err != nil:
+		return fmt.Errorf("failed to open the WebSocket: %w", err)
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("failed to clear deadline: %w", err)
+	}
+
+	w.ws = ws
+	w.pending = make(map[int32]chan webrconMessage)
+	w.pongs, w.done = make(chan struct{}, 1), make(chan struct{})
+	go w.read()
+	return nil
+}
+
+// read reads the messages of the WebSocket until it fails, then reports
+// the connection dying to the drop handler, since a connection that can no
+// longer be read carries no more replies.
+func (w *webrcon) read() {
+	for {
+		t, data, err := w.ws.ReadMessage()
+		if err != nil {
+			w.err = err
+			close(w.done)
+			if w.drop != nil {
+				w.drop(err)
+			}
+			return
+		}
+
+		if t == websocket.PongMessage {
+			select {
+			case w.pongs <- struct{}{}:
+			default:
+			}
+			continue
+		}
+		var msg webrconMessage
+		if t != websocket.TextMessage || json.Unmarshal(data, &msg) != nil {
+			continue
+		}
+		if msg.Identifier > 0 {
+			// A reply, dropped if its command was cancelled
+			w.deliver(msg)
+			continue
+		}
+		if w.console != nil {
+			w.console(ConsoleMessage{Type: msg.Type, Message: msg.Message})
+		}
+	}
+}
+
+// Execute sends command with the next Identifier and waits for the reply
+// carrying it or for ctx to be done. An empty command, as health checks
+// send, is a WebSocket ping answered by a pong instead, since servers do
+// not reply to empty commands.
+func (w *webrcon) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	w.id++
+	id := w.id
+	var reply chan webrconMessage
+	var pongs chan struct{}
+	if command == "" {
+		// Drop a pong left over from an earlier ping that timed out
+		select {
+		case <-w.pongs:
+		default:
+		}
+		pongs = w.pongs
+	} else {
+		reply = w.expect(id)
+		defer w.forget(id)
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetWriteDeadline(deadline); err != nil {
+		return "", fmt.Errorf("failed to set write deadline: %w", err)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetWriteDeadline(time.Now()) })
+	var err error
+	if command == "" {
+		err = w.ws.Ping(nil)
+	} else {
+		data, _ := json.Marshal(webrconMessage{Identifier: id, Message: command, Name: webrconName})
+		err = w.ws.WriteMessage(websocket.TextMessage, data)
+	}
+	stop()
+	// The reader answers the server's pings without a deadline of its own
+	_ = conn.SetWriteDeadline(time.Time{})
+	if err != nil {
+		return "", fmt.Errorf("failed to send command: %w", err)
+	}
+	tracePacket(ctx, "Sent WebRCON message", &Packet{ID: id, Type: PacketTypeCommand, Body: command})
+
+	select {
+	case msg := <-reply:
+		tracePacket(ctx, "Received WebRCON message", &Packet{ID: msg.Identifier, Type: PacketTypeResponse, Body: msg.Message})
+		return msg.Message, nil
+	case <-pongs:
+		return "", nil
+	case <-w.done:
+		return "", fmt.Errorf("failed to read response: %w", w.err)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// expect registers an exchange waiting for the reply with Identifier id.
+func (w *webrcon) expect(id int32) chan webrconMessage {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	reply := make(chan webrconMessage, 1)
+	w.pending[id] = reply
+	return reply
+}
+
+// forget unregisters the exchange waiting for the reply with Identifier id.
+func (w *webrcon) forget(id int32) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.pending, id)
+}
+
+// deliver hands msg to the exchange waiting for it, if any.
+func (w *webrcon) deliver(msg webrconMessage) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if reply, ok := w.pending[msg.Identifier]; ok {
+		reply <- msg
+		delete(w.pending, msg.Identifier)
+	}
+}
@@ -0,0 +1,129 @@
+package rcon
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/websocket"
+)
+
+// startWebRCONServer serves WebRCON on a local port for the password
+// "secret". Before each reply it sends a chat message and a reply to a
+// command nobody sent, as a busy Rust server interleaves them; the
+// command "quit" closes the connection instead.
+func startWebRCONServer(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/secret" {
+			// Rust drops the connection on a wrong password
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		ws, err := websocket.Accept(w, r)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			typ, data, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			if typ != websocket.TextMessage {
+				continue
+			}
+			var cmd webrconMessage
+			_ = json.Unmarshal(data, &cmd)
+			if cmd.Message == "quit" {
+				return
+			}
+			for _, msg := range []webrconMessage{
+				{Identifier: -1, Type: "Chat", Message: `{"Message":"hi","Username":"Bob"}`},
+				{Identifier: cmd.Identifier + 1000, Type: "Generic", Message: "stale"},
+				{Identifier: cmd.Identifier, Type: "Generic", Message: "echo " + cmd.Message},
+			} {
+				out, _ := json.Marshal(msg)
+				if err := ws.WriteMessage(websocket.TextMessage, out); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestWebRCON(t *testing.T) {
+	address := startWebRCONServer(t)
+	client := NewClient()
+	client.SetProtocol(ProtocolWebRCON)
+	console := make(chan ConsoleMessage, 8)
+	client.SetConsoleHandler(func(m ConsoleMessage) { console <- m })
+	dropped := make(chan error, 1)
+	client.SetDisconnectHandler(func(err error) { dropped <- err })
+
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if err := client.Authenticate("secret"); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	for _, command := range []string{"serverinfo", "playerlist"} {
+		out, err := client.Execute(command)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if out != "echo "+command {
+			t.Errorf("Expected the reply to %s, got %q", command, out)
+		}
+	}
+	select {
+	case m := <-console:
+		if m.Type != "Chat" || !strings.Contains(m.Message, "Bob") {
+			t.Errorf("Unexpected console message: %+v", m)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the chat to reach the console handler")
+	}
+
+	// Health checks ping instead of sending an empty command
+	if _, err := client.Execute(healthCheckCommand); err != nil {
+		t.Errorf("Expected the ping to be answered, got %v", err)
+	}
+
+	if _, err := client.Execute("quit"); err == nil {
+		t.Error("Expected the command to fail as the server closed the connection")
+	}
+	select {
+	case <-dropped:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the disconnect handler to be called")
+	}
+	if client.IsConnected() {
+		t.Error("Expected the client to be disconnected")
+	}
+}
+
+func TestWebRCON_InvalidPassword(t *testing.T) {
+	address := startWebRCONServer(t)
+	client := NewClient()
+	client.SetProtocol(ProtocolWebRCON)
+	if err := client.Connect(address); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer client.Disconnect()
+
+	if err := client.Authenticate("wrong"); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+	if client.IsAuthenticated() {
+		t.Error("Expected the client not to be authenticated")
+	}
+}
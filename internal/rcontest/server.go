@@ -0,0 +1,389 @@
+// Package rcontest provides an in-process Source RCON server for tests.
+// It listens on a loopback port, so clients are tested over real sockets,
+// answers commands from a handler such as a Script of expected exchanges,
+// and injects the faults of real servers on demand: refused logins, replies
+// split across writes, slow replies, closed and reset connections.
+//
+// It depends only on the standard library, so the rcon package's own tests
+// can use it. For that reason it keeps a small packet codec of its own
+// rather than rcon's ReadPacket and WritePacket, which would otherwise be
+// tested against themselves.
+package rcontest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Packet types of the Source RCON protocol.
+const (
+	typeResponse     int32 = 0
+	typeCommand      int32 = 2
+	typeAuthResponse int32 = 2
+	typeAuth         int32 = 3
+)
+
+// maxPacketSize bounds the size of the packets the server accepts, as the
+// protocol does.
+const maxPacketSize = 4096
+
+// fragmentGap is how long the server waits between the pieces of a reply
+// split with Reply.Fragment, so that clients read them separately.
+const fragmentGap = 5 * time.Millisecond
+
+// Fault is a failure the server injects instead of a reply.
+type Fault int
+
+// Faults the server can inject.
+const (
+	FaultNone     Fault = iota // Reply normally
+	FaultClose                 // Close the connection instead of replying, as a server shutting down does
+	FaultReset                 // Reset the connection instead of replying, as a crashed server does
+	FaultHang                  // Never reply again, reading and discarding what the client sends
+	FaultMismatch              // Reply with an ID other than the request's
+)
+
+// Reply is the server's answer to one command or login.
+type Reply struct {
+	Body     string        // Output of the command; ignored for logins
+	Delay    time.Duration // How long to wait before replying, as a busy server does
+	Split    int           // Send the body in packets of at most Split bytes, as servers send long output; in one packet if 0
+	Fragment int           // Write the reply in pieces of at most Fragment bytes, each on its own; in one write if 0
+	Fault    Fault         // Fault injected instead of the reply, after Delay
+}
+
+// Handler returns the reply to a command.
+type Handler func(command string) Reply
+
+// Respond returns a handler replying to each command with the output f
+// gives for it.
+func Respond(f func(command string) string) Handler {
+	return func(command string) Reply { return Reply{Body: f(command)} }
+}
+
+// Exchange is a command a Script expects and the reply it sends.
+type Exchange struct {
+	Command string
+	Reply   Reply
+}
+
+// Script returns a handler answering the exchanges in order. It fails t
+// when a command other than the next one expected arrives, answering it
+// with an empty reply, and when the test ends with exchanges unanswered.
+func Script(t testing.TB, exchanges ...Exchange) Handler {
+	t.Helper()
+	var mu sync.Mutex
+	next := 0
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if next < len(exchanges) {
+			t.Errorf("rcontest: %d scripted commands never arrived, the first %q", len(exchanges)-next, exchanges[next].Command)
+		}
+	})
+	return func(command string) Reply {
+		mu.Lock()
+		defer mu.Unlock()
+		if next >= len(exchanges) {
+			t.Errorf("rcontest: unexpected command %q after the script ended", command)
+			return Reply{}
+		}
+		if want := exchanges[next].Command; command != want {
+			t.Errorf("rcontest: got command %q, want %q", command, want)
+			return Reply{}
+		}
+		next++
+		return exchanges[next-1].Reply
+	}
+}
+
+// Server is an in-process Source RCON server. It is safe for concurrent
+// use.
+type Server struct {
+	// AuthReply sets the delay, fragmenting and fault of replies to
+	// logins; its Body is ignored. It must be set before Start.
+	AuthReply Reply
+	// SourceAuth makes the server precede each reply to a login with an
+	// empty response, as Source engine servers do. It must be set before
+	// Start.
+	SourceAuth bool
+	// MirrorResponses makes the server answer response packets from
+	// authenticated clients with an empty response and then one holding
+	// 0x00000100, as Source engine servers do; clients send them after a
+	// command to find the end of its reply. Others are answered as a
+	// refused login. It must be set before Start.
+	MirrorResponses bool
+
+	password string  // Password clients must authenticate with, any if empty
+	handler  Handler // Source of replies to commands, empty ones if nil
+	ln       net.Listener
+	done     chan struct{}
+	once     sync.Once
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	accepted int
+	commands []string
+}
+
+// NewServer starts a server on a loopback port that accepts password, or
+// any password if it is empty, and answers commands with handler. The
+// server is closed when the test ends.
+func NewServer(t testing.TB, password string, handler Handler) *Server {
+	t.Helper()
+	s := NewUnstartedServer(t, password, handler)
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer is like NewServer but does not accept connections
+// until Start is called, so its options can be set first.
+func NewUnstartedServer(t testing.TB, password string, handler Handler) *Server {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("rcontest: failed to listen: %v", err)
+	}
+	s := &Server{
+		password: password,
+		handler:  handler,
+		ln:       ln,
+		done:     make(chan struct{}),
+		conns:    make(map[net.Conn]bool),
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Start starts accepting connections.
+func (s *Server) Start() {
+	s.wg.Add(1)
+	go s.serve()
+}
+
+// Addr returns the server's address in "host:port" format.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Close stops accepting connections, closes open ones and waits for their
+// handlers to return. It is safe to call more than once.
+func (s *Server) Close() {
+	s.once.Do(func() {
+		close(s.done)
+		_ = s.ln.Close()
+		s.mu.Lock()
+		for conn := range s.conns {
+			_ = conn.Close()
+		}
+		s.mu.Unlock()
+	})
+	s.wg.Wait()
+}
+
+// Accepted returns the number of connections the server has accepted.
+func (s *Server) Accepted() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted
+}
+
+// Commands returns the commands the server has received from
+// authenticated clients, in order.
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.commands)
+}
+
+// ResetConnections resets every open connection, as a server restarting
+// does, while still accepting new ones.
+func (s *Server) ResetConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		reset(conn)
+	}
+}
+
+// serve accepts connections until the listener is closed.
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.accepted++
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// serveConn answers the packets of conn until it fails or a fault ends it.
+// Commands are only answered once the client has authenticated.
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	rd := bufio.NewReader(conn)
+	authenticated := false
+	for {
+		id, typ, body, err := readPacket(rd)
+		if err != nil {
+			return
+		}
+
+		var reply Reply
+		replyType := typeResponse
+		switch {
+		case typ == typeResponse && authenticated && s.MirrorResponses:
+			out := appendPacket(nil, id, typeResponse, "")
+			if err := s.write(conn, appendPacket(out, id, typeResponse, "\x00\x01\x00\x00"), 0); err != nil {
+				return
+			}
+			continue
+		case typ == typeAuth:
+			reply, replyType = s.AuthReply, typeAuthResponse
+			reply.Body = ""
+			authenticated = s.password == "" || body == s.password
+			if !authenticated {
+				id = -1
+			}
+		case typ == typeCommand && authenticated:
+			s.mu.Lock()
+			s.commands = append(s.commands, body)
+			s.mu.Unlock()
+			if s.handler != nil {
+				reply = s.handler(body)
+			}
+		default:
+			// Like Source servers, answer commands before a login as a
+			// refused login
+			id, replyType = -1, typeAuthResponse
+		}
+
+		if !s.sleep(reply.Delay) {
+			return
+		}
+		switch reply.Fault {
+		case FaultClose:
+			return
+		case FaultReset:
+			reset(conn)
+			return
+		case FaultHang:
+			_, _ = io.Copy(io.Discard, rd)
+			return
+		case FaultMismatch:
+			id += 1000
+		}
+
+		var out []byte
+		if typ == typeAuth && s.SourceAuth {
+			out = appendPacket(out, id, typeResponse, "")
+		}
+		output := reply.Body
+		for reply.Split > 0 && len(output) > reply.Split {
+			out = appendPacket(out, id, replyType, output[:reply.Split])
+			output = output[reply.Split:]
+		}
+		out = appendPacket(out, id, replyType, output)
+		if err := s.write(conn, out, reply.Fragment); err != nil {
+			return
+		}
+	}
+}
+
+// sleep waits for d, returning false if the server closes meanwhile.
+func (s *Server) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// write writes data to conn in pieces of at most fragment bytes, or in one
+// piece if fragment is 0.
+func (s *Server) write(conn net.Conn, data []byte, fragment int) error {
+	if fragment <= 0 {
+		_, err := conn.Write(data)
+		return err
+	}
+	for len(data) > 0 {
+		n := min(fragment, len(data))
+		if _, err := conn.Write(data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+		if len(data) > 0 && !s.sleep(fragmentGap) {
+			return net.ErrClosed
+		}
+	}
+	return nil
+}
+
+// reset closes conn with a TCP reset instead of an orderly shutdown.
+func reset(conn net.Conn) {
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = conn.Close()
+}
+
+// readPacket reads one packet from r.
+func readPacket(r io.Reader) (id, typ int32, body string, err error) {
+	var size int32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return 0, 0, "", err
+	}
+	if size < 10 || size > maxPacketSize {
+		return 0, 0, "", fmt.Errorf("invalid packet size: %d", size)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return 0, 0, "", err
+	}
+	if buf[size-2] != 0 || buf[size-1] != 0 {
+		return 0, 0, "", errors.New("packet not terminated by two null bytes")
+	}
+	id = int32(binary.LittleEndian.Uint32(buf[0:4]))
+	typ = int32(binary.LittleEndian.Uint32(buf[4:8]))
+	return id, typ, string(buf[8 : size-2]), nil
+}
+
+// appendPacket appends the encoding of a packet to dst.
+func appendPacket(dst []byte, id, typ int32, body string) []byte {
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(len(body)+10))
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(id))
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(typ))
+	dst = append(dst, body...)
+	return append(dst, 0, 0)
+}
@@ -0,0 +1,133 @@
+package rcontest_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcontest"
+)
+
+// dial connects and authenticates an RCON client to s.
+func dial(t *testing.T, s *rcontest.Server, password string) (*rcon.Client, error) {
+	t.Helper()
+	client := rcon.NewClient()
+	if err := client.Connect(s.Addr()); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect() })
+	return client, client.Authenticate(password)
+}
+
+func TestServer_Script(t *testing.T) {
+	s := rcontest.NewServer(t, "secret", rcontest.Script(t,
+		rcontest.Exchange{Command: "list", Reply: rcontest.Reply{Body: "There are 0 players online"}},
+		rcontest.Exchange{Command: "say hi", Reply: rcontest.Reply{Body: strings.Repeat("x", 3000), Fragment: 500}},
+	))
+
+	if _, err := dial(t, s, "wrong"); !errors.Is(err, rcon.ErrAuthFailed) {
+		t.Errorf("Expected ErrAuthFailed, got %v", err)
+	}
+	client, err := dial(t, s, "secret")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if out, err := client.Execute("list"); err != nil || out != "There are 0 players online" {
+		t.Errorf("Execute(list) = %q, %v", out, err)
+	}
+	if out, err := client.Execute("say hi"); err != nil || out != strings.Repeat("x", 3000) {
+		t.Errorf("Expected the fragmented reply whole, got %d bytes, %v", len(out), err)
+	}
+	if got := s.Commands(); !slices.Equal(got, []string{"list", "say hi"}) {
+		t.Errorf("Commands() = %q", got)
+	}
+	if n := s.Accepted(); n != 2 {
+		t.Errorf("Accepted() = %d, want 2", n)
+	}
+}
+
+func TestServer_SourceAuth(t *testing.T) {
+	s := rcontest.NewUnstartedServer(t, "secret", rcontest.Respond(func(command string) string { return "ran " + command }))
+	s.SourceAuth = true
+	s.AuthReply = rcontest.Reply{Fragment: 3}
+	s.Start()
+
+	client, err := dial(t, s, "secret")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	// The auth reply after the empty response is skipped as stale
+	if out, err := client.Execute("status"); err != nil || out != "ran status" {
+		t.Errorf("Execute(status) = %q, %v", out, err)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	tests := []struct {
+		fault     rcontest.Fault
+		connected bool
+	}{
+		{rcontest.FaultClose, false},
+		{rcontest.FaultReset, false},
+		{rcontest.FaultMismatch, true},
+		{rcontest.FaultHang, true},
+	}
+	for _, tt := range tests {
+		s := rcontest.NewServer(t, "", func(string) rcontest.Reply { return rcontest.Reply{Fault: tt.fault} })
+		client, err := dial(t, s, "any")
+		if err != nil {
+			t.Fatalf("Authenticate failed: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		if _, err := client.ExecuteContext(ctx, "status"); err == nil {
+			t.Errorf("Expected fault %d to fail the command", tt.fault)
+		}
+		cancel()
+		if client.IsConnected() != tt.connected {
+			t.Errorf("Expected connected = %v after fault %d", tt.connected, tt.fault)
+		}
+	}
+}
+
+func TestServer_Slow(t *testing.T) {
+	s := rcontest.NewServer(t, "", func(string) rcontest.Reply { return rcontest.Reply{Body: "ok", Delay: 200 * time.Millisecond} })
+	client, err := dial(t, s, "any")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.ExecuteContext(ctx, "status"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the slow reply to outlast the deadline, got %v", err)
+	}
+	start := time.Now()
+	if out, err := client.Execute("status"); err != nil || out != "ok" {
+		t.Errorf("Execute(status) = %q, %v", out, err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected the reply to take its delay, took %v", elapsed)
+	}
+}
+
+func TestServer_ResetConnections(t *testing.T) {
+	s := rcontest.NewServer(t, "", rcontest.Respond(func(string) string { return "ok" }))
+	client, err := dial(t, s, "any")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	s.ResetConnections()
+	if _, err := client.Execute("status"); err == nil {
+		t.Error("Expected a command on a reset connection to fail")
+	}
+	again, err := dial(t, s, "any")
+	if err != nil {
+		t.Fatalf("Expected the server to accept connections after a reset, got %v", err)
+	}
+	if out, err := again.Execute("status"); err != nil || out != "ok" {
+		t.Errorf("Execute(status) = %q, %v", out, err)
+	}
+}
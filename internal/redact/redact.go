@@ -0,0 +1,225 @@
+// Package redact scrubs secrets from text before it leaves the process, in
+// logs and in tool results. It knows two kinds of secrets: the passwords
+// registered with AddSecret as they are configured or used, and the matches
+// of the regular expressions installed with SetPatterns.
+package redact
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces every redacted secret.
+const Mask = "[REDACTED]"
+
+// minSecretLength is the length below which a registered secret is
+// ignored: replacing every occurrence of a one or two character password
+// would mangle unrelated text while protecting nothing.
+const minSecretLength = 3
+
+// sensitiveKeys are the log attribute keys whose values are always masked.
+var sensitiveKeys = []string{"password", "secret", "token"}
+
+// Redactor holds the secrets and patterns to scrub. It is safe for
+// concurrent use.
+type Redactor struct {
+	mu       sync.RWMutex
+	secrets  map[string]bool
+	replacer *strings.Replacer
+	patterns []*regexp.Regexp
+}
+
+// Default is the process-wide redactor used by the package functions.
+var Default = &Redactor{}
+
+// AddSecret registers a secret with the default redactor.
+func AddSecret(secret string) { Default.AddSecret(secret) }
+
+// SetPatterns replaces the patterns of the default redactor.
+func SetPatterns(patterns []string) error { return Default.SetPatterns(patterns) }
+
+// String scrubs s with the default redactor.
+func String(s string) string { return Default.String(s) }
+
+// AddSecret registers a secret so that every occurrence of it is masked.
+// Secrets shorter than three characters are ignored.
+func (r *Redactor) AddSecret(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.secrets[secret] {
+		return
+	}
+	if r.secrets == nil {
+		r.secrets = make(map[string]bool)
+	}
+	r.secrets[secret] = true
+
+	// Replace longer secrets first so a secret containing another one is
+	// masked whole.
+	secrets := make([]string, 0, len(r.secrets))
+	for s := range r.secrets {
+		secrets = append(secrets, s)
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, s := range secrets {
+		pairs = append(pairs, s, Mask)
+	}
+	r.replacer = strings.NewReplacer(pairs...)
+}
+
+// SetPatterns compiles patterns and makes them replace the current ones.
+// Every match of a pattern is masked; if a pattern has a capturing group,
+// only the text of its first group is. Returns an error, and keeps the
+// current patterns, if a pattern does not compile.
+func (r *Redactor) SetPatterns(patterns []string) error {
+	compiled, err := Compile(patterns)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.patterns = compiled
+	return nil
+}
+
+// Compile compiles redaction patterns, reporting the first that is invalid.
+func Compile(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// String returns s with every registered secret and pattern match masked.
+func (r *Redactor) String(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.replacer != nil {
+		s = r.replacer.Replace(s)
+	}
+	for _, re := range r.patterns {
+		s = maskPattern(re, s)
+	}
+	return s
+}
+
+// Value scrubs every string in v, a value decoded from JSON, in place where
+// possible, and returns the result.
+func (r *Redactor) Value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case []any:
+		for i := range v {
+			v[i] = r.Value(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = r.Value(v[k])
+		}
+	}
+	return v
+}
+
+// maskPattern masks the matches of re in s, or only the first group of
+// each match when re has one.
+func maskPattern(re *regexp.Regexp, s string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllLiteralString(s, Mask)
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		if m[2] < 0 {
+			continue
+		}
+		b.WriteString(s[last:m[2]])
+		b.WriteString(Mask)
+		last = m[3]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// Handler wraps a log handler so that the message and every string
+// attribute are scrubbed by r, and the values of attributes named like a
+// password, secret or token are masked outright.
+func (r *Redactor) Handler(h slog.Handler) slog.Handler {
+	return &handler{next: h, r: r}
+}
+
+// handler is the slog.Handler returned by Redactor.Handler.
+type handler struct {
+	next slog.Handler
+	r    *Redactor
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	scrubbed := slog.NewRecord(record.Time, record.Level, h.r.String(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		scrubbed.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, scrubbed)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		scrubbed[i] = h.attr(a)
+	}
+	return &handler{next: h.next.WithAttrs(scrubbed), r: h.r}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), r: h.r}
+}
+
+// attr scrubs a single attribute, recursing into groups.
+func (h *handler) attr(a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(a.Key, Mask)
+		}
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.r.String(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		scrubbed := make([]slog.Attr, len(group))
+		for i, g := range group {
+			scrubbed[i] = h.attr(g)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(scrubbed...)}
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, h.r.String(err.Error()))
+		}
+		if s, ok := v.Any().(fmt.Stringer); ok {
+			return slog.String(a.Key, h.r.String(s.String()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,79 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_String(t *testing.T) {
+	r := &Redactor{}
+	r.AddSecret("hunter2")
+	r.AddSecret("hunter22")
+	r.AddSecret("ab")
+	if err := r.SetPatterns([]string{`token=(\w+)`, `\d{4}-\d{4}`}); err != nil {
+		t.Fatalf("SetPatterns failed: %v", err)
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "failed to authenticate with hunter2", want: "failed to authenticate with [REDACTED]"},
+		{in: "password hunter22 rejected", want: "password [REDACTED] rejected"},
+		{in: "short secrets like ab are kept", want: "short secrets like ab are kept"},
+		{in: "url?token=abc123&x=1", want: "url?token=[REDACTED]&x=1"},
+		{in: "card 1234-5678", want: "card [REDACTED]"},
+		{in: "nothing to hide", want: "nothing to hide"},
+	}
+	for _, tt := range tests {
+		if got := r.String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if err := r.SetPatterns([]string{"("}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	if got := r.String("card 1234-5678"); got != "card [REDACTED]" {
+		t.Errorf("Expected the previous patterns to be kept, got %q", got)
+	}
+}
+
+func TestRedactor_Value(t *testing.T) {
+	r := &Redactor{}
+	r.AddSecret("hunter2")
+	v := r.Value(map[string]any{
+		"output": "pw hunter2",
+		"lines":  []any{"hunter2", 3.0},
+	}).(map[string]any)
+	if v["output"] != "pw [REDACTED]" || v["lines"].([]any)[0] != Mask || v["lines"].([]any)[1] != 3.0 {
+		t.Errorf("Unexpected scrubbed value: %v", v)
+	}
+}
+
+func TestRedactor_Handler(t *testing.T) {
+	r := &Redactor{}
+	r.AddSecret("hunter2")
+	var buf bytes.Buffer
+	logger := slog.New(r.Handler(slog.NewTextHandler(&buf, nil)))
+
+	logger.With("address", "host:1").WithGroup("rcon").Info("auth with hunter2 failed",
+		"error", errors.New("bad password hunter2"),
+		"password", "anything",
+		"api_token", "abc",
+		slog.Group("args", "note", "hunter2"),
+		"count", 3)
+
+	out := buf.String()
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "anything") || strings.Contains(out, "abc") {
+		t.Errorf("Expected secrets to be masked, got:\n%s", out)
+	}
+	for _, want := range []string{"address=host:1", "rcon.count=3", "rcon.args.note=[REDACTED]", "rcon.password=[REDACTED]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected log to contain %q, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rule rewrites the matches of a pattern in server responses, to keep
+// personal data such as player IP addresses or SteamIDs from MCP clients.
+type Rule struct {
+	Pattern *regexp.Regexp
+	Replace string // Replacement text, in which $1 or ${name} stand for groups
+}
+
+// Presets are the built-in rules, by the name a configuration refers to
+// them with.
+var Presets = map[string]Rule{
+	// Dotted IPv4 addresses, as in the address column of Source status.
+	"ipv4": {
+		Pattern: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`),
+		Replace: "[IP]",
+	},
+	// SteamIDs in the legacy STEAM_X:Y:Z, SteamID3 [U:1:Z] and 64-bit forms.
+	"steamid": {
+		Pattern: regexp.MustCompile(`\bSTEAM_[0-5]:[01]:\d+\b|\[U:1:\d+\]|\b7656119\d{10}\b`),
+		Replace: "[STEAMID]",
+	},
+}
+
+// PresetNames returns the names of the built-in rules, sorted.
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewRule returns the built-in rule named preset, or else a rule replacing
+// the matches of pattern. An empty replace selects the preset's own, or
+// Mask.
+func NewRule(preset, pattern, replace string) (Rule, error) {
+	var rule Rule
+	switch {
+	case preset != "" && pattern != "":
+		return Rule{}, errors.New("set either preset or pattern, not both")
+	case preset != "":
+		p, ok := Presets[preset]
+		if !ok {
+			return Rule{}, fmt.Errorf("unknown redaction preset %q, want one of %s", preset, strings.Join(PresetNames(), ", "))
+		}
+		rule = p
+	case pattern != "":
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		rule = Rule{Pattern: re, Replace: Mask}
+	default:
+		return Rule{}, errors.New("a preset or pattern is required")
+	}
+	if replace != "" {
+		rule.Replace = replace
+	}
+	return rule, nil
+}
+
+// Rules rewrites text with each of its rules in turn.
+type Rules []Rule
+
+// Apply returns s with every rule applied.
+func (rs Rules) Apply(s string) string {
+	for _, r := range rs {
+		s = r.Pattern.ReplaceAllString(s, r.Replace)
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRules_Apply(t *testing.T) {
+	ip, err := NewRule("ipv4", "", "")
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	steam, err := NewRule("steamid", "", "[player]")
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	email, err := NewRule("", `(\w+)@\w+\.com`, "$1@…")
+	if err != nil {
+		t.Fatalf("NewRule failed: %v", err)
+	}
+	rules := Rules{ip, steam, email}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   `#  2 1 "Alice" STEAM_1:0:12345 01:23 50 0 active 196608 203.0.113.9:27005`,
+			want: `#  2 1 "Alice" [player] 01:23 50 0 active 196608 [IP]:27005`,
+		},
+		{
+			in:   `# 3 "Bob" [U:1:67890] 12:05 35 1 active 10.0.0.255:27006`,
+			want: `# 3 "Bob" [player] 12:05 35 1 active [IP]:27006`,
+		},
+		{in: "id 76561197960287930 joined", want: "id [player] joined"},
+		{in: "contact alice@example.com", want: "contact alice@…"},
+		{in: "uptime 12:30:45, 999.1.1.1", want: "uptime 12:30:45, 999.1.1.1"},
+	}
+	for _, tt := range tests {
+		if got := rules.Apply(tt.in); got != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNewRule_Errors(t *testing.T) {
+	tests := []struct {
+		preset, pattern string
+		errContains     string
+	}{
+		{preset: "ip", errContains: "unknown redaction preset \"ip\", want one of ipv4, steamid"},
+		{pattern: "(", errContains: "invalid redaction pattern"},
+		{preset: "ipv4", pattern: `\d+`, errContains: "not both"},
+		{errContains: "is required"},
+	}
+	for _, tt := range tests {
+		if _, err := NewRule(tt.preset, tt.pattern, ""); err == nil || !strings.Contains(err.Error(), tt.errContains) {
+			t.Errorf("NewRule(%q, %q) error = %v, want %q", tt.preset, tt.pattern, err, tt.errContains)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResolveTimeout bounds how long a resolver may take to look up a secret.
+const ResolveTimeout = 10 * time.Second
+
+// Resolver looks secrets up in an external store such as HashiCorp Vault.
+// It is given the part of a reference after the scheme, e.g.
+// "kv/game/prod#rcon" for "vault:kv/game/prod#rcon".
+type Resolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface.
+type ResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f.
+func (f ResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{}
+)
+
+// Register makes r resolve the references with the given scheme, replacing
+// any resolver registered for it before.
+func Register(scheme string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = r
+}
+
+// Schemes returns the schemes with a registered resolver, sorted.
+func Schemes() []string {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	schemes := make([]string, 0, len(resolvers))
+	for scheme := range resolvers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// ParseRef splits a secret reference such as "vault:kv/game/prod#rcon" into
+// its scheme and the rest.
+func ParseRef(ref string) (scheme, rest string, err error) {
+	scheme, rest, ok := strings.Cut(ref, ":")
+	if !ok || scheme == "" || rest == "" {
+		return "", "", fmt.Errorf("invalid secret reference %q, want scheme:path such as vault:kv/game/prod#rcon", ref)
+	}
+	return scheme, rest, nil
+}
+
+// Resolve looks up the secret ref refers to with the resolver registered for
+// its scheme, giving up after ResolveTimeout.
+func Resolve(ctx context.Context, ref string) (string, error) {
+	scheme, rest, err := ParseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	resolversMu.RLock()
+	r, ok := resolvers[scheme]
+	resolversMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("no secret resolver for %q references, want one of %s", scheme, strings.Join(Schemes(), ", "))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ResolveTimeout)
+	defer cancel()
+	secret, err := r.Resolve(ctx, rest)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, errors.New("the secret is empty"))
+	}
+	return secret, nil
+}
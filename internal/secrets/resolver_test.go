@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	Register("test", ResolverFunc(func(_ context.Context, ref string) (string, error) {
+		switch ref {
+		case "good":
+			return "hunter2", nil
+		case "empty":
+			return "", nil
+		}
+		return "", errors.New("no such secret")
+	}))
+
+	if secret, err := Resolve(context.Background(), "test:good"); err != nil || secret != "hunter2" {
+		t.Errorf("Resolve() = %q, %v, want hunter2", secret, err)
+	}
+
+	tests := []struct {
+		ref     string
+		wantErr string
+	}{
+		{"test:missing", "failed to resolve test:missing: no such secret"},
+		{"test:empty", "the secret is empty"},
+		{"vaultkv/game", "invalid secret reference"},
+		{"test:", "invalid secret reference"},
+		{"aws:game/prod", `no secret resolver for "aws" references, want one of test, vault`},
+	}
+	for _, tt := range tests {
+		if _, err := Resolve(context.Background(), tt.ref); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Resolve(%q): expected error containing %q, got %v", tt.ref, tt.wantErr, err)
+		}
+	}
+}
+
+func TestVault(t *testing.T) {
+	var tokens []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("X-Vault-Token"))
+		var data any
+		switch r.URL.Path {
+		case "/v1/kv/data/game/prod":
+			data = map[string]any{"data": map[string]any{"rcon": "from-v2", "password": "default-field"}, "metadata": map[string]any{}}
+		case "/v1/legacy/game/prod", "/v1/scoped/game/prod":
+			data = map[string]any{"rcon": "from-v1"}
+		case "/v1/locked/data/game/prod", "/v1/scoped/data/game/prod":
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]any{"errors": []string{"permission denied"}})
+			return
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"errors": []string{}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer srv.Close()
+
+	env := map[string]string{"VAULT_ADDR": srv.URL, "VAULT_TOKEN": "s.token"}
+	vault := &Vault{Lookup: func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "kv/game/prod#rcon", want: "from-v2"},
+		{ref: "kv/game/prod", want: "default-field"},
+		{ref: "legacy/game/prod#rcon", want: "from-v1"},
+		{ref: "scoped/game/prod#rcon", want: "from-v1"},
+		{ref: "kv/game/prod#missing", wantErr: `no string field "missing"`},
+		{ref: "kv/game/staging#rcon", wantErr: "vault secret not found"},
+		{ref: "locked/game/prod#rcon", wantErr: "permission denied"},
+		{ref: "kv#rcon", wantErr: "invalid vault reference"},
+	}
+	for _, tt := range tests {
+		got, err := vault.Resolve(context.Background(), tt.ref)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve(%q): expected error containing %q, got %v", tt.ref, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.ref, got, err, tt.want)
+		}
+	}
+	for _, token := range tokens {
+		if token != "s.token" {
+			t.Errorf("Expected the Vault token on every request, got %q", token)
+		}
+	}
+
+	delete(env, "VAULT_TOKEN")
+	if _, err := vault.Resolve(context.Background(), "kv/game/prod#rcon"); err == nil || !strings.Contains(err.Error(), "VAULT_TOKEN") {
+		t.Errorf("Expected a missing token error, got %v", err)
+	}
+}
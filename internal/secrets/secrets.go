@@ -0,0 +1,124 @@
+// Package secrets keeps RCON passwords out of the config file in plain
+// text. Passwords can be encrypted in the file, sealed with AES-256-GCM
+// under a key taken from the KeyEnv environment variable or, failing that,
+// from the OS keyring entry KeyringAccount. Or they can be referred to in
+// an external secret store, such as "vault:kv/game/prod#rcon", and looked
+// up by the Resolver registered for the reference's scheme.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/keyring"
+)
+
+// KeyEnv is the environment variable holding the encryption key.
+const KeyEnv = "RCON_MCP_SECRET_KEY"
+
+// KeyringAccount is the OS keyring entry holding the encryption key when
+// KeyEnv is not set.
+const KeyringAccount = "secret-key"
+
+// keySize is the length of an AES-256 key in bytes.
+const keySize = 32
+
+// prefix marks the format of an encrypted password, so that it can change
+// without breaking existing config files.
+const prefix = "v1:"
+
+// ErrNoKey is returned when no encryption key is configured.
+var ErrNoKey = errors.New("no encryption key: set " + KeyEnv + " or run \"rcon-mcp-server encrypt-password --generate-key --keyring\"")
+
+// GenerateKey returns a new random key, base64 encoded.
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey decodes a base64 key as returned by GenerateKey.
+func ParseKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("invalid encryption key: want %d base64-encoded bytes", keySize)
+	}
+	return key, nil
+}
+
+// LoadKey returns the encryption key from the KeyEnv variable, read through
+// lookup (normally os.LookupEnv), or else from the OS keyring. Returns
+// ErrNoKey if neither holds one.
+func LoadKey(lookup func(string) (string, bool)) ([]byte, error) {
+	if s, ok := lookup(KeyEnv); ok && s != "" {
+		return ParseKey(s)
+	}
+	s, err := keyring.Get(KeyringAccount)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, ErrNoKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseKey(s)
+}
+
+// Encrypt seals password with key and returns it in a form fit for the
+// config file.
+func Encrypt(key []byte, password string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to encrypt password: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(password), nil)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a password sealed by Encrypt. Returns an error if it was
+// sealed with another key or has been altered.
+func Decrypt(key []byte, encrypted string) (string, error) {
+	encoded, ok := strings.CutPrefix(encrypted, prefix)
+	if !ok {
+		return "", errors.New("invalid encrypted password: unknown format")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errors.New("invalid encrypted password: not base64")
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("invalid encrypted password: too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	password, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt password: wrong key or corrupted value")
+	}
+	return string(password), nil
+}
+
+// newGCM returns the AES-GCM cipher for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid encryption key: want %d bytes", keySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,78 @@
+package secrets
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/keyring"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	encoded, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseKey(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := Encrypt(key, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(encrypted, "hunter2") || !strings.HasPrefix(encrypted, prefix) {
+		t.Errorf("Unexpected encrypted form %q", encrypted)
+	}
+	if again, _ := Encrypt(key, "hunter2"); again == encrypted {
+		t.Error("Expected a fresh nonce for every encryption")
+	}
+
+	password, err := Decrypt(key, encrypted)
+	if err != nil || password != "hunter2" {
+		t.Errorf("Decrypt() = %q, %v, want hunter2", password, err)
+	}
+
+	other, _ := GenerateKey()
+	otherKey, _ := ParseKey(other)
+	if _, err := Decrypt(otherKey, encrypted); err == nil || !strings.Contains(err.Error(), "wrong key") {
+		t.Errorf("Expected wrong key error, got %v", err)
+	}
+	for _, bad := range []string{"hunter2", prefix + "!!!", prefix + "AAAA"} {
+		if _, err := Decrypt(key, bad); err == nil {
+			t.Errorf("Decrypt(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	for _, bad := range []string{"", "not base64", "c2hvcnQ="} {
+		if _, err := ParseKey(bad); err == nil {
+			t.Errorf("ParseKey(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	keyring.MockInit()
+	encoded, _ := GenerateKey()
+	noEnv := func(string) (string, bool) { return "", false }
+
+	if _, err := LoadKey(noEnv); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Expected ErrNoKey, got %v", err)
+	}
+
+	if err := keyring.Set(KeyringAccount, encoded); err != nil {
+		t.Fatal(err)
+	}
+	defer keyring.Delete(KeyringAccount)
+	if _, err := LoadKey(noEnv); err != nil {
+		t.Errorf("Expected key from keyring, got %v", err)
+	}
+
+	env := func(name string) (string, bool) { return "garbage", name == KeyEnv }
+	if _, err := LoadKey(env); err == nil {
+		t.Error("Expected the environment variable to take precedence and fail to parse")
+	}
+}
@@ -0,0 +1,145 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultVaultField is the field of a Vault secret read when a reference
+// names none.
+const DefaultVaultField = "password"
+
+// Vault resolves "vault:" references against the KV secrets engine of a
+// HashiCorp Vault server, version 2 or 1. A reference is the mount and
+// path of a secret and, after a #, the field holding the password:
+// "vault:kv/game/prod#rcon" reads field rcon of secret game/prod in the KV
+// engine mounted at kv.
+type Vault struct {
+	// Lookup reads VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE, as the
+	// Vault CLI does. It is os.LookupEnv when nil.
+	Lookup func(string) (string, bool)
+	// Client makes the requests. It is http.DefaultClient when nil.
+	Client *http.Client
+}
+
+// init registers the Vault resolver for "vault:" references.
+func init() {
+	Register("vault", &Vault{})
+}
+
+// Resolve implements Resolver.
+func (v *Vault) Resolve(ctx context.Context, ref string) (string, error) {
+	lookup := v.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	addr, _ := lookup("VAULT_ADDR")
+	token, _ := lookup("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", errors.New("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+	namespace, _ := lookup("VAULT_NAMESPACE")
+
+	path, field, _ := strings.Cut(ref, "#")
+	if field == "" {
+		field = DefaultVaultField
+	}
+	mount, secret, ok := strings.Cut(strings.Trim(path, "/"), "/")
+	if !ok || secret == "" {
+		return "", fmt.Errorf("invalid vault reference %q, want mount/path#field", ref)
+	}
+
+	// Try the KV version 2 layout first, whose API inserts "data" after the
+	// mount and nests the fields one level deeper, then version 1. A token
+	// whose policy only covers the version 1 path is denied the other, so
+	// a denial falls back too, keeping it as the error if the version 1
+	// path has nothing either.
+	fields, err := v.read(ctx, addr, token, namespace, mount+"/data/"+secret)
+	if err == nil {
+		if nested, ok := fields["data"].(map[string]any); ok {
+			fields = nested
+		}
+	} else if errors.Is(err, errVaultNotFound) || errors.Is(err, errVaultDenied) {
+		var v1Err error
+		fields, v1Err = v.read(ctx, addr, token, namespace, mount+"/"+secret)
+		if v1Err == nil || !errors.Is(v1Err, errVaultNotFound) {
+			err = v1Err
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	value, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no string field %q", path, field)
+	}
+	return value, nil
+}
+
+var (
+	// errVaultNotFound is returned by read when Vault has no secret at a path.
+	errVaultNotFound = errors.New("vault secret not found")
+	// errVaultDenied is returned by read when the token may not read a path.
+	errVaultDenied = errors.New("vault denied access")
+)
+
+// read fetches a path of the Vault API and returns the "data" member of
+// the response.
+func (v *Vault) read(ctx context.Context, addr, token, namespace, path string) (map[string]any, error) {
+	endpoint, err := url.JoinPath(addr, "v1", path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAULT_ADDR: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAULT_ADDR: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%w at %s", errVaultNotFound, path)
+	case resp.StatusCode != http.StatusOK:
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(body, &failure)
+		err := fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(failure.Errors, "; "))
+		if resp.StatusCode == http.StatusForbidden {
+			err = fmt.Errorf("%w to %s: %w", errVaultDenied, path, err)
+		}
+		return nil, err
+	}
+
+	var envelope struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Data == nil {
+		return nil, errors.New("unexpected vault response: no data")
+	}
+	return envelope.Data, nil
+}
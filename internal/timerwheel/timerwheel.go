@@ -0,0 +1,155 @@
+// Package timerwheel schedules many callbacks, such as a heartbeat and an
+// idle expiry for each of hundreds of RCON sessions, on one goroutine and
+// a fixed set of workers rather than a goroutine or runtime timer each.
+//
+// A Wheel is a hashed timing wheel: time advances in ticks, each timer
+// waits in the slot of the tick it is due on, and timers further off than
+// one turn of the wheel wait for that many turns. Callbacks fire within a
+// tick after they are due.
+package timerwheel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// slots is the number of ticks in one turn of a wheel.
+const slots = 512
+
+// Wheel fires scheduled callbacks once Run drives it. Its methods are safe
+// for concurrent use, including from the callbacks themselves.
+type Wheel struct {
+	tick    time.Duration
+	workers int
+
+	mu     sync.Mutex
+	slots  [slots]map[*Timer]struct{} // Timers by the slot they are due in, nil until used
+	cursor int                        // Slot of the tick last processed
+}
+
+// Timer is a callback scheduled on a Wheel.
+type Timer struct {
+	w      *Wheel
+	fn     func()
+	slot   int  // Slot the timer waits in while active
+	rounds int  // Turns of the wheel left before it is due
+	active bool // Waiting to fire
+}
+
+// New creates a wheel that advances every tick and runs due callbacks on
+// workers goroutines, so that at most that many run at once and a slow
+// one delays only its own worker. Values below 1 mean 1.
+func New(tick time.Duration, workers int) *Wheel {
+	return &Wheel{tick: max(tick, time.Millisecond), workers: max(workers, 1)}
+}
+
+// AfterFunc schedules fn to run on one of the wheel's workers once d has
+// passed, rounded up to whole ticks and at least one.
+func (w *Wheel) AfterFunc(d time.Duration, fn func()) *Timer {
+	t := &Timer{w: w, fn: fn}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.add(t, d)
+	return t
+}
+
+// Stop cancels the timer. It reports whether the timer was still waiting;
+// false means its callback has already been handed to a worker, or the
+// timer was stopped before.
+func (t *Timer) Stop() bool {
+	t.w.mu.Lock()
+	defer t.w.mu.Unlock()
+	return t.w.remove(t)
+}
+
+// Reset reschedules the timer to fire once d has passed, whether or not it
+// was still waiting, and reports whether it was.
+func (t *Timer) Reset(d time.Duration) bool {
+	t.w.mu.Lock()
+	defer t.w.mu.Unlock()
+	active := t.w.remove(t)
+	t.w.add(t, d)
+	return active
+}
+
+// Run drives the wheel until ctx is done, then waits for the callbacks in
+// progress to return. Callbacks that come due as ctx is done are dropped;
+// timers still waiting stay scheduled, and fire if Run is called again.
+func (w *Wheel) Run(ctx context.Context) {
+	due := make(chan func())
+	var wg sync.WaitGroup
+	for range w.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for fn := range due {
+				fn()
+			}
+		}()
+	}
+	defer func() {
+		close(due)
+		wg.Wait()
+	}()
+
+	ticker := time.NewTicker(w.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, fn := range w.advance() {
+			select {
+			case due <- fn:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// advance moves the wheel on by one tick and returns the callbacks that
+// became due, in no particular order.
+func (w *Wheel) advance() []func() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cursor = (w.cursor + 1) % slots
+	var fns []func()
+	for t := range w.slots[w.cursor] {
+		if t.rounds > 0 {
+			t.rounds--
+			continue
+		}
+		delete(w.slots[w.cursor], t)
+		t.active = false
+		fns = append(fns, t.fn)
+	}
+	return fns
+}
+
+// add schedules t to fire once d has passed.
+// Must be called with w.mu held.
+func (w *Wheel) add(t *Timer, d time.Duration) {
+	ticks := max(int((d+w.tick-1)/w.tick), 1)
+	t.slot = (w.cursor + ticks) % slots
+	t.rounds = (ticks - 1) / slots
+	t.active = true
+	if w.slots[t.slot] == nil {
+		w.slots[t.slot] = make(map[*Timer]struct{})
+	}
+	w.slots[t.slot][t] = struct{}{}
+}
+
+// remove unschedules t and reports whether it was waiting.
+// Must be called with w.mu held.
+func (w *Wheel) remove(t *Timer) bool {
+	if !t.active {
+		return false
+	}
+	delete(w.slots[t.slot], t)
+	t.active = false
+	return true
+}
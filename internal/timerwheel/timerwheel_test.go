@@ -0,0 +1,130 @@
+package timerwheel
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fired returns a callback that records its name in order.
+func fired(order *[]string, name string) func() {
+	return func() { *order = append(*order, name) }
+}
+
+// turn advances w by n ticks, running the callbacks that come due.
+func turn(w *Wheel, n int) {
+	for range n {
+		for _, fn := range w.advance() {
+			fn()
+		}
+	}
+}
+
+func TestWheel_Order(t *testing.T) {
+	w := New(time.Second, 1)
+	var order []string
+	w.AfterFunc(3*time.Second, fired(&order, "third"))
+	w.AfterFunc(time.Second, fired(&order, "first"))
+	w.AfterFunc(1500*time.Millisecond, fired(&order, "second"))
+	w.AfterFunc(0, fired(&order, "immediate"))
+
+	turn(w, 1)
+	if len(order) != 2 {
+		t.Fatalf("Expected the timers due within a tick to fire after one, got %v", order)
+	}
+	turn(w, 1)
+	if len(order) != 3 || order[2] != "second" {
+		t.Fatalf("Expected the timer due in 1.5s to fire on the second tick, got %v", order)
+	}
+	turn(w, 1)
+	if len(order) != 4 || order[3] != "third" {
+		t.Fatalf("Expected the timer due in 3s to fire on the third tick, got %v", order)
+	}
+}
+
+func TestWheel_BeyondOneTurn(t *testing.T) {
+	w := New(time.Second, 1)
+	var order []string
+	w.AfterFunc(slots*time.Second, fired(&order, "one turn"))
+	w.AfterFunc((2*slots+5)*time.Second, fired(&order, "two turns"))
+
+	turn(w, slots-1)
+	if len(order) != 0 {
+		t.Fatalf("Expected nothing to fire before a full turn, got %v", order)
+	}
+	turn(w, 1)
+	if len(order) != 1 || order[0] != "one turn" {
+		t.Fatalf("Expected the first timer to fire after a full turn, got %v", order)
+	}
+	turn(w, slots+4)
+	if len(order) != 1 {
+		t.Fatalf("Expected the second timer to wait for its last turn, got %v", order)
+	}
+	turn(w, 1)
+	if len(order) != 2 {
+		t.Fatalf("Expected the second timer to fire after two turns and 5 ticks, got %v", order)
+	}
+}
+
+func TestTimer_StopReset(t *testing.T) {
+	w := New(time.Second, 1)
+	var order []string
+	stopped := w.AfterFunc(time.Second, fired(&order, "stopped"))
+	moved := w.AfterFunc(time.Second, fired(&order, "moved"))
+
+	if !stopped.Stop() || stopped.Stop() {
+		t.Error("Expected Stop to report true only the first time")
+	}
+	if !moved.Reset(3 * time.Second) {
+		t.Error("Expected Reset to report the timer was waiting")
+	}
+	turn(w, 2)
+	if len(order) != 0 {
+		t.Fatalf("Expected neither timer to fire yet, got %v", order)
+	}
+	turn(w, 1)
+	if len(order) != 1 || order[0] != "moved" {
+		t.Fatalf("Expected the reset timer to fire at its new time, got %v", order)
+	}
+	if moved.Stop() {
+		t.Error("Expected Stop on a fired timer to report false")
+	}
+	moved.Reset(time.Second)
+	if turn(w, 1); len(order) != 2 {
+		t.Errorf("Expected a fired timer to fire again once reset, got %v", order)
+	}
+}
+
+func TestWheel_Run(t *testing.T) {
+	w := New(time.Millisecond, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	var count atomic.Int32
+	var timer *Timer
+	timer = w.AfterFunc(time.Hour, func() {
+		if count.Add(1) < 3 {
+			timer.Reset(time.Millisecond)
+		}
+	})
+	timer.Reset(time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for count.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if count.Load() != 3 {
+		t.Errorf("Expected a callback rescheduling itself to fire 3 times, got %d", count.Load())
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return once cancelled")
+	}
+}
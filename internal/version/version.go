@@ -0,0 +1,97 @@
+// Package version reports the build information of the binary. Release
+// builds inject it with -ldflags; other builds fall back to what the Go
+// toolchain records in the binary.
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Build information injected at link time, e.g.
+// -X github.com/mjmorales/rcon-mcp-server/internal/version.Version=1.2.3
+var (
+	Version = "" // Release version
+	Commit  = "" // Git commit the binary was built from
+	Date    = "" // Build date
+	BuiltBy = "" // Tool that produced the build
+)
+
+// sdkModule is the module path of the MCP SDK.
+const sdkModule = "github.com/modelcontextprotocol/go-sdk"
+
+// Info describes the running binary.
+type Info struct {
+	Version    string `json:"version"`            // Release version, "dev" when unknown
+	Commit     string `json:"commit,omitempty"`   // Git commit
+	Date       string `json:"date,omitempty"`     // Build date
+	BuiltBy    string `json:"built_by,omitempty"` // Tool that produced the build
+	GoVersion  string `json:"go_version"`         // Go toolchain version
+	SDKVersion string `json:"mcp_sdk_version"`    // MCP SDK module version
+}
+
+// Get returns the build information. Values not injected at link time are
+// taken from the module and VCS information embedded by the Go toolchain.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		BuiltBy:   BuiltBy,
+		GoVersion: runtime.Version(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = strings.TrimPrefix(build.Main.Version, "v")
+		}
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.Date == "":
+				info.Date = setting.Value
+			}
+		}
+		for _, dep := range build.Deps {
+			if dep.Path == sdkModule {
+				info.SDKVersion = dep.Version
+				if dep.Replace != nil {
+					info.SDKVersion = dep.Replace.Version
+				}
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if info.SDKVersion == "" {
+		info.SDKVersion = "unknown"
+	}
+	return info
+}
+
+// String returns the version followed by the commit, build date, Go and
+// MCP SDK versions, e.g. "1.2.3 (commit abc1234, built 2025-01-02, go1.24.5, mcp-sdk v0.2.0)".
+func (i Info) String() string {
+	details := []string{}
+	if i.Commit != "" {
+		details = append(details, "commit "+shortCommit(i.Commit))
+	}
+	if i.Date != "" {
+		details = append(details, "built "+i.Date)
+	}
+	details = append(details, i.GoVersion, "mcp-sdk "+i.SDKVersion)
+	return fmt.Sprintf("%s (%s)", i.Version, strings.Join(details, ", "))
+}
+
+// shortCommit abbreviates a commit hash to the usual seven characters.
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
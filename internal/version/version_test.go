@@ -0,0 +1,36 @@
+package version
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	saved := []string{Version, Commit, Date, BuiltBy}
+	t.Cleanup(func() { Version, Commit, Date, BuiltBy = saved[0], saved[1], saved[2], saved[3] })
+
+	Version, Commit, Date, BuiltBy = "", "", "", ""
+	info := Get()
+	if info.Version == "" {
+		t.Error("Expected a fallback version")
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("Expected Go version %s, got %s", runtime.Version(), info.GoVersion)
+	}
+	if info.SDKVersion == "" {
+		t.Error("Expected an MCP SDK version")
+	}
+
+	Version, Commit, Date, BuiltBy = "1.2.3", "0123456789abcdef", "2025-01-02", "goreleaser"
+	info = Get()
+	if info.Version != "1.2.3" || info.Commit != "0123456789abcdef" || info.Date != "2025-01-02" || info.BuiltBy != "goreleaser" {
+		t.Errorf("Expected injected values, got %+v", info)
+	}
+	got := info.String()
+	for _, want := range []string{"1.2.3 (", "commit 0123456,", "built 2025-01-02", runtime.Version(), "mcp-sdk "} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q to contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+// Package websocket implements the parts of the WebSocket protocol (RFC
+// 6455) that game server consoles such as Rust's WebRCON are reached over:
+// the opening handshake on an existing connection, text, binary and control
+// frames, and the server side of both for tests and mock servers.
+// Extensions such as compression are not supported.
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// MessageType is the type of a message read or written.
+type MessageType int
+
+// Message types, numbered as their frame opcodes.
+const (
+	TextMessage   MessageType = 1  // UTF-8 text, such as JSON
+	BinaryMessage MessageType = 2  // Raw bytes
+	PongMessage   MessageType = 10 // Answer to a Ping, returned by ReadMessage
+)
+
+// Frame opcodes that are not message types.
+const (
+	opContinuation = 0x0
+	opClose        = 0x8
+	opPing         = 0x9
+)
+
+// DefaultReadLimit is the largest message ReadMessage accepts unless
+// SetReadLimit is called.
+const DefaultReadLimit = 1 << 20
+
+// acceptGUID is appended to the handshake key to compute the accept value,
+// as fixed by RFC 6455.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrMessageTooLarge is returned by ReadMessage for messages larger than
+// the read limit.
+var ErrMessageTooLarge = errors.New("websocket: message too large")
+
+// HandshakeError is returned by Handshake when the server answers the
+// upgrade request with anything but 101 Switching Protocols, as servers
+// that check a password in the request do for a wrong one.
+type HandshakeError struct {
+	StatusCode int    // HTTP status code of the answer
+	Status     string // HTTP status line of the answer, e.g. "401 Unauthorized"
+}
+
+func (e *HandshakeError) Error() string {
+	return "websocket: handshake refused: " + e.Status
+}
+
+// Conn is a WebSocket connection. One goroutine may read from it while
+// others write; writes are serialized.
+type Conn struct {
+	conn   net.Conn
+	br     *bufio.Reader
+	client bool // Frames sent are masked, as clients must
+	limit  int
+	wmu    sync.Mutex
+}
+
+// Handshake opens a WebSocket connection as a client over conn, already
+// connected to the server, by requesting an upgrade of path, which must
+// be escaped as in a request URI, on host. Extra headers such as
+// Authorization may be given in header. Deadlines for the handshake are
+// left to the caller to set on conn.
+func Handshake(conn net.Conn, host, path string, header http.Header) (*Conn, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	var req bytes.Buffer
+	fmt.Fprintf(&req, "GET %s HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: %s\r\nSec-WebSocket-Version: 13\r\n", path, host, key)
+	if err := header.Write(&req); err != nil {
+		return nil, err
+	}
+	req.WriteString("\r\n")
+	if _, err := conn.Write(req.Bytes()); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, &HandshakeError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return nil, errors.New("websocket: handshake answered with a wrong accept key")
+	}
+	return &Conn{conn: conn, br: br, client: true, limit: DefaultReadLimit}, nil
+}
+
+// Accept upgrades an HTTP request to a WebSocket connection as a server.
+// The request must be a valid upgrade request and w must support
+// hijacking; otherwise an error response is written and an error returned.
+func Accept(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "WebSocket upgrade required", http.StatusBadRequest)
+		return nil, errors.New("websocket: not an upgrade request")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket upgrade not supported", http.StatusInternalServerError)
+		return nil, errors.New("websocket: response does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintf(conn, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", acceptKey(key))
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &Conn{conn: conn, br: rw.Reader, limit: DefaultReadLimit}, nil
+}
+
+// acceptKey returns the accept value of the handshake for key.
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// NetConn returns the connection the WebSocket runs over, e.g. to set
+// deadlines.
+func (c *Conn) NetConn() net.Conn {
+	return c.conn
+}
+
+// SetReadLimit sets the largest message ReadMessage accepts.
+func (c *Conn) SetReadLimit(limit int) {
+	c.limit = limit
+}
+
+// WriteMessage sends data as a single text or binary message.
+func (c *Conn) WriteMessage(t MessageType, data []byte) error {
+	if t != TextMessage && t != BinaryMessage {
+		return fmt.Errorf("websocket: cannot write messages of type %d", t)
+	}
+	return c.writeFrame(byte(t), data)
+}
+
+// Ping sends a ping, which the other side answers with a pong that
+// ReadMessage returns as a PongMessage.
+func (c *Conn) Ping(data []byte) error {
+	return c.writeFrame(opPing, data)
+}
+
+// Close sends a close frame, without waiting for the answer, and closes
+// the connection.
+func (c *Conn) Close() error {
+	_ = c.writeFrame(opClose, nil)
+	return c.conn.Close()
+}
+
+// ReadMessage reads the next text, binary or pong message, joining
+// fragmented messages. Pings are answered as they arrive. A close frame
+// is answered and reported as io.EOF.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var t MessageType
+	var message []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch {
+		case op == opPing:
+			if err := c.writeFrame(byte(PongMessage), payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case op == byte(PongMessage):
+			return PongMessage, payload, nil
+		case op == opClose:
+			_ = c.writeFrame(opClose, nil)
+			return 0, nil, io.EOF
+		case op == opContinuation:
+			if t == 0 {
+				return 0, nil, errors.New("websocket: continuation frame without a message")
+			}
+		case op == byte(TextMessage) || op == byte(BinaryMessage):
+			if t != 0 {
+				return 0, nil, errors.New("websocket: new message before the last one ended")
+			}
+			t = MessageType(op)
+		default:
+			return 0, nil, fmt.Errorf("websocket: unknown opcode %d", op)
+		}
+
+		if len(message)+len(payload) > c.limit {
+			return 0, nil, ErrMessageTooLarge
+		}
+		message = append(message, payload...)
+		if fin {
+			return t, message, nil
+		}
+	}
+}
+
+// readFrame reads one frame, unmasking its payload.
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, op = header[0]&0x80 != 0, header[0]&0x0F
+	masked := header[1]&0x80 != 0
+
+	size := uint64(header[1] & 0x7F)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if size > uint64(c.limit) {
+		return false, 0, nil, ErrMessageTooLarge
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, size)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, op, payload, nil
+}
+
+// writeFrame sends payload as one final frame with opcode op, masked if
+// the connection is a client's.
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|op)
+	maskBit := byte(0)
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.conn.Write(frame)
+	return err
+}
@@ -0,0 +1,122 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// startEchoServer serves WebSocket upgrades of /secret, echoing every
+// message back and pinging the client before the first reply, and refuses
+// other paths with 401 Unauthorized.
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/secret" {
+			http.Error(w, "wrong password", http.StatusUnauthorized)
+			return
+		}
+		conn, err := Accept(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.Ping([]byte("hi"))
+		for {
+			t, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if t == PongMessage {
+				continue
+			}
+			if err := conn.WriteMessage(t, data); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestHandshakeAndEcho(t *testing.T) {
+	address := startEchoServer(t)
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	ws, err := Handshake(conn, address, "/secret", nil)
+	if err != nil {
+		t.Fatalf("Handshake failed: %v", err)
+	}
+	defer ws.Close()
+
+	// Messages of every length encoding come back whole.
+	for _, size := range []int{5, 300, 70000} {
+		want := bytes.Repeat([]byte("x"), size)
+		if err := ws.WriteMessage(TextMessage, want); err != nil {
+			t.Fatalf("WriteMessage failed: %v", err)
+		}
+		typ, got, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage failed: %v", err)
+		}
+		if typ != TextMessage || !bytes.Equal(got, want) {
+			t.Errorf("Expected a text message of %d bytes, got type %d with %d bytes", size, typ, len(got))
+		}
+	}
+
+	if err := ws.Ping(nil); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if typ, _, err := ws.ReadMessage(); err != nil || typ != PongMessage {
+		t.Errorf("Expected a pong, got type %d, %v", typ, err)
+	}
+
+	ws.SetReadLimit(10)
+	_ = ws.WriteMessage(BinaryMessage, make([]byte, 11))
+	if _, _, err := ws.ReadMessage(); !errors.Is(err, ErrMessageTooLarge) {
+		t.Errorf("Expected ErrMessageTooLarge, got %v", err)
+	}
+}
+
+func TestHandshakeRefused(t *testing.T) {
+	address := startEchoServer(t)
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	_, err = Handshake(conn, address, "/wrong", nil)
+	var refused *HandshakeError
+	if !errors.As(err, &refused) || refused.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 HandshakeError, got %v", err)
+	}
+}
+
+func TestReadMessageClose(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	ws := &Conn{conn: client, br: bufio.NewReader(client), client: true, limit: DefaultReadLimit}
+	go func() {
+		// A fragmented text message, then a close frame
+		_, _ = server.Write([]byte{0x01, 0x03, 'a', 'b', 'c', 0x80, 0x02, 'd', 'e', 0x88, 0x00})
+		_, _ = io.Copy(io.Discard, server)
+	}()
+
+	typ, data, err := ws.ReadMessage()
+	if err != nil || typ != TextMessage || string(data) != "abcde" {
+		t.Errorf("Expected the fragments to be joined, got type %d, %q, %v", typ, data, err)
+	}
+	if _, _, err := ws.ReadMessage(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected io.EOF after a close frame, got %v", err)
+	}
+	server.Close()
+}
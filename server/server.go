@@ -0,0 +1,233 @@
+// Package server exposes the RCON MCP toolset to other Go programs. New
+// returns an MCP server with every RCON tool registered, which can be run on
+// any MCP transport, including the in-memory transport used in tests:
+//
+//	srv, err := server.New(server.WithProfiles(&server.Profile{
+//		Name: "survival", Address: "mc.example.com:25575", Password: "secret",
+//	}))
+//	if err != nil {
+//		return err
+//	}
+//	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+//	go srv.Run(ctx, serverTransport)
+//
+// The configuration and session manager are shared by the whole process, so
+// a program builds a single server: New returns an error when called again.
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/config"
+	rconmcp "github.com/mjmorales/rcon-mcp-server/internal/mcp"
+	"github.com/mjmorales/rcon-mcp-server/internal/rcon"
+	"github.com/mjmorales/rcon-mcp-server/internal/secrets"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Config is the server configuration, in the same form as the config file.
+type Config = config.Config
+
+// Profile is a named RCON server that rcon_connect can connect to.
+type Profile = config.Profile
+
+// Policies decides which commands may be sent to servers.
+type Policies = config.Policies
+
+// Tools decides which tools are exposed and how they are named.
+type Tools = config.Tools
+
+// Limits bounds the resources the server uses.
+type Limits = config.Limits
+
+// SessionManager holds the open RCON sessions the tools operate on.
+type SessionManager = rcon.SessionManager
+
+// SecretResolver looks up the passwords profiles refer to with
+// "password_ref", such as "vault:kv/game/prod#rcon", in a secret store. It
+// is given the reference without its scheme.
+type SecretResolver = secrets.Resolver
+
+// SecretResolverFunc adapts a function to the SecretResolver interface.
+type SecretResolverFunc = secrets.ResolverFunc
+
+// Protocol names a wire protocol that profiles select with "protocol".
+type Protocol = rcon.Protocol
+
+// Backend speaks a wire protocol added with RegisterProtocol: it dials the
+// server, logs in and runs commands over the connection it dialed.
+type Backend = rcon.Backend
+
+// ConsoleBackend is a Backend whose servers send console output and chat
+// unprompted, which it hands to the console tools.
+type ConsoleBackend = rcon.ConsoleBackend
+
+// Capabilities describes what a Backend's protocol offers beyond running
+// commands.
+type Capabilities = rcon.Capabilities
+
+// ConsoleMessage is a message a ConsoleBackend's server sent unprompted.
+type ConsoleMessage = rcon.ConsoleMessage
+
+// ErrAuthFailed is the error a Backend reports a refused password with.
+var ErrAuthFailed = rcon.ErrAuthFailed
+
+// RegisterProtocol adds the protocol p, spoken by the Backends newBackend
+// makes, for profiles and rcon_connect to select like the built-in ones.
+// If its capabilities name a game no protocol serves yet, profiles for
+// that game default to it. Call it from an init function, before New.
+// Returns an error if p is empty or taken.
+func RegisterProtocol(p Protocol, newBackend func() Backend) error {
+	return rconmcp.RegisterProtocol(p, newBackend)
+}
+
+// RegisterGamePack adds the game pack name, whose tools register adds to
+// the server with AddTool, for "game_packs" to enable like the built-in
+// ones. The tools run their commands with RunPackCommand. Call it from an
+// init function, before New. Returns an error if name is empty or taken.
+func RegisterGamePack(name string, register func(srv *mcp.Server)) error {
+	return rconmcp.RegisterGamePack(name, register)
+}
+
+// AddTool adds a tool of a game pack to srv the way the built-in tools are
+// added: unless "tools" disables it, renamed with the configured prefix,
+// and logged and abandoned with the calling client.
+func AddTool[In, Out any](srv *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	rconmcp.AddTool(srv, tool, handler)
+}
+
+// RunPackCommand runs command on the session with the given ID for a tool
+// of a game pack, through the same policies, quotas, audit log and history
+// as rcon_execute. It returns the tool's result: the output as parse reads
+// it, or an error result with the server's reply if the command failed, was
+// rejected or parse refused it.
+func RunPackCommand(ctx context.Context, sessionID, command string, parse func(output string) (any, error)) (*mcp.CallToolResultFor[any], error) {
+	return rconmcp.RunPackCommand(ctx, sessionID, command, parse)
+}
+
+// NewSessionManager creates an empty session manager.
+func NewSessionManager() *SessionManager {
+	return rcon.NewSessionManager()
+}
+
+// LoadConfig reads a config file, as given to the serve command's --config.
+func LoadConfig(path string) (*Config, error) {
+	return config.Load(path)
+}
+
+// Option configures the server built by New.
+type Option func(*options)
+
+// options collects the settings applied by each Option.
+type options struct {
+	config   Config
+	sessions *SessionManager
+	process  []func() // Settings of the whole process, applied once the server is built
+}
+
+// built is set once New has built the process's server.
+var built atomic.Bool
+
+// ErrAlreadyBuilt is returned by New when the process has built its server
+// already.
+var ErrAlreadyBuilt = errors.New("server: New was already called; a process builds a single server")
+
+// WithConfig starts from a copy of cfg instead of an empty configuration.
+// Options given after it override its sections.
+func WithConfig(cfg *Config) Option {
+	return func(o *options) {
+		if cfg != nil {
+			o.config = *cfg
+		}
+	}
+}
+
+// WithProfiles adds server profiles to the configuration.
+func WithProfiles(profiles ...*Profile) Option {
+	return func(o *options) {
+		o.config.Profiles = append(o.config.Profiles, profiles...)
+	}
+}
+
+// WithPolicies replaces the command policies.
+func WithPolicies(policies Policies) Option {
+	return func(o *options) {
+		o.config.Policies = policies
+	}
+}
+
+// WithTools replaces the tool naming and selection.
+func WithTools(tools Tools) Option {
+	return func(o *options) {
+		o.config.Tools = tools
+	}
+}
+
+// WithLimits replaces the resource limits.
+func WithLimits(limits Limits) Option {
+	return func(o *options) {
+		o.config.Limits = limits
+	}
+}
+
+// WithSessionManager makes the tools use sessions, so the caller can open
+// sessions itself or inspect the ones the tools open.
+func WithSessionManager(sessions *SessionManager) Option {
+	return func(o *options) {
+		o.sessions = sessions
+	}
+}
+
+// WithSecretResolver makes r resolve the password references with the
+// given scheme, replacing the built-in resolver if the scheme is "vault".
+// Resolvers are shared by the whole process.
+func WithSecretResolver(scheme string, r SecretResolver) Option {
+	return func(o *options) {
+		o.process = append(o.process, func() { secrets.Register(scheme, r) })
+	}
+}
+
+// WithLogger makes the server log to l instead of the default slog logger.
+// Messages about tool calls carry the tool name and a request ID, and those
+// about sessions their ID. The logger is shared by the whole process.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *options) {
+		o.process = append(o.process, func() { rconmcp.SetLogger(l) })
+	}
+}
+
+// New builds an MCP server with the RCON tools registered and configured by
+// opts. It returns an error if the resulting configuration is invalid or
+// its audit log cannot be opened, and ErrAlreadyBuilt if the process has
+// built its server already. The options that act on the whole process,
+// WithSecretResolver and WithLogger, take effect only once the server is
+// built.
+//
+// Unlike the serve command, New opens no connection: the sessions declared
+// in the configuration are not preloaded and idle sessions are not probed.
+func New(opts ...Option) (*mcp.Server, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	cfg := o.config
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if !built.CompareAndSwap(false, true) {
+		return nil, ErrAlreadyBuilt
+	}
+
+	for _, apply := range o.process {
+		apply()
+	}
+	srv, err := rconmcp.NewServer(&cfg, o.sessions)
+	if err != nil {
+		built.Store(false)
+		return nil, err
+	}
+	return srv, nil
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/mjmorales/rcon-mcp-server/internal/mockrcon"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func init() {
+	if err := RegisterProtocol("echo-test", func() Backend { return &echoBackend{} }); err != nil {
+		panic(err)
+	}
+	if err := RegisterGamePack("echo-test", registerEchoPack); err != nil {
+		panic(err)
+	}
+}
+
+// echoBackend speaks a line protocol with a server served over net.Pipe,
+// which accepts the password "secret" and echoes commands back.
+type echoBackend struct {
+	rd *bufio.Reader
+}
+
+func (b *echoBackend) Dial(ctx context.Context, address string) (net.Conn, error) {
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		rd := bufio.NewReader(server)
+		for {
+			line, err := rd.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(server, "echo %s", line)
+		}
+	}()
+	b.rd = bufio.NewReader(client)
+	return client, nil
+}
+
+func (b *echoBackend) Auth(ctx context.Context, conn net.Conn, password string) error {
+	if password != "secret" {
+		return ErrAuthFailed
+	}
+	return nil
+}
+
+func (b *echoBackend) Execute(ctx context.Context, conn net.Conn, command string) (string, error) {
+	if _, err := fmt.Fprintln(conn, command); err != nil {
+		return "", err
+	}
+	reply, err := b.rd.ReadString('\n')
+	return strings.TrimSuffix(reply, "\n"), err
+}
+
+func (b *echoBackend) Capabilities() Capabilities {
+	return Capabilities{Network: "tcp"}
+}
+
+// registerEchoPack registers the echo_shout tool, which sends "shout" with
+// its text and returns the echoed words.
+func registerEchoPack(srv *mcp.Server) {
+	type shoutParams struct {
+		SessionID string `json:"session_id"`
+		Text      string `json:"text"`
+	}
+	AddTool(srv, &mcp.Tool{Name: "echo_shout", Description: "Shout text"}, func(ctx context.Context, _ *mcp.ServerSession, params *mcp.CallToolParamsFor[shoutParams]) (*mcp.CallToolResultFor[any], error) {
+		return RunPackCommand(ctx, params.Arguments.SessionID, "shout "+params.Arguments.Text, func(output string) (any, error) {
+			return strings.Fields(output), nil
+		})
+	})
+}
+
+// connect runs srv on an in-memory transport and returns a client session
+// connected to it.
+func connect(t *testing.T, srv *mcp.Server) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := srv.Connect(ctx, serverTransport); err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
+// callText calls a tool and returns the text of its result.
+func callText(t *testing.T, cs *mcp.ClientSession, name string, args any) string {
+	t.Helper()
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("CallTool(%s) failed: %v", name, err)
+	}
+	var b strings.Builder
+	for _, c := range res.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			b.WriteString(text.Text)
+		}
+	}
+	return b.String()
+}
+
+// TestNew builds the one server the process may build, and so covers every
+// option and registration through it.
+func TestNew(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	fixture, _ := mockrcon.Lookup("minecraft")
+	mock := mockrcon.NewServer("from-store", fixture)
+	go mock.Serve(ln)
+	defer mock.Close()
+
+	srv, err := New(
+		WithConfig(&Config{Tools: Tools{Prefix: "ignored_"}}),
+		WithTools(Tools{Prefix: "game_", Disabled: []string{"rcon_broadcast"}, GamePacks: []string{"echo-test"}}),
+		WithProfiles(
+			&Profile{Name: "survival", Address: ln.Addr().String(), PasswordRef: "store:game/survival"},
+			&Profile{Name: "echo", Address: "pipe:1", Password: "secret", Protocol: "echo-test"},
+		),
+		WithSecretResolver("store", SecretResolverFunc(func(_ context.Context, ref string) (string, error) {
+			if ref != "game/survival" {
+				return "", errors.New("no such secret")
+			}
+			return "from-store", nil
+		})),
+		WithSessionManager(NewSessionManager()),
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	cs := connect(t, srv)
+
+	res, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	tools := make(map[string]bool)
+	for _, tool := range res.Tools {
+		tools[tool.Name] = true
+	}
+	if !tools["game_execute"] || tools["rcon_execute"] {
+		t.Errorf("Expected tools to use the game_ prefix, got %v", tools)
+	}
+	if tools["game_broadcast"] {
+		t.Error("Expected the disabled broadcast tool not to be registered")
+	}
+
+	if got := callText(t, cs, "game_list_profiles", map[string]any{}); !strings.Contains(got, "survival") {
+		t.Errorf("Expected the profile to be listed, got %q", got)
+	}
+	if got := callText(t, cs, "game_list_sessions", map[string]any{}); !strings.Contains(got, "No active RCON sessions") {
+		t.Errorf("Expected no sessions, got %q", got)
+	}
+
+	// The secret resolver supplies the password of the survival profile.
+	if got := callText(t, cs, "game_connect", map[string]any{"session_id": "survival", "profile": "survival"}); !strings.Contains(got, "Connected") {
+		t.Errorf("Expected to connect with the resolved password, got %q", got)
+	}
+
+	// The registered protocol and game pack serve the echo profile.
+	if got := callText(t, cs, "game_connect", map[string]any{"session_id": "echo", "profile": "echo"}); !strings.Contains(got, "Connected") {
+		t.Fatalf("Expected to connect over the registered protocol, got %q", got)
+	}
+	if got := callText(t, cs, "echo_shout", map[string]any{"session_id": "echo", "text": "hello"}); !strings.Contains(got, `"shout","hello"`) {
+		t.Errorf("Expected the pack tool to run its command, got %q", got)
+	}
+
+	if _, err := New(); !errors.Is(err, ErrAlreadyBuilt) {
+		t.Errorf("Expected building a second server to fail with ErrAlreadyBuilt, got %v", err)
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	_, err := New(WithTools(Tools{Prefix: "bad prefix"}))
+	if err == nil || !strings.Contains(err.Error(), "prefix") {
+		t.Errorf("Expected a prefix error, got %v", err)
+	}
+}
+
+func TestRegister_Taken(t *testing.T) {
+	if err := RegisterProtocol("echo-test", func() Backend { return &echoBackend{} }); err == nil {
+		t.Error("Expected registering a protocol twice to fail")
+	}
+	if err := RegisterGamePack("minecraft", registerEchoPack); err == nil {
+		t.Error("Expected registering a built-in pack's name to fail")
+	}
+}